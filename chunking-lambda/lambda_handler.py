@@ -21,13 +21,16 @@ CHUNK_DURATION_MS = 20 * 60 * 1000  # 20 minutes in milliseconds
 EXPORT_BITRATE = "64k"
 TMP_DIR = "/tmp"
 
+# Chunks are deleted by the bucket's lifecycle rule for chunks/ once merged
+CHUNK_STORAGE_CLASS = os.environ.get('CHUNK_STORAGE_CLASS', 'STANDARD')
 
-def get_s3_client():
+
+def get_s3_client(region=None):
     """Create S3 client with proper configuration for Minio/LocalStack."""
     endpoint_url = os.environ.get('AWS_ENDPOINT_URL')
 
     client_kwargs = {
-        'region_name': os.environ.get('AWS_REGION', 'us-east-1')
+        'region_name': region or os.environ.get('AWS_REGION', 'us-east-1')
     }
 
     if endpoint_url:
@@ -41,6 +44,17 @@ def get_s3_client():
 
 # Initialize AWS clients
 s3_client = get_s3_client()
+# Clients for podcasts pinned to buckets in other regions, by region
+regional_s3_clients = {}
+
+
+def s3_client_for(region):
+    """S3 client for an event's s3_region; the default client for our own."""
+    if not region or region == s3_client.meta.region_name:
+        return s3_client
+    if region not in regional_s3_clients:
+        regional_s3_clients[region] = get_s3_client(region)
+    return regional_s3_clients[region]
 
 # MongoDB connection (initialized lazily)
 mongo_client = None
@@ -135,7 +149,7 @@ def load_audio(file_path: str) -> AudioSegment:
         raise Exception(f"Audio loading failed: {str(e)}")
 
 
-def create_chunks(audio: AudioSegment, episode_id: str, s3_bucket: str) -> List[Dict[str, Any]]:
+def create_chunks(audio: AudioSegment, episode_id: str, s3_bucket: str, s3_region: str = None) -> List[Dict[str, Any]]:
     """
     Split audio into chunks and upload to S3
 
@@ -143,6 +157,7 @@ def create_chunks(audio: AudioSegment, episode_id: str, s3_bucket: str) -> List[
         audio: AudioSegment object
         episode_id: Episode identifier
         s3_bucket: S3 bucket name
+        s3_region: Region of the bucket (default: the Lambda's own)
 
     Returns:
         List of chunk metadata dictionaries
@@ -176,11 +191,11 @@ def create_chunks(audio: AudioSegment, episode_id: str, s3_bucket: str) -> List[
         # Upload to S3
         s3_key = f"chunks/{episode_id}/{chunk_filename}"
         try:
-            s3_client.upload_file(
+            s3_client_for(s3_region).upload_file(
                 chunk_path,
                 s3_bucket,
                 s3_key,
-                ExtraArgs={'ContentType': 'audio/mpeg'}
+                ExtraArgs={'ContentType': 'audio/mpeg', 'StorageClass': CHUNK_STORAGE_CLASS}
             )
             logger.info(f"Uploaded chunk {i} to s3://{s3_bucket}/{s3_key}")
 
@@ -192,9 +207,12 @@ def create_chunks(audio: AudioSegment, episode_id: str, s3_bucket: str) -> List[
         os.remove(chunk_path)
 
         # Add metadata
+        # The bucket and region go with each chunk to the transcription Lambda
         chunks_metadata.append({
             "chunk_index": i,
             "s3_key": s3_key,
+            "s3_bucket": s3_bucket,
+            "s3_region": s3_region,
             "start_time_seconds": start_ms / 1000,
             "end_time_seconds": end_ms / 1000
         })
@@ -260,6 +278,7 @@ def lambda_handler(event: Dict[str, Any], context: Any) -> Dict[str, Any]:
 
     Args:
         event: Step Functions input containing episode_id, audio_url, s3_bucket
+            and optionally s3_region
         context: Lambda context object
 
     Returns:
@@ -278,6 +297,7 @@ def lambda_handler(event: Dict[str, Any], context: Any) -> Dict[str, Any]:
     episode_id = event['episode_id']
     audio_url = event['audio_url']
     s3_bucket = event['s3_bucket']
+    s3_region = event.get('s3_region')
 
     downloaded_file = None
 
@@ -301,7 +321,7 @@ def lambda_handler(event: Dict[str, Any], context: Any) -> Dict[str, Any]:
         audio = load_audio(downloaded_file)
 
         # Step 3 & 4: Create chunks and upload to S3
-        chunks_metadata = create_chunks(audio, episode_id, s3_bucket)
+        chunks_metadata = create_chunks(audio, episode_id, s3_bucket, s3_region)
 
         # Step 5: Update MongoDB
         s3_audio_key = f"audio/{episode_id}.mp3"
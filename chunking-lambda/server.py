@@ -36,7 +36,8 @@ def invoke():
     {
         "episode_id": "string",
         "audio_url": "string",
-        "s3_bucket": "string"
+        "s3_bucket": "string",
+        "s3_region": "string"  (optional)
     }
 
     Returns the Lambda handler response.
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// collectionSpec describes a backed-up collection and the fields that
+// identify a document in it (the collection's unique index)
+type collectionSpec struct {
+	Name string
+	Key  []string
+}
+
+// collections are backed up and restored in this order, parents first
+var collections = []collectionSpec{
+	{Name: "podcasts", Key: []string{"podcast_id"}},
+	{Name: "episodes", Key: []string{"episode_id"}},
+	{Name: "bulk_transcribe_jobs", Key: []string{"job_id"}},
+	{Name: "job_episodes", Key: []string{"job_id", "index"}},
+}
+
+// restoreBatchSize is how many documents are checked for conflicts and
+// written at a time
+const restoreBatchSize = 500
+
+// conflictPolicy decides what restore does with a document whose key
+// already exists in the database
+type conflictPolicy string
+
+const (
+	conflictSkip      conflictPolicy = "skip"      // keep the existing document
+	conflictOverwrite conflictPolicy = "overwrite" // replace it with the backed-up one
+	conflictFail      conflictPolicy = "fail"      // stop the restore
+)
+
+func parseConflictPolicy(s string) (conflictPolicy, error) {
+	switch p := conflictPolicy(s); p {
+	case conflictSkip, conflictOverwrite, conflictFail:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid conflict policy %q (want skip, overwrite or fail)", s)
+}
+
+// selectCollections returns the specs named in a comma-separated list, or
+// all of them for an empty list
+func selectCollections(list string) ([]collectionSpec, error) {
+	if strings.TrimSpace(list) == "" {
+		return collections, nil
+	}
+	wanted := map[string]bool{}
+	for _, name := range strings.Split(list, ",") {
+		wanted[strings.TrimSpace(name)] = true
+	}
+
+	var selected []collectionSpec
+	for _, spec := range collections {
+		if wanted[spec.Name] {
+			selected = append(selected, spec)
+			delete(wanted, spec.Name)
+		}
+	}
+	for name := range wanted {
+		return nil, fmt.Errorf("unknown collection %q", name)
+	}
+	return selected, nil
+}
+
+// writeNDJSON writes each document as one line of canonical Extended JSON,
+// which keeps BSON types such as dates and 64-bit integers intact
+func writeNDJSON(w io.Writer, doc bson.D) error {
+	line, err := bson.MarshalExtJSON(doc, true, false)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	_, err = w.Write(line)
+	return err
+}
+
+// ndjsonReader decodes documents written by writeNDJSON. Lines have no
+// length limit since episodes can carry whole transcripts.
+type ndjsonReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+func newNDJSONReader(r io.Reader) *ndjsonReader {
+	return &ndjsonReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next document, or io.EOF after the last one
+func (nr *ndjsonReader) Next() (bson.D, error) {
+	for {
+		raw, err := nr.r.ReadBytes('\n')
+		if len(raw) == 0 && err != nil {
+			return nil, err
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		nr.line++
+		raw = []byte(strings.TrimSpace(string(raw)))
+		if len(raw) == 0 {
+			continue
+		}
+
+		var doc bson.D
+		if err := bson.UnmarshalExtJSON(raw, true, &doc); err != nil {
+			return nil, fmt.Errorf("line %d: %w", nr.line, err)
+		}
+		return doc, nil
+	}
+}
+
+// lookup returns a top-level field of doc
+func lookup(doc bson.D, field string) (interface{}, bool) {
+	for _, e := range doc {
+		if e.Key == field {
+			return e.Value, true
+		}
+	}
+	return nil, false
+}
+
+// removeField returns doc without a top-level field
+func removeField(doc bson.D, field string) bson.D {
+	out := make(bson.D, 0, len(doc))
+	for _, e := range doc {
+		if e.Key != field {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// keyFilter builds the query matching doc's key fields
+func keyFilter(spec collectionSpec, doc bson.D) (bson.D, error) {
+	filter := bson.D{}
+	for _, field := range spec.Key {
+		value, ok := lookup(doc, field)
+		if !ok {
+			return nil, fmt.Errorf("%s document without %s", spec.Name, field)
+		}
+		filter = append(filter, bson.E{Key: field, Value: value})
+	}
+	return filter, nil
+}
+
+// keyString identifies a document by its key fields, for set lookups
+func keyString(spec collectionSpec, doc bson.D) string {
+	parts := make([]string, len(spec.Key))
+	for i, field := range spec.Key {
+		value, _ := lookup(doc, field)
+		parts[i] = fmt.Sprint(value)
+	}
+	return strings.Join(parts, "/")
+}
+
+// ConflictError reports a document that already exists under the fail policy
+type ConflictError struct {
+	Collection string
+	Key        string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s %s already exists", e.Collection, e.Key)
+}
+
+// restoreStore is the part of the database restore writes to
+type restoreStore interface {
+	// existingKeys returns the keyStrings of the documents in filters that exist
+	existingKeys(ctx context.Context, spec collectionSpec, filters []bson.D) (map[string]bool, error)
+	insertMany(ctx context.Context, collection string, docs []bson.D) error
+	replaceOne(ctx context.Context, collection string, filter, doc bson.D) error
+}
+
+// restoreStats counts what restore did with one collection's documents
+type restoreStats struct {
+	Read        int
+	Inserted    int
+	Overwritten int
+	Skipped     int
+}
+
+// restorer writes backed-up documents into a store under a conflict policy
+type restorer struct {
+	store  restoreStore
+	policy conflictPolicy
+	dryRun bool
+}
+
+// restore reads one collection's documents and writes them batch by batch.
+// Under the fail policy it stops at the first batch with a conflict;
+// earlier batches stay written.
+func (r *restorer) restore(ctx context.Context, spec collectionSpec, src *ndjsonReader) (restoreStats, error) {
+	var stats restoreStats
+	batch := make([]bson.D, 0, restoreBatchSize)
+	for {
+		doc, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return stats, fmt.Errorf("%s: %w", spec.Name, err)
+		}
+		// _id isn't restored: existing documents keep theirs and new ones get fresh IDs
+		batch = append(batch, removeField(doc, "_id"))
+		stats.Read++
+		if len(batch) == restoreBatchSize {
+			if err := r.restoreBatch(ctx, spec, batch, &stats); err != nil {
+				return stats, err
+			}
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		if err := r.restoreBatch(ctx, spec, batch, &stats); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+func (r *restorer) restoreBatch(ctx context.Context, spec collectionSpec, docs []bson.D, stats *restoreStats) error {
+	filters := make([]bson.D, len(docs))
+	for i, doc := range docs {
+		filter, err := keyFilter(spec, doc)
+		if err != nil {
+			return err
+		}
+		filters[i] = filter
+	}
+	existing, err := r.store.existingKeys(ctx, spec, filters)
+	if err != nil {
+		return fmt.Errorf("%s: %w", spec.Name, err)
+	}
+
+	var fresh []bson.D
+	for i, doc := range docs {
+		key := keyString(spec, doc)
+		if !existing[key] {
+			fresh = append(fresh, doc)
+			continue
+		}
+		switch r.policy {
+		case conflictFail:
+			return &ConflictError{Collection: spec.Name, Key: key}
+		case conflictSkip:
+			stats.Skipped++
+		case conflictOverwrite:
+			if !r.dryRun {
+				if err := r.store.replaceOne(ctx, spec.Name, filters[i], doc); err != nil {
+					return fmt.Errorf("%s %s: %w", spec.Name, key, err)
+				}
+			}
+			stats.Overwritten++
+		}
+	}
+
+	if len(fresh) > 0 && !r.dryRun {
+		if err := r.store.insertMany(ctx, spec.Name, fresh); err != nil {
+			return fmt.Errorf("%s: %w", spec.Name, err)
+		}
+	}
+	stats.Inserted += len(fresh)
+	return nil
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNDJSONRoundTrip(t *testing.T) {
+	published := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	docs := []bson.D{
+		{
+			{Key: "episode_id", Value: "ep_1"},
+			{Key: "published_date", Value: primitive.NewDateTimeFromTime(published)},
+			{Key: "duration_seconds", Value: int64(3600)},
+			{Key: "transcript_text", Value: "line one\nline two"},
+		},
+		{
+			{Key: "episode_id", Value: "ep_2"},
+			{Key: "chunks", Value: bson.A{int32(1), int32(2)}},
+		},
+	}
+
+	var buf bytes.Buffer
+	for _, doc := range docs {
+		if err := writeNDJSON(&buf, doc); err != nil {
+			t.Fatalf("writeNDJSON: %v", err)
+		}
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != len(docs) {
+		t.Fatalf("got %d lines, want %d", lines, len(docs))
+	}
+
+	r := newNDJSONReader(&buf)
+	first, err := r.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if v, _ := lookup(first, "published_date"); v != primitive.NewDateTimeFromTime(published) {
+		t.Errorf("published_date = %#v, want the original date", v)
+	}
+	if v, _ := lookup(first, "duration_seconds"); v != int64(3600) {
+		t.Errorf("duration_seconds = %#v, want int64(3600)", v)
+	}
+	if v, _ := lookup(first, "transcript_text"); v != "line one\nline two" {
+		t.Errorf("transcript_text = %q", v)
+	}
+	if _, err := r.Next(); err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if _, err := r.Next(); err == nil {
+		t.Fatal("expected EOF after the last document")
+	}
+}
+
+func TestNDJSONReaderReportsBadLine(t *testing.T) {
+	r := newNDJSONReader(strings.NewReader("{\"a\": {\"$numberInt\": \"1\"}}\n\nnot json\n"))
+	if _, err := r.Next(); err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	_, err := r.Next()
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Fatalf("err = %v, want an error for line 3", err)
+	}
+}
+
+func TestSelectCollections(t *testing.T) {
+	all, err := selectCollections("")
+	if err != nil || len(all) != len(collections) {
+		t.Fatalf("selectCollections(\"\") = %v, %v", all, err)
+	}
+
+	// Order follows the spec list, not the argument
+	specs, err := selectCollections("job_episodes, podcasts")
+	if err != nil {
+		t.Fatalf("selectCollections: %v", err)
+	}
+	if len(specs) != 2 || specs[0].Name != "podcasts" || specs[1].Name != "job_episodes" {
+		t.Errorf("got %v, want podcasts then job_episodes", specs)
+	}
+
+	if _, err := selectCollections("podcasts,users"); err == nil {
+		t.Error("expected an error for an unknown collection")
+	}
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	for _, s := range []string{"skip", "overwrite", "fail"} {
+		if p, err := parseConflictPolicy(s); err != nil || string(p) != s {
+			t.Errorf("parseConflictPolicy(%q) = %q, %v", s, p, err)
+		}
+	}
+	if _, err := parseConflictPolicy("merge"); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+}
+
+// fakeStore is an in-memory restoreStore keyed by keyString
+type fakeStore struct {
+	docs     map[string]bson.D
+	inserted int
+	replaced int
+}
+
+func (f *fakeStore) existingKeys(_ context.Context, spec collectionSpec, filters []bson.D) (map[string]bool, error) {
+	existing := map[string]bool{}
+	for _, filter := range filters {
+		key := keyString(spec, filter)
+		if _, ok := f.docs[key]; ok {
+			existing[key] = true
+		}
+	}
+	return existing, nil
+}
+
+func (f *fakeStore) insertMany(_ context.Context, _ string, docs []bson.D) error {
+	for _, doc := range docs {
+		f.docs[keyString(collections[3], doc)] = doc
+	}
+	f.inserted += len(docs)
+	return nil
+}
+
+func (f *fakeStore) replaceOne(_ context.Context, _ string, filter, doc bson.D) error {
+	f.docs[keyString(collections[3], filter)] = doc
+	f.replaced++
+	return nil
+}
+
+// jobEpisodesBackup returns three job_episodes documents of job_1
+func jobEpisodesBackup(t *testing.T) *ndjsonReader {
+	t.Helper()
+	var buf bytes.Buffer
+	for i := 0; i < 3; i++ {
+		doc := bson.D{
+			{Key: "_id", Value: primitive.NewObjectID()},
+			{Key: "job_id", Value: "job_1"},
+			{Key: "index", Value: int32(i)},
+			{Key: "status", Value: "completed"},
+		}
+		if err := writeNDJSON(&buf, doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return newNDJSONReader(&buf)
+}
+
+func TestRestoreConflictPolicies(t *testing.T) {
+	spec := collections[3]
+	existing := bson.D{{Key: "job_id", Value: "job_1"}, {Key: "index", Value: int32(1)}, {Key: "status", Value: "failed"}}
+
+	tests := []struct {
+		name         string
+		policy       conflictPolicy
+		dryRun       bool
+		want         restoreStats
+		wantInserted int
+		wantReplaced int
+		wantStatus   string
+		wantConflict bool
+	}{
+		{
+			name:         "skip keeps the existing document",
+			policy:       conflictSkip,
+			want:         restoreStats{Read: 3, Inserted: 2, Skipped: 1},
+			wantInserted: 2,
+			wantStatus:   "failed",
+		},
+		{
+			name:         "overwrite replaces it",
+			policy:       conflictOverwrite,
+			want:         restoreStats{Read: 3, Inserted: 2, Overwritten: 1},
+			wantInserted: 2,
+			wantReplaced: 1,
+			wantStatus:   "completed",
+		},
+		{
+			name:         "fail stops before writing the batch",
+			policy:       conflictFail,
+			want:         restoreStats{Read: 3},
+			wantStatus:   "failed",
+			wantConflict: true,
+		},
+		{
+			name:       "dry run writes nothing",
+			policy:     conflictOverwrite,
+			dryRun:     true,
+			want:       restoreStats{Read: 3, Inserted: 2, Overwritten: 1},
+			wantStatus: "failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{docs: map[string]bson.D{"job_1/1": existing}}
+			r := &restorer{store: store, policy: tt.policy, dryRun: tt.dryRun}
+
+			stats, err := r.restore(context.Background(), spec, jobEpisodesBackup(t))
+			var conflict *ConflictError
+			if tt.wantConflict != errors.As(err, &conflict) {
+				t.Fatalf("err = %v, want conflict %v", err, tt.wantConflict)
+			}
+			if !tt.wantConflict && err != nil {
+				t.Fatalf("restore: %v", err)
+			}
+			if stats != tt.want {
+				t.Errorf("stats = %+v, want %+v", stats, tt.want)
+			}
+			if store.inserted != tt.wantInserted || store.replaced != tt.wantReplaced {
+				t.Errorf("inserted %d, replaced %d; want %d, %d", store.inserted, store.replaced, tt.wantInserted, tt.wantReplaced)
+			}
+			if status, _ := lookup(store.docs["job_1/1"], "status"); status != tt.wantStatus {
+				t.Errorf("job_1/1 status = %v, want %s", status, tt.wantStatus)
+			}
+			for key, doc := range store.docs {
+				if _, ok := lookup(doc, "_id"); ok {
+					t.Errorf("%s was restored with its backed-up _id", key)
+				}
+			}
+		})
+	}
+}
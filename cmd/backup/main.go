@@ -0,0 +1,247 @@
+// Command backup exports podcasts, episodes and bulk job metadata from
+// MongoDB to newline-delimited JSON in S3, and restores them, so the
+// dataset can be rebuilt after accidental deletion.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	defaultPrefix   = "backups/"
+	defaultDatabase = "podcast_manager"
+
+	// backupIDLayout names backups by their UTC start time, so they sort by age
+	backupIDLayout = "20060102T150405Z"
+)
+
+const usage = `Usage: backup [-bucket B] [-prefix P] <command> [arguments]
+
+Commands:
+  backup [-collections C]         Export collections to a new backup
+  list                            List backups, oldest first
+  restore [-conflict skip|overwrite|fail] [-collections C] [-dry-run] <backup-id>
+                                  Restore a backup into the database
+
+Collections default to podcasts,episodes,bulk_transcribe_jobs,job_episodes.
+Restore keeps existing documents with -conflict skip (the default), replaces
+them with overwrite, and stops at the first one with fail.
+
+Environment:
+  MONGODB_URI                     MongoDB connection string (required)
+  MONGODB_DB_NAME                 Database name (default ` + defaultDatabase + `)
+  BACKUP_S3_BUCKET                Bucket for backups (default $S3_BUCKET)
+  AWS_REGION, AWS_ENDPOINT_URL    S3 region and custom endpoint (Minio/LocalStack)
+`
+
+// cli holds global options shared by all commands
+type cli struct {
+	bucket string
+	prefix string
+	out    io.Writer
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// run parses global flags and dispatches to a command
+func run(ctx context.Context, args []string, out io.Writer) error {
+	bucket := os.Getenv("BACKUP_S3_BUCKET")
+	if bucket == "" {
+		bucket = os.Getenv("S3_BUCKET")
+	}
+
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&bucket, "bucket", bucket, "S3 bucket holding backups")
+	prefix := fs.String("prefix", defaultPrefix, "key prefix for backups")
+	if err := fs.Parse(args); err != nil || fs.NArg() == 0 {
+		fmt.Fprint(out, usage)
+		if err != nil {
+			return err
+		}
+		return errors.New("no command given")
+	}
+
+	c := &cli{bucket: bucket, prefix: strings.TrimSuffix(*prefix, "/") + "/", out: out}
+	command, rest := fs.Arg(0), fs.Args()[1:]
+	if command == "help" {
+		fmt.Fprint(out, usage)
+		return nil
+	}
+	if c.bucket == "" {
+		return errors.New("no bucket: set BACKUP_S3_BUCKET or pass -bucket")
+	}
+
+	switch command {
+	case "backup":
+		return c.backup(ctx, rest)
+	case "list":
+		return c.list(ctx, rest)
+	case "restore":
+		return c.restore(ctx, rest)
+	default:
+		fmt.Fprint(out, usage)
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+func (c *cli) backup(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	only := fs.String("collections", "", "comma-separated collections to export")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	specs, err := selectCollections(*only)
+	if err != nil {
+		return err
+	}
+
+	db, disconnect, err := connectMongo(ctx)
+	if err != nil {
+		return err
+	}
+	defer disconnect()
+	store, err := newBackupStore(c.bucket, c.prefix)
+	if err != nil {
+		return err
+	}
+
+	started := time.Now().UTC()
+	m := manifest{
+		BackupID:    started.Format(backupIDLayout),
+		CreatedAt:   started,
+		Database:    db.Name(),
+		Collections: map[string]int64{},
+	}
+	for _, spec := range specs {
+		count, err := store.exportCollection(ctx, m.BackupID, db.Collection(spec.Name))
+		if err != nil {
+			return fmt.Errorf("exporting %s: %w", spec.Name, err)
+		}
+		m.Collections[spec.Name] = count
+		fmt.Fprintf(c.out, "%-22s %d documents\n", spec.Name, count)
+	}
+
+	// The manifest goes last: a backup without one is incomplete
+	m.CompletedAt = time.Now().UTC()
+	if err := store.putManifest(ctx, m); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "Backup %s written to s3://%s/%s\n", m.BackupID, c.bucket, store.backupPrefix(m.BackupID))
+	return nil
+}
+
+func (c *cli) list(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := newBackupStore(c.bucket, c.prefix)
+	if err != nil {
+		return err
+	}
+	manifests, err := store.listManifests(ctx)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "BACKUP ID\tDATABASE\tDOCUMENTS\tCOLLECTIONS")
+	for _, m := range manifests {
+		var total int64
+		names := make([]string, 0, len(m.Collections))
+		for _, spec := range collections {
+			if count, ok := m.Collections[spec.Name]; ok {
+				total += count
+				names = append(names, spec.Name)
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", m.BackupID, m.Database, total, strings.Join(names, ","))
+	}
+	return tw.Flush()
+}
+
+func (c *cli) restore(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	conflict := fs.String("conflict", string(conflictSkip), "what to do with existing documents: skip, overwrite or fail")
+	only := fs.String("collections", "", "comma-separated collections to restore")
+	dryRun := fs.Bool("dry-run", false, "report what would be restored without writing")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: backup restore [flags] <backup-id>")
+	}
+	policy, err := parseConflictPolicy(*conflict)
+	if err != nil {
+		return err
+	}
+	specs, err := selectCollections(*only)
+	if err != nil {
+		return err
+	}
+
+	store, err := newBackupStore(c.bucket, c.prefix)
+	if err != nil {
+		return err
+	}
+	m, err := store.getManifest(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	db, disconnect, err := connectMongo(ctx)
+	if err != nil {
+		return err
+	}
+	defer disconnect()
+
+	r := &restorer{store: mongoStore{db: db}, policy: policy, dryRun: *dryRun}
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "COLLECTION\tREAD\tINSERTED\tOVERWRITTEN\tSKIPPED")
+	for _, spec := range specs {
+		expected, ok := m.Collections[spec.Name]
+		if !ok {
+			continue
+		}
+		body, err := store.openCollection(ctx, m.BackupID, spec.Name)
+		if err != nil {
+			return err
+		}
+		stats, err := r.restore(ctx, spec, newNDJSONReader(body))
+		body.Close()
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\n", spec.Name, stats.Read, stats.Inserted, stats.Overwritten, stats.Skipped)
+		if err != nil {
+			tw.Flush()
+			return err
+		}
+		if int64(stats.Read) != expected {
+			tw.Flush()
+			return fmt.Errorf("%s: read %d documents, manifest lists %d", spec.Name, stats.Read, expected)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if *dryRun {
+		fmt.Fprintln(c.out, "Dry run: nothing was written")
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	manifestName        = "manifest.json"
+	mongoConnectTimeout = 10 * time.Second
+)
+
+// manifest describes a complete backup; it is written after every
+// collection file, so backups without one are ignored
+type manifest struct {
+	BackupID    string           `json:"backup_id"`
+	CreatedAt   time.Time        `json:"created_at"`
+	CompletedAt time.Time        `json:"completed_at"`
+	Database    string           `json:"database"`
+	Collections map[string]int64 `json:"collections"` // document count per collection
+}
+
+// connectMongo connects to MONGODB_URI and returns the MONGODB_DB_NAME
+// database with a function that disconnects
+func connectMongo(ctx context.Context) (*mongo.Database, func(), error) {
+	uri := os.Getenv("MONGODB_URI")
+	if uri == "" {
+		return nil, nil, errors.New("MONGODB_URI environment variable not set")
+	}
+	name := os.Getenv("MONGODB_DB_NAME")
+	if name == "" {
+		name = defaultDatabase
+	}
+
+	connectCtx, cancel := context.WithTimeout(ctx, mongoConnectTimeout)
+	defer cancel()
+	client, err := mongo.Connect(connectCtx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+	if err := client.Ping(connectCtx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return nil, nil, fmt.Errorf("failed to ping MongoDB: %w", err)
+	}
+	disconnect := func() { client.Disconnect(context.Background()) }
+	return client.Database(name), disconnect, nil
+}
+
+// backupStore reads and writes backups under a prefix of an S3 bucket:
+// <prefix><backup-id>/<collection>.ndjson plus a manifest.json
+type backupStore struct {
+	client   *s3.S3
+	uploader *s3manager.Uploader
+	bucket   string
+	prefix   string
+}
+
+func newBackupStore(bucket, prefix string) (*backupStore, error) {
+	awsConfig := &aws.Config{
+		Region: aws.String(os.Getenv("AWS_REGION")),
+	}
+
+	// Use custom endpoint for Minio/LocalStack
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+		awsConfig.Endpoint = aws.String(endpoint)
+		awsConfig.S3ForcePathStyle = aws.Bool(true)
+	}
+
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+	return &backupStore{
+		client:   s3.New(sess),
+		uploader: s3manager.NewUploader(sess),
+		bucket:   bucket,
+		prefix:   prefix,
+	}, nil
+}
+
+func (s *backupStore) backupPrefix(backupID string) string {
+	return s.prefix + backupID + "/"
+}
+
+func (s *backupStore) collectionKey(backupID, collection string) string {
+	return s.backupPrefix(backupID) + collection + ".ndjson"
+}
+
+// exportCollection streams every document of coll to S3 and returns how
+// many were written
+func (s *backupStore) exportCollection(ctx context.Context, backupID string, coll *mongo.Collection) (int64, error) {
+	cursor, err := coll.Find(ctx, bson.D{}, options.Find().SetProjection(bson.D{{Key: "_id", Value: 0}}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	pr, pw := io.Pipe()
+	var count int64
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for cursor.Next(ctx) {
+			var doc bson.D
+			if err := cursor.Decode(&doc); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if err := writeNDJSON(pw, doc); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			count++
+		}
+		pw.CloseWithError(cursor.Err())
+	}()
+
+	_, err = s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(s.collectionKey(backupID, coll.Name())),
+		Body:        pr,
+		ContentType: aws.String("application/x-ndjson"),
+	})
+	// Unblock the writer if the upload gave up early
+	pr.CloseWithError(err)
+	<-done
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// openCollection returns the body of a collection file; the caller closes it
+func (s *backupStore) openCollection(ctx context.Context, backupID, collection string) (io.ReadCloser, error) {
+	key := s.collectionKey(backupID, collection)
+	result, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download s3://%s/%s: %w", s.bucket, key, err)
+	}
+	return result.Body, nil
+}
+
+func (s *backupStore) putManifest(ctx context.Context, m manifest) error {
+	body, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = s.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(s.backupPrefix(m.BackupID) + manifestName),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload manifest: %w", err)
+	}
+	return nil
+}
+
+func (s *backupStore) getManifest(ctx context.Context, backupID string) (manifest, error) {
+	var m manifest
+	key := s.backupPrefix(backupID) + manifestName
+	result, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return m, fmt.Errorf("backup %s not found or incomplete: %w", backupID, err)
+	}
+	defer result.Body.Close()
+	if err := json.NewDecoder(result.Body).Decode(&m); err != nil {
+		return m, fmt.Errorf("invalid manifest %s: %w", key, err)
+	}
+	return m, nil
+}
+
+// listManifests returns the manifests of all complete backups, oldest first
+func (s *backupStore) listManifests(ctx context.Context) ([]manifest, error) {
+	var ids []string
+	err := s.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(s.prefix),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, obj := range page.Contents {
+			rel := strings.TrimPrefix(aws.StringValue(obj.Key), s.prefix)
+			if path.Base(rel) == manifestName && path.Dir(rel) != "." {
+				ids = append(ids, path.Dir(rel))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	sort.Strings(ids)
+	manifests := make([]manifest, 0, len(ids))
+	for _, id := range ids {
+		m, err := s.getManifest(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, m)
+	}
+	return manifests, nil
+}
+
+// mongoStore is the restoreStore of a live database
+type mongoStore struct {
+	db *mongo.Database
+}
+
+func (s mongoStore) existingKeys(ctx context.Context, spec collectionSpec, filters []bson.D) (map[string]bool, error) {
+	or := make(bson.A, len(filters))
+	for i, filter := range filters {
+		or[i] = filter
+	}
+	projection := bson.D{}
+	for _, field := range spec.Key {
+		projection = append(projection, bson.E{Key: field, Value: 1})
+	}
+
+	cursor, err := s.db.Collection(spec.Name).Find(ctx, bson.D{{Key: "$or", Value: or}}, options.Find().SetProjection(projection))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	existing := map[string]bool{}
+	for cursor.Next(ctx) {
+		var doc bson.D
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		existing[keyString(spec, doc)] = true
+	}
+	return existing, cursor.Err()
+}
+
+func (s mongoStore) insertMany(ctx context.Context, collection string, docs []bson.D) error {
+	batch := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		batch[i] = doc
+	}
+	_, err := s.db.Collection(collection).InsertMany(ctx, batch)
+	return err
+}
+
+func (s mongoStore) replaceOne(ctx context.Context, collection string, filter, doc bson.D) error {
+	_, err := s.db.Collection(collection).ReplaceOne(ctx, filter, doc)
+	return err
+}
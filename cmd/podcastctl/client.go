@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client talks to the podcasts server API
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// APIError is returned for non-2xx API responses
+type APIError struct {
+	StatusCode int
+	Detail     string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (HTTP %d): %s", e.StatusCode, e.Detail)
+}
+
+// Podcast mirrors the server's PodcastResponse
+type Podcast struct {
+	PodcastID    string    `json:"podcast_id"`
+	RssURL       string    `json:"rss_url"`
+	Title        string    `json:"title"`
+	Author       string    `json:"author,omitempty"`
+	SubscribedAt time.Time `json:"subscribed_at"`
+	Active       bool      `json:"active"`
+	EpisodeCount int       `json:"episode_count,omitempty"`
+}
+
+// PodcastList mirrors the server's PodcastListResponse
+type PodcastList struct {
+	Podcasts []Podcast `json:"podcasts"`
+	Total    int       `json:"total"`
+}
+
+// Episode mirrors the server's EpisodeResponse
+type Episode struct {
+	EpisodeID        string     `json:"episode_id"`
+	PodcastID        string     `json:"podcast_id"`
+	PodcastTitle     string     `json:"podcast_title"`
+	EpisodeTitle     string     `json:"episode_title"`
+	Description      string     `json:"description,omitempty"`
+	PublishedDate    *time.Time `json:"published_date,omitempty"`
+	TranscriptStatus string     `json:"transcript_status"`
+	ProcessingStep   string     `json:"processing_step,omitempty"`
+}
+
+// EpisodeList mirrors the server's EpisodeListResponse
+type EpisodeList struct {
+	Episodes []Episode `json:"episodes"`
+	Total    int       `json:"total"`
+	Page     int       `json:"page"`
+	Limit    int       `json:"limit"`
+	HasMore  bool      `json:"has_more"`
+}
+
+// Transcript mirrors the server's TranscriptResponse
+type Transcript struct {
+	EpisodeID  string `json:"episode_id"`
+	Transcript string `json:"transcript"`
+	Status     string `json:"status"`
+}
+
+// BulkJob mirrors the server's BulkTranscribeJobResponse
+type BulkJob struct {
+	JobID              string    `json:"job_id"`
+	RssURL             string    `json:"rss_url"`
+	Status             string    `json:"status"`
+	TotalEpisodes      int       `json:"total_episodes"`
+	ProcessedEpisodes  int       `json:"processed_episodes"`
+	SuccessfulEpisodes int       `json:"successful_episodes"`
+	FailedEpisodes     int       `json:"failed_episodes"`
+	CreatedAt          time.Time `json:"created_at"`
+	CurrentEpisode     string    `json:"current_episode,omitempty"`
+
+	// Computed by the server as episodes complete
+	ProgressPercent       float64    `json:"progress_percent"`
+	EstimatedCompletionAt *time.Time `json:"estimated_completion_at,omitempty"`
+}
+
+// BulkJobList mirrors the server's BulkTranscribeJobListResponse
+type BulkJobList struct {
+	Jobs  []BulkJob `json:"jobs"`
+	Total int       `json:"total"`
+}
+
+// BulkJobRequest mirrors the server's BulkTranscribeRequest
+type BulkJobRequest struct {
+	RssURL      string `json:"rss_url"`
+	MaxEpisodes *int   `json:"max_episodes,omitempty"`
+	DryRun      bool   `json:"dry_run"`
+}
+
+// CostBreakdown mirrors the server's CostBreakdown (USD)
+type CostBreakdown struct {
+	AudioMinutes     float64 `json:"audio_minutes"`
+	TranscriptionUSD float64 `json:"transcription_usd"`
+	StorageUSD       float64 `json:"storage_usd"`
+	ComputeUSD       float64 `json:"compute_usd"`
+	TotalUSD         float64 `json:"total_usd"`
+}
+
+// BulkDryRunEpisode mirrors the server's BulkTranscribeDryRunEpisode
+type BulkDryRunEpisode struct {
+	EpisodeID       string     `json:"episode_id,omitempty"`
+	Title           string     `json:"title"`
+	AudioURL        string     `json:"audio_url,omitempty"`
+	PublishedDate   *time.Time `json:"published_date,omitempty"`
+	DurationMinutes *int       `json:"duration_minutes,omitempty"`
+	Status          string     `json:"status"`
+	SkipReason      string     `json:"skip_reason,omitempty"`
+}
+
+// BulkDryRun mirrors the server's BulkTranscribeDryRunResponse
+type BulkDryRun struct {
+	RssURL                  string              `json:"rss_url"`
+	PodcastTitle            string              `json:"podcast_title"`
+	TotalEpisodes           int                 `json:"total_episodes"`
+	SkippedEpisodes         int                 `json:"skipped_episodes"`
+	EpisodesWithoutDuration int                 `json:"episodes_without_duration"`
+	EstimatedAudioHours     float64             `json:"estimated_audio_hours"`
+	EstimatedCost           CostBreakdown       `json:"estimated_cost"`
+	Episodes                []BulkDryRunEpisode `json:"episodes"`
+}
+
+// SuccessMessage mirrors the server's SuccessResponse
+type SuccessMessage struct {
+	Message string                 `json:"message"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// NewClient creates an API client for the given base URL
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// do sends a request and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &APIError{StatusCode: resp.StatusCode, Detail: errorDetail(respBody)}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// errorDetail extracts the "detail" field from a FastAPI error body
+func errorDetail(body []byte) string {
+	var payload struct {
+		Detail interface{} `json:"detail"`
+		Error  string      `json:"error"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		if detail, ok := payload.Detail.(string); ok && detail != "" {
+			return detail
+		}
+		if payload.Detail != nil {
+			detail, _ := json.Marshal(payload.Detail)
+			return string(detail)
+		}
+		if payload.Error != "" {
+			return payload.Error
+		}
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// Subscribe subscribes to a podcast by RSS URL
+func (c *Client) Subscribe(ctx context.Context, rssURL string) (*Podcast, error) {
+	var podcast Podcast
+	err := c.do(ctx, http.MethodPost, "/api/podcasts/subscribe", map[string]string{"rss_url": rssURL}, &podcast)
+	return &podcast, err
+}
+
+// ListPodcasts lists subscribed podcasts
+func (c *Client) ListPodcasts(ctx context.Context, activeOnly bool) (*PodcastList, error) {
+	var list PodcastList
+	path := "/api/podcasts?active_only=" + strconv.FormatBool(activeOnly)
+	err := c.do(ctx, http.MethodGet, path, nil, &list)
+	return &list, err
+}
+
+// Poll triggers polling of a single podcast
+func (c *Client) Poll(ctx context.Context, podcastID string) (*SuccessMessage, error) {
+	var msg SuccessMessage
+	err := c.do(ctx, http.MethodPost, "/api/podcasts/"+url.PathEscape(podcastID)+"/poll", nil, &msg)
+	return &msg, err
+}
+
+// ListEpisodes lists episodes with optional status filter
+func (c *Client) ListEpisodes(ctx context.Context, status string, page, limit int) (*EpisodeList, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("limit", strconv.Itoa(limit))
+
+	var list EpisodeList
+	err := c.do(ctx, http.MethodGet, "/api/episodes?"+query.Encode(), nil, &list)
+	return &list, err
+}
+
+// GetTranscript fetches an episode transcript
+func (c *Client) GetTranscript(ctx context.Context, episodeID string) (*Transcript, error) {
+	var transcript Transcript
+	err := c.do(ctx, http.MethodGet, "/api/episodes/"+url.PathEscape(episodeID)+"/transcript", nil, &transcript)
+	return &transcript, err
+}
+
+// StartBulkJob starts a bulk transcription job
+func (c *Client) StartBulkJob(ctx context.Context, request BulkJobRequest) (*BulkJob, error) {
+	var job BulkJob
+	err := c.do(ctx, http.MethodPost, "/api/dev/bulk-transcribe", request, &job)
+	return &job, err
+}
+
+// DryRunBulkJob lists the episodes a bulk job would transcribe and its
+// estimated cost, without creating the job
+func (c *Client) DryRunBulkJob(ctx context.Context, request BulkJobRequest) (*BulkDryRun, error) {
+	var dryRun BulkDryRun
+	request.DryRun = true
+	err := c.do(ctx, http.MethodPost, "/api/dev/bulk-transcribe", request, &dryRun)
+	return &dryRun, err
+}
+
+// GetBulkJob fetches a bulk transcription job
+func (c *Client) GetBulkJob(ctx context.Context, jobID string) (*BulkJob, error) {
+	var job BulkJob
+	err := c.do(ctx, http.MethodGet, "/api/dev/bulk-transcribe/"+url.PathEscape(jobID), nil, &job)
+	return &job, err
+}
+
+// ListBulkJobs lists recent bulk transcription jobs
+func (c *Client) ListBulkJobs(ctx context.Context, limit int) (*BulkJobList, error) {
+	var list BulkJobList
+	err := c.do(ctx, http.MethodGet, "/api/dev/bulk-transcribe?limit="+strconv.Itoa(limit), nil, &list)
+	return &list, err
+}
+
+// CancelBulkJob requests cancellation of a bulk transcription job
+func (c *Client) CancelBulkJob(ctx context.Context, jobID string) (*SuccessMessage, error) {
+	var msg SuccessMessage
+	err := c.do(ctx, http.MethodPost, "/api/dev/bulk-transcribe/"+url.PathEscape(jobID)+"/cancel", nil, &msg)
+	return &msg, err
+}
+
+// SearchEpisodes pages through episodes and returns those whose title or
+// description contains the query (case-insensitive)
+func (c *Client) SearchEpisodes(ctx context.Context, query, status string, maxResults int) ([]Episode, error) {
+	needle := strings.ToLower(query)
+	matches := []Episode{}
+
+	for page := 1; ; page++ {
+		list, err := c.ListEpisodes(ctx, status, page, 100)
+		if err != nil {
+			return matches, err
+		}
+
+		for _, ep := range list.Episodes {
+			if strings.Contains(strings.ToLower(ep.EpisodeTitle), needle) ||
+				strings.Contains(strings.ToLower(ep.Description), needle) {
+				matches = append(matches, ep)
+				if maxResults > 0 && len(matches) >= maxResults {
+					return matches, nil
+				}
+			}
+		}
+
+		if !list.HasMore {
+			return matches, nil
+		}
+	}
+}
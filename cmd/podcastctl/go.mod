@@ -0,0 +1,3 @@
+module podcastctl
+
+go 1.21
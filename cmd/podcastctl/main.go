@@ -0,0 +1,481 @@
+// Command podcastctl is a command-line client for the podcasts server API.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const defaultAPIURL = "http://localhost:8000"
+
+// terminalJobStatuses are bulk job states that no longer change
+var terminalJobStatuses = map[string]bool{
+	"completed": true,
+	"failed":    true,
+	"cancelled": true,
+}
+
+const usage = `Usage: podcastctl [-api URL] [-json] <command> [arguments]
+
+Commands:
+  subscribe <rss-url>             Subscribe to a podcast feed
+  podcasts [-all]                 List subscribed podcasts
+  poll <podcast-id>               Poll a podcast for new episodes
+  episodes [-status S] [-page N] [-limit N]
+                                  List episodes
+  transcript <episode-id>         Print an episode transcript
+  search [-status S] [-max N] <query>
+                                  Search episode titles and descriptions
+  jobs start [-max N] [-dry-run] [-watch] <rss-url>
+                                  Start a bulk transcription job (-dry-run
+                                  lists its episodes and estimated cost)
+  jobs list [-limit N]            List bulk transcription jobs
+  jobs watch [-interval D] <job-id>
+                                  Show live progress of a bulk job
+  jobs cancel <job-id>            Cancel a running bulk job
+
+The API URL defaults to $PODCASTCTL_API_URL or ` + defaultAPIURL + `.
+`
+
+// cli holds global options shared by all commands
+type cli struct {
+	client *Client
+	json   bool
+	out    io.Writer
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// run parses global flags and dispatches to a command
+func run(ctx context.Context, args []string, out io.Writer) error {
+	apiURL := os.Getenv("PODCASTCTL_API_URL")
+	if apiURL == "" {
+		apiURL = defaultAPIURL
+	}
+
+	fs := flag.NewFlagSet("podcastctl", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&apiURL, "api", apiURL, "server API base URL")
+	jsonOutput := fs.Bool("json", false, "print raw JSON responses")
+	if err := fs.Parse(args); err != nil || fs.NArg() == 0 {
+		fmt.Fprint(out, usage)
+		if err != nil {
+			return err
+		}
+		return errors.New("no command given")
+	}
+
+	c := &cli{client: NewClient(apiURL), json: *jsonOutput, out: out}
+	command, rest := fs.Arg(0), fs.Args()[1:]
+
+	switch command {
+	case "subscribe":
+		return c.subscribe(ctx, rest)
+	case "podcasts":
+		return c.podcasts(ctx, rest)
+	case "poll":
+		return c.poll(ctx, rest)
+	case "episodes":
+		return c.episodes(ctx, rest)
+	case "transcript":
+		return c.transcript(ctx, rest)
+	case "search":
+		return c.search(ctx, rest)
+	case "jobs":
+		return c.jobs(ctx, rest)
+	case "help":
+		fmt.Fprint(out, usage)
+		return nil
+	default:
+		fmt.Fprint(out, usage)
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+// printJSON writes v as indented JSON
+func (c *cli) printJSON(v interface{}) error {
+	enc := json.NewEncoder(c.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// requireArgs validates the positional argument count of a subcommand
+func requireArgs(fs *flag.FlagSet, n int, name string) error {
+	if fs.NArg() != n {
+		return fmt.Errorf("usage: podcastctl %s", name)
+	}
+	return nil
+}
+
+func (c *cli) subscribe(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("subscribe", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireArgs(fs, 1, "subscribe <rss-url>"); err != nil {
+		return err
+	}
+
+	podcast, err := c.client.Subscribe(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(podcast)
+	}
+	fmt.Fprintf(c.out, "Subscribed to %s (%s), %d episodes in feed\n", podcast.Title, podcast.PodcastID, podcast.EpisodeCount)
+	return nil
+}
+
+func (c *cli) podcasts(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("podcasts", flag.ContinueOnError)
+	all := fs.Bool("all", false, "include inactive subscriptions")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	list, err := c.client.ListPodcasts(ctx, !*all)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(list)
+	}
+
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PODCAST ID\tTITLE\tACTIVE\tEPISODES")
+	for _, p := range list.Podcasts {
+		fmt.Fprintf(tw, "%s\t%s\t%t\t%d\n", p.PodcastID, p.Title, p.Active, p.EpisodeCount)
+	}
+	return tw.Flush()
+}
+
+func (c *cli) poll(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("poll", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireArgs(fs, 1, "poll <podcast-id>"); err != nil {
+		return err
+	}
+
+	msg, err := c.client.Poll(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(msg)
+	}
+	fmt.Fprintln(c.out, msg.Message)
+	return nil
+}
+
+func (c *cli) episodes(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("episodes", flag.ContinueOnError)
+	status := fs.String("status", "", "filter by transcript status")
+	page := fs.Int("page", 1, "page number")
+	limit := fs.Int("limit", 20, "episodes per page")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	list, err := c.client.ListEpisodes(ctx, *status, *page, *limit)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(list)
+	}
+
+	c.printEpisodes(list.Episodes)
+	fmt.Fprintf(c.out, "Page %d, %d of %d episodes\n", list.Page, len(list.Episodes), list.Total)
+	return nil
+}
+
+func (c *cli) printEpisodes(episodes []Episode) {
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "EPISODE ID\tPODCAST\tTITLE\tSTATUS")
+	for _, ep := range episodes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", ep.EpisodeID, ep.PodcastTitle, ep.EpisodeTitle, ep.TranscriptStatus)
+	}
+	tw.Flush()
+}
+
+func (c *cli) transcript(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("transcript", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireArgs(fs, 1, "transcript <episode-id>"); err != nil {
+		return err
+	}
+
+	transcript, err := c.client.GetTranscript(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(transcript)
+	}
+	fmt.Fprintln(c.out, transcript.Transcript)
+	return nil
+}
+
+func (c *cli) search(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	status := fs.String("status", "", "filter by transcript status")
+	maxResults := fs.Int("max", 50, "maximum number of results (0 = unlimited)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: podcastctl search <query>")
+	}
+
+	matches, err := c.client.SearchEpisodes(ctx, strings.Join(fs.Args(), " "), *status, *maxResults)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(matches)
+	}
+	c.printEpisodes(matches)
+	return nil
+}
+
+func (c *cli) jobs(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: podcastctl jobs <start|list|watch|cancel>")
+	}
+
+	switch args[0] {
+	case "start":
+		return c.jobsStart(ctx, args[1:])
+	case "list":
+		return c.jobsList(ctx, args[1:])
+	case "watch":
+		return c.jobsWatch(ctx, args[1:])
+	case "cancel":
+		return c.jobsCancel(ctx, args[1:])
+	default:
+		return fmt.Errorf("unknown jobs command %q", args[0])
+	}
+}
+
+func (c *cli) jobsStart(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("jobs start", flag.ContinueOnError)
+	maxEpisodes := fs.Int("max", 0, "maximum number of episodes (0 = all)")
+	dryRun := fs.Bool("dry-run", false, "list the episodes and estimated cost without starting a job")
+	watch := fs.Bool("watch", false, "watch progress until the job finishes")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireArgs(fs, 1, "jobs start <rss-url>"); err != nil {
+		return err
+	}
+	if *dryRun && *watch {
+		return errors.New("-dry-run starts no job to watch")
+	}
+
+	request := BulkJobRequest{RssURL: fs.Arg(0)}
+	if *maxEpisodes > 0 {
+		request.MaxEpisodes = maxEpisodes
+	}
+	if *dryRun {
+		return c.jobsDryRun(ctx, request)
+	}
+
+	job, err := c.client.StartBulkJob(ctx, request)
+	if err != nil {
+		return err
+	}
+	if c.json && !*watch {
+		return c.printJSON(job)
+	}
+	fmt.Fprintf(c.out, "Started job %s with %d episodes\n", job.JobID, job.TotalEpisodes)
+
+	if *watch {
+		return c.watchJob(ctx, job.JobID, 2*time.Second)
+	}
+	return nil
+}
+
+func (c *cli) jobsDryRun(ctx context.Context, request BulkJobRequest) error {
+	dryRun, err := c.client.DryRunBulkJob(ctx, request)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(dryRun)
+	}
+
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TITLE	PUBLISHED	MINUTES	STATUS")
+	for _, ep := range dryRun.Episodes {
+		published, minutes, status := "-", "-", ep.Status
+		if ep.PublishedDate != nil {
+			published = ep.PublishedDate.Format("2006-01-02")
+		}
+		if ep.DurationMinutes != nil {
+			minutes = strconv.Itoa(*ep.DurationMinutes)
+		}
+		if ep.SkipReason != "" {
+			status += " (" + ep.SkipReason + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", ep.Title, published, minutes, status)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(c.out, "\n%s: %d episodes, %d skipped\n", dryRun.PodcastTitle, dryRun.TotalEpisodes, dryRun.SkippedEpisodes)
+	fmt.Fprintf(c.out, "Estimated %.1f audio hours, $%.2f\n", dryRun.EstimatedAudioHours, dryRun.EstimatedCost.TotalUSD)
+	if dryRun.EpisodesWithoutDuration > 0 {
+		fmt.Fprintf(c.out, "%d episodes have no duration in the feed and aren't in the estimate\n", dryRun.EpisodesWithoutDuration)
+	}
+	return nil
+}
+
+func (c *cli) jobsList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("jobs list", flag.ContinueOnError)
+	limit := fs.Int("limit", 20, "number of jobs")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	list, err := c.client.ListBulkJobs(ctx, *limit)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(list)
+	}
+
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "JOB ID\tSTATUS\tPROGRESS\tFAILED\tCREATED")
+	for _, job := range list.Jobs {
+		fmt.Fprintf(tw, "%s\t%s\t%d/%d\t%d\t%s\n", job.JobID, job.Status,
+			job.ProcessedEpisodes, job.TotalEpisodes, job.FailedEpisodes,
+			job.CreatedAt.Format(time.RFC3339))
+	}
+	return tw.Flush()
+}
+
+func (c *cli) jobsWatch(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("jobs watch", flag.ContinueOnError)
+	interval := fs.Duration("interval", 2*time.Second, "refresh interval")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireArgs(fs, 1, "jobs watch <job-id>"); err != nil {
+		return err
+	}
+	return c.watchJob(ctx, fs.Arg(0), *interval)
+}
+
+func (c *cli) jobsCancel(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("jobs cancel", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireArgs(fs, 1, "jobs cancel <job-id>"); err != nil {
+		return err
+	}
+
+	msg, err := c.client.CancelBulkJob(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(msg)
+	}
+	fmt.Fprintln(c.out, msg.Message)
+	return nil
+}
+
+// watchJob redraws a progress line until the job reaches a terminal state
+func (c *cli) watchJob(ctx context.Context, jobID string, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job, err := c.client.GetBulkJob(ctx, jobID)
+		if err != nil {
+			return err
+		}
+
+		if c.json {
+			if err := c.printJSON(job); err != nil {
+				return err
+			}
+		} else {
+			fmt.Fprintf(c.out, "\r\033[K%s", formatProgress(job))
+		}
+
+		if terminalJobStatuses[job.Status] {
+			if !c.json {
+				fmt.Fprintln(c.out)
+			}
+			if job.Status == "failed" {
+				return fmt.Errorf("job %s failed", jobID)
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(c.out)
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// formatProgress renders a single-line progress bar for a job
+func formatProgress(job *BulkJob) string {
+	const width = 30
+
+	filled := 0
+	percent := int(job.ProgressPercent)
+	if job.TotalEpisodes > 0 {
+		filled = job.ProcessedEpisodes * width / job.TotalEpisodes
+		if percent == 0 {
+			// Older servers don't report progress_percent
+			percent = job.ProcessedEpisodes * 100 / job.TotalEpisodes
+		}
+	}
+
+	line := fmt.Sprintf("[%s%s] %3d%% %d/%d ok=%d failed=%d %s",
+		strings.Repeat("#", filled), strings.Repeat("-", width-filled), percent,
+		job.ProcessedEpisodes, job.TotalEpisodes, job.SuccessfulEpisodes, job.FailedEpisodes, job.Status)
+	if terminalJobStatuses[job.Status] {
+		return line
+	}
+	if job.EstimatedCompletionAt != nil {
+		remaining := time.Until(*job.EstimatedCompletionAt).Round(time.Second)
+		if remaining < 0 {
+			remaining = 0
+		}
+		line += fmt.Sprintf(" eta %s", remaining)
+	}
+	if job.CurrentEpisode != "" {
+		line += " - " + job.CurrentEpisode
+	}
+	return line
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatProgress(t *testing.T) {
+	tests := []struct {
+		name     string
+		job      BulkJob
+		contains []string
+	}{
+		{
+			name:     "no episodes",
+			job:      BulkJob{Status: "pending"},
+			contains: []string{"  0%", "0/0"},
+		},
+		{
+			name: "half way with current episode",
+			job: BulkJob{
+				Status:             "running",
+				TotalEpisodes:      10,
+				ProcessedEpisodes:  5,
+				SuccessfulEpisodes: 4,
+				FailedEpisodes:     1,
+				CurrentEpisode:     "Episode 6",
+			},
+			contains: []string{" 50%", "5/10", "ok=4", "failed=1", "- Episode 6"},
+		},
+		{
+			name: "server progress and eta",
+			job: BulkJob{
+				Status:                "running",
+				TotalEpisodes:         3,
+				ProcessedEpisodes:     1,
+				ProgressPercent:       33.3,
+				EstimatedCompletionAt: timePtr(time.Now().Add(90 * time.Second)),
+			},
+			contains: []string{" 33%", "1/3", "eta 1m"},
+		},
+		{
+			name: "completed hides current episode",
+			job: BulkJob{
+				Status:            "completed",
+				TotalEpisodes:     2,
+				ProcessedEpisodes: 2,
+				CurrentEpisode:    "stale",
+			},
+			contains: []string{"100%", "completed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := formatProgress(&tt.job)
+			for _, want := range tt.contains {
+				if !strings.Contains(result, want) {
+					t.Errorf("formatProgress() = %q, want it to contain %q", result, want)
+				}
+			}
+			if tt.job.Status == "completed" && strings.Contains(result, "stale") {
+				t.Errorf("formatProgress() = %q, should not show current episode for finished job", result)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestErrorDetail(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "string detail",
+			body:     `{"detail": "Job not found"}`,
+			expected: "Job not found",
+		},
+		{
+			name:     "validation detail list",
+			body:     `{"error": "Validation error", "detail": [{"loc": ["body"]}]}`,
+			expected: `[{"loc":["body"]}]`,
+		},
+		{
+			name:     "error only",
+			body:     `{"error": "Internal server error"}`,
+			expected: "Internal server error",
+		},
+		{
+			name:     "plain text",
+			body:     "Bad Gateway\n",
+			expected: "Bad Gateway",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := errorDetail([]byte(tt.body))
+			if result != tt.expected {
+				t.Errorf("errorDetail() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestClientAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"detail": "Job not found"}`))
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL).GetBulkJob(context.Background(), "job_missing")
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Detail != "Job not found" {
+		t.Errorf("Unexpected APIError: %+v", apiErr)
+	}
+}
+
+func TestSearchEpisodesPaginates(t *testing.T) {
+	pages := map[string]EpisodeList{
+		"1": {
+			Episodes: []Episode{
+				{EpisodeID: "a", EpisodeTitle: "Intro to Go"},
+				{EpisodeID: "b", EpisodeTitle: "Rust deep dive"},
+			},
+			HasMore: true,
+		},
+		"2": {
+			Episodes: []Episode{
+				{EpisodeID: "c", EpisodeTitle: "Interview", Description: "We talk about GO modules"},
+			},
+			HasMore: false,
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(pages[r.URL.Query().Get("page")])
+	}))
+	defer server.Close()
+
+	matches, err := NewClient(server.URL).SearchEpisodes(context.Background(), "go", "", 0)
+	if err != nil {
+		t.Fatalf("SearchEpisodes() error = %v", err)
+	}
+	if len(matches) != 2 || matches[0].EpisodeID != "a" || matches[1].EpisodeID != "c" {
+		t.Errorf("SearchEpisodes() = %+v, want episodes a and c", matches)
+	}
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	var out bytes.Buffer
+	err := run(context.Background(), []string{"bogus"}, &out)
+	if err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Errorf("Expected unknown command error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "Usage: podcastctl") {
+		t.Error("Expected usage to be printed")
+	}
+}
+
+func TestJobsStartDryRun(t *testing.T) {
+	var request BulkJobRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Write([]byte(`{
+			"dry_run": true,
+			"rss_url": "https://example.com/feed.xml",
+			"podcast_title": "Go Time",
+			"total_episodes": 2,
+			"skipped_episodes": 1,
+			"episodes_without_duration": 0,
+			"estimated_audio_hours": 1.5,
+			"estimated_cost": {"audio_minutes": 90, "total_usd": 0.54},
+			"episodes": [
+				{"title": "Generics", "duration_minutes": 90, "status": "pending"},
+				{"title": "Trailer", "duration_minutes": 2, "status": "skipped", "skip_reason": "too_short"}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	err := run(context.Background(), []string{"-api", server.URL, "jobs", "start", "-dry-run", "https://example.com/feed.xml"}, &out)
+	if err != nil {
+		t.Fatalf("jobs start -dry-run error = %v", err)
+	}
+	if !request.DryRun {
+		t.Error("Expected the request to be a dry run")
+	}
+	for _, want := range []string{"Generics", "skipped (too_short)", "Go Time: 2 episodes, 1 skipped", "1.5 audio hours, $0.54"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestJobsStartDryRunRejectsWatch(t *testing.T) {
+	var out bytes.Buffer
+	err := run(context.Background(), []string{"-api", "http://127.0.0.1:0", "jobs", "start", "-dry-run", "-watch", "https://example.com/feed.xml"}, &out)
+	if err == nil || !strings.Contains(err.Error(), "-dry-run") {
+		t.Errorf("Expected -dry-run -watch to be rejected, got %v", err)
+	}
+}
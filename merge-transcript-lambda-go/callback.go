@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// When CALLBACK_URL is set, every merge result is POSTed there as JSON so
+// the API can tell clients the transcript is ready (the Step Functions path
+// otherwise finishes silently). With CALLBACK_SECRET the body is signed as
+// "X-Podcasts-Signature: sha256=<hex HMAC-SHA256>". Delivery is retried a
+// few times; a failed callback is logged and never fails the merge.
+
+const (
+	callbackAttempts = 3
+	callbackTimeout  = 10 * time.Second
+)
+
+var callbackBackoff = time.Second
+
+// callbackPayload is the body POSTed to CALLBACK_URL
+type callbackPayload struct {
+	Event string `json:"event"` // transcript.completed or transcript.failed
+	LambdaResponse
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// signCallback returns the X-Podcasts-Signature value for body
+func signCallback(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// notifyCallback posts the merge result to CALLBACK_URL, if configured
+func notifyCallback(ctx context.Context, response LambdaResponse) {
+	url := os.Getenv("CALLBACK_URL")
+	if url == "" || response.EpisodeID == "" {
+		return
+	}
+
+	event := "transcript.completed"
+	if response.Status != "completed" {
+		event = "transcript.failed"
+	}
+	body, err := json.Marshal(callbackPayload{Event: event, LambdaResponse: response, Timestamp: time.Now().UTC()})
+	if err != nil {
+		log.Printf("Warning: failed to encode callback: %v", err)
+		return
+	}
+
+	backoff := callbackBackoff
+	for attempt := 1; ; attempt++ {
+		err = postCallback(ctx, url, body)
+		if err == nil {
+			log.Printf("Sent %s callback for episode %s", event, response.EpisodeID)
+			return
+		}
+		if attempt >= callbackAttempts {
+			log.Printf("Warning: %s callback for episode %s failed after %d attempts: %v", event, response.EpisodeID, attempt, err)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			log.Printf("Warning: %s callback for episode %s abandoned: %v", event, response.EpisodeID, ctx.Err())
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func postCallback(ctx context.Context, url string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, callbackTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret, err := secrets.get(ctx, "CALLBACK_SECRET"); err == nil && secret != "" {
+		req.Header.Set("X-Podcasts-Signature", signCallback(secret, body))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("callback returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
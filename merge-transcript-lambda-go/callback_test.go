@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNotifyCallbackSignsPayload(t *testing.T) {
+	var gotBody []byte
+	var gotSignature string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotSignature = r.Header.Get("X-Podcasts-Signature")
+	}))
+	defer srv.Close()
+	t.Setenv("CALLBACK_URL", srv.URL)
+	t.Setenv("CALLBACK_SECRET", "s3cret")
+
+	notifyCallback(context.Background(), LambdaResponse{EpisodeID: "ep1", Status: "completed", TotalWords: 42})
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(gotBody, &payload); err != nil {
+		t.Fatalf("invalid callback body %q: %v", gotBody, err)
+	}
+	if payload["event"] != "transcript.completed" || payload["episode_id"] != "ep1" {
+		t.Errorf("payload = %v, want transcript.completed for ep1", payload)
+	}
+	if want := signCallback("s3cret", gotBody); gotSignature != want {
+		t.Errorf("signature = %q, want %q", gotSignature, want)
+	}
+}
+
+func TestNotifyCallbackRetriesFailures(t *testing.T) {
+	calls := 0
+	var gotEvent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var payload callbackPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		gotEvent = payload.Event
+	}))
+	defer srv.Close()
+	t.Setenv("CALLBACK_URL", srv.URL)
+	defer func(d time.Duration) { callbackBackoff = d }(callbackBackoff)
+	callbackBackoff = time.Millisecond
+
+	notifyCallback(context.Background(), LambdaResponse{EpisodeID: "ep1", Status: "error", ErrorMessage: "boom"})
+
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+	if gotEvent != "transcript.failed" {
+		t.Errorf("event = %q, want transcript.failed", gotEvent)
+	}
+}
+
+func TestNotifyCallbackDisabled(t *testing.T) {
+	t.Setenv("CALLBACK_URL", "")
+	// Would panic on the nil context if it tried to send anything
+	notifyCallback(nil, LambdaResponse{EpisodeID: "ep1", Status: "completed"})
+}
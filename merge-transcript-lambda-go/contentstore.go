@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Final transcripts are stored by content: the key is
+// transcripts/sha256/<hex digest>.txt and the episode records the digest as
+// transcript_sha256, so identical transcripts (a replay, or a show published
+// on two feeds) share one object. S3 checks the upload against the digest
+// and keeps it as the object's SHA-256 checksum, which the API's integrity
+// check compares with the episode's transcript_sha256. Mirrors the API's
+// transcript_store.py. TRANSCRIPT_STORAGE_CLASS sets the object's storage
+// class (default STANDARD).
+//
+// Transcripts of private podcasts arrive with encryption in the event: they
+// are stored under transcripts/private/<workspace_id>/sha256/, shared only
+// within the workspace, and encrypted with the workspace's KMS key (SSE-KMS).
+
+const (
+	contentTranscriptPrefix = "transcripts/sha256/"
+	privateTranscriptPrefix = "transcripts/private/"
+)
+
+// TranscriptEncryption is the workspace key a private podcast's transcripts are encrypted with
+type TranscriptEncryption struct {
+	WorkspaceID string `json:"workspace_id"`
+	KMSKeyID    string `json:"kms_key_id"`
+}
+
+// transcriptDigest returns the hex SHA-256 of a transcript's UTF-8 text
+func transcriptDigest(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// transcriptContentKey returns the S3 key of the transcript with a digest
+func transcriptContentKey(digest string) string {
+	return contentTranscriptPrefix + digest + ".txt"
+}
+
+// transcriptKey returns the S3 key of the transcript with a digest, in its
+// workspace's private prefix when it is encrypted
+func transcriptKey(digest string, encryption *TranscriptEncryption) string {
+	if encryption == nil {
+		return transcriptContentKey(digest)
+	}
+	return privateTranscriptPrefix + encryption.WorkspaceID + "/sha256/" + digest + ".txt"
+}
+
+// checksumHeader converts a hex digest to the base64 form S3 checksums use
+func checksumHeader(digest string) string {
+	sum, _ := hex.DecodeString(digest)
+	return base64.StdEncoding.EncodeToString(sum)
+}
+
+// storeTranscript uploads a transcript under its content key, unless an
+// identical transcript is already stored there, and returns the key and digest
+func storeTranscript(ctx context.Context, client *s3.S3, bucket, text string, encryption *TranscriptEncryption) (string, string, error) {
+	digest := transcriptDigest(text)
+	key := transcriptKey(digest, encryption)
+
+	if _, err := client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}); err == nil {
+		log.Printf("Transcript already stored at s3://%s/%s, sharing it", bucket, key)
+		return key, digest, nil
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket:         aws.String(bucket),
+		Key:            aws.String(key),
+		Body:           bytes.NewReader([]byte(text)),
+		ContentType:    aws.String("text/plain"),
+		ChecksumSHA256: aws.String(checksumHeader(digest)),
+		Metadata:       map[string]*string{"sha256": aws.String(digest)},
+	}
+	if class := os.Getenv("TRANSCRIPT_STORAGE_CLASS"); class != "" {
+		input.StorageClass = aws.String(class)
+	}
+	if encryption != nil {
+		input.ServerSideEncryption = aws.String(s3.ServerSideEncryptionAwsKms)
+		input.SSEKMSKeyId = aws.String(encryption.KMSKeyID)
+	}
+
+	log.Printf("Uploading to s3://%s/%s", bucket, key)
+	if _, err := client.PutObjectWithContext(ctx, input); err != nil {
+		return "", "", fmt.Errorf("failed to upload to S3: %w", err)
+	}
+
+	log.Printf("Successfully uploaded to %s", key)
+	return key, digest, nil
+}
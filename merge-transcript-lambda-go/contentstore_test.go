@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestTranscriptContentKey(t *testing.T) {
+	digest := transcriptDigest("hello")
+	if digest != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Fatalf("transcriptDigest() = %s", digest)
+	}
+	if key := transcriptContentKey(digest); key != "transcripts/sha256/"+digest+".txt" {
+		t.Errorf("transcriptContentKey() = %s", key)
+	}
+	if transcriptDigest("hello ") == digest {
+		t.Error("transcriptDigest() should differ for different text")
+	}
+}
+
+func TestTranscriptKey(t *testing.T) {
+	digest := transcriptDigest("hello")
+	if key := transcriptKey(digest, nil); key != transcriptContentKey(digest) {
+		t.Errorf("transcriptKey() without encryption = %s", key)
+	}
+	encryption := &TranscriptEncryption{WorkspaceID: "ws1", KMSKeyID: "alias/ws1"}
+	if key := transcriptKey(digest, encryption); key != "transcripts/private/ws1/sha256/"+digest+".txt" {
+		t.Errorf("transcriptKey() with encryption = %s", key)
+	}
+}
+
+func TestChecksumHeader(t *testing.T) {
+	// S3's x-amz-checksum-sha256 for "hello"
+	if got := checksumHeader(transcriptDigest("hello")); got != "LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=" {
+		t.Errorf("checksumHeader() = %s", got)
+	}
+}
@@ -0,0 +1,118 @@
+//go:build http
+
+package main
+
+// This file is kept identical in poll-lambda-go and merge-transcript-lambda-go.
+// Each Lambda is its own module and Docker build context, so the code is
+// copied rather than shared; `make check-lambda-copies` fails if the copies
+// differ.
+
+import (
+	"encoding/json"
+	"expvar"
+	"log"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Runtime diagnostics for the long-running HTTP server. When DEBUG_ADDR is
+// set (e.g. "localhost:6060"), a separate listener serves net/http/pprof
+// under /debug/pprof/, expvar under /debug/vars and a goroutine/job-state
+// dump at /debug/state. It is never exposed on the main port; bind it to
+// localhost or a private interface.
+
+var (
+	invocationsTotal    = expvar.NewInt("invocations_total")
+	invocationsInFlight = expvar.NewInt("invocations_in_flight")
+
+	activeJobsMu sync.Mutex
+	activeJobs   = map[int64]activeJob{}
+	nextJobID    atomic.Int64
+)
+
+type activeJob struct {
+	Description string    `json:"description"`
+	StartedAt   time.Time `json:"started_at"`
+}
+
+// trackJob records a running invocation for /debug/state; call the returned func when it ends
+func trackJob(description string) func() {
+	id := nextJobID.Add(1)
+	invocationsTotal.Add(1)
+	invocationsInFlight.Add(1)
+	activeJobsMu.Lock()
+	activeJobs[id] = activeJob{Description: description, StartedAt: time.Now()}
+	activeJobsMu.Unlock()
+
+	return func() {
+		activeJobsMu.Lock()
+		delete(activeJobs, id)
+		activeJobsMu.Unlock()
+		invocationsInFlight.Add(-1)
+	}
+}
+
+// debugStateHandler reports goroutines, memory and running jobs
+func debugStateHandler(w http.ResponseWriter, r *http.Request) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	type jobState struct {
+		activeJob
+		RunningSeconds float64 `json:"running_seconds"`
+	}
+	activeJobsMu.Lock()
+	jobs := make([]jobState, 0, len(activeJobs))
+	for _, job := range activeJobs {
+		jobs = append(jobs, jobState{job, time.Since(job.StartedAt).Seconds()})
+	}
+	activeJobsMu.Unlock()
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].StartedAt.Before(jobs[j].StartedAt) })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"goroutines": runtime.NumGoroutine(),
+		"memory": map[string]uint64{
+			"heap_alloc_bytes":  mem.HeapAlloc,
+			"heap_inuse_bytes":  mem.HeapInuse,
+			"heap_objects":      mem.HeapObjects,
+			"sys_bytes":         mem.Sys,
+			"total_alloc_bytes": mem.TotalAlloc,
+			"num_gc":            uint64(mem.NumGC),
+		},
+		"jobs": jobs,
+	})
+}
+
+// debugMux serves pprof, expvar and /debug/state
+func debugMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/state", debugStateHandler)
+	return mux
+}
+
+// startDebugServer serves diagnostics on DEBUG_ADDR, if set
+func startDebugServer() {
+	addr := os.Getenv("DEBUG_ADDR")
+	if addr == "" {
+		return
+	}
+	go func() {
+		log.Printf("Starting debug server on %s", addr)
+		if err := http.ListenAndServe(addr, debugMux()); err != nil {
+			log.Printf("Warning: debug server stopped: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Once the final transcript is stored, the per-chunk transcripts and audio
+// chunks the event lists are no longer needed. With cleanup_intermediates
+// in the event (or CLEANUP_INTERMEDIATES=true when the event doesn't say)
+// they are deleted, or with INTERMEDIATE_CLEANUP_MODE=tag re-tagged
+// artifact=merged-intermediate for the bucket's lifecycle rule to expire.
+// Cleanup never fails the merge; objects it can't remove are left to the
+// lifecycle rules for chunks and chunk transcripts. Chunk transcripts of
+// private podcasts (events with encryption) are always deleted once merged.
+
+const (
+	cleanupModeDelete = "delete"
+	cleanupModeTag    = "tag"
+
+	mergedIntermediateTagKey   = "artifact"
+	mergedIntermediateTagValue = "merged-intermediate"
+)
+
+// AudioChunk is an audio chunk as listed by the chunking Lambda
+type AudioChunk struct {
+	ChunkIndex int    `json:"chunk_index"`
+	S3Key      string `json:"s3_key"`
+}
+
+// cleanupEnabled reports whether to clean up, from the event's flag or else the environment
+func cleanupEnabled(flag *bool, encryption *TranscriptEncryption) bool {
+	if encryption != nil {
+		return true
+	}
+	if flag != nil {
+		return *flag
+	}
+	enabled, _ := strconv.ParseBool(os.Getenv("CLEANUP_INTERMEDIATES"))
+	return enabled
+}
+
+// cleanupMode returns INTERMEDIATE_CLEANUP_MODE, defaulting to delete;
+// intermediates of private podcasts are always deleted
+func cleanupMode(encryption *TranscriptEncryption) string {
+	if encryption != nil {
+		return cleanupModeDelete
+	}
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv("INTERMEDIATE_CLEANUP_MODE")))
+	if mode == cleanupModeTag {
+		return cleanupModeTag
+	}
+	if mode != "" && mode != cleanupModeDelete {
+		log.Printf("Warning: invalid INTERMEDIATE_CLEANUP_MODE %q, using %s", mode, cleanupModeDelete)
+	}
+	return cleanupModeDelete
+}
+
+// intermediateKeys lists the chunk transcripts and audio chunks of an
+// event once each. Final transcripts are never included.
+func intermediateKeys(transcripts []TranscriptChunk, chunks []AudioChunk) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	add := func(key string) {
+		if key == "" || seen[key] || strings.HasPrefix(key, contentTranscriptPrefix) ||
+			strings.HasPrefix(key, privateTranscriptPrefix) {
+			return
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	for _, chunk := range transcripts {
+		add(chunk.TranscriptS3Key)
+	}
+	for _, chunk := range chunks {
+		add(chunk.S3Key)
+	}
+	return keys
+}
+
+// cleanupIntermediates deletes or tags intermediate objects and returns
+// how many it handled and their total size
+func cleanupIntermediates(ctx context.Context, client *s3.S3, bucket string, keys []string, mode string) (int, int64) {
+	removed := 0
+	var reclaimed int64
+	for _, key := range keys {
+		head, err := client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+		if err != nil {
+			// Already gone, or unreadable; either way nothing to reclaim
+			log.Printf("Skipping cleanup of s3://%s/%s: %v", bucket, key, err)
+			continue
+		}
+
+		if mode == cleanupModeTag {
+			_, err = client.PutObjectTaggingWithContext(ctx, &s3.PutObjectTaggingInput{
+				Bucket: aws.String(bucket),
+				Key:    aws.String(key),
+				Tagging: &s3.Tagging{TagSet: []*s3.Tag{{
+					Key:   aws.String(mergedIntermediateTagKey),
+					Value: aws.String(mergedIntermediateTagValue),
+				}}},
+			})
+		} else {
+			_, err = client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+				Bucket: aws.String(bucket),
+				Key:    aws.String(key),
+			})
+		}
+		if err != nil {
+			log.Printf("Failed to clean up s3://%s/%s: %v", bucket, key, err)
+			continue
+		}
+		removed++
+		reclaimed += aws.Int64Value(head.ContentLength)
+	}
+
+	verb := "Deleted"
+	if mode == cleanupModeTag {
+		verb = "Tagged for expiry"
+	}
+	log.Printf("%s %d of %d intermediate object(s), %d bytes", verb, removed, len(keys), reclaimed)
+	return removed, reclaimed
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIntermediateKeys(t *testing.T) {
+	transcripts := []TranscriptChunk{
+		{ChunkIndex: 0, TranscriptS3Key: "transcripts/ep1/chunk_0.json"},
+		{ChunkIndex: 1, TranscriptS3Key: "transcripts/ep1/chunk_1.json"},
+		{ChunkIndex: 1, TranscriptS3Key: "transcripts/ep1/chunk_1.json"},
+		{ChunkIndex: 2},
+		{ChunkIndex: 3, TranscriptS3Key: transcriptContentKey(transcriptDigest("final"))},
+		{ChunkIndex: 4, TranscriptS3Key: transcriptKey(transcriptDigest("final"), &TranscriptEncryption{WorkspaceID: "ws1"})},
+	}
+	chunks := []AudioChunk{
+		{ChunkIndex: 0, S3Key: "chunks/ep1/chunk_0.mp3"},
+		{ChunkIndex: 1, S3Key: ""},
+	}
+	want := []string{
+		"transcripts/ep1/chunk_0.json",
+		"transcripts/ep1/chunk_1.json",
+		"chunks/ep1/chunk_0.mp3",
+	}
+	if got := intermediateKeys(transcripts, chunks); !reflect.DeepEqual(got, want) {
+		t.Errorf("intermediateKeys() = %v, want %v", got, want)
+	}
+	if got := intermediateKeys(nil, nil); len(got) != 0 {
+		t.Errorf("intermediateKeys(nil, nil) = %v", got)
+	}
+}
+
+func TestCleanupEnabled(t *testing.T) {
+	yes, no := true, false
+
+	t.Setenv("CLEANUP_INTERMEDIATES", "")
+	if cleanupEnabled(nil, nil) {
+		t.Error("cleanup should be off by default")
+	}
+	if !cleanupEnabled(&yes, nil) {
+		t.Error("the event's flag should turn cleanup on")
+	}
+
+	t.Setenv("CLEANUP_INTERMEDIATES", "true")
+	if !cleanupEnabled(nil, nil) {
+		t.Error("CLEANUP_INTERMEDIATES=true should turn cleanup on")
+	}
+	if cleanupEnabled(&no, nil) {
+		t.Error("the event's flag should override the environment")
+	}
+	if !cleanupEnabled(&no, &TranscriptEncryption{WorkspaceID: "ws1", KMSKeyID: "alias/ws1"}) {
+		t.Error("intermediates of private podcasts should always be cleaned up")
+	}
+}
+
+func TestCleanupMode(t *testing.T) {
+	tests := map[string]string{
+		"":       cleanupModeDelete,
+		"delete": cleanupModeDelete,
+		"TAG":    cleanupModeTag,
+		"bogus":  cleanupModeDelete,
+	}
+	for raw, want := range tests {
+		t.Setenv("INTERMEDIATE_CLEANUP_MODE", raw)
+		if got := cleanupMode(nil); got != want {
+			t.Errorf("cleanupMode() with %q = %s, want %s", raw, got, want)
+		}
+	}
+
+	t.Setenv("INTERMEDIATE_CLEANUP_MODE", "tag")
+	if got := cleanupMode(&TranscriptEncryption{WorkspaceID: "ws1"}); got != cleanupModeDelete {
+		t.Errorf("cleanupMode() for a private podcast = %s, want %s", got, cleanupModeDelete)
+	}
+}
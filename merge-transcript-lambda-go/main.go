@@ -3,7 +3,6 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -12,6 +11,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
@@ -20,7 +20,6 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const (
@@ -42,7 +41,8 @@ type TranscriptChunk struct {
 
 // TranscriptData is the JSON structure of a transcript file
 type TranscriptData struct {
-	Text string `json:"text"`
+	Text     string              `json:"text"`
+	Segments []TranscriptSegment `json:"segments"`
 }
 
 // LambdaEvent is the input event structure
@@ -51,62 +51,64 @@ type LambdaEvent struct {
 	TotalChunks int               `json:"total_chunks"`
 	Transcripts []TranscriptChunk `json:"transcripts"`
 	S3Bucket    string            `json:"s3_bucket"`
+	// Chunks are the audio chunks, cleaned up with the chunk transcripts (see intermediates.go)
+	Chunks               []AudioChunk `json:"chunks,omitempty"`
+	CleanupIntermediates *bool        `json:"cleanup_intermediates,omitempty"`
+	// S3Region and Storage place the episode's objects (see storage.go)
+	S3Region string           `json:"s3_region,omitempty"`
+	Storage  *StorageLocation `json:"storage,omitempty"`
+	// Encryption is set for private podcasts (see contentstore.go)
+	Encryption *TranscriptEncryption `json:"encryption,omitempty"`
 }
 
 // LambdaResponse is the output structure
 type LambdaResponse struct {
 	EpisodeID       string `json:"episode_id"`
 	TranscriptS3Key string `json:"transcript_s3_key,omitempty"`
+	TranscriptSHA   string `json:"transcript_sha256,omitempty"`
 	TotalWords      int    `json:"total_words,omitempty"`
 	Status          string `json:"status"`
 	ErrorMessage    string `json:"error_message,omitempty"`
+	// IntermediatesCleaned counts chunk objects deleted or tagged for expiry
+	IntermediatesCleaned int   `json:"intermediates_cleaned,omitempty"`
+	ReclaimedBytes       int64 `json:"reclaimed_bytes,omitempty"`
 }
 
-func init() {
-	// Skip initialization during tests
-	if os.Getenv("SKIP_INIT") == "true" {
-		return
-	}
-	// Initialize global clients once
-	initMongoClient()
-	initS3Client()
-}
-
-func initMongoClient() {
-	mongoURI := os.Getenv("MONGODB_URI")
-	if mongoURI == "" {
-		log.Fatal("MONGODB_URI environment variable not set")
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	var err error
-	mongoClient, err = mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
-	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
-	}
+var (
+	clientsOnce sync.Once
+	clientsErr  error
+)
 
-	// Ping to verify connection
-	if err = mongoClient.Ping(ctx, nil); err != nil {
-		log.Fatalf("Failed to ping MongoDB: %v", err)
+// initClients creates the clients on first use and connects to MongoDB.
+// Failing to reach MongoDB returns an error for this invocation only; the
+// next one retries instead of the execution environment being poisoned.
+func initClients(ctx context.Context) error {
+	clientsOnce.Do(func() {
+		clientsErr = initS3Client()
+	})
+	if clientsErr != nil {
+		return clientsErr
 	}
-
-	log.Println("Successfully connected to MongoDB")
+	return ensureMongoClient(ctx)
 }
 
-func initS3Client() {
-	sess := session.Must(session.NewSession(&aws.Config{
+func initS3Client() error {
+	sess, err := session.NewSession(&aws.Config{
 		Region: aws.String(os.Getenv("AWS_REGION")),
-	}))
-	s3Client = s3.New(sess)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create AWS session: %w", err)
+	}
+	awsSession = traceSession(sess)
+	s3Client = s3.New(awsSession)
+	return nil
 }
 
 // downloadTranscriptFromS3 retrieves and parses a transcript chunk
-func downloadTranscriptFromS3(ctx context.Context, bucket, key string) (*TranscriptData, error) {
+func downloadTranscriptFromS3(ctx context.Context, client *s3.S3, bucket, key string) (*TranscriptData, error) {
 	log.Printf("Downloading s3://%s/%s", bucket, key)
 
-	result, err := s3Client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+	result, err := client.GetObjectWithContext(ctx, &s3.GetObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
 	})
@@ -129,25 +131,6 @@ func downloadTranscriptFromS3(ctx context.Context, bucket, key string) (*Transcr
 	return &transcriptData, nil
 }
 
-// uploadToS3 uploads content to S3
-func uploadToS3(ctx context.Context, bucket, key, content, contentType string) error {
-	log.Printf("Uploading to s3://%s/%s", bucket, key)
-
-	_, err := s3Client.PutObjectWithContext(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(bucket),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader([]byte(content)),
-		ContentType: aws.String(contentType),
-	})
-
-	if err != nil {
-		return fmt.Errorf("failed to upload to S3: %w", err)
-	}
-
-	log.Printf("Successfully uploaded to %s", key)
-	return nil
-}
-
 // formatTimestamp converts seconds to [HH:MM:SS] format
 func formatTimestamp(seconds int) string {
 	hours := seconds / 3600
@@ -157,7 +140,7 @@ func formatTimestamp(seconds int) string {
 }
 
 // mergeTranscripts combines transcript chunks into a single formatted transcript
-func mergeTranscripts(ctx context.Context, transcripts []TranscriptChunk, s3Bucket string, addTimestamps bool) (string, int, error) {
+func mergeTranscripts(ctx context.Context, client *s3.S3, transcripts []TranscriptChunk, s3Bucket string, addTimestamps bool) (string, int, *TranscriptQuality, error) {
 	// Sort transcripts by chunk index
 	sort.Slice(transcripts, func(i, j int) bool {
 		return transcripts[i].ChunkIndex < transcripts[j].ChunkIndex
@@ -165,16 +148,18 @@ func mergeTranscripts(ctx context.Context, transcripts []TranscriptChunk, s3Buck
 
 	var builder strings.Builder
 	totalWords := 0
+	var tally qualityTally
 	lastTimestampSeconds := -timestampIntervalSeconds // Force timestamp at the beginning
 
 	for _, chunk := range transcripts {
 		log.Printf("Processing chunk %d from %s", chunk.ChunkIndex, chunk.TranscriptS3Key)
 
 		// Download and parse transcript chunk
-		transcriptData, err := downloadTranscriptFromS3(ctx, s3Bucket, chunk.TranscriptS3Key)
+		transcriptData, err := downloadTranscriptFromS3(ctx, client, s3Bucket, chunk.TranscriptS3Key)
 		if err != nil {
-			return "", 0, fmt.Errorf("chunk %d: %w", chunk.ChunkIndex, err)
+			return "", 0, nil, fmt.Errorf("chunk %d: %w", chunk.ChunkIndex, err)
 		}
+		tally.add(transcriptData.Segments)
 
 		text := strings.TrimSpace(transcriptData.Text)
 		if text == "" {
@@ -203,7 +188,7 @@ func mergeTranscripts(ctx context.Context, transcripts []TranscriptChunk, s3Buck
 	mergedText := strings.TrimSpace(builder.String())
 	log.Printf("Merged transcript: %d characters, %d words", len(mergedText), totalWords)
 
-	return mergedText, totalWords, nil
+	return mergedText, totalWords, tally.quality(), nil
 }
 
 // updateEpisodeStep updates the processing step in MongoDB
@@ -229,21 +214,21 @@ func updateEpisodeStep(ctx context.Context, episodeID, step string) {
 }
 
 // updateEpisodeInMongoDB updates the episode document with completion status
-func updateEpisodeInMongoDB(ctx context.Context, episodeID, transcriptS3Key string) error {
+func updateEpisodeInMongoDB(ctx context.Context, episodeID, transcriptS3Key, transcriptSHA string, storage *StorageLocation, quality *TranscriptQuality) error {
 	db := mongoClient.Database("")
 	episodesCollection := db.Collection("episodes")
 
 	result, err := episodesCollection.UpdateOne(
 		ctx,
 		bson.M{"episode_id": episodeID},
-		bson.M{
-			"$set": bson.M{
-				"transcript_status": "completed",
-				"processing_step":   "completed",
-				"transcript_s3_key": transcriptS3Key,
-				"processed_at":      time.Now().UTC(),
-			},
-		},
+		completionUpdate(bson.M{
+			"transcript_status": "completed",
+			"processing_step":   "completed",
+			"transcript_s3_key": transcriptS3Key,
+			"transcript_sha256": transcriptSHA,
+			"storage":           storage,
+			"processed_at":      time.Now().UTC(),
+		}, quality),
 	)
 
 	if err != nil {
@@ -283,8 +268,15 @@ func updateEpisodeError(ctx context.Context, episodeID, errorMessage string) {
 	}
 }
 
-// HandleRequest is the Lambda handler
+// HandleRequest is the Lambda handler; results are also reported to CALLBACK_URL
 func HandleRequest(ctx context.Context, event LambdaEvent) (LambdaResponse, error) {
+	response := mergeEpisode(ctx, event)
+	notifyCallback(ctx, response)
+	return response, nil
+}
+
+// mergeEpisode merges an episode's chunk transcripts into its final transcript
+func mergeEpisode(ctx context.Context, event LambdaEvent) LambdaResponse {
 	log.Printf("Received event: %+v", event)
 
 	// Validate required parameters
@@ -293,7 +285,7 @@ func HandleRequest(ctx context.Context, event LambdaEvent) (LambdaResponse, erro
 			EpisodeID:    event.EpisodeID,
 			Status:       "error",
 			ErrorMessage: "Missing required parameter: episode_id",
-		}, nil
+		}
 	}
 
 	if len(event.Transcripts) == 0 {
@@ -301,7 +293,7 @@ func HandleRequest(ctx context.Context, event LambdaEvent) (LambdaResponse, erro
 			EpisodeID:    event.EpisodeID,
 			Status:       "error",
 			ErrorMessage: "No transcripts provided",
-		}, nil
+		}
 	}
 
 	s3Bucket := event.S3Bucket
@@ -314,7 +306,7 @@ func HandleRequest(ctx context.Context, event LambdaEvent) (LambdaResponse, erro
 			EpisodeID:    event.EpisodeID,
 			Status:       "error",
 			ErrorMessage: "S3 bucket not specified in event or environment variables",
-		}, nil
+		}
 	}
 
 	// Validate chunk count
@@ -322,9 +314,6 @@ func HandleRequest(ctx context.Context, event LambdaEvent) (LambdaResponse, erro
 		log.Printf("Warning: Expected %d chunks but received %d", event.TotalChunks, len(event.Transcripts))
 	}
 
-	// Update episode status to merging
-	updateEpisodeStep(ctx, event.EpisodeID, "merging")
-
 	// Check for missing chunks
 	chunkIndices := make(map[int]bool)
 	for _, chunk := range event.Transcripts {
@@ -339,12 +328,25 @@ func HandleRequest(ctx context.Context, event LambdaEvent) (LambdaResponse, erro
 				EpisodeID:    event.EpisodeID,
 				Status:       "error",
 				ErrorMessage: errorMsg,
-			}, nil
+			}
+		}
+	}
+
+	if err := initClients(ctx); err != nil {
+		log.Printf("Client initialization failed: %v", err)
+		return LambdaResponse{
+			EpisodeID:    event.EpisodeID,
+			Status:       "error",
+			ErrorMessage: fmt.Sprintf("Service unavailable: %v", err),
 		}
 	}
 
+	// Update episode status to merging
+	updateEpisodeStep(ctx, event.EpisodeID, "merging")
+
 	// Merge transcripts
-	mergedText, totalWords, err := mergeTranscripts(ctx, event.Transcripts, s3Bucket, true)
+	client := s3ClientFor(event.S3Region)
+	mergedText, totalWords, quality, err := mergeTranscripts(ctx, client, event.Transcripts, s3Bucket, true)
 	if err != nil {
 		errorMessage := fmt.Sprintf("Error merging transcripts: %v", err)
 		log.Println(errorMessage)
@@ -353,12 +355,12 @@ func HandleRequest(ctx context.Context, event LambdaEvent) (LambdaResponse, erro
 			EpisodeID:    event.EpisodeID,
 			Status:       "error",
 			ErrorMessage: errorMessage,
-		}, nil
+		}
 	}
 
-	// Upload final transcript to S3
-	finalTranscriptKey := fmt.Sprintf("transcripts/%s/final.txt", event.EpisodeID)
-	if err := uploadToS3(ctx, s3Bucket, finalTranscriptKey, mergedText, "text/plain"); err != nil {
+	// Upload final transcript to S3, under its content key (see contentstore.go)
+	finalTranscriptKey, transcriptSHA, err := storeTranscript(ctx, client, s3Bucket, mergedText, event.Encryption)
+	if err != nil {
 		errorMessage := fmt.Sprintf("Failed to upload final transcript: %v", err)
 		log.Println(errorMessage)
 		updateEpisodeError(ctx, event.EpisodeID, errorMessage)
@@ -366,11 +368,13 @@ func HandleRequest(ctx context.Context, event LambdaEvent) (LambdaResponse, erro
 			EpisodeID:    event.EpisodeID,
 			Status:       "error",
 			ErrorMessage: errorMessage,
-		}, nil
+		}
 	}
 
 	// Update MongoDB
-	if err := updateEpisodeInMongoDB(ctx, event.EpisodeID, finalTranscriptKey); err != nil {
+	recorded := true
+	if err := updateEpisodeInMongoDB(ctx, event.EpisodeID, finalTranscriptKey, transcriptSHA, episodeStorage(event.Storage, s3Bucket), quality); err != nil {
+		recorded = false
 		errorMessage := fmt.Sprintf("Failed to update MongoDB: %v", err)
 		log.Println(errorMessage)
 		// Don't mark as error since transcript was successfully uploaded
@@ -379,12 +383,21 @@ func HandleRequest(ctx context.Context, event LambdaEvent) (LambdaResponse, erro
 
 	log.Printf("Successfully merged transcripts for episode %s", event.EpisodeID)
 
-	return LambdaResponse{
+	response := LambdaResponse{
 		EpisodeID:       event.EpisodeID,
 		TranscriptS3Key: finalTranscriptKey,
+		TranscriptSHA:   transcriptSHA,
 		TotalWords:      totalWords,
 		Status:          "completed",
-	}, nil
+	}
+
+	// Keep the chunks while the episode doesn't record the transcript, so a retry can merge again
+	if recorded && cleanupEnabled(event.CleanupIntermediates, event.Encryption) {
+		keys := intermediateKeys(event.Transcripts, event.Chunks)
+		mode := cleanupMode(event.Encryption)
+		response.IntermediatesCleaned, response.ReclaimedBytes = cleanupIntermediates(ctx, client, s3Bucket, keys, mode)
+	}
+	return response
 }
 
 func main() {
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"math"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Whisper reports, for each segment, the average log-probability of its
+// tokens, the probability that it is silence and the compression ratio of
+// its text. The merge stores a summary of them as the episode's
+// transcript_quality, and the API flags transcripts scoring below
+// TRANSCRIPT_QUALITY_THRESHOLD as likely to need re-transcription. Segments
+// without an avg_logprob (plain-text Whisper responses) aren't scored; an
+// episode with none has no transcript_quality.
+
+// Whisper's own defaults for retrying a segment at a higher temperature
+const (
+	lowConfidenceLogprob = -1.0
+	maxCompressionRatio  = 2.4
+	silenceNoSpeechProb  = 0.6
+)
+
+// TranscriptSegment is a segment of a chunk transcript
+type TranscriptSegment struct {
+	Start            float64  `json:"start"`
+	End              float64  `json:"end"`
+	AvgLogprob       *float64 `json:"avg_logprob"`
+	NoSpeechProb     *float64 `json:"no_speech_prob"`
+	CompressionRatio *float64 `json:"compression_ratio"`
+}
+
+// TranscriptQuality is an episode's transcript_quality
+type TranscriptQuality struct {
+	Score                 float64 `bson:"score"` // Duration-weighted mean token probability, 0-1
+	AvgLogprob            float64 `bson:"avg_logprob"`
+	Segments              int     `bson:"segments"`
+	LowConfidenceSegments int     `bson:"low_confidence_segments"`
+}
+
+// qualityTally accumulates segments across an episode's chunks
+type qualityTally struct {
+	seconds, confidence, logprob float64
+	segments, lowConfidence      int
+}
+
+func (t *qualityTally) add(segments []TranscriptSegment) {
+	for _, seg := range segments {
+		if seg.AvgLogprob == nil {
+			continue
+		}
+		logprob := *seg.AvgLogprob
+		// Whisper drops the text of segments it judges silent
+		if seg.NoSpeechProb != nil && *seg.NoSpeechProb > silenceNoSpeechProb && logprob < lowConfidenceLogprob {
+			continue
+		}
+
+		// Very short segments still count a little
+		seconds := math.Max(seg.End-seg.Start, 0.1)
+		confidence := math.Exp(logprob)
+		repetitive := seg.CompressionRatio != nil && *seg.CompressionRatio > maxCompressionRatio
+		if repetitive {
+			// Repetition loops are hallucinated, however confident
+			confidence = 0
+		}
+		if repetitive || logprob < lowConfidenceLogprob {
+			t.lowConfidence++
+		}
+
+		t.seconds += seconds
+		t.confidence += confidence * seconds
+		t.logprob += logprob * seconds
+		t.segments++
+	}
+}
+
+// quality returns the episode's summary, or nil if no segment was scored
+func (t *qualityTally) quality() *TranscriptQuality {
+	if t.segments == 0 {
+		return nil
+	}
+	round := func(v float64) float64 { return math.Round(v*1000) / 1000 }
+	return &TranscriptQuality{
+		Score:                 round(t.confidence / t.seconds),
+		AvgLogprob:            round(t.logprob / t.seconds),
+		Segments:              t.segments,
+		LowConfidenceSegments: t.lowConfidence,
+	}
+}
+
+// completionUpdate is the update of a completed episode: set, plus its
+// transcript_quality, or unsetting a previous transcription's
+func completionUpdate(set bson.M, quality *TranscriptQuality) bson.M {
+	if quality == nil {
+		return bson.M{"$set": set, "$unset": bson.M{"transcript_quality": ""}}
+	}
+	set["transcript_quality"] = quality
+	return bson.M{"$set": set}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const chunkTranscriptJSON = `{
+	"text": "Welcome back. Thanks thanks thanks thanks.",
+	"segments": [
+		{"id": 0, "start": 0, "end": 6, "text": "Welcome back.", "avg_logprob": -0.2, "no_speech_prob": 0.01, "compression_ratio": 1.1},
+		{"id": 1, "start": 6, "end": 8, "text": "", "avg_logprob": -1.5, "no_speech_prob": 0.9, "compression_ratio": 0.5},
+		{"id": 2, "start": 8, "end": 10, "text": "Thanks thanks thanks thanks.", "avg_logprob": -0.1, "no_speech_prob": 0.02, "compression_ratio": 3.2}
+	]
+}`
+
+func TestTranscriptQuality(t *testing.T) {
+	var data TranscriptData
+	if err := json.Unmarshal([]byte(chunkTranscriptJSON), &data); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	var tally qualityTally
+	tally.add(data.Segments)
+	// A chunk transcribed to plain text has nothing to score
+	tally.add([]TranscriptSegment{{Start: 0, End: 30}})
+
+	got := tally.quality()
+	if got == nil {
+		t.Fatal("quality() = nil, want a score")
+	}
+	// The silent segment is ignored and the repetitive one scores 0:
+	// (exp(-0.2)*6 + 0*2) / 8
+	if got.Score != 0.614 {
+		t.Errorf("Score = %v, want 0.614", got.Score)
+	}
+	if got.AvgLogprob != -0.175 {
+		t.Errorf("AvgLogprob = %v, want -0.175", got.AvgLogprob)
+	}
+	if got.Segments != 2 || got.LowConfidenceSegments != 1 {
+		t.Errorf("Segments = %d, LowConfidenceSegments = %d, want 2 and 1", got.Segments, got.LowConfidenceSegments)
+	}
+}
+
+func TestTranscriptQualityWithoutScores(t *testing.T) {
+	var tally qualityTally
+	tally.add([]TranscriptSegment{{Start: 0, End: 30}})
+	if got := tally.quality(); got != nil {
+		t.Errorf("quality() = %+v, want nil", got)
+	}
+}
+
+func TestCompletionUpdate(t *testing.T) {
+	update := completionUpdate(bson.M{"transcript_status": "completed"}, nil)
+	if _, ok := update["$unset"]; !ok {
+		t.Errorf("completionUpdate(nil) = %v, want transcript_quality unset", update)
+	}
+
+	update = completionUpdate(bson.M{"transcript_status": "completed"}, &TranscriptQuality{Score: 0.8})
+	set := update["$set"].(bson.M)
+	if _, ok := set["transcript_quality"]; !ok {
+		t.Errorf("completionUpdate() = %v, want transcript_quality set", update)
+	}
+	if _, ok := update["$unset"]; ok {
+		t.Errorf("completionUpdate() = %v, want nothing unset", update)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+// This file is kept identical in poll-lambda-go and merge-transcript-lambda-go.
+// Each Lambda is its own module and Docker build context, so the code is
+// copied rather than shared; `make check-lambda-copies` fails if the copies
+// differ.
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+type fakeSecretsManager struct {
+	secretsmanageriface.SecretsManagerAPI
+	value string
+	err   error
+	calls int
+}
+
+func (f *fakeSecretsManager) GetSecretValueWithContext(_ aws.Context, _ *secretsmanager.GetSecretValueInput, _ ...request.Option) (*secretsmanager.GetSecretValueOutput, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(f.value)}, nil
+}
+
+type fakeSSM struct {
+	ssmiface.SSMAPI
+	value string
+}
+
+func (f *fakeSSM) GetParameterWithContext(_ aws.Context, input *ssm.GetParameterInput, _ ...request.Option) (*ssm.GetParameterOutput, error) {
+	if !aws.BoolValue(input.WithDecryption) {
+		return nil, errors.New("expected decryption")
+	}
+	return &ssm.GetParameterOutput{Parameter: &ssm.Parameter{Value: aws.String(f.value)}}, nil
+}
+
+func newTestSecretsProvider(sm *fakeSecretsManager, params *fakeSSM, now *time.Time) *secretsProvider {
+	p := newSecretsProvider()
+	p.secretsManager, p.ssm = sm, params
+	p.now = func() time.Time { return *now }
+	return p
+}
+
+func TestSecretsProviderPlainEnv(t *testing.T) {
+	t.Setenv("TEST_SETTING", "plain")
+	now := time.Now()
+	p := newTestSecretsProvider(&fakeSecretsManager{}, &fakeSSM{}, &now)
+
+	value, err := p.get(context.Background(), "TEST_SETTING")
+	if err != nil || value != "plain" {
+		t.Errorf("get() = %q, %v, want plain", value, err)
+	}
+}
+
+func TestSecretsProviderSecretsManagerJSONKey(t *testing.T) {
+	t.Setenv("TEST_SETTING_SECRET_ARN", "arn:aws:secretsmanager:us-east-1:123:secret:db#uri")
+	now := time.Now()
+	sm := &fakeSecretsManager{value: `{"uri": "mongodb://secret", "user": "x"}`}
+	p := newTestSecretsProvider(sm, &fakeSSM{}, &now)
+
+	value, err := p.get(context.Background(), "TEST_SETTING")
+	if err != nil || value != "mongodb://secret" {
+		t.Errorf("get() = %q, %v, want mongodb://secret", value, err)
+	}
+}
+
+func TestSecretsProviderSSM(t *testing.T) {
+	t.Setenv("TEST_SETTING_SSM_PARAMETER", "/podcasts/test")
+	now := time.Now()
+	p := newTestSecretsProvider(&fakeSecretsManager{}, &fakeSSM{value: "from-ssm"}, &now)
+
+	value, err := p.get(context.Background(), "TEST_SETTING")
+	if err != nil || value != "from-ssm" {
+		t.Errorf("get() = %q, %v, want from-ssm", value, err)
+	}
+}
+
+func TestSecretsProviderCachesUntilTTL(t *testing.T) {
+	t.Setenv("TEST_SETTING_SECRET_ARN", "db-secret")
+	now := time.Now()
+	sm := &fakeSecretsManager{value: "v1"}
+	p := newTestSecretsProvider(sm, &fakeSSM{}, &now)
+	ctx := context.Background()
+
+	p.get(ctx, "TEST_SETTING")
+	sm.value = "v2"
+	if value, _ := p.get(ctx, "TEST_SETTING"); value != "v1" || sm.calls != 1 {
+		t.Errorf("within TTL got %q after %d calls, want cached v1 after 1 call", value, sm.calls)
+	}
+
+	now = now.Add(p.ttl + time.Second)
+	if value, _ := p.get(ctx, "TEST_SETTING"); value != "v2" {
+		t.Errorf("after TTL got %q, want rotated v2", value)
+	}
+
+	// An outage keeps the last good value
+	sm.err = errors.New("throttled")
+	now = now.Add(p.ttl + time.Second)
+	if value, err := p.get(ctx, "TEST_SETTING"); err != nil || value != "v2" {
+		t.Errorf("during outage got %q, %v, want cached v2", value, err)
+	}
+}
+
+func TestSecretsProviderFetchError(t *testing.T) {
+	t.Setenv("TEST_SETTING_SECRET_ARN", "db-secret#missing")
+	now := time.Now()
+	p := newTestSecretsProvider(&fakeSecretsManager{value: `{"uri": "x"}`}, &fakeSSM{}, &now)
+
+	if _, err := p.get(context.Background(), "TEST_SETTING"); err == nil {
+		t.Error("get() with a missing JSON key should fail")
+	}
+}
+
+func TestSecretsProviderSessionError(t *testing.T) {
+	t.Setenv("TEST_SETTING_SSM_PARAMETER", "/podcasts/test")
+	t.Setenv("AWS_STS_REGIONAL_ENDPOINTS", "bogus")
+	p := newSecretsProvider()
+
+	if _, err := p.get(context.Background(), "TEST_SETTING"); err == nil {
+		t.Error("get() without an AWS session should fail")
+	}
+}
@@ -3,16 +3,18 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -21,7 +23,6 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const (
@@ -42,7 +43,8 @@ type TranscriptChunk struct {
 
 // TranscriptData is the JSON structure of a transcript file
 type TranscriptData struct {
-	Text string `json:"text"`
+	Text     string              `json:"text"`
+	Segments []TranscriptSegment `json:"segments"`
 }
 
 // LambdaEvent is the input event structure
@@ -51,48 +53,48 @@ type LambdaEvent struct {
 	TotalChunks int               `json:"total_chunks"`
 	Transcripts []TranscriptChunk `json:"transcripts"`
 	S3Bucket    string            `json:"s3_bucket"`
+	// Chunks are the audio chunks, cleaned up with the chunk transcripts (see intermediates.go)
+	Chunks               []AudioChunk `json:"chunks,omitempty"`
+	CleanupIntermediates *bool        `json:"cleanup_intermediates,omitempty"`
+	// S3Region and Storage place the episode's objects (see storage.go)
+	S3Region string           `json:"s3_region,omitempty"`
+	Storage  *StorageLocation `json:"storage,omitempty"`
+	// Encryption is set for private podcasts (see contentstore.go)
+	Encryption *TranscriptEncryption `json:"encryption,omitempty"`
 }
 
 // LambdaResponse is the output structure
 type LambdaResponse struct {
 	EpisodeID       string `json:"episode_id"`
 	TranscriptS3Key string `json:"transcript_s3_key,omitempty"`
+	TranscriptSHA   string `json:"transcript_sha256,omitempty"`
 	TotalWords      int    `json:"total_words,omitempty"`
 	Status          string `json:"status"`
 	ErrorMessage    string `json:"error_message,omitempty"`
+	// IntermediatesCleaned counts chunk objects deleted or tagged for expiry
+	IntermediatesCleaned int   `json:"intermediates_cleaned,omitempty"`
+	ReclaimedBytes       int64 `json:"reclaimed_bytes,omitempty"`
 }
 
-func init() {
-	if os.Getenv("SKIP_INIT") == "true" {
-		return
-	}
-	initMongoClient()
-	initS3Client()
-}
-
-func initMongoClient() {
-	mongoURI := os.Getenv("MONGODB_URI")
-	if mongoURI == "" {
-		log.Fatal("MONGODB_URI environment variable not set")
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	var err error
-	mongoClient, err = mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
-	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
-	}
+var (
+	clientsOnce sync.Once
+	clientsErr  error
+)
 
-	if err = mongoClient.Ping(ctx, nil); err != nil {
-		log.Fatalf("Failed to ping MongoDB: %v", err)
+// initClients creates the clients on first use and connects to MongoDB.
+// Failing to reach MongoDB returns an error for this invocation only; the
+// next one retries instead of the execution environment being poisoned.
+func initClients(ctx context.Context) error {
+	clientsOnce.Do(func() {
+		clientsErr = initS3Client(ctx)
+	})
+	if clientsErr != nil {
+		return clientsErr
 	}
-
-	log.Println("Successfully connected to MongoDB")
+	return ensureMongoClient(ctx)
 }
 
-func initS3Client() {
+func initS3Client(ctx context.Context) error {
 	awsConfig := &aws.Config{
 		Region: aws.String(os.Getenv("AWS_REGION")),
 	}
@@ -104,20 +106,32 @@ func initS3Client() {
 	}
 
 	// Use explicit credentials if provided (for Minio)
-	if accessKey := os.Getenv("AWS_ACCESS_KEY_ID"); accessKey != "" {
-		secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	accessKey, err := secrets.get(ctx, "AWS_ACCESS_KEY_ID")
+	if err != nil {
+		return err
+	}
+	if accessKey != "" {
+		secretKey, err := secrets.get(ctx, "AWS_SECRET_ACCESS_KEY")
+		if err != nil {
+			return err
+		}
 		awsConfig.Credentials = credentials.NewStaticCredentials(accessKey, secretKey, "")
 	}
 
-	sess := session.Must(session.NewSession(awsConfig))
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create AWS session: %w", err)
+	}
+	awsSession = sess
 	s3Client = s3.New(sess)
 	log.Printf("S3 client initialized with endpoint: %s", os.Getenv("AWS_ENDPOINT_URL"))
+	return nil
 }
 
-func downloadTranscriptFromS3(ctx context.Context, bucket, key string) (*TranscriptData, error) {
+func downloadTranscriptFromS3(ctx context.Context, client *s3.S3, bucket, key string) (*TranscriptData, error) {
 	log.Printf("Downloading s3://%s/%s", bucket, key)
 
-	result, err := s3Client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+	result, err := client.GetObjectWithContext(ctx, &s3.GetObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
 	})
@@ -140,24 +154,6 @@ func downloadTranscriptFromS3(ctx context.Context, bucket, key string) (*Transcr
 	return &transcriptData, nil
 }
 
-func uploadToS3(ctx context.Context, bucket, key, content, contentType string) error {
-	log.Printf("Uploading to s3://%s/%s", bucket, key)
-
-	_, err := s3Client.PutObjectWithContext(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(bucket),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader([]byte(content)),
-		ContentType: aws.String(contentType),
-	})
-
-	if err != nil {
-		return fmt.Errorf("failed to upload to S3: %w", err)
-	}
-
-	log.Printf("Successfully uploaded to %s", key)
-	return nil
-}
-
 func formatTimestamp(seconds int) string {
 	hours := seconds / 3600
 	minutes := (seconds % 3600) / 60
@@ -165,22 +161,24 @@ func formatTimestamp(seconds int) string {
 	return fmt.Sprintf("[%02d:%02d:%02d]", hours, minutes, secs)
 }
 
-func mergeTranscripts(ctx context.Context, transcripts []TranscriptChunk, s3Bucket string, addTimestamps bool) (string, int, error) {
+func mergeTranscripts(ctx context.Context, client *s3.S3, transcripts []TranscriptChunk, s3Bucket string, addTimestamps bool) (string, int, *TranscriptQuality, error) {
 	sort.Slice(transcripts, func(i, j int) bool {
 		return transcripts[i].ChunkIndex < transcripts[j].ChunkIndex
 	})
 
 	var builder strings.Builder
 	totalWords := 0
+	var tally qualityTally
 	lastTimestampSeconds := -timestampIntervalSeconds
 
 	for _, chunk := range transcripts {
 		log.Printf("Processing chunk %d from %s", chunk.ChunkIndex, chunk.TranscriptS3Key)
 
-		transcriptData, err := downloadTranscriptFromS3(ctx, s3Bucket, chunk.TranscriptS3Key)
+		transcriptData, err := downloadTranscriptFromS3(ctx, client, s3Bucket, chunk.TranscriptS3Key)
 		if err != nil {
-			return "", 0, fmt.Errorf("chunk %d: %w", chunk.ChunkIndex, err)
+			return "", 0, nil, fmt.Errorf("chunk %d: %w", chunk.ChunkIndex, err)
 		}
+		tally.add(transcriptData.Segments)
 
 		text := strings.TrimSpace(transcriptData.Text)
 		if text == "" {
@@ -203,23 +201,23 @@ func mergeTranscripts(ctx context.Context, transcripts []TranscriptChunk, s3Buck
 	mergedText := strings.TrimSpace(builder.String())
 	log.Printf("Merged transcript: %d characters, %d words", len(mergedText), totalWords)
 
-	return mergedText, totalWords, nil
+	return mergedText, totalWords, tally.quality(), nil
 }
 
-func updateEpisodeInMongoDB(ctx context.Context, episodeID, transcriptS3Key string) error {
+func updateEpisodeInMongoDB(ctx context.Context, episodeID, transcriptS3Key, transcriptSHA string, storage *StorageLocation, quality *TranscriptQuality) error {
 	db := mongoClient.Database("podcast_db")
 	episodesCollection := db.Collection("episodes")
 
 	result, err := episodesCollection.UpdateOne(
 		ctx,
 		bson.M{"episode_id": episodeID},
-		bson.M{
-			"$set": bson.M{
-				"transcript_status": "completed",
-				"transcript_s3_key": transcriptS3Key,
-				"processed_at":      time.Now().UTC(),
-			},
-		},
+		completionUpdate(bson.M{
+			"transcript_status": "completed",
+			"transcript_s3_key": transcriptS3Key,
+			"transcript_sha256": transcriptSHA,
+			"storage":           storage,
+			"processed_at":      time.Now().UTC(),
+		}, quality),
 	)
 
 	if err != nil {
@@ -310,7 +308,17 @@ func handleRequest(ctx context.Context, event LambdaEvent) LambdaResponse {
 		}
 	}
 
-	mergedText, totalWords, err := mergeTranscripts(ctx, event.Transcripts, s3Bucket, true)
+	if err := initClients(ctx); err != nil {
+		log.Printf("Client initialization failed: %v", err)
+		return LambdaResponse{
+			EpisodeID:    event.EpisodeID,
+			Status:       "error",
+			ErrorMessage: fmt.Sprintf("Service unavailable: %v", err),
+		}
+	}
+
+	client := s3ClientFor(event.S3Region)
+	mergedText, totalWords, quality, err := mergeTranscripts(ctx, client, event.Transcripts, s3Bucket, true)
 	if err != nil {
 		errorMessage := fmt.Sprintf("Error merging transcripts: %v", err)
 		log.Println(errorMessage)
@@ -322,8 +330,8 @@ func handleRequest(ctx context.Context, event LambdaEvent) LambdaResponse {
 		}
 	}
 
-	finalTranscriptKey := fmt.Sprintf("transcripts/%s/final.txt", event.EpisodeID)
-	if err := uploadToS3(ctx, s3Bucket, finalTranscriptKey, mergedText, "text/plain"); err != nil {
+	finalTranscriptKey, transcriptSHA, err := storeTranscript(ctx, client, s3Bucket, mergedText, event.Encryption)
+	if err != nil {
 		errorMessage := fmt.Sprintf("Failed to upload final transcript: %v", err)
 		log.Println(errorMessage)
 		updateEpisodeError(ctx, event.EpisodeID, errorMessage)
@@ -334,7 +342,9 @@ func handleRequest(ctx context.Context, event LambdaEvent) LambdaResponse {
 		}
 	}
 
-	if err := updateEpisodeInMongoDB(ctx, event.EpisodeID, finalTranscriptKey); err != nil {
+	recorded := true
+	if err := updateEpisodeInMongoDB(ctx, event.EpisodeID, finalTranscriptKey, transcriptSHA, episodeStorage(event.Storage, s3Bucket), quality); err != nil {
+		recorded = false
 		errorMessage := fmt.Sprintf("Failed to update MongoDB: %v", err)
 		log.Println(errorMessage)
 		log.Println("Warning: Transcript uploaded but MongoDB update failed")
@@ -342,12 +352,51 @@ func handleRequest(ctx context.Context, event LambdaEvent) LambdaResponse {
 
 	log.Printf("Successfully merged transcripts for episode %s", event.EpisodeID)
 
-	return LambdaResponse{
+	response := LambdaResponse{
 		EpisodeID:       event.EpisodeID,
 		TranscriptS3Key: finalTranscriptKey,
+		TranscriptSHA:   transcriptSHA,
 		TotalWords:      totalWords,
 		Status:          "completed",
 	}
+
+	if recorded && cleanupEnabled(event.CleanupIntermediates, event.Encryption) {
+		keys := intermediateKeys(event.Transcripts, event.Chunks)
+		mode := cleanupMode(event.Encryption)
+		response.IntermediatesCleaned, response.ReclaimedBytes = cleanupIntermediates(ctx, client, s3Bucket, keys, mode)
+	}
+	return response
+}
+
+// defaultMaxRequestBodyBytes caps /invoke bodies unless MAX_REQUEST_BODY_BYTES is set (0 = no limit)
+const defaultMaxRequestBodyBytes = 32 << 20
+
+// readRequestBody reads the body up to the configured limit; ok is false once an error response is sent
+func readRequestBody(w http.ResponseWriter, r *http.Request) (body []byte, ok bool) {
+	limit := int64(defaultMaxRequestBodyBytes)
+	if raw := os.Getenv("MAX_REQUEST_BODY_BYTES"); raw != "" {
+		if parsed, err := strconv.ParseInt(raw, 10, 64); err == nil && parsed >= 0 {
+			limit = parsed
+		} else {
+			log.Printf("Warning: invalid MAX_REQUEST_BODY_BYTES %q, using %d", raw, limit)
+		}
+	}
+	if limit > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+	}
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			sendError(w, fmt.Sprintf("Request body exceeds %d bytes", tooLarge.Limit), http.StatusRequestEntityTooLarge)
+			return nil, false
+		}
+		sendError(w, "Failed to read request body", http.StatusBadRequest)
+		return nil, false
+	}
+	return body, true
 }
 
 func healthHandler(w http.ResponseWriter, r *http.Request) {
@@ -364,12 +413,10 @@ func invokeHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		sendError(w, "Failed to read request body", http.StatusBadRequest)
+	body, ok := readRequestBody(w, r)
+	if !ok {
 		return
 	}
-	defer r.Body.Close()
 
 	log.Printf("Received invoke request: %s", string(body))
 
@@ -381,6 +428,7 @@ func invokeHandler(w http.ResponseWriter, r *http.Request) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
+	defer trackJob(fmt.Sprintf("merge episode %s (%d chunks)", event.EpisodeID, len(event.Transcripts)))()
 
 	response := handleRequest(ctx, event)
 
@@ -407,11 +455,14 @@ func main() {
 		port = "8004"
 	}
 
-	http.HandleFunc("/health", healthHandler)
-	http.HandleFunc("/invoke", invokeHandler)
+	// Own mux so pprof/expvar (registered on the default mux) stay off this port
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", healthHandler)
+	mux.HandleFunc("/invoke", invokeHandler)
+	startDebugServer()
 
 	log.Printf("Starting merge-lambda HTTP server on port %s", port)
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
+	if err := http.ListenAndServe(":"+port, mux); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
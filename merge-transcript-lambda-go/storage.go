@@ -0,0 +1,61 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Episodes of a podcast pinned to a storage location (an EU bucket, say)
+// arrive with that location's bucket and region as s3_bucket and s3_region,
+// and the location itself as storage (see poll-lambda-go's storage.go).
+// Chunks and the final transcript are read and written in that region, and
+// the episode records where its transcript went as its "storage" field,
+// which the API reads transcripts by. Unpinned episodes have a null storage.
+
+// StorageLocation is a named set of buckets in one region
+type StorageLocation struct {
+	Location    string `bson:"location,omitempty" json:"location,omitempty"`
+	Region      string `bson:"region" json:"region"`
+	Bucket      string `bson:"bucket" json:"bucket"`
+	AudioBucket string `bson:"audio_bucket,omitempty" json:"audio_bucket,omitempty"`
+}
+
+// awsSession is the session s3Client was created from
+var awsSession *session.Session
+
+var (
+	regionClientsMu sync.Mutex
+	regionClients   = map[string]*s3.S3{}
+)
+
+// s3ClientFor returns an S3 client for a region; s3Client serves its own
+// region and events that don't name one
+func s3ClientFor(region string) *s3.S3 {
+	if region == "" || awsSession == nil || region == aws.StringValue(s3Client.Config.Region) {
+		return s3Client
+	}
+	regionClientsMu.Lock()
+	defer regionClientsMu.Unlock()
+	if client, ok := regionClients[region]; ok {
+		return client
+	}
+	client := s3.New(awsSession, aws.NewConfig().WithRegion(region))
+	regionClients[region] = client
+	return client
+}
+
+// episodeStorage returns the storage an episode records for a transcript
+// stored in bucket: the event's location, since the pipeline keeps
+// transcripts in the audio bucket, with that bucket as the transcript's
+func episodeStorage(storage *StorageLocation, bucket string) *StorageLocation {
+	if storage == nil {
+		return nil
+	}
+	recorded := *storage
+	recorded.Bucket = bucket
+	recorded.AudioBucket = bucket
+	return &recorded
+}
@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+func TestEpisodeStorage(t *testing.T) {
+	if got := episodeStorage(nil, "bucket"); got != nil {
+		t.Errorf("episodeStorage(nil) = %+v, want nil for unpinned episodes", got)
+	}
+
+	eu := &StorageLocation{Location: "eu", Region: "eu-west-1", Bucket: "eu-transcripts", AudioBucket: "eu-audio"}
+	got := episodeStorage(eu, "eu-audio")
+	want := StorageLocation{Location: "eu", Region: "eu-west-1", Bucket: "eu-audio", AudioBucket: "eu-audio"}
+	if *got != want {
+		t.Errorf("episodeStorage() = %+v, want %+v", *got, want)
+	}
+	if eu.Bucket != "eu-transcripts" {
+		t.Error("episodeStorage() modified the event's location")
+	}
+}
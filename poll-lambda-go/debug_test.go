@@ -0,0 +1,55 @@
+//go:build http
+
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDebugStateTracksJobs(t *testing.T) {
+	done := trackJob("poll podcast p1 (Test)")
+
+	state := fetchDebugState(t)
+	if len(state.Jobs) != 1 || state.Jobs[0].Description != "poll podcast p1 (Test)" {
+		t.Errorf("jobs = %+v, want the running poll", state.Jobs)
+	}
+	if state.Goroutines < 1 {
+		t.Errorf("goroutines = %d, want at least 1", state.Goroutines)
+	}
+
+	done()
+	if state := fetchDebugState(t); len(state.Jobs) != 0 {
+		t.Errorf("jobs after done = %+v, want none", state.Jobs)
+	}
+}
+
+func TestDebugMuxServesPprofAndExpvar(t *testing.T) {
+	mux := debugMux()
+	for _, path := range []string{"/debug/pprof/", "/debug/vars", "/debug/state"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != 200 {
+			t.Errorf("GET %s = %d, want 200", path, rec.Code)
+		}
+	}
+}
+
+type debugState struct {
+	Goroutines int `json:"goroutines"`
+	Jobs       []struct {
+		Description string `json:"description"`
+	} `json:"jobs"`
+}
+
+func fetchDebugState(t *testing.T) debugState {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	debugStateHandler(rec, httptest.NewRequest("GET", "/debug/state", nil))
+	var state debugState
+	if err := json.Unmarshal(rec.Body.Bytes(), &state); err != nil {
+		t.Fatalf("decode /debug/state: %v", err)
+	}
+	return state
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// When a podcast moves hosts, its feed re-lists every episode with a new
+// audio URL. Episode IDs are derived from the audio URL, so without this the
+// moved items would be inserted (and transcribed) a second time. An item
+// whose title, publication date and duration match an existing episode of
+// the same podcast is linked to that episode instead.
+
+const (
+	// Hosts sometimes rewrite pubDate on migration (timezone, re-import time)
+	dedupPublishedWindow = 48 * time.Hour
+	// Re-encoded audio can shift the reported duration slightly
+	dedupMinDurationToleranceMinutes = 1
+	dedupDurationTolerance           = 0.02
+)
+
+var titleNoise = regexp.MustCompile(`[^a-z0-9]+`)
+
+// normalizeTitle lowercases a title and collapses punctuation and whitespace
+func normalizeTitle(title string) string {
+	return strings.TrimSpace(titleNoise.ReplaceAllString(strings.ToLower(title), " "))
+}
+
+// parseDurationMinutes reads an itunes:duration value (seconds, MM:SS or
+// HH:MM:SS) the same way the API's RSS parser does; 0 means unknown
+func parseDurationMinutes(raw string) int {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0
+	}
+
+	parts := strings.Split(raw, ":")
+	values := make([]int, len(parts))
+	for i, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil {
+			return 0
+		}
+		values[i] = v
+	}
+
+	roundUp := func(seconds int) int {
+		if seconds > 30 {
+			return 1
+		}
+		return 0
+	}
+
+	switch len(values) {
+	case 1:
+		return values[0] / 60
+	case 2:
+		return values[0] + roundUp(values[1])
+	case 3:
+		return values[0]*60 + values[1] + roundUp(values[2])
+	}
+	return 0
+}
+
+// itemDurationMinutes gets a feed item's duration in minutes (0 if absent)
+func itemDurationMinutes(item *gofeed.Item) int {
+	if item.ITunesExt == nil {
+		return 0
+	}
+	return parseDurationMinutes(item.ITunesExt.Duration)
+}
+
+// durationsMatch compares durations in minutes; unknown durations don't rule out a match
+func durationsMatch(a, b int) bool {
+	if a == 0 || b == 0 {
+		return true
+	}
+	tolerance := math.Max(dedupMinDurationToleranceMinutes, dedupDurationTolerance*float64(max(a, b)))
+	return math.Abs(float64(a-b)) <= tolerance
+}
+
+// isSameEpisode reports whether a feed item is an existing episode under a new audio URL
+func isSameEpisode(title string, published *time.Time, durationMinutes int, existing Episode) bool {
+	normalized := normalizeTitle(title)
+	if normalized == "" || normalized != normalizeTitle(existing.Title) {
+		return false
+	}
+	if published == nil || existing.PublishedDate == nil {
+		return false
+	}
+	if published.Sub(*existing.PublishedDate).Abs() > dedupPublishedWindow {
+		return false
+	}
+	return durationsMatch(durationMinutes, existing.DurationMinutes)
+}
+
+// findMovedEpisode looks for an existing episode of the podcast that a feed
+// item duplicates. Items without a title or publication date are never matched.
+func findMovedEpisode(ctx context.Context, coll *mongo.Collection, podcastID string, item *gofeed.Item, audioURL string) (*Episode, error) {
+	if podcastID == "" || item.PublishedParsed == nil || normalizeTitle(item.Title) == "" {
+		return nil, nil
+	}
+
+	published := item.PublishedParsed.UTC()
+	cursor, err := coll.Find(ctx, bson.M{
+		"podcast_id": podcastID,
+		"audio_url":  bson.M{"$ne": audioURL},
+		"deleted_at": nil,
+		"published_date": bson.M{
+			"$gte": published.Add(-dedupPublishedWindow),
+			"$lte": published.Add(dedupPublishedWindow),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	durationMinutes := itemDurationMinutes(item)
+	for cursor.Next(ctx) {
+		var candidate Episode
+		if err := cursor.Decode(&candidate); err != nil {
+			continue
+		}
+		if isSameEpisode(item.Title, &published, durationMinutes, candidate) {
+			return &candidate, nil
+		}
+	}
+	return nil, cursor.Err()
+}
+
+// linkMovedEpisode points an existing episode at its new audio URL, keeping
+// the old one so it is still recognized
+func linkMovedEpisode(ctx context.Context, coll *mongo.Collection, existing *Episode, audioURL string) error {
+	_, err := coll.UpdateOne(
+		ctx,
+		bson.M{"_id": existing.ID},
+		bson.M{
+			"$set":      bson.M{"audio_url": audioURL, "updated_at": time.Now().UTC()},
+			"$addToSet": bson.M{"previous_audio_urls": existing.AudioURL},
+		},
+	)
+	return err
+}
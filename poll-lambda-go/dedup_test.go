@@ -0,0 +1,97 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/mmcdole/gofeed/extensions"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		title    string
+		expected string
+	}{
+		{"Episode 12: The Big One!", "episode 12 the big one"},
+		{"  episode 12 -- the BIG one ", "episode 12 the big one"},
+		{"", ""},
+		{"!!!", ""},
+	}
+
+	for _, tt := range tests {
+		if result := normalizeTitle(tt.title); result != tt.expected {
+			t.Errorf("normalizeTitle(%q) = %q, want %q", tt.title, result, tt.expected)
+		}
+	}
+}
+
+func TestParseDurationMinutes(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected int
+	}{
+		{"3600", 60},
+		{"45:10", 45},
+		{"45:31", 46},
+		{"01:02:40", 63},
+		{"", 0},
+		{"about an hour", 0},
+	}
+
+	for _, tt := range tests {
+		if result := parseDurationMinutes(tt.raw); result != tt.expected {
+			t.Errorf("parseDurationMinutes(%q) = %d, want %d", tt.raw, result, tt.expected)
+		}
+	}
+}
+
+func TestItemDurationMinutes(t *testing.T) {
+	item := &gofeed.Item{ITunesExt: &ext.ITunesItemExtension{Duration: "30:00"}}
+	if result := itemDurationMinutes(item); result != 30 {
+		t.Errorf("itemDurationMinutes() = %d, want 30", result)
+	}
+	if result := itemDurationMinutes(&gofeed.Item{}); result != 0 {
+		t.Errorf("itemDurationMinutes() without iTunes data = %d, want 0", result)
+	}
+}
+
+func TestIsSameEpisode(t *testing.T) {
+	published := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	existing := Episode{
+		Title:           "Episode 12: The Big One",
+		AudioURL:        "https://old-host.example.com/ep12.mp3",
+		PublishedDate:   &published,
+		DurationMinutes: 62,
+	}
+
+	shifted := published.Add(5 * time.Hour)
+	farAway := published.Add(72 * time.Hour)
+
+	tests := []struct {
+		name      string
+		title     string
+		published *time.Time
+		duration  int
+		expected  bool
+	}{
+		{"identical", "Episode 12: The Big One", &published, 62, true},
+		{"title punctuation and case differ", "episode 12 - the big one", &published, 62, true},
+		{"pubDate shifted by timezone", "Episode 12: The Big One", &shifted, 62, true},
+		{"duration within tolerance", "Episode 12: The Big One", &published, 63, true},
+		{"unknown duration", "Episode 12: The Big One", &published, 0, true},
+		{"different title", "Episode 13: The Sequel", &published, 62, false},
+		{"published days apart", "Episode 12: The Big One", &farAway, 62, false},
+		{"different duration", "Episode 12: The Big One", &published, 40, false},
+		{"no publication date", "Episode 12: The Big One", nil, 62, false},
+		{"empty title", "", &published, 62, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := isSameEpisode(tt.title, tt.published, tt.duration, existing); result != tt.expected {
+				t.Errorf("isSameEpisode() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// Some items offer the same audio several times, at different bitrates or
+// in different formats. Every audio enclosure is recorded on the episode as
+// "enclosures", and AUDIO_QUALITY_POLICY picks the one that is transcribed:
+//
+//	smallest  the smallest file by its advertised length (default), to cut download time
+//	largest   the largest file
+//	first     the first audio enclosure, in feed order
+//
+// Enclosures without a length rank after those with one. An item whose
+// audio URL changes because the policy did is still recognized, since any
+// of its enclosure URLs matches the existing episode.
+
+// Audio quality policies
+const (
+	qualitySmallest = "smallest"
+	qualityLargest  = "largest"
+	qualityFirst    = "first"
+)
+
+// Enclosure is an audio enclosure of an episode
+type Enclosure struct {
+	URL    string `bson:"url"`
+	Type   string `bson:"type"`
+	Length int64  `bson:"length,omitempty"`
+}
+
+func loadAudioQualityPolicy() string {
+	switch policy := strings.ToLower(os.Getenv("AUDIO_QUALITY_POLICY")); policy {
+	case "":
+		return qualitySmallest
+	case qualitySmallest, qualityLargest, qualityFirst:
+		return policy
+	default:
+		log.Printf("Warning: invalid AUDIO_QUALITY_POLICY %q, using %s", policy, qualitySmallest)
+		return qualitySmallest
+	}
+}
+
+var audioQuality = loadAudioQualityPolicy()
+
+// audioEnclosures returns the audio enclosures of a feed item in feed order,
+// with normalized URLs
+func audioEnclosures(item *gofeed.Item) []Enclosure {
+	var out []Enclosure
+	for _, enc := range item.Enclosures {
+		if enc == nil || enc.URL == "" || len(enc.Type) <= len("audio/") || !strings.HasPrefix(enc.Type, "audio/") {
+			continue
+		}
+		length, err := strconv.ParseInt(strings.TrimSpace(enc.Length), 10, 64)
+		if err != nil || length < 0 {
+			length = 0
+		}
+		out = append(out, Enclosure{URL: normalizeURL(enc.URL), Type: enc.Type, Length: length})
+	}
+	return out
+}
+
+// preferredEnclosure picks the enclosure to transcribe by policy; nil if
+// there are none
+func preferredEnclosure(enclosures []Enclosure, policy string) *Enclosure {
+	if len(enclosures) == 0 {
+		return nil
+	}
+	if policy == qualityFirst {
+		return &enclosures[0]
+	}
+
+	ranked := append([]Enclosure(nil), enclosures...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i].Length, ranked[j].Length
+		if a == 0 || b == 0 {
+			return b == 0 && a != 0
+		}
+		if policy == qualityLargest {
+			return a > b
+		}
+		return a < b
+	})
+	return &ranked[0]
+}
+
+// enclosureURLs returns the URLs of enclosures, or just audioURL when the
+// item had none and its link is used instead
+func enclosureURLs(enclosures []Enclosure, audioURL string) []string {
+	if len(enclosures) == 0 {
+		return []string{audioURL}
+	}
+	urls := make([]string, len(enclosures))
+	for i, enc := range enclosures {
+		urls[i] = enc.URL
+	}
+	return urls
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestAudioEnclosures(t *testing.T) {
+	item := &gofeed.Item{
+		Enclosures: []*gofeed.Enclosure{
+			{URL: "https://example.com/cover.jpg", Type: "image/jpeg", Length: "1000"},
+			{URL: "https://example.com/ep-128.mp3", Type: "audio/mpeg", Length: "64000000"},
+			{URL: "https://example.com/ep.m4a", Type: "audio/mp4", Length: "unknown"},
+			{URL: "", Type: "audio/mpeg", Length: "10"},
+			nil,
+		},
+	}
+	want := []Enclosure{
+		{URL: "https://example.com/ep-128.mp3", Type: "audio/mpeg", Length: 64000000},
+		{URL: "https://example.com/ep.m4a", Type: "audio/mp4"},
+	}
+	if got := audioEnclosures(item); !reflect.DeepEqual(got, want) {
+		t.Errorf("audioEnclosures() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPreferredEnclosure(t *testing.T) {
+	enclosures := []Enclosure{
+		{URL: "https://a/unknown.mp3"},
+		{URL: "https://a/128.mp3", Length: 64000000},
+		{URL: "https://a/64.mp3", Length: 32000000},
+		{URL: "https://a/64-copy.mp3", Length: 32000000},
+	}
+
+	tests := []struct {
+		policy string
+		want   string
+	}{
+		{policy: qualitySmallest, want: "https://a/64.mp3"},
+		{policy: qualityLargest, want: "https://a/128.mp3"},
+		{policy: qualityFirst, want: "https://a/unknown.mp3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			if got := preferredEnclosure(enclosures, tt.policy); got == nil || got.URL != tt.want {
+				t.Errorf("preferredEnclosure() = %+v, want %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("no lengths keeps feed order", func(t *testing.T) {
+		got := preferredEnclosure([]Enclosure{{URL: "https://a/1.mp3"}, {URL: "https://a/2.mp3"}}, qualitySmallest)
+		if got == nil || got.URL != "https://a/1.mp3" {
+			t.Errorf("preferredEnclosure() = %+v, want the first", got)
+		}
+	})
+
+	t.Run("none", func(t *testing.T) {
+		if got := preferredEnclosure(nil, qualitySmallest); got != nil {
+			t.Errorf("preferredEnclosure(nil) = %+v, want nil", got)
+		}
+	})
+}
+
+func TestEnclosureURLs(t *testing.T) {
+	got := enclosureURLs([]Enclosure{{URL: "https://a/1.mp3"}, {URL: "https://a/2.m4a"}}, "https://a/1.mp3")
+	if want := []string{"https://a/1.mp3", "https://a/2.m4a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("enclosureURLs() = %v, want %v", got, want)
+	}
+	if got := enclosureURLs(nil, "https://a/episode.html"); !reflect.DeepEqual(got, []string{"https://a/episode.html"}) {
+		t.Errorf("enclosureURLs(nil) = %v, want the link", got)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// Trailers, bonus clips and re-runs rarely need a transcript. A podcast's
+// "episode_filter" (set through the API) can skip them: they are still
+// recorded as episodes, with transcript_status "skipped" and a skip_reason,
+// but no execution is started. Trailers and bonus episodes are recognized
+// by <itunes:episodeType>, or by their title when the feed doesn't set it;
+// re-runs only by their title ("Rerun:", "(Encore)", "Best of ...").
+//
+// Independently of the podcast, TRANSCRIBE_MIN_DURATION_MINUTES and
+// TRANSCRIBE_MAX_DURATION_MINUTES (0 for no limit, the default) skip items
+// whose <itunes:duration> is outside them as too_short or too_long. Items
+// without a duration are transcribed. The API's bulk jobs read the same
+// variables.
+
+// Skip reasons
+const (
+	skipTrailer = "trailer"
+	skipBonus   = "bonus"
+	skipRerun   = "rerun"
+	skipShort   = "too_short"
+	skipLong    = "too_long"
+)
+
+// EpisodeFilter is a podcast's episode_filter
+type EpisodeFilter struct {
+	SkipTrailers bool `bson:"skip_trailers"`
+	SkipBonus    bool `bson:"skip_bonus"`
+	SkipReruns   bool `bson:"skip_reruns"`
+}
+
+var (
+	trailerTitle = regexp.MustCompile(`(?i)^\W*(official\s+)?(trailer|teaser)\b|\b(trailer|teaser)\W*$`)
+	bonusTitle   = regexp.MustCompile(`(?i)\bbonus\b`)
+	rerunTitle   = regexp.MustCompile(`(?i)\b(re-?run|rebroadcast|re-?release|encore presentation|from the (vault|archives?)|best of)\b|^\W*(replay|encore)\b|[(\[](r|replay|encore|repeat)[)\]]`)
+)
+
+// episodeKind returns skipTrailer, skipBonus or skipRerun for an item that
+// looks like one, or ""
+func episodeKind(item *gofeed.Item) string {
+	switch itemEpisodeType(item) {
+	case "trailer":
+		return skipTrailer
+	case "bonus":
+		return skipBonus
+	case "":
+		if trailerTitle.MatchString(item.Title) {
+			return skipTrailer
+		}
+		if bonusTitle.MatchString(item.Title) {
+			return skipBonus
+		}
+	}
+	if rerunTitle.MatchString(item.Title) {
+		return skipRerun
+	}
+	return ""
+}
+
+// durationGate holds the TRANSCRIBE_MIN/MAX_DURATION_MINUTES limits in seconds
+type durationGate struct {
+	min, max int
+}
+
+func loadDurationGate() durationGate {
+	minutes := func(name string) int {
+		raw := os.Getenv(name)
+		if raw == "" {
+			return 0
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			log.Printf("Warning: invalid %s %q, not limiting durations", name, raw)
+			return 0
+		}
+		return n
+	}
+	return durationGate{
+		min: minutes("TRANSCRIBE_MIN_DURATION_MINUTES") * 60,
+		max: minutes("TRANSCRIBE_MAX_DURATION_MINUTES") * 60,
+	}
+}
+
+var durationLimits = loadDurationGate()
+
+// skipReason returns skipShort or skipLong for a duration outside the gate, or ""
+func (g durationGate) skipReason(seconds int, known bool) string {
+	switch {
+	case !known:
+		return ""
+	case g.min > 0 && seconds < g.min:
+		return skipShort
+	case g.max > 0 && seconds > g.max:
+		return skipLong
+	}
+	return ""
+}
+
+// parseDurationSeconds reads an <itunes:duration> (seconds, MM:SS or
+// HH:MM:SS); known is false if it is missing or invalid
+func parseDurationSeconds(raw string) (seconds int, known bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, false
+	}
+	parts := strings.Split(raw, ":")
+	if len(parts) > 3 {
+		return 0, false
+	}
+	for _, part := range parts {
+		// Some feeds write fractional seconds ("3600.5")
+		whole, _, _ := strings.Cut(part, ".")
+		v, err := strconv.Atoi(whole)
+		if err != nil || v < 0 {
+			return 0, false
+		}
+		seconds = seconds*60 + v
+	}
+	return seconds, true
+}
+
+// transcriptionSkipReason returns why a podcast's new item isn't
+// transcribed, or "" if it is
+func transcriptionSkipReason(podcast Podcast, item *gofeed.Item) string {
+	if reason := skipReason(podcast.EpisodeFilter, item); reason != "" {
+		return reason
+	}
+	if item.ITunesExt == nil {
+		return ""
+	}
+	return durationLimits.skipReason(parseDurationSeconds(item.ITunesExt.Duration))
+}
+
+// skipReason returns why the filter skips transcribing an item, or "" if it doesn't
+func skipReason(filter *EpisodeFilter, item *gofeed.Item) string {
+	if filter == nil {
+		return ""
+	}
+	switch kind := episodeKind(item); {
+	case kind == skipTrailer && filter.SkipTrailers,
+		kind == skipBonus && filter.SkipBonus,
+		kind == skipRerun && filter.SkipReruns:
+		return kind
+	}
+	return ""
+}
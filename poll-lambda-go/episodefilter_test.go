@@ -0,0 +1,130 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+)
+
+func TestEpisodeKind(t *testing.T) {
+	tests := []struct {
+		title       string
+		episodeType string
+		want        string
+	}{
+		{title: "Episode 12: Interest Rates", want: ""},
+		{title: "Trailer", want: skipTrailer},
+		{title: "Official Trailer: Season 3", want: skipTrailer},
+		{title: "Season 2 Teaser", want: skipTrailer},
+		{title: "Reviewing the new Dune trailer in depth", want: ""},
+		{title: "BONUS: Listener Questions", want: skipBonus},
+		{title: "Listener questions", episodeType: "bonus", want: skipBonus},
+		{title: "Welcome to the show", episodeType: "trailer", want: skipTrailer},
+		{title: "Bonus round strategy", episodeType: "full", want: ""},
+		{title: "Rerun: Our First Episode", want: skipRerun},
+		{title: "Best of 2023", want: skipRerun},
+		{title: "Replay: The Interview", want: skipRerun},
+		{title: "The Interview (Encore)", want: skipRerun},
+		{title: "From the Archives: Early Days", want: skipRerun},
+		{title: "Instant replay mistakes in football", want: ""},
+		{title: "Best of 2023 bonus", episodeType: "full", want: skipRerun},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			item := &gofeed.Item{Title: tt.title}
+			if tt.episodeType != "" {
+				item.ITunesExt = &ext.ITunesItemExtension{EpisodeType: tt.episodeType}
+			}
+			if got := episodeKind(item); got != tt.want {
+				t.Errorf("episodeKind(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSkipReason(t *testing.T) {
+	trailer := &gofeed.Item{Title: "Trailer"}
+	rerun := &gofeed.Item{Title: "Rerun: Pilot"}
+
+	if got := skipReason(nil, trailer); got != "" {
+		t.Errorf("skipReason(nil filter) = %q, want empty", got)
+	}
+	filter := &EpisodeFilter{SkipTrailers: true}
+	if got := skipReason(filter, trailer); got != skipTrailer {
+		t.Errorf("skipReason(trailer) = %q, want %q", got, skipTrailer)
+	}
+	if got := skipReason(filter, rerun); got != "" {
+		t.Errorf("skipReason(rerun) = %q with reruns allowed, want empty", got)
+	}
+	if got := skipReason(&EpisodeFilter{SkipReruns: true}, rerun); got != skipRerun {
+		t.Errorf("skipReason(rerun) = %q, want %q", got, skipRerun)
+	}
+}
+
+func TestParseDurationSeconds(t *testing.T) {
+	tests := []struct {
+		raw   string
+		want  int
+		known bool
+	}{
+		{raw: "45", want: 45, known: true},
+		{raw: "1:30", want: 90, known: true},
+		{raw: "01:02:03", want: 3723, known: true},
+		{raw: " 3600.5 ", want: 3600, known: true},
+		{raw: "", known: false},
+		{raw: "about an hour", known: false},
+		{raw: "1:2:3:4", known: false},
+	}
+	for _, tt := range tests {
+		got, known := parseDurationSeconds(tt.raw)
+		if got != tt.want || known != tt.known {
+			t.Errorf("parseDurationSeconds(%q) = %d, %v, want %d, %v", tt.raw, got, known, tt.want, tt.known)
+		}
+	}
+}
+
+func TestDurationGate(t *testing.T) {
+	gate := durationGate{min: 2 * 60, max: 6 * 60 * 60}
+	tests := []struct {
+		name    string
+		seconds int
+		known   bool
+		want    string
+	}{
+		{name: "clip", seconds: 45, known: true, want: skipShort},
+		{name: "at the minimum", seconds: 120, known: true, want: ""},
+		{name: "long episode", seconds: 3 * 60 * 60, known: true, want: ""},
+		{name: "livestream archive", seconds: 7 * 60 * 60, known: true, want: skipLong},
+		{name: "unknown duration", known: false, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gate.skipReason(tt.seconds, tt.known); got != tt.want {
+				t.Errorf("skipReason(%d) = %q, want %q", tt.seconds, got, tt.want)
+			}
+		})
+	}
+
+	if got := (durationGate{}).skipReason(10, true); got != "" {
+		t.Errorf("skipReason() without limits = %q, want empty", got)
+	}
+}
+
+func TestTranscriptionSkipReason(t *testing.T) {
+	saved := durationLimits
+	defer func() { durationLimits = saved }()
+	durationLimits = durationGate{min: 2 * 60}
+
+	clip := &gofeed.Item{Title: "Trailer", ITunesExt: &ext.ITunesItemExtension{Duration: "0:45"}}
+	if got := transcriptionSkipReason(Podcast{EpisodeFilter: &EpisodeFilter{SkipTrailers: true}}, clip); got != skipTrailer {
+		t.Errorf("transcriptionSkipReason() = %q, want the podcast's filter first", got)
+	}
+	if got := transcriptionSkipReason(Podcast{}, clip); got != skipShort {
+		t.Errorf("transcriptionSkipReason() = %q, want %q", got, skipShort)
+	}
+	if got := transcriptionSkipReason(Podcast{}, &gofeed.Item{Title: "No duration"}); got != "" {
+		t.Errorf("transcriptionSkipReason() = %q for an item without duration, want empty", got)
+	}
+}
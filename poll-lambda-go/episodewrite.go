@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// New episodes of a poll are written in one unordered bulk write instead of
+// an insert per item, which matters for feeds that publish a day's batch at
+// once. Each write is an upsert on the episode ID (derived from the audio
+// URL, which is what identifies an episode here; feeds' guids aren't kept)
+// that only sets fields on insert, so an episode another poll inserted in
+// the meantime is left untouched and reported as existing rather than
+// failing the batch.
+
+// MongoDB's E11000 duplicate key error
+const duplicateKeyCode = 11000
+
+type insertOutcome string
+
+const (
+	insertInserted insertOutcome = "inserted"
+	insertExisting insertOutcome = "existing"
+	insertFailed   insertOutcome = "failed"
+)
+
+// episodeInsert is the outcome of writing one episode
+type episodeInsert struct {
+	Outcome insertOutcome
+	Err     error // set when Outcome is insertFailed
+}
+
+// episodeBulkWriter is the part of *mongo.Collection insertEpisodes uses
+type episodeBulkWriter interface {
+	BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error)
+}
+
+// insertEpisodes upserts episodes in one unordered bulk write and returns
+// the outcome of each, in order
+func insertEpisodes(ctx context.Context, collection episodeBulkWriter, episodes []Episode) []episodeInsert {
+	if len(episodes) == 0 {
+		return nil
+	}
+	models := make([]mongo.WriteModel, 0, len(episodes))
+	for _, episode := range episodes {
+		model, err := episodeUpsertModel(episode)
+		if err != nil {
+			return failAll(len(episodes), err)
+		}
+		models = append(models, model)
+	}
+	res, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return insertOutcomes(len(episodes), res, err)
+}
+
+// episodeUpsertModel inserts episode unless its ID is already taken
+func episodeUpsertModel(episode Episode) (mongo.WriteModel, error) {
+	raw, err := bson.Marshal(episode)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.D
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	// _id comes from the filter; setting it again is rejected
+	fields := make(bson.D, 0, len(doc))
+	for _, field := range doc {
+		if field.Key != "_id" {
+			fields = append(fields, field)
+		}
+	}
+	return mongo.NewUpdateOneModel().
+		SetFilter(bson.M{"_id": episode.ID}).
+		SetUpdate(bson.M{"$setOnInsert": fields}).
+		SetUpsert(true), nil
+}
+
+// insertOutcomes maps a bulk write's result to per-episode outcomes. An
+// unordered write attempts every model, so failures are only those its
+// write errors name; any other error leaves every episode's fate unknown.
+func insertOutcomes(n int, res *mongo.BulkWriteResult, err error) []episodeInsert {
+	var bulkErr mongo.BulkWriteException
+	if err != nil && !errors.As(err, &bulkErr) {
+		return failAll(n, err)
+	}
+
+	outcomes := make([]episodeInsert, n)
+	for i := range outcomes {
+		outcomes[i].Outcome = insertExisting
+		if res != nil {
+			if _, ok := res.UpsertedIDs[int64(i)]; ok {
+				outcomes[i].Outcome = insertInserted
+			}
+		}
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Index < 0 || writeErr.Index >= n {
+			continue
+		}
+		// Two upserts racing on one ID: the loser's episode exists
+		if writeErr.Code == duplicateKeyCode {
+			outcomes[writeErr.Index] = episodeInsert{Outcome: insertExisting}
+			continue
+		}
+		outcomes[writeErr.Index] = episodeInsert{Outcome: insertFailed, Err: writeErr}
+	}
+	return outcomes
+}
+
+func failAll(n int, err error) []episodeInsert {
+	outcomes := make([]episodeInsert, n)
+	for i := range outcomes {
+		outcomes[i] = episodeInsert{Outcome: insertFailed, Err: err}
+	}
+	return outcomes
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type fakeBulkWriter struct {
+	models []mongo.WriteModel
+	opts   []*options.BulkWriteOptions
+	result *mongo.BulkWriteResult
+	err    error
+}
+
+func (f *fakeBulkWriter) BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	f.models = models
+	f.opts = opts
+	return f.result, f.err
+}
+
+func TestEpisodeUpsertModel(t *testing.T) {
+	model, err := episodeUpsertModel(Episode{ID: "ep1", EpisodeID: "ep1", Title: "One", TranscriptStatus: "pending"})
+	if err != nil {
+		t.Fatalf("episodeUpsertModel() error = %v", err)
+	}
+	update := model.(*mongo.UpdateOneModel)
+	if update.Upsert == nil || !*update.Upsert {
+		t.Error("model is not an upsert")
+	}
+	if filter := update.Filter.(bson.M); filter["_id"] != "ep1" {
+		t.Errorf("filter = %v, want _id ep1", filter)
+	}
+	fields := update.Update.(bson.M)["$setOnInsert"].(bson.D)
+	set := fields.Map()
+	if _, ok := set["_id"]; ok {
+		t.Error("$setOnInsert sets _id")
+	}
+	if set["episode_id"] != "ep1" || set["title"] != "One" || set["transcript_status"] != "pending" {
+		t.Errorf("$setOnInsert = %v", set)
+	}
+}
+
+func TestInsertEpisodes(t *testing.T) {
+	writer := &fakeBulkWriter{
+		result: &mongo.BulkWriteResult{UpsertedIDs: map[int64]interface{}{0: "ep1", 2: "ep3"}},
+		err: mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{
+			{WriteError: mongo.WriteError{Index: 2, Code: 2, Message: "bad value"}},
+			{WriteError: mongo.WriteError{Index: 3, Code: duplicateKeyCode, Message: "E11000"}},
+		}},
+	}
+	episodes := []Episode{{ID: "ep1"}, {ID: "ep2"}, {ID: "ep3"}, {ID: "ep4"}}
+
+	outcomes := insertEpisodes(context.Background(), writer, episodes)
+
+	if len(writer.models) != 4 {
+		t.Fatalf("wrote %d models, want 4", len(writer.models))
+	}
+	if len(writer.opts) != 1 || writer.opts[0].Ordered == nil || *writer.opts[0].Ordered {
+		t.Error("bulk write is not unordered")
+	}
+	want := []insertOutcome{insertInserted, insertExisting, insertFailed, insertExisting}
+	for i, outcome := range outcomes {
+		if outcome.Outcome != want[i] {
+			t.Errorf("episode %d outcome = %s, want %s", i, outcome.Outcome, want[i])
+		}
+	}
+	if outcomes[2].Err == nil {
+		t.Error("failed episode has no error")
+	}
+}
+
+func TestInsertEpisodesNetworkError(t *testing.T) {
+	writer := &fakeBulkWriter{err: errors.New("connection reset")}
+
+	outcomes := insertEpisodes(context.Background(), writer, []Episode{{ID: "ep1"}, {ID: "ep2"}})
+
+	for i, outcome := range outcomes {
+		if outcome.Outcome != insertFailed || outcome.Err == nil {
+			t.Errorf("episode %d = %+v, want failed with error", i, outcome)
+		}
+	}
+}
+
+func TestInsertEpisodesEmpty(t *testing.T) {
+	writer := &fakeBulkWriter{}
+	if outcomes := insertEpisodes(context.Background(), writer, nil); outcomes != nil {
+		t.Errorf("insertEpisodes(nil) = %v, want nil", outcomes)
+	}
+	if writer.models != nil {
+		t.Error("empty insert reached the database")
+	}
+}
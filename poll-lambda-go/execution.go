@@ -0,0 +1,57 @@
+//go:build !http
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/sfn"
+)
+
+// Execution names must be unique per state machine for 90 days and at most
+// 80 characters. Names carry a shortened episode ID for readability, the
+// start time and a random suffix, so two starts of the same episode in the
+// same second (a retried insert) don't collide.
+
+// executionEpisodeIDLen keeps names well under the limit: ep-<id>-<time>-<suffix>
+const executionEpisodeIDLen = 40
+
+// executionName returns a fresh execution name for an episode
+func executionName(episodeID string, now time.Time) string {
+	return newExecutionName("ep", episodeID, now)
+}
+
+// batchExecutionName returns a fresh name for a podcast's batch execution
+func batchExecutionName(podcastID string, now time.Time) string {
+	return newExecutionName("batch", podcastID, now)
+}
+
+func newExecutionName(kind, id string, now time.Time) string {
+	if len(id) > executionEpisodeIDLen {
+		id = id[:executionEpisodeIDLen]
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		// Fall back to the clock; still distinct unless started in the same nanosecond
+		return kind + "-" + id + "-" + now.UTC().Format("20060102T150405.000000000")
+	}
+	return kind + "-" + id + "-" + now.UTC().Format("20060102T150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// executionARN derives the ARN of a named execution of a standard workflow
+func executionARN(stateMachineARN, name string) string {
+	return strings.Replace(stateMachineARN, ":stateMachine:", ":execution:", 1) + ":" + name
+}
+
+// isExecutionAlreadyExists reports whether a start failed because the
+// execution exists, e.g. when the SDK retried a start whose first attempt
+// succeeded after a timeout
+func isExecutionAlreadyExists(err error) bool {
+	var aerr awserr.Error
+	return errors.As(err, &aerr) && aerr.Code() == sfn.ErrCodeExecutionAlreadyExists
+}
@@ -0,0 +1,68 @@
+//go:build !http
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/sfn"
+)
+
+var validExecutionName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,80}$`)
+
+func TestExecutionName(t *testing.T) {
+	episodeID := generateEpisodeID("https://example.com/episode.mp3")
+	now := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+
+	first := executionName(episodeID, now)
+	second := executionName(episodeID, now)
+	if first == second {
+		t.Errorf("two names in the same second collide: %s", first)
+	}
+	for _, name := range []string{first, second} {
+		if !validExecutionName.MatchString(name) {
+			t.Errorf("invalid execution name %q (%d chars)", name, len(name))
+		}
+	}
+	if want := "ep-" + episodeID[:executionEpisodeIDLen] + "-20240501T083000-"; first[:len(want)] != want {
+		t.Errorf("name = %s, want prefix %s", first, want)
+	}
+}
+
+func TestBatchExecutionName(t *testing.T) {
+	name := batchExecutionName("pod_abc123", time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC))
+	if !validExecutionName.MatchString(name) {
+		t.Errorf("invalid execution name %q", name)
+	}
+	if want := "batch-pod_abc123-20240501T083000-"; name[:len(want)] != want {
+		t.Errorf("name = %s, want prefix %s", name, want)
+	}
+}
+
+func TestExecutionARN(t *testing.T) {
+	got := executionARN("arn:aws:states:us-east-1:123456789012:stateMachine:podcast-processing-workflow", "ep-abc")
+	want := "arn:aws:states:us-east-1:123456789012:execution:podcast-processing-workflow:ep-abc"
+	if got != want {
+		t.Errorf("executionARN() = %s, want %s", got, want)
+	}
+}
+
+func TestIsExecutionAlreadyExists(t *testing.T) {
+	exists := awserr.New(sfn.ErrCodeExecutionAlreadyExists, "Execution Already Exists", nil)
+	if !isExecutionAlreadyExists(exists) {
+		t.Error("expected ExecutionAlreadyExists to be recognised")
+	}
+	if !isExecutionAlreadyExists(fmt.Errorf("start: %w", exists)) {
+		t.Error("expected a wrapped ExecutionAlreadyExists to be recognised")
+	}
+	if isExecutionAlreadyExists(awserr.New(sfn.ErrCodeStateMachineDoesNotExist, "missing", nil)) {
+		t.Error("other errors aren't benign")
+	}
+	if isExecutionAlreadyExists(nil) {
+		t.Error("nil isn't an error")
+	}
+}
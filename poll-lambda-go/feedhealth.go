@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Every live poll classifies a feed failure and records the outcome on the
+// podcast document as feed_health, so dead feeds can be told apart from
+// flaky ones. Timeouts, network errors and 5xx responses are retried up to
+// FEED_FETCH_RETRIES times per poll (default 2), waiting FEED_FETCH_BACKOFF
+// before the first retry (default 1s, doubled each time). A feed whose last
+// FEED_DEAD_AFTER polls (default 5) all failed is marked dead; one that
+// failed fewer times, or only succeeded after retries, is flaky.
+
+// Feed error types
+const (
+	feedErrorTimeout      = "timeout"
+	feedErrorNetwork      = "network"
+	feedErrorHTTP4xx      = "http_4xx"
+	feedErrorHTTP5xx      = "http_5xx"
+	feedErrorAuthRequired = "auth_required"
+	feedErrorParse        = "parse"
+	feedErrorNoURL        = "no_feed_url"
+)
+
+// Feed health statuses
+const (
+	feedStatusHealthy = "healthy"
+	feedStatusFlaky   = "flaky"
+	feedStatusDead    = "dead"
+)
+
+const (
+	defaultFeedFetchRetries = 2
+	defaultFeedFetchBackoff = time.Second
+	defaultFeedDeadAfter    = 5
+)
+
+// FeedHealth is the feed_health field of a podcast document
+type FeedHealth struct {
+	Status              string     `bson:"status"`
+	ConsecutiveFailures int        `bson:"consecutive_failures"`
+	LastRetries         int        `bson:"last_retries"`
+	LastErrorType       string     `bson:"last_error_type,omitempty"`
+	LastError           string     `bson:"last_error,omitempty"`
+	LastErrorAt         *time.Time `bson:"last_error_at,omitempty"`
+	LastSuccessAt       *time.Time `bson:"last_success_at,omitempty"`
+	LastPolledAt        time.Time  `bson:"last_polled_at"`
+}
+
+// feedHTTPError is a feed response with a non-2xx status
+type feedHTTPError struct {
+	StatusCode int
+}
+
+func (e *feedHTTPError) Error() string {
+	return "http error: " + strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode)
+}
+
+// feedParseError is a feed that was fetched but couldn't be parsed
+type feedParseError struct {
+	err error
+}
+
+func (e *feedParseError) Error() string { return e.err.Error() }
+func (e *feedParseError) Unwrap() error { return e.err }
+
+// classifyFeedError returns the error type of a failed fetch
+func classifyFeedError(err error) string {
+	var httpErr *feedHTTPError
+	var parseErr *feedParseError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &httpErr):
+		switch {
+		case httpErr.StatusCode == http.StatusUnauthorized || httpErr.StatusCode == http.StatusForbidden:
+			return feedErrorAuthRequired
+		case httpErr.StatusCode >= 500:
+			return feedErrorHTTP5xx
+		default:
+			return feedErrorHTTP4xx
+		}
+	case errors.As(err, &parseErr):
+		return feedErrorParse
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return feedErrorTimeout
+	default:
+		return feedErrorNetwork
+	}
+}
+
+// isTransientFeedError reports whether a retry in the same poll may succeed
+func isTransientFeedError(errorType string) bool {
+	return errorType == feedErrorTimeout || errorType == feedErrorNetwork || errorType == feedErrorHTTP5xx
+}
+
+// feedRetryPolicy holds the FEED_FETCH_RETRIES, FEED_FETCH_BACKOFF and
+// FEED_DEAD_AFTER settings
+type feedRetryPolicy struct {
+	retries   int
+	backoff   time.Duration
+	deadAfter int
+}
+
+func loadFeedRetryPolicy() feedRetryPolicy {
+	policy := feedRetryPolicy{
+		retries:   defaultFeedFetchRetries,
+		backoff:   defaultFeedFetchBackoff,
+		deadAfter: defaultFeedDeadAfter,
+	}
+	if n, err := strconv.Atoi(os.Getenv("FEED_FETCH_RETRIES")); err == nil && n >= 0 {
+		policy.retries = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("FEED_FETCH_BACKOFF")); err == nil && d >= 0 {
+		policy.backoff = d
+	}
+	if n, err := strconv.Atoi(os.Getenv("FEED_DEAD_AFTER")); err == nil && n > 0 {
+		policy.deadAfter = n
+	}
+	return policy
+}
+
+var feedRetries = loadFeedRetryPolicy()
+
+// fetchWithRetries calls fetch, retrying transient failures, and returns the
+// feed with the number of retries made
+func fetchWithRetries(ctx context.Context, policy feedRetryPolicy, fetch func(context.Context) (*gofeed.Feed, error)) (*gofeed.Feed, int, error) {
+	backoff := policy.backoff
+	for retries := 0; ; retries++ {
+		feed, err := fetch(ctx)
+		if err == nil || retries >= policy.retries || !isTransientFeedError(classifyFeedError(err)) {
+			return feed, retries, err
+		}
+		log.Printf("Feed fetch failed (%s), retry %d/%d in %s: %v", classifyFeedError(err), retries+1, policy.retries, backoff, err)
+		select {
+		case <-ctx.Done():
+			return nil, retries, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// nextFeedHealth folds one poll's outcome into a podcast's feed health;
+// errorType is empty for a successful poll
+func nextFeedHealth(prev *FeedHealth, errorType, errMsg string, retries, deadAfter int, now time.Time) FeedHealth {
+	health := FeedHealth{}
+	if prev != nil {
+		health = *prev
+	}
+	health.LastPolledAt = now
+	health.LastRetries = retries
+
+	if errorType == "" {
+		health.ConsecutiveFailures = 0
+		health.LastSuccessAt = &now
+		health.Status = feedStatusHealthy
+		if retries > 0 {
+			health.Status = feedStatusFlaky
+		}
+		return health
+	}
+
+	health.ConsecutiveFailures++
+	health.LastErrorType = errorType
+	health.LastError = errMsg
+	health.LastErrorAt = &now
+	health.Status = feedStatusFlaky
+	if health.ConsecutiveFailures >= deadAfter {
+		health.Status = feedStatusDead
+	}
+	return health
+}
+
+// recordFeedHealth stores a poll's outcome on the podcast document
+func recordFeedHealth(ctx context.Context, db *mongo.Database, podcast Podcast, result *PodcastResult, errMsg string) {
+	health := nextFeedHealth(podcast.FeedHealth, result.ErrorType, errMsg, result.Retries, feedRetries.deadAfter, time.Now().UTC())
+	result.FeedStatus = health.Status
+
+	_, err := db.Collection("podcasts").UpdateOne(ctx,
+		bson.M{"_id": podcast.ID},
+		bson.M{"$set": bson.M{"feed_health": health}},
+	)
+	if err != nil {
+		log.Printf("Warning: failed to record feed health of podcast %s: %v", podcast.ID.Hex(), err)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// timeoutError is a net.Error that timed out
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyFeedError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&feedHTTPError{StatusCode: 401}, feedErrorAuthRequired},
+		{&feedHTTPError{StatusCode: 403}, feedErrorAuthRequired},
+		{&feedHTTPError{StatusCode: 404}, feedErrorHTTP4xx},
+		{&feedHTTPError{StatusCode: 503}, feedErrorHTTP5xx},
+		{&feedParseError{err: gofeed.ErrFeedTypeNotDetected}, feedErrorParse},
+		{fmt.Errorf("get: %w", timeoutError{}), feedErrorTimeout},
+		{fmt.Errorf("get: %w", context.DeadlineExceeded), feedErrorTimeout},
+		{errors.New("dial tcp: connection refused"), feedErrorNetwork},
+	}
+	for _, tt := range tests {
+		if got := classifyFeedError(tt.err); got != tt.want {
+			t.Errorf("classifyFeedError(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestFetchWithRetries(t *testing.T) {
+	policy := feedRetryPolicy{retries: 2, backoff: time.Millisecond}
+
+	tests := []struct {
+		name        string
+		errs        []error // returned by successive attempts; nil means success
+		wantRetries int
+		wantErr     bool
+	}{
+		{name: "first attempt succeeds", errs: []error{nil}},
+		{name: "transient failure recovers", errs: []error{&feedHTTPError{StatusCode: 502}, nil}, wantRetries: 1},
+		{name: "permanent failure isn't retried", errs: []error{&feedHTTPError{StatusCode: 404}}, wantErr: true},
+		{
+			name:        "retry budget runs out",
+			errs:        []error{timeoutError{}, timeoutError{}, timeoutError{}, nil},
+			wantRetries: 2,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			_, retries, err := fetchWithRetries(context.Background(), policy, func(context.Context) (*gofeed.Feed, error) {
+				err := tt.errs[attempts]
+				attempts++
+				if err != nil {
+					return nil, err
+				}
+				return &gofeed.Feed{}, nil
+			})
+			if retries != tt.wantRetries || (err != nil) != tt.wantErr {
+				t.Errorf("retries = %d, err = %v; want %d, error %v", retries, err, tt.wantRetries, tt.wantErr)
+			}
+			if attempts != retries+1 {
+				t.Errorf("made %d attempts for %d retries", attempts, retries)
+			}
+		})
+	}
+}
+
+func TestNextFeedHealth(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+
+	health := nextFeedHealth(nil, "", "", 0, 3, now)
+	if health.Status != feedStatusHealthy || health.LastSuccessAt == nil {
+		t.Fatalf("first successful poll = %+v, want healthy", health)
+	}
+
+	health = nextFeedHealth(&health, "", "", 1, 3, now)
+	if health.Status != feedStatusFlaky {
+		t.Errorf("success after retries = %s, want flaky", health.Status)
+	}
+
+	for i := 1; i <= 3; i++ {
+		health = nextFeedHealth(&health, feedErrorHTTP4xx, "http error: 404 Not Found", 0, 3, now.Add(time.Duration(i)*time.Hour))
+	}
+	if health.Status != feedStatusDead || health.ConsecutiveFailures != 3 || health.LastErrorType != feedErrorHTTP4xx {
+		t.Errorf("after 3 failures = %+v, want dead with 3 consecutive 4xx failures", health)
+	}
+	if !health.LastSuccessAt.Equal(now) {
+		t.Errorf("last success = %v, want it kept from before the failures", health.LastSuccessAt)
+	}
+
+	health = nextFeedHealth(&health, "", "", 0, 3, now.Add(4*time.Hour))
+	if health.Status != feedStatusHealthy || health.ConsecutiveFailures != 0 {
+		t.Errorf("recovered feed = %+v, want healthy", health)
+	}
+	if health.LastErrorType != feedErrorHTTP4xx {
+		t.Error("the last error should be kept for operators after recovery")
+	}
+}
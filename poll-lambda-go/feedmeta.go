@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Podcasts carry their feed's iTunes categories and explicit flag, which
+// the API filters on; each successful poll refreshes them when the feed
+// changed them. New episodes record their episode type (full, trailer or
+// bonus), explicit flag and the people their <podcast:person> tags name,
+// which the API's guest index links across podcasts. The API's
+// rss_parser.py stores the same fields when a podcast is subscribed to.
+
+// PodcastCategory is an iTunes category with its subcategories
+type PodcastCategory struct {
+	Name          string   `bson:"name"`
+	Subcategories []string `bson:"subcategories"`
+}
+
+// EpisodePerson is a person named by an item's <podcast:person> tag
+type EpisodePerson struct {
+	Name  string `bson:"name"`
+	Role  string `bson:"role"`
+	Group string `bson:"group,omitempty"`
+	Href  string `bson:"href,omitempty"`
+	Img   string `bson:"img,omitempty"`
+}
+
+// episodeTypes are the values of <itunes:episodeType>
+var episodeTypes = map[string]bool{"full": true, "trailer": true, "bonus": true}
+
+// feedCategories returns a feed's iTunes categories in feed order, merging
+// repeated categories
+func feedCategories(feed *gofeed.Feed) []PodcastCategory {
+	if feed.ITunesExt == nil {
+		return nil
+	}
+	var categories []PodcastCategory
+	index := map[string]int{}
+	for _, c := range feed.ITunesExt.Categories {
+		name := strings.TrimSpace(c.Text)
+		if name == "" {
+			continue
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(categories)
+			index[name] = i
+			categories = append(categories, PodcastCategory{Name: name, Subcategories: []string{}})
+		}
+		if c.Subcategory == nil {
+			continue
+		}
+		sub := strings.TrimSpace(c.Subcategory.Text)
+		if sub != "" && !containsString(categories[i].Subcategories, sub) {
+			categories[i].Subcategories = append(categories[i].Subcategories, sub)
+		}
+	}
+	return categories
+}
+
+func containsString(list []string, s string) bool {
+	for _, have := range list {
+		if have == s {
+			return true
+		}
+	}
+	return false
+}
+
+// parseExplicit reads an <itunes:explicit> value; nil if it is missing or unknown
+func parseExplicit(value string) *bool {
+	var explicit bool
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes", "explicit":
+		explicit = true
+	case "false", "no", "clean":
+		explicit = false
+	default:
+		return nil
+	}
+	return &explicit
+}
+
+// feedExplicit returns a feed's explicit flag
+func feedExplicit(feed *gofeed.Feed) *bool {
+	if feed.ITunesExt == nil {
+		return nil
+	}
+	return parseExplicit(feed.ITunesExt.Explicit)
+}
+
+// itemExplicit returns an item's explicit flag
+func itemExplicit(item *gofeed.Item) *bool {
+	if item.ITunesExt == nil {
+		return nil
+	}
+	return parseExplicit(item.ITunesExt.Explicit)
+}
+
+// itemEpisodeType returns an item's episode type, or "" if it has none
+func itemEpisodeType(item *gofeed.Item) string {
+	if item.ITunesExt == nil {
+		return ""
+	}
+	episodeType := strings.ToLower(strings.TrimSpace(item.ITunesExt.EpisodeType))
+	if !episodeTypes[episodeType] {
+		return ""
+	}
+	return episodeType
+}
+
+// itemPersons returns the people an item's <podcast:person> tags name, in
+// feed order. The tag's role defaults to host, as in the namespace spec.
+func itemPersons(item *gofeed.Item) []EpisodePerson {
+	var persons []EpisodePerson
+	for _, tag := range item.Extensions["podcast"]["person"] {
+		name := strings.TrimSpace(tag.Value)
+		if name == "" {
+			continue
+		}
+		role := strings.ToLower(strings.TrimSpace(tag.Attrs["role"]))
+		if role == "" {
+			role = "host"
+		}
+		persons = append(persons, EpisodePerson{
+			Name:  name,
+			Role:  role,
+			Group: strings.ToLower(strings.TrimSpace(tag.Attrs["group"])),
+			Href:  strings.TrimSpace(tag.Attrs["href"]),
+			Img:   strings.TrimSpace(tag.Attrs["img"]),
+		})
+	}
+	return persons
+}
+
+// feedMetadataUpdate returns the podcast fields a feed changed, or nil.
+// Fields the feed doesn't set are left alone.
+func feedMetadataUpdate(podcast Podcast, feed *gofeed.Feed) bson.M {
+	set := bson.M{}
+	if categories := feedCategories(feed); len(categories) > 0 && !reflect.DeepEqual(categories, podcast.Categories) {
+		set["categories"] = categories
+	}
+	if explicit := feedExplicit(feed); explicit != nil && (podcast.Explicit == nil || *podcast.Explicit != *explicit) {
+		set["explicit"] = *explicit
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	return set
+}
+
+// recordFeedMetadata stores the categories and explicit flag of a podcast's feed
+func recordFeedMetadata(ctx context.Context, db *mongo.Database, podcast Podcast, feed *gofeed.Feed) {
+	set := feedMetadataUpdate(podcast, feed)
+	if set == nil {
+		return
+	}
+	_, err := db.Collection("podcasts").UpdateOne(ctx, bson.M{"_id": podcast.ID}, bson.M{"$set": set})
+	if err != nil {
+		log.Printf("Warning: failed to record feed metadata of podcast %s: %v", podcast.ID.Hex(), err)
+	}
+}
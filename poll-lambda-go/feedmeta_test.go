@@ -0,0 +1,129 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+)
+
+const itunesFeedXML = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd">
+<channel>
+<title>Show</title>
+<itunes:explicit>yes</itunes:explicit>
+<itunes:category text="Technology"><itunes:category text="Tech News"/></itunes:category>
+<itunes:category text="News"/>
+<itunes:category text="Technology"><itunes:category text="Podcasting"/></itunes:category>
+<item>
+<title>Trailer</title>
+<itunes:episodeType>Trailer</itunes:episodeType>
+<itunes:explicit>clean</itunes:explicit>
+<enclosure url="https://example.com/trailer.mp3" type="audio/mpeg"/>
+</item>
+</channel>
+</rss>`
+
+func TestFeedMetadata(t *testing.T) {
+	feed, err := gofeed.NewParser().Parse(strings.NewReader(itunesFeedXML))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	wantCategories := []PodcastCategory{
+		{Name: "Technology", Subcategories: []string{"Tech News", "Podcasting"}},
+		{Name: "News", Subcategories: []string{}},
+	}
+	if got := feedCategories(feed); !reflect.DeepEqual(got, wantCategories) {
+		t.Errorf("feedCategories() = %+v, want %+v", got, wantCategories)
+	}
+	if got := feedExplicit(feed); got == nil || !*got {
+		t.Errorf("feedExplicit() = %v, want true", got)
+	}
+
+	item := feed.Items[0]
+	if got := itemEpisodeType(item); got != "trailer" {
+		t.Errorf("itemEpisodeType() = %q, want trailer", got)
+	}
+	if got := itemExplicit(item); got == nil || *got {
+		t.Errorf("itemExplicit() = %v, want false", got)
+	}
+}
+
+func TestItemPersons(t *testing.T) {
+	const feedXML = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:podcast="https://podcastindex.org/namespace/1.0">
+<channel>
+<title>Show</title>
+<item>
+<title>Interview</title>
+<podcast:person href="https://example.com/alex" img="https://example.com/alex.jpg">Alex Host</podcast:person>
+<podcast:person role="Guest" group="Cast">  Jane Doe </podcast:person>
+<podcast:person role="guest"></podcast:person>
+<enclosure url="https://example.com/interview.mp3" type="audio/mpeg"/>
+</item>
+</channel>
+</rss>`
+	feed, err := gofeed.NewParser().Parse(strings.NewReader(feedXML))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []EpisodePerson{
+		{Name: "Alex Host", Role: "host", Href: "https://example.com/alex", Img: "https://example.com/alex.jpg"},
+		{Name: "Jane Doe", Role: "guest", Group: "cast"},
+	}
+	if got := itemPersons(feed.Items[0]); !reflect.DeepEqual(got, want) {
+		t.Errorf("itemPersons() = %+v, want %+v", got, want)
+	}
+	if got := itemPersons(&gofeed.Item{}); got != nil {
+		t.Errorf("itemPersons() without tags = %+v, want nil", got)
+	}
+}
+
+func TestItemWithoutITunes(t *testing.T) {
+	item := &gofeed.Item{}
+	if got := itemEpisodeType(item); got != "" {
+		t.Errorf("itemEpisodeType() = %q, want empty", got)
+	}
+	if got := itemExplicit(item); got != nil {
+		t.Errorf("itemExplicit() = %v, want nil", *got)
+	}
+
+	item.ITunesExt = &ext.ITunesItemExtension{EpisodeType: "teaser", Explicit: "maybe"}
+	if got := itemEpisodeType(item); got != "" {
+		t.Errorf("itemEpisodeType(teaser) = %q, want empty", got)
+	}
+	if got := itemExplicit(item); got != nil {
+		t.Errorf("itemExplicit(maybe) = %v, want nil", *got)
+	}
+}
+
+func TestFeedMetadataUpdate(t *testing.T) {
+	explicit := true
+	feed := &gofeed.Feed{ITunesExt: &ext.ITunesFeedExtension{
+		Explicit:   "true",
+		Categories: []*ext.ITunesCategory{{Text: "Comedy"}},
+	}}
+
+	set := feedMetadataUpdate(Podcast{}, feed)
+	if _, ok := set["categories"]; !ok {
+		t.Errorf("feedMetadataUpdate() = %v, want categories set", set)
+	}
+	if set["explicit"] != true {
+		t.Errorf("feedMetadataUpdate() explicit = %v, want true", set["explicit"])
+	}
+
+	current := Podcast{
+		Categories: []PodcastCategory{{Name: "Comedy", Subcategories: []string{}}},
+		Explicit:   &explicit,
+	}
+	if set := feedMetadataUpdate(current, feed); set != nil {
+		t.Errorf("feedMetadataUpdate() = %v for an unchanged feed, want nil", set)
+	}
+	if set := feedMetadataUpdate(current, &gofeed.Feed{}); set != nil {
+		t.Errorf("feedMetadataUpdate() = %v for a feed without iTunes tags, want nil", set)
+	}
+}
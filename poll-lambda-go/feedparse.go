@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/mmcdole/gofeed"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Some feeds are invalid XML that gofeed rejects outright. When a feed
+// doesn't parse, parseFeed repairs it step by step, trying again after
+// each step that changed something:
+//
+//	leading_garbage  anything before the first '<' (stray BOMs, whitespace, PHP notices) is dropped
+//	charset          bytes that aren't UTF-8 are read as Windows-1252 (a superset of Latin-1)
+//	control_chars    control characters XML forbids are dropped
+//	entities         references to such characters (&#0;, &#x1B;) are dropped, outside CDATA
+//	truncated        everything after the last complete item is dropped and the document closed
+//
+// HTML entities such as &nbsp; and bare ampersands need no repair; gofeed
+// parses leniently and accepts them. The steps a feed needed are reported
+// in the poll result as "sanitizations".
+
+type feedSanitizer struct {
+	name string
+	fix  func([]byte) []byte
+}
+
+var feedSanitizers = []feedSanitizer{
+	{"leading_garbage", trimLeadingGarbage},
+	{"charset", transcodeToUTF8},
+	{"control_chars", dropControlChars},
+	{"entities", func(b []byte) []byte { return outsideCDATA(b, dropInvalidCharRefs) }},
+	{"truncated", truncateToLastItem},
+}
+
+// parseFeed parses a raw feed, repairing it if needed, and returns the
+// repairs that were applied. If no repair helps, the original error is returned.
+func parseFeed(raw []byte) (*gofeed.Feed, []string, error) {
+	feed, err := feedParser.Parse(bytes.NewReader(raw))
+	if err == nil {
+		return feed, nil, nil
+	}
+
+	var applied []string
+	fixed := raw
+	for _, s := range feedSanitizers {
+		out := s.fix(fixed)
+		if bytes.Equal(out, fixed) {
+			continue
+		}
+		fixed = out
+		applied = append(applied, s.name)
+		if feed, retryErr := feedParser.Parse(bytes.NewReader(fixed)); retryErr == nil {
+			log.Printf("Parsed malformed feed after sanitizing (%v): %v", applied, err)
+			return feed, applied, nil
+		}
+	}
+	return nil, applied, err
+}
+
+// appendMissing appends the names not in list yet
+func appendMissing(list []string, names ...string) []string {
+	for _, name := range names {
+		found := false
+		for _, have := range list {
+			found = found || have == name
+		}
+		if !found {
+			list = append(list, name)
+		}
+	}
+	return list
+}
+
+func trimLeadingGarbage(b []byte) []byte {
+	if i := bytes.IndexByte(b, '<'); i > 0 {
+		return b[i:]
+	}
+	return b
+}
+
+var xmlEncodingDecl = regexp.MustCompile(`^(<\?xml[^>]*?encoding=)["'][^"']*["']`)
+
+// transcodeToUTF8 decodes invalid UTF-8 sequences as Windows-1252 and
+// declares the result as UTF-8
+func transcodeToUTF8(b []byte) []byte {
+	if utf8.Valid(b) {
+		return b
+	}
+	decode := charmap.Windows1252.DecodeByte
+	out := make([]byte, 0, len(b)+len(b)/8)
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		if r == utf8.RuneError && size <= 1 {
+			r = decode(b[0])
+			size = 1
+		}
+		out = utf8.AppendRune(out, r)
+		b = b[size:]
+	}
+	return xmlEncodingDecl.ReplaceAll(out, []byte(`${1}"UTF-8"`))
+}
+
+// dropControlChars removes the C0 controls XML 1.0 doesn't allow
+func dropControlChars(b []byte) []byte {
+	return bytes.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			return -1
+		}
+		return r
+	}, b)
+}
+
+var charRef = regexp.MustCompile(`&#(?:[0-9]{1,10}|x[0-9a-fA-F]{1,8});`)
+
+// dropInvalidCharRefs removes character references to control characters
+// and other code points XML 1.0 doesn't allow
+func dropInvalidCharRefs(b []byte) []byte {
+	return charRef.ReplaceAllFunc(b, func(m []byte) []byte {
+		ref := string(m[2 : len(m)-1])
+		base := 10
+		if ref[0] == 'x' {
+			ref, base = ref[1:], 16
+		}
+		n, err := strconv.ParseUint(ref, base, 32)
+		if err != nil || !isXMLChar(rune(n)) {
+			return nil
+		}
+		return m
+	})
+}
+
+// isXMLChar reports whether r may appear in an XML 1.0 document
+func isXMLChar(r rune) bool {
+	return r == '\t' || r == '\n' || r == '\r' ||
+		r >= 0x20 && r <= 0xD7FF ||
+		r >= 0xE000 && r <= 0xFFFD ||
+		r >= 0x10000 && r <= 0x10FFFF
+}
+
+// outsideCDATA applies fix to the parts of b outside CDATA sections
+func outsideCDATA(b []byte, fix func([]byte) []byte) []byte {
+	var out []byte
+	for {
+		start := bytes.Index(b, []byte("<![CDATA["))
+		if start < 0 {
+			return append(out, fix(b)...)
+		}
+		end := bytes.Index(b[start:], []byte("]]>"))
+		if end < 0 {
+			return append(append(out, fix(b[:start])...), b[start:]...)
+		}
+		end += start + len("]]>")
+		out = append(append(out, fix(b[:start])...), b[start:end]...)
+		b = b[end:]
+	}
+}
+
+// truncateToLastItem keeps a cut-off feed up to its last complete item
+func truncateToLastItem(b []byte) []byte {
+	closers := []struct{ item, end string }{
+		{"</item>", "</channel></rss>"},
+		{"</entry>", "</feed>"},
+	}
+	for _, c := range closers {
+		i := bytes.LastIndex(b, []byte(c.item))
+		if i < 0 {
+			continue
+		}
+		i += len(c.item)
+		tail := bytes.Join(bytes.Fields(b[i:]), nil)
+		if bytes.Equal(tail, []byte(c.end)) {
+			return b // already complete
+		}
+		out := append([]byte{}, b[:i]...)
+		return append(out, c.end...)
+	}
+	return b
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const itemXML = `<item><title>Episode 1</title><enclosure url="https://example.com/1.mp3" type="audio/mpeg"/></item>`
+
+func rssFeed(channel string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?><rss version="2.0"><channel><title>Show</title>` + channel + `</channel></rss>`
+}
+
+func TestParseFeedSanitizes(t *testing.T) {
+	if feedParser == nil {
+		feedParser = gofeed.NewParser()
+	}
+
+	tests := []struct {
+		name      string
+		raw       string
+		want      []string
+		wantTitle string
+	}{
+		{name: "valid feed", raw: rssFeed(itemXML), wantTitle: "Episode 1"},
+		{
+			name:      "leading garbage",
+			raw:       "Notice: undefined index\n" + rssFeed(itemXML),
+			want:      []string{"leading_garbage"},
+			wantTitle: "Episode 1",
+		},
+		{
+			name:      "latin-1 bytes",
+			raw:       rssFeed(strings.Replace(itemXML, "Episode 1", "Caf\xe9 talk", 1)),
+			want:      []string{"charset"},
+			wantTitle: "Café talk",
+		},
+		{
+			name:      "control characters",
+			raw:       rssFeed(strings.Replace(itemXML, "Episode 1", "Episode\x0b 1", 1)),
+			want:      []string{"control_chars"},
+			wantTitle: "Episode 1",
+		},
+		{
+			name:      "references to forbidden characters",
+			raw:       rssFeed(strings.Replace(itemXML, "Episode 1", "Episode&#0; 1&#x1b;", 1)),
+			want:      []string{"entities"},
+			wantTitle: "Episode 1",
+		},
+		{
+			name:      "truncated download",
+			raw:       strings.TrimSuffix(rssFeed(itemXML+`<item><title>Episode 2</title><enclos`), "</channel></rss>"),
+			want:      []string{"truncated"},
+			wantTitle: "Episode 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed, applied, err := parseFeed([]byte(tt.raw))
+			if err != nil {
+				t.Fatalf("parseFeed: %v (applied %v)", err, applied)
+			}
+			if strings.Join(applied, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("applied = %v, want %v", applied, tt.want)
+			}
+			if len(feed.Items) == 0 || feed.Items[0].Title != tt.wantTitle {
+				t.Errorf("items = %v, want first title %q", feed.Items, tt.wantTitle)
+			}
+		})
+	}
+}
+
+func TestParseFeedGivesUp(t *testing.T) {
+	if feedParser == nil {
+		feedParser = gofeed.NewParser()
+	}
+	if _, _, err := parseFeed([]byte("<html><body>Not a feed</body></html>")); err == nil {
+		t.Error("expected an error for an HTML page")
+	}
+}
+
+func TestDropInvalidCharRefs(t *testing.T) {
+	in := `<title>a&#0;b&#x1B;c &#169; &#x263A; &#99999999;</title><description><![CDATA[&#0;]]></description>`
+	want := `<title>abc &#169; &#x263A; </title><description><![CDATA[&#0;]]></description>`
+	if got := string(outsideCDATA([]byte(in), dropInvalidCharRefs)); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestTranscodeToUTF8(t *testing.T) {
+	in := []byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><t>na\xefve \x93quoted\x94</t>")
+	want := "<?xml version=\"1.0\" encoding=\"UTF-8\"?><t>naïve “quoted”</t>"
+	if got := string(transcodeToUTF8(in)); got != want {
+		t.Errorf("transcodeToUTF8 = %q, want %q", got, want)
+	}
+}
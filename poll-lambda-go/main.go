@@ -21,7 +21,6 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 var (
@@ -33,12 +32,22 @@ var (
 
 // Podcast represents a podcast document
 type Podcast struct {
-	ID          primitive.ObjectID `bson:"_id"`
-	PodcastID   string             `bson:"podcast_id,omitempty"`
-	FeedURL     string             `bson:"feed_url,omitempty"`
-	RssURL      string             `bson:"rss_url,omitempty"`
-	Title       string             `bson:"title"`
-	Active      bool               `bson:"active"`
+	ID            primitive.ObjectID `bson:"_id"`
+	PodcastID     string             `bson:"podcast_id,omitempty"`
+	FeedURL       string             `bson:"feed_url,omitempty"`
+	RssURL        string             `bson:"rss_url,omitempty"`
+	Title         string             `bson:"title"`
+	Active        bool               `bson:"active"`
+	Workflow      *PodcastWorkflow   `bson:"workflow,omitempty"`
+	FeedHealth    *FeedHealth        `bson:"feed_health,omitempty"`
+	Categories    []PodcastCategory  `bson:"categories,omitempty"`
+	Explicit      *bool              `bson:"explicit,omitempty"`
+	EpisodeFilter *EpisodeFilter     `bson:"episode_filter,omitempty"`
+	WorkspaceID   string             `bson:"workspace_id,omitempty"`
+	Storage       *StorageLocation   `bson:"storage,omitempty"`
+	Private       bool               `bson:"private,omitempty"`
+	// Encryption is the workspace key of a private podcast (see resolveWorkspace)
+	Encryption *TranscriptEncryption `bson:"-"`
 }
 
 // Episode represents an episode document
@@ -48,9 +57,21 @@ type Episode struct {
 	PodcastID         string             `bson:"podcast_id"`
 	Title             string             `bson:"title"`
 	Description       string             `bson:"description"`
+	DescriptionHTML   string             `bson:"description_html,omitempty"`
+	ShowNotes         []ShowNote         `bson:"show_notes,omitempty"`
 	AudioURL          string             `bson:"audio_url"`
+	Enclosures        []Enclosure        `bson:"enclosures,omitempty"`
 	PublishedDate     *time.Time         `bson:"published_date,omitempty"`
+	DurationMinutes   int                `bson:"duration_minutes,omitempty"`
+	EpisodeType       string             `bson:"episode_type,omitempty"`
+	Explicit          *bool              `bson:"explicit,omitempty"`
+	Persons           []EpisodePerson    `bson:"persons,omitempty"`
 	TranscriptStatus  string             `bson:"transcript_status"`
+	SkipReason        string             `bson:"skip_reason,omitempty"`
+	TranscriptS3Key   string             `bson:"transcript_s3_key,omitempty"`
+	TranscriptSHA256  string             `bson:"transcript_sha256,omitempty"`
+	RerunOf           string             `bson:"rerun_of,omitempty"` // original episode whose transcript a replay shares (see rerun.go)
+	Storage           *StorageLocation   `bson:"storage,omitempty"`  // where the transcript is, if not the default buckets (see storage.go)
 	CreatedAt         time.Time          `bson:"created_at"`
 	UpdatedAt         time.Time          `bson:"updated_at"`
 }
@@ -60,12 +81,25 @@ type PodcastResult struct {
 	PodcastID    string   `json:"podcast_id"`
 	PodcastTitle string   `json:"podcast_title"`
 	NewEpisodes  int      `json:"new_episodes"`
-	Errors       []string `json:"errors"`
+	Skipped      int      `json:"skipped_episodes,omitempty"` // recorded without transcription (see episodefilter.go)
+	Reruns       int      `json:"rerun_episodes,omitempty"`   // replays linked to an earlier transcript (see rerun.go)
+	Snapshots    int      `json:"snapshots_replayed,omitempty"`
+	// Classified fetch failure (see feedhealth.go) and retries made
+	ErrorType  string   `json:"error_type,omitempty"`
+	Retries    int      `json:"retries,omitempty"`
+	FeedStatus string   `json:"feed_status,omitempty"`
+	// Repairs a malformed feed needed to parse (see feedparse.go)
+	Sanitizations []string `json:"sanitizations,omitempty"`
+	Errors        []string `json:"errors"`
 }
 
 // Request is the Lambda function request
 type Request struct {
 	PodcastID string `json:"podcast_id,omitempty"`
+	// Replay archived feed snapshots of PodcastID taken in this window
+	// (RFC 3339) instead of fetching the live feed
+	ReplayFrom string `json:"replay_from,omitempty"`
+	ReplayTo   string `json:"replay_to,omitempty"`
 }
 
 // Response is the Lambda function response
@@ -81,46 +115,38 @@ type Response struct {
 
 // StepFunctionInput is the input for Step Functions
 type StepFunctionInput struct {
-	EpisodeID string `json:"episode_id"`
-	AudioURL  string `json:"audio_url"`
-	S3Bucket  string `json:"s3_bucket"`
+	EpisodeID string           `json:"episode_id"`
+	AudioURL  string           `json:"audio_url"`
+	S3Bucket  string           `json:"s3_bucket"`
+	S3Region  string           `json:"s3_region"`
+	Storage   *StorageLocation `json:"storage"`
+	// Encryption is null unless the podcast is private (see resolveWorkspace)
+	Encryption *TranscriptEncryption `json:"encryption"`
+	Language   string                `json:"language,omitempty"`
+	Provider   string                `json:"provider,omitempty"`
+	Priority   string                `json:"priority,omitempty"`
 }
 
-func init() {
-	// Skip initialization during tests
-	if os.Getenv("SKIP_INIT") == "true" {
-		return
-	}
-	// Initialize global clients once
-	initMongoClient()
-	initSFNClient()
-	feedParser = gofeed.NewParser()
-}
-
-func initMongoClient() {
-	mongoURI := os.Getenv("MONGODB_URI")
-	if mongoURI == "" {
-		log.Fatal("MONGODB_URI environment variable not set")
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	var err error
-	mongoClient, err = mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
-	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
-	}
+var (
+	clientsOnce sync.Once
+	clientsErr  error
+)
 
-	// Ping to verify connection
-	if err = mongoClient.Ping(ctx, nil); err != nil {
-		log.Fatalf("Failed to ping MongoDB: %v", err)
+// initClients creates the clients on first use and connects to MongoDB.
+// Failing to reach MongoDB returns an error for this invocation only; the
+// next one retries instead of the execution environment being poisoned.
+func initClients(ctx context.Context) error {
+	clientsOnce.Do(func() {
+		feedParser = gofeed.NewParser()
+		clientsErr = initSFNClient()
+	})
+	if clientsErr != nil {
+		return clientsErr
 	}
-
-	log.Println("Successfully connected to MongoDB")
+	return ensureMongoClient(ctx)
 }
 
-func initSFNClient() {
+func initSFNClient() error {
 	awsConfig := &aws.Config{
 		Region: aws.String(os.Getenv("AWS_REGION")),
 	}
@@ -130,8 +156,12 @@ func initSFNClient() {
 		awsConfig.Endpoint = aws.String(endpoint)
 	}
 
-	sess := session.Must(session.NewSession(awsConfig))
-	sfnClient = sfn.New(sess)
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create AWS session: %w", err)
+	}
+	sfnClient = sfn.New(traceSession(sess))
+	return nil
 }
 
 // generateEpisodeID creates a unique episode ID from audio URL
@@ -142,11 +172,10 @@ func generateEpisodeID(audioURL string) string {
 
 // extractAudioURL gets the audio URL from a feed item
 func extractAudioURL(item *gofeed.Item) string {
-	// Check enclosures first (most common for podcasts)
-	for _, enc := range item.Enclosures {
-		if enc.Type != "" && len(enc.Type) > 6 && enc.Type[:6] == "audio/" {
-			return enc.URL
-		}
+	// Check enclosures first (most common for podcasts), picking one by
+	// AUDIO_QUALITY_POLICY when there are several
+	if enc := preferredEnclosure(audioEnclosures(item), audioQuality); enc != nil {
+		return enc.URL
 	}
 
 	// Fallback to item link
@@ -159,12 +188,7 @@ func extractAudioURL(item *gofeed.Item) string {
 
 // processPodcast handles a single podcast feed with error handling
 func processPodcast(ctx context.Context, podcast Podcast, db *mongo.Database) PodcastResult {
-	result := PodcastResult{
-		PodcastID:    podcast.ID.Hex(),
-		PodcastTitle: podcast.Title,
-		NewEpisodes:  0,
-		Errors:       []string{},
-	}
+	result := newPodcastResult(podcast)
 
 	feedURL := podcast.FeedURL
 	if feedURL == "" {
@@ -175,23 +199,50 @@ func processPodcast(ctx context.Context, podcast Podcast, db *mongo.Database) Po
 		errMsg := fmt.Sprintf("No feed URL found for podcast %s", podcast.ID.Hex())
 		log.Println(errMsg)
 		result.Errors = append(result.Errors, errMsg)
+		result.ErrorType = feedErrorNoURL
+		recordFeedHealth(ctx, db, podcast, &result, errMsg)
 		return result
 	}
 
 	log.Printf("Processing podcast: %s (%s)", podcast.Title, podcast.ID.Hex())
 
-	// Parse RSS feed
-	feed, err := feedParser.ParseURL(feedURL)
+	// Fetch and parse RSS feed, retrying transient failures
+	feed, retries, err := fetchWithRetries(ctx, feedRetries, func(ctx context.Context) (*gofeed.Feed, error) {
+		feed, sanitized, err := fetchAndParseFeed(ctx, snapshotPodcastID(podcast), feedURL)
+		result.Sanitizations = sanitized
+		return feed, err
+	})
+	result.Retries = retries
 	if err != nil {
 		errMsg := fmt.Sprintf("Failed to parse feed %s: %v", feedURL, err)
 		log.Println(errMsg)
 		result.Errors = append(result.Errors, errMsg)
+		result.ErrorType = classifyFeedError(err)
+		recordFeedHealth(ctx, db, podcast, &result, errMsg)
 		return result
 	}
+	recordFeedHealth(ctx, db, podcast, &result, "")
+	recordFeedMetadata(ctx, db, podcast, feed)
+
+	processFeed(ctx, podcast, feed, db, &result)
+	return result
+}
 
+// newPodcastResult returns empty processing stats for a podcast
+func newPodcastResult(podcast Podcast) PodcastResult {
+	return PodcastResult{
+		PodcastID:    podcast.ID.Hex(),
+		PodcastTitle: podcast.Title,
+		NewEpisodes:  0,
+		Errors:       []string{},
+	}
+}
+
+// processFeed inserts the episodes of a parsed feed that aren't known yet
+func processFeed(ctx context.Context, podcast Podcast, feed *gofeed.Feed, db *mongo.Database, result *PodcastResult) {
 	if len(feed.Items) == 0 {
 		log.Printf("No items found in feed for podcast %s", podcast.Title)
-		return result
+		return
 	}
 
 	episodesCollection := db.Collection("episodes")
@@ -206,17 +257,21 @@ func processPodcast(ctx context.Context, podcast Podcast, db *mongo.Database) Po
 			maxEpisodes, len(feed.Items), podcast.Title)
 	}
 
+	// Episodes new to this poll; they are inserted together after the loop
+	var newEpisodes []Episode
+
 	// Process each episode in the feed
 	for _, item := range itemsToProcess {
-		audioURL := extractAudioURL(item)
+		enclosures := audioEnclosures(item)
+		audioURL := normalizeURL(extractAudioURL(item))
 		if audioURL == "" {
 			log.Printf("No audio URL found for episode: %s", item.Title)
 			continue
 		}
 
-		// Check if episode already exists
+		// Check if episode already exists under any of its enclosures
 		var existingEpisode Episode
-		err := episodesCollection.FindOne(ctx, bson.M{"audio_url": audioURL}).Decode(&existingEpisode)
+		err := episodesCollection.FindOne(ctx, bson.M{"audio_url": bson.M{"$in": enclosureURLs(enclosures, audioURL)}}).Decode(&existingEpisode)
 		if err == nil {
 			// Episode already exists
 			continue
@@ -227,6 +282,27 @@ func processPodcast(ctx context.Context, podcast Podcast, db *mongo.Database) Po
 			continue
 		}
 
+		// Same episode re-listed under a new audio URL (host migration)
+		moved, err := findMovedEpisode(ctx, episodesCollection, podcast.PodcastID, item, audioURL)
+		if err != nil {
+			log.Printf("Duplicate lookup failed for episode %s: %v", item.Title, err)
+		} else if moved != nil {
+			if err := linkMovedEpisode(ctx, episodesCollection, moved, audioURL); err != nil {
+				errMsg := fmt.Sprintf("Failed to link moved episode %s: %v", moved.EpisodeID, err)
+				log.Println(errMsg)
+				result.Errors = append(result.Errors, errMsg)
+			} else {
+				log.Printf("Linked %s to existing episode %s (audio moved from %s)", audioURL, moved.EpisodeID, moved.AudioURL)
+			}
+			continue
+		}
+
+		// Replay of an episode already transcribed (see rerun.go)
+		original, err := findRerunOriginal(ctx, episodesCollection, podcast.PodcastID, item)
+		if err != nil {
+			log.Printf("Rerun lookup failed for episode %s: %v", item.Title, err)
+		}
+
 		// Generate episode ID
 		episodeID := generateEpisodeID(audioURL)
 
@@ -237,83 +313,98 @@ func processPodcast(ctx context.Context, podcast Podcast, db *mongo.Database) Po
 		}
 
 		// Create episode document
+		description, showNotes := sanitizeDescription(item.Description)
 		episode := Episode{
 			ID:               episodeID,
 			EpisodeID:        episodeID,
 			PodcastID:        podcast.PodcastID,
 			Title:            item.Title,
-			Description:      item.Description,
+			Description:      description,
+			DescriptionHTML:  item.Description,
+			ShowNotes:        showNotes,
 			AudioURL:         audioURL,
+			Enclosures:       enclosures,
 			PublishedDate:    publishedDate,
+			DurationMinutes:  itemDurationMinutes(item),
+			EpisodeType:      itemEpisodeType(item),
+			Explicit:         itemExplicit(item),
+			Persons:          itemPersons(item),
 			TranscriptStatus: "pending",
 			CreatedAt:        time.Now().UTC(),
 			UpdatedAt:        time.Now().UTC(),
 		}
+		if reason := transcriptionSkipReason(podcast, item); reason != "" {
+			episode.TranscriptStatus = "skipped"
+			episode.SkipReason = reason
+		} else if original != nil {
+			linkRerun(&episode, original)
+		}
 
-		// Insert episode into MongoDB
-		_, err = episodesCollection.InsertOne(ctx, episode)
-		if err != nil {
-			if mongo.IsDuplicateKeyError(err) {
-				log.Printf("Duplicate episode detected (race condition): %s", episodeID)
-				continue
-			}
-			errMsg := fmt.Sprintf("Failed to insert episode %s: %v", episodeID, err)
+		newEpisodes = append(newEpisodes, episode)
+	}
+
+	// Insert episodes into MongoDB (see episodewrite.go)
+	var pending []pendingEpisode
+	for i, inserted := range insertEpisodes(ctx, episodesCollection, newEpisodes) {
+		episode := newEpisodes[i]
+		switch inserted.Outcome {
+		case insertExisting:
+			log.Printf("Duplicate episode detected (race condition): %s", episode.EpisodeID)
+			continue
+		case insertFailed:
+			errMsg := fmt.Sprintf("Failed to insert episode %s: %v", episode.EpisodeID, inserted.Err)
 			log.Println(errMsg)
 			result.Errors = append(result.Errors, errMsg)
 			continue
 		}
 
-		log.Printf("Inserted new episode: %s (%s)", item.Title, episodeID)
+		log.Printf("Inserted new episode: %s (%s)", episode.Title, episode.EpisodeID)
 		result.NewEpisodes++
-
-		// Trigger Step Functions workflow
-		if err := triggerStepFunction(ctx, episodeID, audioURL); err != nil {
-			errMsg := fmt.Sprintf("Failed to trigger Step Function for %s: %v", episodeID, err)
-			log.Println(errMsg)
-			result.Errors = append(result.Errors, errMsg)
-
-			// Update episode status to failed
-			_, _ = episodesCollection.UpdateOne(
-				ctx,
-				bson.M{"_id": episodeID},
-				bson.M{"$set": bson.M{"status": "failed", "error": err.Error()}},
-			)
-		} else {
-			log.Printf("Triggered Step Function for episode %s", episodeID)
+		if episode.SkipReason != "" {
+			log.Printf("Not transcribing episode %s: %s", episode.EpisodeID, episode.SkipReason)
+			result.Skipped++
+			continue
 		}
+		if episode.RerunOf != "" {
+			log.Printf("Episode %s is a rerun of %s, reusing its transcript", episode.EpisodeID, episode.RerunOf)
+			result.Reruns++
+			continue
+		}
+		pending = append(pending, pendingEpisode{EpisodeID: episode.EpisodeID, AudioURL: episode.AudioURL})
 	}
 
-	return result
+	// Trigger Step Functions workflow
+	triggerEpisodes(ctx, podcast, pending, episodesCollection, result)
 }
 
-// triggerStepFunction starts a Step Functions execution
-func triggerStepFunction(ctx context.Context, episodeID, audioURL string) error {
-	stepFunctionARN := os.Getenv("STEP_FUNCTION_ARN")
-	s3Bucket := os.Getenv("S3_BUCKET")
-	if s3Bucket == "" {
-		s3Bucket = "podcast-audio-bucket"
-	}
-
-	input := StepFunctionInput{
-		EpisodeID: episodeID,
-		AudioURL:  audioURL,
-		S3Bucket:  s3Bucket,
-	}
+// triggerStepFunction starts a Step Functions execution on the podcast's
+// workflow and returns its ARN
+func triggerStepFunction(ctx context.Context, podcast Podcast, episodeID, audioURL string) (string, error) {
+	stepFunctionARN := stateMachineFor(podcast.Workflow, workflowRoutes, os.Getenv("STEP_FUNCTION_ARN"))
+	input := newStepFunctionInput(podcast, episodeID, audioURL)
 
 	inputJSON, err := json.Marshal(input)
 	if err != nil {
-		return fmt.Errorf("failed to marshal input: %w", err)
+		return "", fmt.Errorf("failed to marshal input: %w", err)
 	}
 
-	executionName := fmt.Sprintf("episode-%s-%d", episodeID, time.Now().Unix())
-
-	_, err = sfnClient.StartExecutionWithContext(ctx, &sfn.StartExecutionInput{
+	if err := startLimiter.wait(ctx); err != nil {
+		return "", err
+	}
+	name := executionName(episodeID, time.Now())
+	out, err := sfnClient.StartExecutionWithContext(ctx, &sfn.StartExecutionInput{
 		StateMachineArn: aws.String(stepFunctionARN),
-		Name:            aws.String(executionName),
+		Name:            aws.String(name),
 		Input:           aws.String(string(inputJSON)),
 	})
-
-	return err
+	if isExecutionAlreadyExists(err) {
+		log.Printf("Execution %s already exists, treating the start as done", name)
+		return executionARN(stepFunctionARN, name), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(out.ExecutionArn), nil
 }
 
 // HandleRequest is the Lambda handler
@@ -339,6 +430,22 @@ func HandleRequest(ctx context.Context, event json.RawMessage) (Response, error)
 		PodcastResults: []PodcastResult{},
 	}
 
+	replay, err := parseReplayWindow(request, time.Now())
+	if err != nil {
+		response.StatusCode = 400
+		response.Message = err.Error()
+		response.Errors = append(response.Errors, err.Error())
+		return response, err
+	}
+
+	if err := initClients(ctx); err != nil {
+		log.Printf("Client initialization failed: %v", err)
+		response.StatusCode = 503
+		response.Message = "Database unavailable"
+		response.Errors = append(response.Errors, err.Error())
+		return response, err
+	}
+
 	// Get database
 	db := mongoClient.Database("podcast_db")
 	podcastsCollection := db.Collection("podcasts")
@@ -399,7 +506,12 @@ func HandleRequest(ctx context.Context, event json.RawMessage) (Response, error)
 			defer wg.Done()
 			defer func() { <-semaphore }() // Release semaphore
 
-			result := processPodcast(ctx, p, db)
+			var result PodcastResult
+			if replay != nil {
+				result = replayPodcast(ctx, p, *replay, db)
+			} else {
+				result = processPodcast(ctx, p, db)
+			}
 
 			mu.Lock()
 			results = append(results, result)
@@ -415,7 +527,10 @@ func HandleRequest(ctx context.Context, event json.RawMessage) (Response, error)
 	wg.Wait()
 	response.PodcastResults = results
 
-	if request.PodcastID != "" {
+	if replay != nil {
+		response.Message = fmt.Sprintf("Replay completed for podcast %s", request.PodcastID)
+		log.Printf("Snapshot replay complete for podcast %s. Found %d new episodes", request.PodcastID, response.TotalEpisodes)
+	} else if request.PodcastID != "" {
 		response.Message = fmt.Sprintf("Polling completed for podcast %s", request.PodcastID)
 		log.Printf("RSS polling complete for podcast %s. Found %d new episodes", request.PodcastID, response.TotalEpisodes)
 	} else {
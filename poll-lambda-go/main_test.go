@@ -94,6 +94,24 @@ func TestExtractAudioURL(t *testing.T) {
 			},
 			expected: "https://example.com/podcast.mp3",
 		},
+		{
+			name: "several audio qualities, smallest wins",
+			item: &gofeed.Item{
+				Enclosures: []*gofeed.Enclosure{
+					{
+						URL:    "https://example.com/podcast-128k.mp3",
+						Type:   "audio/mpeg",
+						Length: "57600000",
+					},
+					{
+						URL:    "https://example.com/podcast-64k.mp3",
+						Type:   "audio/mpeg",
+						Length: "28800000",
+					},
+				},
+			},
+			expected: "https://example.com/podcast-64k.mp3",
+		},
 		{
 			name: "non-audio enclosure, fallback to link",
 			item: &gofeed.Item{
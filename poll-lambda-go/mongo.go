@@ -0,0 +1,260 @@
+package main
+
+// This file is kept identical in poll-lambda-go and merge-transcript-lambda-go.
+// Each Lambda is its own module and Docker build context, so the code is
+// copied rather than shared; `make check-lambda-copies` fails if the copies
+// differ.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+// MongoDB client tuning comes from the environment:
+//
+//	MONGODB_MAX_POOL_SIZE                 connections per client (driver default 100)
+//	MONGODB_READ_PREFERENCE               primary, primaryPreferred, secondary, secondaryPreferred or nearest
+//	MONGODB_WRITE_CONCERN                 "majority" or a number of acknowledging nodes
+//	MONGODB_SERVER_SELECTION_TIMEOUT      Go duration (driver default 30s)
+//	MONGODB_CONNECT_RETRIES               connection attempts before giving up (default 3)
+//	MONGODB_CONNECT_BACKOFF               wait before the first retry, doubled each time (default 500ms)
+//	MONGODB_SLOW_QUERY                    Go duration; commands slower than this are logged
+//
+// Clients are created by the first invocation (initClients); if MongoDB
+// can't be reached, that invocation fails cleanly and the next one retries.
+
+const (
+	defaultConnectRetries = 3
+	defaultConnectBackoff = 500 * time.Millisecond
+	maxConnectBackoff     = 5 * time.Second
+	mongoConnectTimeout   = 10 * time.Second
+)
+
+var (
+	errMongoURIMissing = errors.New("MONGODB_URI environment variable not set")
+	mongoMu            sync.Mutex
+	connectedMongoURI  string
+)
+
+// mongoClientOptions builds client options for uri from the MONGODB_* tuning variables
+func mongoClientOptions(uri string) (*options.ClientOptions, error) {
+	opts := options.Client().ApplyURI(uri)
+
+	if raw := os.Getenv("MONGODB_MAX_POOL_SIZE"); raw != "" {
+		size, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MONGODB_MAX_POOL_SIZE %q", raw)
+		}
+		opts.SetMaxPoolSize(size)
+	}
+
+	if raw := os.Getenv("MONGODB_READ_PREFERENCE"); raw != "" {
+		mode, err := readpref.ModeFromString(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MONGODB_READ_PREFERENCE %q", raw)
+		}
+		pref, err := readpref.New(mode)
+		if err != nil {
+			return nil, err
+		}
+		opts.SetReadPreference(pref)
+	}
+
+	if raw := os.Getenv("MONGODB_WRITE_CONCERN"); raw != "" {
+		if raw == "majority" {
+			opts.SetWriteConcern(writeconcern.Majority())
+		} else if nodes, err := strconv.Atoi(raw); err == nil && nodes >= 0 {
+			opts.SetWriteConcern(&writeconcern.WriteConcern{W: nodes})
+		} else {
+			return nil, fmt.Errorf("invalid MONGODB_WRITE_CONCERN %q", raw)
+		}
+	}
+
+	if raw := os.Getenv("MONGODB_SERVER_SELECTION_TIMEOUT"); raw != "" {
+		timeout, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MONGODB_SERVER_SELECTION_TIMEOUT %q", raw)
+		}
+		opts.SetServerSelectionTimeout(timeout)
+	}
+
+	if raw := os.Getenv("MONGODB_SLOW_QUERY"); raw != "" {
+		threshold, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MONGODB_SLOW_QUERY %q", raw)
+		}
+		opts.SetMonitor(slowQueryMonitor(threshold))
+	}
+
+	if tracingEnabled {
+		opts.SetMonitor(chainMonitors(opts.Monitor, mongoTraceMonitor()))
+	}
+
+	return opts, nil
+}
+
+// slowQueryMonitor logs commands slower than threshold with their collection
+// and filter fields (never values), e.g. to spot unindexed lookups
+func slowQueryMonitor(threshold time.Duration) *event.CommandMonitor {
+	var mu sync.Mutex
+	started := map[int64]string{}
+
+	finished := func(e event.CommandFinishedEvent, failure string) {
+		mu.Lock()
+		target, ok := started[e.RequestID]
+		delete(started, e.RequestID)
+		mu.Unlock()
+		if ok && e.Duration >= threshold {
+			log.Printf("Slow query (%s): %s %s%s", e.Duration.Round(time.Millisecond), e.CommandName, target, failure)
+		}
+	}
+
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			mu.Lock()
+			started[e.RequestID] = commandTarget(e.CommandName, e.Command)
+			mu.Unlock()
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			finished(e.CommandFinishedEvent, "")
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			finished(e.CommandFinishedEvent, " failed: "+e.Failure)
+		},
+	}
+}
+
+// commandTarget describes a command as "collection filter=[field ...]"
+func commandTarget(name string, command bson.Raw) string {
+	collection, _ := command.Lookup(name).StringValueOK()
+	var filter bson.Raw
+	switch name {
+	case "find", "count", "distinct", "findAndModify":
+		filter, _ = command.Lookup("filter").DocumentOK()
+		if filter == nil {
+			filter, _ = command.Lookup("query").DocumentOK()
+		}
+	case "update":
+		filter, _ = command.Lookup("updates", "0", "q").DocumentOK()
+	case "delete":
+		filter, _ = command.Lookup("deletes", "0", "q").DocumentOK()
+	}
+	if filter == nil {
+		return collection
+	}
+
+	elements, _ := filter.Elements()
+	fields := make([]string, 0, len(elements))
+	for _, element := range elements {
+		fields = append(fields, element.Key())
+	}
+	return fmt.Sprintf("%s filter=[%s]", collection, strings.Join(fields, " "))
+}
+
+// connectRetryPolicy reads MONGODB_CONNECT_RETRIES and MONGODB_CONNECT_BACKOFF
+func connectRetryPolicy() (int, time.Duration) {
+	retries, backoff := defaultConnectRetries, defaultConnectBackoff
+	if n, err := strconv.Atoi(os.Getenv("MONGODB_CONNECT_RETRIES")); err == nil && n > 0 {
+		retries = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("MONGODB_CONNECT_BACKOFF")); err == nil && d >= 0 {
+		backoff = d
+	}
+	return retries, backoff
+}
+
+// connectMongo connects to uri and pings it, retrying with exponential backoff
+func connectMongo(ctx context.Context, uri string) (*mongo.Client, error) {
+	opts, err := mongoClientOptions(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	retries, backoff := connectRetryPolicy()
+	for attempt := 1; ; attempt++ {
+		client, err := mongo.Connect(ctx, opts)
+		if err == nil {
+			pingCtx, cancel := context.WithTimeout(ctx, mongoConnectTimeout)
+			err = client.Ping(pingCtx, nil)
+			cancel()
+			if err == nil {
+				return client, nil
+			}
+			client.Disconnect(context.Background())
+		}
+
+		if attempt >= retries {
+			return nil, fmt.Errorf("failed to connect to MongoDB after %d attempts: %w", attempt, err)
+		}
+		log.Printf("MongoDB connection attempt %d/%d failed, retrying in %s: %v", attempt, retries, backoff, err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to connect to MongoDB: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+}
+
+// ensureMongoClient connects if there is no client yet (e.g. MongoDB was
+// unreachable at cold start). Every invocation calls it through initClients:
+// warm invocations reuse the client an earlier one created, so this is also
+// where a MONGODB_URI rotated since then is picked up.
+func ensureMongoClient(ctx context.Context) error {
+	mongoMu.Lock()
+	defer mongoMu.Unlock()
+	if mongoClient != nil {
+		refreshMongoClient(ctx)
+		return nil
+	}
+
+	mongoURI, err := secrets.get(ctx, "MONGODB_URI")
+	if err != nil {
+		return err
+	}
+	if mongoURI == "" {
+		return errMongoURIMissing
+	}
+
+	client, err := connectMongo(ctx, mongoURI)
+	if err != nil {
+		return err
+	}
+	mongoClient, connectedMongoURI = client, mongoURI
+	log.Println("Successfully connected to MongoDB")
+	return nil
+}
+
+// refreshMongoClient reconnects when MONGODB_URI has been rotated since the
+// client was created. Failures keep the current client. Callers hold mongoMu.
+func refreshMongoClient(ctx context.Context) {
+	mongoURI, err := secrets.get(ctx, "MONGODB_URI")
+	if err != nil || mongoURI == "" || mongoURI == connectedMongoURI {
+		return
+	}
+
+	log.Println("MONGODB_URI changed, reconnecting to MongoDB")
+	client, err := connectMongo(ctx, mongoURI)
+	if err != nil {
+		log.Printf("Warning: failed to reconnect with rotated MONGODB_URI: %v", err)
+		return
+	}
+
+	previous := mongoClient
+	mongoClient, connectedMongoURI = client, mongoURI
+	go previous.Disconnect(context.Background())
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestMongoClientOptions(t *testing.T) {
+	t.Setenv("MONGODB_MAX_POOL_SIZE", "20")
+	t.Setenv("MONGODB_READ_PREFERENCE", "secondaryPreferred")
+	t.Setenv("MONGODB_WRITE_CONCERN", "majority")
+	t.Setenv("MONGODB_SERVER_SELECTION_TIMEOUT", "3s")
+
+	opts, err := mongoClientOptions("mongodb://localhost:27017")
+	if err != nil {
+		t.Fatalf("mongoClientOptions() error = %v", err)
+	}
+	if opts.MaxPoolSize == nil || *opts.MaxPoolSize != 20 {
+		t.Errorf("MaxPoolSize = %v, want 20", opts.MaxPoolSize)
+	}
+	if opts.ReadPreference == nil || opts.ReadPreference.Mode() != readpref.SecondaryPreferredMode {
+		t.Errorf("ReadPreference = %v, want secondaryPreferred", opts.ReadPreference)
+	}
+	if opts.WriteConcern == nil || opts.WriteConcern.W != "majority" {
+		t.Errorf("WriteConcern = %v, want majority", opts.WriteConcern)
+	}
+	if opts.ServerSelectionTimeout == nil || *opts.ServerSelectionTimeout != 3*time.Second {
+		t.Errorf("ServerSelectionTimeout = %v, want 3s", opts.ServerSelectionTimeout)
+	}
+}
+
+func TestMongoClientOptionsInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"MONGODB_MAX_POOL_SIZE", "lots"},
+		{"MONGODB_READ_PREFERENCE", "closest"},
+		{"MONGODB_WRITE_CONCERN", "all"},
+		{"MONGODB_SERVER_SELECTION_TIMEOUT", "5"},
+		{"MONGODB_SLOW_QUERY", "100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.name, tt.value)
+			if _, err := mongoClientOptions("mongodb://localhost:27017"); err == nil {
+				t.Errorf("mongoClientOptions() with %s=%q should fail", tt.name, tt.value)
+			}
+		})
+	}
+}
+
+func TestConnectRetryPolicy(t *testing.T) {
+	retries, backoff := connectRetryPolicy()
+	if retries != defaultConnectRetries || backoff != defaultConnectBackoff {
+		t.Errorf("defaults = %d, %s, want %d, %s", retries, backoff, defaultConnectRetries, defaultConnectBackoff)
+	}
+
+	t.Setenv("MONGODB_CONNECT_RETRIES", "6")
+	t.Setenv("MONGODB_CONNECT_BACKOFF", "2s")
+	if retries, backoff := connectRetryPolicy(); retries != 6 || backoff != 2*time.Second {
+		t.Errorf("connectRetryPolicy() = %d, %s, want 6, 2s", retries, backoff)
+	}
+}
+
+func TestCommandTarget(t *testing.T) {
+	find, _ := bson.Marshal(bson.D{{Key: "find", Value: "episodes"}, {Key: "filter", Value: bson.M{"audio_url": "https://x/1.mp3"}}})
+	if got := commandTarget("find", find); got != "episodes filter=[audio_url]" {
+		t.Errorf("commandTarget(find) = %q", got)
+	}
+
+	update, _ := bson.Marshal(bson.D{{Key: "update", Value: "episodes"}, {Key: "updates", Value: bson.A{bson.M{"q": bson.M{"episode_id": "e1"}, "u": bson.M{}}}}})
+	if got := commandTarget("update", update); got != "episodes filter=[episode_id]" {
+		t.Errorf("commandTarget(update) = %q", got)
+	}
+
+	insert, _ := bson.Marshal(bson.D{{Key: "insert", Value: "episodes"}})
+	if got := commandTarget("insert", insert); got != "episodes" {
+		t.Errorf("commandTarget(insert) = %q", got)
+	}
+}
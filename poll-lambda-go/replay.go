@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// A replay re-processes a podcast's archived feed snapshots (see
+// snapshot.go) instead of its live feed, recovering episodes missed
+// because of a bug or downtime after the publisher stopped listing them.
+// Snapshots are processed oldest first through the same path as a live
+// poll, so episodes that already exist are left alone.
+
+// maxReplayWindow bounds a replay; at the default 30 minute poll interval
+// it covers a few thousand snapshots at most
+const maxReplayWindow = 90 * 24 * time.Hour
+
+// replayWindow is the half-open range of snapshot times to replay
+type replayWindow struct {
+	from time.Time
+	to   time.Time
+}
+
+// parseReplayWindow returns the window a request asks to replay, or nil for
+// a normal poll. replay_to defaults to now.
+func parseReplayWindow(req Request, now time.Time) (*replayWindow, error) {
+	if req.ReplayFrom == "" && req.ReplayTo == "" {
+		return nil, nil
+	}
+	if req.PodcastID == "" {
+		return nil, errors.New("replay requires podcast_id")
+	}
+	if req.ReplayFrom == "" {
+		return nil, errors.New("replay requires replay_from")
+	}
+
+	from, err := time.Parse(time.RFC3339, req.ReplayFrom)
+	if err != nil {
+		return nil, fmt.Errorf("invalid replay_from %q: want RFC 3339", req.ReplayFrom)
+	}
+	to := now
+	if req.ReplayTo != "" {
+		if to, err = time.Parse(time.RFC3339, req.ReplayTo); err != nil {
+			return nil, fmt.Errorf("invalid replay_to %q: want RFC 3339", req.ReplayTo)
+		}
+	}
+	if !from.Before(to) {
+		return nil, errors.New("replay_from must be before replay_to")
+	}
+	if to.Sub(from) > maxReplayWindow {
+		return nil, fmt.Errorf("replay window exceeds %d days", int(maxReplayWindow.Hours()/24))
+	}
+	return &replayWindow{from: from.UTC(), to: to.UTC()}, nil
+}
+
+// replayPodcast processes the podcast's snapshots taken within the window
+func replayPodcast(ctx context.Context, podcast Podcast, window replayWindow, db *mongo.Database) PodcastResult {
+	result := newPodcastResult(podcast)
+	podcastID := snapshotPodcastID(podcast)
+
+	keys, err := snapshots.list(ctx, podcastID, window.from, window.to)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to list feed snapshots of %s: %v", podcastID, err)
+		log.Println(errMsg)
+		result.Errors = append(result.Errors, errMsg)
+		return result
+	}
+	log.Printf("Replaying %d feed snapshots of %s (%s to %s)", len(keys), podcastID,
+		window.from.Format(time.RFC3339), window.to.Format(time.RFC3339))
+
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("Replay stopped before %s: %v", key, ctx.Err()))
+			break
+		}
+		raw, err := snapshots.load(ctx, key)
+		if err != nil {
+			errMsg := fmt.Sprintf("Failed to load feed snapshot %s: %v", key, err)
+			log.Println(errMsg)
+			result.Errors = append(result.Errors, errMsg)
+			continue
+		}
+		feed, sanitized, err := parseFeed(raw)
+		if err != nil {
+			// Snapshots of broken feeds are kept on purpose; nothing to recover from them
+			log.Printf("Skipping unparseable feed snapshot %s: %v", key, err)
+			continue
+		}
+		result.Sanitizations = appendMissing(result.Sanitizations, sanitized...)
+		processFeed(ctx, podcast, feed, db, &result)
+		result.Snapshots++
+	}
+	return result
+}
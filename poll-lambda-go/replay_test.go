@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseReplayWindow(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		req      Request
+		wantNil  bool
+		wantErr  bool
+		wantFrom time.Time
+		wantTo   time.Time
+	}{
+		{name: "normal poll", req: Request{PodcastID: "pod_1"}, wantNil: true},
+		{
+			name:     "explicit window",
+			req:      Request{PodcastID: "pod_1", ReplayFrom: "2024-05-01T00:00:00Z", ReplayTo: "2024-05-02T02:00:00+02:00"},
+			wantFrom: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "open end defaults to now",
+			req:      Request{PodcastID: "pod_1", ReplayFrom: "2024-05-01T00:00:00Z"},
+			wantFrom: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			wantTo:   now,
+		},
+		{name: "needs a podcast", req: Request{ReplayFrom: "2024-05-01T00:00:00Z"}, wantErr: true},
+		{name: "needs a start", req: Request{PodcastID: "pod_1", ReplayTo: "2024-05-01T00:00:00Z"}, wantErr: true},
+		{name: "bad time", req: Request{PodcastID: "pod_1", ReplayFrom: "2024-05-01"}, wantErr: true},
+		{
+			name:    "reversed",
+			req:     Request{PodcastID: "pod_1", ReplayFrom: "2024-05-02T00:00:00Z", ReplayTo: "2024-05-01T00:00:00Z"},
+			wantErr: true,
+		},
+		{name: "too long", req: Request{PodcastID: "pod_1", ReplayFrom: "2023-01-01T00:00:00Z"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, err := parseReplayWindow(tt.req, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.wantNil {
+				if window != nil {
+					t.Errorf("window = %+v, want nil", window)
+				}
+				return
+			}
+			if !window.from.Equal(tt.wantFrom) || !window.to.Equal(tt.wantTo) {
+				t.Errorf("window = %s..%s, want %s..%s", window.from, window.to, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
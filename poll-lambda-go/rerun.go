@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Many feeds republish an old episode as a "Replay", "Encore" or
+// "Rebroadcast", with a new audio URL and date. An item is a rerun of an
+// earlier, already transcribed episode of the same podcast when its title
+// looks like a rerun (rerunTitle, see episodefilter.go), matches once the
+// marker is stripped and its duration is within the dedup tolerance, or,
+// without a marker, when title and duration are identical.
+// A rerun gets its own episode document sharing the original's transcript
+// (rerun_of names the original) and is not transcribed again.
+// Mirrors the API's episode_dedup.py.
+
+// rerunMarker matches the strippable rerun markers: "Replay:" style
+// prefixes and "(Encore)" or "- Rebroadcast" style suffixes
+var rerunMarker = regexp.MustCompile(
+	`(?i)^\W*(?:replay|encore|rebroadcast|re-?run|repost|best of|from the archives?)\b\W*` +
+		`|\W*[(\[]\s*(?:replay|encore|rebroadcast|re-?run|repost|from the archives?)\s*[)\]]\W*$` +
+		`|\s+[-–—|:]\s*(?:replay|encore|rebroadcast|re-?run|repost)\W*$`,
+)
+
+// stripRerunMarker removes rerun markers from a title and reports whether
+// it looks like a rerun
+func stripRerunMarker(title string) (string, bool) {
+	stripped := rerunMarker.ReplaceAllString(title, "")
+	return stripped, stripped != title || rerunTitle.MatchString(title)
+}
+
+// isRerunOf reports whether a feed item republishes an existing episode
+func isRerunOf(title string, published *time.Time, durationMinutes int, existing Episode) bool {
+	if published == nil || existing.PublishedDate == nil {
+		return false
+	}
+	// Within the window it would be the same episode re-listed (see dedup.go)
+	if published.Sub(*existing.PublishedDate) <= dedupPublishedWindow {
+		return false
+	}
+	stripped, marked := stripRerunMarker(title)
+	original, _ := stripRerunMarker(existing.Title)
+	normalized := normalizeTitle(stripped)
+	if normalized == "" || normalized != normalizeTitle(original) {
+		return false
+	}
+	if marked {
+		return durationsMatch(durationMinutes, existing.DurationMinutes)
+	}
+	return durationMinutes != 0 && durationMinutes == existing.DurationMinutes
+}
+
+// findRerunOriginal looks for the earliest transcribed episode of the
+// podcast that a feed item republishes. Items without a title or
+// publication date are never matched.
+func findRerunOriginal(ctx context.Context, coll *mongo.Collection, podcastID string, item *gofeed.Item) (*Episode, error) {
+	stripped, _ := stripRerunMarker(item.Title)
+	if podcastID == "" || item.PublishedParsed == nil || normalizeTitle(stripped) == "" {
+		return nil, nil
+	}
+
+	published := item.PublishedParsed.UTC()
+	cursor, err := coll.Find(ctx, bson.M{
+		"podcast_id":        podcastID,
+		"deleted_at":        nil,
+		"transcript_status": "completed",
+		"transcript_s3_key": bson.M{"$nin": bson.A{nil, ""}},
+		"published_date":    bson.M{"$lt": published.Add(-dedupPublishedWindow)},
+	}, options.Find().SetSort(bson.D{{Key: "published_date", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	durationMinutes := itemDurationMinutes(item)
+	for cursor.Next(ctx) {
+		var candidate Episode
+		if err := cursor.Decode(&candidate); err != nil {
+			continue
+		}
+		if isRerunOf(item.Title, &published, durationMinutes, candidate) {
+			return &candidate, nil
+		}
+	}
+	return nil, cursor.Err()
+}
+
+// linkRerun makes a new episode share its original's transcript
+func linkRerun(episode *Episode, original *Episode) {
+	episode.TranscriptStatus = "completed"
+	episode.TranscriptS3Key = original.TranscriptS3Key
+	episode.TranscriptSHA256 = original.TranscriptSHA256
+	episode.Storage = original.Storage
+	episode.RerunOf = original.EpisodeID
+}
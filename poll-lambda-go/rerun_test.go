@@ -0,0 +1,92 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStripRerunMarker(t *testing.T) {
+	tests := []struct {
+		title    string
+		expected string
+		marked   bool
+	}{
+		{"Replay: Episode 12: The Big One", "Episode 12: The Big One", true},
+		{"ENCORE - Episode 12: The Big One", "Episode 12: The Big One", true},
+		{"Best of: Episode 12", "Episode 12", true},
+		{"Episode 12: The Big One (Rebroadcast)", "Episode 12: The Big One", true},
+		{"Episode 12: The Big One [Re-run]", "Episode 12: The Big One", true},
+		{"Episode 12: The Big One - Replay", "Episode 12: The Big One", true},
+		{"Episode 12: The Big One", "Episode 12: The Big One", false},
+		{"Replaying the tape: a history of cassettes", "Replaying the tape: a history of cassettes", false},
+	}
+
+	for _, tt := range tests {
+		stripped, marked := stripRerunMarker(tt.title)
+		if stripped != tt.expected || marked != tt.marked {
+			t.Errorf("stripRerunMarker(%q) = %q, %v, want %q, %v", tt.title, stripped, marked, tt.expected, tt.marked)
+		}
+	}
+}
+
+func TestIsRerunOf(t *testing.T) {
+	published := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	existing := Episode{
+		Title:           "Episode 12: The Big One",
+		PublishedDate:   &published,
+		DurationMinutes: 62,
+	}
+
+	sameDay := published.Add(5 * time.Hour)
+	yearLater := published.AddDate(1, 0, 0)
+
+	tests := []struct {
+		name      string
+		title     string
+		published *time.Time
+		duration  int
+		expected  bool
+	}{
+		{"marked replay", "Replay: Episode 12: The Big One", &yearLater, 62, true},
+		{"marked replay with new intro", "Episode 12: The Big One (Encore)", &yearLater, 63, true},
+		{"marked replay, unknown duration", "Replay: Episode 12: The Big One", &yearLater, 0, true},
+		{"unmarked identical title and duration", "Episode 12: The Big One", &yearLater, 62, true},
+		{"unmarked, duration differs", "Episode 12: The Big One", &yearLater, 63, false},
+		{"unmarked, unknown duration", "Episode 12: The Big One", &yearLater, 0, false},
+		{"marked, different episode", "Replay: Episode 13: The Sequel", &yearLater, 62, false},
+		{"marked, much longer", "Replay: Episode 12: The Big One", &yearLater, 90, false},
+		{"same day is a re-listing, not a rerun", "Episode 12: The Big One", &sameDay, 62, false},
+		{"no publication date", "Replay: Episode 12: The Big One", nil, 62, false},
+		{"empty title", "Replay", &yearLater, 62, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := isRerunOf(tt.title, tt.published, tt.duration, existing); result != tt.expected {
+				t.Errorf("isRerunOf() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsRerunOfMarkedOriginal(t *testing.T) {
+	published := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	again := published.AddDate(0, 6, 0)
+	existing := Episode{Title: "Encore: Episode 12", PublishedDate: &published, DurationMinutes: 40}
+
+	if !isRerunOf("Replay: Episode 12", &again, 40, existing) {
+		t.Error("isRerunOf() should match a replay of an episode that was itself marked")
+	}
+}
+
+func TestLinkRerun(t *testing.T) {
+	eu := &StorageLocation{Location: "eu", Region: "eu-west-1", Bucket: "eu-audio"}
+	original := &Episode{EpisodeID: "orig", TranscriptS3Key: "transcripts/sha256/abc.txt", TranscriptSHA256: "abc", Storage: eu}
+	episode := Episode{EpisodeID: "new", TranscriptStatus: "pending"}
+	linkRerun(&episode, original)
+
+	if episode.TranscriptStatus != "completed" || episode.TranscriptS3Key != original.TranscriptS3Key ||
+		episode.TranscriptSHA256 != "abc" || episode.RerunOf != "orig" || episode.Storage != eu {
+		t.Errorf("linkRerun() = %+v", episode)
+	}
+}
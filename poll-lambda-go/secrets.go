@@ -0,0 +1,164 @@
+package main
+
+// This file is kept identical in poll-lambda-go and merge-transcript-lambda-go.
+// Each Lambda is its own module and Docker build context, so the code is
+// copied rather than shared; `make check-lambda-copies` fails if the copies
+// differ.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// Secret settings such as MONGODB_URI need not be plaintext environment
+// variables: NAME_SECRET_ARN names a Secrets Manager secret (append
+// "#key" to read one key of a JSON secret) and NAME_SSM_PARAMETER an SSM
+// SecureString parameter. Fetched values are cached for SECRETS_CACHE_TTL
+// (default 5m), after which warm invocations re-read them, so rotated
+// secrets are picked up without a cold start.
+
+const defaultSecretsCacheTTL = 5 * time.Minute
+
+type cachedSecret struct {
+	value   string
+	fetched time.Time
+}
+
+// secretsProvider resolves settings from the environment, Secrets Manager or SSM
+type secretsProvider struct {
+	mu             sync.Mutex
+	secretsManager secretsmanageriface.SecretsManagerAPI
+	ssm            ssmiface.SSMAPI
+	ttl            time.Duration
+	cache          map[string]cachedSecret
+	now            func() time.Time
+}
+
+var secrets = newSecretsProvider()
+
+func newSecretsProvider() *secretsProvider {
+	ttl := defaultSecretsCacheTTL
+	if raw := os.Getenv("SECRETS_CACHE_TTL"); raw != "" {
+		if parsed, err := time.ParseDuration(raw); err == nil {
+			ttl = parsed
+		} else {
+			log.Printf("Warning: invalid SECRETS_CACHE_TTL %q, using %s", raw, ttl)
+		}
+	}
+	return &secretsProvider{ttl: ttl, cache: map[string]cachedSecret{}, now: time.Now}
+}
+
+// clients creates the AWS clients on first use; most deployments never need them
+func (p *secretsProvider) clients() error {
+	if p.secretsManager != nil && p.ssm != nil {
+		return nil
+	}
+	awsConfig := &aws.Config{Region: aws.String(os.Getenv("AWS_REGION"))}
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+		awsConfig.Endpoint = aws.String(endpoint)
+	}
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create AWS session: %w", err)
+	}
+	sess = traceSession(sess)
+	if p.secretsManager == nil {
+		p.secretsManager = secretsmanager.New(sess)
+	}
+	if p.ssm == nil {
+		p.ssm = ssm.New(sess)
+	}
+	return nil
+}
+
+// get returns the setting called name, fetching it from Secrets Manager or SSM
+// when NAME_SECRET_ARN or NAME_SSM_PARAMETER is set
+func (p *secretsProvider) get(ctx context.Context, name string) (string, error) {
+	secretID := os.Getenv(name + "_SECRET_ARN")
+	parameter := os.Getenv(name + "_SSM_PARAMETER")
+	if secretID == "" && parameter == "" {
+		return os.Getenv(name), nil
+	}
+
+	cacheKey := "sm:" + secretID
+	if secretID == "" {
+		cacheKey = "ssm:" + parameter
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if cached, ok := p.cache[cacheKey]; ok && p.now().Sub(cached.fetched) < p.ttl {
+		return cached.value, nil
+	}
+
+	if err := p.clients(); err != nil {
+		return "", fmt.Errorf("failed to load %s: %w", name, err)
+	}
+	var value string
+	var err error
+	if secretID != "" {
+		value, err = p.fetchSecret(ctx, secretID)
+	} else {
+		value, err = p.fetchParameter(ctx, parameter)
+	}
+	if err != nil {
+		// Keep serving the last good value through a Secrets Manager/SSM outage
+		if cached, ok := p.cache[cacheKey]; ok {
+			log.Printf("Warning: failed to refresh %s, using cached value: %v", name, err)
+			return cached.value, nil
+		}
+		return "", fmt.Errorf("failed to load %s: %w", name, err)
+	}
+	p.cache[cacheKey] = cachedSecret{value: value, fetched: p.now()}
+	return value, nil
+}
+
+// fetchSecret reads a Secrets Manager secret, or one key of a JSON secret ("id#key")
+func (p *secretsProvider) fetchSecret(ctx context.Context, secretID string) (string, error) {
+	id, key, hasKey := strings.Cut(secretID, "#")
+	out, err := p.secretsManager.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(id),
+	})
+	if err != nil {
+		return "", err
+	}
+	value := aws.StringValue(out.SecretString)
+	if !hasKey {
+		return value, nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("secret %s is not JSON: %w", id, err)
+	}
+	field, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no key %q", id, key)
+	}
+	return fmt.Sprint(field), nil
+}
+
+// fetchParameter reads a (SecureString) SSM parameter
+func (p *secretsProvider) fetchParameter(ctx context.Context, name string) (string, error) {
+	out, err := p.ssm.GetParameterWithContext(ctx, &ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(out.Parameter.Value), nil
+}
@@ -7,11 +7,13 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"sync"
 	"time"
 
@@ -19,7 +21,6 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 var (
@@ -29,26 +30,42 @@ var (
 
 // Podcast represents a podcast document
 type Podcast struct {
-	ID        primitive.ObjectID `bson:"_id"`
-	PodcastID string             `bson:"podcast_id,omitempty"`
-	FeedURL   string             `bson:"feed_url,omitempty"`
-	RssURL    string             `bson:"rss_url,omitempty"`
-	Title     string             `bson:"title"`
-	Active    bool               `bson:"active"`
+	ID            primitive.ObjectID `bson:"_id"`
+	PodcastID     string             `bson:"podcast_id,omitempty"`
+	FeedURL       string             `bson:"feed_url,omitempty"`
+	RssURL        string             `bson:"rss_url,omitempty"`
+	Title         string             `bson:"title"`
+	Active        bool               `bson:"active"`
+	FeedHealth    *FeedHealth        `bson:"feed_health,omitempty"`
+	Categories    []PodcastCategory  `bson:"categories,omitempty"`
+	Explicit      *bool              `bson:"explicit,omitempty"`
+	EpisodeFilter *EpisodeFilter     `bson:"episode_filter,omitempty"`
 }
 
 // Episode represents an episode document
 type Episode struct {
-	ID               string     `bson:"_id"`
-	EpisodeID        string     `bson:"episode_id"`
-	PodcastID        string     `bson:"podcast_id"`
-	Title            string     `bson:"title"`
-	Description      string     `bson:"description"`
-	AudioURL         string     `bson:"audio_url"`
-	PublishedDate    *time.Time `bson:"published_date,omitempty"`
-	TranscriptStatus string     `bson:"transcript_status"`
-	CreatedAt        time.Time  `bson:"created_at"`
-	UpdatedAt        time.Time  `bson:"updated_at"`
+	ID               string           `bson:"_id"`
+	EpisodeID        string           `bson:"episode_id"`
+	PodcastID        string           `bson:"podcast_id"`
+	Title            string           `bson:"title"`
+	Description      string           `bson:"description"`
+	DescriptionHTML  string           `bson:"description_html,omitempty"`
+	ShowNotes        []ShowNote       `bson:"show_notes,omitempty"`
+	AudioURL         string           `bson:"audio_url"`
+	Enclosures       []Enclosure      `bson:"enclosures,omitempty"`
+	PublishedDate    *time.Time       `bson:"published_date,omitempty"`
+	DurationMinutes  int              `bson:"duration_minutes,omitempty"`
+	EpisodeType      string           `bson:"episode_type,omitempty"`
+	Explicit         *bool            `bson:"explicit,omitempty"`
+	Persons          []EpisodePerson  `bson:"persons,omitempty"`
+	TranscriptStatus string           `bson:"transcript_status"`
+	SkipReason       string           `bson:"skip_reason,omitempty"`
+	TranscriptS3Key  string           `bson:"transcript_s3_key,omitempty"`
+	TranscriptSHA256 string           `bson:"transcript_sha256,omitempty"`
+	RerunOf          string           `bson:"rerun_of,omitempty"`
+	Storage          *StorageLocation `bson:"storage,omitempty"`
+	CreatedAt        time.Time        `bson:"created_at"`
+	UpdatedAt        time.Time        `bson:"updated_at"`
 }
 
 // NewEpisode represents a newly discovered episode
@@ -64,13 +81,26 @@ type PodcastResult struct {
 	PodcastID    string       `json:"podcast_id"`
 	PodcastTitle string       `json:"podcast_title"`
 	NewEpisodes  int          `json:"new_episodes"`
+	Skipped      int          `json:"skipped_episodes,omitempty"` // recorded without transcription (see episodefilter.go)
+	Reruns       int          `json:"rerun_episodes,omitempty"`   // replays linked to an earlier transcript (see rerun.go)
 	Episodes     []NewEpisode `json:"episodes,omitempty"`
-	Errors       []string     `json:"errors"`
+	Snapshots    int          `json:"snapshots_replayed,omitempty"`
+	// Classified fetch failure (see feedhealth.go) and retries made
+	ErrorType  string `json:"error_type,omitempty"`
+	Retries    int    `json:"retries,omitempty"`
+	FeedStatus string `json:"feed_status,omitempty"`
+	// Repairs a malformed feed needed to parse (see feedparse.go)
+	Sanitizations []string `json:"sanitizations,omitempty"`
+	Errors        []string `json:"errors"`
 }
 
 // Request is the request structure
 type Request struct {
 	PodcastID string `json:"podcast_id,omitempty"`
+	// Replay archived feed snapshots of PodcastID taken in this window
+	// (RFC 3339) instead of fetching the live feed
+	ReplayFrom string `json:"replay_from,omitempty"`
+	ReplayTo   string `json:"replay_to,omitempty"`
 }
 
 // Response is the response structure
@@ -84,34 +114,16 @@ type Response struct {
 	PodcastResults []PodcastResult `json:"podcast_results,omitempty"`
 }
 
-func init() {
-	if os.Getenv("SKIP_INIT") == "true" {
-		return
-	}
-	initMongoClient()
-	feedParser = gofeed.NewParser()
-}
+var clientsOnce sync.Once
 
-func initMongoClient() {
-	mongoURI := os.Getenv("MONGODB_URI")
-	if mongoURI == "" {
-		log.Fatal("MONGODB_URI environment variable not set")
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	var err error
-	mongoClient, err = mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
-	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
-	}
-
-	if err = mongoClient.Ping(ctx, nil); err != nil {
-		log.Fatalf("Failed to ping MongoDB: %v", err)
-	}
-
-	log.Println("Successfully connected to MongoDB")
+// initClients creates the clients on first use and connects to MongoDB.
+// Failing to reach MongoDB returns an error for this invocation only; the
+// next one retries instead of the execution environment being poisoned.
+func initClients(ctx context.Context) error {
+	clientsOnce.Do(func() {
+		feedParser = gofeed.NewParser()
+	})
+	return ensureMongoClient(ctx)
 }
 
 func generateEpisodeID(audioURL string) string {
@@ -120,10 +132,8 @@ func generateEpisodeID(audioURL string) string {
 }
 
 func extractAudioURL(item *gofeed.Item) string {
-	for _, enc := range item.Enclosures {
-		if enc.Type != "" && len(enc.Type) > 6 && enc.Type[:6] == "audio/" {
-			return enc.URL
-		}
+	if enc := preferredEnclosure(audioEnclosures(item), audioQuality); enc != nil {
+		return enc.URL
 	}
 	if item.Link != "" {
 		return item.Link
@@ -132,13 +142,9 @@ func extractAudioURL(item *gofeed.Item) string {
 }
 
 func processPodcast(ctx context.Context, podcast Podcast, db *mongo.Database) PodcastResult {
-	result := PodcastResult{
-		PodcastID:    podcast.PodcastID,
-		PodcastTitle: podcast.Title,
-		NewEpisodes:  0,
-		Episodes:     []NewEpisode{},
-		Errors:       []string{},
-	}
+	defer trackJob(fmt.Sprintf("poll podcast %s (%s)", podcast.PodcastID, podcast.Title))()
+
+	result := newPodcastResult(podcast)
 
 	feedURL := podcast.FeedURL
 	if feedURL == "" {
@@ -149,22 +155,50 @@ func processPodcast(ctx context.Context, podcast Podcast, db *mongo.Database) Po
 		errMsg := fmt.Sprintf("No feed URL found for podcast %s", podcast.ID.Hex())
 		log.Println(errMsg)
 		result.Errors = append(result.Errors, errMsg)
+		result.ErrorType = feedErrorNoURL
+		recordFeedHealth(ctx, db, podcast, &result, errMsg)
 		return result
 	}
 
 	log.Printf("Processing podcast: %s (%s)", podcast.Title, podcast.ID.Hex())
 
-	feed, err := feedParser.ParseURL(feedURL)
+	feed, retries, err := fetchWithRetries(ctx, feedRetries, func(ctx context.Context) (*gofeed.Feed, error) {
+		feed, sanitized, err := fetchAndParseFeed(ctx, snapshotPodcastID(podcast), feedURL)
+		result.Sanitizations = sanitized
+		return feed, err
+	})
+	result.Retries = retries
 	if err != nil {
 		errMsg := fmt.Sprintf("Failed to parse feed %s: %v", feedURL, err)
 		log.Println(errMsg)
 		result.Errors = append(result.Errors, errMsg)
+		result.ErrorType = classifyFeedError(err)
+		recordFeedHealth(ctx, db, podcast, &result, errMsg)
 		return result
 	}
+	recordFeedHealth(ctx, db, podcast, &result, "")
+	recordFeedMetadata(ctx, db, podcast, feed)
+
+	processFeed(ctx, podcast, feed, db, &result)
+	return result
+}
+
+// newPodcastResult returns empty processing stats for a podcast
+func newPodcastResult(podcast Podcast) PodcastResult {
+	return PodcastResult{
+		PodcastID:    podcast.PodcastID,
+		PodcastTitle: podcast.Title,
+		NewEpisodes:  0,
+		Episodes:     []NewEpisode{},
+		Errors:       []string{},
+	}
+}
 
+// processFeed inserts the episodes of a parsed feed that aren't known yet
+func processFeed(ctx context.Context, podcast Podcast, feed *gofeed.Feed, db *mongo.Database, result *PodcastResult) {
 	if len(feed.Items) == 0 {
 		log.Printf("No items found in feed for podcast %s", podcast.Title)
-		return result
+		return
 	}
 
 	episodesCollection := db.Collection("episodes")
@@ -177,15 +211,17 @@ func processPodcast(ctx context.Context, podcast Podcast, db *mongo.Database) Po
 			maxEpisodes, len(feed.Items), podcast.Title)
 	}
 
+	var newEpisodes []Episode
 	for _, item := range itemsToProcess {
-		audioURL := extractAudioURL(item)
+		enclosures := audioEnclosures(item)
+		audioURL := normalizeURL(extractAudioURL(item))
 		if audioURL == "" {
 			log.Printf("No audio URL found for episode: %s", item.Title)
 			continue
 		}
 
 		var existingEpisode Episode
-		err := episodesCollection.FindOne(ctx, bson.M{"audio_url": audioURL}).Decode(&existingEpisode)
+		err := episodesCollection.FindOne(ctx, bson.M{"audio_url": bson.M{"$in": enclosureURLs(enclosures, audioURL)}}).Decode(&existingEpisode)
 		if err == nil {
 			continue
 		} else if err != mongo.ErrNoDocuments {
@@ -195,6 +231,25 @@ func processPodcast(ctx context.Context, podcast Podcast, db *mongo.Database) Po
 			continue
 		}
 
+		moved, err := findMovedEpisode(ctx, episodesCollection, podcast.PodcastID, item, audioURL)
+		if err != nil {
+			log.Printf("Duplicate lookup failed for episode %s: %v", item.Title, err)
+		} else if moved != nil {
+			if err := linkMovedEpisode(ctx, episodesCollection, moved, audioURL); err != nil {
+				errMsg := fmt.Sprintf("Failed to link moved episode %s: %v", moved.EpisodeID, err)
+				log.Println(errMsg)
+				result.Errors = append(result.Errors, errMsg)
+			} else {
+				log.Printf("Linked %s to existing episode %s (audio moved from %s)", audioURL, moved.EpisodeID, moved.AudioURL)
+			}
+			continue
+		}
+
+		original, err := findRerunOriginal(ctx, episodesCollection, podcast.PodcastID, item)
+		if err != nil {
+			log.Printf("Rerun lookup failed for episode %s: %v", item.Title, err)
+		}
+
 		episodeID := generateEpisodeID(audioURL)
 
 		var publishedDate *time.Time
@@ -202,44 +257,70 @@ func processPodcast(ctx context.Context, podcast Podcast, db *mongo.Database) Po
 			publishedDate = item.PublishedParsed
 		}
 
+		description, showNotes := sanitizeDescription(item.Description)
 		episode := Episode{
 			ID:               episodeID,
 			EpisodeID:        episodeID,
 			PodcastID:        podcast.PodcastID,
 			Title:            item.Title,
-			Description:      item.Description,
+			Description:      description,
+			DescriptionHTML:  item.Description,
+			ShowNotes:        showNotes,
 			AudioURL:         audioURL,
+			Enclosures:       enclosures,
 			PublishedDate:    publishedDate,
+			DurationMinutes:  itemDurationMinutes(item),
+			EpisodeType:      itemEpisodeType(item),
+			Explicit:         itemExplicit(item),
+			Persons:          itemPersons(item),
 			TranscriptStatus: "pending",
 			CreatedAt:        time.Now().UTC(),
 			UpdatedAt:        time.Now().UTC(),
 		}
+		if reason := transcriptionSkipReason(podcast, item); reason != "" {
+			episode.TranscriptStatus = "skipped"
+			episode.SkipReason = reason
+		} else if original != nil {
+			linkRerun(&episode, original)
+		}
 
-		_, err = episodesCollection.InsertOne(ctx, episode)
-		if err != nil {
-			if mongo.IsDuplicateKeyError(err) {
-				log.Printf("Duplicate episode detected (race condition): %s", episodeID)
-				continue
-			}
-			errMsg := fmt.Sprintf("Failed to insert episode %s: %v", episodeID, err)
+		newEpisodes = append(newEpisodes, episode)
+	}
+
+	for i, inserted := range insertEpisodes(ctx, episodesCollection, newEpisodes) {
+		episode := newEpisodes[i]
+		switch inserted.Outcome {
+		case insertExisting:
+			log.Printf("Duplicate episode detected (race condition): %s", episode.EpisodeID)
+			continue
+		case insertFailed:
+			errMsg := fmt.Sprintf("Failed to insert episode %s: %v", episode.EpisodeID, inserted.Err)
 			log.Println(errMsg)
 			result.Errors = append(result.Errors, errMsg)
 			continue
 		}
 
-		log.Printf("Inserted new episode: %s (%s)", item.Title, episodeID)
+		log.Printf("Inserted new episode: %s (%s)", episode.Title, episode.EpisodeID)
 		result.NewEpisodes++
+		if episode.SkipReason != "" {
+			log.Printf("Not transcribing episode %s: %s", episode.EpisodeID, episode.SkipReason)
+			result.Skipped++
+			continue
+		}
+		if episode.RerunOf != "" {
+			log.Printf("Episode %s is a rerun of %s, reusing its transcript", episode.EpisodeID, episode.RerunOf)
+			result.Reruns++
+			continue
+		}
 		result.Episodes = append(result.Episodes, NewEpisode{
-			EpisodeID: episodeID,
-			Title:     item.Title,
-			AudioURL:  audioURL,
+			EpisodeID: episode.EpisodeID,
+			Title:     episode.Title,
+			AudioURL:  episode.AudioURL,
 			PodcastID: podcast.PodcastID,
 		})
 		// NOTE: In HTTP mode, we don't trigger Step Functions
 		// The backend orchestration handles transcription workflow
 	}
-
-	return result
 }
 
 func handleRequest(ctx context.Context, event json.RawMessage) (Response, error) {
@@ -263,6 +344,22 @@ func handleRequest(ctx context.Context, event json.RawMessage) (Response, error)
 		PodcastResults: []PodcastResult{},
 	}
 
+	replay, err := parseReplayWindow(request, time.Now())
+	if err != nil {
+		response.StatusCode = 400
+		response.Message = err.Error()
+		response.Errors = append(response.Errors, err.Error())
+		return response, err
+	}
+
+	if err := initClients(ctx); err != nil {
+		log.Printf("Client initialization failed: %v", err)
+		response.StatusCode = 503
+		response.Message = "Database unavailable"
+		response.Errors = append(response.Errors, err.Error())
+		return response, err
+	}
+
 	db := mongoClient.Database("podcast_db")
 	podcastsCollection := db.Collection("podcasts")
 
@@ -319,7 +416,12 @@ func handleRequest(ctx context.Context, event json.RawMessage) (Response, error)
 			defer wg.Done()
 			defer func() { <-semaphore }()
 
-			result := processPodcast(ctx, p, db)
+			var result PodcastResult
+			if replay != nil {
+				result = replayPodcast(ctx, p, *replay, db)
+			} else {
+				result = processPodcast(ctx, p, db)
+			}
 
 			mu.Lock()
 			results = append(results, result)
@@ -335,7 +437,10 @@ func handleRequest(ctx context.Context, event json.RawMessage) (Response, error)
 	wg.Wait()
 	response.PodcastResults = results
 
-	if request.PodcastID != "" {
+	if replay != nil {
+		response.Message = fmt.Sprintf("Replay completed for podcast %s", request.PodcastID)
+		log.Printf("Snapshot replay complete for podcast %s. Found %d new episodes", request.PodcastID, response.TotalEpisodes)
+	} else if request.PodcastID != "" {
 		response.Message = fmt.Sprintf("Polling completed for podcast %s", request.PodcastID)
 		log.Printf("RSS polling complete for podcast %s. Found %d new episodes", request.PodcastID, response.TotalEpisodes)
 	} else {
@@ -346,6 +451,37 @@ func handleRequest(ctx context.Context, event json.RawMessage) (Response, error)
 	return response, nil
 }
 
+// defaultMaxRequestBodyBytes caps /invoke bodies unless MAX_REQUEST_BODY_BYTES is set (0 = no limit)
+const defaultMaxRequestBodyBytes = 32 << 20
+
+// readRequestBody reads the body up to the configured limit; ok is false once an error response is sent
+func readRequestBody(w http.ResponseWriter, r *http.Request) (body []byte, ok bool) {
+	limit := int64(defaultMaxRequestBodyBytes)
+	if raw := os.Getenv("MAX_REQUEST_BODY_BYTES"); raw != "" {
+		if parsed, err := strconv.ParseInt(raw, 10, 64); err == nil && parsed >= 0 {
+			limit = parsed
+		} else {
+			log.Printf("Warning: invalid MAX_REQUEST_BODY_BYTES %q, using %d", raw, limit)
+		}
+	}
+	if limit > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+	}
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			sendError(w, fmt.Sprintf("Request body exceeds %d bytes", tooLarge.Limit), http.StatusRequestEntityTooLarge)
+			return nil, false
+		}
+		sendError(w, "Failed to read request body", http.StatusBadRequest)
+		return nil, false
+	}
+	return body, true
+}
+
 func healthHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
@@ -360,12 +496,10 @@ func invokeHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		sendError(w, "Failed to read request body", http.StatusBadRequest)
+	body, ok := readRequestBody(w, r)
+	if !ok {
 		return
 	}
-	defer r.Body.Close()
 
 	log.Printf("Received invoke request: %s", string(body))
 
@@ -400,11 +534,14 @@ func main() {
 		port = "8001"
 	}
 
-	http.HandleFunc("/health", healthHandler)
-	http.HandleFunc("/invoke", invokeHandler)
+	// Own mux so pprof/expvar (registered on the default mux) stay off this port
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", healthHandler)
+	mux.HandleFunc("/invoke", invokeHandler)
+	startDebugServer()
 
 	log.Printf("Starting poll-lambda HTTP server on port %s", port)
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
+	if err := http.ListenAndServe(":"+port, mux); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Episode descriptions are usually HTML, full of tracking links and the odd
+// script or pixel. The original is kept as description_html, and
+// description holds a Markdown rendering without scripts, styles, embeds or
+// images. Every link is also collected into show_notes ({url, text}), with
+// bare URLs in the text included. Link URLs go through normalizeURL, which
+// unwraps analytics redirects and drops campaign parameters.
+
+// ShowNote is a link found in an episode description
+type ShowNote struct {
+	URL  string `bson:"url"`
+	Text string `bson:"text,omitempty"`
+}
+
+// droppedElements never contribute text
+var droppedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true,
+	atom.Object: true, atom.Embed: true, atom.Img: true, atom.Form: true,
+	atom.Head: true, atom.Template: true, atom.Svg: true,
+}
+
+// blockElements start on a line of their own
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Ul: true, atom.Ol: true, atom.Li: true,
+	atom.Blockquote: true, atom.Pre: true, atom.Table: true, atom.Tr: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+}
+
+var (
+	bareURL    = regexp.MustCompile(`https?://[^\s<>"')\]]+`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+	spaceRun   = regexp.MustCompile(`[ \t\r\n]+`)
+)
+
+// descriptionRenderer turns a parsed description into Markdown
+type descriptionRenderer struct {
+	out   strings.Builder
+	notes []ShowNote
+	seen  map[string]bool
+}
+
+// sanitizeDescription renders an HTML description as Markdown and returns
+// the links it contains
+func sanitizeDescription(raw string) (string, []ShowNote) {
+	if strings.TrimSpace(raw) == "" {
+		return "", nil
+	}
+	nodes, err := html.ParseFragment(strings.NewReader(raw), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return strings.TrimSpace(raw), nil
+	}
+
+	r := &descriptionRenderer{seen: map[string]bool{}}
+	for _, n := range nodes {
+		r.render(n)
+	}
+	text := blankLines.ReplaceAllString(r.out.String(), "\n\n")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), r.notes
+}
+
+func (r *descriptionRenderer) render(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		text := bareURL.ReplaceAllStringFunc(spaceRun.ReplaceAllString(n.Data, " "), func(u string) string {
+			trimmed := strings.TrimRight(u, ".,;:!?")
+			clean := normalizeURL(trimmed)
+			r.addNote(clean, "")
+			return clean + u[len(trimmed):]
+		})
+		r.out.WriteString(text)
+		return
+	case html.ElementNode:
+	default:
+		r.renderChildren(n)
+		return
+	}
+
+	if droppedElements[n.DataAtom] {
+		return
+	}
+	switch n.DataAtom {
+	case atom.Br:
+		r.out.WriteString("\n")
+	case atom.Hr:
+		r.out.WriteString("\n\n---\n\n")
+	case atom.A:
+		r.renderLink(n)
+	case atom.B, atom.Strong:
+		r.wrap(n, "**")
+	case atom.I, atom.Em:
+		r.wrap(n, "_")
+	case atom.Li:
+		r.out.WriteString("\n- ")
+		r.renderChildren(n)
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		r.out.WriteString("\n\n" + strings.Repeat("#", int(n.Data[1]-'0')) + " ")
+		r.renderChildren(n)
+		r.out.WriteString("\n\n")
+	default:
+		if blockElements[n.DataAtom] {
+			r.out.WriteString("\n\n")
+			r.renderChildren(n)
+			r.out.WriteString("\n\n")
+			return
+		}
+		r.renderChildren(n)
+	}
+}
+
+func (r *descriptionRenderer) renderChildren(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.render(c)
+	}
+}
+
+// wrap renders n's children between marker, unless they are blank
+func (r *descriptionRenderer) wrap(n *html.Node, marker string) {
+	inner := r.renderToString(n)
+	if strings.TrimSpace(inner) == "" {
+		r.out.WriteString(inner)
+		return
+	}
+	r.out.WriteString(marker + strings.TrimSpace(inner) + marker)
+}
+
+func (r *descriptionRenderer) renderLink(n *html.Node) {
+	text := strings.TrimSpace(r.renderToString(n))
+	href := ""
+	for _, attr := range n.Attr {
+		if attr.Key == "href" {
+			href = strings.TrimSpace(attr.Val)
+		}
+	}
+
+	lower := strings.ToLower(href)
+	switch {
+	case strings.HasPrefix(lower, "http://"), strings.HasPrefix(lower, "https://"):
+		href = normalizeURL(href)
+	case strings.HasPrefix(lower, "mailto:"):
+	default:
+		// Relative, javascript: and empty links keep only their text
+		r.out.WriteString(text)
+		return
+	}
+
+	if text == "" || text == href {
+		r.addNote(href, "")
+		r.out.WriteString("<" + href + ">")
+		return
+	}
+	r.addNote(href, text)
+	r.out.WriteString("[" + text + "](" + href + ")")
+}
+
+// renderToString renders n's children on their own, keeping the links found
+func (r *descriptionRenderer) renderToString(n *html.Node) string {
+	sub := &descriptionRenderer{notes: r.notes, seen: r.seen}
+	sub.renderChildren(n)
+	r.notes = sub.notes
+	return sub.out.String()
+}
+
+// addNote records a link once, keeping the first text given for it
+func (r *descriptionRenderer) addNote(u, text string) {
+	if u == "" {
+		return
+	}
+	if r.seen[u] {
+		for i := range r.notes {
+			if r.notes[i].URL == u && r.notes[i].Text == "" {
+				r.notes[i].Text = text
+			}
+		}
+		return
+	}
+	r.seen[u] = true
+	r.notes = append(r.notes, ShowNote{URL: u, Text: text})
+}
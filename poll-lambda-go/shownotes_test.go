@@ -0,0 +1,76 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSanitizeDescription(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       string
+		want      string
+		wantNotes []ShowNote
+	}{
+		{
+			name: "empty",
+			raw:  "  ",
+			want: "",
+		},
+		{
+			name: "plain text",
+			raw:  "Just   a\nplain description.",
+			want: "Just a plain description.",
+		},
+		{
+			name: "paragraphs and formatting",
+			raw:  "<p>This week: <b>tariffs</b> and <em>trade</em>.</p><p>Second&nbsp;paragraph<br/>next line</p>",
+			want: "This week: **tariffs** and _trade_.\n\nSecond paragraph\nnext line",
+		},
+		{
+			name: "scripts, styles, images and embeds are dropped",
+			raw:  `<p>Hello</p><script>track()</script><style>p{}</style><img src="https://pixel.example.com/1x1.gif" width="1" height="1"><iframe src="https://ads.example.com"></iframe>`,
+			want: "Hello",
+		},
+		{
+			name: "links become Markdown and show notes",
+			raw:  `<p>Sponsor: <a href="https://sponsor.example.com/offer?utm_source=podcast&code=SHOW">Sponsor Inc</a></p><ul><li><a href="https://dts.podtrac.com/redirect.mp3/example.com/book">The book</a></li><li><a href="mailto:show@example.com">Email us</a></li></ul>`,
+			want: "Sponsor: [Sponsor Inc](https://sponsor.example.com/offer?code=SHOW)\n\n- [The book](https://example.com/book)\n- [Email us](mailto:show@example.com)",
+			wantNotes: []ShowNote{
+				{URL: "https://sponsor.example.com/offer?code=SHOW", Text: "Sponsor Inc"},
+				{URL: "https://example.com/book", Text: "The book"},
+				{URL: "mailto:show@example.com", Text: "Email us"},
+			},
+		},
+		{
+			name: "bare URLs are cleaned and collected",
+			raw:  "Notes at https://example.com/notes?fbclid=abc. Same link: <a href=\"https://example.com/notes\">https://example.com/notes</a>",
+			want: "Notes at https://example.com/notes. Same link: <https://example.com/notes>",
+			wantNotes: []ShowNote{
+				{URL: "https://example.com/notes"},
+			},
+		},
+		{
+			name: "unsafe and relative links keep their text",
+			raw:  `<a href="javascript:alert(1)">click</a> <a href="/about">about</a>`,
+			want: "click about",
+		},
+		{
+			name: "headings",
+			raw:  "<h2>Chapters</h2>00:00 Intro<br>05:30 Interview",
+			want: "## Chapters\n\n00:00 Intro\n05:30 Interview",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, notes := sanitizeDescription(tt.raw)
+			if got != tt.want {
+				t.Errorf("sanitizeDescription() text =\n%q\nwant\n%q", got, tt.want)
+			}
+			if !reflect.DeepEqual(notes, tt.wantNotes) {
+				t.Errorf("sanitizeDescription() notes = %+v, want %+v", notes, tt.wantNotes)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/mmcdole/gofeed"
+)
+
+// Feeds are fetched here rather than by gofeed so the raw XML can be kept.
+// With FEED_SNAPSHOTS_ENABLED=true every fetched feed is stored in S3 at
+//
+//	<FEED_SNAPSHOT_PREFIX><podcast_id>/<UTC timestamp>.xml
+//
+// (bucket FEED_SNAPSHOT_BUCKET, default S3_BUCKET; prefix default
+// "feed-snapshots/"), and only the newest FEED_SNAPSHOT_VERSIONS (default
+// 10) are kept per podcast. Feeds that fail to parse are stored too. This
+// shows what a feed looked like at poll time, after the publisher has
+// changed it. Snapshot failures are logged and never fail a poll.
+
+const (
+	defaultSnapshotPrefix   = "feed-snapshots/"
+	defaultSnapshotVersions = 10
+	defaultSnapshotBucket   = "podcast-audio-bucket"
+
+	feedFetchTimeout = 30 * time.Second
+	// Larger responses aren't feeds we can process anyway
+	maxFeedBytes  = 32 << 20
+	feedUserAgent = "Gofeed/1.0"
+
+	// Fixed width, so keys sort by time
+	snapshotTimeLayout = "20060102T150405.000Z"
+)
+
+// snapshotConfig holds the FEED_SNAPSHOT_* settings
+type snapshotConfig struct {
+	enabled  bool
+	bucket   string
+	prefix   string
+	versions int
+}
+
+func loadSnapshotConfig() snapshotConfig {
+	cfg := snapshotConfig{
+		bucket:   os.Getenv("FEED_SNAPSHOT_BUCKET"),
+		prefix:   defaultSnapshotPrefix,
+		versions: defaultSnapshotVersions,
+	}
+	cfg.enabled, _ = strconv.ParseBool(os.Getenv("FEED_SNAPSHOTS_ENABLED"))
+	if cfg.bucket == "" {
+		cfg.bucket = os.Getenv("S3_BUCKET")
+	}
+	if cfg.bucket == "" {
+		cfg.bucket = defaultSnapshotBucket
+	}
+	if raw := os.Getenv("FEED_SNAPSHOT_PREFIX"); raw != "" {
+		cfg.prefix = strings.TrimSuffix(raw, "/") + "/"
+	}
+	if raw := os.Getenv("FEED_SNAPSHOT_VERSIONS"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			cfg.versions = n
+		} else {
+			log.Printf("Warning: invalid FEED_SNAPSHOT_VERSIONS %q, keeping %d", raw, cfg.versions)
+		}
+	}
+	return cfg
+}
+
+// feedSnapshots stores raw feeds in S3
+type feedSnapshots struct {
+	cfg  snapshotConfig
+	once sync.Once
+	s3   s3iface.S3API
+	now  func() time.Time
+}
+
+var snapshots = &feedSnapshots{cfg: loadSnapshotConfig(), now: time.Now}
+
+// client creates the S3 client on first use; polls without snapshots never need it
+func (f *feedSnapshots) client() s3iface.S3API {
+	f.once.Do(func() {
+		if f.s3 != nil {
+			return
+		}
+		awsConfig := &aws.Config{Region: aws.String(os.Getenv("AWS_REGION"))}
+		if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+			awsConfig.Endpoint = aws.String(endpoint)
+			awsConfig.S3ForcePathStyle = aws.Bool(true)
+		}
+		f.s3 = s3.New(traceSession(session.Must(session.NewSession(awsConfig))))
+	})
+	return f.s3
+}
+
+func (f *feedSnapshots) podcastPrefix(podcastID string) string {
+	return f.cfg.prefix + podcastID + "/"
+}
+
+// save stores one fetched feed and prunes the podcast's old snapshots
+func (f *feedSnapshots) save(ctx context.Context, podcastID string, raw []byte) {
+	if !f.cfg.enabled {
+		return
+	}
+	key := f.podcastPrefix(podcastID) + f.now().UTC().Format(snapshotTimeLayout) + ".xml"
+	_, err := f.client().PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(f.cfg.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(raw),
+		ContentType: aws.String("application/xml"),
+	})
+	if err != nil {
+		log.Printf("Warning: failed to store feed snapshot %s: %v", key, err)
+		return
+	}
+	if err := f.prune(ctx, podcastID); err != nil {
+		log.Printf("Warning: failed to prune feed snapshots of %s: %v", podcastID, err)
+	}
+}
+
+// prune deletes all but the newest cfg.versions snapshots of a podcast
+func (f *feedSnapshots) prune(ctx context.Context, podcastID string) error {
+	var keys []string
+	err := f.client().ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(f.cfg.bucket),
+		Prefix: aws.String(f.podcastPrefix(podcastID)),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, obj := range page.Contents {
+			keys = append(keys, aws.StringValue(obj.Key))
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if len(keys) <= f.cfg.versions {
+		return nil
+	}
+
+	sort.Strings(keys)
+	stale := keys[:len(keys)-f.cfg.versions]
+	// DeleteObjects takes at most 1000 keys per call
+	for start := 0; start < len(stale); start += 1000 {
+		end := start + 1000
+		if end > len(stale) {
+			end = len(stale)
+		}
+		objects := make([]*s3.ObjectIdentifier, 0, end-start)
+		for _, key := range stale[start:end] {
+			objects = append(objects, &s3.ObjectIdentifier{Key: aws.String(key)})
+		}
+		_, err := f.client().DeleteObjectsWithContext(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(f.cfg.bucket),
+			Delete: &s3.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// list returns the keys of a podcast's snapshots taken in [from, to), oldest first
+func (f *feedSnapshots) list(ctx context.Context, podcastID string, from, to time.Time) ([]string, error) {
+	var keys []string
+	err := f.client().ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(f.cfg.bucket),
+		Prefix: aws.String(f.podcastPrefix(podcastID)),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, obj := range page.Contents {
+			key := aws.StringValue(obj.Key)
+			taken, err := time.Parse(snapshotTimeLayout, strings.TrimSuffix(path.Base(key), ".xml"))
+			if err != nil {
+				continue
+			}
+			if !taken.Before(from) && taken.Before(to) {
+				keys = append(keys, key)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// load returns the raw feed stored in a snapshot
+func (f *feedSnapshots) load(ctx context.Context, key string) ([]byte, error) {
+	out, err := f.client().GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(f.cfg.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+	return io.ReadAll(out.Body)
+}
+
+var feedHTTPClient = traceHTTPClient(&http.Client{Timeout: feedFetchTimeout})
+
+// snapshotPodcastID names a podcast's snapshot folder; podcasts created
+// before podcast_id existed fall back to their document ID
+func snapshotPodcastID(p Podcast) string {
+	if p.PodcastID != "" {
+		return p.PodcastID
+	}
+	return p.ID.Hex()
+}
+
+// fetchFeed downloads a feed's raw XML
+func fetchFeed(ctx context.Context, feedURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", feedUserAgent)
+
+	resp, err := feedHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &feedHTTPError{StatusCode: resp.StatusCode}
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxFeedBytes))
+}
+
+// fetchAndParseFeed downloads a podcast's feed, snapshots it and parses it,
+// returning the sanitizations a malformed feed needed
+func fetchAndParseFeed(ctx context.Context, podcastID, feedURL string) (*gofeed.Feed, []string, error) {
+	var feed *gofeed.Feed
+	var sanitized []string
+	err := traced(ctx, "fetch-feed", map[string]string{"podcast_id": podcastID}, func(ctx context.Context) error {
+		raw, err := fetchFeed(ctx, feedURL)
+		if err != nil {
+			return err
+		}
+		snapshots.save(ctx, podcastID, raw)
+		feed, sanitized, err = parseFeed(raw)
+		if err != nil {
+			return &feedParseError{err: err}
+		}
+		return nil
+	})
+	return feed, sanitized, err
+}
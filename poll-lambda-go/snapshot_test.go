@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// fakeS3 keeps objects in memory; only the calls snapshots make are implemented
+type fakeS3 struct {
+	s3iface.S3API
+	objects map[string][]byte
+}
+
+func (f *fakeS3) PutObjectWithContext(_ aws.Context, in *s3.PutObjectInput, _ ...request.Option) (*s3.PutObjectOutput, error) {
+	body, _ := io.ReadAll(in.Body)
+	f.objects[aws.StringValue(in.Key)] = body
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3) ListObjectsV2PagesWithContext(_ aws.Context, in *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool, _ ...request.Option) error {
+	page := &s3.ListObjectsV2Output{}
+	for key := range f.objects {
+		if strings.HasPrefix(key, aws.StringValue(in.Prefix)) {
+			page.Contents = append(page.Contents, &s3.Object{Key: aws.String(key)})
+		}
+	}
+	fn(page, true)
+	return nil
+}
+
+func (f *fakeS3) DeleteObjectsWithContext(_ aws.Context, in *s3.DeleteObjectsInput, _ ...request.Option) (*s3.DeleteObjectsOutput, error) {
+	for _, obj := range in.Delete.Objects {
+		delete(f.objects, aws.StringValue(obj.Key))
+	}
+	return &s3.DeleteObjectsOutput{}, nil
+}
+
+func (f *fakeS3) keys() []string {
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestLoadSnapshotConfig(t *testing.T) {
+	t.Setenv("FEED_SNAPSHOTS_ENABLED", "true")
+	t.Setenv("S3_BUCKET", "audio")
+	t.Setenv("FEED_SNAPSHOT_BUCKET", "")
+	t.Setenv("FEED_SNAPSHOT_PREFIX", "feeds")
+	t.Setenv("FEED_SNAPSHOT_VERSIONS", "3")
+
+	cfg := loadSnapshotConfig()
+	want := snapshotConfig{enabled: true, bucket: "audio", prefix: "feeds/", versions: 3}
+	if cfg != want {
+		t.Errorf("loadSnapshotConfig() = %+v, want %+v", cfg, want)
+	}
+
+	t.Setenv("FEED_SNAPSHOTS_ENABLED", "")
+	t.Setenv("FEED_SNAPSHOT_VERSIONS", "0")
+	cfg = loadSnapshotConfig()
+	if cfg.enabled || cfg.versions != defaultSnapshotVersions {
+		t.Errorf("got enabled=%v versions=%d, want disabled with the default versions", cfg.enabled, cfg.versions)
+	}
+}
+
+func TestFeedSnapshotsKeepNewestVersions(t *testing.T) {
+	store := &fakeS3{objects: map[string][]byte{
+		"feed-snapshots/pod_2/20240101T000000.000Z.xml": []byte("other podcast"),
+	}}
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	f := &feedSnapshots{
+		cfg: snapshotConfig{enabled: true, bucket: "b", prefix: "feed-snapshots/", versions: 2},
+		s3:  store,
+		now: func() time.Time { return now },
+	}
+
+	for i := 0; i < 3; i++ {
+		f.save(context.Background(), "pod_1", []byte{byte('a' + i)})
+		now = now.Add(30 * time.Minute)
+	}
+
+	want := []string{
+		"feed-snapshots/pod_1/20240501T083000.000Z.xml",
+		"feed-snapshots/pod_1/20240501T090000.000Z.xml",
+		"feed-snapshots/pod_2/20240101T000000.000Z.xml",
+	}
+	got := store.keys()
+	if len(got) != len(want) {
+		t.Fatalf("keys = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("keys[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+	if body := string(store.objects[want[1]]); body != "c" {
+		t.Errorf("newest snapshot = %q, want the last feed fetched", body)
+	}
+}
+
+func TestFeedSnapshotsDisabled(t *testing.T) {
+	store := &fakeS3{objects: map[string][]byte{}}
+	f := &feedSnapshots{cfg: snapshotConfig{versions: 2}, s3: store, now: time.Now}
+	f.save(context.Background(), "pod_1", []byte("<rss/>"))
+	if len(store.objects) != 0 {
+		t.Errorf("stored %v with snapshots disabled", store.keys())
+	}
+}
+
+func TestFetchFeed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		if ua := r.Header.Get("User-Agent"); ua != feedUserAgent {
+			t.Errorf("User-Agent = %q", ua)
+		}
+		io.WriteString(w, "<rss><channel><title>Show</title></channel></rss>")
+	}))
+	defer srv.Close()
+
+	raw, err := fetchFeed(context.Background(), srv.URL+"/feed.xml")
+	if err != nil {
+		t.Fatalf("fetchFeed: %v", err)
+	}
+	if string(raw) != "<rss><channel><title>Show</title></channel></rss>" {
+		t.Errorf("fetchFeed = %q", raw)
+	}
+
+	if _, err := fetchFeed(context.Background(), srv.URL+"/missing"); err == nil {
+		t.Error("expected an error for a 404")
+	}
+}
+
+func TestFeedSnapshotsList(t *testing.T) {
+	store := &fakeS3{objects: map[string][]byte{
+		"feed-snapshots/pod_1/20240501T080000.000Z.xml": nil,
+		"feed-snapshots/pod_1/20240501T083000.000Z.xml": nil,
+		"feed-snapshots/pod_1/20240501T090000.000Z.xml": nil,
+		"feed-snapshots/pod_1/notes.txt":                nil,
+		"feed-snapshots/pod_2/20240501T083000.000Z.xml": nil,
+	}}
+	f := &feedSnapshots{cfg: snapshotConfig{bucket: "b", prefix: "feed-snapshots/", versions: 10}, s3: store}
+
+	from := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	keys, err := f.list(context.Background(), "pod_1", from, from.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	want := []string{
+		"feed-snapshots/pod_1/20240501T080000.000Z.xml",
+		"feed-snapshots/pod_1/20240501T083000.000Z.xml",
+	}
+	if strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Errorf("list = %v, want %v", keys, want)
+	}
+}
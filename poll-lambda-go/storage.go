@@ -0,0 +1,18 @@
+package main
+
+// A podcast or its workspace can be pinned to a storage location (an EU
+// bucket, say) through the API, which copies the location onto the
+// document's "storage" field (see the API's storage_locations.py). The
+// podcast's own location wins over its workspace's. Executions for its
+// episodes get the location's audio bucket and region as s3_bucket and
+// s3_region, and the location itself as storage, which the merge Lambda
+// records on the episode; unpinned podcasts get S3_BUCKET and AWS_REGION
+// with a null storage.
+
+// StorageLocation is a named set of buckets in one region
+type StorageLocation struct {
+	Location    string `bson:"location,omitempty" json:"location,omitempty"`
+	Region      string `bson:"region" json:"region"`
+	Bucket      string `bson:"bucket" json:"bucket"`
+	AudioBucket string `bson:"audio_bucket,omitempty" json:"audio_bucket,omitempty"`
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-xray-sdk-go/strategy/ctxmissing"
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// With XRAY_TRACING_ENABLED=true (and active tracing on the function),
+// AWS SDK calls, MongoDB commands and feed fetches are recorded as X-Ray
+// subsegments of the invocation, so slow feeds, S3 operations and queries
+// show up in the service map. Outside Lambda there is no segment to attach
+// them to and nothing is recorded.
+
+var tracingEnabled = initTracing()
+
+func initTracing() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("XRAY_TRACING_ENABLED"))
+	if enabled {
+		xray.Configure(xray.Config{ContextMissingStrategy: ctxmissing.NewDefaultIgnoreErrorStrategy()})
+	}
+	return enabled
+}
+
+// traceSession instruments every client created from sess
+func traceSession(sess *session.Session) *session.Session {
+	if !tracingEnabled {
+		return sess
+	}
+	return xray.AWSSession(sess)
+}
+
+// traceHTTPClient records each request of c as a subsegment named after its host
+func traceHTTPClient(c *http.Client) *http.Client {
+	if !tracingEnabled {
+		return c
+	}
+	return xray.Client(c)
+}
+
+// traced runs fn in a subsegment called name carrying the given annotations
+func traced(ctx context.Context, name string, annotations map[string]string, fn func(context.Context) error) error {
+	if !tracingEnabled {
+		return fn(ctx)
+	}
+	return xray.Capture(ctx, name, func(ctx context.Context) error {
+		if seg := xray.GetSegment(ctx); seg != nil {
+			for key, value := range annotations {
+				seg.AddAnnotation(key, value)
+			}
+		}
+		return fn(ctx)
+	})
+}
+
+// mongoTraceMonitor records each MongoDB command as a remote subsegment
+func mongoTraceMonitor() *event.CommandMonitor {
+	var mu sync.Mutex
+	open := map[int64]*xray.Segment{}
+
+	finished := func(e event.CommandFinishedEvent, err error) {
+		mu.Lock()
+		seg := open[e.RequestID]
+		delete(open, e.RequestID)
+		mu.Unlock()
+		if seg != nil {
+			seg.Close(err)
+		}
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			_, seg := xray.BeginSubsegment(ctx, "MongoDB")
+			if seg == nil {
+				return
+			}
+			seg.Namespace = "remote"
+			seg.AddAnnotation("command", e.CommandName)
+			seg.AddMetadata("target", commandTarget(e.CommandName, e.Command))
+			mu.Lock()
+			open[e.RequestID] = seg
+			mu.Unlock()
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			finished(e.CommandFinishedEvent, nil)
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			finished(e.CommandFinishedEvent, errors.New(e.Failure))
+		},
+	}
+}
+
+// chainMonitors calls each non-nil monitor in turn
+func chainMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	var active []*event.CommandMonitor
+	for _, m := range monitors {
+		if m != nil {
+			active = append(active, m)
+		}
+	}
+	if len(active) == 1 {
+		return active[0]
+	}
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			for _, m := range active {
+				if m.Started != nil {
+					m.Started(ctx, e)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			for _, m := range active {
+				if m.Succeeded != nil {
+					m.Succeeded(ctx, e)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			for _, m := range active {
+				if m.Failed != nil {
+					m.Failed(ctx, e)
+				}
+			}
+		},
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+// This file is kept identical in poll-lambda-go and merge-transcript-lambda-go.
+// Each Lambda is its own module and Docker build context, so the code is
+// copied rather than shared; `make check-lambda-copies` fails if the copies
+// differ.
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func TestChainMonitors(t *testing.T) {
+	var calls []string
+	record := func(name string) *event.CommandMonitor {
+		return &event.CommandMonitor{
+			Started: func(context.Context, *event.CommandStartedEvent) { calls = append(calls, name+" started") },
+			Failed:  func(context.Context, *event.CommandFailedEvent) { calls = append(calls, name+" failed") },
+		}
+	}
+
+	first := record("first")
+	if got := chainMonitors(nil, first); got != first {
+		t.Error("a single monitor should be used as is")
+	}
+
+	m := chainMonitors(first, nil, record("second"))
+	m.Started(context.Background(), &event.CommandStartedEvent{})
+	m.Succeeded(context.Background(), &event.CommandSucceededEvent{})
+	m.Failed(context.Background(), &event.CommandFailedEvent{})
+
+	want := []string{"first started", "second started", "first failed", "second failed"}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("calls[%d] = %q, want %q", i, calls[i], want[i])
+		}
+	}
+}
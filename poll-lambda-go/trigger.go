@@ -0,0 +1,309 @@
+//go:build !http
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// A newly subscribed feed can bring dozens of new episodes at once, so
+// StartExecution calls are paced to STEP_FUNCTION_START_RATE per second
+// (default 5, 0 for no limit) across all podcasts of an invocation.
+//
+// With STEP_FUNCTION_TRIGGER_MODE=batch, a podcast's new episodes start a
+// single execution of STEP_FUNCTION_BATCH_ARN instead, with the episodes
+// as an array for a Map state:
+//
+//	{"podcast_id": "...", "state_machine_arn": "<episode workflow>", "episodes": [{"episode_id": ...}, ...]}
+//
+// state_machine_arn is the workflow each episode would have started on its
+// own. Every episode of the batch records the batch execution's ARN.
+
+const (
+	triggerModeEpisode = "episode"
+	triggerModeBatch   = "batch"
+
+	defaultStartRate   = 5.0
+	defaultAudioBucket = "podcast-audio-bucket"
+)
+
+// triggerConfig holds the STEP_FUNCTION_TRIGGER_MODE settings
+type triggerConfig struct {
+	mode      string
+	batchARN  string
+	startRate float64
+}
+
+func loadTriggerConfig() triggerConfig {
+	cfg := triggerConfig{
+		mode:      triggerModeEpisode,
+		batchARN:  os.Getenv("STEP_FUNCTION_BATCH_ARN"),
+		startRate: defaultStartRate,
+	}
+
+	switch mode := strings.ToLower(os.Getenv("STEP_FUNCTION_TRIGGER_MODE")); mode {
+	case "", triggerModeEpisode:
+	case triggerModeBatch:
+		if cfg.batchARN == "" {
+			log.Println("Warning: STEP_FUNCTION_TRIGGER_MODE=batch needs STEP_FUNCTION_BATCH_ARN, starting one execution per episode")
+		} else {
+			cfg.mode = triggerModeBatch
+		}
+	default:
+		log.Printf("Warning: invalid STEP_FUNCTION_TRIGGER_MODE %q, starting one execution per episode", mode)
+	}
+
+	if raw := os.Getenv("STEP_FUNCTION_START_RATE"); raw != "" {
+		if rate, err := strconv.ParseFloat(raw, 64); err == nil && rate >= 0 {
+			cfg.startRate = rate
+		} else {
+			log.Printf("Warning: invalid STEP_FUNCTION_START_RATE %q, keeping %g", raw, cfg.startRate)
+		}
+	}
+	return cfg
+}
+
+var (
+	triggers     = loadTriggerConfig()
+	startLimiter = newRateLimiter(triggers.startRate)
+)
+
+// rateLimiter spaces calls evenly; a nil limiter never waits
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(perSecond float64) *rateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// wait blocks until the caller's turn or until ctx is done
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	turn := l.next
+	if turn.Before(now) {
+		turn = now
+	}
+	l.next = turn.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(turn)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// pendingEpisode is an inserted episode waiting for its execution
+type pendingEpisode struct {
+	EpisodeID string
+	AudioURL  string
+}
+
+// BatchStepFunctionInput is the input of a podcast's batch execution
+type BatchStepFunctionInput struct {
+	PodcastID       string              `json:"podcast_id"`
+	StateMachineARN string              `json:"state_machine_arn"`
+	Episodes        []StepFunctionInput `json:"episodes"`
+}
+
+func audioBucket() string {
+	if bucket := os.Getenv("S3_BUCKET"); bucket != "" {
+		return bucket
+	}
+	return defaultAudioBucket
+}
+
+// pipelineStorage returns the bucket and region an execution keeps the
+// episode's chunks and transcripts in
+func pipelineStorage(storage *StorageLocation) (string, string) {
+	if storage == nil || storage.Region == "" || storage.Bucket == "" {
+		return audioBucket(), os.Getenv("AWS_REGION")
+	}
+	if storage.AudioBucket != "" {
+		return storage.AudioBucket, storage.Region
+	}
+	return storage.Bucket, storage.Region
+}
+
+// TranscriptEncryption is the workspace KMS key a private podcast's
+// transcripts are encrypted with; the merge Lambda stores them under the
+// workspace's private prefix with SSE-KMS (see its contentstore.go)
+type TranscriptEncryption struct {
+	WorkspaceID string `json:"workspace_id"`
+	KMSKeyID    string `json:"kms_key_id"`
+}
+
+// defaultWorkspaceID owns podcasts without a workspace_id, as in the API
+const defaultWorkspaceID = "default"
+
+// resolveWorkspace gives a podcast without a storage location its
+// workspace's, if any, and a private podcast its workspace's KMS key.
+// Failing to look them up is an error rather than a fallback to the default
+// buckets, which may be in the wrong region, or to plaintext transcripts.
+func resolveWorkspace(ctx context.Context, workspaces *mongo.Collection, podcast *Podcast) error {
+	workspaceID := podcast.WorkspaceID
+	if workspaceID == "" && podcast.Private {
+		workspaceID = defaultWorkspaceID
+	}
+	if workspaceID == "" || (podcast.Storage != nil && !podcast.Private) {
+		return nil
+	}
+	var workspace struct {
+		Storage  *StorageLocation `bson:"storage,omitempty"`
+		KMSKeyID string           `bson:"kms_key_id,omitempty"`
+	}
+	err := workspaces.FindOne(
+		ctx,
+		bson.M{"workspace_id": workspaceID},
+		options.FindOne().SetProjection(bson.M{"storage": 1, "kms_key_id": 1}),
+	).Decode(&workspace)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return fmt.Errorf("failed to look up workspace %s: %w", workspaceID, err)
+	}
+	if podcast.Storage == nil {
+		podcast.Storage = workspace.Storage
+	}
+	if podcast.Private {
+		if workspace.KMSKeyID == "" {
+			return fmt.Errorf("podcast is private but workspace %s has no KMS key", workspaceID)
+		}
+		podcast.Encryption = &TranscriptEncryption{WorkspaceID: workspaceID, KMSKeyID: workspace.KMSKeyID}
+	}
+	return nil
+}
+
+// triggerEpisodes starts executions for a podcast's new episodes and
+// records the execution ARN on each episode
+func triggerEpisodes(ctx context.Context, podcast Podcast, pending []pendingEpisode, episodesCollection *mongo.Collection, result *PodcastResult) {
+	if len(pending) == 0 {
+		return
+	}
+
+	if err := resolveWorkspace(ctx, episodesCollection.Database().Collection("workspaces"), &podcast); err != nil {
+		errMsg := fmt.Sprintf("Failed to trigger Step Functions for podcast %s: %v", podcast.PodcastID, err)
+		log.Println(errMsg)
+		result.Errors = append(result.Errors, errMsg)
+		ids := make([]string, len(pending))
+		for i, ep := range pending {
+			ids[i] = ep.EpisodeID
+		}
+		_, _ = episodesCollection.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}}, bson.M{"$set": bson.M{"status": "failed", "error": err.Error()}})
+		return
+	}
+
+	if triggers.mode == triggerModeBatch {
+		ids := make([]string, len(pending))
+		for i, ep := range pending {
+			ids[i] = ep.EpisodeID
+		}
+		filter := bson.M{"_id": bson.M{"$in": ids}}
+
+		executionArn, err := triggerBatchStepFunction(ctx, podcast, pending)
+		if err != nil {
+			errMsg := fmt.Sprintf("Failed to trigger batch Step Function for podcast %s: %v", podcast.PodcastID, err)
+			log.Println(errMsg)
+			result.Errors = append(result.Errors, errMsg)
+			_, _ = episodesCollection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"status": "failed", "error": err.Error()}})
+			return
+		}
+		log.Printf("Triggered batch Step Function for %d episodes of podcast %s: %s", len(pending), podcast.PodcastID, executionArn)
+		_, _ = episodesCollection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"execution_arn": executionArn}})
+		return
+	}
+
+	for _, ep := range pending {
+		executionArn, err := triggerStepFunction(ctx, podcast, ep.EpisodeID, ep.AudioURL)
+		if err != nil {
+			errMsg := fmt.Sprintf("Failed to trigger Step Function for %s: %v", ep.EpisodeID, err)
+			log.Println(errMsg)
+			result.Errors = append(result.Errors, errMsg)
+
+			// Update episode status to failed
+			_, _ = episodesCollection.UpdateOne(
+				ctx,
+				bson.M{"_id": ep.EpisodeID},
+				bson.M{"$set": bson.M{"status": "failed", "error": err.Error()}},
+			)
+		} else {
+			log.Printf("Triggered Step Function for episode %s: %s", ep.EpisodeID, executionArn)
+			// Recorded so the execution's status can be looked up later
+			_, _ = episodesCollection.UpdateOne(
+				ctx,
+				bson.M{"_id": ep.EpisodeID},
+				bson.M{"$set": bson.M{"execution_arn": executionArn}},
+			)
+		}
+	}
+}
+
+// newBatchInput builds the batch execution input for a podcast's episodes
+func newBatchInput(podcast Podcast, pending []pendingEpisode, episodeARN string) BatchStepFunctionInput {
+	input := BatchStepFunctionInput{
+		PodcastID:       podcast.PodcastID,
+		StateMachineARN: episodeARN,
+		Episodes:        make([]StepFunctionInput, len(pending)),
+	}
+	for i, ep := range pending {
+		input.Episodes[i] = newStepFunctionInput(podcast, ep.EpisodeID, ep.AudioURL)
+	}
+	return input
+}
+
+// triggerBatchStepFunction starts one STEP_FUNCTION_BATCH_ARN execution for
+// a podcast's episodes and returns its ARN
+func triggerBatchStepFunction(ctx context.Context, podcast Podcast, pending []pendingEpisode) (string, error) {
+	episodeARN := stateMachineFor(podcast.Workflow, workflowRoutes, os.Getenv("STEP_FUNCTION_ARN"))
+	inputJSON, err := json.Marshal(newBatchInput(podcast, pending, episodeARN))
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal input: %w", err)
+	}
+
+	if err := startLimiter.wait(ctx); err != nil {
+		return "", err
+	}
+	name := batchExecutionName(snapshotPodcastID(podcast), time.Now())
+	out, err := sfnClient.StartExecutionWithContext(ctx, &sfn.StartExecutionInput{
+		StateMachineArn: aws.String(triggers.batchARN),
+		Name:            aws.String(name),
+		Input:           aws.String(string(inputJSON)),
+	})
+	if isExecutionAlreadyExists(err) {
+		log.Printf("Execution %s already exists, treating the start as done", name)
+		return executionARN(triggers.batchARN, name), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(out.ExecutionArn), nil
+}
@@ -0,0 +1,121 @@
+//go:build !http
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestLoadTriggerConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		batchARN string
+		rate     string
+		want     triggerConfig
+	}{
+		{name: "defaults", want: triggerConfig{mode: triggerModeEpisode, startRate: defaultStartRate}},
+		{
+			name: "batch mode", mode: "BATCH", batchARN: "arn:batch", rate: "2.5",
+			want: triggerConfig{mode: triggerModeBatch, batchARN: "arn:batch", startRate: 2.5},
+		},
+		{
+			name: "batch mode without a state machine", mode: "batch", rate: "0",
+			want: triggerConfig{mode: triggerModeEpisode, startRate: 0},
+		},
+		{
+			name: "invalid values", mode: "bulk", rate: "-1",
+			want: triggerConfig{mode: triggerModeEpisode, startRate: defaultStartRate},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("STEP_FUNCTION_TRIGGER_MODE", tt.mode)
+			t.Setenv("STEP_FUNCTION_BATCH_ARN", tt.batchARN)
+			t.Setenv("STEP_FUNCTION_START_RATE", tt.rate)
+			if got := loadTriggerConfig(); got != tt.want {
+				t.Errorf("loadTriggerConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimiterSpacesCalls(t *testing.T) {
+	l := newRateLimiter(100) // one call per 10ms
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatalf("wait: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("5 calls took %s, want at least 40ms", elapsed)
+	}
+
+	if newRateLimiter(0).wait(context.Background()) != nil {
+		t.Error("a disabled limiter should never fail")
+	}
+}
+
+func TestRateLimiterCanceled(t *testing.T) {
+	l := newRateLimiter(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := l.wait(ctx); err != nil {
+		t.Fatalf("first call should not wait: %v", err)
+	}
+	cancel()
+	if err := l.wait(ctx); err == nil {
+		t.Error("expected the canceled context's error")
+	}
+}
+
+func TestNewBatchInput(t *testing.T) {
+	podcast := Podcast{PodcastID: "pod_1", Workflow: &PodcastWorkflow{Language: "es"}}
+	pending := []pendingEpisode{
+		{EpisodeID: "ep1", AudioURL: "https://a/1.mp3"},
+		{EpisodeID: "ep2", AudioURL: "https://a/2.mp3"},
+	}
+
+	t.Setenv("S3_BUCKET", "bucket")
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	raw, err := json.Marshal(newBatchInput(podcast, pending, "arn:episode"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"podcast_id":"pod_1","state_machine_arn":"arn:episode","episodes":[` +
+		`{"episode_id":"ep1","audio_url":"https://a/1.mp3","s3_bucket":"bucket","s3_region":"us-east-1","storage":null,"encryption":null,"language":"es"},` +
+		`{"episode_id":"ep2","audio_url":"https://a/2.mp3","s3_bucket":"bucket","s3_region":"us-east-1","storage":null,"encryption":null,"language":"es"}]}`
+	if string(raw) != want {
+		t.Errorf("batch input = %s, want %s", raw, want)
+	}
+}
+
+func TestPipelineStorage(t *testing.T) {
+	t.Setenv("S3_BUCKET", "audio")
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	tests := []struct {
+		name       string
+		storage    *StorageLocation
+		wantBucket string
+		wantRegion string
+	}{
+		{name: "unpinned", storage: nil, wantBucket: "audio", wantRegion: "us-east-1"},
+		{name: "incomplete location", storage: &StorageLocation{Region: "eu-west-1"}, wantBucket: "audio", wantRegion: "us-east-1"},
+		{name: "audio bucket", storage: &StorageLocation{Region: "eu-west-1", Bucket: "eu-transcripts", AudioBucket: "eu-audio"}, wantBucket: "eu-audio", wantRegion: "eu-west-1"},
+		{name: "single bucket", storage: &StorageLocation{Region: "eu-central-1", Bucket: "eu-all"}, wantBucket: "eu-all", wantRegion: "eu-central-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket, region := pipelineStorage(tt.storage)
+			if bucket != tt.wantBucket || region != tt.wantRegion {
+				t.Errorf("pipelineStorage() = %s, %s, want %s, %s", bucket, region, tt.wantBucket, tt.wantRegion)
+			}
+		})
+	}
+}
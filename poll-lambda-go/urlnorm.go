@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Enclosure URLs are canonicalized before they are looked up or hashed into
+// an episode ID: analytics redirect prefixes (Podtrac, Chartable, ...) are
+// unwrapped, the scheme and host lowercased, default ports, fragments and
+// campaign parameters dropped, and the remaining parameters sorted. This
+// must produce the same string as the API's app/url_normalization.py,
+// since both derive episode IDs from it.
+
+// trackingPrefixes match measurement redirect prefixes against "host/path"
+var trackingPrefixes = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)^(dts\.|www\.)?podtrac\.com/(pts/)?redirect\.[a-z0-9]+/`),
+	regexp.MustCompile(`(?i)^(www\.)?chtbl\.com/track/[^/]+/`),
+	regexp.MustCompile(`(?i)^chrt\.fm/track/[^/]+/`),
+	regexp.MustCompile(`(?i)^pdst\.fm/e/`),
+	regexp.MustCompile(`(?i)^(pfx\.)?vpixl\.com/[^/]+/`),
+	regexp.MustCompile(`(?i)^op3\.dev/e(,[^/]*)?/`),
+	regexp.MustCompile(`(?i)^(verifi\.)?podscribe\.com/rss/p/`),
+	regexp.MustCompile(`(?i)^pscrb\.fm/rss/p/`),
+	regexp.MustCompile(`(?i)^arttrk\.com/p/[^/]+/`),
+	regexp.MustCompile(`(?i)^mgln\.ai/e/[^/]+/`),
+	regexp.MustCompile(`(?i)^prfx\.byspotify\.com/e/`),
+	regexp.MustCompile(`(?i)^claritaspod\.com/measure/`),
+	regexp.MustCompile(`(?i)^tracking\.swap\.fm/track/[^/]+/`),
+}
+
+// trackingParams only identify a campaign or listener
+var trackingParams = regexp.MustCompile(`(?i)^(utm_\w+|fbclid|gclid|mc_(cid|eid)|aw(collection|episode)id)$`)
+
+var defaultPorts = map[string]string{"http": "80", "https": "443"}
+
+// stripTrackingPrefixes unwraps (possibly chained) analytics redirect prefixes
+func stripTrackingPrefixes(rawURL string) string {
+	scheme, rest, ok := strings.Cut(rawURL, "://")
+	if !ok {
+		return rawURL
+	}
+	for {
+		stripped := false
+		for _, prefix := range trackingPrefixes {
+			loc := prefix.FindStringIndex(rest)
+			if loc == nil {
+				continue
+			}
+			rest = rest[loc[1]:]
+			// A few services embed the full target URL, scheme included
+			if innerScheme, innerRest, ok := strings.Cut(rest, "://"); ok {
+				if _, known := defaultPorts[strings.ToLower(innerScheme)]; known {
+					scheme, rest = innerScheme, innerRest
+				}
+			}
+			stripped = true
+			break
+		}
+		if !stripped {
+			return scheme + "://" + rest
+		}
+	}
+}
+
+type queryParam struct {
+	key, value string
+}
+
+// parseQuery splits a query string like Python's parse_qsl(keep_blank_values=True)
+func parseQuery(query string) []queryParam {
+	var params []queryParam
+	for _, pair := range strings.Split(query, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		params = append(params, queryParam{unescapeQuery(key), unescapeQuery(value)})
+	}
+	return params
+}
+
+func unescapeQuery(s string) string {
+	s = strings.ReplaceAll(s, "+", " ")
+	if unescaped, err := url.PathUnescape(s); err == nil {
+		return unescaped
+	}
+	return s
+}
+
+// escapeQuery percent-encodes everything but unreserved characters, like Python's quote(safe="")
+func escapeQuery(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// normalizeURL canonicalizes a feed or enclosure URL
+func normalizeURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return rawURL
+	}
+
+	stripped := stripTrackingPrefixes(rawURL)
+	scheme, rest, ok := strings.Cut(stripped, "://")
+	if !ok || scheme == "" {
+		return rawURL
+	}
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, query, _ := strings.Cut(rest, "?")
+	authority, path := rest, "/"
+	if i := strings.Index(rest, "/"); i >= 0 {
+		authority, path = rest[:i], rest[i:]
+	}
+	if authority == "" {
+		return rawURL
+	}
+
+	scheme = strings.ToLower(scheme)
+	userinfo, hostport := "", authority
+	if i := strings.LastIndex(authority, "@"); i >= 0 {
+		userinfo, hostport = authority[:i+1], authority[i+1:]
+	}
+	host, port := hostport, ""
+	if i := strings.LastIndex(hostport, ":"); i >= 0 && !strings.Contains(hostport[i:], "]") {
+		host, port = hostport[:i], hostport[i+1:]
+	}
+	if _, err := strconv.Atoi(port); port != "" && err != nil {
+		return rawURL
+	}
+	host = strings.ToLower(host)
+	if port != "" && port != defaultPorts[scheme] {
+		host += ":" + port
+	}
+
+	params := parseQuery(query)
+	kept := make([]queryParam, 0, len(params))
+	for _, p := range params {
+		if !trackingParams.MatchString(p.key) {
+			kept = append(kept, p)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		if kept[i].key != kept[j].key {
+			return kept[i].key < kept[j].key
+		}
+		return kept[i].value < kept[j].value
+	})
+
+	// Leave an already-canonical query string byte-for-byte (signed URLs)
+	unchanged := len(kept) == len(params)
+	for i := 0; unchanged && i < len(kept); i++ {
+		unchanged = kept[i] == params[i]
+	}
+	if !unchanged {
+		encoded := make([]string, len(kept))
+		for i, p := range kept {
+			encoded[i] = escapeQuery(p.key) + "=" + escapeQuery(p.value)
+		}
+		query = strings.Join(encoded, "&")
+	}
+
+	normalized := scheme + "://" + userinfo + host + path
+	if query != "" {
+		normalized += "?" + query
+	}
+	return normalized
+}
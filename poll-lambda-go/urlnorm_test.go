@@ -0,0 +1,78 @@
+package main
+
+import "testing"
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{
+			name:     "already canonical",
+			url:      "https://example.com/podcast.mp3",
+			expected: "https://example.com/podcast.mp3",
+		},
+		{
+			name:     "chained tracking prefixes, campaign params and fragment",
+			url:      "https://dts.podtrac.com/redirect.mp3/chtbl.com/track/ABC12/Traffic.Libsyn.com/Show/ep1.mp3?utm_source=rss&b=2&a=1#x",
+			expected: "https://traffic.libsyn.com/Show/ep1.mp3?a=1&b=2",
+		},
+		{
+			name:     "prefix embedding the full target URL",
+			url:      "https://op3.dev/e,pg=123/https://cdn.example.com/a.mp3",
+			expected: "https://cdn.example.com/a.mp3",
+		},
+		{
+			name:     "inner scheme wins",
+			url:      "https://pdst.fm/e/chrt.fm/track/X/http://host.com/a%20b.mp3?sig=a%2Fb",
+			expected: "http://host.com/a%20b.mp3?sig=a%2Fb",
+		},
+		{
+			name:     "host case and default port",
+			url:      "HTTPS://Feeds.Example.COM:443/podcast.xml",
+			expected: "https://feeds.example.com/podcast.xml",
+		},
+		{
+			name:     "non-default port kept",
+			url:      "http://example.com:8080/feed",
+			expected: "http://example.com:8080/feed",
+		},
+		{
+			name:     "params re-encoded when reordered",
+			url:      "https://example.com/a.mp3?z=1&key=a+b%2Fc",
+			expected: "https://example.com/a.mp3?key=a%20b%2Fc&z=1",
+		},
+		{
+			name:     "empty host path",
+			url:      "https://example.com",
+			expected: "https://example.com/",
+		},
+		{
+			name:     "not a URL",
+			url:      "not a url",
+			expected: "not a url",
+		},
+		{
+			name:     "empty",
+			url:      "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := normalizeURL(tt.url); result != tt.expected {
+				t.Errorf("normalizeURL(%q) = %q, want %q", tt.url, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeURLIsStable(t *testing.T) {
+	url := "https://dts.podtrac.com/redirect.mp3/Example.com/ep.mp3?b=2&a=1&utm_medium=feed"
+	once := normalizeURL(url)
+	if twice := normalizeURL(once); twice != once {
+		t.Errorf("normalizeURL() not idempotent: %q then %q", once, twice)
+	}
+}
@@ -0,0 +1,81 @@
+//go:build !http
+
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+)
+
+// New episodes start the STEP_FUNCTION_ARN state machine unless their
+// podcast says otherwise. A podcast document's "workflow" field can name
+// its own state machine and add language, provider and priority to the
+// execution input. STEP_FUNCTION_ARNS routes by those fields instead, as a
+// JSON object keyed "provider:<name>" or "language:<code>":
+//
+//	{"provider:local-whisper": "arn:...", "language:es": "arn:..."}
+//
+// The podcast's own ARN wins, then its provider's route, then its
+// language's, then STEP_FUNCTION_ARN.
+
+// PodcastWorkflow is a podcast's transcription workflow settings
+type PodcastWorkflow struct {
+	StateMachineARN string `bson:"state_machine_arn,omitempty"`
+	Language        string `bson:"language,omitempty"`
+	Provider        string `bson:"provider,omitempty"`
+	Priority        string `bson:"priority,omitempty"`
+}
+
+// loadWorkflowRoutes reads STEP_FUNCTION_ARNS; an invalid value is logged
+// and ignored so polling keeps working on the default state machine
+func loadWorkflowRoutes() map[string]string {
+	raw := os.Getenv("STEP_FUNCTION_ARNS")
+	if raw == "" {
+		return nil
+	}
+	var routes map[string]string
+	if err := json.Unmarshal([]byte(raw), &routes); err != nil {
+		log.Printf("Warning: invalid STEP_FUNCTION_ARNS, ignoring it: %v", err)
+		return nil
+	}
+	return routes
+}
+
+var workflowRoutes = loadWorkflowRoutes()
+
+// stateMachineFor picks the state machine for a podcast's episodes
+func stateMachineFor(wf *PodcastWorkflow, routes map[string]string, defaultARN string) string {
+	if wf == nil {
+		return defaultARN
+	}
+	if wf.StateMachineARN != "" {
+		return wf.StateMachineARN
+	}
+	if arn := routes["provider:"+wf.Provider]; wf.Provider != "" && arn != "" {
+		return arn
+	}
+	if arn := routes["language:"+wf.Language]; wf.Language != "" && arn != "" {
+		return arn
+	}
+	return defaultARN
+}
+
+// newStepFunctionInput builds the execution input for an episode
+func newStepFunctionInput(podcast Podcast, episodeID, audioURL string) StepFunctionInput {
+	s3Bucket, s3Region := pipelineStorage(podcast.Storage)
+	input := StepFunctionInput{
+		EpisodeID:  episodeID,
+		AudioURL:   audioURL,
+		S3Bucket:   s3Bucket,
+		S3Region:   s3Region,
+		Storage:    podcast.Storage,
+		Encryption: podcast.Encryption,
+	}
+	if wf := podcast.Workflow; wf != nil {
+		input.Language = wf.Language
+		input.Provider = wf.Provider
+		input.Priority = wf.Priority
+	}
+	return input
+}
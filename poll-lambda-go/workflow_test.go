@@ -0,0 +1,80 @@
+//go:build !http
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStateMachineFor(t *testing.T) {
+	routes := map[string]string{
+		"provider:local-whisper": "arn:provider",
+		"language:es":            "arn:spanish",
+	}
+
+	tests := []struct {
+		name string
+		wf   *PodcastWorkflow
+		want string
+	}{
+		{name: "no workflow", wf: nil, want: "arn:default"},
+		{name: "empty workflow", wf: &PodcastWorkflow{}, want: "arn:default"},
+		{name: "podcast ARN wins", wf: &PodcastWorkflow{StateMachineARN: "arn:own", Provider: "local-whisper"}, want: "arn:own"},
+		{name: "provider route", wf: &PodcastWorkflow{Provider: "local-whisper", Language: "es"}, want: "arn:provider"},
+		{name: "language route", wf: &PodcastWorkflow{Provider: "openai", Language: "es"}, want: "arn:spanish"},
+		{name: "no matching route", wf: &PodcastWorkflow{Language: "de"}, want: "arn:default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stateMachineFor(tt.wf, routes, "arn:default"); got != tt.want {
+				t.Errorf("stateMachineFor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewStepFunctionInput(t *testing.T) {
+	t.Setenv("S3_BUCKET", "bucket")
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	plain, err := json.Marshal(newStepFunctionInput(Podcast{}, "ep1", "https://a/1.mp3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"episode_id":"ep1","audio_url":"https://a/1.mp3","s3_bucket":"bucket","s3_region":"us-east-1","storage":null,"encryption":null}`
+	if string(plain) != want {
+		t.Errorf("input = %s, want %s", plain, want)
+	}
+
+	wf := &PodcastWorkflow{Language: "es", Provider: "openai", Priority: "high"}
+	input := newStepFunctionInput(Podcast{Workflow: wf}, "ep1", "https://a/1.mp3")
+	if input.Language != "es" || input.Provider != "openai" || input.Priority != "high" {
+		t.Errorf("input = %+v, want the podcast's workflow fields", input)
+	}
+
+	eu := &StorageLocation{Location: "eu", Region: "eu-west-1", Bucket: "eu-transcripts", AudioBucket: "eu-audio"}
+	input = newStepFunctionInput(Podcast{Storage: eu}, "ep1", "https://a/1.mp3")
+	if input.S3Bucket != "eu-audio" || input.S3Region != "eu-west-1" || input.Storage != eu {
+		t.Errorf("input = %+v, want the podcast's storage location", input)
+	}
+
+	encryption := &TranscriptEncryption{WorkspaceID: "ws1", KMSKeyID: "alias/ws1"}
+	input = newStepFunctionInput(Podcast{Private: true, Encryption: encryption}, "ep1", "https://a/1.mp3")
+	if input.Encryption != encryption {
+		t.Errorf("input = %+v, want the private podcast's workspace key", input)
+	}
+}
+
+func TestLoadWorkflowRoutes(t *testing.T) {
+	t.Setenv("STEP_FUNCTION_ARNS", `{"language:es": "arn:spanish"}`)
+	if routes := loadWorkflowRoutes(); routes["language:es"] != "arn:spanish" {
+		t.Errorf("routes = %v", routes)
+	}
+
+	t.Setenv("STEP_FUNCTION_ARNS", "not json")
+	if routes := loadWorkflowRoutes(); routes != nil {
+		t.Errorf("routes = %v, want nil for invalid JSON", routes)
+	}
+}
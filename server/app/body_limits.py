@@ -0,0 +1,128 @@
+"""Request body size limits.
+
+Every request body is capped at MAX_REQUEST_BODY_BYTES. REQUEST_BODY_LIMITS
+overrides the cap for path prefixes (the longest match wins; 0 removes it):
+
+    /api/podcasts/import=52428800,/graphql=262144
+
+The subscription import and audio upload routes default to
+IMPORT_MAX_FILE_BYTES and TRANSCRIBE_UPLOAD_MAX_BYTES plus room for the
+multipart envelope. A declared Content-Length over the limit is
+rejected with 413 before the body is read; chunked bodies are counted as
+they stream in and cut off with 413 as soon as they pass it, so oversized
+uploads are never buffered.
+"""
+import logging
+from typing import Dict, List, Optional, Tuple
+
+from fastapi import HTTPException, status
+from starlette.responses import JSONResponse
+from starlette.types import ASGIApp, Message, Receive, Scope, Send
+
+from app.config import settings
+from app.validation import field_error, validation_response_body
+
+logger = logging.getLogger(__name__)
+
+# Multipart boundaries and form fields sent alongside an uploaded file
+MULTIPART_OVERHEAD_BYTES = 64 * 1024
+
+IMPORT_PATH = "/api/podcasts/import"
+TRANSCRIBE_PATH = "/api/transcribe"
+
+
+class RequestBodyTooLarge(HTTPException):
+    """Raised while reading a body that exceeds its route's limit."""
+
+    def __init__(self, limit: int):
+        super().__init__(
+            status_code=status.HTTP_413_REQUEST_ENTITY_TOO_LARGE,
+            detail=f"Request body exceeds {limit} bytes"
+        )
+        self.limit = limit
+
+
+def too_large_response(limit: int) -> JSONResponse:
+    """The 413 response, in the same shape as validation failures."""
+    return JSONResponse(
+        status_code=status.HTTP_413_REQUEST_ENTITY_TOO_LARGE,
+        content=validation_response_body([
+            field_error("body", "too_large", f"Request body exceeds {limit} bytes")
+        ])
+    )
+
+
+def load_limits() -> List[Tuple[str, int]]:
+    """Per-prefix limits from settings, longest prefix first; invalid entries are skipped."""
+    limits: Dict[str, int] = {
+        IMPORT_PATH: settings.import_max_file_bytes + MULTIPART_OVERHEAD_BYTES,
+        TRANSCRIBE_PATH: (settings.transcribe_upload_max_bytes + MULTIPART_OVERHEAD_BYTES
+                          if settings.transcribe_upload_max_bytes else 0),
+    }
+    for entry in settings.request_body_limits.split(","):
+        if not entry.strip():
+            continue
+        prefix, _, value = entry.partition("=")
+        try:
+            limits[prefix.strip().rstrip("/")] = int(value)
+        except ValueError:
+            logger.error(f"Ignoring invalid REQUEST_BODY_LIMITS entry: {entry.strip()}")
+    return sorted(limits.items(), key=lambda item: len(item[0]), reverse=True)
+
+
+class BodySizeLimitMiddleware:
+    """Rejects request bodies over the limit for their path with 413."""
+
+    def __init__(self, app: ASGIApp):
+        self.app = app
+        self.limits = load_limits()
+
+    def limit_for(self, path: str) -> Optional[int]:
+        """Byte limit for a path (None = unlimited)."""
+        for prefix, limit in self.limits:
+            if path == prefix or path.startswith(prefix + "/"):
+                return limit or None
+        return settings.max_request_body_bytes or None
+
+    async def __call__(self, scope: Scope, receive: Receive, send: Send):
+        if scope["type"] != "http":
+            await self.app(scope, receive, send)
+            return
+        limit = self.limit_for(scope["path"])
+        if limit is None:
+            await self.app(scope, receive, send)
+            return
+
+        headers = dict(scope.get("headers") or [])
+        declared = headers.get(b"content-length")
+        if declared is not None and declared.isdigit() and int(declared) > limit:
+            logger.warning(f"Rejected {scope['method']} {scope['path']}: Content-Length {int(declared)} > {limit}")
+            await too_large_response(limit)(scope, receive, send)
+            return
+
+        received = 0
+        response_started = False
+
+        async def limited_receive() -> Message:
+            nonlocal received
+            message = await receive()
+            if message["type"] == "http.request":
+                received += len(message.get("body", b""))
+                if received > limit:
+                    logger.warning(f"Rejected {scope['method']} {scope['path']}: body passed {limit} bytes")
+                    raise RequestBodyTooLarge(limit)
+            return message
+
+        async def tracking_send(message: Message):
+            nonlocal response_started
+            if message["type"] == "http.response.start":
+                response_started = True
+            await send(message)
+
+        try:
+            await self.app(scope, limited_receive, tracking_send)
+        except RequestBodyTooLarge:
+            # Raised outside FastAPI's exception handling (e.g. from another middleware)
+            if response_started:
+                raise
+            await too_large_response(limit)(scope, receive, send)
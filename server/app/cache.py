@@ -0,0 +1,116 @@
+"""Optional Redis cache for hot reads.
+
+With REDIS_URL set, transcript text, the episode list, bulk job status and
+podcast directory searches are served from Redis for a few seconds to a
+day (CACHE_*_TTL_SECONDS), keeping MongoDB and S3 load flat as dashboards
+poll. Without it, or while Redis is unreachable, every read goes to the
+database as before.
+
+Entries that a write invalidates live under a namespace ("episodes",
+"job:<id>"): the namespace's version is part of their keys, and write
+paths call invalidate() to bump it, so every instance stops reading the
+old entries at once. Writes made outside the API (the Lambdas) are picked
+up when entries expire. Transcripts are keyed by their episode's
+updated_at instead, so a re-transcription never serves the old text.
+"""
+import logging
+import time
+from typing import Any, Awaitable, Callable, Optional
+
+import redis.asyncio as redis
+from bson import json_util
+
+from app.config import settings
+
+logger = logging.getLogger(__name__)
+
+# After a Redis error, reads go straight to the database for this long
+RETRY_AFTER_SECONDS = 30.0
+
+# Namespace versions outlive any entry keyed by them
+VERSION_TTL_SECONDS = 7 * 24 * 3600
+
+
+class Cache:
+    """Read-through cache of JSON-serialisable values (MongoDB documents included)."""
+
+    def __init__(self):
+        self._client: Optional[redis.Redis] = None
+        self._unavailable_until = 0.0
+
+    @property
+    def enabled(self) -> bool:
+        return bool(settings.redis_url) and time.monotonic() >= self._unavailable_until
+
+    def _redis(self) -> redis.Redis:
+        if self._client is None:
+            self._client = redis.from_url(
+                settings.redis_url, socket_timeout=1.0, socket_connect_timeout=1.0
+            )
+        return self._client
+
+    def _failed(self, action: str, error: Exception):
+        if time.monotonic() >= self._unavailable_until:
+            logger.warning(f"Redis {action} failed, bypassing the cache for {RETRY_AFTER_SECONDS:.0f}s: {error}")
+        self._unavailable_until = time.monotonic() + RETRY_AFTER_SECONDS
+
+    async def get_or_load(
+        self,
+        key: str,
+        ttl_seconds: int,
+        load: Callable[[], Awaitable[Any]],
+        namespace: Optional[str] = None,
+    ) -> Any:
+        """
+        Return the cached value of key, loading and caching it on a miss.
+
+        Args:
+            key: Cache key (without REDIS_KEY_PREFIX)
+            ttl_seconds: How long a loaded value is kept; 0 disables caching
+            load: Reads the value from the database; None results aren't cached
+            namespace: Namespace whose invalidate() drops the entry
+        """
+        if not self.enabled or ttl_seconds <= 0:
+            return await load()
+        try:
+            full_key = settings.redis_key_prefix + key
+            if namespace:
+                version = await self._redis().get(f"{settings.redis_key_prefix}v:{namespace}")
+                full_key += f"@{int(version or 0)}"
+            cached = await self._redis().get(full_key)
+            if cached is not None:
+                return json_util.loads(cached)
+        except Exception as e:
+            self._failed("read", e)
+            return await load()
+
+        value = await load()
+        if value is not None:
+            try:
+                await self._redis().set(full_key, json_util.dumps(value), ex=ttl_seconds)
+            except Exception as e:
+                self._failed("write", e)
+        return value
+
+    async def invalidate(self, namespace: str):
+        """Drop every entry cached under namespace, on all instances."""
+        if not settings.redis_url:
+            return
+        try:
+            key = f"{settings.redis_key_prefix}v:{namespace}"
+            async with self._redis().pipeline(transaction=False) as pipe:
+                pipe.incr(key)
+                pipe.expire(key, VERSION_TTL_SECONDS)
+                await pipe.execute()
+        except Exception as e:
+            # Entries left behind expire with their TTL
+            self._failed("invalidation", e)
+
+    async def close(self):
+        if self._client is not None:
+            await self._client.aclose()
+            self._client = None
+
+
+# Shared by the whole process
+cache = Cache()
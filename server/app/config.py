@@ -1,6 +1,107 @@
-"""Application configuration module."""
-from pydantic_settings import BaseSettings
-from typing import List
+"""Application configuration module.
+
+Settings come from environment variables (and .env), falling back to an
+optional YAML or TOML file named by CONFIG_FILE, then to the defaults below.
+Keys in the file are setting names in any case; nested sections are joined
+with underscores, so `whisper: {download_retries: 5}` sets
+whisper_download_retries. Invalid combinations fail at startup.
+"""
+import base64
+import binascii
+import json
+import os
+import re
+import tomllib
+from dotenv import dotenv_values
+from pathlib import Path
+from pydantic import model_validator
+from pydantic.fields import FieldInfo
+from pydantic_settings import BaseSettings, PydanticBaseSettingsSource
+from typing import Any, Dict, List, Tuple, Type
+
+from app.secret_sources import secrets_provider
+
+# Setting names whose values are never shown in config dumps
+SECRET_SETTING = re.compile(r"(key|secret|password|token|webhooks)", re.IGNORECASE)
+# user:password@ in connection URLs
+URL_CREDENTIALS = re.compile(r"(://[^/:@]+:)[^@/]+@")
+# Storage classes objects can be written with
+S3_STORAGE_CLASSES = (
+    "STANDARD", "STANDARD_IA", "ONEZONE_IA", "INTELLIGENT_TIERING", "GLACIER_IR", "GLACIER", "DEEP_ARCHIVE"
+)
+
+
+def _flatten(data: Dict[str, Any], prefix: str = "") -> Dict[str, Any]:
+    """Join nested sections into flat setting names."""
+    flat = {}
+    for key, value in data.items():
+        name = f"{prefix}{key}".lower()
+        if isinstance(value, dict):
+            flat.update(_flatten(value, name + "_"))
+        else:
+            flat[name] = value
+    return flat
+
+
+def load_config_file(path: str) -> Dict[str, Any]:
+    """
+    Read settings from a YAML (.yaml/.yml) or TOML (.toml) file.
+
+    Raises:
+        ValueError: If the file is missing, unreadable or of another type
+    """
+    file = Path(path)
+    if not file.is_file():
+        raise ValueError(f"Config file {path} not found")
+    suffix = file.suffix.lower()
+    if suffix == ".toml":
+        try:
+            data = tomllib.loads(file.read_text())
+        except tomllib.TOMLDecodeError as e:
+            raise ValueError(f"Invalid config file {path}: {e}")
+    elif suffix in (".yaml", ".yml"):
+        try:
+            import yaml
+        except ImportError:
+            raise ValueError("YAML config files need PyYAML installed")
+        try:
+            data = yaml.safe_load(file.read_text()) or {}
+        except yaml.YAMLError as e:
+            raise ValueError(f"Invalid config file {path}: {e}")
+    else:
+        raise ValueError(f"Config file {path} must be .yaml, .yml or .toml")
+    if not isinstance(data, dict):
+        raise ValueError(f"Config file {path} must contain a mapping of settings")
+    return _flatten(data)
+
+
+class ConfigFileSettingsSource(PydanticBaseSettingsSource):
+    """Settings from the file named by CONFIG_FILE."""
+
+    def get_field_value(self, field: FieldInfo, field_name: str) -> Tuple[Any, str, bool]:
+        # Values are read all at once in __call__
+        return None, field_name, False
+
+    def __call__(self) -> Dict[str, Any]:
+        path = os.environ.get("CONFIG_FILE") or dotenv_values(".env").get("CONFIG_FILE")
+        if not path:
+            return {}
+        data = load_config_file(path)
+        unknown = sorted(key for key in data if key not in self.settings_cls.model_fields)
+        if unknown:
+            raise ValueError(f"Unknown settings in {path}: {', '.join(unknown)}")
+        return data
+
+
+class SecretsSettingsSource(PydanticBaseSettingsSource):
+    """Secret settings configured as NAME_SECRET_ARN / NAME_SSM_PARAMETER."""
+
+    def get_field_value(self, field: FieldInfo, field_name: str) -> Tuple[Any, str, bool]:
+        # Values are read all at once in __call__
+        return None, field_name, False
+
+    def __call__(self) -> Dict[str, Any]:
+        return secrets_provider.load()
 
 
 class Settings(BaseSettings):
@@ -9,6 +110,16 @@ class Settings(BaseSettings):
     # MongoDB Configuration
     mongodb_url: str = "mongodb://localhost:27017"
     mongodb_db_name: str = "podcast_manager"
+    mongodb_max_pool_size: int = 100
+    mongodb_read_preference: str = "primary"  # primaryPreferred, secondary, secondaryPreferred, nearest
+    mongodb_write_concern: str = ""  # "majority" or a node count; empty uses the server default
+    mongodb_server_selection_timeout_ms: int = 30000
+    mongodb_connect_retries: int = 5  # Startup connection attempts before giving up
+    mongodb_connect_backoff_seconds: float = 1.0  # Doubled after each failed attempt, up to 30s
+    # Time every query; slow ones are logged and served at GET /debug/queries
+    mongodb_query_trace_enabled: bool = False
+    mongodb_slow_query_ms: int = 100
+    mongodb_query_trace_buffer: int = 200  # Slow queries kept for /debug/queries
 
     # AWS S3 Configuration
     aws_access_key_id: str = ""
@@ -29,23 +140,412 @@ class Settings(BaseSettings):
     # S3 Audio Bucket (separate from transcripts bucket)
     s3_audio_bucket: str = "podcast-audio"
 
+    # Named storage locations podcasts and workspaces can be pinned to, as a
+    # JSON object (see app/services/storage_locations.py), e.g.
+    # {"eu": {"region": "eu-west-1", "bucket": "podcasts-eu-transcripts",
+    #         "audio_bucket": "podcasts-eu-audio"}}
+    storage_locations: str = ""
+
     # Transcription Configuration
     openai_api_key: str = ""
     whisper_service_url: str = "http://localhost:9000"
+    # Comma-separated pool of Whisper containers; overrides whisper_service_url
+    whisper_service_urls: str = ""
+    whisper_balance_strategy: str = "least_busy"  # "least_busy" or "round_robin"
+    whisper_max_concurrent_per_backend: int = 1  # Requests beyond pool capacity queue
+    # Model requested from the backends (tiny ... large-v3, or an OpenAI model name); empty = the backend's own
+    whisper_model: str = ""
+    # Audio seconds per second of processing assumed for bulk progress until a transcription is measured
+    whisper_expected_speed: float = 4.0
+    # Ad/sponsor detection on completed transcripts
+    ad_detection_enabled: bool = True
+    ad_detection_min_confidence: float = 0.5
+    ad_exclude_from_exports: bool = False  # Default for export requests that don't say
+    # OpenAI model that picks notable quotes (with openai_api_key); empty = heuristic only
+    quote_llm_model: str = "gpt-4o-mini"
+    # Transcripts whose quality score (mean Whisper token probability) is lower are flagged low_confidence
+    transcript_quality_threshold: float = 0.5
+
+    temp_dir: str = ""  # Downloaded audio; defaults to <system temp>/podcasts. One per process
+    temp_max_bytes: int = 4 * 1024 ** 3  # Audio kept on disk at once; 0 = no limit
+    whisper_download_timeout_seconds: int = 600  # Per attempt
+    whisper_download_retries: int = 3  # Resumed with a Range request when possible
+    whisper_downloads_per_host: int = 2  # Concurrent audio downloads from one host; 0 = no limit
+    whisper_download_max_retry_after_seconds: int = 300  # Longer Retry-After waits fail the download
+    # Bytes downloaded from one host per UTC day (see app/services/download_usage.py); 0 = no cap
+    whisper_download_host_daily_bytes: int = 0
+    whisper_download_host_daily_limits: str = ""  # Per host, e.g. "traffic.libsyn.com=107374182400"
+    whisper_max_download_bytes: int = 1024 ** 3  # 0 = no limit
+    transcribe_upload_max_bytes: int = 512 * 1024 ** 2  # POST /api/transcribe uploads; 0 = no limit
+    whisper_min_free_disk_bytes: int = 512 * 1024 ** 2  # Headroom kept free in the temp dir
+    whisper_allowed_content_types: str = "audio/,video/,application/octet-stream"  # Prefixes
+    whisper_preprocess_audio: bool = False  # ffmpeg: 16kHz mono, loudness normalization, silence trim
+    whisper_preprocess_silence_threshold_db: int = -50
+    whisper_preprocess_timeout_seconds: int = 600
+    ffmpeg_path: str = "ffmpeg"
+    # POST /api/episodes/{id}/clip
+    clip_max_seconds: int = 300
+    clip_timeout_seconds: int = 120
+    whisper_transcription_timeout_seconds: int = 3600
+    whisper_health_check_interval_seconds: int = 30  # 0 disables the health monitor
+    # Jobs wait this long for a backend to load its model before failing; 0 skips the check
+    whisper_ready_timeout_seconds: int = 600
+
+    # Asynchronous ASR Providers (see app/services/asr_jobs.py). When set,
+    # POST /api/transcription/start submits episodes to the provider instead
+    # of running the Lambda workflow; results arrive by callback or polling
+    asr_provider: str = ""  # "assemblyai", "deepgram", or empty
+    assemblyai_api_key: str = ""
+    deepgram_api_key: str = ""
+    asr_poll_interval_seconds: int = 60
+    asr_job_timeout_hours: int = 6  # Submissions without a result then fail
+
+    # Episode search across shows (see app/services/episode_search.py)
+    episode_search_provider: str = ""  # "listennotes", "taddy", or empty
+    listennotes_api_key: str = ""
+    taddy_user_id: str = ""
+    taddy_api_key: str = ""
+
+    # Transcript search index (see app/services/transcript_indexer.py), kept
+    # current from a change stream on episodes
+    transcript_index_enabled: bool = False
+    transcript_index_poll_seconds: int = 300  # Catch-up interval without change streams
 
     # Application Configuration
     app_host: str = "0.0.0.0"
     app_port: int = 8000
     log_level: str = "INFO"
+    config_dump_enabled: bool = False  # Serve the redacted settings at GET /config
+    # Request bodies over the limit get 413; see app/body_limits.py
+    max_request_body_bytes: int = 1024 ** 2  # 0 = no limit
+    request_body_limits: str = ""  # Per path prefix, e.g. "/api/podcasts/import=52428800"
+    # Enables /admin/runtime-settings (sent as X-Admin-Key); empty disables it
+    admin_api_key: str = ""
+    # Verifies transcript callbacks from the merge Lambda (POST
+    # /api/callbacks/transcript); empty disables the endpoint
+    callback_secret: str = ""
+    # Scope /api data to workspaces; requests send a workspace API key as
+    # X-API-Key (keys are issued under /admin/workspaces)
+    workspaces_enabled: bool = False
+    # Envelope encryption of secrets stored in MongoDB (see
+    # app/field_encryption.py): a KMS key ID, ARN or alias, or for
+    # development a base64 256-bit key; neither stores them in plaintext
+    field_encryption_kms_key_id: str = ""
+    field_encryption_local_key: str = ""
+    # Secrets from Secrets Manager / SSM (see app/secret_sources.py) are
+    # re-read this often so rotations apply without a restart; 0 disables
+    secrets_refresh_interval_seconds: int = 300
+
+    # gRPC API Configuration (served alongside REST on a separate port)
+    grpc_enabled: bool = False
+    grpc_port: int = 50051
+
+    # Redis Cache for hot reads (see app/cache.py); empty disables it
+    redis_url: str = ""  # e.g. redis://localhost:6379/0
+    redis_key_prefix: str = "podcasts:"
+    cache_transcript_ttl_seconds: int = 24 * 3600  # 0 disables caching of that read
+    cache_episode_list_ttl_seconds: int = 30
+    cache_job_ttl_seconds: int = 10
+    cache_directory_ttl_seconds: int = 24 * 3600
+
+    # Response Compression (brotli when accepted, gzip otherwise)
+    compression_enabled: bool = True
+    compression_minimum_size: int = 1024  # Bytes; smaller responses are sent as-is
+    brotli_quality: int = 4  # 0-11; higher is smaller but slower
+
+    # Soft Delete Archival Configuration
+    archive_after_days: int = 30  # Days after deletion before transcripts are archived
+    archive_interval_hours: int = 24  # 0 disables the scheduled archival run
+    archive_prefix: str = "archive/"
+    archive_storage_class: str = "GLACIER_IR"  # S3 storage class for archived transcripts
+
+    # Storage classes and lifecycle of pipeline artifacts (see app/services/storage_lifecycle.py)
+    transcript_storage_class: str = "STANDARD"  # S3 storage class of transcripts the API stores
+    s3_lifecycle_bootstrap: bool = False  # Install the intermediate lifecycle rules at startup
+    chunk_audio_expire_days: int = 7  # Delete audio chunks after this many days (0 = keep)
+    chunk_transcript_ia_days: int = 30  # Move chunk transcripts to STANDARD_IA (0 = don't; at least 30)
+    chunk_transcript_glacier_days: int = 90  # Then to GLACIER (0 = don't)
+    chunk_transcript_expire_days: int = 0  # Delete chunk transcripts (0 = keep)
+    cleanup_intermediates: bool = False  # Have the merge Lambda clean up chunks once merged
+    merged_intermediate_expire_days: int = 1  # Delete intermediates the merge Lambda tagged (0 = keep)
+
+    # MongoDB/S3 Reconciliation Configuration (see app/services/reconciliation.py)
+    reconcile_interval_hours: int = 0  # 0 disables the scheduled reconciliation run
+    reconcile_repair: bool = False  # Scheduled runs repair mismatches instead of only reporting
+    reconcile_verify: bool = False  # Scheduled runs also compare transcript checksums with transcript_sha256
+    reconcile_orphan_prefix: str = "orphaned/"  # Where repairs move transcripts without an owner
+
+    # Topic trend analytics (see app/services/topic_analytics.py)
+    topic_analysis_interval_hours: int = 6  # 0 disables keyword extraction
+    # Guest appearance index (see app/services/guest_index.py)
+    guest_index_interval_hours: int = 6  # 0 disables indexing
+    # Transcript embeddings for similar episodes (see app/services/episode_embeddings.py); need openai_api_key
+    embedding_model: str = "text-embedding-3-small"
+    embedding_interval_hours: int = 6  # 0 disables embedding
+
+    # Concurrent transcriptions shared by bulk jobs and single episodes;
+    # waiting work is admitted by priority
+    transcription_workers: int = 2
+    bulk_schedule_poll_seconds: int = 60  # How often scheduled bulk jobs are checked
+    # A bulk job whose instance hasn't renewed its lease for this long is resumed elsewhere
+    bulk_job_lease_seconds: int = 120
+    # Pause between a bulk job's episodes, sparing the publisher's CDN
+    bulk_episode_delay_seconds: float = 2.0
+    # A bulk episode still queued or transcribing after this long fails (0 = no limit)
+    bulk_episode_timeout_seconds: int = 0
+
+    # Monthly Transcription Quotas (audio minutes; 0 = unlimited)
+    quota_monthly_minutes: int = 0  # Global budget
+    quota_key_monthly_minutes: int = 0  # Per X-API-Key budget
+    quota_default_episode_minutes: int = 60  # Reserved when a feed omits duration
+
+    # Duration Gates (minutes; 0 = no limit): episodes outside them are
+    # skipped by bulk jobs, and by the poll Lambda with the same variables
+    transcribe_min_duration_minutes: int = 0
+    transcribe_max_duration_minutes: int = 0
+
+    # Cost Model (USD) used for per-episode and per-job cost tracking
+    cost_transcription_per_minute: float = 0.006  # Provider rate per audio minute
+    cost_s3_per_gb_month: float = 0.023
+    cost_lambda_per_gb_second: float = 0.0000166667
+    cost_lambda_memory_mb: int = 3008  # Whisper Lambda memory size
+    cost_compute_seconds_per_audio_minute: float = 6.0  # Used for estimates only
+    cost_audio_bytes_per_minute: int = 480000  # 64 kbps audio chunks
+
+    # Subscription Import Configuration (Apple Podcasts / Spotify exports)
+    podcast_directory_url: str = "https://itunes.apple.com/search"  # iTunes Search API compatible
+    import_max_file_bytes: int = 20 * 1024 ** 2
+    import_max_shows: int = 500
+
+    # RSS Feed Fetching (limits a slow or oversized feed can't get past)
+    rss_fetch_timeout_seconds: int = 10  # Whole request, connecting through reading the body
+    rss_max_feed_bytes: int = 20 * 1024 ** 2
+
+    # Parsed Feed Cache (see FeedCache in app/services/rss_parser.py)
+    rss_cache_ttl_seconds: int = 300  # Served without a request; older entries are revalidated
+    rss_cache_max_entries: int = 256  # 0 disables the cache
+
+    # Public URL of this API, used for links in generated feeds
+    public_base_url: str = "http://localhost:8000"
+
+    # Email Notification Configuration
+    email_backend: str = ""  # "smtp", "ses", or empty to disable
+    email_from: str = "podcasts@localhost"
+    smtp_host: str = "localhost"
+    smtp_port: int = 587
+    smtp_username: str = ""
+    smtp_password: str = ""
+    smtp_use_tls: bool = True
+    notification_digest_interval_minutes: int = 60  # 0 disables the scheduled digest
+
+    # Chat Webhook Notification Configuration
+    # JSON list of destinations, e.g.
+    # [{"name": "ops", "type": "slack", "url": "https://hooks.slack.com/...",
+    #   "events": ["bulk_job_completed", "bulk_job_failed"],
+    #   "templates": {"bulk_job_failed": ":x: {podcast_title} backfill failed"}}]
+    chat_webhooks: str = ""
+    flagship_podcast_ids: str = ""  # Comma-separated podcast IDs announced on transcription
 
     # CORS Configuration
-    cors_origins: str = "http://localhost:3000,http://localhost:8080"
+    cors_origins: str = "http://localhost:3000,http://localhost:8080"  # "https://*.example.com" for subdomains
+    # Response headers readable by browser clients (validators, quota, pagination)
+    cors_expose_headers: str = "ETag,Last-Modified,Retry-After,X-Total-Count,X-Next-Cursor,X-Request-ID"
+    cors_max_age_seconds: int = 600  # How long browsers may cache preflight responses
+    # JSON list of per-route-group policies overriding the above; see app/cors.py
+    cors_policies: str = ""
 
     @property
     def cors_origins_list(self) -> List[str]:
         """Parse CORS origins from comma-separated string."""
         return [origin.strip() for origin in self.cors_origins.split(",")]
 
+    @property
+    def cors_expose_headers_list(self) -> List[str]:
+        """Parse exposed response headers from comma-separated string."""
+        return [h.strip() for h in self.cors_expose_headers.split(",") if h.strip()]
+
+    @property
+    def whisper_service_urls_list(self) -> List[str]:
+        """Whisper pool URLs, falling back to the single whisper_service_url."""
+        urls = [url.strip() for url in self.whisper_service_urls.split(",") if url.strip()]
+        return urls or [self.whisper_service_url]
+
+    @property
+    def whisper_allowed_content_types_list(self) -> List[str]:
+        """Parse allowed audio content-type prefixes from comma-separated string."""
+        return [t.strip() for t in self.whisper_allowed_content_types.split(",") if t.strip()]
+
+    @property
+    def flagship_podcast_ids_list(self) -> List[str]:
+        """Parse flagship podcast IDs from comma-separated string."""
+        return [pid.strip() for pid in self.flagship_podcast_ids.split(",") if pid.strip()]
+
+    @model_validator(mode="after")
+    def check_combinations(self) -> "Settings":
+        """Fail fast on settings that can't work together."""
+        errors = []
+        if not self.aws_region and not self.aws_endpoint_url:
+            errors.append("S3 needs AWS_REGION (or AWS_ENDPOINT_URL for LocalStack)")
+        if not self.s3_bucket_name:
+            errors.append("S3_BUCKET_NAME is required")
+        if self.email_backend not in ("", "smtp", "ses"):
+            errors.append("EMAIL_BACKEND must be smtp, ses or empty")
+        if self.email_backend == "ses" and not self.aws_region:
+            errors.append("EMAIL_BACKEND=ses needs AWS_REGION")
+        if self.email_backend == "smtp" and not self.smtp_host:
+            errors.append("EMAIL_BACKEND=smtp needs SMTP_HOST")
+        if self.whisper_balance_strategy not in ("least_busy", "round_robin"):
+            errors.append("WHISPER_BALANCE_STRATEGY must be least_busy or round_robin")
+        if self.bulk_job_lease_seconds < 10:
+            errors.append("BULK_JOB_LEASE_SECONDS must be at least 10")
+        if self.bulk_episode_timeout_seconds < 0:
+            errors.append("BULK_EPISODE_TIMEOUT_SECONDS must not be negative")
+        if self.bulk_episode_delay_seconds < 0:
+            errors.append("BULK_EPISODE_DELAY_SECONDS must not be negative")
+        if self.whisper_downloads_per_host < 0:
+            errors.append("WHISPER_DOWNLOADS_PER_HOST must not be negative")
+        if self.whisper_download_max_retry_after_seconds < 0:
+            errors.append("WHISPER_DOWNLOAD_MAX_RETRY_AFTER_SECONDS must not be negative")
+        if self.whisper_download_host_daily_bytes < 0:
+            errors.append("WHISPER_DOWNLOAD_HOST_DAILY_BYTES must not be negative")
+        if self.whisper_expected_speed <= 0:
+            errors.append("WHISPER_EXPECTED_SPEED must be positive")
+        if not 0 <= self.transcript_quality_threshold <= 1:
+            errors.append("TRANSCRIPT_QUALITY_THRESHOLD must be between 0 and 1")
+        if not 0 <= self.brotli_quality <= 11:
+            errors.append("BROTLI_QUALITY must be between 0 and 11")
+        if self.grpc_enabled and self.grpc_port == self.app_port:
+            errors.append("GRPC_PORT must differ from APP_PORT")
+        if self.reconcile_orphan_prefix.startswith("transcripts/") or not self.reconcile_orphan_prefix.strip("/"):
+            errors.append("RECONCILE_ORPHAN_PREFIX must be a prefix outside transcripts/")
+        for name in ("archive_storage_class", "transcript_storage_class"):
+            if getattr(self, name) not in S3_STORAGE_CLASSES:
+                errors.append(f"{name.upper()} must be one of {', '.join(S3_STORAGE_CLASSES)}")
+        if min(
+            self.chunk_audio_expire_days, self.chunk_transcript_ia_days,
+            self.chunk_transcript_glacier_days, self.chunk_transcript_expire_days,
+            self.merged_intermediate_expire_days
+        ) < 0:
+            errors.append("CHUNK_*_DAYS and MERGED_INTERMEDIATE_EXPIRE_DAYS must not be negative")
+        if 0 < self.chunk_transcript_ia_days < 30:
+            errors.append("CHUNK_TRANSCRIPT_IA_DAYS must be 0 or at least 30 (S3's minimum for STANDARD_IA)")
+        if self.chunk_transcript_ia_days and 0 < self.chunk_transcript_glacier_days < self.chunk_transcript_ia_days + 30:
+            errors.append("CHUNK_TRANSCRIPT_GLACIER_DAYS must be at least 30 days after CHUNK_TRANSCRIPT_IA_DAYS")
+        if self.chunk_transcript_expire_days and self.chunk_transcript_expire_days <= max(
+            self.chunk_transcript_ia_days, self.chunk_transcript_glacier_days
+        ):
+            errors.append("CHUNK_TRANSCRIPT_EXPIRE_DAYS must be after the transitions")
+        if self.workspaces_enabled and self.grpc_enabled:
+            errors.append("WORKSPACES_ENABLED does not support GRPC_ENABLED")
+        if self.workspaces_enabled and not self.admin_api_key:
+            errors.append("WORKSPACES_ENABLED needs ADMIN_API_KEY to issue workspace keys")
+        if self.mongodb_read_preference not in (
+            "primary", "primaryPreferred", "secondary", "secondaryPreferred", "nearest"
+        ):
+            errors.append("MONGODB_READ_PREFERENCE is not a valid read preference")
+        if self.mongodb_write_concern and not (
+            self.mongodb_write_concern == "majority" or self.mongodb_write_concern.isdigit()
+        ):
+            errors.append("MONGODB_WRITE_CONCERN must be majority or a number")
+        if self.mongodb_connect_retries < 1:
+            errors.append("MONGODB_CONNECT_RETRIES must be at least 1")
+        if self.transcription_workers < 1:
+            errors.append("TRANSCRIPTION_WORKERS must be at least 1")
+        if self.max_request_body_bytes < 0:
+            errors.append("MAX_REQUEST_BODY_BYTES must not be negative")
+        if self.rss_fetch_timeout_seconds < 1 or self.rss_max_feed_bytes < 1:
+            errors.append("RSS_FETCH_TIMEOUT_SECONDS and RSS_MAX_FEED_BYTES must be positive")
+        if self.rss_cache_ttl_seconds < 0 or self.rss_cache_max_entries < 0:
+            errors.append("RSS_CACHE_TTL_SECONDS and RSS_CACHE_MAX_ENTRIES must not be negative")
+        if min(
+            self.cache_transcript_ttl_seconds, self.cache_episode_list_ttl_seconds,
+            self.cache_job_ttl_seconds, self.cache_directory_ttl_seconds
+        ) < 0:
+            errors.append("CACHE_*_TTL_SECONDS must not be negative")
+        if self.asr_provider not in ("", "assemblyai", "deepgram"):
+            errors.append("ASR_PROVIDER must be assemblyai, deepgram or empty")
+        if self.asr_provider and not getattr(self, f"{self.asr_provider}_api_key", ""):
+            errors.append(f"ASR_PROVIDER={self.asr_provider} needs {self.asr_provider.upper()}_API_KEY")
+        if self.asr_provider == "deepgram" and not self.callback_secret:
+            errors.append("ASR_PROVIDER=deepgram needs CALLBACK_SECRET (Deepgram jobs can't be polled)")
+        if self.episode_search_provider not in ("", "listennotes", "taddy"):
+            errors.append("EPISODE_SEARCH_PROVIDER must be listennotes, taddy or empty")
+        if self.episode_search_provider == "listennotes" and not self.listennotes_api_key:
+            errors.append("EPISODE_SEARCH_PROVIDER=listennotes needs LISTENNOTES_API_KEY")
+        if self.episode_search_provider == "taddy" and not (self.taddy_user_id and self.taddy_api_key):
+            errors.append("EPISODE_SEARCH_PROVIDER=taddy needs TADDY_USER_ID and TADDY_API_KEY")
+        if self.asr_poll_interval_seconds < 1 or self.asr_job_timeout_hours < 1:
+            errors.append("ASR_POLL_INTERVAL_SECONDS and ASR_JOB_TIMEOUT_HOURS must be at least 1")
+        if self.clip_max_seconds < 1 or self.clip_timeout_seconds < 1:
+            errors.append("CLIP_MAX_SECONDS and CLIP_TIMEOUT_SECONDS must be at least 1")
+        if self.transcript_index_poll_seconds < 1:
+            errors.append("TRANSCRIPT_INDEX_POLL_SECONDS must be at least 1")
+        if self.bulk_schedule_poll_seconds < 1:
+            errors.append("BULK_SCHEDULE_POLL_SECONDS must be at least 1")
+        if self.transcribe_min_duration_minutes < 0 or self.transcribe_max_duration_minutes < 0:
+            errors.append("TRANSCRIBE_MIN_DURATION_MINUTES and TRANSCRIBE_MAX_DURATION_MINUTES must not be negative")
+        if self.log_level.upper() not in ("DEBUG", "INFO", "WARNING", "ERROR", "CRITICAL"):
+            errors.append("LOG_LEVEL must be DEBUG, INFO, WARNING, ERROR or CRITICAL")
+        for name in ("chat_webhooks", "cors_policies"):
+            value = getattr(self, name)
+            if value:
+                try:
+                    if not isinstance(json.loads(value), list):
+                        errors.append(f"{name.upper()} must be a JSON list")
+                except json.JSONDecodeError as e:
+                    errors.append(f"{name.upper()} is not valid JSON: {e}")
+        if self.field_encryption_kms_key_id and self.field_encryption_local_key:
+            errors.append("Set only one of FIELD_ENCRYPTION_KMS_KEY_ID and FIELD_ENCRYPTION_LOCAL_KEY")
+        if self.field_encryption_local_key:
+            try:
+                if len(base64.b64decode(self.field_encryption_local_key, validate=True)) != 32:
+                    errors.append("FIELD_ENCRYPTION_LOCAL_KEY must be 32 bytes, base64-encoded")
+            except binascii.Error:
+                errors.append("FIELD_ENCRYPTION_LOCAL_KEY is not valid base64")
+        if self.storage_locations:
+            try:
+                locations = json.loads(self.storage_locations)
+                if not isinstance(locations, dict) or not all(
+                    isinstance(location, dict) and location.get("region") and location.get("bucket")
+                    for location in locations.values()
+                ):
+                    errors.append("STORAGE_LOCATIONS must map names to objects with region and bucket")
+            except json.JSONDecodeError as e:
+                errors.append(f"STORAGE_LOCATIONS is not valid JSON: {e}")
+        if errors:
+            raise ValueError("Invalid configuration: " + "; ".join(errors))
+        return self
+
+    def redacted(self) -> Dict[str, Any]:
+        """Settings with secrets masked, for logs and the config dump endpoint."""
+        values = {}
+        for name, value in self.model_dump().items():
+            if SECRET_SETTING.search(name) and value:
+                value = "***"
+            elif isinstance(value, str):
+                value = URL_CREDENTIALS.sub(r"\1***@", value)
+            values[name] = value
+        return values
+
+    @classmethod
+    def settings_customise_sources(
+        cls,
+        settings_cls: Type[BaseSettings],
+        init_settings: PydanticBaseSettingsSource,
+        env_settings: PydanticBaseSettingsSource,
+        dotenv_settings: PydanticBaseSettingsSource,
+        file_secret_settings: PydanticBaseSettingsSource,
+    ) -> Tuple[PydanticBaseSettingsSource, ...]:
+        # Secret stores override environment variables, which override the config file
+        return (
+            init_settings,
+            SecretsSettingsSource(settings_cls),
+            env_settings,
+            dotenv_settings,
+            ConfigFileSettingsSource(settings_cls),
+            file_secret_settings,
+        )
+
     class Config:
         env_file = ".env"
         case_sensitive = False
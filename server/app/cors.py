@@ -0,0 +1,108 @@
+"""Per-route-group CORS policies.
+
+Public read endpoints (feeds, transcripts) can be opened to any origin
+while admin endpoints stay limited to the dashboard's origins. Policies are
+configured as a JSON list in CORS_POLICIES, each applying to path prefixes:
+
+    [{"name": "public", "paths": ["/feeds", "/api/episodes"],
+      "origins": ["*"], "methods": ["GET", "HEAD"], "credentials": false},
+     {"name": "admin", "paths": ["/api/dev", "/api/podcasts"],
+      "origins": ["https://*.admin.example.com"], "max_age": 600}]
+
+The longest matching prefix wins; other paths use the global CORS_* settings.
+Origins may use a "*." wildcard for subdomains. Each policy is enforced by
+its own Starlette CORSMiddleware.
+"""
+import json
+import logging
+import re
+from typing import Any, Dict, List, Optional, Tuple
+
+from starlette.middleware.cors import CORSMiddleware
+from starlette.types import ASGIApp, Receive, Scope, Send
+
+from app.config import settings
+
+logger = logging.getLogger(__name__)
+
+
+def _split_origins(origins: List[str]) -> Tuple[List[str], Optional[str]]:
+    """
+    Separate exact origins from "*." subdomain wildcards.
+
+    Returns:
+        Exact origins, and a regex matching the wildcard ones (None if none)
+    """
+    exact, patterns = [], []
+    for origin in origins:
+        origin = origin.strip().rstrip("/")
+        if not origin:
+            continue
+        if "*." in origin:
+            scheme, _, host = origin.partition("://")
+            suffix = re.escape(host.replace("*.", "", 1))
+            patterns.append(rf"{re.escape(scheme)}://([a-z0-9-]+\.)+{suffix}")
+        else:
+            exact.append(origin)
+    return exact, "|".join(f"(?:{p})" for p in patterns) or None
+
+
+def _middleware_kwargs(policy: Dict[str, Any]) -> Dict[str, Any]:
+    """CORSMiddleware arguments for a policy, defaulting to the global settings."""
+    origins, origin_regex = _split_origins(policy.get("origins", settings.cors_origins_list))
+    return {
+        "allow_origins": origins,
+        "allow_origin_regex": origin_regex,
+        "allow_methods": policy.get("methods", ["*"]),
+        "allow_headers": policy.get("headers", ["*"]),
+        "allow_credentials": policy.get("credentials", True),
+        "expose_headers": policy.get("expose_headers", settings.cors_expose_headers_list),
+        "max_age": policy.get("max_age", settings.cors_max_age_seconds),
+    }
+
+
+def load_policies() -> List[Dict[str, Any]]:
+    """Parse route-group policies from settings, skipping invalid entries."""
+    if not settings.cors_policies:
+        return []
+
+    try:
+        policies = json.loads(settings.cors_policies)
+    except json.JSONDecodeError as e:
+        logger.error(f"Invalid CORS_POLICIES configuration: {e}")
+        return []
+
+    valid = []
+    for policy in policies:
+        if not isinstance(policy, dict) or not policy.get("paths"):
+            logger.warning(f"Ignoring CORS policy without paths: {policy}")
+            continue
+        valid.append(policy)
+    return valid
+
+
+class RouteCorsMiddleware:
+    """Applies the CORS policy of the route group a request path belongs to."""
+
+    def __init__(self, app: ASGIApp):
+        self.default = CORSMiddleware(app, **_middleware_kwargs({}))
+        self.routes: List[Tuple[str, CORSMiddleware]] = []
+        for policy in load_policies():
+            middleware = CORSMiddleware(app, **_middleware_kwargs(policy))
+            for path in policy["paths"]:
+                self.routes.append((path.rstrip("/"), middleware))
+            logger.info(f"CORS policy '{policy.get('name', 'unnamed')}' applies to {policy['paths']}")
+        # Longest prefix first, so /api/podcasts/import can override /api/podcasts
+        self.routes.sort(key=lambda route: len(route[0]), reverse=True)
+
+    def _policy_for(self, path: str) -> CORSMiddleware:
+        for prefix, middleware in self.routes:
+            if path == prefix or path.startswith(prefix + "/"):
+                return middleware
+        return self.default
+
+    async def __call__(self, scope: Scope, receive: Receive, send: Send):
+        if scope["type"] != "http":
+            await self.default(scope, receive, send)
+            return
+        await self._policy_for(scope["path"])(scope, receive, send)
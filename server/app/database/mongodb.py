@@ -1,11 +1,16 @@
 """MongoDB database connection and utilities."""
+import asyncio
 import logging
 from motor.motor_asyncio import AsyncIOMotorClient, AsyncIOMotorDatabase
 from typing import Optional
 from app.config import settings
+from app.database.query_trace import query_tracer
+from app.services.transcript_indexer import create_search_indexes
 
 logger = logging.getLogger(__name__)
 
+MAX_CONNECT_BACKOFF_SECONDS = 30
+
 
 class MongoDB:
     """MongoDB connection manager."""
@@ -13,23 +18,54 @@ class MongoDB:
     client: Optional[AsyncIOMotorClient] = None
     db: Optional[AsyncIOMotorDatabase] = None
 
+    @staticmethod
+    def _new_client() -> AsyncIOMotorClient:
+        """Create a client for MONGODB_URL with the configured pool and consistency options."""
+        options = {
+            "maxPoolSize": settings.mongodb_max_pool_size,
+            "readPreference": settings.mongodb_read_preference,
+            "serverSelectionTimeoutMS": settings.mongodb_server_selection_timeout_ms,
+        }
+        if settings.mongodb_write_concern:
+            concern = settings.mongodb_write_concern
+            options["w"] = int(concern) if concern.isdigit() else concern
+        if settings.mongodb_query_trace_enabled:
+            options["event_listeners"] = [query_tracer]
+        return AsyncIOMotorClient(settings.mongodb_url, **options)
+
     @classmethod
     async def connect_db(cls):
-        """Connect to MongoDB."""
-        try:
-            logger.info(f"Connecting to MongoDB at {settings.mongodb_url}")
-            cls.client = AsyncIOMotorClient(settings.mongodb_url)
-            cls.db = cls.client[settings.mongodb_db_name]
+        """
+        Connect to MongoDB, retrying with exponential backoff so a database
+        that is still starting (or briefly unreachable) doesn't fail startup.
+        """
+        logger.info(f"Connecting to MongoDB at {settings.redacted()['mongodb_url']}")
+        backoff = settings.mongodb_connect_backoff_seconds
+        for attempt in range(1, settings.mongodb_connect_retries + 1):
+            client = None
+            try:
+                client = cls._new_client()
+                await client.admin.command('ping')
+                break
+            except Exception as e:
+                if client:
+                    client.close()
+                if attempt == settings.mongodb_connect_retries:
+                    logger.error(f"Failed to connect to MongoDB after {attempt} attempts: {e}")
+                    raise
+                logger.warning(
+                    f"MongoDB connection attempt {attempt}/{settings.mongodb_connect_retries} failed, "
+                    f"retrying in {backoff:.1f}s: {e}"
+                )
+                await asyncio.sleep(backoff)
+                backoff = min(backoff * 2, MAX_CONNECT_BACKOFF_SECONDS)
 
-            # Create indexes
-            await cls._create_indexes()
+        cls.client = client
+        cls.db = cls.client[settings.mongodb_db_name]
 
-            # Test connection
-            await cls.client.admin.command('ping')
-            logger.info("Successfully connected to MongoDB")
-        except Exception as e:
-            logger.error(f"Failed to connect to MongoDB: {e}")
-            raise
+        # Create indexes
+        await cls._create_indexes()
+        logger.info("Successfully connected to MongoDB")
 
     @classmethod
     async def _create_indexes(cls):
@@ -45,12 +81,111 @@ class MongoDB:
             await cls.db.episodes.create_index("podcast_id")
             await cls.db.episodes.create_index([("podcast_id", 1), ("published_date", -1)])
             await cls.db.episodes.create_index("transcript_status")
-            await cls.db.episodes.create_index([("published_date", -1)])
+            await cls.db.episodes.create_index([("published_date", -1), ("_id", -1)])
+            await cls.db.episodes.create_index("deleted_at", sparse=True)
+            await cls.db.episodes.create_index("audio_url")
+            await cls.db.episodes.create_index("transcript_quality.score", sparse=True)
+            await cls.db.episodes.create_index("transcript_s3_key", sparse=True)
+
+            # Bulk transcription jobs indexes (cursor pagination sort)
+            await cls.db.bulk_transcribe_jobs.create_index([("created_at", -1), ("_id", -1)])
+            await cls.db.bulk_transcribe_jobs.create_index("next_run_at", sparse=True)
+            await cls.db.bulk_transcribe_jobs.create_index("parent_job_id", sparse=True)
+
+            # Bulk job episode progress indexes
+            await cls.db.job_episodes.create_index([("job_id", 1), ("index", 1)], unique=True)
+            await cls.db.job_episodes.create_index([("job_id", 1), ("status", 1), ("index", 1)])
+
+            # One-off transcription tasks indexes
+            await cls.db.transcription_tasks.create_index("task_id", unique=True)
+
+            # Podcast cleanup jobs indexes
+            await cls.db.podcast_cleanup_jobs.create_index("job_id", unique=True)
+
+            # Quota usage collection indexes
+            await cls.db.quota_usage.create_index([("subject", 1), ("month", 1)], unique=True)
+
+            # Audio download accounting indexes (per host and UTC day)
+            await cls.db.download_usage.create_index([("host", 1), ("day", 1)], unique=True)
+            await cls.db.download_usage.create_index([("day", -1), ("bytes", -1)])
+
+            # Per-user episode playback/read state indexes
+            await cls.db.user_episode_state.create_index([("user_id", 1), ("episode_id", 1)], unique=True)
+            await cls.db.user_episode_state.create_index([("user_id", 1), ("updated_at", -1)])
+            await cls.db.user_episode_state.create_index("podcast_id")
+
+            # Favorites and bookmarked passages indexes
+            await cls.db.favorite_episodes.create_index([("user_id", 1), ("episode_id", 1)], unique=True)
+            await cls.db.favorite_episodes.create_index([("user_id", 1), ("created_at", -1)])
+            await cls.db.favorite_episodes.create_index("podcast_id")
+            await cls.db.transcript_bookmarks.create_index("bookmark_id", unique=True)
+            await cls.db.transcript_bookmarks.create_index([("user_id", 1), ("created_at", -1)])
+            await cls.db.transcript_bookmarks.create_index("podcast_id")
+
+            # Transcript share links indexes
+            await cls.db.share_links.create_index("token", unique=True)
+            await cls.db.share_links.create_index([("episode_id", 1), ("created_at", -1)])
+            await cls.db.share_links.create_index("podcast_id")
+
+            # Workspaces, their API keys and workspace-stamped collections indexes
+            await cls.db.workspaces.create_index("workspace_id", unique=True)
+            await cls.db.workspace_api_keys.create_index("key_hash", unique=True)
+            await cls.db.workspace_api_keys.create_index([("workspace_id", 1), ("created_at", -1)])
+            await cls.db.podcasts.create_index("workspace_id")
+            await cls.db.bulk_transcribe_jobs.create_index("workspace_id")
+
+            # Audit log indexes (newest first, optionally by action or target)
+            await cls.db.audit_log.create_index([("timestamp", -1), ("_id", -1)])
+            await cls.db.audit_log.create_index([("action", 1), ("timestamp", -1)])
+            await cls.db.audit_log.create_index([("target_type", 1), ("target_id", 1), ("timestamp", -1)])
+            await cls.db.audit_log.create_index([("workspace_id", 1), ("timestamp", -1)])
+
+            # MongoDB/S3 reconciliation reports
+            await cls.db.reconciliation_runs.create_index([("started_at", -1)])
+
+            # Submissions to asynchronous ASR providers
+            await cls.db.asr_jobs.create_index("asr_job_id", unique=True)
+            await cls.db.asr_jobs.create_index([("status", 1), ("next_poll_at", 1)])
+
+            # Transcript search index (see app/services/transcript_indexer.py)
+            await create_search_indexes(cls.db)
+            await cls.db.reindex_runs.create_index("run_id", unique=True)
+            await cls.db.reindex_runs.create_index(
+                "status", unique=True, partialFilterExpression={"status": "running"}
+            )
+
+            # Topic analytics keywords (see app/services/topic_analytics.py)
+            await cls.db.episode_keywords.create_index([("podcast_id", 1), ("published_date", 1)])
+            await cls.db.episode_embeddings.create_index("episode_id")
+            await cls.db.episode_embeddings.create_index([("model", 1), ("podcast_id", 1)])
+
+            # Guest appearance index (see app/services/guest_index.py)
+            await cls.db.guests.create_index("name_key", unique=True)
+            await cls.db.guest_appearances.create_index([("name_key", 1), ("published_date", -1)])
+            await cls.db.guest_appearances.create_index("episode_id")
+
+            # Notification preferences collection indexes
+            await cls.db.notification_preferences.create_index("email", unique=True)
+            await cls.db.digests.create_index("digest_id", unique=True)
+            await cls.db.digests.create_index([("email", 1), ("created_at", -1)])
 
             logger.info("Database indexes created successfully")
         except Exception as e:
             logger.warning(f"Error creating indexes: {e}")
 
+    @classmethod
+    async def reconnect(cls):
+        """Switch to a new client for the current MONGODB_URL (e.g. rotated credentials)."""
+        client = cls._new_client()
+        await client.admin.command('ping')
+        previous, cls.client = cls.client, client
+        cls.db = client[settings.mongodb_db_name]
+        logger.info("Reconnected to MongoDB")
+        if previous:
+            # Let in-flight operations on the old client finish
+            await asyncio.sleep(30)
+            previous.close()
+
     @classmethod
     async def close_db(cls):
         """Close MongoDB connection."""
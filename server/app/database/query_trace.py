@@ -0,0 +1,167 @@
+"""MongoDB query tracing for diagnosing slow requests.
+
+With MONGODB_QUERY_TRACE_ENABLED, a command listener on the Mongo client
+times every command. Commands slower than MONGODB_SLOW_QUERY_MS are logged
+with their collection and filter shape (field names and operators, never
+values) and kept in a ring buffer served at GET /debug/queries, together
+with per-collection totals. Each HTTP request also logs how many queries it
+ran and how long they took at DEBUG level.
+"""
+import logging
+import threading
+from collections import deque
+from contextvars import ContextVar
+from datetime import datetime
+from typing import Any, Dict, Optional
+
+from pymongo import monitoring
+
+from app.config import settings
+
+logger = logging.getLogger(__name__)
+
+# Driver housekeeping that would only add noise
+IGNORED_COMMANDS = {
+    "ping", "hello", "ismaster", "isMaster", "buildInfo", "endSessions",
+    "saslStart", "saslContinue", "getnonce", "authenticate",
+}
+
+
+class RequestTrace:
+    """Queries run on behalf of one HTTP request."""
+
+    def __init__(self, label: str):
+        self.label = label
+        self.count = 0
+        self.total_ms = 0.0
+
+
+# Set by the request logging middleware; motor copies context into its executor threads
+current_request: ContextVar[Optional[RequestTrace]] = ContextVar("current_request", default=None)
+
+
+def _shape(value: Any) -> Any:
+    """A filter with its values replaced, keeping field names and operators."""
+    if isinstance(value, dict):
+        return {key: _shape(item) for key, item in value.items()}
+    if isinstance(value, list):
+        return [_shape(value[0])] if value else []
+    return "?"
+
+
+def _command_filter(name: str, command: Dict[str, Any]) -> Optional[Dict[str, Any]]:
+    """The query filter of a command, where it has one."""
+    if name in ("find", "count", "distinct", "findAndModify"):
+        return command.get("filter") or command.get("query")
+    if name == "update" and command.get("updates"):
+        return command["updates"][0].get("q")
+    if name == "delete" and command.get("deletes"):
+        return command["deletes"][0].get("q")
+    if name == "aggregate":
+        for stage in command.get("pipeline", []):
+            if "$match" in stage:
+                return stage["$match"]
+    return None
+
+
+class QueryTracer(monitoring.CommandListener):
+    """Times Mongo commands and remembers the slow ones."""
+
+    def __init__(self, slow_query_ms: int, buffer_size: int):
+        self.slow_query_ms = slow_query_ms
+        self._lock = threading.Lock()
+        self._pending: Dict[Any, Dict[str, Any]] = {}
+        self._slow = deque(maxlen=max(buffer_size, 1))
+        self._collections: Dict[str, Dict[str, float]] = {}
+
+    def started(self, event):
+        if event.command_name in IGNORED_COMMANDS:
+            return
+        command = event.command
+        collection = command.get(event.command_name)
+        if event.command_name == "getMore":
+            collection = command.get("collection")
+        query = _command_filter(event.command_name, command)
+        with self._lock:
+            self._pending[(event.connection_id, event.request_id)] = {
+                "command": event.command_name,
+                "collection": collection if isinstance(collection, str) else None,
+                "filter": _shape(query) if query else None,
+                "request": current_request.get(),
+            }
+
+    def _finish(self, event, error: Optional[str] = None):
+        duration_ms = event.duration_micros / 1000
+        with self._lock:
+            started = self._pending.pop((event.connection_id, event.request_id), None)
+            if not started:
+                return
+            trace = started.pop("request")
+            if trace:
+                trace.count += 1
+                trace.total_ms += duration_ms
+
+            stats = self._collections.setdefault(
+                started["collection"] or "(none)", {"count": 0, "total_ms": 0.0, "max_ms": 0.0, "slow": 0}
+            )
+            stats["count"] += 1
+            stats["total_ms"] += duration_ms
+            stats["max_ms"] = max(stats["max_ms"], duration_ms)
+            if duration_ms < self.slow_query_ms:
+                return
+            stats["slow"] += 1
+            entry = {
+                **started,
+                "duration_ms": round(duration_ms, 1),
+                "request": trace.label if trace else None,
+                "error": error,
+                "at": datetime.utcnow(),
+            }
+            self._slow.append(entry)
+
+        logger.warning(
+            f"Slow query ({entry['duration_ms']}ms): {entry['command']} {entry['collection']} "
+            f"filter={entry['filter']} request={entry['request']}"
+        )
+
+    def succeeded(self, event):
+        self._finish(event)
+
+    def failed(self, event):
+        self._finish(event, str(event.failure))
+
+    def report(self) -> Dict[str, Any]:
+        """Recent slow queries (newest first) and per-collection totals."""
+        with self._lock:
+            collections = {
+                name: {**stats, "total_ms": round(stats["total_ms"], 1), "max_ms": round(stats["max_ms"], 1)}
+                for name, stats in sorted(self._collections.items(), key=lambda item: -item[1]["total_ms"])
+            }
+            return {
+                "slow_query_ms": self.slow_query_ms,
+                "slow_queries": list(reversed(self._slow)),
+                "collections": collections,
+            }
+
+    def reset(self):
+        """Forget recorded queries and totals."""
+        with self._lock:
+            self._slow.clear()
+            self._collections.clear()
+
+
+def start_request_trace(label: str) -> RequestTrace:
+    """Attribute queries run from the current context to a request."""
+    trace = RequestTrace(label)
+    current_request.set(trace)
+    return trace
+
+
+def log_request_trace(trace: RequestTrace):
+    """Log a request's query count and time."""
+    if trace.count:
+        logger.debug(f"{trace.label}: {trace.count} queries in {trace.total_ms:.1f}ms")
+
+
+# Singleton instance, attached to the client when tracing is enabled
+query_tracer = QueryTracer(settings.mongodb_slow_query_ms, settings.mongodb_query_trace_buffer)
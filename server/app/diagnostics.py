@@ -0,0 +1,73 @@
+"""Process state dump for profiling a running server.
+
+Served at GET /admin/debug/state. Memory allocation sites are included
+when the process was started with PYTHONTRACEMALLOC=1 (or tracing was
+started with GET /admin/debug/state?start_tracemalloc=true); tracing slows
+allocation, so leave it off unless diagnosing memory growth. The Go
+servers have pprof and expvar on DEBUG_ADDR instead (debug.go).
+"""
+import asyncio
+import gc
+import resource
+import sys
+import tracemalloc
+from collections import Counter
+from typing import Any, Dict
+
+from app.services.bulk_transcribe_service import BulkTranscribeService
+from app.services.temp_storage import temp_storage
+from app.services.whisper_service import whisper_service
+from app.services.work_queue import transcription_slots
+
+TOP_ALLOCATIONS = 25
+
+
+def _task_summary() -> Dict[str, Any]:
+    """Running asyncio tasks, counted by the coroutine they run."""
+    tasks = [task for task in asyncio.all_tasks() if not task.done()]
+    by_coroutine = Counter(getattr(task.get_coro(), "__qualname__", "unknown") for task in tasks)
+    return {"count": len(tasks), "by_coroutine": dict(by_coroutine.most_common())}
+
+
+def _memory() -> Dict[str, Any]:
+    """Peak RSS, GC state and (if tracing) the largest allocation sites."""
+    max_rss = resource.getrusage(resource.RUSAGE_SELF).ru_maxrss
+    memory = {
+        # ru_maxrss is bytes on macOS, kilobytes elsewhere
+        "max_rss_bytes": max_rss if sys.platform == "darwin" else max_rss * 1024,
+        "gc_counts": gc.get_count(),
+        "gc_objects": len(gc.get_objects()),
+        "tracemalloc": tracemalloc.is_tracing(),
+    }
+    if tracemalloc.is_tracing():
+        current, peak = tracemalloc.get_traced_memory()
+        snapshot = tracemalloc.take_snapshot()
+        memory["traced_bytes"] = current
+        memory["traced_peak_bytes"] = peak
+        memory["top_allocations"] = [
+            {"location": str(stat.traceback), "size_bytes": stat.size, "count": stat.count}
+            for stat in snapshot.statistics("lineno")[:TOP_ALLOCATIONS]
+        ]
+    return memory
+
+
+def debug_state(start_tracemalloc: bool = False) -> Dict[str, Any]:
+    """
+    Snapshot of tasks, running jobs, worker slots and memory.
+
+    Args:
+        start_tracemalloc: Start allocation tracing if it isn't running
+
+    Returns:
+        Process state
+    """
+    if start_tracemalloc and not tracemalloc.is_tracing():
+        tracemalloc.start()
+    return {
+        "tasks": _task_summary(),
+        "bulk_jobs_running": sorted(BulkTranscribeService.running_jobs),
+        "transcription_slots": transcription_slots.status(),
+        "whisper": whisper_service.status(),
+        "temp_storage": temp_storage.status(),
+        "memory": _memory(),
+    }
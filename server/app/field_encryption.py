@@ -0,0 +1,200 @@
+"""Envelope encryption of sensitive fields stored in MongoDB.
+
+Secrets the API keeps in documents (private feed credentials, webhook
+secrets, third-party API tokens) are encrypted before they are written.
+With FIELD_ENCRYPTION_KMS_KEY_ID each value gets its own data key from KMS
+GenerateDataKey and is sealed with AES-256-GCM; the document keeps the
+ciphertext and the KMS-encrypted data key, so reading it needs kms:Decrypt
+on the key and nothing but ciphertext ever reaches MongoDB. Without KMS (in
+development) FIELD_ENCRYPTION_LOCAL_KEY, a base64 256-bit key, wraps the
+data keys instead.
+
+An encrypted value is stored as a subdocument:
+
+    {"enc": "v1", "kid": "<key id or 'local'>", "edk": "<b64>", "iv": "<b64>", "ct": "<b64>"}
+
+Values written before encryption was configured are plain strings and are
+read as they are, so encryption can be turned on without a migration. With
+neither key set, values are stored in plaintext. Encrypted fields can't be
+queried by value; look documents up by another field.
+"""
+import base64
+import logging
+import os
+import threading
+from collections import OrderedDict
+from typing import Any, Dict, Iterable, Optional
+
+import boto3
+from cryptography.hazmat.primitives.ciphers.aead import AESGCM
+
+from app.config import settings
+
+logger = logging.getLogger(__name__)
+
+ENVELOPE_VERSION = "v1"
+LOCAL_KEY_ID = "local"
+# AES-GCM nonce size in bytes
+NONCE_BYTES = 12
+# Decrypted data keys kept in memory, so reading a document doesn't call KMS each time
+DATA_KEY_CACHE_SIZE = 256
+
+
+class FieldEncryptionError(Exception):
+    """Raised when a value can't be encrypted or decrypted."""
+
+
+def _b64(data: bytes) -> str:
+    return base64.b64encode(data).decode("ascii")
+
+
+def local_key() -> Optional[bytes]:
+    """FIELD_ENCRYPTION_LOCAL_KEY decoded, None if unset (validated at startup)."""
+    if not settings.field_encryption_local_key:
+        return None
+    return base64.b64decode(settings.field_encryption_local_key)
+
+
+def encryption_enabled() -> bool:
+    """Whether new values are encrypted."""
+    return bool(settings.field_encryption_kms_key_id or settings.field_encryption_local_key)
+
+
+def is_encrypted(value: Any) -> bool:
+    """Whether a stored value is an encrypted envelope."""
+    return isinstance(value, dict) and value.get("enc") == ENVELOPE_VERSION
+
+
+class FieldEncryptor:
+    """Seals and opens field values with KMS (or local) data keys."""
+
+    def __init__(self):
+        self._kms = None
+        self._data_keys: "OrderedDict[str, bytes]" = OrderedDict()
+        self._lock = threading.Lock()
+
+    @property
+    def kms(self):
+        """Lazy initialization of the KMS client."""
+        if self._kms is None:
+            client_kwargs = {"region_name": settings.aws_region}
+            if settings.aws_endpoint_url:
+                client_kwargs["endpoint_url"] = settings.aws_endpoint_url
+            if settings.aws_access_key_id and settings.aws_secret_access_key:
+                client_kwargs["aws_access_key_id"] = settings.aws_access_key_id
+                client_kwargs["aws_secret_access_key"] = settings.aws_secret_access_key
+            self._kms = boto3.client("kms", **client_kwargs)
+        return self._kms
+
+    def reset_client(self):
+        """Drop the client so the next call uses the current credentials."""
+        self._kms = None
+
+    def _new_data_key(self) -> Dict[str, Any]:
+        """A fresh data key, plaintext and wrapped."""
+        if settings.field_encryption_kms_key_id:
+            response = self.kms.generate_data_key(KeyId=settings.field_encryption_kms_key_id, KeySpec="AES_256")
+            return {"kid": response["KeyId"], "key": response["Plaintext"], "edk": response["CiphertextBlob"]}
+        key = AESGCM.generate_key(bit_length=256)
+        nonce = os.urandom(NONCE_BYTES)
+        wrapped = nonce + AESGCM(local_key()).encrypt(nonce, key, LOCAL_KEY_ID.encode())
+        return {"kid": LOCAL_KEY_ID, "key": key, "edk": wrapped}
+
+    def _data_key(self, envelope: Dict[str, Any]) -> bytes:
+        """The plaintext data key of an envelope."""
+        with self._lock:
+            cached = self._data_keys.get(envelope["edk"])
+            if cached:
+                self._data_keys.move_to_end(envelope["edk"])
+                return cached
+
+        wrapped = base64.b64decode(envelope["edk"])
+        if envelope["kid"] == LOCAL_KEY_ID:
+            key = local_key()
+            if not key:
+                raise FieldEncryptionError("Value was encrypted with FIELD_ENCRYPTION_LOCAL_KEY, which isn't set")
+            data_key = AESGCM(key).decrypt(wrapped[:NONCE_BYTES], wrapped[NONCE_BYTES:], LOCAL_KEY_ID.encode())
+        else:
+            data_key = self.kms.decrypt(CiphertextBlob=wrapped, KeyId=envelope["kid"])["Plaintext"]
+
+        with self._lock:
+            self._data_keys[envelope["edk"]] = data_key
+            if len(self._data_keys) > DATA_KEY_CACHE_SIZE:
+                self._data_keys.popitem(last=False)
+        return data_key
+
+    def encrypt(self, plaintext: Optional[str], context: str = "") -> Any:
+        """
+        Encrypt a value for storage.
+
+        Args:
+            plaintext: The value; None is stored as None
+            context: Bound to the ciphertext (e.g. the field name), so a
+                value can't be copied into another field
+
+        Returns:
+            The envelope, or the plaintext when encryption is off
+        """
+        if plaintext is None or not encryption_enabled():
+            return plaintext
+        try:
+            data_key = self._new_data_key()
+            nonce = os.urandom(NONCE_BYTES)
+            ciphertext = AESGCM(data_key["key"]).encrypt(nonce, plaintext.encode("utf-8"), context.encode())
+        except Exception as e:
+            raise FieldEncryptionError(f"Failed to encrypt {context or 'value'}: {e}") from e
+        return {
+            "enc": ENVELOPE_VERSION,
+            "kid": data_key["kid"],
+            "edk": _b64(data_key["edk"]),
+            "iv": _b64(nonce),
+            "ct": _b64(ciphertext),
+        }
+
+    def decrypt(self, value: Any, context: str = "") -> Optional[str]:
+        """
+        Read a stored value: envelopes are decrypted, plaintext passes through.
+
+        Raises:
+            FieldEncryptionError: If an envelope can't be decrypted
+        """
+        if not is_encrypted(value):
+            return value
+        try:
+            data_key = self._data_key(value)
+            plaintext = AESGCM(data_key).decrypt(
+                base64.b64decode(value["iv"]), base64.b64decode(value["ct"]), context.encode()
+            )
+        except FieldEncryptionError:
+            raise
+        except Exception as e:
+            raise FieldEncryptionError(f"Failed to decrypt {context or 'value'}: {e}") from e
+        return plaintext.decode("utf-8")
+
+    def encrypt_fields(self, document: Dict[str, Any], fields: Iterable[str]) -> Dict[str, Any]:
+        """A copy of a document with the named top-level fields encrypted."""
+        encrypted = dict(document)
+        for field in fields:
+            if field in encrypted and not is_encrypted(encrypted[field]):
+                encrypted[field] = self.encrypt(encrypted[field], field)
+        return encrypted
+
+    def decrypt_fields(self, document: Dict[str, Any], fields: Iterable[str]) -> Dict[str, Any]:
+        """A copy of a document with the named top-level fields decrypted."""
+        decrypted = dict(document)
+        for field in fields:
+            if field in decrypted:
+                decrypted[field] = self.decrypt(decrypted[field], field)
+        return decrypted
+
+    def check(self) -> bool:
+        """Round-trip a value, so a missing key or KMS permission shows at startup."""
+        try:
+            return self.decrypt(self.encrypt("check", "check"), "check") == "check"
+        except FieldEncryptionError as e:
+            logger.error(f"Field encryption is misconfigured: {e}")
+            return False
+
+
+# Global instance
+field_encryptor = FieldEncryptor()
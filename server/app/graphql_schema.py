@@ -0,0 +1,242 @@
+"""GraphQL schema exposing podcasts with nested episodes and job progress.
+
+Lets a dashboard fetch subscriptions, their recent episodes with transcript
+status, and bulk job progress in a single query instead of several REST calls.
+"""
+import logging
+from datetime import datetime
+from typing import List, Optional
+
+import strawberry
+from strawberry.fastapi import GraphQLRouter
+
+from app.database import MongoDB
+
+logger = logging.getLogger(__name__)
+
+# Constants
+DEFAULT_EPISODE_LIMIT = 20
+MAX_EPISODE_LIMIT = 100
+
+
+@strawberry.type
+class Episode:
+    """Episode with transcript status."""
+    episode_id: str
+    podcast_id: str
+    title: str
+    description: Optional[str]
+    audio_url: Optional[str]
+    published_date: Optional[datetime]
+    duration_minutes: Optional[int]
+    transcript_status: str
+    processing_step: Optional[str]
+    transcript_s3_key: Optional[str]
+    processed_at: Optional[datetime]
+
+    @classmethod
+    def from_doc(cls, doc: dict) -> "Episode":
+        return cls(
+            episode_id=doc["episode_id"],
+            podcast_id=doc.get("podcast_id", ""),
+            title=doc.get("title", ""),
+            description=doc.get("description"),
+            audio_url=doc.get("audio_url"),
+            published_date=doc.get("published_date"),
+            duration_minutes=doc.get("duration_minutes"),
+            transcript_status=doc.get("transcript_status", "pending"),
+            processing_step=doc.get("processing_step"),
+            transcript_s3_key=doc.get("transcript_s3_key"),
+            processed_at=doc.get("processed_at"),
+        )
+
+
+@strawberry.type
+class TranscriptStatusCounts:
+    """Number of episodes in each transcript status."""
+    pending: int = 0
+    processing: int = 0
+    completed: int = 0
+    failed: int = 0
+
+
+@strawberry.type
+class Podcast:
+    """Subscribed podcast with nested episodes."""
+    podcast_id: str
+    rss_url: str
+    title: str
+    description: Optional[str]
+    image_url: Optional[str]
+    author: Optional[str]
+    subscribed_at: datetime
+    active: bool
+    episode_count: Optional[int]
+
+    @strawberry.field
+    async def episodes(
+        self,
+        status: Optional[str] = None,
+        limit: int = DEFAULT_EPISODE_LIMIT,
+    ) -> List[Episode]:
+        """Most recent episodes, optionally filtered by transcript status."""
+        query = {"podcast_id": self.podcast_id, "deleted_at": None}
+        if status:
+            query["transcript_status"] = status
+        limit = min(max(limit, 1), MAX_EPISODE_LIMIT)
+
+        cursor = MongoDB.get_db().episodes.find(query).sort("published_date", -1).limit(limit)
+        return [Episode.from_doc(doc) for doc in await cursor.to_list(length=limit)]
+
+    @strawberry.field
+    async def transcript_status_counts(self) -> TranscriptStatusCounts:
+        """Episode counts per transcript status."""
+        pipeline = [
+            {"$match": {"podcast_id": self.podcast_id, "deleted_at": None}},
+            {"$group": {"_id": "$transcript_status", "count": {"$sum": 1}}},
+        ]
+        counts = TranscriptStatusCounts()
+        async for row in MongoDB.get_db().episodes.aggregate(pipeline):
+            if row["_id"] in ("pending", "processing", "completed", "failed"):
+                setattr(counts, row["_id"], row["count"])
+        return counts
+
+    @classmethod
+    def from_doc(cls, doc: dict) -> "Podcast":
+        return cls(
+            podcast_id=doc["podcast_id"],
+            rss_url=doc.get("rss_url", ""),
+            title=doc.get("title", ""),
+            description=doc.get("description"),
+            image_url=doc.get("image_url"),
+            author=doc.get("author"),
+            subscribed_at=doc["subscribed_at"],
+            active=doc.get("active", True),
+            episode_count=doc.get("episode_count"),
+        )
+
+
+@strawberry.type
+class BulkJobEpisode:
+    """Progress of a single episode in a bulk job."""
+    index: int
+    episode_id: Optional[str]
+    title: str
+    status: str
+    error_message: Optional[str]
+    started_at: Optional[datetime]
+    completed_at: Optional[datetime]
+
+
+@strawberry.type
+class BulkJob:
+    """Bulk transcription job progress."""
+    job_id: str
+    rss_url: str
+    podcast_title: Optional[str]
+    status: str
+    total_episodes: int
+    processed_episodes: int
+    successful_episodes: int
+    failed_episodes: int
+    skipped_episodes: int
+    created_at: datetime
+    updated_at: datetime
+    completed_at: Optional[datetime]
+    current_episode: Optional[str]
+    progress_percent: float
+    average_episode_seconds: Optional[float]
+    estimated_completion_at: Optional[datetime]
+
+    @strawberry.field
+    async def episodes(
+        self,
+        status: Optional[str] = None,
+        limit: int = DEFAULT_EPISODE_LIMIT,
+        after: Optional[int] = None,
+    ) -> List[BulkJobEpisode]:
+        """Episode progress in processing order, optionally after an index."""
+        query = {"job_id": self.job_id}
+        if status:
+            query["status"] = status
+        if after is not None:
+            query["index"] = {"$gt": after}
+        limit = min(max(limit, 1), MAX_EPISODE_LIMIT)
+
+        cursor = MongoDB.get_db().job_episodes.find(query).sort("index", 1).limit(limit)
+        return [
+            BulkJobEpisode(
+                index=ep["index"],
+                episode_id=ep.get("episode_id"),
+                title=ep.get("title", ""),
+                status=ep.get("status", ""),
+                error_message=ep.get("error_message"),
+                started_at=ep.get("started_at"),
+                completed_at=ep.get("completed_at"),
+            )
+            for ep in await cursor.to_list(length=limit)
+        ]
+
+    @classmethod
+    def from_doc(cls, doc: dict) -> "BulkJob":
+        return cls(
+            job_id=doc["job_id"],
+            rss_url=doc.get("rss_url", ""),
+            podcast_title=doc.get("podcast_title"),
+            status=doc.get("status", ""),
+            total_episodes=doc.get("total_episodes", 0),
+            processed_episodes=doc.get("processed_episodes", 0),
+            successful_episodes=doc.get("successful_episodes", 0),
+            failed_episodes=doc.get("failed_episodes", 0),
+            skipped_episodes=doc.get("skipped_episodes", 0),
+            created_at=doc["created_at"],
+            updated_at=doc["updated_at"],
+            completed_at=doc.get("completed_at"),
+            current_episode=doc.get("current_episode"),
+            progress_percent=doc.get("progress_percent", 0),
+            average_episode_seconds=doc.get("average_episode_seconds"),
+            estimated_completion_at=doc.get("estimated_completion_at"),
+        )
+
+
+@strawberry.type
+class Query:
+    """Root query type."""
+
+    @strawberry.field
+    async def podcasts(self, active_only: bool = True) -> List[Podcast]:
+        """Subscribed podcasts, newest subscription first."""
+        query = {"active": True, "deleted_at": None} if active_only else {"deleted_at": None}
+        docs = await MongoDB.get_db().podcasts.find(query).sort("subscribed_at", -1).to_list(length=None)
+        return [Podcast.from_doc(doc) for doc in docs]
+
+    @strawberry.field
+    async def podcast(self, podcast_id: str) -> Optional[Podcast]:
+        """A single podcast by ID."""
+        doc = await MongoDB.get_db().podcasts.find_one({"podcast_id": podcast_id, "deleted_at": None})
+        return Podcast.from_doc(doc) if doc else None
+
+    @strawberry.field
+    async def episode(self, episode_id: str) -> Optional[Episode]:
+        """A single episode by ID."""
+        doc = await MongoDB.get_db().episodes.find_one({"episode_id": episode_id, "deleted_at": None})
+        return Episode.from_doc(doc) if doc else None
+
+    @strawberry.field
+    async def bulk_jobs(self, status: Optional[str] = None, limit: int = 20) -> List[BulkJob]:
+        """Recent bulk transcription jobs."""
+        query = {"status": status} if status else {}
+        limit = min(max(limit, 1), 100)
+        cursor = MongoDB.get_db().bulk_transcribe_jobs.find(query).sort("created_at", -1).limit(limit)
+        return [BulkJob.from_doc(doc) for doc in await cursor.to_list(length=limit)]
+
+    @strawberry.field
+    async def bulk_job(self, job_id: str) -> Optional[BulkJob]:
+        """A single bulk transcription job by ID."""
+        doc = await MongoDB.get_db().bulk_transcribe_jobs.find_one({"job_id": job_id})
+        return BulkJob.from_doc(doc) if doc else None
+
+
+schema = strawberry.Schema(query=Query)
+
+graphql_router = GraphQLRouter(schema, tags=["graphql"])
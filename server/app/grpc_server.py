@@ -0,0 +1,213 @@
+"""gRPC API served alongside the REST API.
+
+The servicer reads the same MongoDB collections as the REST routes. Stubs are
+generated from protos/podcasts/v1/podcasts.proto at image build time:
+
+    python -m grpc_tools.protoc -I protos --python_out=. --grpc_python_out=. \\
+        protos/podcasts/v1/podcasts.proto
+"""
+import logging
+from datetime import datetime
+from typing import Optional
+
+import grpc
+from google.protobuf.timestamp_pb2 import Timestamp
+
+from app.config import settings
+from app.database import MongoDB
+from app.services import s3_service
+from app.services.private_transcripts import is_private
+from app.services.storage_locations import transcript_location
+
+logger = logging.getLogger(__name__)
+
+# Constants
+DEFAULT_PAGE_LIMIT = 20
+MAX_PAGE_LIMIT = 100
+
+
+def _timestamp(value: Optional[datetime]) -> Optional[Timestamp]:
+    """Convert a naive UTC datetime to a protobuf Timestamp."""
+    if value is None:
+        return None
+    ts = Timestamp()
+    ts.FromDatetime(value)
+    return ts
+
+
+def _build_servicer(pb2, pb2_grpc):
+    """Build the servicer class against the generated modules."""
+
+    def to_podcast(doc: dict):
+        return pb2.Podcast(
+            podcast_id=doc["podcast_id"],
+            rss_url=doc.get("rss_url", ""),
+            title=doc.get("title", ""),
+            description=doc.get("description") or "",
+            image_url=doc.get("image_url") or "",
+            author=doc.get("author") or "",
+            subscribed_at=_timestamp(doc.get("subscribed_at")),
+            active=doc.get("active", True),
+            episode_count=doc.get("episode_count") or 0,
+        )
+
+    def to_episode(doc: dict):
+        return pb2.Episode(
+            episode_id=doc["episode_id"],
+            podcast_id=doc.get("podcast_id", ""),
+            title=doc.get("title", ""),
+            description=doc.get("description") or "",
+            audio_url=doc.get("audio_url") or "",
+            published_date=_timestamp(doc.get("published_date")),
+            duration_minutes=doc.get("duration_minutes") or 0,
+            transcript_status=doc.get("transcript_status", "pending"),
+            processing_step=doc.get("processing_step") or "",
+            transcript_s3_key=doc.get("transcript_s3_key") or "",
+            processed_at=_timestamp(doc.get("processed_at")),
+        )
+
+    def to_bulk_job(doc: dict, job_episodes: Optional[list] = None):
+        episodes = [
+            pb2.BulkJobEpisode(
+                episode_id=ep.get("episode_id") or "",
+                title=ep.get("title", ""),
+                status=ep.get("status", ""),
+                error_message=ep.get("error_message") or "",
+            )
+            for ep in job_episodes or []
+        ]
+        return pb2.BulkJob(
+            job_id=doc["job_id"],
+            rss_url=doc.get("rss_url", ""),
+            status=doc.get("status", ""),
+            total_episodes=doc.get("total_episodes", 0),
+            processed_episodes=doc.get("processed_episodes", 0),
+            successful_episodes=doc.get("successful_episodes", 0),
+            failed_episodes=doc.get("failed_episodes", 0),
+            created_at=_timestamp(doc.get("created_at")),
+            updated_at=_timestamp(doc.get("updated_at")),
+            completed_at=_timestamp(doc.get("completed_at")),
+            current_episode=doc.get("current_episode") or "",
+            episodes=episodes,
+            progress_percent=doc.get("progress_percent") or 0,
+            average_episode_seconds=doc.get("average_episode_seconds") or 0,
+            estimated_completion_at=_timestamp(doc.get("estimated_completion_at")),
+            skipped_episodes=doc.get("skipped_episodes", 0),
+        )
+
+    class PodcastServicer(pb2_grpc.PodcastServiceServicer):
+        """Read API over podcasts, episodes, transcripts and bulk jobs."""
+
+        async def ListPodcasts(self, request, context):
+            db = MongoDB.get_db()
+            query = {"deleted_at": None} if request.include_inactive else {"active": True, "deleted_at": None}
+            docs = await db.podcasts.find(query).sort("subscribed_at", -1).to_list(length=None)
+            return pb2.ListPodcastsResponse(podcasts=[to_podcast(d) for d in docs])
+
+        async def GetPodcast(self, request, context):
+            doc = await MongoDB.get_db().podcasts.find_one({"podcast_id": request.podcast_id, "deleted_at": None})
+            if not doc:
+                await context.abort(grpc.StatusCode.NOT_FOUND, f"Podcast '{request.podcast_id}' not found")
+            return to_podcast(doc)
+
+        async def ListEpisodes(self, request, context):
+            db = MongoDB.get_db()
+            query = {"deleted_at": None}
+            if request.podcast_id:
+                query["podcast_id"] = request.podcast_id
+            if request.transcript_status:
+                query["transcript_status"] = request.transcript_status
+
+            page = max(request.page, 1)
+            limit = min(request.limit or DEFAULT_PAGE_LIMIT, MAX_PAGE_LIMIT)
+            skip = (page - 1) * limit
+
+            total = await db.episodes.count_documents(query)
+            docs = await db.episodes.find(query).sort("published_date", -1).skip(skip).limit(limit).to_list(length=limit)
+            return pb2.ListEpisodesResponse(
+                episodes=[to_episode(d) for d in docs],
+                total=total,
+                has_more=(skip + len(docs)) < total,
+            )
+
+        async def GetEpisode(self, request, context):
+            doc = await MongoDB.get_db().episodes.find_one({"episode_id": request.episode_id, "deleted_at": None})
+            if not doc:
+                await context.abort(grpc.StatusCode.NOT_FOUND, f"Episode '{request.episode_id}' not found")
+            return to_episode(doc)
+
+        async def GetTranscript(self, request, context):
+            doc = await MongoDB.get_db().episodes.find_one({"episode_id": request.episode_id, "deleted_at": None})
+            if not doc:
+                await context.abort(grpc.StatusCode.NOT_FOUND, f"Episode '{request.episode_id}' not found")
+
+            transcript_status = doc.get("transcript_status", "pending")
+            if transcript_status != "completed":
+                await context.abort(
+                    grpc.StatusCode.FAILED_PRECONDITION,
+                    f"Transcript not available (status: {transcript_status})"
+                )
+            if await is_private(MongoDB.get_db(), doc.get("podcast_id")):
+                await context.abort(
+                    grpc.StatusCode.PERMISSION_DENIED, "Private podcasts' transcripts are served only by the REST API"
+                )
+
+            text = None
+            if doc.get("transcript_s3_key"):
+                try:
+                    text = await s3_service.get_transcript(
+                        doc["transcript_s3_key"], **transcript_location(doc.get("storage"))
+                    )
+                except Exception as e:
+                    logger.error(f"Failed to fetch transcript from S3: {e}")
+            text = text or doc.get("transcript_text")
+            if not text:
+                await context.abort(grpc.StatusCode.NOT_FOUND, "Transcript not found in storage")
+
+            return pb2.Transcript(
+                episode_id=request.episode_id,
+                text=text,
+                status=transcript_status,
+                generated_at=_timestamp(doc.get("processed_at")),
+            )
+
+        async def ListBulkJobs(self, request, context):
+            limit = request.limit or 50
+            docs = await MongoDB.get_db().bulk_transcribe_jobs.find().sort("created_at", -1).limit(limit).to_list(length=limit)
+            return pb2.ListBulkJobsResponse(jobs=[to_bulk_job(d) for d in docs])
+
+        async def GetBulkJob(self, request, context):
+            doc = await MongoDB.get_db().bulk_transcribe_jobs.find_one({"job_id": request.job_id})
+            if not doc:
+                await context.abort(grpc.StatusCode.NOT_FOUND, f"Job '{request.job_id}' not found")
+            job_episodes = None
+            if request.include_episodes:
+                job_episodes = await MongoDB.get_db().job_episodes.find(
+                    {"job_id": request.job_id}
+                ).sort("index", 1).to_list(length=None)
+            return to_bulk_job(doc, job_episodes)
+
+    return PodcastServicer
+
+
+async def start_grpc_server() -> Optional[grpc.aio.Server]:
+    """
+    Start the gRPC server on the configured port.
+
+    Returns:
+        Running server, or None if the generated stubs are unavailable
+    """
+    try:
+        from podcasts.v1 import podcasts_pb2, podcasts_pb2_grpc
+    except ImportError:
+        logger.error("gRPC stubs not generated; run grpc_tools.protoc on protos/ (see server/Dockerfile)")
+        return None
+
+    server = grpc.aio.server()
+    servicer = _build_servicer(podcasts_pb2, podcasts_pb2_grpc)
+    podcasts_pb2_grpc.add_PodcastServiceServicer_to_server(servicer(), server)
+    server.add_insecure_port(f"{settings.app_host}:{settings.grpc_port}")
+    await server.start()
+
+    logger.info(f"gRPC server listening on port {settings.grpc_port}")
+    return server
@@ -0,0 +1,100 @@
+"""Conditional GET support (ETag / Last-Modified).
+
+Polling clients and CDNs send If-None-Match / If-Modified-Since; when the
+resource hasn't changed since, handlers answer 304 without re-reading S3 or
+re-serialising large job documents.
+"""
+import hashlib
+from datetime import datetime, timezone
+from email.utils import format_datetime, parsedate_to_datetime
+from typing import Any, Optional
+
+from fastapi import Request, Response, status
+
+
+def compute_etag(resource_id: str, updated_at: Optional[datetime], *extra: Any) -> str:
+    """
+    Build a weak ETag for a resource.
+
+    Args:
+        resource_id: Episode or job ID
+        updated_at: Last modification time of the resource
+        *extra: Additional values that change the representation (e.g. status)
+
+    Returns:
+        Quoted weak ETag value
+    """
+    parts = [resource_id, updated_at.isoformat() if updated_at else ""]
+    parts.extend("" if value is None else str(value) for value in extra)
+    digest = hashlib.sha1("|".join(parts).encode("utf-8")).hexdigest()
+    return f'W/"{digest}"'
+
+
+def _as_utc(value: datetime) -> datetime:
+    """Treat naive datetimes (as stored in MongoDB) as UTC."""
+    if value.tzinfo is None:
+        return value.replace(tzinfo=timezone.utc)
+    return value.astimezone(timezone.utc)
+
+
+def _etag_matches(header: str, etag: str) -> bool:
+    """Weak comparison of an If-None-Match header against an ETag."""
+    if header.strip() == "*":
+        return True
+    opaque = etag.removeprefix("W/")
+    return any(candidate.strip().removeprefix("W/") == opaque for candidate in header.split(","))
+
+
+def is_not_modified(request: Request, etag: str, last_modified: Optional[datetime]) -> bool:
+    """
+    Check the request's conditional headers against the current validators.
+
+    If-None-Match takes precedence over If-Modified-Since (RFC 9110 13.2.2).
+    """
+    if_none_match = request.headers.get("if-none-match")
+    if if_none_match is not None:
+        return _etag_matches(if_none_match, etag)
+
+    if_modified_since = request.headers.get("if-modified-since")
+    if if_modified_since and last_modified:
+        try:
+            since = parsedate_to_datetime(if_modified_since)
+        except (TypeError, ValueError):
+            return False
+        # HTTP dates have one-second resolution
+        return _as_utc(last_modified).replace(microsecond=0) <= _as_utc(since)
+
+    return False
+
+
+def cache_headers(etag: str, last_modified: Optional[datetime]) -> dict:
+    """Validator headers to attach to 200 and 304 responses."""
+    headers = {"ETag": etag, "Cache-Control": "no-cache"}
+    if last_modified:
+        headers["Last-Modified"] = format_datetime(_as_utc(last_modified), usegmt=True)
+    return headers
+
+
+def conditional_response(
+    request: Request,
+    response: Response,
+    etag: str,
+    last_modified: Optional[datetime],
+) -> Optional[Response]:
+    """
+    Apply validators to a response, or short-circuit with 304.
+
+    Args:
+        request: Incoming request
+        response: Response injected by FastAPI (headers are set on it)
+        etag: Current ETag of the resource
+        last_modified: Current modification time of the resource
+
+    Returns:
+        A 304 response if the client's copy is current, otherwise None
+    """
+    headers = cache_headers(etag, last_modified)
+    if is_not_modified(request, etag, last_modified):
+        return Response(status_code=status.HTTP_304_NOT_MODIFIED, headers=headers)
+    response.headers.update(headers)
+    return None
@@ -1,14 +1,69 @@
 """Main FastAPI application."""
+import asyncio
 import logging
+import signal
+import uuid
+from datetime import datetime
 from contextlib import asynccontextmanager
 from fastapi import FastAPI, Request, status
-from fastapi.middleware.cors import CORSMiddleware
+from fastapi.encoders import jsonable_encoder
 from fastapi.responses import JSONResponse
 from fastapi.exceptions import RequestValidationError
+from brotli_asgi import BrotliMiddleware
 
+from app.cache import cache
 from app.config import settings
+from app.body_limits import BodySizeLimitMiddleware, RequestBodyTooLarge, too_large_response
+from app.cors import RouteCorsMiddleware
+from app.validation import RequestValidationFailure, errors_from_pydantic, validation_response_body
 from app.database import MongoDB
-from app.routes import podcasts_router, episodes_router, dev_bulk_transcribe_router, transcription_router
+from app.database.query_trace import query_tracer, start_request_trace, log_request_trace
+from app.models.schemas import ValidationErrorResponse
+from app.graphql_schema import graphql_router
+from app.runtime_settings import reload_on_signal
+from app.field_encryption import encryption_enabled, field_encryptor
+from app.secret_sources import run_secrets_refresher
+from app.services.archive_service import run_archival_scheduler
+from app.services.storage_lifecycle import bootstrap_lifecycle
+from app.services.asr_jobs import run_asr_poller
+from app.services.transcript_indexer import run_transcript_indexer
+from app.services.topic_analytics import run_topic_analysis_scheduler
+from app.services.guest_index import run_guest_index_scheduler
+from app.services.episode_embeddings import run_embedding_scheduler
+from app.services.audit_service import current_actor, current_request_id, request_actor
+from app.services.bulk_schedule import run_bulk_schedule_scheduler
+from app.services.bulk_transcribe_service import BulkTranscribeService
+from app.services.reconciliation import run_reconciliation_scheduler
+from app.services.whisper_service import whisper_service, run_whisper_health_monitor
+from app.services.temp_storage import temp_storage
+from app.services.upload_transcription import UploadTranscriptionService
+from app.services.workspace_service import WorkspaceService
+from app.services.quota_service import QuotaExceededError
+from app.services.notification_service import email_notifier, run_digest_scheduler
+from app.routes import (
+    podcasts_router,
+    episodes_router,
+    dev_bulk_transcribe_router,
+    transcription_router,
+    transcribe_router,
+    export_router,
+    feeds_router,
+    notifications_router,
+    costs_router,
+    quota_router,
+    admin_router,
+    admin_ops_router,
+    callbacks_router,
+    asr_callbacks_router,
+    user_state_router,
+    favorites_router,
+    share_router,
+    share_public_router,
+    audit_router,
+    analytics_router,
+    people_router,
+    external_router,
+)
 
 # Configure logging
 logging.basicConfig(
@@ -18,6 +73,9 @@ logging.basicConfig(
 
 logger = logging.getLogger(__name__)
 
+# Longer client-supplied X-Request-ID values are truncated
+MAX_REQUEST_ID_LENGTH = 128
+
 
 @asynccontextmanager
 async def lifespan(app: FastAPI):
@@ -28,6 +86,7 @@ async def lifespan(app: FastAPI):
     """
     # Startup
     logger.info("Starting up podcast subscription API")
+    logger.debug(f"Configuration: {settings.redacted()}")
     try:
         await MongoDB.connect_db()
         logger.info("Database connection established")
@@ -35,10 +94,148 @@ async def lifespan(app: FastAPI):
         logger.error(f"Failed to connect to database: {e}")
         raise
 
+    if settings.workspaces_enabled:
+        await WorkspaceService(MongoDB.get_db()).ensure_default()
+
+    grpc_server = None
+    if settings.grpc_enabled and settings.workspaces_enabled:
+        # Like GraphQL, the gRPC API has no API keys or workspace scoping
+        logger.warning("Not starting the gRPC server: it isn't workspace-aware")
+    elif settings.grpc_enabled:
+        from app.grpc_server import start_grpc_server
+        grpc_server = await start_grpc_server()
+
+    digest_task = None
+    if email_notifier.enabled and settings.notification_digest_interval_minutes > 0:
+        digest_task = asyncio.create_task(
+            run_digest_scheduler(MongoDB.get_db, settings.notification_digest_interval_minutes)
+        )
+
+    archival_task = None
+    if settings.archive_interval_hours > 0:
+        archival_task = asyncio.create_task(
+            run_archival_scheduler(MongoDB.get_db, settings.archive_interval_hours)
+        )
+
+    reconciliation_task = None
+    if settings.reconcile_interval_hours > 0:
+        reconciliation_task = asyncio.create_task(
+            run_reconciliation_scheduler(MongoDB.get_db, settings.reconcile_interval_hours)
+        )
+
+    topic_analysis_task = None
+    if settings.topic_analysis_interval_hours > 0:
+        topic_analysis_task = asyncio.create_task(
+            run_topic_analysis_scheduler(MongoDB.get_db, settings.topic_analysis_interval_hours)
+        )
+
+    guest_index_task = None
+    if settings.guest_index_interval_hours > 0:
+        guest_index_task = asyncio.create_task(
+            run_guest_index_scheduler(MongoDB.get_db, settings.guest_index_interval_hours)
+        )
+
+    embedding_task = None
+    if settings.embedding_interval_hours > 0 and settings.openai_api_key:
+        embedding_task = asyncio.create_task(
+            run_embedding_scheduler(MongoDB.get_db, settings.embedding_interval_hours)
+        )
+
+    try:
+        await BulkTranscribeService(MongoDB.get_db()).migrate_embedded_episodes()
+    except Exception as e:
+        logger.error(f"Failed to migrate bulk job episode progress: {e}")
+
+    # Pick up bulk jobs left unfinished by the previous deployment
+    try:
+        resumed = await BulkTranscribeService(MongoDB.get_db()).resume_interrupted_jobs()
+        if resumed:
+            logger.info(f"Resumed {resumed} interrupted bulk job(s)")
+    except Exception as e:
+        logger.error(f"Failed to resume interrupted bulk jobs: {e}")
+
+    if settings.s3_lifecycle_bootstrap:
+        await bootstrap_lifecycle()
+
+    if encryption_enabled() and not field_encryptor.check():
+        raise RuntimeError("Field encryption is enabled but can't encrypt; check the key configuration")
+
+    # Remove audio left behind by a crashed run before new downloads start
+    try:
+        temp_storage.cleanup_orphans()
+    except Exception as e:
+        logger.error(f"Failed to clean up temp directory: {e}")
+
+    schedule_task = asyncio.create_task(run_bulk_schedule_scheduler(MongoDB.get_db))
+
+    asr_poll_task = None
+    if settings.asr_provider:
+        asr_poll_task = asyncio.create_task(run_asr_poller(MongoDB.get_db))
+
+    indexer_task = None
+    if settings.transcript_index_enabled:
+        indexer_task = asyncio.create_task(run_transcript_indexer(MongoDB.get_db))
+
+    secrets_task = None
+    if settings.secrets_refresh_interval_seconds > 0:
+        secrets_task = asyncio.create_task(run_secrets_refresher(settings.secrets_refresh_interval_seconds))
+
+    whisper_health_task = None
+    if settings.whisper_health_check_interval_seconds > 0:
+        whisper_health_task = asyncio.create_task(
+            run_whisper_health_monitor(settings.whisper_health_check_interval_seconds)
+        )
+    # Load the model in the background so the first job doesn't wait for it
+    whisper_warmup_task = asyncio.create_task(whisper_service.warm_up())
+
+    # SIGHUP reloads runtime settings (log level, worker counts, Whisper pool)
+    loop = asyncio.get_running_loop()
+    sighup_handled = False
+    if hasattr(signal, "SIGHUP"):
+        try:
+            loop.add_signal_handler(signal.SIGHUP, lambda: asyncio.create_task(reload_on_signal()))
+            sighup_handled = True
+        except (NotImplementedError, RuntimeError) as e:
+            logger.warning(f"SIGHUP reload unavailable: {e}")
+
     yield
 
     # Shutdown
     logger.info("Shutting down podcast subscription API")
+    if digest_task:
+        digest_task.cancel()
+    if archival_task:
+        archival_task.cancel()
+    if reconciliation_task:
+        reconciliation_task.cancel()
+    if topic_analysis_task:
+        topic_analysis_task.cancel()
+    if guest_index_task:
+        guest_index_task.cancel()
+    if embedding_task:
+        embedding_task.cancel()
+    schedule_task.cancel()
+    if asr_poll_task:
+        asr_poll_task.cancel()
+    if indexer_task:
+        indexer_task.cancel()
+    if whisper_health_task:
+        whisper_health_task.cancel()
+    whisper_warmup_task.cancel()
+    if secrets_task:
+        secrets_task.cancel()
+    if sighup_handled:
+        loop.remove_signal_handler(signal.SIGHUP)
+    # Abort in-flight Whisper requests rather than leaving hour-long calls running
+    interrupted = await BulkTranscribeService.interrupt_all(MongoDB.get_db())
+    if interrupted:
+        logger.info(f"Released {interrupted} running bulk job(s) for another instance to resume")
+    cancelled = UploadTranscriptionService.cancel_all()
+    if cancelled:
+        logger.info(f"Cancelled {cancelled} running transcription task(s)")
+    if grpc_server:
+        await grpc_server.stop(grace=5)
+    await cache.close()
     await MongoDB.close_db()
     logger.info("Database connection closed")
 
@@ -51,29 +248,67 @@ app = FastAPI(
     lifespan=lifespan,
     docs_url="/docs",
     redoc_url="/redoc",
+    responses={422: {"model": ValidationErrorResponse}},
 )
 
-# Configure CORS
-app.add_middleware(
-    CORSMiddleware,
-    allow_origins=settings.cors_origins_list,
-    allow_credentials=True,
-    allow_methods=["*"],
-    allow_headers=["*"],
-)
+# Compress large responses (transcripts, job details with episode progress).
+# Clients sending Accept-Encoding: br get brotli, otherwise gzip.
+if settings.compression_enabled:
+    app.add_middleware(
+        BrotliMiddleware,
+        quality=settings.brotli_quality,
+        minimum_size=settings.compression_minimum_size,
+        gzip_fallback=True,
+    )
+
+# Reject oversized request bodies with 413 (inside CORS so browsers can read the error)
+app.add_middleware(BodySizeLimitMiddleware)
+
+# Configure CORS (global settings, overridden per route group by CORS_POLICIES)
+app.add_middleware(RouteCorsMiddleware)
 
 
 # Exception Handlers
 @app.exception_handler(RequestValidationError)
 async def validation_exception_handler(request: Request, exc: RequestValidationError):
-    """Handle validation errors."""
-    logger.error(f"Validation error: {exc}")
+    """Handle request DTO binding errors."""
+    errors = errors_from_pydantic(exc.errors())
+    logger.error(f"Validation error: {errors}")
     return JSONResponse(
         status_code=status.HTTP_422_UNPROCESSABLE_ENTITY,
-        content={
-            "error": "Validation error",
-            "detail": exc.errors()
-        }
+        content=validation_response_body(errors)
+    )
+
+
+@app.exception_handler(RequestValidationFailure)
+async def validation_failure_handler(request: Request, exc: RequestValidationFailure):
+    """Handle validation rules checked inside route handlers."""
+    logger.error(f"Validation error: {exc.errors}")
+    return JSONResponse(
+        status_code=exc.status_code,
+        content=validation_response_body(exc.errors)
+    )
+
+
+@app.exception_handler(RequestBodyTooLarge)
+async def body_too_large_handler(request: Request, exc: RequestBodyTooLarge):
+    """Handle request bodies that passed their size limit while streaming in."""
+    return too_large_response(exc.limit)
+
+
+@app.exception_handler(QuotaExceededError)
+async def quota_exceeded_handler(request: Request, exc: QuotaExceededError):
+    """Handle transcription requests over the monthly quota."""
+    logger.warning(f"Quota exceeded: {exc.message}")
+    retry_after = int((exc.quota["resets_at"] - datetime.utcnow()).total_seconds())
+    return JSONResponse(
+        status_code=exc.status_code,
+        headers={"Retry-After": str(max(retry_after, 0))},
+        content=jsonable_encoder({
+            "error": "Quota exceeded",
+            "detail": exc.message,
+            "quota": exc.quota,
+        })
     )
 
 
@@ -97,10 +332,47 @@ async def health_check():
     return {
         "status": "healthy",
         "service": "podcast-subscription-api",
-        "version": "1.0.0"
+        "version": "1.0.0",
+        "whisper": whisper_service.status(),
+        "temp_storage": temp_storage.status()
     }
 
 
+# Redacted configuration, for debugging deployments
+@app.get("/config", tags=["health"])
+async def config_dump():
+    """Effective settings with secrets masked (CONFIG_DUMP_ENABLED only)."""
+    if not settings.config_dump_enabled:
+        return JSONResponse(
+            status_code=status.HTTP_404_NOT_FOUND,
+            content={"error": "Not found", "detail": "Config dump is disabled"}
+        )
+    return settings.redacted()
+
+
+# Slow MongoDB queries, for diagnosing missing indexes
+@app.get("/debug/queries", tags=["health"])
+async def debug_queries():
+    """Recent slow queries and per-collection totals (MONGODB_QUERY_TRACE_ENABLED only)."""
+    if not settings.mongodb_query_trace_enabled:
+        return JSONResponse(
+            status_code=status.HTTP_404_NOT_FOUND,
+            content={"error": "Not found", "detail": "Query tracing is disabled"}
+        )
+    return query_tracer.report()
+
+
+@app.delete("/debug/queries", tags=["health"], status_code=status.HTTP_204_NO_CONTENT)
+async def reset_debug_queries():
+    """Clear recorded queries, e.g. before reproducing a slow request."""
+    if not settings.mongodb_query_trace_enabled:
+        return JSONResponse(
+            status_code=status.HTTP_404_NOT_FOUND,
+            content={"error": "Not found", "detail": "Query tracing is disabled"}
+        )
+    query_tracer.reset()
+
+
 # Root endpoint
 @app.get("/", tags=["root"])
 async def root():
@@ -118,15 +390,45 @@ app.include_router(podcasts_router)
 app.include_router(episodes_router)
 app.include_router(dev_bulk_transcribe_router)
 app.include_router(transcription_router)
+app.include_router(transcribe_router)
+app.include_router(export_router)
+app.include_router(feeds_router)
+app.include_router(notifications_router)
+app.include_router(costs_router)
+app.include_router(quota_router)
+app.include_router(admin_router)
+app.include_router(admin_ops_router)
+app.include_router(callbacks_router)
+app.include_router(asr_callbacks_router)
+app.include_router(user_state_router)
+app.include_router(favorites_router)
+app.include_router(share_router)
+app.include_router(share_public_router)
+app.include_router(audit_router)
+app.include_router(analytics_router)
+app.include_router(people_router)
+app.include_router(external_router)
+if not settings.workspaces_enabled:
+    # GraphQL resolvers aren't workspace-aware
+    app.include_router(graphql_router, prefix="/graphql")
 
 
 # Middleware for request logging
 @app.middleware("http")
 async def log_requests(request: Request, call_next):
-    """Log all incoming requests."""
-    logger.info(f"{request.method} {request.url.path}")
+    """Log all incoming requests and tag them with a request ID."""
+    request_id = request.headers.get("x-request-id", "")[:MAX_REQUEST_ID_LENGTH] or uuid.uuid4().hex
+    current_request_id.set(request_id)
+    current_actor.set(request_actor(request.headers.get("x-admin-key"), request.headers.get("x-api-key")))
+    logger.info(f"{request.method} {request.url.path} ({request_id})")
+    trace = None
+    if settings.mongodb_query_trace_enabled:
+        trace = start_request_trace(f"{request.method} {request.url.path}")
     response = await call_next(request)
     logger.info(f"Response status: {response.status_code}")
+    if trace:
+        log_request_trace(trace)
+    response.headers["X-Request-ID"] = request_id
     return response
 
 
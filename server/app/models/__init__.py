@@ -5,11 +5,19 @@ from .schemas import (
     PodcastResponse,
     PodcastListResponse,
     EpisodeResponse,
+    AdSegment,
+    QuoteExtractionResponse,
+    ClipRequest,
+    ClipResponse,
+    SimilarEpisodesResponse,
+    ShowNote,
     EpisodeListResponse,
     TranscriptResponse,
+    PipelineStatusResponse,
     ErrorResponse,
     SuccessResponse,
     TranscriptStatus,
+    EpisodeOrder,
 )
 
 __all__ = [
@@ -18,9 +26,17 @@ __all__ = [
     "PodcastResponse",
     "PodcastListResponse",
     "EpisodeResponse",
+    "AdSegment",
+    "QuoteExtractionResponse",
+    "ClipRequest",
+    "ClipResponse",
+    "SimilarEpisodesResponse",
+    "ShowNote",
     "EpisodeListResponse",
     "TranscriptResponse",
+    "PipelineStatusResponse",
     "ErrorResponse",
     "SuccessResponse",
     "TranscriptStatus",
+    "EpisodeOrder",
 ]
@@ -1,8 +1,11 @@
 """Pydantic models for request and response validation."""
-from pydantic import BaseModel, Field, HttpUrl
-from typing import Optional, List
-from datetime import datetime
+from pydantic import BaseModel, Field, HttpUrl, field_validator
+from typing import Any, Dict, Optional, List
+from datetime import datetime, timezone
 from enum import Enum
+import re
+
+from app.scheduling import validate_schedule
 
 
 class TranscriptStatus(str, Enum):
@@ -11,6 +14,31 @@ class TranscriptStatus(str, Enum):
     PROCESSING = "processing"
     COMPLETED = "completed"
     FAILED = "failed"
+    SKIPPED = "skipped"
+
+
+class EpisodeOrder(str, Enum):
+    """
+    Publication-date ordering for episode listings and bulk jobs.
+
+    Episodes without a publication date are treated as the oldest: first
+    when oldest-first, last when newest-first. Ties keep a stable order.
+    """
+    OLDEST = "oldest"
+    NEWEST = "newest"
+
+
+class ImportSource(str, Enum):
+    """Service a subscription export came from."""
+    APPLE = "apple"
+    SPOTIFY = "spotify"
+
+
+class JobPriority(str, Enum):
+    """Scheduling priority for transcription work."""
+    HIGH = "high"
+    NORMAL = "normal"
+    LOW = "low"
 
 
 class BulkJobStatus(str, Enum):
@@ -26,7 +54,11 @@ class BulkJobStatus(str, Enum):
 # Request Models
 class SubscribePodcastRequest(BaseModel):
     """Request model for subscribing to a podcast."""
-    rss_url: HttpUrl = Field(..., description="RSS feed URL of the podcast")
+    rss_url: HttpUrl = Field(..., description="RSS feed URL of the podcast, or its website")
+    discover: bool = Field(
+        True,
+        description="If the URL isn't a podcast feed, look for podcast feeds linked from the page"
+    )
 
 
 class EpisodeQueryParams(BaseModel):
@@ -34,9 +66,203 @@ class EpisodeQueryParams(BaseModel):
     status: Optional[str] = Field(None, description="Filter by transcript status (all/completed/processing)")
     page: int = Field(1, ge=1, description="Page number")
     limit: int = Field(20, ge=1, le=100, description="Number of items per page")
+    order: EpisodeOrder = Field(EpisodeOrder.NEWEST, description="Publication-date order (newest/oldest)")
 
 
 # Response Models
+class CostBreakdown(BaseModel):
+    """Transcription cost in USD."""
+    audio_minutes: float = 0
+    lambda_gb_seconds: float = 0
+    transcription_usd: float = 0
+    storage_usd: float = 0
+    compute_usd: float = 0
+    total_usd: float = 0
+
+
+class AdSegment(BaseModel):
+    """A likely ad, sponsor or self-promotion read in a transcript."""
+    label: str = Field(..., description="sponsor, ad or promo")
+    start_seconds: float = Field(..., description="Estimated start time")
+    end_seconds: float = Field(..., description="Estimated end time")
+    start_char: int = Field(..., description="Start offset in the transcript text")
+    end_char: int = Field(..., description="End offset in the transcript text")
+    confidence: float = Field(..., description="Detector confidence (0-1)")
+    cues: List[str] = Field(default_factory=list, description="Phrases that triggered detection")
+
+
+class Quote(BaseModel):
+    """A notable sentence from a transcript."""
+    text: str = Field(..., description="Sentence as transcribed, without timestamps or speaker label")
+    start_seconds: float = Field(..., description="Estimated start time")
+    end_seconds: float = Field(..., description="Estimated end time")
+    start_char: int = Field(..., description="Start offset in the transcript text")
+    end_char: int = Field(..., description="End offset in the transcript text")
+    score: float = Field(..., description="Heuristic quotability (0-1)")
+    card_url: Optional[str] = Field(None, description="Presigned URL of the quote card (SVG), valid 7 days")
+
+
+class QuoteExtractionResponse(BaseModel):
+    """Quotes extracted from an episode."""
+    episode_id: str
+    method: str = Field(..., description="llm or heuristic")
+    model: Optional[str] = Field(None, description="Model that picked the quotes")
+    quotes: List[Quote]
+
+
+class ClipRequest(BaseModel):
+    """Time range of an episode's audio to cut."""
+    start_seconds: float = Field(..., ge=0, description="Clip start, as in transcript timestamps")
+    end_seconds: float = Field(..., gt=0, description="Clip end")
+
+
+class ClipResponse(BaseModel):
+    """An audio clip stored in S3."""
+    episode_id: str
+    start_seconds: float
+    end_seconds: float
+    duration_seconds: float
+    s3_key: str = Field(..., description="Key in the audio bucket")
+    url: str = Field(..., description="Presigned download URL")
+    expires_in: int = Field(..., description="Seconds the URL stays valid")
+    created: bool = Field(..., description="False when an identical earlier clip was reused")
+
+
+class SimilarEpisode(BaseModel):
+    """An episode close in content to another."""
+    episode_id: str
+    title: Optional[str] = None
+    podcast_id: Optional[str] = None
+    podcast_title: Optional[str] = None
+    published_date: Optional[datetime] = None
+    score: float = Field(..., description="Cosine similarity of the transcript embeddings (-1 to 1)")
+
+
+class SimilarEpisodesResponse(BaseModel):
+    """Episodes most similar to one, best first."""
+    episode_id: str
+    model: str = Field(..., description="Embedding model compared")
+    episodes: List[SimilarEpisode]
+
+
+class ExternalEpisode(BaseModel):
+    """An episode found through the episode search provider."""
+    external_id: str = Field(..., description="Provider's episode ID")
+    title: Optional[str] = None
+    description: Optional[str] = None
+    audio_url: Optional[str] = None
+    published_date: Optional[datetime] = None
+    duration_minutes: Optional[int] = None
+    image_url: Optional[str] = None
+    podcast_title: Optional[str] = None
+    podcast_rss_url: Optional[str] = None
+    episode_id: Optional[str] = Field(None, description="Library episode, if already imported")
+    transcript_status: Optional[str] = Field(None, description="Transcript status of the library episode")
+
+
+class ExternalEpisodeSearchResponse(BaseModel):
+    """Episode search results."""
+    provider: str
+    query: str
+    page: int
+    results: List[ExternalEpisode]
+
+
+class ExternalTranscribeResponse(BaseModel):
+    """Result of importing and transcribing an external episode."""
+    status: str = Field(..., description="started, already_processing or already_completed")
+    episode_id: str
+    podcast_id: str
+    message: str
+
+
+class ShowNote(BaseModel):
+    """A link from an episode's show notes."""
+    url: str = Field(..., description="Link URL, with tracking redirects and campaign parameters removed")
+    text: Optional[str] = Field(None, description="Link text")
+
+
+class TranscriptQuality(BaseModel):
+    """Confidence summary of a transcript, from Whisper's per-segment scores."""
+    score: float = Field(..., description="Duration-weighted mean token probability (0-1)")
+    avg_logprob: float = Field(..., description="Duration-weighted mean of the segments' avg_logprob")
+    segments: int = Field(..., description="Segments scored")
+    low_confidence_segments: int = Field(..., description="Segments below Whisper's confidence or above its repetition thresholds")
+
+
+class TranscriptProgress(BaseModel):
+    """How far a running transcription has got."""
+    percent: float = Field(..., description="Percent of the audio transcribed")
+    transcribed_seconds: Optional[float] = Field(None, description="Position in the audio the transcript reaches")
+    estimated: bool = Field(False, description="Estimated from elapsed time and transcription speed rather than reported")
+    updated_at: Optional[datetime] = Field(None, description="When the progress was recorded")
+
+
+class PodcastWorkflow(BaseModel):
+    """How the poll Lambda starts transcription of a podcast's new episodes."""
+    state_machine_arn: Optional[str] = Field(
+        None,
+        pattern=r"^arn:aws[a-z-]*:states:",
+        description="State machine to start instead of STEP_FUNCTION_ARN"
+    )
+    language: Optional[str] = Field(None, max_length=16, description="Transcription language code, e.g. 'es'")
+    provider: Optional[str] = Field(None, max_length=64, description="Transcription provider name")
+    priority: Optional[JobPriority] = Field(None, description="Transcription priority")
+
+
+class EpisodeFilter(BaseModel):
+    """Which of a podcast's new episodes the poll Lambda records without transcribing."""
+    skip_trailers: bool = Field(False, description="Skip trailers (itunes:episodeType, or titles like 'Trailer')")
+    skip_bonus: bool = Field(False, description="Skip bonus episodes (itunes:episodeType, or titles like 'BONUS: ...')")
+    skip_reruns: bool = Field(False, description="Skip re-runs (titles like 'Rerun: ...', '(Encore)', 'Best of ...')")
+
+
+class FeedHealth(BaseModel):
+    """Outcome of the poll Lambda's recent fetches of a podcast's feed."""
+    status: str = Field(..., description="healthy, flaky (failing, or needed retries) or dead (failed FEED_DEAD_AFTER polls in a row)")
+    consecutive_failures: int = Field(0, description="Polls in a row that failed")
+    last_retries: int = Field(0, description="Retries the last poll needed")
+    last_error_type: Optional[str] = Field(
+        None,
+        description="timeout, network, http_4xx, http_5xx, auth_required, parse or no_feed_url"
+    )
+    last_error: Optional[str] = Field(None, description="Last failure message")
+    last_error_at: Optional[datetime] = None
+    last_success_at: Optional[datetime] = None
+    last_polled_at: Optional[datetime] = None
+
+
+class StorageLocation(BaseModel):
+    """Buckets a podcast's audio and transcripts are kept in (see STORAGE_LOCATIONS)."""
+    location: Optional[str] = Field(None, description="Name of the location in STORAGE_LOCATIONS")
+    region: str = Field(..., description="AWS region of the buckets")
+    bucket: str = Field(..., description="Transcripts bucket")
+    audio_bucket: Optional[str] = Field(None, description="Audio bucket, where the pipeline also keeps its transcripts")
+
+
+class StorageLocationUpdate(BaseModel):
+    """Request to pin a podcast or workspace to a storage location."""
+    location: Optional[str] = Field(
+        None, max_length=64, description="Name of a location in STORAGE_LOCATIONS; null for the default buckets"
+    )
+
+
+class PodcastPrivacyUpdate(BaseModel):
+    """Request to make a podcast private or public."""
+    private: bool = Field(..., description="Encrypt transcripts with the workspace's KMS key and serve them only through the API")
+
+
+class WorkspaceKmsKeyUpdate(BaseModel):
+    """Request to set the KMS key a workspace's private podcasts are encrypted with."""
+    kms_key_id: Optional[str] = Field(None, max_length=2048, description="KMS key ID, ARN or alias; null to clear")
+
+
+class PodcastCategory(BaseModel):
+    """An iTunes category of a podcast."""
+    name: str = Field(..., description="Category, e.g. Technology")
+    subcategories: List[str] = Field(default_factory=list, description="Subcategories, e.g. Tech News")
+
+
 class PodcastResponse(BaseModel):
     """Response model for podcast data."""
     podcast_id: str = Field(..., description="Unique podcast identifier")
@@ -45,9 +271,17 @@ class PodcastResponse(BaseModel):
     description: Optional[str] = Field(None, description="Podcast description")
     image_url: Optional[str] = Field(None, description="Podcast cover image URL")
     author: Optional[str] = Field(None, description="Podcast author")
+    categories: List[PodcastCategory] = Field(default_factory=list, description="iTunes categories")
+    explicit: Optional[bool] = Field(None, description="iTunes explicit flag (None if the feed doesn't say)")
     subscribed_at: datetime = Field(..., description="Subscription timestamp")
     active: bool = Field(True, description="Subscription status")
     episode_count: Optional[int] = Field(None, description="Total number of episodes in RSS feed")
+    deleted_at: Optional[datetime] = Field(None, description="When the podcast was deleted (restorable)")
+    workflow: Optional[PodcastWorkflow] = Field(None, description="Transcription workflow overrides")
+    episode_filter: Optional[EpisodeFilter] = Field(None, description="New episodes recorded without transcription")
+    feed_health: Optional[FeedHealth] = Field(None, description="Feed fetch health recorded by the poll Lambda")
+    storage: Optional[StorageLocation] = Field(None, description="Storage location the podcast is pinned to")
+    private: bool = Field(False, description="Whether transcripts are encrypted and served only through the API")
 
     class Config:
         json_schema_extra = {
@@ -77,16 +311,31 @@ class EpisodeResponse(BaseModel):
     podcast_title: str = Field(..., description="Podcast title")
     episode_title: str = Field(..., description="Episode title")
     title: Optional[str] = Field(None, description="Deprecated: use episode_title")
-    description: Optional[str] = Field(None, description="Episode description")
+    description: Optional[str] = Field(None, description="Episode description (Markdown, sanitized)")
+    description_html: Optional[str] = Field(None, description="Episode description as published in the feed")
+    show_notes: Optional[List[ShowNote]] = Field(None, description="Links found in the description")
     audio_url: Optional[str] = Field(None, description="Original audio URL")
     published_date: Optional[datetime] = Field(None, description="Episode publication date")
     duration_minutes: Optional[int] = Field(None, description="Episode duration in minutes")
+    episode_type: Optional[str] = Field(None, description="iTunes episode type (full, trailer or bonus)")
+    explicit: Optional[bool] = Field(None, description="iTunes explicit flag of the episode")
     s3_audio_key: Optional[str] = Field(None, description="S3 key for stored audio")
     transcript_status: TranscriptStatus = Field(..., description="Transcript processing status")
+    transcript_progress: Optional[TranscriptProgress] = Field(None, description="Progress while processing")
+    skip_reason: Optional[str] = Field(None, description="Why the episode was skipped (trailer, bonus, rerun, too_short or too_long)")
+    rerun_of: Optional[str] = Field(None, description="Episode this replays; the transcript is shared with it")
     processing_step: Optional[str] = Field(None, description="Current processing step (downloading, chunking, transcribing, merging, completed)")
     transcript_s3_key: Optional[str] = Field(None, description="S3 key for transcript")
+    transcript_sha256: Optional[str] = Field(None, description="SHA-256 of the transcript; identical transcripts share one S3 object")
+    storage: Optional[StorageLocation] = Field(None, description="Where the transcript is stored, if not in the default bucket")
+    execution_arn: Optional[str] = Field(None, description="Step Functions execution that last transcribed the episode")
     discovered_at: datetime = Field(..., description="When episode was discovered")
     processed_at: Optional[datetime] = Field(None, description="When processing completed")
+    estimated_cost: Optional[CostBreakdown] = Field(None, description="Estimated transcription cost")
+    actual_cost: Optional[CostBreakdown] = Field(None, description="Actual transcription cost")
+    ad_segments: Optional[List[AdSegment]] = Field(None, description="Detected ad/sponsor segments (None until analyzed)")
+    transcript_quality: Optional[TranscriptQuality] = Field(None, description="Transcript confidence (None if Whisper didn't report it)")
+    low_confidence: Optional[bool] = Field(None, description="Quality score below TRANSCRIPT_QUALITY_THRESHOLD; likely worth re-transcribing")
 
     class Config:
         populate_by_name = True
@@ -115,6 +364,7 @@ class EpisodeListResponse(BaseModel):
     page: int
     limit: int
     has_more: bool
+    next_cursor: Optional[str] = Field(None, description="Cursor for the next page, if any")
 
 
 class TranscriptResponse(BaseModel):
@@ -135,6 +385,79 @@ class TranscriptResponse(BaseModel):
         }
 
 
+class PipelineStatusResponse(BaseModel):
+    """Where an episode is in the transcription pipeline."""
+    episode_id: str = Field(..., description="Episode identifier")
+    source: str = Field(..., description="step_functions when read from the execution, local for the in-process orchestrator")
+    transcript_status: TranscriptStatus = Field(..., description="Transcript status stored on the episode")
+    stage: Optional[str] = Field(None, description="chunking, transcribing, merging, completed or failed")
+    current_state: Optional[str] = Field(None, description="State machine state the execution is in")
+    chunks_completed: Optional[int] = Field(None, description="Chunks transcribed so far")
+    chunks_total: Optional[int] = Field(None, description="Chunks the audio was split into")
+    progress: Optional[TranscriptProgress] = Field(None, description="Progress within the episode while transcribing")
+    execution_arn: Optional[str] = Field(None, description="Step Functions execution ARN")
+    execution_status: Optional[str] = Field(None, description="RUNNING, SUCCEEDED, FAILED, TIMED_OUT or ABORTED")
+    started_at: Optional[datetime] = Field(None, description="When the execution started")
+    stopped_at: Optional[datetime] = Field(None, description="When the execution stopped")
+    error: Optional[str] = Field(None, description="Failure reason, if any")
+
+    class Config:
+        json_schema_extra = {
+            "example": {
+                "episode_id": "ep_xyz789",
+                "source": "step_functions",
+                "transcript_status": "processing",
+                "stage": "transcribing",
+                "current_state": "TranscribeChunks",
+                "chunks_completed": 7,
+                "chunks_total": 20,
+                "execution_arn": "arn:aws:states:us-east-1:123456789012:execution:podcast-transcription:ep-xyz789-20250115T103000-1a2b3c4d",
+                "execution_status": "RUNNING",
+                "started_at": "2025-01-15T10:30:00"
+            }
+        }
+
+
+class FeedCandidate(BaseModel):
+    """Podcast feed discovered on a website."""
+    url: str = Field(..., description="Feed URL; subscribe with it as rss_url")
+    title: Optional[str] = None
+    author: Optional[str] = None
+    episode_count: int = Field(..., description="Episodes with audio in the feed")
+
+
+class FeedCandidatesResponse(BaseModel):
+    """Returned with 300 Multiple Choices when a website links several podcast feeds."""
+    error: str = "Multiple podcast feeds found"
+    detail: Optional[str] = None
+    candidates: List[FeedCandidate]
+
+
+class ImportedShow(BaseModel):
+    """Outcome of importing one show from a subscription export."""
+    title: Optional[str] = None
+    author: Optional[str] = None
+    rss_url: Optional[str] = Field(None, description="Feed from the export or the directory match")
+    status: str = Field(..., description="subscribed, already_subscribed, unmatched or failed")
+    podcast_id: Optional[str] = None
+    error: Optional[str] = None
+    candidates: List[FeedCandidate] = Field(
+        default_factory=list,
+        description="Directory results for unmatched shows; subscribe to the right one manually"
+    )
+
+
+class SubscriptionImportResponse(BaseModel):
+    """Result of a subscription import."""
+    source: ImportSource
+    total: int
+    subscribed: int
+    already_subscribed: int
+    unmatched: int
+    failed: int
+    shows: List[ImportedShow]
+
+
 class ErrorResponse(BaseModel):
     """Error response model."""
     error: str = Field(..., description="Error message")
@@ -149,6 +472,31 @@ class ErrorResponse(BaseModel):
         }
 
 
+class FieldError(BaseModel):
+    """Structured validation error for a single request field."""
+    code: str = Field(..., description="Stable error code (e.g. required, invalid_url, too_small)")
+    field: str = Field(..., description="Dotted path of the offending field")
+    message: str = Field(..., description="Human-readable message")
+
+
+class ValidationErrorResponse(BaseModel):
+    """Validation error response model."""
+    error: str = Field("Validation error", description="Error category")
+    detail: str = Field(..., description="Summary of all field errors")
+    errors: List[FieldError] = Field(..., description="Field-level errors")
+
+    class Config:
+        json_schema_extra = {
+            "example": {
+                "error": "Validation error",
+                "detail": "rss_url: Input should be a valid URL",
+                "errors": [
+                    {"code": "invalid_url", "field": "rss_url", "message": "Input should be a valid URL"}
+                ]
+            }
+        }
+
+
 class SuccessResponse(BaseModel):
     """Generic success response."""
     message: str = Field(..., description="Success message")
@@ -158,25 +506,107 @@ class SuccessResponse(BaseModel):
 # Bulk Transcription Models
 class BulkTranscribeRequest(BaseModel):
     """Request model for bulk transcribing podcast episodes."""
-    rss_url: HttpUrl = Field(..., description="RSS feed URL to process")
+    rss_url: Optional[HttpUrl] = Field(None, description="RSS feed URL to process (or give podcast_id)")
+    podcast_id: Optional[str] = Field(
+        None,
+        description="Subscribed podcast to process; its stored feed is used and its episodes receive the transcripts"
+    )
     max_episodes: Optional[int] = Field(None, ge=1, description="Maximum number of episodes to process (default: all)")
-    dry_run: bool = Field(False, description="If True, only transcribe 1 episode for testing purposes")
+    dry_run: bool = Field(
+        False,
+        description="If True, return the selected episodes with estimated hours and cost without creating a job"
+    )
+    published_after: Optional[datetime] = Field(None, description="Only include episodes published on or after this date")
+    published_before: Optional[datetime] = Field(None, description="Only include episodes published before this date")
+    title_contains: Optional[str] = Field(
+        None,
+        max_length=200,
+        description="Only include episodes whose title matches this regular expression (case-insensitive)"
+    )
+    order: EpisodeOrder = Field(
+        EpisodeOrder.OLDEST,
+        description="Process oldest or newest episodes first; max_episodes keeps the first N in this order"
+    )
+    priority: JobPriority = Field(
+        JobPriority.LOW,
+        description="Queue priority; backfills default to low so single-episode requests run first"
+    )
+    schedule: Optional[str] = Field(
+        None,
+        description="Re-run for new episodes on an interval ('6h', 'every 1d') or cron expression ('0 */6 * * *', UTC)"
+    )
+    model: Optional[str] = Field(
+        None,
+        max_length=100,
+        pattern=r"^[A-Za-z0-9][A-Za-z0-9._:/-]*$",
+        description="Whisper model for this job (tiny, base, small, medium, large-v3, or an OpenAI model name); default WHISPER_MODEL"
+    )
+
+    @field_validator("published_after", "published_before")
+    @classmethod
+    def to_naive_utc(cls, value: Optional[datetime]) -> Optional[datetime]:
+        # Feed dates are stored as naive UTC
+        if value is not None and value.tzinfo is not None:
+            return value.astimezone(timezone.utc).replace(tzinfo=None)
+        return value
+
+    @field_validator("title_contains")
+    @classmethod
+    def validate_title_pattern(cls, value: Optional[str]) -> Optional[str]:
+        if value is None:
+            return value
+        try:
+            re.compile(value)
+        except re.error as e:
+            raise ValueError(f"Invalid regular expression: {e}")
+        return value
+
+    @field_validator("schedule")
+    @classmethod
+    def validate_schedule(cls, value: Optional[str]) -> Optional[str]:
+        if value is None:
+            return value
+        return validate_schedule(value)
 
     class Config:
         json_schema_extra = {
             "example": {
                 "rss_url": "https://example.com/feed.rss",
                 "max_episodes": 10,
-                "dry_run": False
+                "dry_run": False,
+                "published_after": "2024-01-01T00:00:00",
+                "published_before": "2025-01-01T00:00:00",
+                "title_contains": "interview",
+                "order": "newest",
+                "schedule": "6h",
+                "model": "small"
             }
         }
 
 
+class BulkJobInFlight(BaseModel):
+    """Entry being transcribed when a bulk job was checkpointed."""
+    index: int = Field(..., description="Entry index in the job")
+    episode_id: Optional[str] = Field(None, description="Episode being transcribed")
+
+
+class BulkJobCheckpoint(BaseModel):
+    """Where a bulk job is, so another instance can resume it."""
+    next_index: int = Field(0, description="Index of the next entry to process")
+    in_flight: List[BulkJobInFlight] = Field(default_factory=list, description="Entries being transcribed (requeued on resume)")
+    owner: Optional[str] = Field(None, description="API instance processing the job (None: released)")
+    heartbeat_at: Optional[datetime] = Field(None, description="When the owner last renewed its lease")
+    resumes: int = Field(0, description="Times the job was resumed by another instance")
+
+
 class BulkTranscribeEpisodeProgress(BaseModel):
     """Progress for a single episode in a bulk job."""
+    index: int = Field(..., description="Position in the job's processing order")
     episode_id: Optional[str] = Field(None, description="Episode identifier (set when processing starts)")
     title: str = Field(..., description="Episode title")
     status: TranscriptStatus = Field(..., description="Transcription status")
+    skip_reason: Optional[str] = Field(None, description="too_short or too_long if skipped by the duration gates")
+    transcript_progress: Optional[TranscriptProgress] = Field(None, description="Estimated progress while processing")
     transcript: Optional[str] = Field(None, description="Transcript text (when completed)")
     error_message: Optional[str] = Field(None, description="Error message if failed")
     started_at: Optional[datetime] = Field(None, description="When transcription started")
@@ -187,16 +617,35 @@ class BulkTranscribeJobResponse(BaseModel):
     """Response model for bulk transcription job."""
     job_id: str = Field(..., description="Unique job identifier")
     rss_url: str = Field(..., description="RSS feed URL being processed")
+    podcast_id: Optional[str] = Field(None, description="Subscribed podcast the job belongs to")
     status: BulkJobStatus = Field(..., description="Job status")
     total_episodes: int = Field(..., description="Total episodes to process")
     processed_episodes: int = Field(0, description="Number of episodes processed")
     successful_episodes: int = Field(0, description="Number of successfully transcribed episodes")
     failed_episodes: int = Field(0, description="Number of failed episodes")
+    skipped_episodes: int = Field(0, description="Episodes skipped because they already have a completed transcript")
     created_at: datetime = Field(..., description="Job creation timestamp")
     updated_at: datetime = Field(..., description="Last update timestamp")
     completed_at: Optional[datetime] = Field(None, description="Job completion timestamp")
     current_episode: Optional[str] = Field(None, description="Currently processing episode title")
-    episodes: Optional[List[BulkTranscribeEpisodeProgress]] = Field(None, description="Detailed episode progress")
+    queue_position: Optional[int] = Field(None, description="Position in the Whisper admission queue while waiting for capacity")
+    download_paused_until: Optional[datetime] = Field(
+        None, description="While paused on an audio host's daily download cap, when the cap resets"
+    )
+    started_at: Optional[datetime] = Field(None, description="When processing started")
+    progress_percent: float = Field(0, description="Share of episodes processed (0-100)")
+    average_episode_seconds: Optional[float] = Field(None, description="Average wall-clock seconds per processed episode")
+    estimated_completion_at: Optional[datetime] = Field(None, description="Projected completion time while running")
+    priority: JobPriority = Field(JobPriority.LOW, description="Queue priority")
+    schedule: Optional[str] = Field(None, description="Recurrence schedule, if this job starts a series")
+    model: Optional[str] = Field(None, description="Whisper model requested for the job (None: WHISPER_MODEL)")
+    next_run_at: Optional[datetime] = Field(None, description="When the series runs next")
+    parent_job_id: Optional[str] = Field(None, description="Scheduled job this run belongs to")
+    checkpoint: Optional[BulkJobCheckpoint] = Field(None, description="Progress checkpoint used to resume the job after a deployment")
+    episodes: Optional[List[BulkTranscribeEpisodeProgress]] = Field(None, description="A page of detailed episode progress")
+    episodes_next_after: Optional[int] = Field(None, description="Pass as episodes_after to fetch the next page of episode progress")
+    estimated_cost: Optional[CostBreakdown] = Field(None, description="Estimated cost from feed durations")
+    actual_cost: Optional[CostBreakdown] = Field(None, description="Cost of episodes processed so far")
 
     class Config:
         json_schema_extra = {
@@ -215,7 +664,556 @@ class BulkTranscribeJobResponse(BaseModel):
         }
 
 
+class BulkTranscribeDryRunEpisode(BaseModel):
+    """An episode a bulk job would cover."""
+    episode_id: Optional[str] = Field(None, description="Existing episode, if already transcribed")
+    title: str = Field(..., description="Episode title")
+    audio_url: Optional[str] = Field(None, description="Audio file URL")
+    published_date: Optional[datetime] = Field(None, description="Publication date")
+    duration_minutes: Optional[int] = Field(None, description="Duration from the feed")
+    status: TranscriptStatus = Field(..., description="pending, or skipped if already transcribed or outside the duration gates")
+    skip_reason: Optional[str] = Field(None, description="too_short or too_long if skipped by the duration gates")
+
+
+class BulkTranscribeDryRunResponse(BaseModel):
+    """Response model for a bulk transcription dry run; no job is created."""
+    dry_run: bool = True
+    rss_url: str = Field(..., description="RSS feed URL")
+    podcast_id: Optional[str] = Field(None, description="Subscribed podcast, if requested by podcast_id")
+    podcast_title: str = Field(..., description="Podcast title")
+    total_episodes: int = Field(..., description="Episodes selected by the request")
+    skipped_episodes: int = Field(0, description="Selected episodes that already have a completed transcript or are outside the duration gates")
+    episodes_without_duration: int = Field(0, description="Episodes to transcribe whose feed entry has no duration (not included in the estimates)")
+    estimated_audio_hours: float = Field(..., description="Audio hours to transcribe")
+    estimated_cost: CostBreakdown = Field(..., description="Estimated cost of the job")
+    episodes: List[BulkTranscribeDryRunEpisode]
+
+
 class BulkTranscribeJobListResponse(BaseModel):
     """Response model for list of bulk transcription jobs."""
     jobs: List[BulkTranscribeJobResponse]
     total: int
+    has_more: bool = False
+    next_cursor: Optional[str] = Field(None, description="Cursor for the next page, if any")
+
+
+# Cost Models
+class MonthlyCostSummary(CostBreakdown):
+    """Actual costs for one transcription path in a month."""
+    episodes: Optional[int] = Field(None, description="Episodes transcribed through the Lambda pipeline")
+    jobs: Optional[int] = Field(None, description="Bulk jobs created")
+
+
+class MonthlyCost(BaseModel):
+    """Actual costs for a calendar month."""
+    month: str = Field(..., description="Month (YYYY-MM)")
+    pipeline: Optional[MonthlyCostSummary] = None
+    bulk_jobs: Optional[MonthlyCostSummary] = None
+    total: CostBreakdown
+
+
+class TopicTermCount(BaseModel):
+    """How often a keyword came up in a period."""
+    term: str
+    count: int = Field(..., description="Occurrences in the period's transcripts")
+    per_thousand_words: float = Field(..., description="Occurrences per 1,000 transcript words")
+
+
+class TopicPeriod(BaseModel):
+    """Keywords of the episodes published in one period."""
+    period: str = Field(..., description="e.g. 2025-W09, 2025-03, 2025-Q1 or 2025")
+    start: datetime = Field(..., description="Start of the period")
+    episodes: int = Field(..., description="Analyzed episodes published in the period")
+    words: int = Field(..., description="Words in their transcripts")
+    top_terms: List[TopicTermCount]
+
+
+class TopicTrend(BaseModel):
+    """One of the overall top keywords, followed across periods."""
+    term: str
+    total: int = Field(..., description="Occurrences over all periods")
+    counts: List[int] = Field(..., description="Occurrences per period, in the order of periods")
+
+
+class TopicAnalyticsResponse(BaseModel):
+    """Keyword frequency over time."""
+    podcast_id: Optional[str] = Field(None, description="Podcast analyzed, or null for all the caller's podcasts")
+    window: str = Field(..., description="Period length: week, month, quarter or year")
+    periods: List[TopicPeriod]
+    trends: List[TopicTrend]
+    episodes_analyzed: int = Field(..., description="Episodes whose keywords are included")
+    episodes_pending: int = Field(..., description="Completed episodes not analyzed yet")
+
+
+class GuestAppearance(BaseModel):
+    """An episode a person appears in."""
+    episode_id: str
+    episode_title: Optional[str] = None
+    podcast_id: Optional[str] = None
+    podcast_title: Optional[str] = None
+    published_date: Optional[datetime] = None
+    role: str = Field(..., description="Role from the feed's <podcast:person> tag, or guest if found in the transcript")
+    sources: List[str] = Field(..., description="Where the person was found: feed, transcript")
+    mentions: int = Field(0, description="Times the transcript names the person")
+
+
+class GuestAppearancesResponse(BaseModel):
+    """A person's appearances across podcasts, newest first."""
+    name: str
+    appearances: List[GuestAppearance]
+    total: int
+
+
+class CostReportResponse(BaseModel):
+    """Monthly cost report."""
+    months: List[MonthlyCost]
+    total: CostBreakdown
+
+
+# Quota Models
+class QuotaBudget(BaseModel):
+    """Monthly transcription budget for one subject."""
+    limit_minutes: Optional[int] = Field(None, description="Monthly limit (null = unlimited)")
+    used_minutes: int = Field(0, description="Minutes reserved this month")
+    remaining_minutes: Optional[int] = Field(None, description="Minutes left (null = unlimited)")
+
+
+class QuotaResponse(BaseModel):
+    """Current month's transcription quota."""
+    month: str = Field(..., description="Quota month (YYYY-MM)")
+    resets_at: datetime = Field(..., description="When usage resets")
+    global_: QuotaBudget = Field(..., alias="global", description="Budget shared by all callers")
+    api_key: Optional[QuotaBudget] = Field(None, description="Budget for the caller's X-API-Key")
+
+    class Config:
+        populate_by_name = True
+
+
+class DownloadUsage(BaseModel):
+    """Audio downloaded from one host on one UTC day."""
+    host: str = Field(..., description="Audio host")
+    day: str = Field(..., description="UTC day (YYYY-MM-DD)")
+    bytes: int = Field(..., description="Bytes downloaded, partial and failed downloads included")
+    downloads: int = Field(..., description="Downloads started")
+    limit_bytes: Optional[int] = Field(None, description="Today's cap for the host (None: no cap, or an earlier day)")
+    capped: bool = Field(False, description="Whether the host has served its cap today")
+
+
+class DownloadUsageResponse(BaseModel):
+    """Audio download bandwidth per host and day, newest first."""
+    days: int = Field(..., description="Days covered, today included")
+    resets_at: datetime = Field(..., description="When today's caps reset")
+    total_bytes: int = Field(..., description="Bytes downloaded over the period")
+    usage: List[DownloadUsage]
+
+
+# Podcast Cleanup Models
+class CleanupMode(str, Enum):
+    """What happens to a podcast's data when it is removed."""
+    NONE = "none"  # Soft delete only; restorable
+    ARCHIVE = "archive"  # Soft delete and archive transcripts immediately
+    DELETE = "delete"  # Permanently delete episodes, transcripts and bulk jobs
+
+
+class CleanupJobStatus(str, Enum):
+    """Status of a podcast cleanup job."""
+    PENDING = "pending"
+    RUNNING = "running"
+    COMPLETED = "completed"
+    FAILED = "failed"
+
+
+class CleanupJobResponse(BaseModel):
+    """Progress of an asynchronous podcast cleanup."""
+    job_id: str = Field(..., description="Cleanup job identifier")
+    podcast_id: str = Field(..., description="Podcast being cleaned up")
+    mode: CleanupMode = Field(..., description="Cleanup mode")
+    status: CleanupJobStatus = Field(..., description="Job status")
+    total_episodes: int = Field(0, description="Episodes to clean up")
+    processed_episodes: int = Field(0, description="Episodes cleaned up so far")
+    transcripts_archived: int = Field(0, description="Transcripts moved to the archive prefix")
+    transcripts_deleted: int = Field(0, description="Transcripts deleted from S3")
+    bulk_jobs_updated: int = Field(0, description="Bulk jobs stripped of transcripts or deleted")
+    errors: List[str] = Field(default_factory=list, description="Per-episode errors")
+    created_at: datetime = Field(..., description="Job creation timestamp")
+    updated_at: datetime = Field(..., description="Last update timestamp")
+    completed_at: Optional[datetime] = Field(None, description="Job completion timestamp")
+
+
+# Export Models
+class ExportFormat(str, Enum):
+    """Transcript file format inside an export archive."""
+    TXT = "txt"
+    JSON = "json"
+
+
+class ExportDelivery(str, Enum):
+    """How an export archive is delivered to the caller."""
+    STREAM = "stream"
+    S3 = "s3"
+
+
+class ExportRequest(BaseModel):
+    """Request model for exporting transcripts as a ZIP archive."""
+    podcast_ids: Optional[List[str]] = Field(None, description="Podcasts to include (default: all)")
+    published_after: Optional[datetime] = Field(None, description="Only include episodes published on or after this date")
+    published_before: Optional[datetime] = Field(None, description="Only include episodes published before this date")
+    format: ExportFormat = Field(ExportFormat.TXT, description="Transcript file format (txt/json)")
+    delivery: ExportDelivery = Field(ExportDelivery.STREAM, description="Stream the ZIP back or stage it in S3 with a presigned link")
+    exclude_ads: Optional[bool] = Field(
+        None,
+        description="Remove detected ad/sponsor segments from transcripts (default: AD_EXCLUDE_FROM_EXPORTS)"
+    )
+
+    class Config:
+        json_schema_extra = {
+            "example": {
+                "podcast_ids": ["pod_abc123"],
+                "published_after": "2024-01-01T00:00:00",
+                "published_before": "2025-01-01T00:00:00",
+                "format": "txt",
+                "delivery": "stream"
+            }
+        }
+
+
+class ExportResponse(BaseModel):
+    """Response model for an export staged in S3."""
+    export_id: str = Field(..., description="Unique export identifier")
+    s3_key: str = Field(..., description="S3 key of the staged archive")
+    download_url: str = Field(..., description="Presigned download URL")
+    expires_in: int = Field(..., description="Seconds until the download URL expires")
+    episode_count: int = Field(..., description="Number of transcripts in the archive")
+
+
+# Notification Models
+class DigestFrequency(str, Enum):
+    """How often a user receives transcription digests."""
+    IMMEDIATE = "immediate"
+    DAILY = "daily"
+    WEEKLY = "weekly"
+
+
+class NotificationPreferencesRequest(BaseModel):
+    """Request model for updating a user's notification preferences."""
+    email_enabled: bool = Field(True, description="Whether to send digest emails")
+    podcast_ids: Optional[List[str]] = Field(None, description="Podcasts to notify about (default: all subscribed)")
+    frequency: DigestFrequency = Field(DigestFrequency.DAILY, description="Digest frequency")
+
+
+class NotificationPreferencesResponse(BaseModel):
+    """Response model for a user's notification preferences."""
+    email: str = Field(..., description="Recipient email address")
+    email_enabled: bool = Field(..., description="Whether digest emails are sent")
+    podcast_ids: Optional[List[str]] = Field(None, description="Podcasts to notify about (None = all)")
+    frequency: DigestFrequency = Field(..., description="Digest frequency")
+    last_notified_at: Optional[datetime] = Field(None, description="When the last digest was sent")
+    updated_at: datetime = Field(..., description="Last update timestamp")
+
+
+class DigestResponse(BaseModel):
+    """A stored transcription digest."""
+    digest_id: str = Field(..., description="Digest identifier")
+    email: str = Field(..., description="Recipient email address")
+    frequency: DigestFrequency = Field(..., description="Frequency it was compiled for")
+    subject: str = Field(..., description="Email subject")
+    period_start: datetime = Field(..., description="Episodes transcribed after this time are included")
+    period_end: datetime = Field(..., description="...up to this time")
+    episode_count: int = Field(..., description="Episodes in the digest")
+    episode_ids: List[str] = Field(default_factory=list, description="Those episodes")
+    formats: List[str] = Field(default_factory=list, description="Stored renderings (markdown, html)")
+    delivered: bool = Field(..., description="Whether the email was handed to the backend")
+    created_at: datetime = Field(..., description="When the digest was compiled")
+
+
+class DigestListResponse(BaseModel):
+    """A user's digests, newest first."""
+    digests: List[DigestResponse]
+    total: int
+
+
+# Episode State Models
+class EpisodeStateUpdate(BaseModel):
+    """Playback and read state to record; omitted fields keep their value."""
+    position_seconds: Optional[float] = Field(None, ge=0, description="Playback position in seconds")
+    played: Optional[bool] = Field(None, description="Whether the episode has been played to the end")
+    transcript_read: Optional[bool] = Field(None, description="Whether the transcript has been read")
+
+
+class EpisodeStateResponse(BaseModel):
+    """A user's playback and read state for an episode."""
+    user_id: str = Field(..., description="User identifier")
+    episode_id: str = Field(..., description="Episode identifier")
+    podcast_id: str = Field(..., description="Podcast identifier")
+    position_seconds: float = Field(0, description="Last playback position in seconds")
+    played: bool = Field(False, description="Whether the episode has been played to the end")
+    transcript_read: bool = Field(False, description="Whether the transcript has been read")
+    transcript_read_at: Optional[datetime] = Field(None, description="When the transcript was marked read")
+    updated_at: datetime = Field(..., description="Last update timestamp")
+
+
+class EpisodeStateListResponse(BaseModel):
+    """A user's episode states, most recently updated first."""
+    states: List[EpisodeStateResponse]
+    total: int
+
+
+# Favorites Models
+class FavoriteEpisodeResponse(BaseModel):
+    """An episode a user has starred."""
+    user_id: str = Field(..., description="User identifier")
+    episode_id: str = Field(..., description="Episode identifier")
+    podcast_id: str = Field(..., description="Podcast identifier")
+    episode_title: Optional[str] = Field(None, description="Episode title when starred")
+    created_at: datetime = Field(..., description="When the episode was starred")
+
+
+class FavoriteEpisodeListResponse(BaseModel):
+    """A user's starred episodes, newest first."""
+    favorites: List[FavoriteEpisodeResponse]
+    total: int
+
+
+class TranscriptBookmarkCreate(BaseModel):
+    """A transcript passage to bookmark."""
+    episode_id: str = Field(..., description="Episode identifier")
+    start_seconds: float = Field(..., ge=0, description="Start of the passage in seconds")
+    end_seconds: float = Field(..., ge=0, description="End of the passage in seconds")
+    quote: str = Field(..., min_length=1, max_length=2000, description="Passage text")
+    note: Optional[str] = Field(None, max_length=2000, description="User's note")
+
+
+class TranscriptBookmarkResponse(BaseModel):
+    """A bookmarked transcript passage."""
+    bookmark_id: str = Field(..., description="Bookmark identifier")
+    user_id: str = Field(..., description="User identifier")
+    episode_id: str = Field(..., description="Episode identifier")
+    podcast_id: str = Field(..., description="Podcast identifier")
+    start_seconds: float = Field(..., description="Start of the passage in seconds")
+    end_seconds: float = Field(..., description="End of the passage in seconds")
+    quote: str = Field(..., description="Passage text")
+    note: Optional[str] = Field(None, description="User's note")
+    created_at: datetime = Field(..., description="When the passage was bookmarked")
+
+
+class TranscriptBookmarkListResponse(BaseModel):
+    """A user's bookmarked passages, newest first."""
+    bookmarks: List[TranscriptBookmarkResponse]
+    total: int
+
+
+# Share Link Models
+class ShareLinkCreate(BaseModel):
+    """Request to share an episode's transcript publicly."""
+    expires_in_hours: Optional[int] = Field(None, ge=1, le=24 * 365, description="Link lifetime (default: until revoked)")
+
+
+class ShareLinkResponse(BaseModel):
+    """A public transcript link."""
+    token: str = Field(..., description="Share token")
+    episode_id: str = Field(..., description="Episode identifier")
+    url: str = Field(..., description="Public URL of the shared transcript")
+    created_at: datetime = Field(..., description="When the link was created")
+    expires_at: Optional[datetime] = Field(None, description="When the link stops working")
+    revoked_at: Optional[datetime] = Field(None, description="When the link was revoked")
+
+
+class ShareLinkListResponse(BaseModel):
+    """An episode's share links, newest first."""
+    links: List[ShareLinkResponse]
+    total: int
+
+
+class SharedTranscriptResponse(BaseModel):
+    """A transcript served through a share link."""
+    episode_id: str = Field(..., description="Episode identifier")
+    episode_title: str = Field(..., description="Episode title")
+    podcast_title: str = Field(..., description="Podcast title")
+    published_date: Optional[datetime] = Field(None, description="Episode publication date")
+    transcript: str = Field(..., description="Transcript text")
+    expires_at: Optional[datetime] = Field(None, description="When the link stops working")
+
+
+# Runtime Settings Models
+class RuntimeSettingsUpdate(BaseModel):
+    """Runtime settings to change on a running server; omitted fields keep their value."""
+    log_level: Optional[str] = Field(None, description="DEBUG, INFO, WARNING, ERROR or CRITICAL")
+    bulk_schedule_poll_seconds: Optional[int] = Field(None, ge=1, description="How often scheduled bulk jobs are checked")
+    transcription_workers: Optional[int] = Field(None, ge=1, description="Concurrent transcriptions (bulk and single episode)")
+    whisper_service_url: Optional[str] = Field(None, description="Single Whisper container URL")
+    whisper_service_urls: Optional[str] = Field(None, description="Comma-separated Whisper pool; overrides whisper_service_url")
+    whisper_max_concurrent_per_backend: Optional[int] = Field(None, ge=1, description="Requests per Whisper backend")
+    whisper_download_host_daily_bytes: Optional[int] = Field(None, ge=0, description="Daily download cap per audio host (0 = none)")
+    whisper_download_host_daily_limits: Optional[str] = Field(None, description="Per-host daily caps, host=bytes,...")
+
+
+class RuntimeSettingsResponse(BaseModel):
+    """Current runtime settings and what the last change applied."""
+    settings: Dict[str, Any] = Field(..., description="Current values of the runtime settings")
+    changed: List[str] = Field(default_factory=list, description="Settings changed by this request")
+
+
+# One-off Transcription Models
+class TranscribeTaskSource(str, Enum):
+    """Where a one-off transcription's audio came from."""
+    UPLOAD = "upload"
+    S3 = "s3"
+    URL = "url"
+
+
+class TranscribeUrlRequest(BaseModel):
+    """Request to transcribe audio from a URL."""
+    audio_url: HttpUrl = Field(..., description="Audio URL (e.g. presigned)")
+    priority: JobPriority = Field(JobPriority.HIGH, description="Queue priority")
+
+
+class TranscribeTaskResponse(BaseModel):
+    """A one-off transcription task and, once completed, its transcript."""
+    task_id: str = Field(..., description="Task identifier")
+    source: TranscribeTaskSource = Field(..., description="How the audio was provided")
+    description: str = Field(..., description="File name, S3 key or URL of the audio")
+    status: TranscriptStatus = Field(..., description="pending, processing, completed or failed")
+    queue_position: Optional[int] = Field(None, description="Position in the Whisper queue while waiting")
+    transcript_s3_key: Optional[str] = Field(None, description="S3 key of the transcript")
+    total_words: Optional[int] = Field(None, description="Words in the transcript")
+    transcript: Optional[str] = Field(None, description="Transcript text (completed tasks)")
+    error_message: Optional[str] = Field(None, description="Why the task failed")
+    created_at: datetime = Field(..., description="When the task was created")
+    updated_at: datetime = Field(..., description="Last status change")
+
+
+# Pipeline Callback Models
+class TranscriptCallback(BaseModel):
+    """Merge result POSTed by the merge Lambda to CALLBACK_URL."""
+    event: str = Field(..., description="transcript.completed or transcript.failed")
+    episode_id: str = Field(..., description="Episode identifier")
+    status: str = Field(..., description="Merge status (completed or error)")
+    transcript_s3_key: Optional[str] = Field(None, description="S3 key of the merged transcript")
+    total_words: Optional[int] = Field(None, description="Words in the transcript")
+    error_message: Optional[str] = Field(None, description="Why the merge failed")
+    timestamp: datetime = Field(..., description="When the merge finished")
+
+
+class TranscriptCallbackResponse(BaseModel):
+    """Result of handling a pipeline callback."""
+    episode_id: str = Field(..., description="Episode identifier")
+    handled: bool = Field(..., description="False when the callback was a duplicate delivery")
+
+
+# Workspace Models
+class WorkspaceCreate(BaseModel):
+    """Request to create a workspace."""
+    name: str = Field(..., min_length=1, max_length=200, description="Display name")
+
+
+class WorkspaceResponse(BaseModel):
+    """A workspace."""
+    workspace_id: str = Field(..., description="Workspace identifier")
+    name: str = Field(..., description="Display name")
+    created_at: datetime = Field(..., description="When the workspace was created")
+    storage: Optional[StorageLocation] = Field(None, description="Storage location the workspace's podcasts use by default")
+    kms_key_id: Optional[str] = Field(None, description="KMS key private podcasts' transcripts are encrypted with")
+
+
+class WorkspaceListResponse(BaseModel):
+    """All workspaces, oldest first."""
+    workspaces: List[WorkspaceResponse]
+    total: int
+
+
+class ApiKeyCreate(BaseModel):
+    """Request to issue a workspace API key."""
+    name: str = Field(..., min_length=1, max_length=200, description="What the key is for")
+
+
+class ApiKeyResponse(BaseModel):
+    """A workspace API key (without the secret)."""
+    key_id: str = Field(..., description="Key identifier")
+    workspace_id: str = Field(..., description="Workspace the key belongs to")
+    name: str = Field(..., description="What the key is for")
+    key_prefix: str = Field(..., description="First characters of the key, to tell keys apart")
+    created_at: datetime = Field(..., description="When the key was issued")
+    last_used_at: Optional[datetime] = Field(None, description="Last request made with the key")
+    revoked_at: Optional[datetime] = Field(None, description="When the key was revoked")
+
+
+class ApiKeyCreatedResponse(ApiKeyResponse):
+    """A newly issued key; the secret is only returned here."""
+    api_key: str = Field(..., description="Send as X-API-Key; store it now, it can't be shown again")
+
+
+class ApiKeyListResponse(BaseModel):
+    """A workspace's keys, newest first."""
+    keys: List[ApiKeyResponse]
+    total: int
+
+
+# Audit Log Models
+class AuditLogEntry(BaseModel):
+    """One recorded action."""
+    timestamp: datetime = Field(..., description="When the action happened")
+    actor: str = Field(..., description='"admin", "key:<hash>" (API key holder) or "anonymous"')
+    request_id: Optional[str] = Field(None, description="X-Request-ID of the request that made the change")
+    action: str = Field(..., description='What happened, e.g. "podcast.subscribed"')
+    target_type: str = Field(..., description="Kind of object acted on")
+    target_id: str = Field(..., description="ID of the object acted on")
+    details: Dict[str, Any] = Field(default_factory=dict, description="Extra context")
+
+
+class AuditLogListResponse(BaseModel):
+    """Audit log entries, newest first."""
+    entries: List[AuditLogEntry]
+    total: int
+    has_more: bool = False
+    next_cursor: Optional[str] = Field(None, description="Cursor for the next page, if any")
+
+
+# Reconciliation Models
+class ReconciliationRepairs(BaseModel):
+    """What a repairing reconciliation run fixed."""
+    reuploaded: int = Field(0, description="Missing or mismatched transcripts stored again from MongoDB")
+    marked_failed: int = Field(0, description="Episodes marked failed because their transcript is gone")
+    orphans_moved: int = Field(0, description="Orphaned objects moved under RECONCILE_ORPHAN_PREFIX")
+
+
+class ReconciliationReport(BaseModel):
+    """Result of a MongoDB/S3 consistency check."""
+    run_id: str = Field(..., description="Run identifier")
+    trigger: str = Field(..., description="manual or scheduled")
+    repair: bool = Field(..., description="Whether mismatches were repaired")
+    verify: bool = Field(False, description="Whether transcript checksums were verified")
+    started_at: datetime = Field(..., description="When the run started")
+    finished_at: datetime = Field(..., description="When the run finished")
+    episodes_checked: int = Field(..., description="Completed episodes checked against S3")
+    objects_checked: int = Field(..., description="Objects under transcripts/ checked against MongoDB")
+    missing_transcripts: int = Field(..., description="Completed episodes whose transcript_s3_key doesn't exist")
+    missing_episode_ids: List[str] = Field(default_factory=list, description="Those episodes (first 1000)")
+    orphaned_objects: int = Field(..., description="Objects without an episode or transcription task")
+    orphaned_keys: List[str] = Field(default_factory=list, description="Those objects (first 1000)")
+    checksums_verified: Optional[int] = Field(None, description="Transcripts whose checksum was compared with transcript_sha256 (verify runs only)")
+    checksum_mismatches: Optional[int] = Field(None, description="Transcripts whose checksum doesn't match (verify runs only)")
+    mismatched_episode_ids: List[str] = Field(default_factory=list, description="Those episodes (first 1000)")
+    repaired: Optional[ReconciliationRepairs] = Field(None, description="Repairs made (repair runs only)")
+
+
+class ReconciliationListResponse(BaseModel):
+    """Recent reconciliation runs, newest first."""
+    runs: List[ReconciliationReport]
+    total: int
+
+
+class ReindexRunResponse(BaseModel):
+    """Progress of a transcript search index rebuild."""
+    run_id: str = Field(..., description="Run identifier")
+    podcast_id: Optional[str] = Field(None, description="Podcast rebuilt, or null for all podcasts")
+    status: str = Field(..., description="running, completed or failed")
+    total: int = Field(..., description="Completed episodes in scope when the run started")
+    processed: int = Field(0, description="Episodes handled so far")
+    indexed: int = Field(0, description="Episodes indexed")
+    skipped: int = Field(0, description="Episodes without transcript text")
+    failed: int = Field(0, description="Episodes whose transcript couldn't be read or indexed")
+    failed_episode_ids: List[str] = Field(default_factory=list, description="Those episodes (first 1000)")
+    started_at: datetime = Field(..., description="When the run started")
+    updated_at: datetime = Field(..., description="When progress was last recorded")
+    finished_at: Optional[datetime] = Field(None, description="When the run finished")
+    error: Optional[str] = Field(None, description="Why the run failed")
@@ -0,0 +1,97 @@
+"""Opaque cursor pagination for large collections.
+
+A cursor encodes the sort key and _id of the last item on a page. The next
+page is fetched with a range query on (sort key, _id) instead of $skip, so
+cost stays constant however deep the client pages. The _id keeps its type:
+jobs and audit entries have ObjectIds, episodes the sha256 string IDs the
+poll Lambda and bulk jobs give them.
+"""
+import base64
+import json
+from datetime import datetime
+from typing import Any, Dict, Optional, Tuple, Union
+
+from bson import ObjectId
+from bson.errors import InvalidId
+
+from app.validation import RequestValidationFailure
+
+
+DocId = Union[ObjectId, str]
+
+
+def encode_cursor(sort_value: Optional[datetime], doc_id: DocId) -> str:
+    """
+    Encode the position after a document as an opaque cursor.
+
+    Args:
+        sort_value: Value of the sort field on the last document
+        doc_id: _id of the last document
+
+    Returns:
+        URL-safe cursor string
+    """
+    payload = {
+        "v": sort_value.isoformat() if sort_value else None,
+        "id": str(doc_id),
+        "t": "oid" if isinstance(doc_id, ObjectId) else "str",
+    }
+    raw = json.dumps(payload, separators=(",", ":")).encode("utf-8")
+    return base64.urlsafe_b64encode(raw).decode("ascii").rstrip("=")
+
+
+def decode_cursor(cursor: str) -> Tuple[Optional[datetime], DocId]:
+    """
+    Decode a cursor produced by encode_cursor.
+
+    Raises:
+        RequestValidationFailure: If the cursor is malformed
+    """
+    try:
+        padded = cursor + "=" * (-len(cursor) % 4)
+        payload = json.loads(base64.urlsafe_b64decode(padded.encode("ascii")))
+        sort_value = datetime.fromisoformat(payload["v"]) if payload["v"] else None
+        # Cursors issued before the type was recorded only had ObjectIds
+        if payload.get("t", "oid") == "str":
+            return sort_value, str(payload["id"])
+        return sort_value, ObjectId(payload["id"])
+    except (ValueError, KeyError, TypeError, InvalidId) as e:
+        raise RequestValidationFailure.single(
+            "cursor", "invalid_cursor", "Cursor is malformed or expired"
+        ) from e
+
+
+def seek_after(field: str, cursor: str, ascending: bool = False) -> Dict[str, Any]:
+    """
+    Build a query matching documents after the cursor for a
+    {field: -1, _id: -1} sort, or {field: 1, _id: 1} when ascending.
+
+    Mongo sorts null below every value, so null sort values come last when
+    descending and first when ascending; among themselves they are paged
+    by _id.
+    """
+    sort_value, doc_id = decode_cursor(cursor)
+    if ascending:
+        if sort_value is None:
+            return {
+                "$or": [
+                    {field: None, "_id": {"$gt": doc_id}},
+                    {field: {"$ne": None}},
+                ]
+            }
+        return {
+            "$or": [
+                {field: {"$gt": sort_value}},
+                {field: sort_value, "_id": {"$gt": doc_id}},
+            ]
+        }
+
+    if sort_value is None:
+        return {field: None, "_id": {"$lt": doc_id}}
+    return {
+        "$or": [
+            {field: {"$lt": sort_value}},
+            {field: sort_value, "_id": {"$lt": doc_id}},
+            {field: None},
+        ]
+    }
@@ -3,10 +3,45 @@ from .podcasts import router as podcasts_router
 from .episodes import router as episodes_router
 from .dev_bulk_transcribe import router as dev_bulk_transcribe_router
 from .transcription import router as transcription_router
+from .transcribe import router as transcribe_router
+from .export import router as export_router
+from .feeds import router as feeds_router
+from .notifications import router as notifications_router
+from .costs import router as costs_router
+from .quota import router as quota_router
+from .admin import router as admin_router
+from .admin_ops import router as admin_ops_router
+from .callbacks import router as callbacks_router
+from .asr_callbacks import router as asr_callbacks_router
+from .user_state import router as user_state_router
+from .favorites import router as favorites_router
+from .share import router as share_router, public_router as share_public_router
+from .audit import router as audit_router
+from .analytics import router as analytics_router
+from .people import router as people_router
+from .external import router as external_router
 
 __all__ = [
     "podcasts_router",
     "episodes_router",
     "dev_bulk_transcribe_router",
-    "transcription_router"
+    "transcription_router",
+    "transcribe_router",
+    "export_router",
+    "feeds_router",
+    "notifications_router",
+    "costs_router",
+    "quota_router",
+    "admin_router",
+    "admin_ops_router",
+    "callbacks_router",
+    "asr_callbacks_router",
+    "user_state_router",
+    "favorites_router",
+    "share_router",
+    "share_public_router",
+    "audit_router",
+    "analytics_router",
+    "people_router",
+    "external_router",
 ]
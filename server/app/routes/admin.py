@@ -0,0 +1,368 @@
+"""Admin endpoints for operating a running server."""
+import hmac
+import logging
+from typing import Any, Dict, Optional
+from fastapi import APIRouter, HTTPException, Depends, Header, Query, status
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.config import settings
+from app.database import get_database
+from app.diagnostics import debug_state
+from app.models.schemas import (
+    ApiKeyCreate,
+    ApiKeyCreatedResponse,
+    ApiKeyListResponse,
+    ApiKeyResponse,
+    DownloadUsageResponse,
+    RuntimeSettingsResponse,
+    RuntimeSettingsUpdate,
+    StorageLocationUpdate,
+    SuccessResponse,
+    WorkspaceCreate,
+    WorkspaceKmsKeyUpdate,
+    WorkspaceListResponse,
+    WorkspaceResponse,
+)
+from app.runtime_settings import apply_runtime_settings, current_runtime_settings, reload_runtime_settings
+from app.services.audit_service import AuditService
+from app.services.download_usage import DownloadUsageService
+from app.services.storage_locations import location_document
+from app.services.workspace_service import DEFAULT_WORKSPACE_ID, WorkspaceService
+from app.workspaces import scoped
+
+logger = logging.getLogger(__name__)
+
+
+async def require_admin_key(x_admin_key: Optional[str] = Header(None)):
+    """Allow the request only with the configured ADMIN_API_KEY."""
+    if not settings.admin_api_key:
+        raise HTTPException(status_code=status.HTTP_404_NOT_FOUND, detail="Admin endpoints are disabled")
+    if not x_admin_key or not hmac.compare_digest(x_admin_key, settings.admin_api_key):
+        raise HTTPException(status_code=status.HTTP_401_UNAUTHORIZED, detail="Invalid admin key")
+
+
+router = APIRouter(prefix="/admin", tags=["admin"], dependencies=[Depends(require_admin_key)])
+
+
+@router.get("/runtime-settings", response_model=RuntimeSettingsResponse)
+async def get_runtime_settings():
+    """
+    Get the settings that can be changed without a restart.
+
+    Returns:
+        Current runtime settings
+    """
+    return RuntimeSettingsResponse(settings=current_runtime_settings())
+
+
+@router.patch("/runtime-settings", response_model=RuntimeSettingsResponse)
+async def update_runtime_settings(
+    request: RuntimeSettingsUpdate,
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """
+    Change runtime settings on this server process.
+
+    Changes last until the next restart or reload; update the environment or
+    config file as well to keep them.
+
+    Args:
+        request: Settings to change
+        db: Database instance
+
+    Returns:
+        Current runtime settings and the names of those that changed
+
+    Raises:
+        HTTPException: If the new values are invalid
+    """
+    try:
+        changed = await apply_runtime_settings(request.model_dump(exclude_none=True))
+        if changed:
+            await AuditService(db).record(
+                "runtime_settings.updated", "runtime_settings", "runtime", details={"changed": changed}
+            )
+        return RuntimeSettingsResponse(settings=current_runtime_settings(), changed=changed)
+
+    except ValueError as e:
+        raise HTTPException(status_code=status.HTTP_400_BAD_REQUEST, detail=str(e))
+    except Exception as e:
+        logger.error(f"Error updating runtime settings: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to update runtime settings"
+        )
+
+
+@router.post("/runtime-settings/reload", response_model=RuntimeSettingsResponse)
+async def reload_settings(db: AsyncIOMotorDatabase = Depends(get_database)):
+    """
+    Re-read runtime settings from the environment, .env and CONFIG_FILE
+    (same as sending the process SIGHUP).
+
+    Args:
+        db: Database instance
+
+    Returns:
+        Current runtime settings and the names of those that changed
+
+    Raises:
+        HTTPException: If the reloaded configuration is invalid
+    """
+    try:
+        changed = await reload_runtime_settings()
+        if changed:
+            await AuditService(db).record(
+                "runtime_settings.reloaded", "runtime_settings", "runtime", details={"changed": changed}
+            )
+        return RuntimeSettingsResponse(settings=current_runtime_settings(), changed=changed)
+
+    except ValueError as e:
+        raise HTTPException(status_code=status.HTTP_400_BAD_REQUEST, detail=str(e))
+    except Exception as e:
+        logger.error(f"Error reloading runtime settings: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to reload runtime settings"
+        )
+
+
+@router.get("/download-usage", response_model=DownloadUsageResponse)
+async def get_download_usage(
+    days: int = Query(7, ge=1, le=90, description="Days to report, today included"),
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """
+    Report audio bytes downloaded per host and UTC day, with today's caps
+    (WHISPER_DOWNLOAD_HOST_DAILY_BYTES / WHISPER_DOWNLOAD_HOST_DAILY_LIMITS).
+
+    Args:
+        days: Days to report, today included
+        db: Database instance
+
+    Returns:
+        Usage per host and day, newest day first
+    """
+    return await DownloadUsageService(db).report(days)
+
+
+@router.get("/debug/state")
+async def get_debug_state(
+    start_tracemalloc: bool = Query(False, description="Start tracing allocations for later snapshots")
+) -> Dict[str, Any]:
+    """
+    Dump asyncio tasks, running bulk jobs, worker slots and memory usage,
+    for profiling memory growth during large bulk jobs.
+
+    Args:
+        start_tracemalloc: Start allocation tracing if it isn't running
+
+    Returns:
+        Process state
+    """
+    return debug_state(start_tracemalloc)
+
+
+@router.post("/workspaces", response_model=WorkspaceResponse, status_code=status.HTTP_201_CREATED)
+async def create_workspace(
+    request: WorkspaceCreate,
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """Create a workspace; issue it a key with POST /admin/workspaces/{id}/keys."""
+    workspace = await WorkspaceService(db).create_workspace(request.name)
+    await AuditService(db).record(
+        "workspace.created", "workspace", workspace["workspace_id"], workspace["workspace_id"], {"name": request.name}
+    )
+    return WorkspaceResponse(**workspace)
+
+
+@router.get("/workspaces", response_model=WorkspaceListResponse)
+async def list_workspaces(db: AsyncIOMotorDatabase = Depends(get_database)):
+    """List all workspaces, oldest first."""
+    workspaces = await WorkspaceService(db).list_workspaces()
+    return WorkspaceListResponse(
+        workspaces=[WorkspaceResponse(**workspace) for workspace in workspaces],
+        total=len(workspaces)
+    )
+
+
+@router.get("/workspaces/{workspace_id}", response_model=WorkspaceResponse)
+async def get_workspace(
+    workspace_id: str,
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """Get a workspace."""
+    return WorkspaceResponse(**await _get_workspace(db, workspace_id))
+
+
+@router.put("/workspaces/{workspace_id}/storage", response_model=WorkspaceResponse)
+async def set_workspace_storage(
+    workspace_id: str,
+    request: StorageLocationUpdate,
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """
+    Pin a workspace to a storage location from STORAGE_LOCATIONS.
+
+    Its podcasts not pinned to a location of their own use this one for new
+    transcriptions. A null location unpins the workspace.
+
+    Args:
+        workspace_id: Workspace identifier
+        request: Location name
+        db: Database instance
+
+    Returns:
+        The updated workspace
+
+    Raises:
+        HTTPException: If the workspace doesn't exist or the location isn't configured
+    """
+    await _get_workspace(db, workspace_id)
+    storage = location_document(request.location) if request.location else None
+    if request.location and not storage:
+        raise HTTPException(
+            status_code=status.HTTP_400_BAD_REQUEST,
+            detail=f"Unknown storage location '{request.location}'"
+        )
+    update = {"$set": {"storage": storage}} if storage else {"$unset": {"storage": ""}}
+    await db.workspaces.update_one({"workspace_id": workspace_id}, update)
+    await AuditService(db).record(
+        "workspace.storage_updated", "workspace", workspace_id, workspace_id, {"location": request.location}
+    )
+    return WorkspaceResponse(**await _get_workspace(db, workspace_id))
+
+
+@router.put("/workspaces/{workspace_id}/kms-key", response_model=WorkspaceResponse)
+async def set_workspace_kms_key(
+    workspace_id: str,
+    request: WorkspaceKmsKeyUpdate,
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """
+    Set the KMS key the workspace's private podcasts are encrypted with.
+
+    Transcripts already encrypted keep the key they were written with, so
+    the API needs kms:Decrypt on old keys until they are rewritten.
+
+    Args:
+        workspace_id: Workspace identifier
+        request: KMS key ID, ARN or alias, or null to clear it
+        db: Database instance
+
+    Returns:
+        The updated workspace
+
+    Raises:
+        HTTPException: If the workspace doesn't exist, or the key is cleared
+            while it has private podcasts
+    """
+    await _get_workspace(db, workspace_id)
+    if not request.kms_key_id:
+        private = await db.podcasts.find_one(
+            scoped({"private": True}, workspace_id), {"podcast_id": 1}
+        )
+        if private:
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail=f"Podcast '{private['podcast_id']}' is private; make it public before clearing the key"
+            )
+    update = {"$set": {"kms_key_id": request.kms_key_id}} if request.kms_key_id else {"$unset": {"kms_key_id": ""}}
+    await db.workspaces.update_one({"workspace_id": workspace_id}, update)
+    await AuditService(db).record(
+        "workspace.kms_key_updated", "workspace", workspace_id, workspace_id, {"kms_key_id": request.kms_key_id}
+    )
+    return WorkspaceResponse(**await _get_workspace(db, workspace_id))
+
+
+@router.delete("/workspaces/{workspace_id}", response_model=SuccessResponse)
+async def delete_workspace(
+    workspace_id: str,
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """
+    Delete a workspace and its API keys.
+
+    Args:
+        workspace_id: Workspace identifier
+        db: Database instance
+
+    Returns:
+        Success message
+
+    Raises:
+        HTTPException: If the workspace doesn't exist, is the default
+            workspace, or still has podcasts
+    """
+    if workspace_id == DEFAULT_WORKSPACE_ID:
+        raise HTTPException(status_code=status.HTTP_409_CONFLICT, detail="The default workspace can't be deleted")
+    await _get_workspace(db, workspace_id)
+    if await db.podcasts.count_documents(scoped({}, workspace_id), limit=1):
+        raise HTTPException(
+            status_code=status.HTTP_409_CONFLICT,
+            detail="Delete the workspace's podcasts first"
+        )
+
+    await WorkspaceService(db).delete_workspace(workspace_id)
+    await AuditService(db).record("workspace.deleted", "workspace", workspace_id, workspace_id)
+    logger.info(f"Deleted workspace {workspace_id}")
+    return {"message": f"Deleted workspace '{workspace_id}'", "data": {"workspace_id": workspace_id}}
+
+
+@router.post(
+    "/workspaces/{workspace_id}/keys",
+    response_model=ApiKeyCreatedResponse,
+    status_code=status.HTTP_201_CREATED
+)
+async def create_workspace_key(
+    workspace_id: str,
+    request: ApiKeyCreate,
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """Issue an API key for a workspace. The key is only shown in this response."""
+    await _get_workspace(db, workspace_id)
+    key = await WorkspaceService(db).create_key(workspace_id, request.name)
+    await AuditService(db).record(
+        "api_key.created", "api_key", key["key_id"], workspace_id,
+        {"name": request.name, "key_prefix": key["key_prefix"]}
+    )
+    return ApiKeyCreatedResponse(**key)
+
+
+@router.get("/workspaces/{workspace_id}/keys", response_model=ApiKeyListResponse)
+async def list_workspace_keys(
+    workspace_id: str,
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """List a workspace's keys, including revoked ones."""
+    await _get_workspace(db, workspace_id)
+    keys = await WorkspaceService(db).list_keys(workspace_id)
+    return ApiKeyListResponse(keys=[ApiKeyResponse(**key) for key in keys], total=len(keys))
+
+
+@router.delete("/workspaces/{workspace_id}/keys/{key_id}", response_model=SuccessResponse)
+async def revoke_workspace_key(
+    workspace_id: str,
+    key_id: str,
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """Revoke a workspace API key; it stops working immediately."""
+    if not await WorkspaceService(db).revoke_key(workspace_id, key_id):
+        raise HTTPException(
+            status_code=status.HTTP_404_NOT_FOUND,
+            detail="API key not found or already revoked"
+        )
+    logger.info(f"Revoked API key {key_id} of workspace {workspace_id}")
+    await AuditService(db).record("api_key.revoked", "api_key", key_id, workspace_id)
+    return {"message": f"Revoked API key '{key_id}'", "data": {"key_id": key_id}}
+
+
+async def _get_workspace(db: AsyncIOMotorDatabase, workspace_id: str) -> dict:
+    """Find a workspace or raise 404."""
+    workspace = await WorkspaceService(db).get_workspace(workspace_id)
+    if not workspace:
+        raise HTTPException(
+            status_code=status.HTTP_404_NOT_FOUND,
+            detail=f"Workspace '{workspace_id}' not found"
+        )
+    return workspace
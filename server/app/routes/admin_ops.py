@@ -0,0 +1,384 @@
+"""Admin endpoints for repairing episodes, bulk jobs and transcripts."""
+import logging
+from datetime import datetime
+from typing import Optional
+from fastapi import APIRouter, BackgroundTasks, HTTPException, Depends, Query, status
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.database import get_database
+from app.models.schemas import (
+    JobPriority, ReconciliationListResponse, ReconciliationReport, ReindexRunResponse, SuccessResponse
+)
+from app.routes.admin import require_admin_key
+from app.services.admin_ops import AdminOpsService, JobRunningError
+from app.services.audit_service import AuditService
+from app.services.lambda_service import lambda_service
+from app.services.orchestration_service import get_orchestration_service
+from app.services.reconciliation import ReconciliationService
+from app.services.reindex_service import ReindexRunningError, ReindexService
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter(prefix="/api/admin", tags=["admin"], dependencies=[Depends(require_admin_key)])
+
+
+@router.post("/episodes/requeue", response_model=SuccessResponse)
+async def requeue_stuck_episodes(
+    background_tasks: BackgroundTasks,
+    older_than_minutes: int = Query(120, ge=1, description="Processing this long without an update counts as stuck"),
+    limit: int = Query(100, ge=1, le=1000, description="Maximum episodes to requeue"),
+    dry_run: bool = Query(False, description="Only list the stuck episodes"),
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """
+    Requeue episodes stuck in processing, e.g. after a crash or a lost
+    pipeline callback.
+
+    Each episode is reset to pending and transcribed again through the
+    transcription queue at normal priority.
+
+    Args:
+        background_tasks: FastAPI background tasks
+        older_than_minutes: Minutes without an update before an episode counts as stuck
+        limit: Maximum episodes to requeue
+        dry_run: Only list the episodes
+        db: Database instance
+
+    Returns:
+        IDs of the requeued (or, with dry_run, stuck) episodes
+    """
+    try:
+        service = AdminOpsService(db)
+        episodes = await service.find_stuck_episodes(older_than_minutes, limit)
+        episode_ids = [episode["episode_id"] for episode in episodes]
+        if dry_run:
+            return {
+                "message": f"Found {len(episode_ids)} stuck episode(s)",
+                "data": {"episode_ids": episode_ids, "dry_run": True}
+            }
+
+        await service.reset_episodes(episode_ids)
+        orchestration_service = get_orchestration_service()
+
+        async def run_transcription(episode: dict):
+            try:
+                await orchestration_service.transcribe_episode(
+                    episode_id=episode["episode_id"],
+                    audio_url=episode["audio_url"],
+                    priority=JobPriority.NORMAL
+                )
+            except Exception as e:
+                logger.error(f"Requeued transcription failed for {episode['episode_id']}: {e}")
+
+        for episode in episodes:
+            if episode.get("audio_url"):
+                background_tasks.add_task(run_transcription, episode)
+            await AuditService(db).record(
+                "episode.requeued", "episode", episode["episode_id"],
+                details={"older_than_minutes": older_than_minutes}
+            )
+
+        logger.info(f"Requeued {len(episode_ids)} stuck episode(s)")
+        return {
+            "message": f"Requeued {len(episode_ids)} stuck episode(s)",
+            "data": {"episode_ids": episode_ids, "dry_run": False}
+        }
+
+    except Exception as e:
+        logger.error(f"Error requeueing stuck episodes: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to requeue stuck episodes"
+        )
+
+
+@router.post("/jobs/{job_id}/force-complete", response_model=SuccessResponse)
+async def force_complete_job(
+    job_id: str,
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """
+    Mark a bulk job completed, e.g. one left running by a crashed process.
+
+    Episodes it never finished are marked failed and the job's counters are
+    recomputed. Jobs still running in this process must be cancelled first.
+
+    Args:
+        job_id: Bulk job identifier
+        db: Database instance
+
+    Returns:
+        The job's recomputed counters
+
+    Raises:
+        HTTPException: If the job doesn't exist or is running here
+    """
+    try:
+        fields = await AdminOpsService(db).force_complete_job(job_id)
+        if fields is None:
+            raise HTTPException(status_code=status.HTTP_404_NOT_FOUND, detail="Job not found")
+
+        await AuditService(db).record("bulk_job.force_completed", "bulk_job", job_id, details=fields)
+        return {"message": f"Force-completed job '{job_id}'", "data": {"job_id": job_id, **fields}}
+
+    except JobRunningError as e:
+        raise HTTPException(status_code=status.HTTP_409_CONFLICT, detail=str(e))
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error force-completing job {job_id}: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to force-complete job"
+        )
+
+
+@router.post("/jobs/{job_id}/recompute", response_model=SuccessResponse)
+async def recompute_job_counters(
+    job_id: str,
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """
+    Recompute a bulk job's episode counters, progress and actual cost from
+    its per-episode state.
+
+    Args:
+        job_id: Bulk job identifier
+        db: Database instance
+
+    Returns:
+        The recomputed counters
+
+    Raises:
+        HTTPException: If the job doesn't exist
+    """
+    try:
+        fields = await AdminOpsService(db).recompute_job_counters(job_id)
+        if fields is None:
+            raise HTTPException(status_code=status.HTTP_404_NOT_FOUND, detail="Job not found")
+
+        await AuditService(db).record("bulk_job.recomputed", "bulk_job", job_id, details=fields)
+        return {"message": f"Recomputed counters of job '{job_id}'", "data": {"job_id": job_id, **fields}}
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error recomputing job {job_id}: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to recompute job counters"
+        )
+
+
+@router.post("/transcripts/relink", response_model=SuccessResponse)
+async def relink_orphaned_transcripts(
+    dry_run: bool = Query(False, description="Only report what would be re-linked"),
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """
+    Re-link final transcripts in S3 to episodes that don't reference them.
+
+    Scans transcripts/<episode_id>/final.txt and marks the matching episodes
+    completed with that transcript. Transcripts whose episode no longer
+    exists are reported, not deleted.
+
+    Args:
+        dry_run: Only report
+        db: Database instance
+
+    Returns:
+        Scan counts and affected episode IDs
+    """
+    try:
+        result = await AdminOpsService(db).relink_transcripts(dry_run)
+        if not dry_run:
+            for episode_id in result["relinked_episode_ids"]:
+                await AuditService(db).record("transcript.relinked", "episode", episode_id)
+
+        verb = "Would re-link" if dry_run else "Re-linked"
+        return {
+            "message": f"{verb} {result['relinked']} of {result['scanned']} transcript(s)",
+            "data": {**result, "dry_run": dry_run}
+        }
+
+    except Exception as e:
+        logger.error(f"Error re-linking transcripts: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to re-link transcripts"
+        )
+
+
+@router.post("/podcasts/{podcast_id}/replay-feed", response_model=SuccessResponse)
+async def replay_feed_snapshots(
+    podcast_id: str,
+    replay_from: datetime = Query(..., description="Replay snapshots taken from this time (UTC if no offset)"),
+    replay_to: Optional[datetime] = Query(None, description="Replay snapshots taken before this time (default now)"),
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """
+    Recover episodes a poll missed by re-processing the podcast's archived
+    feed snapshots (FEED_SNAPSHOTS_ENABLED on the poll Lambda) in a window.
+
+    Snapshots are processed oldest first like live polls, so only episodes
+    that don't exist yet are added. Recovered episodes are left pending and
+    aren't transcribed automatically.
+
+    Args:
+        podcast_id: Podcast to replay
+        replay_from: Start of the window
+        replay_to: End of the window
+        db: Database instance
+
+    Returns:
+        Snapshot and episode counts and the recovered episodes
+
+    Raises:
+        HTTPException: If the podcast doesn't exist or the window is invalid
+    """
+    try:
+        podcast = await db.podcasts.find_one({"podcast_id": podcast_id}, {"title": 1})
+        if not podcast:
+            raise HTTPException(status_code=status.HTTP_404_NOT_FOUND, detail="Podcast not found")
+
+        response = await lambda_service.invoke_poll_lambda(
+            podcast_id=podcast_id, replay_from=replay_from, replay_to=replay_to
+        )
+        if response.get("statusCode") in (status.HTTP_400_BAD_REQUEST, status.HTTP_404_NOT_FOUND):
+            raise HTTPException(status_code=response["statusCode"], detail=response.get("message"))
+
+        results = response.get("podcast_results") or [{}]
+        snapshots = results[0].get("snapshots_replayed", 0)
+        episodes = results[0].get("episodes", [])
+        await AuditService(db).record(
+            "podcast.feed_replayed", "podcast", podcast_id,
+            details={"snapshots": snapshots, "new_episodes": len(episodes)}
+        )
+        return {
+            "message": f"Replayed {snapshots} feed snapshot(s) of '{podcast.get('title')}', "
+                       f"recovered {len(episodes)} episode(s)",
+            "data": {
+                "snapshots_replayed": snapshots,
+                "new_episodes": len(episodes),
+                "episodes": episodes,
+                "errors": response.get("errors", []),
+            }
+        }
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error replaying feed snapshots of {podcast_id}: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to replay feed snapshots"
+        )
+
+
+@router.post("/reconcile", response_model=ReconciliationReport)
+async def reconcile_storage(
+    repair: bool = Query(False, description="Repair mismatches instead of only reporting them"),
+    verify: bool = Query(False, description="Also compare transcript checksums with transcript_sha256"),
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """
+    Check that completed episodes' transcripts exist in S3 and that every
+    object under transcripts/ belongs to an episode or transcription task.
+    With verify, each transcript's checksum in S3 is compared with the
+    episode's transcript_sha256 as well (one request per transcript).
+
+    With repair, missing or mismatched transcripts are stored again from
+    MongoDB when a copy exists (otherwise the episode is marked failed), and
+    orphaned objects are moved under RECONCILE_ORPHAN_PREFIX.
+
+    Args:
+        repair: Repair mismatches
+        verify: Verify checksums
+        db: Database instance
+
+    Returns:
+        The run's report
+    """
+    try:
+        report = await ReconciliationService(db).run(repair, verify=verify)
+        if repair:
+            await AuditService(db).record(
+                "storage.reconciled", "reconciliation", report["run_id"], details=report["repaired"]
+            )
+        return ReconciliationReport(**report)
+
+    except Exception as e:
+        logger.error(f"Error reconciling storage: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to reconcile storage"
+        )
+
+
+@router.get("/reconcile/runs", response_model=ReconciliationListResponse)
+async def list_reconciliation_runs(
+    limit: int = Query(10, ge=1, le=100, description="Maximum runs to return"),
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """List recent reconciliation reports, manual and scheduled, newest first."""
+    runs = await ReconciliationService(db).list_runs(limit)
+    return ReconciliationListResponse(runs=[ReconciliationReport(**run) for run in runs], total=len(runs))
+
+
+@router.post("/reindex", response_model=ReindexRunResponse, status_code=status.HTTP_202_ACCEPTED)
+async def reindex_transcripts(
+    background_tasks: BackgroundTasks,
+    podcast_id: Optional[str] = Query(None, description="Rebuild only this podcast's episodes"),
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """
+    Rebuild the transcript search index from the transcripts in S3, e.g.
+    after its definition changed or it was lost.
+
+    Every completed episode in scope is indexed again and entries the run
+    didn't refresh are dropped; a full rebuild also recreates the index
+    definitions. The run continues in the background; poll
+    GET /api/admin/reindex/{run_id} for its progress.
+
+    Args:
+        background_tasks: FastAPI background tasks
+        podcast_id: Podcast to rebuild (default: all)
+        db: Database instance
+
+    Returns:
+        The started run
+
+    Raises:
+        HTTPException: If the podcast doesn't exist or a rebuild is already running
+    """
+    try:
+        if podcast_id and not await db.podcasts.find_one({"podcast_id": podcast_id}, {"_id": 1}):
+            raise HTTPException(status_code=status.HTTP_404_NOT_FOUND, detail="Podcast not found")
+
+        service = ReindexService(db)
+        run = await service.start(podcast_id)
+        background_tasks.add_task(service.run, run["run_id"])
+        await AuditService(db).record(
+            "transcripts.reindexed", "reindex", run["run_id"], details={"podcast_id": podcast_id}
+        )
+        return ReindexRunResponse(**run)
+
+    except ReindexRunningError as e:
+        raise HTTPException(status_code=status.HTTP_409_CONFLICT, detail=str(e))
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error starting reindex: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to start reindex"
+        )
+
+
+@router.get("/reindex/{run_id}", response_model=ReindexRunResponse)
+async def get_reindex_run(run_id: str, db: AsyncIOMotorDatabase = Depends(get_database)):
+    """Progress of a transcript index rebuild."""
+    run = await ReindexService(db).get_run(run_id)
+    if not run:
+        raise HTTPException(status_code=status.HTTP_404_NOT_FOUND, detail="Reindex run not found")
+    return ReindexRunResponse(**run)
@@ -0,0 +1,65 @@
+"""Transcript analytics endpoints."""
+import logging
+from typing import Literal, Optional
+from fastapi import APIRouter, HTTPException, Depends, Query, status
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.database import get_database
+from app.models.schemas import TopicAnalyticsResponse
+from app.services.topic_analytics import TopicAnalyticsService
+from app.workspaces import current_workspace, in_workspace, workspace_podcast_ids
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter(prefix="/api/analytics", tags=["analytics"])
+
+
+@router.get("/topics", response_model=TopicAnalyticsResponse)
+async def get_topic_trends(
+    podcast_id: Optional[str] = Query(None, description="Podcast to analyze (default: all)"),
+    window: Literal["week", "month", "quarter", "year"] = Query("month", description="Period length"),
+    top: int = Query(10, ge=1, le=50, description="Keywords listed per period and followed as trends"),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Get keyword frequency over time across a podcast's transcripts, or
+    across all of the caller's podcasts.
+
+    Episodes are grouped by publication date. Keywords are extracted in the
+    background every TOPIC_ANALYSIS_INTERVAL_HOURS, so recently transcribed
+    episodes may still be pending.
+
+    Args:
+        podcast_id: Podcast to analyze
+        window: week, month, quarter or year
+        top: Keywords per period
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        Top keywords per period and the overall top keywords' counts per period
+
+    Raises:
+        HTTPException: If the podcast doesn't exist
+    """
+    try:
+        if podcast_id:
+            podcast = await db.podcasts.find_one({"podcast_id": podcast_id}, {"workspace_id": 1})
+            if not in_workspace(podcast, workspace_id):
+                raise HTTPException(status_code=status.HTTP_404_NOT_FOUND, detail="Podcast not found")
+            podcast_ids = [podcast_id]
+        else:
+            podcast_ids = await workspace_podcast_ids(db, workspace_id)
+
+        trends = await TopicAnalyticsService(db).topic_trends(podcast_ids, window, top)
+        return TopicAnalyticsResponse(podcast_id=podcast_id, **trends)
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error computing topic trends: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to compute topic trends"
+        )
@@ -0,0 +1,79 @@
+"""Callbacks from asynchronous ASR providers.
+
+Episodes submitted with ASR_PROVIDER set are reported here by the provider
+(see app/services/asr_jobs.py). Providers can't sign requests with our
+secret, so each callback URL carries its job ID and a token derived from
+CALLBACK_SECRET.
+"""
+import hmac
+import logging
+from typing import Optional
+
+from fastapi import APIRouter, HTTPException, Depends, Query, Request, status
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.config import settings
+from app.database import get_database
+from app.models.schemas import TranscriptCallbackResponse
+from app.services.asr_jobs import ASRJobService, callback_token
+from app.services.asr_providers import get_provider
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter(prefix="/api/asr/callbacks", tags=["callbacks"])
+
+
+@router.post("/{provider}", response_model=TranscriptCallbackResponse)
+async def asr_callback(
+    provider: str,
+    request: Request,
+    job: str = Query(..., description="ASR job the callback reports"),
+    token: Optional[str] = Query(None, description="Callback token of the job"),
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """
+    Receive a transcription result from an ASR provider.
+
+    Providers retry deliveries, and the poller may have finished the job
+    first; such repeats return handled=false.
+
+    Args:
+        provider: Provider name (assemblyai or deepgram)
+        request: Provider payload
+        job: ASR job ID from the callback URL
+        token: Callback token from the callback URL
+        db: Database instance
+
+    Returns:
+        Whether the callback was handled
+
+    Raises:
+        HTTPException: If callbacks are disabled, the provider is unknown or the token is invalid
+    """
+    if not settings.callback_secret or not get_provider(provider):
+        raise HTTPException(status_code=status.HTTP_404_NOT_FOUND, detail="Callbacks are disabled")
+    if not token or not hmac.compare_digest(token, callback_token(provider, job)):
+        raise HTTPException(status_code=status.HTTP_401_UNAUTHORIZED, detail="Invalid token")
+    try:
+        payload = await request.json()
+    except ValueError:
+        raise HTTPException(status_code=status.HTTP_400_BAD_REQUEST, detail="Body must be JSON")
+
+    try:
+        handled = await ASRJobService(db).handle_callback(provider, job, payload)
+        if not handled:
+            logger.info(f"Ignoring repeated or unknown {provider} callback for ASR job {job}")
+            asr_job = await db.asr_jobs.find_one({"asr_job_id": job})
+            return TranscriptCallbackResponse(episode_id=asr_job["episode_id"] if asr_job else "", handled=False)
+        return TranscriptCallbackResponse(episode_id=handled["episode_id"], handled=True)
+
+    except (KeyError, ValueError) as e:
+        raise HTTPException(status_code=status.HTTP_400_BAD_REQUEST, detail=f"Invalid callback: {e}")
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error handling {provider} callback for ASR job {job}: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to handle callback"
+        )
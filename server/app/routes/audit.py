@@ -0,0 +1,87 @@
+"""Audit log query endpoint."""
+import logging
+from datetime import datetime
+from typing import Optional
+from fastapi import APIRouter, HTTPException, Depends, Query, status
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.database import get_database
+from app.models.schemas import AuditLogEntry, AuditLogListResponse
+from app.pagination import encode_cursor
+from app.services.audit_service import AuditService
+from app.validation import RequestValidationFailure
+from app.workspaces import current_workspace
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter(prefix="/api/audit", tags=["audit"])
+
+
+@router.get("", response_model=AuditLogListResponse)
+async def list_audit_entries(
+    action: Optional[str] = Query(None, description='Only this action, e.g. "bulk_job.cancelled"'),
+    actor: Optional[str] = Query(None, description='Only this actor, e.g. "admin"'),
+    target_type: Optional[str] = Query(None, description='Only this kind of object, e.g. "podcast"'),
+    target_id: Optional[str] = Query(None, description="Only this object"),
+    request_id: Optional[str] = Query(None, description="Only changes made by this request"),
+    since: Optional[datetime] = Query(None, description="Only entries at or after this time"),
+    until: Optional[datetime] = Query(None, description="Only entries before this time"),
+    limit: int = Query(50, ge=1, le=500, description="Maximum entries to return"),
+    cursor: Optional[str] = Query(None, description="next_cursor from the previous page"),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Query the audit trail of subscriptions, jobs, transcript edits, share
+    links, workspaces and keys, newest first.
+
+    Args:
+        action: Action filter
+        actor: Actor filter
+        target_type: Target type filter
+        target_id: Target ID filter
+        request_id: Request ID filter
+        since: Inclusive lower time bound
+        until: Exclusive upper time bound
+        limit: Maximum entries to return
+        cursor: Pagination cursor
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        Matching entries and the cursor of the next page
+    """
+    try:
+        filters = {
+            field: value for field, value in (
+                ("action", action),
+                ("actor", actor),
+                ("target_type", target_type),
+                ("target_id", target_id),
+                ("request_id", request_id),
+            ) if value
+        }
+
+        # Fetch one extra entry to know whether another page follows
+        entries = await AuditService(db).list_entries(
+            filters, since=since, until=until, limit=limit + 1, cursor=cursor, workspace_id=workspace_id
+        )
+        has_more = len(entries) > limit
+        entries = entries[:limit]
+        next_cursor = encode_cursor(entries[-1]["timestamp"], entries[-1]["_id"]) if has_more else None
+
+        return AuditLogListResponse(
+            entries=[AuditLogEntry(**entry) for entry in entries],
+            total=len(entries),
+            has_more=has_more,
+            next_cursor=next_cursor
+        )
+
+    except RequestValidationFailure:
+        raise
+    except Exception as e:
+        logger.error(f"Error listing audit entries: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to list audit entries"
+        )
@@ -0,0 +1,110 @@
+"""Callbacks from the AWS transcription pipeline.
+
+With CALLBACK_URL set, the merge Lambda POSTs its result here when a Step
+Functions execution finishes, so the API can run the same follow-up work
+as the in-process orchestrator (ad detection, chat webhooks). Requests
+are signed with CALLBACK_SECRET as "X-Podcasts-Signature: sha256=<hex>".
+"""
+import hashlib
+import hmac
+import logging
+from datetime import timezone
+from typing import Optional
+
+from fastapi import APIRouter, HTTPException, Depends, Header, Request, status
+from motor.motor_asyncio import AsyncIOMotorDatabase
+from pydantic import ValidationError
+
+from app.cache import cache
+from app.config import settings
+from app.database import get_database
+from app.models.schemas import TranscriptCallback, TranscriptCallbackResponse, TranscriptStatus
+from app.services.ad_detection import AdDetectionService
+from app.services.chat_notifier import chat_notifier
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter(prefix="/api/callbacks", tags=["callbacks"])
+
+
+def verify_signature(body: bytes, signature: Optional[str]) -> bool:
+    """Check an X-Podcasts-Signature header against CALLBACK_SECRET."""
+    expected = "sha256=" + hmac.new(settings.callback_secret.encode(), body, hashlib.sha256).hexdigest()
+    return bool(signature) and hmac.compare_digest(signature, expected)
+
+
+@router.post("/transcript", response_model=TranscriptCallbackResponse)
+async def transcript_callback(
+    request: Request,
+    x_podcasts_signature: Optional[str] = Header(None),
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """
+    Receive a merge result from the merge Lambda.
+
+    Deliveries are retried, so each result is handled once per episode
+    and merge timestamp; repeats return handled=false.
+
+    Args:
+        request: Signed callback payload
+        x_podcasts_signature: HMAC-SHA256 of the body
+        db: Database instance
+
+    Returns:
+        Whether the callback was handled
+
+    Raises:
+        HTTPException: If callbacks are disabled or the signature is invalid
+    """
+    if not settings.callback_secret:
+        raise HTTPException(status_code=status.HTTP_404_NOT_FOUND, detail="Callbacks are disabled")
+    body = await request.body()
+    if not verify_signature(body, x_podcasts_signature):
+        raise HTTPException(status_code=status.HTTP_401_UNAUTHORIZED, detail="Invalid signature")
+    try:
+        callback = TranscriptCallback.model_validate_json(body)
+    except ValidationError as e:
+        raise HTTPException(status_code=status.HTTP_400_BAD_REQUEST, detail=str(e))
+
+    try:
+        # MongoDB keeps milliseconds; match what a previous delivery stored
+        timestamp = callback.timestamp.astimezone(timezone.utc).replace(tzinfo=None)
+        timestamp = timestamp.replace(microsecond=timestamp.microsecond // 1000 * 1000)
+
+        fields = {"pipeline_callback_at": timestamp}
+        if callback.event == "transcript.failed":
+            fields.update({
+                "transcript_status": TranscriptStatus.FAILED.value,
+                "processing_step": None,
+                "error_message": callback.error_message,
+                "updated_at": timestamp,
+            })
+        episode = await db.episodes.find_one_and_update(
+            {"episode_id": callback.episode_id, "pipeline_callback_at": {"$ne": timestamp}},
+            {"$set": fields}
+        )
+        if not episode:
+            logger.info(f"Ignoring repeated or unknown {callback.event} callback for episode {callback.episode_id}")
+            return TranscriptCallbackResponse(episode_id=callback.episode_id, handled=False)
+        # The merge Lambda completed the episode in MongoDB itself
+        await cache.invalidate("episodes")
+
+        if callback.event == "transcript.completed":
+            logger.info(f"Pipeline completed episode {callback.episode_id}: {callback.total_words} words")
+            await AdDetectionService(db).analyze_completed(callback.episode_id)
+            podcast = await db.podcasts.find_one({"podcast_id": episode.get("podcast_id")})
+            if podcast:
+                await chat_notifier.notify_episode_transcribed(podcast, episode)
+        else:
+            logger.warning(f"Pipeline failed episode {callback.episode_id}: {callback.error_message}")
+
+        return TranscriptCallbackResponse(episode_id=callback.episode_id, handled=True)
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error handling callback for episode {callback.episode_id}: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to handle callback"
+        )
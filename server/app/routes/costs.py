@@ -0,0 +1,50 @@
+"""Transcription cost reporting endpoints."""
+import logging
+from typing import Optional
+from fastapi import APIRouter, HTTPException, Depends, Query, status
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.database import get_database
+from app.models.schemas import CostReportResponse
+from app.services.cost_service import CostService, sum_costs
+from app.workspaces import current_workspace
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter(prefix="/api/costs", tags=["costs"])
+
+
+@router.get("", response_model=CostReportResponse)
+async def get_costs(
+    months: int = Query(12, ge=1, le=60, description="Number of most recent months to include"),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Get actual transcription costs aggregated by month.
+
+    Costs are split between the Lambda pipeline (per-episode transcription)
+    and bulk jobs, each covering provider minutes, S3 storage and Lambda
+    GB-seconds.
+
+    Args:
+        months: Number of most recent months to include
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        Monthly cost breakdown and the total over those months
+    """
+    try:
+        rows = await CostService(db).monthly_costs(months, workspace_id)
+        return {
+            "months": rows,
+            "total": sum_costs([row["total"] for row in rows]),
+        }
+
+    except Exception as e:
+        logger.error(f"Error computing costs: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to compute costs"
+        )
@@ -1,122 +1,271 @@
 """Dev-only routes for bulk podcast transcription."""
 import logging
-from fastapi import APIRouter, HTTPException, BackgroundTasks
-from typing import List
+from fastapi import APIRouter, HTTPException, BackgroundTasks, Depends, Header, Request, Response
+from typing import Any, Dict, List, Optional, Tuple, Union
+from app.cache import cache
+from app.config import settings
 from app.database.mongodb import get_database
 from app.models.schemas import (
     BulkTranscribeRequest,
     BulkTranscribeJobResponse,
     BulkTranscribeJobListResponse,
     BulkTranscribeEpisodeProgress,
+    BulkTranscribeDryRunResponse,
     BulkJobStatus,
+    JobPriority,
     SuccessResponse
 )
+from app.http_cache import compute_etag, conditional_response
+from app.pagination import encode_cursor
+from app.services.audit_service import AuditService
+from app.services.bulk_schedule import BulkScheduleService
 from app.services.bulk_transcribe_service import BulkTranscribeService
+from app.services.quota_service import QuotaExceededError
+from app.url_normalization import normalize_url
+from app.validation import RequestValidationFailure
+from app.workspaces import current_workspace, in_workspace
 
 logger = logging.getLogger(__name__)
 
 router = APIRouter(prefix="/api/dev", tags=["dev-bulk-transcribe"])
 
+# Episode progress entries returned per page of a job
+DEFAULT_EPISODE_PAGE_SIZE = 100
+MAX_EPISODE_PAGE_SIZE = 500
 
-@router.post("/bulk-transcribe", response_model=BulkTranscribeJobResponse)
+
+async def _episode_page(
+    service: BulkTranscribeService,
+    job_id: str,
+    limit: int,
+    after: Optional[int] = None
+) -> Tuple[List[BulkTranscribeEpisodeProgress], Optional[int]]:
+    """Fetch a page of a job's episode progress and the index to continue after."""
+    limit = min(max(limit, 1), MAX_EPISODE_PAGE_SIZE)
+    # Fetch one extra entry to know whether another page follows
+    entries = await cache.get_or_load(
+        f"job:{job_id}:episodes:{after}:{limit}",
+        settings.cache_job_ttl_seconds,
+        lambda: service.list_job_episodes(job_id, limit=limit + 1, after=after),
+        namespace=f"job:{job_id}"
+    )
+    has_more = len(entries) > limit
+    entries = entries[:limit]
+
+    progress = [
+        BulkTranscribeEpisodeProgress(
+            index=ep["index"],
+            episode_id=ep.get("episode_id", ""),
+            title=ep["title"],
+            status=ep["status"],
+            skip_reason=ep.get("skip_reason"),
+            transcript_progress=ep.get("transcript_progress"),
+            transcript=ep.get("transcript"),
+            error_message=ep.get("error_message"),
+            started_at=ep.get("started_at"),
+            completed_at=ep.get("completed_at")
+        )
+        for ep in entries
+    ]
+    return progress, entries[-1]["index"] if has_more else None
+
+
+def _job_response(
+    job: Dict[str, Any],
+    episodes: Optional[List[BulkTranscribeEpisodeProgress]] = None,
+    episodes_next_after: Optional[int] = None
+) -> BulkTranscribeJobResponse:
+    """Response for a job document, with a page of its episode progress if given."""
+    return BulkTranscribeJobResponse(
+        job_id=job["job_id"],
+        rss_url=job["rss_url"],
+        podcast_id=job.get("podcast_id"),
+        status=BulkJobStatus(job["status"]),
+        total_episodes=job["total_episodes"],
+        processed_episodes=job["processed_episodes"],
+        successful_episodes=job["successful_episodes"],
+        failed_episodes=job["failed_episodes"],
+        skipped_episodes=job.get("skipped_episodes", 0),
+        created_at=job["created_at"],
+        updated_at=job["updated_at"],
+        completed_at=job.get("completed_at"),
+        current_episode=job.get("current_episode"),
+        queue_position=job.get("queue_position"),
+        download_paused_until=job.get("download_paused_until"),
+        started_at=job.get("started_at"),
+        progress_percent=job.get("progress_percent", 0),
+        average_episode_seconds=job.get("average_episode_seconds"),
+        estimated_completion_at=job.get("estimated_completion_at"),
+        priority=job.get("priority", JobPriority.LOW.value),
+        schedule=job.get("schedule"),
+        model=job.get("model"),
+        checkpoint=job.get("checkpoint"),
+        next_run_at=job.get("next_run_at"),
+        parent_job_id=job.get("parent_job_id"),
+        estimated_cost=job.get("estimated_cost"),
+        actual_cost=job.get("actual_cost"),
+        episodes=episodes,
+        episodes_next_after=episodes_next_after
+    )
+
+
+@router.post(
+    "/bulk-transcribe",
+    response_model=Union[BulkTranscribeJobResponse, BulkTranscribeDryRunResponse]
+)
 async def start_bulk_transcribe(
     request: BulkTranscribeRequest,
-    background_tasks: BackgroundTasks
+    background_tasks: BackgroundTasks,
+    x_api_key: Optional[str] = Header(None),
+    workspace_id: Optional[str] = Depends(current_workspace)
 ):
     """
-    Start a bulk transcription job for all episodes in an RSS feed.
+    Start a bulk transcription job for an RSS feed or a subscribed podcast.
     This endpoint is dev-only and uses the local Whisper container.
+
+    The job's total audio minutes are reserved against the monthly quota
+    up front; the job is rejected (402/429) if they don't fit.
+
+    With a schedule, the job starts a series: whenever the schedule fires, a
+    new job transcribes episodes the series hasn't transcribed yet.
+
+    With dry_run, the feed is parsed and filtered and the selected episodes
+    are returned with estimated audio hours and cost; no job is created and
+    no quota is reserved.
     """
     try:
+        if bool(request.rss_url) == bool(request.podcast_id):
+            raise RequestValidationFailure.single(
+                "rss_url",
+                "required",
+                "Provide exactly one of rss_url or podcast_id"
+            )
+
+        if (request.published_after and request.published_before
+                and request.published_after >= request.published_before):
+            raise RequestValidationFailure.single(
+                "published_after",
+                "invalid_range",
+                "published_after must be earlier than published_before"
+            )
+
         db = await get_database()
         service = BulkTranscribeService(db)
 
+        podcast = None
+        rss_url = None
+        if request.rss_url:
+            requested_url = str(request.rss_url)
+            rss_url = normalize_url(requested_url)
+            # Podcasts subscribed before URL normalization keep their original URL
+            podcast = await db.podcasts.find_one({"rss_url": {"$in": list({rss_url, requested_url})}})
+            if podcast and not in_workspace(podcast, workspace_id):
+                raise HTTPException(status_code=409, detail="This feed is subscribed in another workspace")
+            if podcast:
+                rss_url = podcast["rss_url"]
+        if request.podcast_id:
+            podcast = await db.podcasts.find_one({"podcast_id": request.podcast_id, "deleted_at": None})
+            if not in_workspace(podcast, workspace_id):
+                raise HTTPException(status_code=404, detail="Podcast not found")
+            rss_url = podcast["rss_url"]
+
+        if request.dry_run:
+            preview = await service.preview_job(
+                rss_url=rss_url,
+                max_episodes=request.max_episodes,
+                published_after=request.published_after,
+                published_before=request.published_before,
+                title_contains=request.title_contains,
+                order=request.order,
+                podcast=podcast
+            )
+            return BulkTranscribeDryRunResponse(**preview)
+
         # Create job
         job = await service.create_job(
-            rss_url=str(request.rss_url),
+            rss_url=rss_url,
             max_episodes=request.max_episodes,
-            dry_run=request.dry_run
+            priority=request.priority,
+            published_after=request.published_after,
+            published_before=request.published_before,
+            title_contains=request.title_contains,
+            order=request.order,
+            podcast=podcast,
+            workspace_id=workspace_id,
+            model=request.model,
+            api_key=x_api_key
+        )
+
+        if request.schedule:
+            await BulkScheduleService(db).set_schedule(job["job_id"], request.schedule)
+            job = await service.get_job(job["job_id"])
+        await AuditService(db).record(
+            "bulk_job.started", "bulk_job", job["job_id"], workspace_id,
+            {"rss_url": rss_url, "total_episodes": job["total_episodes"], "schedule": request.schedule}
         )
 
         # Start processing in background
         background_tasks.add_task(service.process_job, job["job_id"])
 
         # Convert episodes to response model
-        episodes_progress = [
-            BulkTranscribeEpisodeProgress(
-                episode_id=ep.get("episode_id", ""),
-                title=ep["title"],
-                status=ep["status"],
-                transcript=ep.get("transcript"),
-                error_message=ep.get("error_message"),
-                started_at=ep.get("started_at"),
-                completed_at=ep.get("completed_at")
-            )
-            for ep in job.get("episodes", [])
-        ]
-
-        return BulkTranscribeJobResponse(
-            job_id=job["job_id"],
-            rss_url=job["rss_url"],
-            status=BulkJobStatus(job["status"]),
-            total_episodes=job["total_episodes"],
-            processed_episodes=job["processed_episodes"],
-            successful_episodes=job["successful_episodes"],
-            failed_episodes=job["failed_episodes"],
-            created_at=job["created_at"],
-            updated_at=job["updated_at"],
-            completed_at=job.get("completed_at"),
-            current_episode=job.get("current_episode"),
-            episodes=episodes_progress
+        episodes_progress, episodes_next_after = await _episode_page(
+            service, job["job_id"], DEFAULT_EPISODE_PAGE_SIZE
         )
 
+        return _job_response(job, episodes_progress, episodes_next_after)
+
+    except (HTTPException, QuotaExceededError, RequestValidationFailure):
+        raise
     except ValueError as e:
-        raise HTTPException(status_code=400, detail=str(e))
+        raise RequestValidationFailure.single("rss_url", "invalid_feed", str(e), 400)
     except Exception as e:
         logger.error(f"Error starting bulk transcribe job: {e}")
         raise HTTPException(status_code=500, detail="Failed to start bulk transcription job")
 
 
 @router.get("/bulk-transcribe/{job_id}", response_model=BulkTranscribeJobResponse)
-async def get_bulk_transcribe_job(job_id: str):
-    """Get the status and progress of a bulk transcription job."""
+async def get_bulk_transcribe_job(
+    job_id: str,
+    request: Request,
+    response: Response,
+    episodes_limit: int = DEFAULT_EPISODE_PAGE_SIZE,
+    episodes_after: Optional[int] = None,
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Get the status and progress of a bulk transcription job.
+
+    Episode progress is paged: pass episodes_next_after from the previous
+    response as episodes_after to fetch the next page. With REDIS_URL set,
+    the job is cached for CACHE_JOB_TTL_SECONDS (until it next changes).
+
+    Returns 304 when the client's ETag / Last-Modified is still current.
+    """
     try:
         db = await get_database()
         service = BulkTranscribeService(db)
 
-        job = await service.get_job(job_id)
-        if not job:
+        job = await cache.get_or_load(
+            f"job:{job_id}",
+            settings.cache_job_ttl_seconds,
+            lambda: service.get_job(job_id),
+            namespace=f"job:{job_id}"
+        )
+        if not in_workspace(job, workspace_id):
             raise HTTPException(status_code=404, detail="Job not found")
 
+        etag = compute_etag(job_id, job["updated_at"], job["status"], job["processed_episodes"])
+        not_modified = conditional_response(request, response, etag, job["updated_at"])
+        if not_modified:
+            return not_modified
+
         # Convert episodes to response model
-        episodes_progress = [
-            BulkTranscribeEpisodeProgress(
-                episode_id=ep.get("episode_id", ""),
-                title=ep["title"],
-                status=ep["status"],
-                transcript=ep.get("transcript"),
-                error_message=ep.get("error_message"),
-                started_at=ep.get("started_at"),
-                completed_at=ep.get("completed_at")
-            )
-            for ep in job.get("episodes", [])
-        ]
-
-        return BulkTranscribeJobResponse(
-            job_id=job["job_id"],
-            rss_url=job["rss_url"],
-            status=BulkJobStatus(job["status"]),
-            total_episodes=job["total_episodes"],
-            processed_episodes=job["processed_episodes"],
-            successful_episodes=job["successful_episodes"],
-            failed_episodes=job["failed_episodes"],
-            created_at=job["created_at"],
-            updated_at=job["updated_at"],
-            completed_at=job.get("completed_at"),
-            current_episode=job.get("current_episode"),
-            episodes=episodes_progress
+        episodes_progress, episodes_next_after = await _episode_page(
+            service, job["job_id"], episodes_limit, episodes_after
         )
 
+        return _job_response(job, episodes_progress, episodes_next_after)
+
     except HTTPException:
         raise
     except Exception as e:
@@ -125,59 +274,87 @@ async def get_bulk_transcribe_job(job_id: str):
 
 
 @router.get("/bulk-transcribe", response_model=BulkTranscribeJobListResponse)
-async def list_bulk_transcribe_jobs(limit: int = 50):
-    """List all bulk transcription jobs."""
+async def list_bulk_transcribe_jobs(
+    limit: int = 50,
+    cursor: Optional[str] = None,
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    List bulk transcription jobs, most recent first.
+
+    Pass next_cursor from the previous response as cursor to fetch the next page.
+    """
     try:
         db = await get_database()
         service = BulkTranscribeService(db)
 
-        jobs = await service.list_jobs(limit=limit)
-
-        job_responses = [
-            BulkTranscribeJobResponse(
-                job_id=job["job_id"],
-                rss_url=job["rss_url"],
-                status=BulkJobStatus(job["status"]),
-                total_episodes=job["total_episodes"],
-                processed_episodes=job["processed_episodes"],
-                successful_episodes=job["successful_episodes"],
-                failed_episodes=job["failed_episodes"],
-                created_at=job["created_at"],
-                updated_at=job["updated_at"],
-                completed_at=job.get("completed_at"),
-                current_episode=job.get("current_episode"),
-                episodes=None  # Don't include full episode list in listing
-            )
-            for job in jobs
-        ]
+        # Fetch one extra job to know whether another page follows
+        jobs = await service.list_jobs(limit=limit + 1, cursor=cursor, workspace_id=workspace_id)
+        has_more = len(jobs) > limit
+        jobs = jobs[:limit]
+        next_cursor = encode_cursor(jobs[-1]["created_at"], jobs[-1]["_id"]) if has_more else None
+
+        # Listings don't include episode progress
+        job_responses = [_job_response(job) for job in jobs]
 
         return BulkTranscribeJobListResponse(
             jobs=job_responses,
-            total=len(job_responses)
+            total=len(job_responses),
+            has_more=has_more,
+            next_cursor=next_cursor
         )
 
+    except RequestValidationFailure:
+        raise
     except Exception as e:
         logger.error(f"Error listing bulk transcribe jobs: {e}")
         raise HTTPException(status_code=500, detail="Failed to list jobs")
 
 
+@router.delete("/bulk-transcribe/{job_id}/schedule", response_model=SuccessResponse)
+async def stop_bulk_transcribe_schedule(job_id: str, workspace_id: Optional[str] = Depends(current_workspace)):
+    """Stop a scheduled bulk transcription series. Runs already started continue."""
+    try:
+        db = await get_database()
+        job = await BulkTranscribeService(db).get_job(job_id)
+        if not in_workspace(job, workspace_id):
+            raise HTTPException(status_code=404, detail="Job not found")
+        if not job.get("schedule"):
+            raise HTTPException(status_code=400, detail="Job is not scheduled")
+
+        await BulkScheduleService(db).set_schedule(job_id, None)
+        await AuditService(db).record("bulk_job.schedule_stopped", "bulk_job", job_id, workspace_id)
+        return SuccessResponse(
+            message="Schedule stopped",
+            data={"job_id": job_id, "run_count": job.get("run_count", 0)}
+        )
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error stopping schedule: {e}")
+        raise HTTPException(status_code=500, detail="Failed to stop schedule")
+
+
 @router.post("/bulk-transcribe/{job_id}/cancel", response_model=SuccessResponse)
-async def cancel_bulk_transcribe_job(job_id: str):
-    """Cancel a running bulk transcription job."""
+async def cancel_bulk_transcribe_job(job_id: str, workspace_id: Optional[str] = Depends(current_workspace)):
+    """Cancel a pending or running bulk transcription job, on whichever instance is processing it."""
     try:
         db = await get_database()
         service = BulkTranscribeService(db)
 
         # Check if job exists
         job = await service.get_job(job_id)
-        if not job:
+        if not in_workspace(job, workspace_id):
             raise HTTPException(status_code=404, detail="Job not found")
 
         # Try to cancel
         cancelled = await service.cancel_job(job_id)
+        if cancelled:
+            await AuditService(db).record("bulk_job.cancelled", "bulk_job", job_id, workspace_id)
 
         return SuccessResponse(
-            message="Job cancellation requested" if cancelled else "Job is not running",
+            message="Job cancellation requested" if cancelled else "Job has already finished",
             data={"job_id": job_id, "cancelled": cancelled}
         )
 
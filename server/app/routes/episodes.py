@@ -1,17 +1,42 @@
 """Episode and transcript management endpoints."""
 import logging
-from typing import Optional
-from fastapi import APIRouter, HTTPException, Depends, Query, status
+from typing import List, Optional
+from fastapi import APIRouter, HTTPException, Depends, Header, Query, Request, Response, status
+from fastapi.responses import HTMLResponse
 from motor.motor_asyncio import AsyncIOMotorDatabase
 
+from app.cache import cache
+from app.config import settings
 from app.database import get_database
 from app.models import (
     EpisodeResponse,
     EpisodeListResponse,
     TranscriptResponse,
+    PipelineStatusResponse,
     TranscriptStatus,
+    SuccessResponse,
+    EpisodeOrder,
+    AdSegment,
+    QuoteExtractionResponse,
+    ClipRequest,
+    ClipResponse,
+    SimilarEpisodesResponse,
 )
+from app.http_cache import compute_etag, conditional_response
+from app.pagination import encode_cursor, seek_after
 from app.services import s3_service, step_functions_service
+from app.services.ad_detection import AdDetectionService, strip_ad_segments
+from app.services.archive_service import ArchiveService
+from app.services.audio_clips import ClipError, create_clip
+from app.services.audit_service import AuditService
+from app.services.episode_embeddings import EpisodeEmbeddingService
+from app.services.quote_extraction import QuoteExtractionService
+from app.services.private_transcripts import PrivateTranscriptError, is_private, podcast_encryption
+from app.services.storage_locations import podcast_storage, transcript_location
+from app.services.quota_service import QuotaService, QuotaExceededError, episode_minutes
+from app.services.transcript_render import parse_chapters, render_transcript_html, render_transcript_page
+from app.validation import RequestValidationFailure
+from app.workspaces import current_workspace, find_episode, scoped, workspace_podcast_ids
 
 # Constants
 DEFAULT_PAGE_LIMIT = 20
@@ -24,19 +49,33 @@ router = APIRouter(prefix="/api/episodes", tags=["episodes"])
 
 @router.get("", response_model=EpisodeListResponse)
 async def get_episodes(
+    response: Response,
     status_filter: Optional[str] = Query(None, alias="status", description="Filter by transcript status (all/completed/processing/pending/failed)"),
     page: int = Query(1, ge=1, description="Page number"),
     limit: int = Query(DEFAULT_PAGE_LIMIT, ge=1, le=MAX_PAGE_LIMIT, description="Items per page"),
-    db: AsyncIOMotorDatabase = Depends(get_database)
+    cursor: Optional[str] = Query(None, description="Opaque cursor from a previous next_cursor; overrides page"),
+    order: EpisodeOrder = Query(EpisodeOrder.NEWEST, description="Publication-date order (newest/oldest)"),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
 ):
     """
     Get episodes from subscribed podcasts.
 
+    Supports offset pagination (page) and cursor pagination (cursor). Cursor
+    pagination stays fast on large collections; pass the next_cursor from the
+    previous response to fetch the following page. The total and next cursor
+    are also sent as X-Total-Count and X-Next-Cursor headers. With REDIS_URL
+    set, pages are cached for CACHE_EPISODE_LIST_TTL_SECONDS.
+
     Args:
+        response: Response the pagination headers are set on
         status_filter: Filter by transcript status (all/completed/processing/pending/failed)
         page: Page number (1-indexed)
         limit: Number of items per page (max 100)
+        cursor: Cursor returned as next_cursor by a previous call
+        order: newest (default) or oldest first; undated episodes count as oldest
         db: Database instance
+        workspace_id: Caller's workspace
 
     Returns:
         Paginated list of episodes with transcript status
@@ -44,49 +83,170 @@ async def get_episodes(
     try:
         logger.info(f"Fetching episodes (status={status_filter}, page={page}, limit={limit})")
 
-        # Get active podcast IDs
-        active_podcasts = await db.podcasts.find(
-            {"active": True},
-            {"podcast_id": 1}
-        ).to_list(length=None)
+        # Validate status
+        valid_statuses = ["completed", "processing", "pending", "failed"]
+        if status_filter and status_filter != "all" and status_filter not in valid_statuses:
+            raise RequestValidationFailure.single(
+                "status",
+                "invalid_choice",
+                f"Invalid status filter. Must be one of: all, {', '.join(valid_statuses)}",
+                status.HTTP_400_BAD_REQUEST
+            )
 
-        active_podcast_ids = [p["podcast_id"] for p in active_podcasts]
+        result = await cache.get_or_load(
+            f"episodes:{workspace_id}:{status_filter}:{page}:{limit}:{cursor}:{order.value}",
+            settings.cache_episode_list_ttl_seconds,
+            lambda: _list_episodes(db, workspace_id, status_filter, page, limit, cursor, order),
+            namespace="episodes"
+        )
 
-        if not active_podcast_ids:
-            logger.info("No active podcasts found")
-            return {
-                "episodes": [],
-                "total": 0,
-                "page": page,
-                "limit": limit,
-                "has_more": False
-            }
+        response.headers["X-Total-Count"] = str(result["total"])
+        if result["next_cursor"]:
+            response.headers["X-Next-Cursor"] = result["next_cursor"]
+        return result
 
-        # Build query
-        query = {"podcast_id": {"$in": active_podcast_ids}}
-
-        # Add status filter if specified
-        if status_filter and status_filter != "all":
-            # Validate status
-            valid_statuses = ["completed", "processing", "pending", "failed"]
-            if status_filter not in valid_statuses:
-                raise HTTPException(
-                    status_code=status.HTTP_400_BAD_REQUEST,
-                    detail=f"Invalid status filter. Must be one of: all, {', '.join(valid_statuses)}"
-                )
-            query["transcript_status"] = status_filter
+    except (HTTPException, RequestValidationFailure):
+        raise
+    except Exception as e:
+        logger.error(f"Error fetching episodes: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to fetch episodes"
+        )
 
-        # Count total matching episodes
-        total = await db.episodes.count_documents(query)
 
-        # Calculate pagination
+async def _list_episodes(
+    db: AsyncIOMotorDatabase,
+    workspace_id: Optional[str],
+    status_filter: Optional[str],
+    page: int,
+    limit: int,
+    cursor: Optional[str],
+    order: EpisodeOrder
+) -> dict:
+    """Read a page of the episode list (cached by get_episodes)."""
+    # Get active podcast IDs
+    active_podcasts = await db.podcasts.find(
+        scoped({"active": True}, workspace_id),
+        {"podcast_id": 1}
+    ).to_list(length=None)
+
+    active_podcast_ids = [p["podcast_id"] for p in active_podcasts]
+
+    if not active_podcast_ids:
+        logger.info("No active podcasts found")
+        return {
+            "episodes": [],
+            "total": 0,
+            "page": page,
+            "limit": limit,
+            "has_more": False,
+            "next_cursor": None
+        }
+
+    # Build query
+    query = {"podcast_id": {"$in": active_podcast_ids}, "deleted_at": None}
+
+    # Add status filter if specified
+    if status_filter and status_filter != "all":
+        query["transcript_status"] = status_filter
+
+    # Count total matching episodes
+    total = await db.episodes.count_documents(query)
+
+    # Calculate pagination; one extra document tells us whether more follow
+    ascending = order == EpisodeOrder.OLDEST
+    direction = 1 if ascending else -1
+    if cursor:
+        match = {"$and": [query, seek_after("published_date", cursor, ascending)]}
+        skip = 0
+    else:
+        match = query
         skip = (page - 1) * limit
 
-        # Fetch episodes with podcast info using aggregation
+    # Fetch episodes with podcast info using aggregation
+    pipeline = [
+        {"$match": match},
+        {"$sort": {"published_date": direction, "_id": direction}},
+        {"$skip": skip},
+        {"$limit": limit + 1},
+        {
+            "$lookup": {
+                "from": "podcasts",
+                "localField": "podcast_id",
+                "foreignField": "podcast_id",
+                "as": "podcast"
+            }
+        },
+        {"$unwind": {"path": "$podcast", "preserveNullAndEmptyArrays": True}}
+    ]
+
+    episodes = await db.episodes.aggregate(pipeline).to_list(length=limit + 1)
+
+    # Check if there are more pages
+    has_more = len(episodes) > limit
+    episodes = episodes[:limit]
+    next_cursor = None
+    if has_more:
+        last = episodes[-1]
+        next_cursor = encode_cursor(last.get("published_date"), last["_id"])
+
+    logger.info(f"Found {len(episodes)} episodes (total: {total})")
+
+    return {
+        "episodes": [_format_episode_response(e).model_dump(mode="json") for e in episodes],
+        "total": total,
+        "page": page,
+        "limit": limit,
+        "has_more": has_more,
+        "next_cursor": next_cursor
+    }
+
+
+@router.get("/low-confidence", response_model=EpisodeListResponse)
+async def get_low_confidence_episodes(
+    threshold: Optional[float] = Query(None, ge=0, le=1, description="Quality score cutoff (default TRANSCRIPT_QUALITY_THRESHOLD)"),
+    page: int = Query(1, ge=1, description="Page number"),
+    limit: int = Query(DEFAULT_PAGE_LIMIT, ge=1, le=MAX_PAGE_LIMIT, description="Items per page"),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    List completed transcripts that likely need re-transcription.
+
+    Episodes whose transcript_quality score is below the threshold, lowest
+    first. Transcripts without a score (Whisper didn't report confidence)
+    aren't listed.
+
+    Args:
+        threshold: Score cutoff; defaults to TRANSCRIPT_QUALITY_THRESHOLD
+        page: Page number (1-indexed)
+        limit: Number of items per page (max 100)
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        Paginated list of low-confidence episodes
+    """
+    try:
+        cutoff = settings.transcript_quality_threshold if threshold is None else threshold
+        active_podcasts = await db.podcasts.find(
+            scoped({"active": True}, workspace_id),
+            {"podcast_id": 1}
+        ).to_list(length=None)
+
+        query = {
+            "podcast_id": {"$in": [p["podcast_id"] for p in active_podcasts]},
+            "deleted_at": None,
+            "transcript_status": "completed",
+            "transcript_quality.score": {"$lt": cutoff},
+        }
+        total = await db.episodes.count_documents(query)
+
         pipeline = [
             {"$match": query},
-            {"$sort": {"published_date": -1}},
-            {"$skip": skip},
+            {"$sort": {"transcript_quality.score": 1, "_id": 1}},
+            {"$skip": (page - 1) * limit},
             {"$limit": limit},
             {
                 "$lookup": {
@@ -98,46 +258,50 @@ async def get_episodes(
             },
             {"$unwind": {"path": "$podcast", "preserveNullAndEmptyArrays": True}}
         ]
-
-        cursor = db.episodes.aggregate(pipeline)
-        episodes = await cursor.to_list(length=limit)
-
-        # Check if there are more pages
-        has_more = (skip + len(episodes)) < total
-
-        logger.info(f"Found {len(episodes)} episodes (total: {total})")
+        episodes = await db.episodes.aggregate(pipeline).to_list(length=limit)
 
         return {
-            "episodes": [_format_episode_response(e) for e in episodes],
+            "episodes": [_format_episode_response(e, cutoff) for e in episodes],
             "total": total,
             "page": page,
             "limit": limit,
-            "has_more": has_more
+            "has_more": page * limit < total,
+            "next_cursor": None
         }
 
     except HTTPException:
         raise
     except Exception as e:
-        logger.error(f"Error fetching episodes: {e}")
+        logger.error(f"Error fetching low-confidence episodes: {e}")
         raise HTTPException(
             status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
-            detail="Failed to fetch episodes"
+            detail="Failed to fetch low-confidence episodes"
         )
 
 
 @router.get("/{episode_id}/transcript", response_model=TranscriptResponse)
 async def get_episode_transcript(
     episode_id: str,
-    db: AsyncIOMotorDatabase = Depends(get_database)
+    request: Request,
+    response: Response,
+    exclude_ads: bool = Query(False, description="Remove detected ad/sponsor segments"),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
 ):
     """
     Get transcript for a specific episode.
 
     This endpoint fetches the transcript from S3 or MongoDB depending on storage.
+    Supports conditional requests: a matching If-None-Match or If-Modified-Since
+    returns 304 without fetching the transcript.
 
     Args:
         episode_id: ID of the episode
+        request: Incoming request (conditional headers)
+        response: Outgoing response (validator headers)
+        exclude_ads: Remove detected ad/sponsor segments from the text
         db: Database instance
+        workspace_id: Caller's workspace
 
     Returns:
         Transcript text and metadata
@@ -149,7 +313,7 @@ async def get_episode_transcript(
         logger.info(f"Fetching transcript for episode: {episode_id}")
 
         # Find episode
-        episode = await db.episodes.find_one({"episode_id": episode_id})
+        episode = await find_episode(db, episode_id, workspace_id, deleted_at=None)
         if not episode:
             raise HTTPException(
                 status_code=status.HTTP_404_NOT_FOUND,
@@ -175,30 +339,24 @@ async def get_episode_transcript(
                 detail="Transcript generation failed"
             )
 
-        # Try to get transcript from S3
-        transcript_text = None
-        transcript_s3_key = episode.get("transcript_s3_key")
-
-        if transcript_s3_key:
-            try:
-                logger.info(f"Fetching transcript from S3: {transcript_s3_key}")
-                transcript_text = await s3_service.get_transcript(transcript_s3_key)
-            except Exception as e:
-                logger.error(f"Failed to fetch transcript from S3: {e}")
-                # Fall back to MongoDB if S3 fails
-                transcript_text = None
-
-        # Fallback: Check MongoDB for transcript
-        if not transcript_text and "transcript_text" in episode:
-            logger.info("Using transcript from MongoDB")
-            transcript_text = episode["transcript_text"]
+        last_modified = episode.get("updated_at") or episode.get("processed_at")
+        etag = compute_etag(
+            episode_id, last_modified, transcript_status, episode.get("transcript_s3_key"), exclude_ads
+        )
+        not_modified = conditional_response(request, response, etag, last_modified)
+        if not_modified:
+            return not_modified
 
+        transcript_text = await _load_transcript_text(episode)
         if not transcript_text:
             raise HTTPException(
                 status_code=status.HTTP_404_NOT_FOUND,
                 detail="Transcript not found in storage"
             )
 
+        if exclude_ads:
+            transcript_text = strip_ad_segments(transcript_text, episode.get("ad_segments") or [])
+
         logger.info(f"Successfully retrieved transcript for episode: {episode_id}")
 
         return {
@@ -218,10 +376,481 @@ async def get_episode_transcript(
         )
 
 
+@router.get("/{episode_id}/transcript.html", response_class=HTMLResponse)
+async def get_episode_transcript_html(
+    episode_id: str,
+    request: Request,
+    response: Response,
+    exclude_ads: bool = Query(False, description="Remove detected ad/sponsor segments"),
+    fragment: bool = Query(False, description="Return only the <article> element, for embedding"),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Get an episode's transcript rendered as HTML.
+
+    Timestamps become anchor links (#t-<seconds>), speaker labels are set
+    apart and chapters listed in the show notes become headings. Supports
+    the same conditional requests as the JSON transcript.
+
+    Args:
+        episode_id: ID of the episode
+        request: Incoming request (conditional headers)
+        response: Outgoing response (validator headers)
+        exclude_ads: Remove detected ad/sponsor segments from the text
+        fragment: Omit the surrounding HTML document
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        HTML document or fragment
+
+    Raises:
+        HTTPException: If the episode or its transcript is not available
+    """
+    try:
+        episode = await find_episode(db, episode_id, workspace_id, deleted_at=None)
+        if not episode:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=f"Episode with ID '{episode_id}' not found"
+            )
+        transcript_status = episode.get("transcript_status", "pending")
+        if transcript_status != "completed":
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=f"Transcript not yet available (status: {transcript_status})"
+            )
+
+        last_modified = episode.get("updated_at") or episode.get("processed_at")
+        etag = compute_etag(
+            episode_id, last_modified, "html", episode.get("transcript_s3_key"), exclude_ads, fragment
+        )
+        not_modified = conditional_response(request, response, etag, last_modified)
+        if not_modified:
+            return not_modified
+
+        transcript_text = await _load_transcript_text(episode)
+        if not transcript_text:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail="Transcript not found in storage"
+            )
+        if exclude_ads:
+            transcript_text = strip_ad_segments(transcript_text, episode.get("ad_segments") or [])
+
+        html = render_transcript_html(transcript_text, parse_chapters(episode.get("description_html") or episode.get("description")))
+        if fragment:
+            return html
+        podcast = await db.podcasts.find_one({"podcast_id": episode.get("podcast_id")}) or {}
+        published = episode.get("published_date")
+        subtitle = podcast.get("title", "Unknown Podcast")
+        if published:
+            subtitle += f" · {published.strftime('%B %d, %Y')}"
+        return render_transcript_page(episode.get("title", "Untitled Episode"), subtitle, html)
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error rendering transcript: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to render transcript"
+        )
+
+
+async def _load_transcript_text(episode: dict) -> Optional[str]:
+    """Fetch a transcript from the cache or S3, falling back to text stored in MongoDB."""
+    transcript_text = None
+    transcript_s3_key = episode.get("transcript_s3_key")
+
+    if transcript_s3_key:
+        async def fetch():
+            logger.info(f"Fetching transcript from S3: {transcript_s3_key}")
+            return await s3_service.get_transcript(transcript_s3_key, **transcript_location(episode.get("storage")))
+
+        # Keyed by the episode's version, so a re-transcription misses the old text
+        version = episode.get("updated_at") or episode.get("processed_at")
+        try:
+            transcript_text = await cache.get_or_load(
+                f"transcript:{transcript_s3_key}:{version.isoformat() if version else ''}",
+                settings.cache_transcript_ttl_seconds,
+                fetch
+            )
+        except Exception as e:
+            logger.error(f"Failed to fetch transcript from S3: {e}")
+            # Fall back to MongoDB if S3 fails
+            transcript_text = None
+
+    # Fallback: Check MongoDB for transcript
+    if not transcript_text and "transcript_text" in episode:
+        logger.info("Using transcript from MongoDB")
+        transcript_text = episode["transcript_text"]
+
+    return transcript_text
+
+
+@router.delete("/{episode_id}", response_model=SuccessResponse)
+async def delete_episode(
+    episode_id: str,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Soft-delete an episode.
+
+    The episode is hidden from listings and its transcript is archived once
+    the deletion is older than ARCHIVE_AFTER_DAYS.
+
+    Args:
+        episode_id: ID of the episode
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        Success message
+
+    Raises:
+        HTTPException: If episode not found or already deleted
+    """
+    try:
+        episode = await find_episode(db, episode_id, workspace_id)
+        if not episode:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=f"Episode with ID '{episode_id}' not found"
+            )
+        if episode.get("deleted_at"):
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail=f"Episode '{episode['title']}' is already deleted"
+            )
+
+        await ArchiveService(db).delete_episode(episode_id)
+        await AuditService(db).record("episode.deleted", "episode", episode_id, workspace_id)
+
+        return {
+            "message": f"Deleted episode '{episode['title']}'",
+            "data": {"episode_id": episode_id}
+        }
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error deleting episode: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to delete episode"
+        )
+
+
+@router.get("/{episode_id}/similar", response_model=SimilarEpisodesResponse)
+async def get_similar_episodes(
+    episode_id: str,
+    limit: int = Query(10, ge=1, le=50, description="Maximum episodes to return"),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Get the episodes whose transcripts are most similar to this one's,
+    across all of the caller's podcasts.
+
+    Args:
+        episode_id: ID of the episode
+        limit: Maximum episodes to return
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        Similar episodes with their similarity scores, best first
+
+    Raises:
+        HTTPException: If episode not found or its transcript isn't embedded yet
+    """
+    try:
+        episode = await find_episode(db, episode_id, workspace_id, deleted_at=None)
+        if not episode:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=f"Episode with ID '{episode_id}' not found"
+            )
+
+        podcast_ids = await workspace_podcast_ids(db, workspace_id)
+        result = await EpisodeEmbeddingService(db).similar(episode_id, podcast_ids, limit=limit)
+        if result is None:
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail="Episode transcript has not been embedded yet"
+            )
+        return result
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error finding similar episodes: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to find similar episodes"
+        )
+
+
+@router.post("/{episode_id}/ad-segments", response_model=List[AdSegment])
+async def detect_episode_ads(
+    episode_id: str,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Re-run ad/sponsor detection on an episode's transcript.
+
+    Detection runs automatically when a transcript completes; this refreshes
+    the stored segments (e.g. after the detector changes).
+
+    Args:
+        episode_id: ID of the episode
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        Detected segments
+
+    Raises:
+        HTTPException: If episode not found or it has no transcript
+    """
+    try:
+        episode = await find_episode(db, episode_id, workspace_id, deleted_at=None)
+        if not episode:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=f"Episode with ID '{episode_id}' not found"
+            )
+
+        segments = await AdDetectionService(db).analyze_episode(episode_id)
+        if segments is None:
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail="Episode has no transcript to analyze"
+            )
+        await AuditService(db).record(
+            "transcript.ad_segments_updated", "episode", episode_id, workspace_id, {"segments": len(segments)}
+        )
+        return segments
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error detecting ads: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to detect ad segments"
+        )
+
+
+@router.post("/{episode_id}/quotes", response_model=QuoteExtractionResponse)
+async def extract_episode_quotes(
+    episode_id: str,
+    count: int = Query(5, ge=1, le=20, description="Maximum quotes to return"),
+    cards: bool = Query(False, description="Render a shareable quote card for each quote"),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Extract notable quotes from an episode's transcript, with timestamps.
+
+    Quotes are picked by QUOTE_LLM_MODEL when OPENAI_API_KEY is set, and by
+    a heuristic otherwise; detected ad reads are never quoted. The quotes
+    are stored on the episode, replacing earlier ones.
+
+    Args:
+        episode_id: ID of the episode
+        count: Maximum quotes to return
+        cards: Also render quote cards (episode art and quote text) to S3
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        Quotes in transcript order
+
+    Raises:
+        HTTPException: If episode not found or it has no transcript, or cards
+            are asked for a private podcast
+    """
+    try:
+        episode = await find_episode(db, episode_id, workspace_id, deleted_at=None)
+        if not episode:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=f"Episode with ID '{episode_id}' not found"
+            )
+        if cards and await is_private(db, episode.get("podcast_id")):
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail="Quote cards can't be rendered for private podcasts"
+            )
+
+        result = await QuoteExtractionService(db).extract(episode_id, count=count, cards=cards)
+        if result is None:
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail="Episode has no transcript to quote"
+            )
+        await AuditService(db).record(
+            "transcript.quotes_extracted", "episode", episode_id, workspace_id,
+            {"quotes": len(result["quotes"]), "method": result["method"], "cards": cards}
+        )
+        return result
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error extracting quotes: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to extract quotes"
+        )
+
+
+@router.post("/{episode_id}/clip", response_model=ClipResponse)
+async def clip_episode_audio(
+    episode_id: str,
+    clip: ClipRequest,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Cut a time range of an episode's audio and return a download URL.
+
+    Times are in the episode's audio, as in transcript timestamps and search
+    hits. Identical clips are cut once and reused.
+
+    Args:
+        episode_id: ID of the episode
+        clip: Start and end in seconds
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        The clip's S3 key and a presigned URL valid for 24 hours
+
+    Raises:
+        HTTPException: If episode not found, it has no audio or cutting fails
+    """
+    try:
+        if clip.end_seconds <= clip.start_seconds:
+            raise RequestValidationFailure.single(
+                "end_seconds", "invalid_range", "end_seconds must be after start_seconds"
+            )
+        if clip.end_seconds - clip.start_seconds > settings.clip_max_seconds:
+            raise RequestValidationFailure.single(
+                "end_seconds", "too_long", f"Clips can be at most {settings.clip_max_seconds} seconds"
+            )
+
+        episode = await find_episode(db, episode_id, workspace_id, deleted_at=None)
+        if not episode:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=f"Episode with ID '{episode_id}' not found"
+            )
+        duration_minutes = episode.get("duration_minutes")
+        if duration_minutes and clip.start_seconds >= duration_minutes * 60:
+            raise RequestValidationFailure.single(
+                "start_seconds", "out_of_range", "start_seconds is past the end of the episode"
+            )
+        if not episode.get("audio_url") and not episode.get("s3_audio_key"):
+            raise HTTPException(
+                status_code=status.HTTP_400_BAD_REQUEST,
+                detail="Episode does not have an audio URL"
+            )
+
+        try:
+            result = await create_clip(episode, clip.start_seconds, clip.end_seconds)
+        except ClipError as e:
+            logger.warning(f"Failed to clip episode {episode_id}: {e}")
+            raise HTTPException(
+                status_code=status.HTTP_502_BAD_GATEWAY,
+                detail=f"Failed to cut clip: {e}"
+            )
+        if result["created"]:
+            await AuditService(db).record(
+                "episode.clip_created", "episode", episode_id, workspace_id,
+                {"s3_key": result["s3_key"], "seconds": result["duration_seconds"]}
+            )
+        return result
+
+    except (HTTPException, RequestValidationFailure):
+        raise
+    except Exception as e:
+        logger.error(f"Error clipping episode: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to cut clip"
+        )
+
+
+@router.post("/{episode_id}/restore", response_model=SuccessResponse)
+async def restore_episode(
+    episode_id: str,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Restore a soft-deleted episode, moving an archived transcript back.
+
+    Args:
+        episode_id: ID of the episode
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        Success message
+
+    Raises:
+        HTTPException: If episode not found, not deleted, or its podcast is deleted
+    """
+    try:
+        episode = await find_episode(db, episode_id, workspace_id)
+        if not episode:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=f"Episode with ID '{episode_id}' not found"
+            )
+        if not episode.get("deleted_at"):
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail=f"Episode '{episode['title']}' is not deleted"
+            )
+
+        podcast = await db.podcasts.find_one({"podcast_id": episode["podcast_id"]})
+        if podcast and podcast.get("deleted_at"):
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail=f"Podcast '{podcast['title']}' is deleted; restore the podcast instead"
+            )
+
+        unarchived = await ArchiveService(db).restore_episode(episode)
+        await AuditService(db).record("episode.restored", "episode", episode_id, workspace_id)
+
+        return {
+            "message": f"Restored episode '{episode['title']}'",
+            "data": {"episode_id": episode_id, "transcript_unarchived": unarchived}
+        }
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error restoring episode: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to restore episode"
+        )
+
+
 @router.post("/{episode_id}/transcribe")
 async def trigger_episode_transcription(
     episode_id: str,
-    db: AsyncIOMotorDatabase = Depends(get_database)
+    x_api_key: Optional[str] = Header(None),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
 ):
     """
     Trigger transcription for a specific episode.
@@ -231,19 +860,22 @@ async def trigger_episode_transcription(
 
     Args:
         episode_id: ID of the episode to transcribe
+        x_api_key: Caller's API key, used for per-key quotas
         db: Database instance
+        workspace_id: Caller's workspace
 
     Returns:
         Success message with execution details
 
     Raises:
         HTTPException: If episode not found or transcription cannot be triggered
+        QuotaExceededError: If the episode would exceed the monthly quota
     """
     try:
         logger.info(f"Triggering transcription for episode: {episode_id}")
 
         # Find episode
-        episode = await db.episodes.find_one({"episode_id": episode_id})
+        episode = await find_episode(db, episode_id, workspace_id)
         if not episode:
             raise HTTPException(
                 status_code=status.HTTP_404_NOT_FOUND,
@@ -266,6 +898,13 @@ async def trigger_episode_transcription(
                 detail="Episode is already being transcribed"
             )
 
+        try:
+            encryption = await podcast_encryption(db, episode.get("podcast_id"))
+        except PrivateTranscriptError as e:
+            raise HTTPException(status_code=status.HTTP_409_CONFLICT, detail=str(e))
+
+        await QuotaService(db).reserve(episode_minutes(episode), x_api_key)
+
         # Update episode status to processing
         await db.episodes.update_one(
             {"episode_id": episode_id},
@@ -276,13 +915,23 @@ async def trigger_episode_transcription(
         try:
             execution_result = await step_functions_service.trigger_transcription(
                 episode_id=episode_id,
-                audio_url=audio_url
+                audio_url=audio_url,
+                storage=await podcast_storage(db, episode.get("podcast_id")),
+                encryption=encryption
             )
 
             logger.info(
                 f"Successfully triggered transcription for episode {episode_id}. "
                 f"Execution ARN: {execution_result['execution_arn']}"
             )
+            await db.episodes.update_one(
+                {"episode_id": episode_id},
+                {"$set": {"execution_arn": execution_result["execution_arn"]}}
+            )
+            await AuditService(db).record(
+                "transcription.started", "episode", episode_id, workspace_id,
+                {"execution_arn": execution_result["execution_arn"]}
+            )
 
             return {
                 "message": "Transcription started successfully",
@@ -314,7 +963,7 @@ async def trigger_episode_transcription(
                 detail=f"Failed to start transcription: {str(e)}"
             )
 
-    except HTTPException:
+    except (HTTPException, QuotaExceededError):
         raise
     except Exception as e:
         logger.error(f"Error triggering transcription: {e}")
@@ -324,8 +973,94 @@ async def trigger_episode_transcription(
         )
 
 
-def _format_episode_response(episode_doc: dict) -> EpisodeResponse:
+@router.get("/{episode_id}/pipeline", response_model=PipelineStatusResponse)
+async def get_episode_pipeline(
+    episode_id: str,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Show which pipeline stage an episode's transcription is in.
+
+    Episodes with a Step Functions execution are described from its history
+    (current state and Map chunk progress). Otherwise, or if the execution
+    can't be read, the stage and chunk counts the local orchestrator stores
+    on the episode are returned.
+
+    Args:
+        episode_id: ID of the episode
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        Stage, chunk progress and execution details
+
+    Raises:
+        HTTPException: If episode not found
+    """
+    try:
+        episode = await find_episode(db, episode_id, workspace_id, deleted_at=None)
+        if not episode:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=f"Episode with ID '{episode_id}' not found"
+            )
+
+        transcript_status = episode.get("transcript_status", "pending")
+        execution_arn = episode.get("execution_arn")
+        progress = episode.get("transcript_progress")
+
+        if execution_arn:
+            try:
+                execution = await step_functions_service.describe_pipeline(execution_arn)
+                if not progress and execution.get("chunks_total") and execution.get("stage") == "transcribing":
+                    progress = {
+                        "percent": round(100 * (execution.get("chunks_completed") or 0) / execution["chunks_total"], 1)
+                    }
+                return PipelineStatusResponse(
+                    episode_id=episode_id,
+                    source="step_functions",
+                    transcript_status=transcript_status,
+                    execution_arn=execution_arn,
+                    progress=progress,
+                    **execution
+                )
+            except Exception as e:
+                logger.warning(f"Could not describe execution {execution_arn}, using stored state: {e}")
+
+        stage = episode.get("processing_step")
+        if transcript_status in ("completed", "failed"):
+            stage = transcript_status
+
+        return PipelineStatusResponse(
+            episode_id=episode_id,
+            source="local",
+            transcript_status=transcript_status,
+            stage=stage,
+            chunks_completed=episode.get("chunks_completed"),
+            chunks_total=episode.get("chunks_total"),
+            progress=progress,
+            execution_arn=execution_arn,
+            error=episode.get("error_message")
+        )
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error fetching pipeline status: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to fetch pipeline status"
+        )
+
+
+def _format_episode_response(episode_doc: dict, quality_threshold: Optional[float] = None) -> EpisodeResponse:
     """Format episode document as response model."""
+    if quality_threshold is None:
+        quality_threshold = settings.transcript_quality_threshold
+    quality = episode_doc.get("transcript_quality")
+    low_confidence = quality["score"] < quality_threshold if quality else None
+
     # Extract podcast title from joined podcast data
     podcast_title = "Unknown Podcast"
     if "podcast" in episode_doc and episode_doc["podcast"]:
@@ -338,13 +1073,28 @@ def _format_episode_response(episode_doc: dict) -> EpisodeResponse:
         episode_title=episode_doc["title"],
         title=episode_doc["title"],  # For backwards compatibility
         description=episode_doc.get("description"),
+        description_html=episode_doc.get("description_html"),
+        show_notes=episode_doc.get("show_notes"),
         audio_url=episode_doc.get("audio_url"),
         published_date=episode_doc.get("published_date"),
         duration_minutes=episode_doc.get("duration_minutes"),
+        episode_type=episode_doc.get("episode_type"),
+        explicit=episode_doc.get("explicit"),
         s3_audio_key=episode_doc.get("s3_audio_key"),
         transcript_status=episode_doc.get("transcript_status", "pending"),
+        transcript_progress=episode_doc.get("transcript_progress"),
+        skip_reason=episode_doc.get("skip_reason"),
+        rerun_of=episode_doc.get("rerun_of"),
         processing_step=episode_doc.get("processing_step"),
+        execution_arn=episode_doc.get("execution_arn"),
         transcript_s3_key=episode_doc.get("transcript_s3_key"),
+        transcript_sha256=episode_doc.get("transcript_sha256"),
+        storage=episode_doc.get("storage"),
         discovered_at=episode_doc.get("discovered_at") or episode_doc.get("created_at"),
         processed_at=episode_doc.get("processed_at"),
+        estimated_cost=episode_doc.get("cost", {}).get("estimated"),
+        actual_cost=episode_doc.get("cost", {}).get("actual"),
+        ad_segments=episode_doc.get("ad_segments"),
+        transcript_quality=quality,
+        low_confidence=low_confidence,
     )
@@ -0,0 +1,151 @@
+"""Transcript export endpoints."""
+import io
+import logging
+import uuid
+from datetime import datetime
+from typing import IO, Iterator, Optional
+from fastapi import APIRouter, HTTPException, Depends, status
+from fastapi.responses import StreamingResponse
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.config import settings
+from app.database import get_database
+from app.models.schemas import ExportRequest, ExportResponse, ExportDelivery
+from app.services import s3_service
+from app.services.export_service import ExportService
+from app.validation import RequestValidationFailure
+from app.workspaces import current_workspace, workspace_podcast_ids
+
+# Presigned download links for staged exports expire after this many seconds
+EXPORT_URL_EXPIRY_SECONDS = 3600
+
+# Streamed archives are read in chunks of this size
+EXPORT_CHUNK_BYTES = 1024 * 1024
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter(prefix="/api/export", tags=["export"])
+
+
+@router.post("", response_model=ExportResponse)
+async def export_transcripts(
+    request: ExportRequest,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Export transcripts as a ZIP archive with a manifest.
+
+    The archive contains one file per completed episode matching the filters,
+    grouped by podcast, plus a manifest.json describing every entry. With
+    delivery=stream the archive is returned directly; with delivery=s3 it is
+    staged in the transcripts bucket and a presigned link is returned.
+
+    Args:
+        request: Export filters, format and delivery mode
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        ZIP archive stream or staged export details
+
+    Raises:
+        HTTPException: If nothing matches or the archive cannot be staged
+    """
+    try:
+        if (request.published_after and request.published_before
+                and request.published_after >= request.published_before):
+            raise RequestValidationFailure.single(
+                "published_after",
+                "invalid_range",
+                "published_after must be earlier than published_before"
+            )
+
+        # Within a workspace, an empty list must match nothing, not everything
+        podcast_ids = request.podcast_ids
+        allowed = await workspace_podcast_ids(db, workspace_id)
+        if allowed is not None:
+            podcast_ids = [p for p in (podcast_ids or allowed) if p in allowed] or ["-"]
+
+        service = ExportService(db)
+        episodes = await service.find_episodes(
+            podcast_ids=podcast_ids,
+            published_after=request.published_after,
+            published_before=request.published_before,
+        )
+
+        if not episodes:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail="No completed transcripts match the export filters"
+            )
+        # Private podcasts' transcripts are never left behind a presigned link
+        if request.delivery == ExportDelivery.S3 and any(
+            (e.get("podcast") or {}).get("private") for e in episodes
+        ):
+            raise RequestValidationFailure.single(
+                "delivery",
+                "private_podcast",
+                "Exports including private podcasts must use delivery=stream"
+            )
+
+        filters = {
+            "podcast_ids": request.podcast_ids,
+            "published_after": request.published_after,
+            "published_before": request.published_before,
+        }
+        exclude_ads = settings.ad_exclude_from_exports if request.exclude_ads is None else request.exclude_ads
+        archive, episode_count = await service.build_archive(
+            episodes, request.format.value, filters, exclude_ads
+        )
+
+        export_id = f"exp_{uuid.uuid4().hex[:12]}"
+        file_name = f"transcripts-{datetime.utcnow().strftime('%Y%m%d')}-{export_id}.zip"
+        size = archive.seek(0, io.SEEK_END)
+        archive.seek(0)
+        logger.info(f"Built export {export_id} with {episode_count} transcripts ({size} bytes)")
+
+        if request.delivery == ExportDelivery.STREAM:
+            return StreamingResponse(
+                _read_chunks(archive),
+                media_type="application/zip",
+                headers={
+                    "Content-Disposition": f'attachment; filename="{file_name}"',
+                    "Content-Length": str(size),
+                }
+            )
+
+        s3_key = f"exports/{file_name}"
+        try:
+            uploaded = await s3_service.upload_fileobj(s3_key, archive, "application/zip")
+        finally:
+            archive.close()
+        if not uploaded:
+            raise HTTPException(
+                status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+                detail="Failed to stage export in S3"
+            )
+
+        return ExportResponse(
+            export_id=export_id,
+            s3_key=s3_key,
+            download_url=s3_service.generate_presigned_url(s3_key, EXPORT_URL_EXPIRY_SECONDS),
+            expires_in=EXPORT_URL_EXPIRY_SECONDS,
+            episode_count=episode_count,
+        )
+
+    except (HTTPException, RequestValidationFailure):
+        raise
+    except Exception as e:
+        logger.error(f"Error exporting transcripts: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to export transcripts"
+        )
+
+
+def _read_chunks(archive: IO[bytes]) -> Iterator[bytes]:
+    """Read an archive in chunks for a streamed response, closing it at the end."""
+    with archive:
+        while chunk := archive.read(EXPORT_CHUNK_BYTES):
+            yield chunk
@@ -0,0 +1,161 @@
+"""Search for and transcribe episodes of shows nobody subscribed to."""
+import logging
+from typing import Optional
+from fastapi import APIRouter, BackgroundTasks, HTTPException, Depends, Header, Query, status
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.database import get_database
+from app.models.schemas import (
+    ExternalEpisodeSearchResponse,
+    ExternalTranscribeResponse,
+    JobPriority,
+    TranscriptStatus,
+)
+from app.services.audit_service import AuditService
+from app.services.episode_search import (
+    EpisodeSearchProvider,
+    ExternalEpisodeError,
+    get_search_provider,
+    import_external_episode,
+    mark_imported,
+)
+from app.services.orchestration_service import get_orchestration_service
+from app.services.quota_service import QuotaService, episode_minutes
+from app.workspaces import current_workspace, workspace_podcast_ids
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter(prefix="/api/external", tags=["external"])
+
+
+def _provider() -> EpisodeSearchProvider:
+    provider = get_search_provider()
+    if not provider:
+        raise HTTPException(
+            status_code=status.HTTP_503_SERVICE_UNAVAILABLE,
+            detail="Episode search not configured (set EPISODE_SEARCH_PROVIDER to listennotes or taddy)"
+        )
+    return provider
+
+
+@router.get("/episodes", response_model=ExternalEpisodeSearchResponse)
+async def search_external_episodes(
+    q: str = Query(..., min_length=2, max_length=200, description="Search terms"),
+    page: int = Query(1, ge=1, le=100, description="Page of results"),
+    limit: int = Query(10, ge=1, le=25, description="Results per page"),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Search the episode search provider for episodes of any show.
+
+    Results already in the caller's library carry their episode_id and
+    transcript_status.
+
+    Args:
+        q: Search terms
+        page: Page of results
+        limit: Results per page (Listen Notes' free plan always returns 10)
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        Matching episodes, most relevant first
+
+    Raises:
+        HTTPException: If search isn't configured or the provider fails
+    """
+    provider = _provider()
+    try:
+        results = await provider.search(q, page, limit)
+        await mark_imported(db, results, await workspace_podcast_ids(db, workspace_id))
+        return ExternalEpisodeSearchResponse(provider=provider.name, query=q, page=page, results=results)
+
+    except Exception as e:
+        logger.error(f"Error searching {provider.name} for episodes: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_502_BAD_GATEWAY,
+            detail="Episode search failed"
+        )
+
+
+@router.post("/episodes/{external_id}/transcribe", response_model=ExternalTranscribeResponse)
+async def transcribe_external_episode(
+    external_id: str,
+    background_tasks: BackgroundTasks,
+    priority: JobPriority = Query(JobPriority.HIGH, description="Queue priority"),
+    x_api_key: Optional[str] = Header(None),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Import one episode found by search and start transcribing it.
+
+    Only this item is imported: its show gets an inactive podcast record
+    (subscribing later keeps the episode) and no other episodes are added.
+    Returns 402/429 if the episode would exceed the monthly quota.
+
+    Args:
+        external_id: Provider's episode ID, from search results
+        background_tasks: Runs the transcription after responding
+        priority: Queue priority
+        x_api_key: Caller's API key, for the quota
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        The library episode and whether transcription started
+
+    Raises:
+        HTTPException: If search isn't configured, the episode doesn't exist
+            or can't be imported
+    """
+    provider = _provider()
+    try:
+        item = await provider.get_episode(external_id)
+    except Exception as e:
+        logger.error(f"Error fetching {provider.name} episode {external_id}: {e}")
+        raise HTTPException(status_code=status.HTTP_502_BAD_GATEWAY, detail="Episode lookup failed")
+    if not item:
+        raise HTTPException(
+            status_code=status.HTTP_404_NOT_FOUND,
+            detail=f"Episode '{external_id}' not found at {provider.name}"
+        )
+
+    try:
+        episode = await import_external_episode(db, item, workspace_id)
+    except ExternalEpisodeError as e:
+        raise HTTPException(status_code=status.HTTP_409_CONFLICT, detail=str(e))
+
+    episode_id = episode["episode_id"]
+    response = {"episode_id": episode_id, "podcast_id": episode["podcast_id"]}
+    current_status = episode.get("transcript_status")
+    if current_status == TranscriptStatus.PROCESSING.value:
+        return ExternalTranscribeResponse(
+            status="already_processing", message="Transcription is already in progress", **response
+        )
+    if current_status == TranscriptStatus.COMPLETED.value:
+        return ExternalTranscribeResponse(
+            status="already_completed", message="Transcription is already completed", **response
+        )
+
+    await QuotaService(db).reserve(episode_minutes(episode), x_api_key)
+
+    orchestration_service = get_orchestration_service()
+
+    async def run_transcription():
+        try:
+            await orchestration_service.transcribe_episode(
+                episode_id=episode_id,
+                audio_url=episode["audio_url"],
+                priority=priority
+            )
+        except Exception as e:
+            logger.error(f"Background transcription failed for {episode_id}: {e}")
+
+    background_tasks.add_task(run_transcription)
+    await AuditService(db).record(
+        "transcription.started", "episode", episode_id, workspace_id,
+        {"priority": priority.value, "provider": provider.name, "external_id": external_id}
+    )
+    return ExternalTranscribeResponse(status="started", message="Transcription workflow started", **response)
@@ -0,0 +1,231 @@
+"""Per-user starred episodes and bookmarked transcript passages."""
+import logging
+import uuid
+from datetime import datetime
+from typing import Optional
+from fastapi import APIRouter, HTTPException, Depends, Query, status
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.database import get_database
+from app.models.schemas import (
+    FavoriteEpisodeListResponse,
+    FavoriteEpisodeResponse,
+    SuccessResponse,
+    TranscriptBookmarkCreate,
+    TranscriptBookmarkListResponse,
+    TranscriptBookmarkResponse,
+)
+from app.validation import RequestValidationFailure
+from app.workspaces import current_workspace, find_episode, scoped, stamp
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter(prefix="/api/users", tags=["favorites"])
+
+
+async def _get_episode(db: AsyncIOMotorDatabase, episode_id: str, workspace_id: Optional[str]) -> dict:
+    """Find an episode in the caller's workspace or raise 404."""
+    episode = await find_episode(db, episode_id, workspace_id, deleted_at=None)
+    if not episode:
+        raise HTTPException(
+            status_code=status.HTTP_404_NOT_FOUND,
+            detail=f"Episode with ID '{episode_id}' not found"
+        )
+    return episode
+
+
+@router.get("/{user_id}/favorites", response_model=FavoriteEpisodeListResponse)
+async def list_favorites(
+    user_id: str,
+    podcast_id: Optional[str] = Query(None, description="Only episodes of this podcast"),
+    limit: int = Query(50, ge=1, le=500, description="Maximum favorites to return"),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """List a user's starred episodes, newest first."""
+    try:
+        query = scoped({"user_id": user_id}, workspace_id)
+        if podcast_id:
+            query["podcast_id"] = podcast_id
+
+        total = await db.favorite_episodes.count_documents(query)
+        cursor = db.favorite_episodes.find(query, {"_id": 0}).sort("created_at", -1).limit(limit)
+        favorites = [FavoriteEpisodeResponse(**favorite) async for favorite in cursor]
+        return FavoriteEpisodeListResponse(favorites=favorites, total=total)
+
+    except Exception as e:
+        logger.error(f"Error listing favorites for {user_id}: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to list favorites"
+        )
+
+
+@router.put("/{user_id}/favorites/{episode_id}", response_model=FavoriteEpisodeResponse)
+async def star_episode(
+    user_id: str,
+    episode_id: str,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Star an episode for a user. Starring an already starred episode is a no-op.
+
+    Args:
+        user_id: User identifier
+        episode_id: Episode identifier
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        The favorite
+
+    Raises:
+        HTTPException: If the episode doesn't exist
+    """
+    try:
+        episode = await _get_episode(db, episode_id, workspace_id)
+        await db.favorite_episodes.update_one(
+            {"user_id": user_id, "episode_id": episode_id},
+            {"$setOnInsert": stamp({
+                "user_id": user_id,
+                "episode_id": episode_id,
+                "podcast_id": episode["podcast_id"],
+                "episode_title": episode.get("title"),
+                "created_at": datetime.utcnow(),
+            }, workspace_id)},
+            upsert=True
+        )
+        favorite = await db.favorite_episodes.find_one({"user_id": user_id, "episode_id": episode_id}, {"_id": 0})
+        return FavoriteEpisodeResponse(**favorite)
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error starring episode {episode_id} for {user_id}: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to star episode"
+        )
+
+
+@router.delete("/{user_id}/favorites/{episode_id}", response_model=SuccessResponse)
+async def unstar_episode(
+    user_id: str,
+    episode_id: str,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """Remove an episode from a user's favorites."""
+    result = await db.favorite_episodes.delete_one(
+        scoped({"user_id": user_id, "episode_id": episode_id}, workspace_id)
+    )
+    if result.deleted_count == 0:
+        raise HTTPException(
+            status_code=status.HTTP_404_NOT_FOUND,
+            detail=f"Episode '{episode_id}' is not starred"
+        )
+    return {"message": f"Unstarred episode '{episode_id}'", "data": {"episode_id": episode_id}}
+
+
+@router.get("/{user_id}/bookmarks", response_model=TranscriptBookmarkListResponse)
+async def list_bookmarks(
+    user_id: str,
+    episode_id: Optional[str] = Query(None, description="Only passages of this episode"),
+    podcast_id: Optional[str] = Query(None, description="Only passages of this podcast"),
+    limit: int = Query(50, ge=1, le=500, description="Maximum bookmarks to return"),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """List a user's bookmarked transcript passages, newest first."""
+    try:
+        query = scoped({"user_id": user_id}, workspace_id)
+        if episode_id:
+            query["episode_id"] = episode_id
+        if podcast_id:
+            query["podcast_id"] = podcast_id
+
+        total = await db.transcript_bookmarks.count_documents(query)
+        cursor = db.transcript_bookmarks.find(query, {"_id": 0}).sort("created_at", -1).limit(limit)
+        bookmarks = [TranscriptBookmarkResponse(**bookmark) async for bookmark in cursor]
+        return TranscriptBookmarkListResponse(bookmarks=bookmarks, total=total)
+
+    except Exception as e:
+        logger.error(f"Error listing bookmarks for {user_id}: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to list bookmarks"
+        )
+
+
+@router.post(
+    "/{user_id}/bookmarks",
+    response_model=TranscriptBookmarkResponse,
+    status_code=status.HTTP_201_CREATED
+)
+async def create_bookmark(
+    user_id: str,
+    request: TranscriptBookmarkCreate,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Bookmark a transcript passage, e.g. one found through search.
+
+    Args:
+        user_id: User identifier
+        request: Episode, time range and quote
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        The bookmark
+
+    Raises:
+        HTTPException: If the episode doesn't exist or the range is invalid
+    """
+    if request.end_seconds < request.start_seconds:
+        raise RequestValidationFailure.single(
+            "end_seconds", "invalid_range", "end_seconds must not be before start_seconds"
+        )
+
+    try:
+        episode = await _get_episode(db, request.episode_id, workspace_id)
+        bookmark = stamp({
+            "bookmark_id": f"bm_{uuid.uuid4().hex[:12]}",
+            "user_id": user_id,
+            "podcast_id": episode["podcast_id"],
+            **request.model_dump(),
+            "created_at": datetime.utcnow(),
+        }, workspace_id)
+        await db.transcript_bookmarks.insert_one(bookmark)
+        bookmark.pop("_id", None)
+        return TranscriptBookmarkResponse(**bookmark)
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error bookmarking episode {request.episode_id} for {user_id}: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to create bookmark"
+        )
+
+
+@router.delete("/{user_id}/bookmarks/{bookmark_id}", response_model=SuccessResponse)
+async def delete_bookmark(
+    user_id: str,
+    bookmark_id: str,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """Delete one of a user's bookmarks."""
+    result = await db.transcript_bookmarks.delete_one(
+        scoped({"user_id": user_id, "bookmark_id": bookmark_id}, workspace_id)
+    )
+    if result.deleted_count == 0:
+        raise HTTPException(
+            status_code=status.HTTP_404_NOT_FOUND,
+            detail=f"Bookmark '{bookmark_id}' not found"
+        )
+    return {"message": f"Deleted bookmark '{bookmark_id}'", "data": {"bookmark_id": bookmark_id}}
@@ -0,0 +1,124 @@
+"""Syndication feeds publishing the pipeline's output."""
+import logging
+from datetime import datetime
+from email.utils import format_datetime
+from typing import Optional
+from xml.sax.saxutils import escape
+from fastapi import APIRouter, HTTPException, Depends, Query, Response, status
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.config import settings
+from app.database import get_database
+from app.workspaces import current_workspace, workspace_podcast_ids
+
+# Constants
+DEFAULT_FEED_LIMIT = 50
+MAX_FEED_LIMIT = 200
+SUMMARY_MAX_CHARS = 500
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter(prefix="/feeds", tags=["feeds"])
+
+
+@router.get("/transcribed.rss")
+async def transcribed_episodes_feed(
+    podcast_id: Optional[str] = Query(None, description="Only include episodes of this podcast"),
+    limit: int = Query(DEFAULT_FEED_LIMIT, ge=1, le=MAX_FEED_LIMIT, description="Number of items"),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Publish recently completed transcriptions as an RSS 2.0 feed.
+
+    Each item links to the episode's transcript endpoint and carries the
+    episode summary (or a truncated description when no summary exists).
+
+    Args:
+        podcast_id: Optional podcast filter
+        limit: Maximum number of items
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        RSS XML document
+    """
+    try:
+        query = {"transcript_status": "completed", "deleted_at": None}
+        if podcast_id:
+            query["podcast_id"] = podcast_id
+        podcast_ids = await workspace_podcast_ids(db, workspace_id)
+        if podcast_ids is not None:
+            query["podcast_id"] = {"$in": [p for p in podcast_ids if not podcast_id or p == podcast_id]}
+
+        pipeline = [
+            {"$match": query},
+            {"$sort": {"processed_at": -1}},
+            {"$limit": limit},
+            {
+                "$lookup": {
+                    "from": "podcasts",
+                    "localField": "podcast_id",
+                    "foreignField": "podcast_id",
+                    "as": "podcast"
+                }
+            },
+            {"$unwind": {"path": "$podcast", "preserveNullAndEmptyArrays": True}}
+        ]
+        episodes = await db.episodes.aggregate(pipeline).to_list(length=limit)
+
+        base_url = settings.public_base_url.rstrip("/")
+        feed_url = f"{base_url}/feeds/transcribed.rss"
+        items = [_render_item(episode, base_url) for episode in episodes]
+
+        last_build = episodes[0].get("processed_at") if episodes else None
+        xml = (
+            '<?xml version="1.0" encoding="UTF-8"?>\n'
+            '<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">\n'
+            "<channel>\n"
+            "<title>Transcribed Episodes</title>\n"
+            f"<link>{escape(base_url)}</link>\n"
+            f'<atom:link href="{escape(feed_url)}" rel="self" type="application/rss+xml"/>\n'
+            "<description>Recently completed podcast transcriptions</description>\n"
+            f"<lastBuildDate>{_rfc822(last_build or datetime.utcnow())}</lastBuildDate>\n"
+            + "".join(items)
+            + "</channel>\n</rss>\n"
+        )
+
+        return Response(content=xml, media_type="application/rss+xml")
+
+    except Exception as e:
+        logger.error(f"Error building transcribed episodes feed: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to build feed"
+        )
+
+
+def _render_item(episode: dict, base_url: str) -> str:
+    """Render a single episode as an RSS <item>."""
+    # Titles can be stored as null, which escape() rejects
+    podcast_title = (episode.get("podcast") or {}).get("title") or "Unknown Podcast"
+    episode_title = episode.get("title") or "Untitled Episode"
+    transcript_url = f"{base_url}/api/episodes/{episode['episode_id']}/transcript"
+
+    summary = episode.get("summary") or episode.get("description") or ""
+    if len(summary) > SUMMARY_MAX_CHARS:
+        summary = summary[:SUMMARY_MAX_CHARS].rsplit(" ", 1)[0] + "..."
+
+    pub_date = episode.get("processed_at") or episode.get("published_date")
+
+    return (
+        "<item>\n"
+        f"<title>{escape(podcast_title)}: {escape(episode_title)}</title>\n"
+        f"<link>{escape(transcript_url)}</link>\n"
+        f'<guid isPermaLink="false">{escape(episode["episode_id"])}</guid>\n'
+        f"<description>{escape(summary)}</description>\n"
+        + (f"<pubDate>{_rfc822(pub_date)}</pubDate>\n" if pub_date else "")
+        + "</item>\n"
+    )
+
+
+def _rfc822(value: datetime) -> str:
+    """Format a naive UTC datetime as an RFC 822 date."""
+    return format_datetime(value, usegmt=False).replace("-0000", "+0000")
@@ -0,0 +1,215 @@
+"""Notification preference endpoints."""
+import logging
+from datetime import datetime
+from typing import Literal, Optional
+from fastapi import APIRouter, HTTPException, Depends, Query, Response, status
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.database import get_database
+from app.models.schemas import (
+    DigestListResponse,
+    DigestResponse,
+    NotificationPreferencesRequest,
+    NotificationPreferencesResponse,
+    SuccessResponse,
+)
+from app.services.notification_service import NotificationService, email_notifier
+from app.services.s3_service import s3_service
+from app.validation import RequestValidationFailure
+from app.workspaces import current_workspace, in_workspace, scoped, stamp
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter(prefix="/api/notifications", tags=["notifications"])
+
+
+@router.get("/preferences/{email}", response_model=NotificationPreferencesResponse)
+async def get_notification_preferences(
+    email: str,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """Get notification preferences for a user."""
+    preferences = await db.notification_preferences.find_one(scoped({"email": email.lower()}, workspace_id))
+    if not preferences:
+        raise HTTPException(
+            status_code=status.HTTP_404_NOT_FOUND,
+            detail=f"No notification preferences for '{email}'"
+        )
+    return _format_preferences_response(preferences)
+
+
+@router.put("/preferences/{email}", response_model=NotificationPreferencesResponse)
+async def update_notification_preferences(
+    email: str,
+    request: NotificationPreferencesRequest,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Create or update notification preferences for a user.
+
+    Args:
+        email: Recipient email address
+        request: Preference settings
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        Stored preferences
+
+    Raises:
+        HTTPException: If the address has preferences in another workspace
+    """
+    if "@" not in email:
+        raise RequestValidationFailure.single("email", "invalid_email", "Invalid email address")
+
+    email = email.lower()
+    # Addresses are unique per deployment, like feeds
+    existing = await db.notification_preferences.find_one({"email": email}, {"workspace_id": 1})
+    if existing and not in_workspace(existing, workspace_id):
+        raise HTTPException(
+            status_code=status.HTTP_409_CONFLICT,
+            detail=f"'{email}' has notification preferences in another workspace"
+        )
+
+    try:
+        await db.notification_preferences.update_one(
+            {"email": email},
+            {
+                "$set": {
+                    "email_enabled": request.email_enabled,
+                    "podcast_ids": request.podcast_ids,
+                    "frequency": request.frequency.value,
+                    "updated_at": datetime.utcnow(),
+                },
+                "$setOnInsert": stamp({"email": email, "last_notified_at": None}, workspace_id),
+            },
+            upsert=True
+        )
+        preferences = await db.notification_preferences.find_one({"email": email})
+        logger.info(f"Updated notification preferences for {email}")
+        return _format_preferences_response(preferences)
+
+    except Exception as e:
+        logger.error(f"Error updating notification preferences: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to update notification preferences"
+        )
+
+
+@router.delete("/preferences/{email}", response_model=SuccessResponse)
+async def delete_notification_preferences(
+    email: str,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """Remove a user's notification preferences, stopping all digests."""
+    result = await db.notification_preferences.delete_one(scoped({"email": email.lower()}, workspace_id))
+    if result.deleted_count == 0:
+        raise HTTPException(
+            status_code=status.HTTP_404_NOT_FOUND,
+            detail=f"No notification preferences for '{email}'"
+        )
+    return {"message": f"Notifications disabled for '{email}'", "data": {"email": email.lower()}}
+
+
+@router.post("/digest", response_model=SuccessResponse)
+async def send_digests(
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """Send the workspace's due transcription digests immediately."""
+    if not email_notifier.enabled:
+        raise HTTPException(
+            status_code=status.HTTP_503_SERVICE_UNAVAILABLE,
+            detail="Email backend not configured (set EMAIL_BACKEND to smtp or ses)"
+        )
+
+    try:
+        counts = await NotificationService(db).send_due_digests(workspace_id)
+        return {"message": f"Sent {counts['sent']} digest(s)", "data": counts}
+    except Exception as e:
+        logger.error(f"Error sending digests: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to send digests"
+        )
+
+
+@router.get("/digests/{email}", response_model=DigestListResponse)
+async def list_digests(
+    email: str,
+    limit: int = Query(20, ge=1, le=100, description="Maximum digests to return"),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """List a user's past digests, newest first, including undelivered ones."""
+    digests = await NotificationService(db).list_digests(email.lower(), limit, workspace_id)
+    return DigestListResponse(digests=[_format_digest_response(d) for d in digests], total=len(digests))
+
+
+@router.get("/digests/{email}/{digest_id}")
+async def get_digest(
+    email: str,
+    digest_id: str,
+    format: Literal["json", "markdown", "html"] = Query("json", description="Metadata, or a stored rendering"),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Get a past digest.
+
+    Args:
+        email: Recipient email address
+        digest_id: Digest identifier
+        format: json for its metadata, markdown or html for its content
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        The digest's metadata or content
+
+    Raises:
+        HTTPException: If the digest or the requested rendering doesn't exist
+    """
+    digest = await NotificationService(db).get_digest(email.lower(), digest_id, workspace_id)
+    if not digest:
+        raise HTTPException(status_code=status.HTTP_404_NOT_FOUND, detail="Digest not found")
+    if format == "json":
+        return _format_digest_response(digest)
+
+    s3_key = digest.get(f"{format}_s3_key")
+    try:
+        content = await s3_service.get_transcript(s3_key) if s3_key else None
+    except Exception as e:
+        logger.error(f"Error fetching digest {digest_id} from S3: {e}")
+        content = None
+    if content is None:
+        raise HTTPException(
+            status_code=status.HTTP_404_NOT_FOUND,
+            detail=f"No {format} copy of digest '{digest_id}' is stored"
+        )
+    media_type = "text/markdown; charset=utf-8" if format == "markdown" else "text/html; charset=utf-8"
+    return Response(content=content, media_type=media_type)
+
+
+def _format_digest_response(digest: dict) -> DigestResponse:
+    """Format digest document as response model."""
+    return DigestResponse(
+        **{key: value for key, value in digest.items() if not key.endswith("_s3_key")},
+        formats=[fmt for fmt in ("markdown", "html") if digest.get(f"{fmt}_s3_key")],
+    )
+
+
+def _format_preferences_response(preferences: dict) -> NotificationPreferencesResponse:
+    """Format preferences document as response model."""
+    return NotificationPreferencesResponse(
+        email=preferences["email"],
+        email_enabled=preferences.get("email_enabled", True),
+        podcast_ids=preferences.get("podcast_ids"),
+        frequency=preferences.get("frequency", "daily"),
+        last_notified_at=preferences.get("last_notified_at"),
+        updated_at=preferences["updated_at"],
+    )
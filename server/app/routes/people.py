@@ -0,0 +1,60 @@
+"""People (hosts and guests) endpoints."""
+import logging
+from typing import Optional
+from fastapi import APIRouter, HTTPException, Depends, Query, status
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.database import get_database
+from app.models.schemas import GuestAppearancesResponse
+from app.services.guest_index import GuestIndexService
+from app.workspaces import current_workspace, workspace_podcast_ids
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter(prefix="/api/people", tags=["people"])
+
+
+@router.get("/{name}/appearances", response_model=GuestAppearancesResponse)
+async def get_appearances(
+    name: str,
+    limit: int = Query(50, ge=1, le=200, description="Maximum appearances to return"),
+    offset: int = Query(0, ge=0, description="Appearances to skip"),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Get the episodes a person appears in across the caller's podcasts,
+    newest first.
+
+    People come from the feeds' <podcast:person> tags and from names the
+    transcripts introduce or label as speakers. The index is updated in the
+    background every GUEST_INDEX_INTERVAL_HOURS.
+
+    Args:
+        name: Person's name (case- and accent-insensitive)
+        limit: Maximum appearances to return
+        offset: Appearances to skip
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        The person's appearances and their total
+
+    Raises:
+        HTTPException: If the person appears in none of the caller's podcasts
+    """
+    try:
+        podcast_ids = await workspace_podcast_ids(db, workspace_id)
+        result = await GuestIndexService(db).appearances(name, podcast_ids, limit=limit, offset=offset)
+        if not result["total"]:
+            raise HTTPException(status_code=status.HTTP_404_NOT_FOUND, detail="No appearances found")
+        return GuestAppearancesResponse(**result)
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error getting appearances of {name}: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to get appearances"
+        )
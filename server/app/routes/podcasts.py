@@ -1,11 +1,18 @@
 """Podcast management endpoints."""
+import asyncio
 import logging
+import re
 import uuid
 from datetime import datetime
-from fastapi import APIRouter, HTTPException, Depends, status, BackgroundTasks
+from typing import Optional
+from fastapi import APIRouter, HTTPException, Depends, status, BackgroundTasks, File, Form, UploadFile
+from fastapi.responses import JSONResponse
 from motor.motor_asyncio import AsyncIOMotorDatabase
+from pymongo import ReturnDocument
 from pymongo.errors import DuplicateKeyError
 
+from app.cache import cache
+from app.config import settings
 from app.database import get_database
 from app.models import (
     SubscribePodcastRequest,
@@ -13,74 +20,205 @@ from app.models import (
     PodcastListResponse,
     SuccessResponse,
 )
+from app.models.schemas import (
+    CleanupMode,
+    CleanupJobResponse,
+    EpisodeFilter,
+    FeedCandidatesResponse,
+    ImportSource,
+    PodcastPrivacyUpdate,
+    PodcastWorkflow,
+    StorageLocationUpdate,
+    SubscriptionImportResponse,
+)
 from app.services import rss_parser, lambda_service
+from app.services.archive_service import ArchiveService
+from app.services.audit_service import AuditService
+from app.services.cleanup_service import CleanupService
+from app.services.feed_discovery import discover_podcast_feeds
 from app.services.orchestration_service import get_orchestration_service
+from app.services.private_transcripts import encrypt_existing
+from app.services.subscription_import import (
+    ImportFormatError,
+    parse_export,
+    subscription_import_service,
+)
+from app.services.quota_service import QuotaService, QuotaExceededError, episode_minutes
+from app.services.storage_locations import location_document
+from app.services.workspace_service import DEFAULT_WORKSPACE_ID
+from app.url_normalization import normalize_url
+from app.validation import RequestValidationFailure
+from app.workspaces import current_workspace, in_workspace, scoped, stamp
 
 logger = logging.getLogger(__name__)
 
 router = APIRouter(prefix="/api/podcasts", tags=["podcasts"])
 
 
-@router.post("/subscribe", response_model=PodcastResponse, status_code=status.HTTP_201_CREATED)
+async def _resume_subscription(
+    db: AsyncIOMotorDatabase,
+    urls: set,
+    workspace_id: Optional[str]
+) -> Optional[PodcastResponse]:
+    """
+    Handle a subscribe request for a podcast that's already in the database.
+
+    Args:
+        db: Database instance
+        urls: Feed URLs the podcast may be stored under
+        workspace_id: Caller's workspace
+
+    Returns:
+        The restored or reactivated podcast, or None if it isn't known
+
+    Raises:
+        HTTPException: If already subscribed, here or in another workspace
+    """
+    # Podcasts added before normalization keep their original URL
+    existing_podcast = await db.podcasts.find_one({"rss_url": {"$in": list(urls)}})
+    if not existing_podcast:
+        return None
+    # Feeds (and so their episodes) are unique per deployment
+    if not in_workspace(existing_podcast, workspace_id):
+        raise HTTPException(
+            status_code=status.HTTP_409_CONFLICT,
+            detail="This feed is already subscribed in another workspace"
+        )
+
+    existing_filter = {"podcast_id": existing_podcast["podcast_id"]}
+    # If podcast exists but was deleted, restore it with its episodes
+    if existing_podcast.get("deleted_at"):
+        await ArchiveService(db).restore_podcast(existing_podcast["podcast_id"])
+        await db.podcasts.update_one(
+            existing_filter,
+            {"$set": {"subscribed_at": datetime.utcnow()}}
+        )
+        logger.info(f"Restored deleted podcast: {existing_podcast['podcast_id']}")
+        await AuditService(db).record(
+            "podcast.subscribed", "podcast", existing_podcast["podcast_id"], workspace_id, {"resumed": "restored"}
+        )
+
+        updated_podcast = await db.podcasts.find_one(existing_filter)
+        return _format_podcast_response(updated_podcast)
+
+    # If podcast exists but is inactive, reactivate it
+    if not existing_podcast.get("active", True):
+        await db.podcasts.update_one(
+            existing_filter,
+            {"$set": {"active": True, "subscribed_at": datetime.utcnow()}}
+        )
+        logger.info(f"Reactivated podcast: {existing_podcast['podcast_id']}")
+        await cache.invalidate("episodes")
+        await AuditService(db).record(
+            "podcast.subscribed", "podcast", existing_podcast["podcast_id"], workspace_id, {"resumed": "reactivated"}
+        )
+
+        # Fetch updated podcast
+        updated_podcast = await db.podcasts.find_one(existing_filter)
+        return _format_podcast_response(updated_podcast)
+
+    raise HTTPException(
+        status_code=status.HTTP_409_CONFLICT,
+        detail="Already subscribed to this podcast"
+    )
+
+
+@router.post(
+    "",
+    response_model=PodcastResponse,
+    status_code=status.HTTP_201_CREATED,
+    responses={status.HTTP_300_MULTIPLE_CHOICES: {"model": FeedCandidatesResponse}},
+)
+@router.post(
+    "/subscribe",
+    response_model=PodcastResponse,
+    status_code=status.HTTP_201_CREATED,
+    responses={status.HTTP_300_MULTIPLE_CHOICES: {"model": FeedCandidatesResponse}},
+)
 async def subscribe_to_podcast(
     request: SubscribePodcastRequest,
-    db: AsyncIOMotorDatabase = Depends(get_database)
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
 ):
     """
-    Subscribe to a podcast by RSS feed URL.
+    Subscribe to a podcast by RSS feed URL or website URL.
 
     This endpoint:
     1. Parses the RSS feed to extract podcast metadata
-    2. Saves the podcast to the database
-    3. Returns the podcast details
+    2. If the URL is a web page rather than a podcast feed, discovers the
+       feeds it links (<link rel="alternate" type="application/rss+xml">):
+       a single podcast feed is subscribed to, several are returned as
+       candidates with 300 Multiple Choices for the client to pick from
+    3. Saves the podcast to the database
+    4. Returns the podcast details
 
     Args:
-        request: Subscribe request containing RSS feed URL
+        request: Subscribe request containing RSS feed or website URL
         db: Database instance
+        workspace_id: Caller's workspace
 
     Returns:
-        Podcast details
+        Podcast details, or feed candidates
 
     Raises:
         HTTPException: If RSS feed is invalid or already subscribed
     """
     try:
-        rss_url = str(request.rss_url)
+        # Canonical feed URL, so the same show subscribed via a tracking
+        # redirect or different casing/query order isn't added twice
+        requested_url = str(request.rss_url)
+        rss_url = normalize_url(requested_url)
         logger.info(f"Subscribing to podcast: {rss_url}")
 
-        # Check if already subscribed
-        existing_podcast = await db.podcasts.find_one({"rss_url": rss_url})
-        if existing_podcast:
-            # If podcast exists but is inactive, reactivate it
-            if not existing_podcast.get("active", True):
-                await db.podcasts.update_one(
-                    {"rss_url": rss_url},
-                    {"$set": {"active": True, "subscribed_at": datetime.utcnow()}}
-                )
-                logger.info(f"Reactivated podcast: {existing_podcast['podcast_id']}")
-
-                # Fetch updated podcast
-                updated_podcast = await db.podcasts.find_one({"rss_url": rss_url})
-                return _format_podcast_response(updated_podcast)
-            else:
-                raise HTTPException(
-                    status_code=status.HTTP_409_CONFLICT,
-                    detail="Already subscribed to this podcast"
-                )
+        resumed = await _resume_subscription(db, {rss_url, requested_url}, workspace_id)
+        if resumed:
+            return resumed
 
         # Parse RSS feed to get podcast metadata and episode count
+        from app.services.rss_parser import parse_rss_feed
+        feed_error = None
+        episodes = []
         try:
             # Use the optimized parser that fetches the feed once
-            from app.services.rss_parser import parse_rss_feed
             podcast_data, episodes = await parse_rss_feed(rss_url)
-            episode_count = len(episodes)
-            logger.info(f"Found {episode_count} episodes in RSS feed")
         except ValueError as e:
-            logger.error(f"Failed to parse RSS feed: {e}")
-            raise HTTPException(
-                status_code=status.HTTP_400_BAD_REQUEST,
-                detail=f"Invalid RSS feed: {str(e)}"
+            feed_error = e
+
+        # Not a podcast feed: maybe the podcast's website
+        if request.discover and (feed_error or not any(ep.get("audio_url") for ep in episodes)):
+            candidates = await discover_podcast_feeds(rss_url)
+            if len(candidates) > 1:
+                logger.info(f"Found {len(candidates)} podcast feeds on {rss_url}")
+                return JSONResponse(
+                    status_code=status.HTTP_300_MULTIPLE_CHOICES,
+                    content=FeedCandidatesResponse(
+                        detail="This page links several podcast feeds; subscribe to one of the candidates",
+                        candidates=candidates,
+                    ).model_dump()
+                )
+            if candidates:
+                rss_url = candidates[0]["url"]
+                logger.info(f"Discovered podcast feed {rss_url} on {requested_url}")
+                resumed = await _resume_subscription(db, {rss_url}, workspace_id)
+                if resumed:
+                    return resumed
+                try:
+                    podcast_data, episodes = await parse_rss_feed(rss_url)
+                    feed_error = None
+                except ValueError as e:
+                    feed_error = e
+            # Otherwise a feed without episodes yet is subscribed to as before
+
+        if feed_error:
+            logger.error(f"Failed to parse RSS feed: {feed_error}")
+            raise RequestValidationFailure.single(
+                "rss_url",
+                "invalid_feed",
+                f"Invalid RSS feed: {str(feed_error)}",
+                status.HTTP_400_BAD_REQUEST
             )
+        episode_count = len(episodes)
+        logger.info(f"Found {episode_count} episodes in RSS feed")
 
         # Generate podcast ID
         podcast_id = f"pod_{uuid.uuid4().hex[:12]}"
@@ -93,10 +231,13 @@ async def subscribe_to_podcast(
             "description": podcast_data["description"],
             "image_url": podcast_data["image_url"],
             "author": podcast_data["author"],
+            "categories": podcast_data.get("categories") or [],
+            "explicit": podcast_data.get("explicit"),
             "subscribed_at": datetime.utcnow(),
             "active": True,
             "episode_count": episode_count,
         }
+        stamp(podcast_doc, workspace_id)
 
         # Insert into database
         try:
@@ -107,10 +248,11 @@ async def subscribe_to_podcast(
                 status_code=status.HTTP_409_CONFLICT,
                 detail="Podcast with this RSS URL already exists"
             )
+        await AuditService(db).record("podcast.subscribed", "podcast", podcast_id, workspace_id, {"rss_url": rss_url})
 
         return _format_podcast_response(podcast_doc)
 
-    except HTTPException:
+    except (HTTPException, RequestValidationFailure):
         raise
     except Exception as e:
         logger.error(f"Error subscribing to podcast: {e}")
@@ -123,23 +265,35 @@ async def subscribe_to_podcast(
 @router.get("", response_model=PodcastListResponse)
 async def get_podcasts(
     active_only: bool = True,
-    db: AsyncIOMotorDatabase = Depends(get_database)
+    include_deleted: bool = False,
+    category: Optional[str] = None,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
 ):
     """
     Get all subscribed podcasts.
 
     Args:
         active_only: If True, only return active subscriptions
+        include_deleted: If True, also return soft-deleted podcasts (requires active_only=false)
+        category: Only return podcasts with this iTunes category or subcategory (case-insensitive)
         db: Database instance
+        workspace_id: Caller's workspace
 
     Returns:
         List of podcasts with metadata
     """
     try:
-        logger.info(f"Fetching podcasts (active_only={active_only})")
+        logger.info(f"Fetching podcasts (active_only={active_only}, include_deleted={include_deleted}, category={category})")
 
         # Build query
         query = {"active": True} if active_only else {}
+        if not include_deleted:
+            query["deleted_at"] = None
+        if category:
+            name = re.compile(f"^{re.escape(category.strip())}$", re.IGNORECASE)
+            query["$or"] = [{"categories.name": name}, {"categories.subcategories": name}]
+        query = scoped(query, workspace_id)
 
         # Fetch podcasts sorted by subscription date (newest first)
         cursor = db.podcasts.find(query).sort("subscribed_at", -1)
@@ -163,16 +317,29 @@ async def get_podcasts(
 @router.delete("/{podcast_id}", response_model=SuccessResponse)
 async def unsubscribe_from_podcast(
     podcast_id: str,
-    db: AsyncIOMotorDatabase = Depends(get_database)
+    background_tasks: BackgroundTasks,
+    cleanup: CleanupMode = CleanupMode.NONE,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
 ):
     """
     Unsubscribe from a podcast.
 
-    This marks the podcast as inactive but doesn't delete episodes or transcripts.
+    This soft-deletes the podcast and its episodes: they are hidden from
+    listings but can be restored with POST /api/podcasts/{podcast_id}/restore.
+    Transcripts are moved to the archive prefix once the deletion is older
+    than ARCHIVE_AFTER_DAYS.
+
+    With cleanup=archive or cleanup=delete, a background job also archives or
+    permanently deletes the episodes, their S3 transcripts and the feed's bulk
+    jobs. Its progress is available at GET /api/podcasts/cleanup/{job_id}.
 
     Args:
         podcast_id: ID of the podcast to unsubscribe from
+        background_tasks: FastAPI background tasks
+        cleanup: What to do with episodes and transcripts (none/archive/delete)
         db: Database instance
+        workspace_id: Caller's workspace
 
     Returns:
         Success message
@@ -185,26 +352,38 @@ async def unsubscribe_from_podcast(
 
         # Check if podcast exists
         podcast = await db.podcasts.find_one({"podcast_id": podcast_id})
-        if not podcast:
+        if not in_workspace(podcast, workspace_id):
             raise HTTPException(
                 status_code=status.HTTP_404_NOT_FOUND,
                 detail=f"Podcast with ID '{podcast_id}' not found"
             )
 
-        # Mark as inactive
-        result = await db.podcasts.update_one(
-            {"podcast_id": podcast_id},
-            {"$set": {"active": False}}
-        )
+        if podcast.get("deleted_at") and cleanup == CleanupMode.NONE:
+            logger.warning(f"Podcast {podcast_id} was already deleted")
+            return {
+                "message": f"Podcast '{podcast['title']}' is already deleted",
+                "data": {"podcast_id": podcast_id, "deleted_at": podcast["deleted_at"]}
+            }
+
+        episode_count = 0
+        if not podcast.get("deleted_at"):
+            episode_count = await ArchiveService(db).delete_podcast(podcast_id)
 
-        if result.modified_count == 0:
-            logger.warning(f"Podcast {podcast_id} was already inactive")
+        data = {"podcast_id": podcast_id, "deleted_episodes": episode_count}
+        if cleanup != CleanupMode.NONE:
+            cleanup_service = CleanupService(db)
+            job = await cleanup_service.create_job(podcast_id, cleanup, workspace_id)
+            background_tasks.add_task(cleanup_service.run_job, job["job_id"])
+            data["cleanup_job_id"] = job["job_id"]
 
-        logger.info(f"Successfully unsubscribed from podcast: {podcast_id}")
+        logger.info(f"Successfully unsubscribed from podcast: {podcast_id} (cleanup={cleanup.value})")
+        await AuditService(db).record(
+            "podcast.unsubscribed", "podcast", podcast_id, workspace_id, {"cleanup": cleanup.value}
+        )
 
         return {
             "message": f"Successfully unsubscribed from podcast '{podcast['title']}'",
-            "data": {"podcast_id": podcast_id}
+            "data": data
         }
 
     except HTTPException:
@@ -217,11 +396,429 @@ async def unsubscribe_from_podcast(
         )
 
 
+@router.post("/{podcast_id}/restore", response_model=SuccessResponse)
+async def restore_podcast(
+    podcast_id: str,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Restore a soft-deleted podcast and the episodes deleted with it.
+
+    Archived transcripts are moved back to their original S3 keys.
+
+    Args:
+        podcast_id: ID of the podcast to restore
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        Success message with restore counts
+
+    Raises:
+        HTTPException: If podcast not found or not deleted
+    """
+    try:
+        podcast = await db.podcasts.find_one({"podcast_id": podcast_id})
+        if not in_workspace(podcast, workspace_id):
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=f"Podcast with ID '{podcast_id}' not found"
+            )
+        if not podcast.get("deleted_at"):
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail=f"Podcast '{podcast['title']}' is not deleted"
+            )
+
+        counts = await ArchiveService(db).restore_podcast(podcast_id)
+        await AuditService(db).record("podcast.restored", "podcast", podcast_id, workspace_id, counts)
+
+        return {
+            "message": f"Restored podcast '{podcast['title']}' with {counts['episodes']} episode(s)",
+            "data": {"podcast_id": podcast_id, **counts}
+        }
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error restoring podcast: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to restore podcast"
+        )
+
+
+@router.put("/{podcast_id}/workflow", response_model=PodcastResponse)
+async def set_podcast_workflow(
+    podcast_id: str,
+    workflow: PodcastWorkflow,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Set how the poll Lambda transcribes the podcast's new episodes.
+
+    The state machine ARN replaces STEP_FUNCTION_ARN (and any
+    STEP_FUNCTION_ARNS route) for this podcast; language, provider and
+    priority are added to each execution's input. Sending no fields clears
+    the overrides.
+
+    Args:
+        podcast_id: ID of the podcast
+        workflow: Workflow overrides
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        The updated podcast
+
+    Raises:
+        HTTPException: If podcast not found
+    """
+    try:
+        podcast = await db.podcasts.find_one({"podcast_id": podcast_id, "deleted_at": None})
+        if not in_workspace(podcast, workspace_id):
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=f"Podcast with ID '{podcast_id}' not found"
+            )
+
+        fields = workflow.model_dump(mode="json", exclude_none=True)
+        update = {"$set": {"workflow": fields}} if fields else {"$unset": {"workflow": ""}}
+        podcast = await db.podcasts.find_one_and_update(
+            {"podcast_id": podcast_id}, update, return_document=ReturnDocument.AFTER
+        )
+        await AuditService(db).record("podcast.workflow_updated", "podcast", podcast_id, workspace_id, fields)
+
+        return _format_podcast_response(podcast)
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error setting podcast workflow: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to set podcast workflow"
+        )
+
+
+@router.put("/{podcast_id}/storage", response_model=PodcastResponse)
+async def set_podcast_storage(
+    podcast_id: str,
+    request: StorageLocationUpdate,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Pin the podcast to a storage location from STORAGE_LOCATIONS.
+
+    Its new transcriptions keep audio, chunks and transcripts in the
+    location's buckets; existing transcripts stay where they are. A null
+    location unpins the podcast, which then uses its workspace's location
+    or the default buckets.
+
+    Args:
+        podcast_id: ID of the podcast
+        request: Location name
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        The updated podcast
+
+    Raises:
+        HTTPException: If podcast not found or the location isn't configured
+    """
+    try:
+        podcast = await db.podcasts.find_one({"podcast_id": podcast_id, "deleted_at": None})
+        if not in_workspace(podcast, workspace_id):
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=f"Podcast with ID '{podcast_id}' not found"
+            )
+
+        storage = location_document(request.location) if request.location else None
+        if request.location and not storage:
+            raise HTTPException(
+                status_code=status.HTTP_400_BAD_REQUEST,
+                detail=f"Unknown storage location '{request.location}'"
+            )
+        update = {"$set": {"storage": storage}} if storage else {"$unset": {"storage": ""}}
+        podcast = await db.podcasts.find_one_and_update(
+            {"podcast_id": podcast_id}, update, return_document=ReturnDocument.AFTER
+        )
+        await AuditService(db).record(
+            "podcast.storage_updated", "podcast", podcast_id, workspace_id, {"location": request.location}
+        )
+
+        return _format_podcast_response(podcast)
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error setting podcast storage: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to set podcast storage"
+        )
+
+
+@router.put("/{podcast_id}/privacy", response_model=PodcastResponse)
+async def set_podcast_privacy(
+    podcast_id: str,
+    request: PodcastPrivacyUpdate,
+    background_tasks: BackgroundTasks,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Make the podcast private or public.
+
+    A private podcast's transcripts are encrypted with its workspace's KMS
+    key and served only through the API (see private_transcripts.py); its
+    existing transcripts are encrypted in the background. Making it public
+    again allows share links and the like, but leaves the transcripts
+    encrypted.
+
+    Args:
+        podcast_id: ID of the podcast
+        request: Whether the podcast is private
+        background_tasks: FastAPI background tasks
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        The updated podcast
+
+    Raises:
+        HTTPException: If podcast not found, or workspaces are disabled or
+            the workspace has no KMS key
+    """
+    try:
+        podcast = await db.podcasts.find_one({"podcast_id": podcast_id, "deleted_at": None})
+        if not in_workspace(podcast, workspace_id):
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=f"Podcast with ID '{podcast_id}' not found"
+            )
+
+        if request.private:
+            if not settings.workspaces_enabled:
+                raise HTTPException(
+                    status_code=status.HTTP_400_BAD_REQUEST,
+                    detail="Private podcasts need WORKSPACES_ENABLED"
+                )
+            workspace = await db.workspaces.find_one(
+                {"workspace_id": podcast.get("workspace_id") or DEFAULT_WORKSPACE_ID}, {"kms_key_id": 1}
+            )
+            if not (workspace or {}).get("kms_key_id"):
+                raise HTTPException(
+                    status_code=status.HTTP_400_BAD_REQUEST,
+                    detail="The podcast's workspace has no KMS key"
+                )
+
+        update = {"$set": {"private": True}} if request.private else {"$unset": {"private": ""}}
+        podcast = await db.podcasts.find_one_and_update(
+            {"podcast_id": podcast_id}, update, return_document=ReturnDocument.AFTER
+        )
+        if request.private:
+            background_tasks.add_task(encrypt_existing, db, podcast_id)
+        await AuditService(db).record(
+            "podcast.privacy_updated", "podcast", podcast_id, workspace_id, {"private": request.private}
+        )
+
+        return _format_podcast_response(podcast)
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error setting podcast privacy: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to set podcast privacy"
+        )
+
+
+@router.put("/{podcast_id}/episode-filter", response_model=PodcastResponse)
+async def set_podcast_episode_filter(
+    podcast_id: str,
+    episode_filter: EpisodeFilter,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Set which of the podcast's new episodes the poll Lambda skips.
+
+    Skipped episodes (trailers, bonus episodes, re-runs) are still recorded,
+    with transcript_status "skipped" and a skip_reason, but aren't
+    transcribed. Turning every option off clears the filter; episodes
+    skipped before are left as they are.
+
+    Args:
+        podcast_id: ID of the podcast
+        episode_filter: Episode kinds to skip
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        The updated podcast
+
+    Raises:
+        HTTPException: If podcast not found
+    """
+    try:
+        podcast = await db.podcasts.find_one({"podcast_id": podcast_id, "deleted_at": None})
+        if not in_workspace(podcast, workspace_id):
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=f"Podcast with ID '{podcast_id}' not found"
+            )
+
+        fields = episode_filter.model_dump()
+        update = {"$set": {"episode_filter": fields}} if any(fields.values()) else {"$unset": {"episode_filter": ""}}
+        podcast = await db.podcasts.find_one_and_update(
+            {"podcast_id": podcast_id}, update, return_document=ReturnDocument.AFTER
+        )
+        await AuditService(db).record("podcast.episode_filter_updated", "podcast", podcast_id, workspace_id, fields)
+
+        return _format_podcast_response(podcast)
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error setting podcast episode filter: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to set podcast episode filter"
+        )
+
+
+@router.get("/cleanup/{job_id}", response_model=CleanupJobResponse)
+async def get_cleanup_job(
+    job_id: str,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """Get the progress of a podcast cleanup job."""
+    job = await CleanupService(db).get_job(job_id)
+    if not in_workspace(job, workspace_id):
+        raise HTTPException(
+            status_code=status.HTTP_404_NOT_FOUND,
+            detail=f"Cleanup job '{job_id}' not found"
+        )
+    job.pop("_id", None)
+    return CleanupJobResponse(**job)
+
+
+@router.post("/archive", response_model=SuccessResponse)
+async def archive_deleted_transcripts(db: AsyncIOMotorDatabase = Depends(get_database)):
+    """Run the transcript archival policy for deleted podcasts and episodes immediately."""
+    try:
+        counts = await ArchiveService(db).archive_expired()
+        return {"message": f"Archived {counts['archived']} transcript(s)", "data": counts}
+    except Exception as e:
+        logger.error(f"Error archiving transcripts: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to archive transcripts"
+        )
+
+
+# Feeds fetched at once while subscribing to imported shows
+IMPORT_SUBSCRIBE_CONCURRENCY = 5
+
+
+@router.post("/import", response_model=SubscriptionImportResponse)
+async def import_subscriptions(
+    source: ImportSource = Form(..., description="Service the export came from"),
+    file: UploadFile = File(..., description="Apple Podcasts OPML/JSON or Spotify data export (zip or JSON)"),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Bulk-subscribe to the shows in an Apple Podcasts or Spotify export.
+
+    Shows listed with a feed URL are subscribed to directly; the rest are
+    matched through the podcast directory by title and publisher. Shows
+    that can't be matched unambiguously are reported as unmatched with the
+    directory's closest results, to be subscribed to manually.
+
+    Args:
+        source: apple or spotify
+        file: Export file
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        Per-show outcome and totals
+    """
+    try:
+        content = await file.read(settings.import_max_file_bytes + 1)
+        if len(content) > settings.import_max_file_bytes:
+            raise RequestValidationFailure.single(
+                "file",
+                "too_large",
+                f"Export file exceeds {settings.import_max_file_bytes} bytes",
+                status.HTTP_413_REQUEST_ENTITY_TOO_LARGE
+            )
+        try:
+            shows = parse_export(source, file.filename, content)
+        except ImportFormatError as e:
+            raise RequestValidationFailure.single("file", "invalid_export", str(e), status.HTTP_400_BAD_REQUEST)
+        if len(shows) > settings.import_max_shows:
+            raise RequestValidationFailure.single(
+                "file",
+                "too_many_shows",
+                f"Export lists {len(shows)} shows; at most {settings.import_max_shows} can be imported at once",
+                status.HTTP_400_BAD_REQUEST
+            )
+
+        logger.info(f"Importing {len(shows)} shows from {source.value} export")
+        resolved = await subscription_import_service.resolve(shows)
+
+        semaphore = asyncio.Semaphore(IMPORT_SUBSCRIBE_CONCURRENCY)
+
+        async def subscribe(show: dict) -> dict:
+            if not show.get("rss_url"):
+                return {**show, "status": "unmatched"}
+            async with semaphore:
+                try:
+                    podcast = await subscribe_to_podcast(
+                        SubscribePodcastRequest(rss_url=show["rss_url"], discover=False), db, workspace_id
+                    )
+                    return {**show, "status": "subscribed", "podcast_id": podcast.podcast_id}
+                except HTTPException as e:
+                    if e.status_code == status.HTTP_409_CONFLICT:
+                        return {**show, "status": "already_subscribed"}
+                    return {**show, "status": "failed", "error": str(e.detail)}
+                except Exception as e:
+                    return {**show, "status": "failed", "error": str(e)}
+
+        results = await asyncio.gather(*(subscribe(show) for show in resolved))
+        counts = {
+            outcome: sum(1 for r in results if r["status"] == outcome)
+            for outcome in ("subscribed", "already_subscribed", "unmatched", "failed")
+        }
+        logger.info(f"Imported {source.value} export: {counts}")
+        return SubscriptionImportResponse(source=source, total=len(results), shows=results, **counts)
+
+    except (HTTPException, RequestValidationFailure):
+        raise
+    except Exception as e:
+        logger.error(f"Error importing subscriptions: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to import subscriptions"
+        )
+
+
 @router.post("/{podcast_id}/poll", response_model=SuccessResponse)
 async def poll_podcast(
     podcast_id: str,
     background_tasks: BackgroundTasks,
-    db: AsyncIOMotorDatabase = Depends(get_database)
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
 ):
     """
     Manually trigger polling for new episodes of a specific podcast.
@@ -232,6 +829,7 @@ async def poll_podcast(
     Args:
         podcast_id: ID of the podcast to poll
         db: Database instance
+        workspace_id: Caller's workspace
 
     Returns:
         Success message with polling results
@@ -244,7 +842,7 @@ async def poll_podcast(
 
         # Check if podcast exists and is active
         podcast = await db.podcasts.find_one({"podcast_id": podcast_id})
-        if not podcast:
+        if not in_workspace(podcast, workspace_id):
             raise HTTPException(
                 status_code=status.HTTP_404_NOT_FOUND,
                 detail=f"Podcast with ID '{podcast_id}' not found"
@@ -287,11 +885,19 @@ async def poll_podcast(
                 }).to_list(length=None)
 
                 # Start transcription for each episode in background
+                quota_service = QuotaService(db)
                 for episode in pending_episodes:
                     episode_id = episode.get("episode_id")
                     audio_url = episode.get("audio_url")
 
                     if episode_id and audio_url:
+                        try:
+                            await quota_service.reserve(episode_minutes(episode))
+                        except QuotaExceededError as e:
+                            logger.warning(f"Skipping auto-transcription of remaining episodes: {e.message}")
+                            message += " Auto-transcription stopped: monthly quota exhausted."
+                            break
+
                         # Create a function that starts transcription
                         def create_transcription_task(ep_id: str, aud_url: str):
                             async def run():
@@ -345,7 +951,15 @@ def _format_podcast_response(podcast_doc: dict) -> PodcastResponse:
         description=podcast_doc.get("description"),
         image_url=podcast_doc.get("image_url"),
         author=podcast_doc.get("author"),
+        categories=podcast_doc.get("categories") or [],
+        explicit=podcast_doc.get("explicit"),
         subscribed_at=podcast_doc["subscribed_at"],
         active=podcast_doc.get("active", True),
         episode_count=podcast_doc.get("episode_count"),
+        deleted_at=podcast_doc.get("deleted_at"),
+        workflow=podcast_doc.get("workflow"),
+        episode_filter=podcast_doc.get("episode_filter"),
+        feed_health=podcast_doc.get("feed_health"),
+        storage=podcast_doc.get("storage"),
+        private=podcast_doc.get("private", False),
     )
@@ -0,0 +1,39 @@
+"""Transcription quota endpoints."""
+import logging
+from typing import Optional
+from fastapi import APIRouter, HTTPException, Depends, Header, status
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.database import get_database
+from app.models.schemas import QuotaResponse
+from app.services.quota_service import QuotaService
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter(prefix="/api/quota", tags=["quota"])
+
+
+@router.get("", response_model=QuotaResponse)
+async def get_quota(
+    x_api_key: Optional[str] = Header(None),
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """
+    Get the remaining transcription budget for the current month.
+
+    Args:
+        x_api_key: Caller's API key; when sent, its own budget is included
+        db: Database instance
+
+    Returns:
+        Global and per-key usage, limits and reset time
+    """
+    try:
+        return await QuotaService(db).get_status(x_api_key)
+
+    except Exception as e:
+        logger.error(f"Error fetching quota: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to fetch quota"
+        )
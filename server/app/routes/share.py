@@ -0,0 +1,206 @@
+"""Public transcript share links.
+
+Links are managed under /api/episodes/{episode_id}/share; the shared
+transcript itself is served without authentication at /share/{token}.
+"""
+import logging
+from typing import Optional
+from fastapi import APIRouter, HTTPException, Depends, Query, Request, status
+from fastapi.responses import HTMLResponse
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.database import get_database
+from app.models.schemas import (
+    ShareLinkCreate,
+    ShareLinkListResponse,
+    ShareLinkResponse,
+    SharedTranscriptResponse,
+    SuccessResponse,
+)
+from app.services.audit_service import AuditService
+from app.services.private_transcripts import is_private
+from app.services.share_links import ShareLinkService
+from app.services.transcript_render import render_transcript_html, render_transcript_page
+from app.workspaces import current_workspace, find_episode
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter(prefix="/api/episodes", tags=["share"])
+public_router = APIRouter(prefix="/share", tags=["share"])
+
+
+@router.post(
+    "/{episode_id}/share",
+    response_model=ShareLinkResponse,
+    status_code=status.HTTP_201_CREATED
+)
+async def create_share_link(
+    episode_id: str,
+    request: Optional[ShareLinkCreate] = None,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Create a public, read-only link to an episode's transcript.
+
+    Args:
+        episode_id: ID of the episode
+        request: Optional link lifetime
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        The share link
+
+    Raises:
+        HTTPException: If the episode doesn't exist, has no transcript or is private
+    """
+    try:
+        episode = await find_episode(db, episode_id, workspace_id, deleted_at=None)
+        if not episode:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=f"Episode with ID '{episode_id}' not found"
+            )
+        if episode.get("transcript_status") != "completed":
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail="Only completed transcripts can be shared"
+            )
+        if await is_private(db, episode.get("podcast_id")):
+            raise HTTPException(
+                status_code=status.HTTP_409_CONFLICT,
+                detail="Transcripts of private podcasts can't be shared"
+            )
+
+        link = await ShareLinkService(db).create(episode, request.expires_in_hours if request else None)
+        logger.info(f"Created share link for episode {episode_id}")
+        await AuditService(db).record(
+            "share_link.created", "episode", episode_id, workspace_id, {"expires_at": link["expires_at"]}
+        )
+        return _format_link_response(link)
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error creating share link for episode {episode_id}: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to create share link"
+        )
+
+
+@router.get("/{episode_id}/share", response_model=ShareLinkListResponse)
+async def list_share_links(
+    episode_id: str,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """List an episode's share links, including revoked and expired ones."""
+    await _require_episode(db, episode_id, workspace_id)
+    links = await ShareLinkService(db).list_for_episode(episode_id)
+    return ShareLinkListResponse(links=[_format_link_response(link) for link in links], total=len(links))
+
+
+@router.delete("/{episode_id}/share/{token}", response_model=SuccessResponse)
+async def revoke_share_link(
+    episode_id: str,
+    token: str,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """Revoke a share link; it stops working immediately."""
+    await _require_episode(db, episode_id, workspace_id)
+    if not await ShareLinkService(db).revoke(episode_id, token):
+        raise HTTPException(
+            status_code=status.HTTP_404_NOT_FOUND,
+            detail="Share link not found or already revoked"
+        )
+    logger.info(f"Revoked share link for episode {episode_id}")
+    await AuditService(db).record("share_link.revoked", "episode", episode_id, workspace_id)
+    return {"message": "Share link revoked", "data": {"episode_id": episode_id}}
+
+
+@public_router.get("/{token}", response_model=SharedTranscriptResponse)
+async def get_shared_transcript(
+    token: str,
+    request: Request,
+    format: Optional[str] = Query(None, pattern="^(json|html)$", description="json or html (default: by Accept header)"),
+    db: AsyncIOMotorDatabase = Depends(get_database)
+):
+    """
+    Read a shared transcript without authentication.
+
+    Browsers (Accept: text/html) and format=html get a plain page; other
+    clients get JSON. Unknown, revoked and expired links all return 404.
+
+    Args:
+        token: Share token
+        request: Incoming request (Accept header)
+        format: Response format override
+        db: Database instance
+
+    Returns:
+        Episode details and transcript
+    """
+    try:
+        service = ShareLinkService(db)
+        resolved = await service.resolve(token)
+        transcript = await service.load_transcript(resolved[1]) if resolved else None
+        if not transcript:
+            raise HTTPException(status_code=status.HTTP_404_NOT_FOUND, detail="Shared transcript not found")
+
+        link, episode = resolved
+        podcast = await db.podcasts.find_one({"podcast_id": episode["podcast_id"]}) or {}
+        shared = SharedTranscriptResponse(
+            episode_id=episode["episode_id"],
+            episode_title=episode.get("title", "Untitled Episode"),
+            podcast_title=podcast.get("title", "Unknown Podcast"),
+            published_date=episode.get("published_date"),
+            transcript=transcript,
+            expires_at=link["expires_at"]
+        )
+
+        wants_html = format == "html" or (format is None and "text/html" in request.headers.get("accept", ""))
+        if wants_html:
+            return HTMLResponse(_render_page(shared))
+        return shared
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error serving shared transcript: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to fetch shared transcript"
+        )
+
+
+async def _require_episode(db: AsyncIOMotorDatabase, episode_id: str, workspace_id: Optional[str]):
+    """404 unless the episode exists in the caller's workspace."""
+    if not await find_episode(db, episode_id, workspace_id):
+        raise HTTPException(
+            status_code=status.HTTP_404_NOT_FOUND,
+            detail=f"Episode with ID '{episode_id}' not found"
+        )
+
+
+def _format_link_response(link: dict) -> ShareLinkResponse:
+    """Format a share_links document as response model."""
+    return ShareLinkResponse(
+        token=link["token"],
+        episode_id=link["episode_id"],
+        url=ShareLinkService.share_url(link["token"]),
+        created_at=link["created_at"],
+        expires_at=link.get("expires_at"),
+        revoked_at=link.get("revoked_at")
+    )
+
+
+def _render_page(shared: SharedTranscriptResponse) -> str:
+    """Read-only HTML page for a shared transcript."""
+    subtitle = shared.podcast_title
+    if shared.published_date:
+        subtitle += f" · {shared.published_date.strftime('%B %d, %Y')}"
+    page = render_transcript_page(shared.episode_title, subtitle, render_transcript_html(shared.transcript))
+    return page.replace("<head>", '<head><meta name="robots" content="noindex">', 1)
@@ -0,0 +1,217 @@
+"""One-off audio transcription routes (audio not part of any feed)."""
+import logging
+from contextlib import AsyncExitStack
+from pathlib import Path
+from typing import Optional
+from urllib.parse import urlsplit
+
+from fastapi import APIRouter, HTTPException, Depends, File, Form, Header, UploadFile, status
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.config import settings
+from app.database import get_database
+from app.models.schemas import (
+    JobPriority,
+    TranscribeTaskResponse,
+    TranscribeTaskSource,
+    TranscribeUrlRequest,
+    TranscriptStatus,
+)
+from app.services.audit_service import AuditService
+from app.services.quota_service import QuotaService
+from app.services.s3_service import s3_service
+from app.services.temp_storage import TempBudgetExceeded, temp_storage
+from app.services.upload_transcription import UploadTooLarge, UploadTranscriptionService
+from app.validation import RequestValidationFailure
+from app.workspaces import current_workspace, in_workspace
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter(prefix="/api/transcribe", tags=["transcription"])
+
+
+@router.post("", response_model=TranscribeTaskResponse, status_code=status.HTTP_202_ACCEPTED)
+async def transcribe_audio(
+    file: Optional[UploadFile] = File(None, description="Audio file to transcribe"),
+    s3_key: Optional[str] = Form(None, description="Key of an object in the audio bucket"),
+    audio_url: Optional[str] = Form(None, description="URL (e.g. presigned) to download the audio from"),
+    priority: JobPriority = Form(JobPriority.HIGH, description="Queue priority"),
+    x_api_key: Optional[str] = Header(None),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Transcribe a one-off audio file.
+
+    Send exactly one of a multipart `file`, an audio-bucket `s3_key` or an
+    `audio_url`. Returns 202 with a task ID immediately; poll
+    GET /api/transcribe/{task_id} for the transcript. Reserves
+    QUOTA_DEFAULT_EPISODE_MINUTES from the monthly quota.
+
+    Args:
+        file: Uploaded audio
+        s3_key: Audio bucket key
+        audio_url: Audio URL
+        priority: Queue priority (default high)
+        x_api_key: Caller's API key, for quota
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        The pending task
+    """
+    sources = [name for name, value in (("file", file), ("s3_key", s3_key), ("audio_url", audio_url)) if value]
+    if len(sources) != 1:
+        raise RequestValidationFailure.single(
+            "file",
+            "invalid_source",
+            "Provide exactly one of file, s3_key or audio_url",
+            status.HTTP_400_BAD_REQUEST
+        )
+    if audio_url and urlsplit(audio_url).scheme not in ("http", "https"):
+        raise RequestValidationFailure.single(
+            "audio_url", "invalid_url", "audio_url must be an http(s) URL", status.HTTP_400_BAD_REQUEST
+        )
+    if file and file.content_type and not any(
+        file.content_type.startswith(prefix) for prefix in settings.whisper_allowed_content_types_list
+    ):
+        raise RequestValidationFailure.single(
+            "file",
+            "unsupported_type",
+            f"Unsupported content type {file.content_type}",
+            status.HTTP_415_UNSUPPORTED_MEDIA_TYPE
+        )
+
+    await QuotaService(db).reserve(settings.quota_default_episode_minutes, x_api_key)
+
+    service = UploadTranscriptionService(db)
+    cleanup = AsyncExitStack()
+    try:
+        if file:
+            temp = await cleanup.enter_async_context(temp_storage.create(suffix=Path(file.filename or "").suffix))
+            await service.save_upload(file, temp)
+            task = await service.create_task(
+                TranscribeTaskSource.UPLOAD.value, file.filename or "upload", priority, workspace_id
+            )
+            service.start(task["task_id"], audio_path=temp.path, cleanup=cleanup)
+        elif s3_key:
+            task = await service.create_task(TranscribeTaskSource.S3.value, s3_key, priority, workspace_id)
+            service.start(task["task_id"], audio_url=service.audio_bucket_url(s3_key))
+        else:
+            # The query string may hold a presigned signature; don't store it
+            task = await service.create_task(
+                TranscribeTaskSource.URL.value, audio_url.split("?", 1)[0], priority, workspace_id
+            )
+            service.start(task["task_id"], audio_url=audio_url)
+
+        logger.info(f"Started transcription task {task['task_id']} from {sources[0]}")
+        await AuditService(db).record(
+            "transcribe_task.created", "transcribe_task", task["task_id"], workspace_id,
+            {"source": task["source"], "description": task["description"]}
+        )
+        return TranscribeTaskResponse(**task)
+
+    except UploadTooLarge as e:
+        await cleanup.aclose()
+        raise RequestValidationFailure.single(
+            "file", "too_large", str(e), status.HTTP_413_REQUEST_ENTITY_TOO_LARGE
+        )
+    except TempBudgetExceeded as e:
+        await cleanup.aclose()
+        raise HTTPException(status_code=status.HTTP_503_SERVICE_UNAVAILABLE, detail=str(e))
+    except Exception as e:
+        await cleanup.aclose()
+        logger.error(f"Error starting transcription task: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to start transcription"
+        )
+
+
+@router.post("/url", response_model=TranscribeTaskResponse, status_code=status.HTTP_202_ACCEPTED)
+async def transcribe_url(
+    request: TranscribeUrlRequest,
+    x_api_key: Optional[str] = Header(None),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Transcribe audio from any URL (JSON alternative to the multipart form).
+
+    The audio is downloaded and transcribed in the background through the
+    transcription queue; poll GET /api/transcribe/{task_id} for the result.
+    Reserves QUOTA_DEFAULT_EPISODE_MINUTES from the monthly quota.
+
+    Args:
+        request: Audio URL and priority
+        x_api_key: Caller's API key, for quota
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        The pending task
+    """
+    audio_url = str(request.audio_url)
+    await QuotaService(db).reserve(settings.quota_default_episode_minutes, x_api_key)
+
+    try:
+        service = UploadTranscriptionService(db)
+        # The query string may hold a presigned signature; don't store it
+        task = await service.create_task(
+            TranscribeTaskSource.URL.value, audio_url.split("?", 1)[0], request.priority, workspace_id
+        )
+        service.start(task["task_id"], audio_url=audio_url)
+
+        logger.info(f"Started transcription task {task['task_id']} from URL")
+        await AuditService(db).record(
+            "transcribe_task.created", "transcribe_task", task["task_id"], workspace_id,
+            {"source": task["source"], "description": task["description"]}
+        )
+        return TranscribeTaskResponse(**task)
+
+    except Exception as e:
+        logger.error(f"Error starting transcription task: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to start transcription"
+        )
+
+
+@router.get("/{task_id}", response_model=TranscribeTaskResponse)
+async def get_transcribe_task(
+    task_id: str,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Get a one-off transcription task, including the transcript once completed.
+
+    Args:
+        task_id: Task ID from POST /api/transcribe
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        Task status and transcript
+
+    Raises:
+        HTTPException: If the task doesn't exist
+    """
+    try:
+        task = await UploadTranscriptionService(db).get_task(task_id)
+        if not in_workspace(task, workspace_id):
+            raise HTTPException(status_code=status.HTTP_404_NOT_FOUND, detail=f"Task {task_id} not found")
+
+        transcript = None
+        if task["status"] == TranscriptStatus.COMPLETED.value and task.get("transcript_s3_key"):
+            transcript = await s3_service.get_transcript(task["transcript_s3_key"])
+        return TranscribeTaskResponse(**task, transcript=transcript)
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error fetching transcription task {task_id}: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to fetch transcription task"
+        )
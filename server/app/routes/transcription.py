@@ -4,11 +4,16 @@ Provides endpoints to trigger and monitor transcription workflows.
 """
 import logging
 from typing import Optional
-from fastapi import APIRouter, HTTPException, BackgroundTasks
+from fastapi import APIRouter, HTTPException, BackgroundTasks, Depends, Header, Request, Response
 from pydantic import BaseModel
 
 from app.database.mongodb import get_database
+from app.models.schemas import JobPriority
+from app.http_cache import compute_etag, conditional_response
+from app.services.audit_service import AuditService
 from app.services.orchestration_service import get_orchestration_service
+from app.services.quota_service import QuotaService, episode_minutes
+from app.workspaces import current_workspace, find_episode
 
 logger = logging.getLogger(__name__)
 
@@ -19,6 +24,7 @@ class TranscribeRequest(BaseModel):
     """Request to start transcription for an episode."""
     episode_id: str
     audio_url: Optional[str] = None  # Optional - will look up from DB if not provided
+    priority: JobPriority = JobPriority.HIGH  # User requests jump ahead of backfills
 
 
 class TranscribeResponse(BaseModel):
@@ -39,19 +45,21 @@ class TranscriptionStatusResponse(BaseModel):
 @router.post("/start", response_model=TranscribeResponse)
 async def start_transcription(
     request: TranscribeRequest,
-    background_tasks: BackgroundTasks
+    background_tasks: BackgroundTasks,
+    x_api_key: Optional[str] = Header(None),
+    workspace_id: Optional[str] = Depends(current_workspace)
 ):
     """
     Start transcription workflow for an episode.
 
     This endpoint triggers the transcription workflow in the background
     and returns immediately. Use the status endpoint to check progress.
+    Returns 402/429 if the episode would exceed the monthly quota.
     """
-    db = get_database()
-    episodes_collection = db.episodes
+    db = await get_database()
 
     # Look up the episode
-    episode = episodes_collection.find_one({"episode_id": request.episode_id})
+    episode = await find_episode(db, request.episode_id, workspace_id)
     if not episode:
         raise HTTPException(status_code=404, detail=f"Episode {request.episode_id} not found")
 
@@ -75,6 +83,8 @@ async def start_transcription(
             message="Transcription is already completed"
         )
 
+    await QuotaService(db).reserve(episode_minutes(episode), x_api_key)
+
     # Start transcription in background
     orchestration_service = get_orchestration_service()
 
@@ -82,7 +92,8 @@ async def start_transcription(
         try:
             await orchestration_service.transcribe_episode(
                 episode_id=request.episode_id,
-                audio_url=audio_url
+                audio_url=audio_url,
+                priority=request.priority
             )
         except Exception as e:
             logger.error(f"Background transcription failed for {request.episode_id}: {e}")
@@ -90,6 +101,9 @@ async def start_transcription(
     background_tasks.add_task(run_transcription)
 
     logger.info(f"Started transcription workflow for episode {request.episode_id}")
+    await AuditService(db).record(
+        "transcription.started", "episode", request.episode_id, workspace_id, {"priority": request.priority.value}
+    )
 
     return TranscribeResponse(
         status="started",
@@ -99,18 +113,36 @@ async def start_transcription(
 
 
 @router.get("/status/{episode_id}", response_model=TranscriptionStatusResponse)
-async def get_transcription_status(episode_id: str):
-    """Get the current transcription status for an episode."""
-    db = get_database()
-    episodes_collection = db.episodes
+async def get_transcription_status(
+    episode_id: str,
+    request: Request,
+    response: Response,
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Get the current transcription status for an episode.
+
+    Returns 304 when the client's ETag / Last-Modified is still current.
+    """
+    db = await get_database()
 
-    episode = episodes_collection.find_one({"episode_id": episode_id})
+    episode = await find_episode(db, episode_id, workspace_id)
     if not episode:
         raise HTTPException(status_code=404, detail=f"Episode {episode_id} not found")
 
+    transcript_status = episode.get("transcript_status", "pending")
+    last_modified = episode.get("updated_at") or episode.get("processed_at")
+    etag = compute_etag(
+        episode_id, last_modified, transcript_status,
+        episode.get("processing_step"), episode.get("error_message")
+    )
+    not_modified = conditional_response(request, response, etag, last_modified)
+    if not_modified:
+        return not_modified
+
     return TranscriptionStatusResponse(
         episode_id=episode_id,
-        transcript_status=episode.get("transcript_status", "pending"),
+        transcript_status=transcript_status,
         transcript_s3_key=episode.get("transcript_s3_key"),
         error_message=episode.get("error_message")
     )
@@ -119,17 +151,20 @@ async def get_transcription_status(episode_id: str):
 @router.post("/retry/{episode_id}", response_model=TranscribeResponse)
 async def retry_transcription(
     episode_id: str,
-    background_tasks: BackgroundTasks
+    background_tasks: BackgroundTasks,
+    x_api_key: Optional[str] = Header(None),
+    workspace_id: Optional[str] = Depends(current_workspace)
 ):
     """
     Retry a failed transcription.
 
-    Resets the status and starts the workflow again.
+    Resets the status and starts the workflow again. Retries count against
+    the monthly quota like any other transcription.
     """
-    db = get_database()
+    db = await get_database()
     episodes_collection = db.episodes
 
-    episode = episodes_collection.find_one({"episode_id": episode_id})
+    episode = await find_episode(db, episode_id, workspace_id)
     if not episode:
         raise HTTPException(status_code=404, detail=f"Episode {episode_id} not found")
 
@@ -141,8 +176,10 @@ async def retry_transcription(
     if not audio_url:
         raise HTTPException(status_code=400, detail="No audio URL found for episode")
 
+    await QuotaService(db).reserve(episode_minutes(episode), x_api_key)
+
     # Reset status
-    episodes_collection.update_one(
+    await episodes_collection.update_one(
         {"episode_id": episode_id},
         {"$set": {"transcript_status": "pending", "error_message": None}}
     )
@@ -154,7 +191,8 @@ async def retry_transcription(
         try:
             await orchestration_service.transcribe_episode(
                 episode_id=episode_id,
-                audio_url=audio_url
+                audio_url=audio_url,
+                priority=JobPriority.HIGH
             )
         except Exception as e:
             logger.error(f"Background transcription failed for {episode_id}: {e}")
@@ -162,6 +200,7 @@ async def retry_transcription(
     background_tasks.add_task(run_transcription)
 
     logger.info(f"Retrying transcription for episode {episode_id}")
+    await AuditService(db).record("transcription.retried", "episode", episode_id, workspace_id)
 
     return TranscribeResponse(
         status="started",
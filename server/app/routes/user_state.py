@@ -0,0 +1,183 @@
+"""Per-user episode playback position and transcript read state."""
+import logging
+from datetime import datetime
+from typing import Optional
+from fastapi import APIRouter, HTTPException, Depends, Query, status
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.database import get_database
+from app.models.schemas import (
+    EpisodeStateListResponse,
+    EpisodeStateResponse,
+    EpisodeStateUpdate,
+    SuccessResponse,
+)
+from app.workspaces import current_workspace, find_episode, scoped, stamp
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter(prefix="/api/users", tags=["user state"])
+
+
+@router.get("/{user_id}/episodes", response_model=EpisodeStateListResponse)
+async def list_episode_states(
+    user_id: str,
+    podcast_id: Optional[str] = Query(None, description="Only episodes of this podcast"),
+    in_progress: bool = Query(False, description="Only episodes started but not played to the end"),
+    limit: int = Query(50, ge=1, le=500, description="Maximum states to return"),
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    List a user's episode states, most recently updated first.
+
+    With in_progress=true this is the "continue listening" list.
+
+    Args:
+        user_id: User identifier
+        podcast_id: Optional podcast filter
+        in_progress: Only partially played episodes
+        limit: Maximum states to return
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        Episode states and the total matching
+    """
+    try:
+        query = scoped({"user_id": user_id}, workspace_id)
+        if podcast_id:
+            query["podcast_id"] = podcast_id
+        if in_progress:
+            query.update({"position_seconds": {"$gt": 0}, "played": False})
+
+        total = await db.user_episode_state.count_documents(query)
+        cursor = db.user_episode_state.find(query).sort("updated_at", -1).limit(limit)
+        states = [_format_state_response(state) async for state in cursor]
+        return EpisodeStateListResponse(states=states, total=total)
+
+    except Exception as e:
+        logger.error(f"Error listing episode states for {user_id}: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to list episode states"
+        )
+
+
+@router.get("/{user_id}/episodes/{episode_id}", response_model=EpisodeStateResponse)
+async def get_episode_state(
+    user_id: str,
+    episode_id: str,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """Get a user's playback position and read state for an episode."""
+    state = await db.user_episode_state.find_one(
+        scoped({"user_id": user_id, "episode_id": episode_id}, workspace_id)
+    )
+    if not state:
+        raise HTTPException(
+            status_code=status.HTTP_404_NOT_FOUND,
+            detail=f"No state for episode '{episode_id}'"
+        )
+    return _format_state_response(state)
+
+
+@router.put("/{user_id}/episodes/{episode_id}", response_model=EpisodeStateResponse)
+async def update_episode_state(
+    user_id: str,
+    episode_id: str,
+    request: EpisodeStateUpdate,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """
+    Record a user's playback position and/or read state for an episode.
+
+    Clients call this periodically while playing to support resuming where
+    the user left off.
+
+    Args:
+        user_id: User identifier
+        episode_id: Episode identifier
+        request: Fields to change
+        db: Database instance
+        workspace_id: Caller's workspace
+
+    Returns:
+        Stored state
+
+    Raises:
+        HTTPException: If the episode doesn't exist
+    """
+    try:
+        episode = await find_episode(db, episode_id, workspace_id, deleted_at=None)
+        if not episode:
+            raise HTTPException(
+                status_code=status.HTTP_404_NOT_FOUND,
+                detail=f"Episode with ID '{episode_id}' not found"
+            )
+
+        now = datetime.utcnow()
+        fields = {**request.model_dump(exclude_none=True), "updated_at": now}
+        if request.transcript_read is not None:
+            fields["transcript_read_at"] = now if request.transcript_read else None
+        defaults = {"position_seconds": 0, "played": False, "transcript_read": False, "transcript_read_at": None}
+
+        await db.user_episode_state.update_one(
+            {"user_id": user_id, "episode_id": episode_id},
+            {
+                "$set": fields,
+                "$setOnInsert": stamp({
+                    "user_id": user_id,
+                    "episode_id": episode_id,
+                    "podcast_id": episode["podcast_id"],
+                    **{key: value for key, value in defaults.items() if key not in fields},
+                }, workspace_id),
+            },
+            upsert=True
+        )
+        state = await db.user_episode_state.find_one({"user_id": user_id, "episode_id": episode_id})
+        return _format_state_response(state)
+
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error updating state of episode {episode_id} for {user_id}: {e}")
+        raise HTTPException(
+            status_code=status.HTTP_500_INTERNAL_SERVER_ERROR,
+            detail="Failed to update episode state"
+        )
+
+
+@router.delete("/{user_id}/episodes/{episode_id}", response_model=SuccessResponse)
+async def delete_episode_state(
+    user_id: str,
+    episode_id: str,
+    db: AsyncIOMotorDatabase = Depends(get_database),
+    workspace_id: Optional[str] = Depends(current_workspace)
+):
+    """Forget a user's playback position and read state for an episode."""
+    result = await db.user_episode_state.delete_one(
+        scoped({"user_id": user_id, "episode_id": episode_id}, workspace_id)
+    )
+    if result.deleted_count == 0:
+        raise HTTPException(
+            status_code=status.HTTP_404_NOT_FOUND,
+            detail=f"No state for episode '{episode_id}'"
+        )
+    return {"message": f"Cleared state for episode '{episode_id}'", "data": {"episode_id": episode_id}}
+
+
+def _format_state_response(state: dict) -> EpisodeStateResponse:
+    """Format a user_episode_state document as response model."""
+    return EpisodeStateResponse(
+        user_id=state["user_id"],
+        episode_id=state["episode_id"],
+        podcast_id=state["podcast_id"],
+        position_seconds=state.get("position_seconds", 0),
+        played=state.get("played", False),
+        transcript_read=state.get("transcript_read", False),
+        transcript_read_at=state.get("transcript_read_at"),
+        updated_at=state["updated_at"]
+    )
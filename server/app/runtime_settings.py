@@ -0,0 +1,102 @@
+"""Settings that can be changed without restarting the server.
+
+A few settings are useful to turn while watching a live backfill: the log
+level, how often scheduled bulk jobs are checked, how many transcriptions
+run at once and which Whisper containers serve them. They can be set
+directly through PATCH /admin/runtime-settings, or re-read from the
+environment, .env and CONFIG_FILE with POST /admin/runtime-settings/reload
+or a SIGHUP. Everything else still needs a restart.
+"""
+import asyncio
+import logging
+from typing import Any, Dict, List
+
+from app.config import Settings, settings
+
+logger = logging.getLogger(__name__)
+
+RUNTIME_SETTINGS = (
+    "log_level",
+    "bulk_schedule_poll_seconds",
+    "transcription_workers",
+    "whisper_service_url",
+    "whisper_service_urls",
+    "whisper_max_concurrent_per_backend",
+    "whisper_download_host_daily_bytes",
+    "whisper_download_host_daily_limits",
+)
+
+WHISPER_POOL_SETTINGS = {"whisper_service_url", "whisper_service_urls", "whisper_max_concurrent_per_backend"}
+
+
+def current_runtime_settings() -> Dict[str, Any]:
+    """Current values of the runtime settings."""
+    return {name: getattr(settings, name) for name in RUNTIME_SETTINGS}
+
+
+async def apply_runtime_settings(values: Dict[str, Any]) -> List[str]:
+    """
+    Validate and apply new runtime setting values.
+
+    Args:
+        values: Setting names to new values; names outside RUNTIME_SETTINGS are rejected
+
+    Returns:
+        Names of the settings that changed
+
+    Raises:
+        ValueError: If a name isn't a runtime setting or the result is invalid
+    """
+    unknown = sorted(set(values) - set(RUNTIME_SETTINGS))
+    if unknown:
+        raise ValueError(f"Not runtime settings: {', '.join(unknown)}")
+
+    # Validate against the full settings so combination rules still apply
+    candidate = Settings.model_validate({**settings.model_dump(), **values})
+    changed = [name for name in RUNTIME_SETTINGS if getattr(candidate, name) != getattr(settings, name)]
+    if not changed:
+        return []
+    for name in changed:
+        setattr(settings, name, getattr(candidate, name))
+
+    from app.services.whisper_service import whisper_service
+    from app.services.work_queue import transcription_slots
+
+    if "log_level" in changed:
+        logging.getLogger().setLevel(settings.log_level.upper())
+    if "transcription_workers" in changed:
+        transcription_slots.resize(settings.transcription_workers)
+    if WHISPER_POOL_SETTINGS & set(changed):
+        await whisper_service.reconfigure(
+            settings.whisper_service_urls_list,
+            settings.whisper_max_concurrent_per_backend
+        )
+
+    logger.info(
+        "Runtime settings changed: "
+        + ", ".join(f"{name.upper()}={getattr(settings, name)}" for name in changed)
+    )
+    return changed
+
+
+async def reload_runtime_settings() -> List[str]:
+    """
+    Re-read the runtime settings from their sources (environment, .env,
+    CONFIG_FILE) and apply any that changed.
+
+    Returns:
+        Names of the settings that changed
+    """
+    fresh = await asyncio.to_thread(Settings)
+    return await apply_runtime_settings({name: getattr(fresh, name) for name in RUNTIME_SETTINGS})
+
+
+async def reload_on_signal():
+    """SIGHUP handler: reload runtime settings, logging rather than raising failures."""
+    logger.info("SIGHUP received, reloading runtime settings")
+    try:
+        changed = await reload_runtime_settings()
+        if not changed:
+            logger.info("Runtime settings unchanged")
+    except Exception as e:
+        logger.error(f"Failed to reload runtime settings: {e}")
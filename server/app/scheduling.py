@@ -0,0 +1,42 @@
+"""Schedule expressions for recurring work.
+
+A schedule is either an interval ("30m", "6h", "every 1d") or a five-field
+cron expression ("0 */6 * * *", evaluated in UTC).
+"""
+import re
+from datetime import datetime, timedelta
+from typing import Optional
+
+from croniter import croniter
+
+_INTERVAL_RE = re.compile(r"^(?:every\s+)?(\d+)\s*([mhd])$", re.IGNORECASE)
+_INTERVAL_UNITS = {"m": "minutes", "h": "hours", "d": "days"}
+MIN_INTERVAL = timedelta(minutes=15)
+
+
+def validate_schedule(schedule: str) -> str:
+    """
+    Validate a schedule string.
+
+    Raises:
+        ValueError: If the schedule is neither a valid interval nor cron expression
+    """
+    schedule = schedule.strip()
+    match = _INTERVAL_RE.match(schedule)
+    if match:
+        interval = timedelta(**{_INTERVAL_UNITS[match.group(2).lower()]: int(match.group(1))})
+        if interval < MIN_INTERVAL:
+            raise ValueError(f"Schedule interval must be at least {int(MIN_INTERVAL.total_seconds() // 60)} minutes")
+        return schedule
+    if croniter.is_valid(schedule):
+        return schedule
+    raise ValueError("Schedule must be an interval like '6h' or a cron expression like '0 */6 * * *'")
+
+
+def next_run_at(schedule: str, after: Optional[datetime] = None) -> datetime:
+    """Next time a schedule fires after the given time (UTC)."""
+    after = after or datetime.utcnow()
+    match = _INTERVAL_RE.match(schedule.strip())
+    if match:
+        return after + timedelta(**{_INTERVAL_UNITS[match.group(2).lower()]: int(match.group(1))})
+    return croniter(schedule.strip(), after).get_next(datetime)
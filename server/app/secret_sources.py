@@ -0,0 +1,175 @@
+"""Secrets from AWS Secrets Manager and SSM Parameter Store.
+
+Secret settings need not be plaintext environment variables in Lambda and
+ECS task definitions. For a setting such as MONGODB_URL, set either
+MONGODB_URL_SECRET_ARN (a Secrets Manager secret ID or ARN; append "#key"
+to read one key of a JSON secret) or MONGODB_URL_SSM_PARAMETER (an SSM
+SecureString name). Values are resolved when settings load and refreshed
+periodically so rotated secrets take effect without a restart. The Go
+Lambdas follow the same convention (secrets.go).
+"""
+import asyncio
+import json
+import logging
+import os
+import threading
+import time
+from typing import Any, Dict, Optional, Tuple
+
+from dotenv import dotenv_values
+
+logger = logging.getLogger(__name__)
+
+# Settings that may be loaded from a secret store
+SECRET_SETTINGS = (
+    "mongodb_url",
+    "redis_url",
+    "openai_api_key",
+    "assemblyai_api_key",
+    "deepgram_api_key",
+    "listennotes_api_key",
+    "taddy_api_key",
+    "aws_access_key_id",
+    "aws_secret_access_key",
+    "smtp_password",
+    "callback_secret",
+    "field_encryption_local_key",
+)
+
+DEFAULT_CACHE_TTL_SECONDS = 300
+
+
+def _env(name: str) -> Optional[str]:
+    """Read a variable from the environment, falling back to .env."""
+    return os.environ.get(name) or dotenv_values(".env").get(name)
+
+
+def secret_source(setting: str) -> Optional[Tuple[str, str]]:
+    """The ("secretsmanager" | "ssm", id) a setting is loaded from, if any."""
+    secret_id = _env(f"{setting.upper()}_SECRET_ARN")
+    if secret_id:
+        return "secretsmanager", secret_id
+    parameter = _env(f"{setting.upper()}_SSM_PARAMETER")
+    if parameter:
+        return "ssm", parameter
+    return None
+
+
+class SecretsProvider:
+    """Fetches secrets, caching each secret so JSON secrets are read once for all their keys."""
+
+    def __init__(self):
+        self._clients: Dict[str, Any] = {}
+        self._cache: Dict[Tuple[str, str], Tuple[str, float]] = {}
+        self._lock = threading.Lock()
+        ttl = _env("SECRETS_CACHE_TTL_SECONDS")
+        self.ttl = int(ttl) if ttl else DEFAULT_CACHE_TTL_SECONDS
+
+    def _client(self, service: str):
+        """boto3 client built from the environment (secrets can't depend on settings)."""
+        if service not in self._clients:
+            import boto3
+            kwargs = {"region_name": _env("AWS_REGION") or "us-east-1"}
+            endpoint = _env("AWS_ENDPOINT_URL")
+            if endpoint:
+                kwargs["endpoint_url"] = endpoint
+            self._clients[service] = boto3.client(service, **kwargs)
+        return self._clients[service]
+
+    def _fetch(self, kind: str, identifier: str) -> str:
+        if kind == "ssm":
+            response = self._client("ssm").get_parameter(Name=identifier, WithDecryption=True)
+            return response["Parameter"]["Value"]
+        response = self._client("secretsmanager").get_secret_value(SecretId=identifier)
+        return response["SecretString"]
+
+    def get(self, setting: str) -> Optional[str]:
+        """
+        Resolve a setting from its secret store.
+
+        Args:
+            setting: Setting name (e.g. mongodb_url)
+
+        Returns:
+            The value, or None if the setting isn't configured as a secret
+
+        Raises:
+            ValueError: If the secret can't be read and nothing is cached
+        """
+        source = secret_source(setting)
+        if not source:
+            return None
+        kind, identifier = source
+        secret_id, _, key = identifier.partition("#")
+        cache_key = (kind, secret_id)
+
+        with self._lock:
+            cached = self._cache.get(cache_key)
+            if not cached or time.monotonic() - cached[1] >= self.ttl:
+                try:
+                    cached = (self._fetch(kind, secret_id), time.monotonic())
+                    self._cache[cache_key] = cached
+                except Exception as e:
+                    if not cached:
+                        raise ValueError(f"Failed to load {setting.upper()} from {kind}: {e}")
+                    # Keep serving the last good value through an outage
+                    logger.warning(f"Failed to refresh {setting.upper()}, using cached value: {e}")
+        value = cached[0]
+
+        if not key:
+            return value
+        try:
+            return str(json.loads(value)[key])
+        except (ValueError, KeyError, TypeError):
+            raise ValueError(f"Secret {secret_id} has no JSON key '{key}'")
+
+    def load(self) -> Dict[str, str]:
+        """All secret settings that are configured, by setting name."""
+        values = {}
+        for setting in SECRET_SETTINGS:
+            value = self.get(setting)
+            if value is not None:
+                values[setting] = value
+        return values
+
+
+secrets_provider = SecretsProvider()
+
+
+async def run_secrets_refresher(interval_seconds: int):
+    """
+    Re-read secrets periodically and apply rotated values. Secrets are
+    fetched again once their cache TTL has passed.
+
+    A rotated MONGODB_URL reconnects the database; rotated AWS credentials
+    rebuild the AWS clients.
+    """
+    from app.config import settings
+    from app.database import MongoDB
+    from app.field_encryption import field_encryptor
+    from app.services.s3_service import s3_service
+
+    logger.info(f"Secrets refresher started (every {interval_seconds}s)")
+    while True:
+        await asyncio.sleep(interval_seconds)
+        try:
+            values = await asyncio.to_thread(secrets_provider.load)
+        except Exception as e:
+            logger.error(f"Secrets refresh failed: {e}")
+            continue
+
+        changed = [name for name, value in values.items() if getattr(settings, name) != value]
+        if not changed:
+            continue
+        for name in changed:
+            setattr(settings, name, values[name])
+        logger.info(f"Rotated secrets: {', '.join(name.upper() for name in changed)}")
+
+        if "mongodb_url" in changed:
+            try:
+                await MongoDB.reconnect()
+            except Exception as e:
+                logger.error(f"Failed to reconnect to MongoDB with rotated URL: {e}")
+        if {"aws_access_key_id", "aws_secret_access_key"} & set(changed):
+            s3_service.reset_client()
+            field_encryptor.reset_client()
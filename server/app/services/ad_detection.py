@@ -0,0 +1,249 @@
+"""Heuristic detection of ad and sponsor reads in transcripts.
+
+Sponsor reads follow a script: an opener ("this episode is brought to you
+by"), offer language ("use code", "free trial", "dot com slash") and often a
+closer ("now back to the show"). Sentences are scanned for these cues and
+runs of cue-bearing sentences become labeled segments. Times are taken from
+the [HH:MM:SS] markers the merge step writes, interpolated by character
+position, or estimated from the episode duration when there are no markers.
+"""
+import bisect
+import logging
+import re
+from datetime import datetime
+from typing import Any, Dict, List, Optional, Tuple
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.cache import cache
+from app.config import settings
+from app.services.s3_service import s3_service
+from app.services.storage_locations import transcript_location
+
+logger = logging.getLogger(__name__)
+
+DETECTOR_VERSION = 1
+
+# Speaking rate used to estimate time when the duration is unknown
+WORDS_PER_MINUTE = 150
+
+# Sentences without a cue tolerated inside one ad read
+MAX_GAP_SENTENCES = 2
+
+SPONSOR = "sponsor"
+AD = "ad"
+PROMO = "promo"
+
+# Cues that open a read, by label
+OPENERS = {
+    SPONSOR: re.compile(
+        r"\b(brought to you by|sponsored by|(thanks|thank you) to our sponsors?|"
+        r"support for (this|the) (podcast|show|episode) comes from|"
+        r"(this|today's) (podcast|show|episode) is (supported|presented) by|"
+        r"a word from (our|today's) sponsors?)\b",
+        re.IGNORECASE,
+    ),
+    AD: re.compile(r"\b(we'll be right back|after (this|these) (short |quick )?(break|messages?))\b", re.IGNORECASE),
+    PROMO: re.compile(
+        r"\b(rate and review|leave (us )?a review|(join|support) us on patreon|"
+        r"become a (member|supporter)|check out our merch)\b",
+        re.IGNORECASE,
+    ),
+}
+
+# Offer language inside a read
+OFFER = re.compile(
+    r"\b(promo code|offer code|discount code|use (the )?code|free trial|"
+    r"\d+ ?(%|percent) off|dot com slash|\.com/\w+|first (month|order|box)|"
+    r"sign up (today|now)|for a limited time|money[- ]back guarantee|terms apply)\b",
+    re.IGNORECASE,
+)
+
+# Cues that close a read
+CLOSER = re.compile(r"\b(back to the (show|episode|interview|conversation)|and we're back)\b", re.IGNORECASE)
+
+# A terminator followed by more text (as in "example.com/code") doesn't end a sentence
+SENTENCE = re.compile(r"[^.!?\n]+(?:[.!?]+(?!\s|$)[^.!?\n]*)*[.!?]*")
+TIMESTAMP = re.compile(r"\[(\d{2}):(\d{2}):(\d{2})\]")
+
+
+def split_sentences(transcript: str) -> List[Tuple[int, int, str]]:
+    """Sentences with their character offsets."""
+    return [
+        (m.start(), m.end(), m.group())
+        for m in SENTENCE.finditer(transcript)
+        if m.group().strip()
+    ]
+
+
+def time_anchors(transcript: str, duration_seconds: Optional[float]) -> Tuple[List[int], List[float]]:
+    """Character offsets paired with seconds, for interpolating times."""
+    positions, seconds = [0], [0.0]
+    for m in TIMESTAMP.finditer(transcript):
+        secs = int(m.group(1)) * 3600 + int(m.group(2)) * 60 + int(m.group(3))
+        if secs > seconds[-1]:
+            positions.append(m.start())
+            seconds.append(float(secs))
+
+    if not duration_seconds:
+        duration_seconds = len(transcript.split()) / WORDS_PER_MINUTE * 60
+    if len(positions) > 1 and duration_seconds <= seconds[-1]:
+        # Duration unknown or stale; extrapolate at the rate of the last marker
+        rate = seconds[-1] / max(positions[-1], 1)
+        duration_seconds = seconds[-1] + (len(transcript) - positions[-1]) * rate
+    positions.append(max(len(transcript), positions[-1] + 1))
+    seconds.append(max(duration_seconds, seconds[-1]))
+    return positions, seconds
+
+
+def time_at(anchors: Tuple[List[int], List[float]], offset: int) -> float:
+    positions, seconds = anchors
+    i = min(max(bisect.bisect_right(positions, offset) - 1, 0), len(positions) - 2)
+    span = positions[i + 1] - positions[i]
+    fraction = (offset - positions[i]) / span if span else 0
+    return round(seconds[i] + fraction * (seconds[i + 1] - seconds[i]), 1)
+
+
+def detect_ad_segments(transcript: str, duration_seconds: Optional[float] = None) -> List[Dict[str, Any]]:
+    """
+    Find likely ad, sponsor and self-promotion segments.
+
+    Args:
+        transcript: Transcript text
+        duration_seconds: Episode length, used when the transcript has no timestamps
+
+    Returns:
+        Segments ordered by position, each with label, character range,
+        time range, confidence and the cues that matched
+    """
+    sentences = split_sentences(transcript)
+    anchors = time_anchors(transcript, duration_seconds)
+    segments: List[Dict[str, Any]] = []
+    current: Optional[Dict[str, Any]] = None
+    gap = 0
+
+    def finish():
+        if not current:
+            return
+        # A lone offer mention without an opener is weak evidence
+        confidence = min(1.0, (0.5 if current["opened"] else 0.2) + 0.15 * len(current["cues"]))
+        if confidence >= settings.ad_detection_min_confidence:
+            segments.append({
+                "label": current["label"],
+                "start_char": current["start"],
+                "end_char": current["end"],
+                "start_seconds": time_at(anchors, current["start"]),
+                "end_seconds": time_at(anchors, current["end"]),
+                "confidence": round(confidence, 2),
+                "cues": sorted(set(current["cues"])),
+            })
+
+    for start, end, text in sentences:
+        opener = next(((label, m) for label, pattern in OPENERS.items() if (m := pattern.search(text))), None)
+        offers = [m.group().lower() for m in OFFER.finditer(text)]
+        closer = CLOSER.search(text)
+
+        if opener and (not current or current["label"] != opener[0] or gap):
+            finish()
+            current = {"label": opener[0], "start": start, "end": end, "cues": [opener[1].group().lower()], "opened": True}
+            gap = 0
+        elif offers and not current:
+            current = {"label": AD, "start": start, "end": end, "cues": [], "opened": False}
+            gap = 0
+
+        if current:
+            if offers or opener or closer:
+                current["end"] = end
+                current["cues"].extend(offers)
+                if opener and current["start"] != start:
+                    current["cues"].append(opener[1].group().lower())
+                gap = 0
+            else:
+                gap += 1
+            if closer:
+                current["cues"].append(closer.group().lower())
+                finish()
+                current = None
+            elif gap > MAX_GAP_SENTENCES:
+                finish()
+                current = None
+
+    finish()
+    return segments
+
+
+def strip_ad_segments(transcript: str, segments: List[Dict[str, Any]]) -> str:
+    """Remove detected segments from a transcript (for summaries, indexing and exports)."""
+    if not segments:
+        return transcript
+    parts, cursor = [], 0
+    for segment in sorted(segments, key=lambda s: s["start_char"]):
+        if segment["start_char"] > cursor:
+            parts.append(transcript[cursor:segment["start_char"]].rstrip())
+        cursor = max(cursor, segment["end_char"])
+    parts.append(transcript[cursor:].lstrip())
+    return "\n".join(part for part in parts if part)
+
+
+class AdDetectionService:
+    """Runs ad detection on completed episodes and stores the segments."""
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+
+    async def analyze_completed(self, episode_id: str, transcript: Optional[str] = None):
+        """Best-effort detection after a transcription completes."""
+        if not settings.ad_detection_enabled:
+            return
+        try:
+            await self.analyze_episode(episode_id, transcript)
+        except Exception as e:
+            logger.warning(f"Ad detection failed for {episode_id}: {e}")
+
+    async def analyze_episode(
+        self,
+        episode_id: str,
+        transcript: Optional[str] = None
+    ) -> Optional[List[Dict[str, Any]]]:
+        """
+        Detect ad segments in an episode's transcript and store them on the episode.
+
+        Args:
+            episode_id: Episode to analyze
+            transcript: Transcript text, if already loaded
+
+        Returns:
+            Detected segments, or None if the episode or transcript is missing
+        """
+        episode = await self.db.episodes.find_one({"episode_id": episode_id})
+        if not episode:
+            return None
+
+        if transcript is None:
+            if episode.get("transcript_s3_key"):
+                transcript = await s3_service.get_transcript(
+                    episode["transcript_s3_key"], **transcript_location(episode.get("storage"))
+                )
+            transcript = transcript or episode.get("transcript_text")
+        if not transcript:
+            return None
+
+        duration_minutes = episode.get("duration_minutes")
+        segments = detect_ad_segments(transcript, duration_minutes * 60 if duration_minutes else None)
+        now = datetime.utcnow()
+        await self.db.episodes.update_one(
+            {"episode_id": episode_id},
+            {"$set": {
+                "ad_segments": segments,
+                "updated_at": now,
+                "ad_detection": {
+                    "method": "heuristic",
+                    "version": DETECTOR_VERSION,
+                    "analyzed_at": now,
+                    "ad_seconds": round(sum(s["end_seconds"] - s["start_seconds"] for s in segments), 1),
+                },
+            }}
+        )
+        await cache.invalidate("episodes")
+        logger.info(f"Detected {len(segments)} ad segment(s) in episode {episode_id}")
+        return segments
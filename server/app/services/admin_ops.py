@@ -0,0 +1,209 @@
+"""Operational repairs for episodes, bulk jobs and transcripts.
+
+These fix state left inconsistent by crashes, lost Lambda callbacks or
+manual S3 changes; they are exposed under /api/admin for operators.
+"""
+import logging
+import re
+from datetime import datetime, timedelta
+from typing import Any, Dict, List, Optional
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.models.schemas import BulkJobStatus, TranscriptStatus
+from app.services.bulk_transcribe_service import BulkTranscribeService
+from app.services.s3_service import s3_service
+
+logger = logging.getLogger(__name__)
+
+# Final transcripts written by the merge Lambda and bulk jobs
+FINAL_TRANSCRIPT_KEY = re.compile(r"^transcripts/([^/]+)/final\.txt$")
+
+FORCE_COMPLETE_ERROR = "Not processed: job force-completed by an operator"
+
+
+class JobRunningError(Exception):
+    """Raised when a job can't be repaired because this process is running it."""
+
+
+class AdminOpsService:
+    """Requeues stuck episodes, repairs bulk jobs and re-links transcripts."""
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+        self.episodes_collection = db.episodes
+        self.jobs_collection = db.bulk_transcribe_jobs
+        self.job_episodes = db.job_episodes
+
+    async def find_stuck_episodes(self, older_than_minutes: int, limit: int) -> List[Dict[str, Any]]:
+        """
+        Episodes processing for longer than the threshold without an update.
+
+        Episodes a bulk job in this process is transcribing right now are
+        left out, however long they take.
+        """
+        cutoff = datetime.utcnow() - timedelta(minutes=older_than_minutes)
+        active_ids = await self.job_episodes.distinct("episode_id", {
+            "job_id": {"$in": list(BulkTranscribeService.running_jobs)},
+            "status": TranscriptStatus.PROCESSING.value,
+        })
+        cursor = self.episodes_collection.find(
+            {
+                "transcript_status": TranscriptStatus.PROCESSING.value,
+                "deleted_at": None,
+                "episode_id": {"$nin": [episode_id for episode_id in active_ids if episode_id]},
+                "$or": [{"updated_at": {"$lt": cutoff}}, {"updated_at": None}],
+            },
+            {"episode_id": 1, "audio_url": 1, "title": 1}
+        ).limit(limit)
+        return await cursor.to_list(length=limit)
+
+    async def reset_episodes(self, episode_ids: List[str]) -> int:
+        """Put episodes back to pending so they can be transcribed again."""
+        result = await self.episodes_collection.update_many(
+            {"episode_id": {"$in": episode_ids}, "transcript_status": TranscriptStatus.PROCESSING.value},
+            {"$set": {
+                "transcript_status": TranscriptStatus.PENDING.value,
+                "processing_step": None,
+                "error_message": None,
+                "updated_at": datetime.utcnow(),
+            }}
+        )
+        return result.modified_count
+
+    async def recompute_job_counters(self, job_id: str) -> Optional[Dict[str, Any]]:
+        """
+        Rebuild a job's counters and actual cost from its per-episode progress.
+
+        Returns:
+            The recomputed fields, or None if the job doesn't exist
+        """
+        if not await self.jobs_collection.find_one({"job_id": job_id}, {"_id": 1}):
+            return None
+
+        counts = {status.value: 0 for status in TranscriptStatus}
+        actual_cost: Dict[str, float] = {}
+        async for entry in self.job_episodes.find({"job_id": job_id}, {"status": 1, "cost": 1}):
+            status = entry.get("status", TranscriptStatus.PENDING.value)
+            counts[status] = counts.get(status, 0) + 1
+            if entry.get("status") == TranscriptStatus.COMPLETED.value:
+                for key, value in (entry.get("cost") or {}).items():
+                    actual_cost[key] = actual_cost.get(key, 0) + value
+
+        total = sum(counts.values())
+        finished = counts[TranscriptStatus.COMPLETED.value] + counts[TranscriptStatus.FAILED.value]
+        to_process = total - counts[TranscriptStatus.SKIPPED.value]
+        fields: Dict[str, Any] = {
+            "total_episodes": total,
+            "processed_episodes": finished + counts[TranscriptStatus.SKIPPED.value],
+            "successful_episodes": counts[TranscriptStatus.COMPLETED.value],
+            "failed_episodes": counts[TranscriptStatus.FAILED.value],
+            "skipped_episodes": counts[TranscriptStatus.SKIPPED.value],
+            "progress_percent": round(finished / to_process * 100, 1) if to_process else 100.0,
+        }
+        if actual_cost:
+            fields["actual_cost"] = actual_cost
+
+        await BulkTranscribeService(self.db).update_job(job_id, dict(fields))
+        logger.info(f"Recomputed counters of job {job_id}: {fields}")
+        return fields
+
+    async def force_complete_job(self, job_id: str) -> Optional[Dict[str, Any]]:
+        """
+        Mark a job completed, failing the episodes it never finished.
+
+        Returns:
+            The recomputed counters, or None if the job doesn't exist
+
+        Raises:
+            JobRunningError: If this process is still running the job
+        """
+        if job_id in BulkTranscribeService.running_jobs:
+            raise JobRunningError(f"Job {job_id} is running; cancel it first")
+
+        now = datetime.utcnow()
+        await self.job_episodes.update_many(
+            {
+                "job_id": job_id,
+                "status": {"$in": [TranscriptStatus.PENDING.value, TranscriptStatus.PROCESSING.value]},
+            },
+            {"$set": {
+                "status": TranscriptStatus.FAILED.value,
+                "error_message": FORCE_COMPLETE_ERROR,
+                "completed_at": now,
+            }}
+        )
+        fields = await self.recompute_job_counters(job_id)
+        if fields is None:
+            return None
+
+        await BulkTranscribeService(self.db).update_job(job_id, {
+            "status": BulkJobStatus.COMPLETED.value,
+            "current_episode": None,
+            "queue_position": None,
+            "estimated_completion_at": None,
+            "completed_at": now,
+        })
+        logger.info(f"Force-completed job {job_id}")
+        return fields
+
+    async def relink_transcripts(self, dry_run: bool = False) -> Dict[str, Any]:
+        """
+        Point episodes at final transcripts in S3 their documents don't know about.
+
+        An episode is re-linked when transcripts/<episode_id>/final.txt
+        exists but the episode isn't completed with that key, e.g. after a
+        lost merge callback. Episodes still processing are only re-linked if
+        the transcript is newer than their last update, so a rerun in flight
+        doesn't pick up its predecessor's transcript.
+
+        Returns:
+            Counts and the IDs of re-linked episodes and of transcripts
+            without an episode
+        """
+        objects = await s3_service.list_objects("transcripts/")
+        by_episode = {}
+        for item in objects:
+            match = FINAL_TRANSCRIPT_KEY.match(item["key"])
+            if match:
+                by_episode[match.group(1)] = item
+
+        relinked, unmatched = [], []
+        for episode_id, item in by_episode.items():
+            episode = await self.episodes_collection.find_one(
+                {"episode_id": episode_id},
+                {"transcript_status": 1, "transcript_s3_key": 1, "total_words": 1, "updated_at": 1}
+            )
+            if not episode:
+                unmatched.append(episode_id)
+                continue
+            if (episode.get("transcript_status") == TranscriptStatus.COMPLETED.value
+                    and episode.get("transcript_s3_key") == item["key"]):
+                continue
+            if (episode.get("transcript_status") == TranscriptStatus.PROCESSING.value
+                    and episode.get("updated_at") and item["last_modified"] < episode["updated_at"]):
+                continue
+
+            relinked.append(episode_id)
+            if dry_run:
+                continue
+
+            fields = {
+                "transcript_status": TranscriptStatus.COMPLETED.value,
+                "processing_step": "completed",
+                "transcript_s3_key": item["key"],
+                "error_message": None,
+                "updated_at": datetime.utcnow(),
+            }
+            if not episode.get("total_words"):
+                transcript = await s3_service.get_transcript(item["key"])
+                fields["total_words"] = len((transcript or "").split())
+            await self.episodes_collection.update_one({"episode_id": episode_id}, {"$set": fields})
+            logger.info(f"Re-linked transcript {item['key']} to episode {episode_id}")
+
+        return {
+            "scanned": len(by_episode),
+            "relinked": len(relinked),
+            "relinked_episode_ids": relinked,
+            "unmatched_episode_ids": unmatched,
+        }
@@ -0,0 +1,236 @@
+"""Soft delete, restore and transcript archival for podcasts and episodes."""
+import asyncio
+import logging
+from datetime import datetime, timedelta
+from typing import Any, Dict, Optional
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.cache import cache
+from app.config import settings
+from app.services.s3_service import s3_service
+from app.services.storage_locations import same_bucket, transcript_location
+
+logger = logging.getLogger(__name__)
+
+
+class ArchiveService:
+    """
+    Manages the deleted_at lifecycle.
+
+    Deleting marks documents with deleted_at and hides them from listings.
+    Once a deletion is older than settings.archive_after_days, the episode's
+    transcript is moved under settings.archive_prefix with the configured
+    storage class. Restoring clears deleted_at and moves archived transcripts
+    back to their original keys.
+    """
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+
+    async def delete_podcast(self, podcast_id: str) -> int:
+        """
+        Soft-delete a podcast and its episodes.
+
+        Returns:
+            Number of episodes marked deleted
+        """
+        now = datetime.utcnow()
+        await self.db.podcasts.update_one(
+            {"podcast_id": podcast_id},
+            {"$set": {"active": False, "deleted_at": now}}
+        )
+        # Episodes deleted individually keep their own deleted_at
+        result = await self.db.episodes.update_many(
+            {"podcast_id": podcast_id, "deleted_at": None},
+            {"$set": {"deleted_at": now, "deleted_with_podcast": True}}
+        )
+        logger.info(f"Soft-deleted podcast {podcast_id} and {result.modified_count} episodes")
+        await cache.invalidate("episodes")
+        return result.modified_count
+
+    async def restore_podcast(self, podcast_id: str) -> Dict[str, int]:
+        """
+        Restore a soft-deleted podcast and the episodes deleted with it.
+
+        Returns:
+            Counts of restored episodes and transcripts moved out of the archive
+        """
+        await self.db.podcasts.update_one(
+            {"podcast_id": podcast_id},
+            {
+                "$set": {"active": True},
+                "$unset": {"deleted_at": "", "archived_at": ""},
+            }
+        )
+        episodes = await self.db.episodes.find(
+            {"podcast_id": podcast_id, "deleted_with_podcast": True}
+        ).to_list(length=None)
+
+        counts = {"episodes": 0, "unarchived": 0, "unarchive_failed": 0}
+        for episode in episodes:
+            result = await self._restore_episode_doc(episode)
+            counts["episodes"] += 1
+            if result is True:
+                counts["unarchived"] += 1
+            elif result is False:
+                counts["unarchive_failed"] += 1
+
+        logger.info(f"Restored podcast {podcast_id}: {counts}")
+        await cache.invalidate("episodes")
+        return counts
+
+    async def delete_episode(self, episode_id: str) -> None:
+        """Soft-delete a single episode."""
+        await self.db.episodes.update_one(
+            {"episode_id": episode_id},
+            {"$set": {"deleted_at": datetime.utcnow(), "deleted_with_podcast": False}}
+        )
+        logger.info(f"Soft-deleted episode {episode_id}")
+        await cache.invalidate("episodes")
+
+    async def restore_episode(self, episode: Dict[str, Any]) -> Optional[bool]:
+        """
+        Restore a soft-deleted episode.
+
+        Returns:
+            True if its transcript was unarchived, False if unarchiving failed,
+            None if the transcript was never archived
+        """
+        result = await self._restore_episode_doc(episode)
+        logger.info(f"Restored episode {episode['episode_id']}")
+        await cache.invalidate("episodes")
+        return result
+
+    async def _restore_episode_doc(self, episode: Dict[str, Any]) -> Optional[bool]:
+        """Clear deletion fields and move an archived transcript back."""
+        update: Dict[str, Any] = {"$unset": {"deleted_at": "", "deleted_with_podcast": ""}}
+        unarchived = None
+
+        original_key = episode.get("transcript_original_s3_key")
+        if episode.get("transcript_archived_at") and original_key:
+            unarchived = await s3_service.move_object(
+                episode["transcript_s3_key"], original_key, **transcript_location(episode.get("storage"))
+            )
+            if unarchived:
+                update["$set"] = {"transcript_s3_key": original_key}
+                update["$unset"].update({"transcript_archived_at": "", "transcript_original_s3_key": ""})
+                # Episodes sharing the transcript follow it back
+                await self.db.episodes.update_many(
+                    {
+                        "transcript_s3_key": episode["transcript_s3_key"],
+                        "episode_id": {"$ne": episode["episode_id"]},
+                        **same_bucket(episode.get("storage")),
+                    },
+                    {
+                        "$set": {"transcript_s3_key": original_key},
+                        "$unset": {"transcript_archived_at": "", "transcript_original_s3_key": ""},
+                    }
+                )
+            elif await self.db.episodes.find_one(
+                {"episode_id": episode["episode_id"], "transcript_archived_at": None}, {"_id": 1}
+            ):
+                # Already moved back with an episode sharing it
+                unarchived = True
+            else:
+                # Transcript stays readable (or restorable) at its archive key
+                logger.warning(f"Could not unarchive transcript for episode {episode['episode_id']}")
+
+        await self.db.episodes.update_one({"episode_id": episode["episode_id"]}, update)
+        return unarchived
+
+    async def archive_episode(self, episode: Dict[str, Any], podcast_id: Optional[str] = None) -> bool:
+        """
+        Move an episode's transcript to the archive prefix.
+
+        Identical transcripts (a rerun and its original, or the same show on
+        two feeds) are stored once; the episodes sharing one follow it to the
+        archive. A transcript another live episode still uses stays where it
+        is, unless that episode belongs to podcast_id, the podcast being
+        archived as a whole.
+
+        Returns:
+            True if the transcript was archived (or there was none to archive)
+        """
+        source_key = episode.get("transcript_s3_key")
+        if not source_key or episode.get("transcript_archived_at"):
+            return True
+
+        storage = episode.get("storage")
+        sharing: Dict[str, Any] = {
+            "transcript_s3_key": source_key,
+            "episode_id": {"$ne": episode["episode_id"]},
+            "deleted_at": None,
+            **same_bucket(storage),
+        }
+        if podcast_id:
+            sharing["podcast_id"] = {"$ne": podcast_id}
+        if await self.db.episodes.find_one(sharing, {"_id": 1}):
+            return True
+
+        archive_key = f"{settings.archive_prefix.rstrip('/')}/{source_key}"
+        if not await s3_service.move_object(
+            source_key, archive_key, settings.archive_storage_class, **transcript_location(storage)
+        ):
+            # A stale copy of an episode archived along with one sharing its transcript
+            return bool(await self.db.episodes.find_one(
+                {"episode_id": episode["episode_id"], "transcript_archived_at": {"$ne": None}}, {"_id": 1}
+            ))
+
+        await self.db.episodes.update_many(
+            {"transcript_s3_key": source_key, **same_bucket(storage)},
+            {"$set": {
+                "transcript_s3_key": archive_key,
+                "transcript_original_s3_key": source_key,
+                "transcript_archived_at": datetime.utcnow(),
+            }}
+        )
+        return True
+
+    async def archive_expired(self) -> Dict[str, int]:
+        """
+        Move transcripts of episodes deleted longer than the retention window
+        to the archive prefix.
+
+        Returns:
+            Counts of archived and failed transcripts
+        """
+        cutoff = datetime.utcnow() - timedelta(days=settings.archive_after_days)
+        episodes = await self.db.episodes.find({
+            "deleted_at": {"$lte": cutoff},
+            "transcript_s3_key": {"$ne": None},
+            "transcript_archived_at": None,
+        }).to_list(length=None)
+
+        counts = {"archived": 0, "failed": 0}
+        for episode in episodes:
+            if await self.archive_episode(episode):
+                counts["archived"] += 1
+            else:
+                counts["failed"] += 1
+
+        await self.db.podcasts.update_many(
+            {"deleted_at": {"$lte": cutoff}, "archived_at": None},
+            {"$set": {"archived_at": datetime.utcnow()}}
+        )
+
+        if episodes:
+            logger.info(f"Archival run: {counts}")
+        return counts
+
+
+async def run_archival_scheduler(get_db, interval_hours: int):
+    """
+    Periodically archive transcripts of long-deleted episodes until cancelled.
+
+    Args:
+        get_db: Callable returning the database instance
+        interval_hours: Hours between archival runs
+    """
+    logger.info(f"Starting transcript archival scheduler (every {interval_hours} hours)")
+    while True:
+        await asyncio.sleep(interval_hours * 3600)
+        try:
+            await ArchiveService(get_db()).archive_expired()
+        except Exception as e:
+            logger.error(f"Transcript archival run failed: {e}")
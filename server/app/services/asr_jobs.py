@@ -0,0 +1,251 @@
+"""
+Submit-and-poll transcription with asynchronous ASR providers.
+
+With ASR_PROVIDER set, an episode is submitted to the provider and the API
+returns; nothing holds a connection open while the provider transcribes.
+Each submission is an asr_jobs document. The provider reports the result
+at POST /api/asr/callbacks/<provider> (the callback URL carries the job ID
+and an HMAC of it under CALLBACK_SECRET, since providers can't sign with
+our secret), and a poller asks pollable providers about jobs every
+ASR_POLL_INTERVAL_SECONDS in case a callback is lost. Whichever arrives
+first completes the job; jobs still unanswered after ASR_JOB_TIMEOUT_HOURS
+fail.
+"""
+import asyncio
+import hashlib
+import hmac
+import logging
+import uuid
+from datetime import datetime, timedelta
+from typing import Any, Dict, Optional
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.cache import cache
+from app.config import settings
+from app.models.schemas import TranscriptStatus
+from app.services.ad_detection import AdDetectionService
+from app.services.asr_providers import ASRResult, COMPLETED, FAILED, PROCESSING, get_provider
+from app.services.chat_notifier import chat_notifier
+from app.services.cost_service import CostService
+from app.services.private_transcripts import episode_encryption
+from app.services.storage_locations import episode_storage
+from app.services.transcript_store import store_transcript
+
+logger = logging.getLogger(__name__)
+
+SUBMITTED = "submitted"
+
+
+def callback_token(provider: str, asr_job_id: str) -> str:
+    """HMAC authenticating a provider's callback for one job."""
+    message = f"{provider}:{asr_job_id}".encode()
+    return hmac.new(settings.callback_secret.encode(), message, hashlib.sha256).hexdigest()
+
+
+def callback_url(provider: str, asr_job_id: str) -> Optional[str]:
+    """Where the provider reports the job, or None without CALLBACK_SECRET."""
+    if not settings.callback_secret:
+        return None
+    base = settings.public_base_url.rstrip("/")
+    return f"{base}/api/asr/callbacks/{provider}?job={asr_job_id}&token={callback_token(provider, asr_job_id)}"
+
+
+class ASRJobService:
+    """Tracks episodes submitted to asynchronous ASR providers."""
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+        self.jobs_collection = db.asr_jobs
+
+    async def submit(self, episode_id: str, audio_url: str) -> Dict[str, Any]:
+        """
+        Submit an episode to ASR_PROVIDER.
+
+        Returns:
+            Dict with status "submitted" (or "failed" and error_message)
+        """
+        provider = get_provider(settings.asr_provider)
+        asr_job_id = f"asr_{uuid.uuid4().hex[:12]}"
+        now = datetime.utcnow()
+        try:
+            provider_job_id = await provider.submit(audio_url, callback_url(provider.name, asr_job_id))
+        except Exception as e:
+            error_message = f"Submitting to {provider.name} failed: {e}"
+            logger.error(f"Transcription failed for episode {episode_id}: {error_message}")
+            await self._set_episode_failed(episode_id, error_message)
+            return {"status": "failed", "episode_id": episode_id, "error_message": error_message}
+
+        await self.jobs_collection.insert_one({
+            "asr_job_id": asr_job_id,
+            "provider": provider.name,
+            "provider_job_id": provider_job_id,
+            "episode_id": episode_id,
+            "audio_url": audio_url,
+            "status": SUBMITTED,
+            "submitted_at": now,
+            "next_poll_at": now + timedelta(seconds=settings.asr_poll_interval_seconds),
+        })
+        await self.db.episodes.update_one(
+            {"episode_id": episode_id},
+            {
+                "$set": {
+                    "transcript_status": TranscriptStatus.PROCESSING.value,
+                    "processing_step": "submitted",
+                    "asr_job_id": asr_job_id,
+                    "updated_at": now,
+                },
+                "$unset": {"transcript_progress": ""}
+            }
+        )
+        await cache.invalidate("episodes")
+        logger.info(f"Submitted episode {episode_id} to {provider.name} as {provider_job_id}")
+        return {"status": SUBMITTED, "episode_id": episode_id, "asr_job_id": asr_job_id}
+
+    async def handle_callback(self, provider_name: str, asr_job_id: str, payload: Dict[str, Any]) -> Optional[Dict[str, Any]]:
+        """
+        Apply a provider callback.
+
+        Returns:
+            The job, or None if it was unknown or already finished
+        """
+        job = await self.jobs_collection.find_one({"asr_job_id": asr_job_id, "provider": provider_name})
+        if not job or job["status"] != SUBMITTED:
+            return None
+        provider_job_id, result = await get_provider(provider_name).parse_callback(payload)
+        if provider_job_id != job["provider_job_id"]:
+            raise ValueError(f"Callback is for {provider_job_id}, not {job['provider_job_id']}")
+        if result.status == PROCESSING:
+            return None
+        return job if await self._finish(job, result) else None
+
+    async def poll_due(self) -> int:
+        """
+        Check submitted jobs that are due a poll.
+
+        Returns:
+            Number of jobs finished
+        """
+        now = datetime.utcnow()
+        finished = 0
+        jobs = await self.jobs_collection.find(
+            {"status": SUBMITTED, "next_poll_at": {"$lte": now}}
+        ).to_list(length=None)
+        for job in jobs:
+            try:
+                if now - job["submitted_at"] > timedelta(hours=settings.asr_job_timeout_hours):
+                    result = ASRResult(
+                        FAILED, error_message=f"No result from {job['provider']} after {settings.asr_job_timeout_hours}h"
+                    )
+                else:
+                    provider = get_provider(job["provider"])
+                    result = await provider.fetch(job["provider_job_id"]) if provider else None
+                if result and result.status != PROCESSING:
+                    if await self._finish(job, result):
+                        finished += 1
+                    continue
+            except Exception as e:
+                logger.warning(f"Failed to poll ASR job {job['asr_job_id']}: {e}")
+            await self.jobs_collection.update_one(
+                {"asr_job_id": job["asr_job_id"], "status": SUBMITTED},
+                {"$set": {"next_poll_at": now + timedelta(seconds=settings.asr_poll_interval_seconds)}}
+            )
+        return finished
+
+    async def _finish(self, job: Dict[str, Any], result: ASRResult) -> bool:
+        """Record a job's result on it and its episode; False if it was already finished."""
+        status = COMPLETED if result.status == COMPLETED else FAILED
+        claimed = await self.jobs_collection.find_one_and_update(
+            {"asr_job_id": job["asr_job_id"], "status": SUBMITTED},
+            {"$set": {
+                "status": status,
+                "completed_at": datetime.utcnow(),
+                "error_message": result.error_message,
+            }}
+        )
+        if not claimed:
+            return False
+
+        episode_id = job["episode_id"]
+        if status == FAILED:
+            logger.warning(f"{job['provider']} failed episode {episode_id}: {result.error_message}")
+            await self._set_episode_failed(episode_id, result.error_message)
+            return True
+
+        try:
+            storage = await episode_storage(self.db, episode_id)
+            encryption = await episode_encryption(self.db, episode_id)
+            stored = await store_transcript(result.text, storage, encryption)
+            if not stored:
+                raise Exception("Failed to store transcript in S3")
+            transcript_s3_key, transcript_sha256 = stored
+            total_words = len(result.text.split())
+            now = datetime.utcnow()
+            await self.db.episodes.update_one(
+                {"episode_id": episode_id},
+                {"$set": {
+                    "transcript_status": TranscriptStatus.COMPLETED.value,
+                    "processing_step": "completed",
+                    "transcript_s3_key": transcript_s3_key,
+                    "transcript_sha256": transcript_sha256,
+                    "storage": storage,
+                    "total_words": total_words,
+                    "error_message": None,
+                    "processed_at": now,
+                    "updated_at": now,
+                }}
+            )
+            await cache.invalidate("episodes")
+        except Exception as e:
+            logger.error(f"Failed to store {job['provider']} transcript of episode {episode_id}: {e}")
+            await self._set_episode_failed(episode_id, str(e))
+            return True
+
+        logger.info(f"{job['provider']} completed episode {episode_id}: {total_words} words")
+        try:
+            await CostService(self.db).record_episode_cost(
+                episode_id, result.audio_seconds / 60, 0, len(result.text.encode())
+            )
+        except Exception as e:
+            logger.warning(f"Failed to record cost for {episode_id}: {e}")
+        await AdDetectionService(self.db).analyze_completed(episode_id)
+        await self._announce(episode_id)
+        return True
+
+    async def _set_episode_failed(self, episode_id: str, error_message: Optional[str]):
+        await self.db.episodes.update_one(
+            {"episode_id": episode_id},
+            {"$set": {
+                "transcript_status": TranscriptStatus.FAILED.value,
+                "processing_step": None,
+                "error_message": error_message,
+                "updated_at": datetime.utcnow(),
+            }}
+        )
+        await cache.invalidate("episodes")
+
+    async def _announce(self, episode_id: str):
+        """Post chat notifications for a completed episode of a flagship podcast."""
+        try:
+            episode = await self.db.episodes.find_one({"episode_id": episode_id})
+            podcast = episode and await self.db.podcasts.find_one({"podcast_id": episode.get("podcast_id")})
+            if podcast:
+                await chat_notifier.notify_episode_transcribed(podcast, episode)
+        except Exception as e:
+            logger.warning(f"Failed to announce transcription of {episode_id}: {e}")
+
+
+async def run_asr_poller(get_db):
+    """
+    Poll ASR providers for submitted jobs until cancelled.
+
+    Args:
+        get_db: Callable returning the database instance
+    """
+    logger.info(f"Starting {settings.asr_provider} job poller")
+    while True:
+        await asyncio.sleep(settings.asr_poll_interval_seconds)
+        try:
+            await ASRJobService(get_db()).poll_due()
+        except Exception as e:
+            logger.error(f"ASR job poll failed: {e}")
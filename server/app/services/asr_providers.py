@@ -0,0 +1,127 @@
+"""
+Clients for asynchronous ASR providers.
+
+These providers take an audio URL and answer at once with a job ID; the
+transcript arrives later at a callback URL, or (AssemblyAI) can be fetched
+by polling. See app/services/asr_jobs.py for how jobs are tracked.
+"""
+import logging
+from dataclasses import dataclass
+from typing import Any, Dict, Optional, Tuple
+
+import httpx
+
+from app.config import settings
+
+logger = logging.getLogger(__name__)
+
+REQUEST_TIMEOUT = 30.0
+
+PROCESSING = "processing"
+COMPLETED = "completed"
+FAILED = "failed"
+
+
+@dataclass
+class ASRResult:
+    """State of a provider job."""
+    status: str  # PROCESSING, COMPLETED or FAILED
+    text: str = ""
+    audio_seconds: float = 0.0
+    error_message: Optional[str] = None
+
+
+class ASRProvider:
+    """An asynchronous transcription API."""
+
+    name = ""
+
+    async def submit(self, audio_url: str, callback_url: Optional[str]) -> str:
+        """Start transcribing audio_url; returns the provider's job ID."""
+        raise NotImplementedError
+
+    async def fetch(self, provider_job_id: str) -> Optional[ASRResult]:
+        """Current state of a job, or None if the provider can't be polled."""
+        return None
+
+    async def parse_callback(self, payload: Dict[str, Any]) -> Tuple[str, ASRResult]:
+        """The provider job ID and result carried by a callback."""
+        raise NotImplementedError
+
+
+class AssemblyAIProvider(ASRProvider):
+    """AssemblyAI: webhooks only announce completion, the transcript is fetched."""
+
+    name = "assemblyai"
+    base_url = "https://api.assemblyai.com/v2"
+
+    def _headers(self) -> Dict[str, str]:
+        return {"authorization": settings.assemblyai_api_key}
+
+    async def submit(self, audio_url: str, callback_url: Optional[str]) -> str:
+        payload: Dict[str, Any] = {"audio_url": audio_url}
+        if callback_url:
+            payload["webhook_url"] = callback_url
+        async with httpx.AsyncClient(timeout=REQUEST_TIMEOUT) as client:
+            response = await client.post(f"{self.base_url}/transcript", json=payload, headers=self._headers())
+            response.raise_for_status()
+            return response.json()["id"]
+
+    async def fetch(self, provider_job_id: str) -> Optional[ASRResult]:
+        async with httpx.AsyncClient(timeout=REQUEST_TIMEOUT) as client:
+            response = await client.get(f"{self.base_url}/transcript/{provider_job_id}", headers=self._headers())
+            response.raise_for_status()
+            data = response.json()
+        if data.get("status") == "completed":
+            return ASRResult(COMPLETED, data.get("text") or "", float(data.get("audio_duration") or 0))
+        if data.get("status") == "error":
+            return ASRResult(FAILED, error_message=data.get("error") or "Transcription failed")
+        return ASRResult(PROCESSING)
+
+    async def parse_callback(self, payload: Dict[str, Any]) -> Tuple[str, ASRResult]:
+        provider_job_id = payload["transcript_id"]
+        result = await self.fetch(provider_job_id)
+        return provider_job_id, result
+
+
+class DeepgramProvider(ASRProvider):
+    """Deepgram: the callback carries the whole response; there is nothing to poll."""
+
+    name = "deepgram"
+    base_url = "https://api.deepgram.com/v1"
+
+    async def submit(self, audio_url: str, callback_url: Optional[str]) -> str:
+        if not callback_url:
+            raise ValueError("Deepgram needs a callback URL (set CALLBACK_SECRET)")
+        async with httpx.AsyncClient(timeout=REQUEST_TIMEOUT) as client:
+            response = await client.post(
+                f"{self.base_url}/listen",
+                params={"callback": callback_url, "punctuate": "true", "smart_format": "true"},
+                json={"url": audio_url},
+                headers={"Authorization": f"Token {settings.deepgram_api_key}"},
+            )
+            response.raise_for_status()
+            return response.json()["request_id"]
+
+    async def parse_callback(self, payload: Dict[str, Any]) -> Tuple[str, ASRResult]:
+        metadata = payload.get("metadata") or {}
+        provider_job_id = metadata.get("request_id") or payload["request_id"]
+        channels = (payload.get("results") or {}).get("channels")
+        if not channels:
+            return provider_job_id, ASRResult(
+                FAILED, error_message=payload.get("err_msg") or "Deepgram returned no transcript"
+            )
+        alternatives = channels[0].get("alternatives") or [{}]
+        return provider_job_id, ASRResult(
+            COMPLETED, alternatives[0].get("transcript") or "", float(metadata.get("duration") or 0)
+        )
+
+
+PROVIDERS: Dict[str, ASRProvider] = {
+    provider.name: provider for provider in (AssemblyAIProvider(), DeepgramProvider())
+}
+
+
+def get_provider(name: str) -> Optional[ASRProvider]:
+    """The provider registered as name, if any."""
+    return PROVIDERS.get(name)
@@ -0,0 +1,124 @@
+"""
+Audio clips cut from an episode by time range.
+
+A clip is cut with ffmpeg straight from the episode's stored audio in the
+audio bucket (s3_audio_key) when it is there, and from the feed's audio URL
+otherwise; ffmpeg seeks with range requests, so only the clipped part is
+downloaded. Clips are re-encoded to MP3 and stored in the audio bucket
+under clips/<episode_id>/, keyed by the time range, so sharing the same
+moment twice reuses the first cut.
+"""
+import asyncio
+import logging
+from typing import Any, Dict, Optional
+
+from app.config import settings
+from app.services.s3_service import s3_service
+from app.services.storage_locations import audio_location
+from app.services.temp_storage import temp_storage
+
+logger = logging.getLogger(__name__)
+
+CLIP_URL_EXPIRES_SECONDS = 24 * 3600
+CLIP_BITRATE = "128k"
+
+# 128 kbit/s plus container overhead, reserved in the temp budget per clip second
+CLIP_BYTES_PER_SECOND = 17_000
+
+
+class ClipError(Exception):
+    """Raised when the audio can't be cut."""
+
+
+def clip_key(episode_id: str, start_seconds: float, end_seconds: float) -> str:
+    """S3 key of a clip; times are rounded to milliseconds."""
+    return f"clips/{episode_id}/{round(start_seconds * 1000)}-{round(end_seconds * 1000)}.mp3"
+
+
+async def _source_url(episode: Dict[str, Any]) -> Optional[str]:
+    """URL ffmpeg reads the episode's audio from."""
+    key = episode.get("s3_audio_key")
+    location = audio_location(episode.get("storage"))
+    if key and await s3_service.object_exists(key, **location):
+        return s3_service.generate_presigned_url(key, **location)
+    return episode.get("audio_url")
+
+
+async def _cut(source: str, start_seconds: float, end_seconds: float) -> bytes:
+    duration = end_seconds - start_seconds
+    async with temp_storage.create(suffix=".mp3") as temp:
+        await temp.reserve(int(duration * CLIP_BYTES_PER_SECOND) + 64 * 1024)
+        try:
+            process = await asyncio.create_subprocess_exec(
+                settings.ffmpeg_path, "-hide_banner", "-loglevel", "error", "-y",
+                "-ss", f"{start_seconds:.3f}", "-i", source, "-t", f"{duration:.3f}",
+                "-vn", "-c:a", "libmp3lame", "-b:a", CLIP_BITRATE,
+                str(temp.path),
+                stdout=asyncio.subprocess.DEVNULL,
+                stderr=asyncio.subprocess.PIPE,
+            )
+        except OSError as e:
+            raise ClipError(f"Could not run ffmpeg: {e}")
+
+        try:
+            _, stderr = await asyncio.wait_for(process.communicate(), timeout=settings.clip_timeout_seconds)
+        except asyncio.TimeoutError:
+            process.kill()
+            await process.wait()
+            raise ClipError(f"ffmpeg took longer than {settings.clip_timeout_seconds}s")
+        except asyncio.CancelledError:
+            process.kill()
+            await process.wait()
+            raise
+
+        if process.returncode != 0:
+            raise ClipError(
+                f"ffmpeg failed (exit {process.returncode}): {stderr.decode(errors='replace').strip()[-500:]}"
+            )
+        data = temp.path.read_bytes()
+        if not data:
+            # ffmpeg succeeds with no output when the start is past the end of the audio
+            raise ClipError("The time range is outside the episode's audio")
+        return data
+
+
+async def create_clip(episode: Dict[str, Any], start_seconds: float, end_seconds: float) -> Dict[str, Any]:
+    """
+    Cut a clip of an episode (or reuse an earlier identical one) and sign its URL.
+
+    Args:
+        episode: Episode document
+        start_seconds: Clip start in the episode's audio
+        end_seconds: Clip end in the episode's audio
+
+    Returns:
+        The clip's range, S3 key, presigned URL and whether it was cut now
+
+    Raises:
+        ClipError: If the episode has no audio or ffmpeg fails
+    """
+    key = clip_key(episode["episode_id"], start_seconds, end_seconds)
+    created = False
+    location = audio_location(episode.get("storage"))
+    if not await s3_service.object_exists(key, **location):
+        source = await _source_url(episode)
+        if not source:
+            raise ClipError("Episode has no audio")
+        data = await _cut(source, start_seconds, end_seconds)
+        if not await s3_service.upload_bytes(key, data, "audio/mpeg", **location):
+            raise ClipError("Failed to store the clip")
+        created = True
+        logger.info(f"Cut {end_seconds - start_seconds:.1f}s clip of episode {episode['episode_id']} to {key}")
+
+    return {
+        "episode_id": episode["episode_id"],
+        "start_seconds": start_seconds,
+        "end_seconds": end_seconds,
+        "duration_seconds": round(end_seconds - start_seconds, 3),
+        "s3_key": key,
+        "url": s3_service.generate_presigned_url(
+            key, expires_in=CLIP_URL_EXPIRES_SECONDS, **location
+        ),
+        "expires_in": CLIP_URL_EXPIRES_SECONDS,
+        "created": created,
+    }
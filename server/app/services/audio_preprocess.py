@@ -0,0 +1,96 @@
+"""Optional ffmpeg preprocessing of audio before it is sent to Whisper.
+
+Whisper resamples everything to 16kHz mono anyway, so doing it here shrinks
+the upload; loudness normalization helps quiet or unevenly mixed feeds, and
+trimming leading/trailing silence skips audio that only costs GPU time.
+"""
+import asyncio
+import logging
+from pathlib import Path
+
+from app.config import settings
+from app.services.temp_storage import TempBudgetExceeded, TempFile
+
+logger = logging.getLogger(__name__)
+
+# FLAC keeps the downmixed audio lossless at roughly 70 MB per hour
+OUTPUT_SUFFIX = ".flac"
+
+
+def _filter_graph() -> str:
+    """Downmix/resample first so the reversed buffers used for trailing trim stay small."""
+    threshold = settings.whisper_preprocess_silence_threshold_db
+    trim = f"silenceremove=start_periods=1:start_duration=0.5:start_threshold={threshold}dB"
+    return ",".join([
+        "aformat=channel_layouts=mono",
+        "aresample=16000",
+        trim,
+        "areverse",
+        trim,
+        "areverse",
+        "loudnorm=I=-16:TP=-1.5:LRA=11",
+    ])
+
+
+async def preprocess_audio(source: Path, dest: TempFile) -> bool:
+    """
+    Convert audio to normalized, silence-trimmed 16kHz mono FLAC.
+
+    Args:
+        source: Downloaded audio
+        dest: Managed temp file to write the result to
+
+    Returns:
+        True if dest holds the processed audio; False if the original should be used
+    """
+    # Reserve an estimate up front, then settle on the actual size
+    try:
+        await dest.reserve(source.stat().st_size * 2)
+    except TempBudgetExceeded as e:
+        logger.warning(f"No temp budget to preprocess {source.name}: {e}; using original audio")
+        return False
+
+    try:
+        process = await asyncio.create_subprocess_exec(
+            settings.ffmpeg_path, "-hide_banner", "-loglevel", "error", "-y",
+            "-i", str(source),
+            "-af", _filter_graph(),
+            "-ac", "1", "-ar", "16000",
+            str(dest.path),
+            stdout=asyncio.subprocess.DEVNULL,
+            stderr=asyncio.subprocess.PIPE,
+        )
+    except OSError as e:
+        logger.warning(f"Could not run ffmpeg ({e}); using original audio")
+        return False
+
+    try:
+        _, stderr = await asyncio.wait_for(
+            process.communicate(), timeout=settings.whisper_preprocess_timeout_seconds
+        )
+    except asyncio.TimeoutError:
+        process.kill()
+        await process.wait()
+        logger.warning(f"Audio preprocessing timed out for {source.name}; using original audio")
+        return False
+    except asyncio.CancelledError:
+        process.kill()
+        await process.wait()
+        raise
+
+    if process.returncode != 0:
+        logger.warning(
+            f"Audio preprocessing failed for {source.name} (exit {process.returncode}): "
+            f"{stderr.decode(errors='replace').strip()[-500:]}; using original audio"
+        )
+        return False
+
+    size = dest.path.stat().st_size
+    try:
+        dest.grow(size)
+    except TempBudgetExceeded as e:
+        logger.warning(f"Preprocessed audio for {source.name} doesn't fit: {e}; using original audio")
+        return False
+
+    logger.info(f"Preprocessed {source.name}: {source.stat().st_size} -> {size} bytes")
+    return True
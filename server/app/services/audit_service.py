@@ -0,0 +1,113 @@
+"""Append-only audit trail of administrative and job actions.
+
+Routes record an entry after a mutation succeeds. The actor and request ID
+come from the request logging middleware through context variables, so
+callers only describe what changed. Entries are never updated or deleted
+through the API.
+"""
+import logging
+from contextvars import ContextVar
+from datetime import datetime
+from typing import Any, Dict, List, Optional
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.pagination import seek_after
+from app.services.quota_service import key_subject
+from app.workspaces import scoped, stamp
+
+logger = logging.getLogger(__name__)
+
+ANONYMOUS_ACTOR = "anonymous"
+ADMIN_ACTOR = "admin"
+
+# Set per request by the request logging middleware (see app/main.py)
+current_actor: ContextVar[str] = ContextVar("current_actor", default=ANONYMOUS_ACTOR)
+current_request_id: ContextVar[Optional[str]] = ContextVar("current_request_id", default=None)
+
+
+def request_actor(admin_key: Optional[str], api_key: Optional[str]) -> str:
+    """
+    Who is making a request: "admin" for admin endpoints, "key:<hash>"
+    (as in quota usage) for API key holders, otherwise "anonymous".
+    """
+    if admin_key:
+        return ADMIN_ACTOR
+    return key_subject(api_key) or ANONYMOUS_ACTOR
+
+
+class AuditService:
+    """Records and queries audit log entries."""
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+        self.audit_collection = db.audit_log
+
+    async def record(
+        self,
+        action: str,
+        target_type: str,
+        target_id: str,
+        workspace_id: Optional[str] = None,
+        details: Optional[Dict[str, Any]] = None
+    ):
+        """
+        Append an entry for the current request. Failures are logged, never
+        raised, so auditing can't fail a mutation that already happened.
+
+        Args:
+            action: What happened, e.g. "podcast.subscribed"
+            target_type: Kind of object acted on, e.g. "podcast"
+            target_id: ID of that object
+            workspace_id: Workspace the object belongs to
+            details: Extra context (no secrets)
+        """
+        entry = stamp({
+            "timestamp": datetime.utcnow(),
+            "actor": current_actor.get(),
+            "request_id": current_request_id.get(),
+            "action": action,
+            "target_type": target_type,
+            "target_id": target_id,
+            "details": details or {},
+        }, workspace_id)
+        try:
+            await self.audit_collection.insert_one(entry)
+        except Exception as e:
+            logger.error(f"Failed to record audit entry {action} {target_type}/{target_id}: {e}")
+
+    async def list_entries(
+        self,
+        filters: Dict[str, Any],
+        since: Optional[datetime] = None,
+        until: Optional[datetime] = None,
+        limit: int = 50,
+        cursor: Optional[str] = None,
+        workspace_id: Optional[str] = None
+    ) -> List[Dict[str, Any]]:
+        """
+        List entries, newest first.
+
+        Args:
+            filters: Exact matches on action, actor, target_type, target_id
+                or request_id
+            since: Inclusive lower bound on timestamp
+            until: Exclusive upper bound on timestamp
+            limit: Maximum entries to return
+            cursor: Opaque cursor; only entries after this position are returned
+            workspace_id: Only entries of this workspace (None = all)
+        """
+        query: Dict[str, Any] = dict(filters)
+        time_range: Dict[str, datetime] = {}
+        if since:
+            time_range["$gte"] = since
+        if until:
+            time_range["$lt"] = until
+        if time_range:
+            query["timestamp"] = time_range
+        if cursor:
+            query.update(seek_after("timestamp", cursor))
+
+        results = self.audit_collection.find(scoped(query, workspace_id))
+        results = results.sort([("timestamp", -1), ("_id", -1)]).limit(limit)
+        return await results.to_list(length=limit)
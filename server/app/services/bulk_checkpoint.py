@@ -0,0 +1,121 @@
+"""
+Checkpoints that let bulk jobs survive API deployments.
+
+The instance processing a job holds a lease on it, kept on the job as
+"checkpoint": the owner, a token that changes with every claim, a heartbeat,
+the next entry to process and the entries in flight. The checkpoint is
+written at every state change and only by the holder of the current token,
+so an instance that lost its lease can't overwrite its successor's progress.
+
+On shutdown an instance requeues its in-flight entries and releases its
+leases; any instance resumes released jobs right away, and jobs whose
+heartbeat is older than BULK_JOB_LEASE_SECONDS (their instance crashed)
+once it expires. Whisper requests are synchronous, so there is no provider
+task to reattach to: an entry that was in flight is transcribed again.
+"""
+import os
+import secrets
+import socket
+from datetime import datetime, timedelta
+from typing import Any, Dict, List, Optional
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.config import settings
+from app.models.schemas import BulkJobStatus, TranscriptStatus
+
+# Identifies this process as a lease owner
+INSTANCE_ID = f"{socket.gethostname()}-{os.getpid()}-{secrets.token_hex(3)}"
+
+
+class JobCheckpoints:
+    """Leases and checkpoints of bulk jobs."""
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.jobs_collection = db.bulk_transcribe_jobs
+        self.job_episodes = db.job_episodes
+
+    async def claim(self, job_id: str, token: Optional[str] = None) -> Optional[str]:
+        """
+        Take a job's lease.
+
+        Args:
+            job_id: Job to claim
+            token: Checkpoint token seen when the job was found resumable
+                (None for a job that has never been claimed)
+
+        Returns:
+            The new token, or None if another instance claimed it first
+        """
+        now = datetime.utcnow()
+        query: Dict[str, Any] = {"job_id": job_id}
+        query["checkpoint.token"] = token if token else {"$exists": False}
+        update: Dict[str, Any] = {"$set": {
+            "checkpoint.token": secrets.token_urlsafe(12),
+            "checkpoint.owner": INSTANCE_ID,
+            "checkpoint.heartbeat_at": now,
+            "checkpoint.updated_at": now,
+        }}
+        if token:
+            update["$inc"] = {"checkpoint.resumes": 1}
+        result = await self.jobs_collection.update_one(query, update)
+        return update["$set"]["checkpoint.token"] if result.modified_count else None
+
+    async def save(self, job_id: str, token: str, next_index: int, in_flight: List[Dict[str, Any]]) -> bool:
+        """
+        Record the next entry to process and the entries in flight.
+
+        Returns:
+            False if the lease has passed to another instance
+        """
+        now = datetime.utcnow()
+        result = await self.jobs_collection.update_one(
+            {"job_id": job_id, "checkpoint.token": token},
+            {"$set": {
+                "checkpoint.next_index": next_index,
+                "checkpoint.in_flight": in_flight,
+                "checkpoint.heartbeat_at": now,
+                "checkpoint.updated_at": now,
+            }}
+        )
+        return result.matched_count > 0
+
+    async def heartbeat(self, job_id: str, token: str) -> bool:
+        """Renew the lease; False if it has passed to another instance."""
+        result = await self.jobs_collection.update_one(
+            {"job_id": job_id, "checkpoint.token": token},
+            {"$set": {"checkpoint.heartbeat_at": datetime.utcnow()}}
+        )
+        return result.matched_count > 0
+
+    async def release(self, job_id: str, token: str):
+        """Give up the lease, so an unfinished job is resumed right away."""
+        await self.jobs_collection.update_one(
+            {"job_id": job_id, "checkpoint.token": token},
+            {"$set": {"checkpoint.owner": None, "checkpoint.heartbeat_at": None}}
+        )
+
+    async def requeue_in_flight(self, job_id: str) -> int:
+        """Return entries left processing by a stopped instance to the queue."""
+        result = await self.job_episodes.update_many(
+            {"job_id": job_id, "status": TranscriptStatus.PROCESSING.value},
+            {"$set": {
+                "status": TranscriptStatus.PENDING.value,
+                "started_at": None,
+                "transcript_progress": None,
+            }}
+        )
+        return result.modified_count
+
+    async def resumable(self) -> List[Dict[str, Any]]:
+        """Unfinished jobs no live instance holds."""
+        expired = datetime.utcnow() - timedelta(seconds=settings.bulk_job_lease_seconds)
+        return await self.jobs_collection.find({
+            "status": {"$in": [BulkJobStatus.PENDING.value, BulkJobStatus.RUNNING.value]},
+            "$or": [
+                {"checkpoint.token": {"$exists": True}, "checkpoint.owner": None},
+                {"checkpoint.heartbeat_at": {"$lt": expired}},
+                # Created but never started (the instance stopped first)
+                {"checkpoint": {"$exists": False}, "created_at": {"$lt": expired}},
+            ],
+        }).to_list(length=None)
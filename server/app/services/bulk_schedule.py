@@ -0,0 +1,138 @@
+"""Recurring bulk transcription jobs.
+
+A bulk job created with a schedule becomes the parent of a series: each time
+the schedule fires, a child job is created for episodes the series hasn't
+transcribed yet, so newly published episodes of a backfilled show keep being
+transcribed.
+"""
+import asyncio
+import logging
+from datetime import datetime
+from typing import Optional, Set
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.cache import cache
+from app.config import settings
+from app.models.schemas import BulkJobStatus, EpisodeOrder, JobPriority, TranscriptStatus
+from app.scheduling import next_run_at
+from app.services.bulk_transcribe_service import BulkTranscribeService
+from app.services.quota_service import QuotaExceededError
+
+logger = logging.getLogger(__name__)
+
+class BulkScheduleService:
+    """Runs due scheduled bulk jobs."""
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+        self.bulk_service = BulkTranscribeService(db)
+        self.jobs_collection = db.bulk_transcribe_jobs
+
+    async def _transcribed_audio_urls(self, parent_job_id: str) -> Set[str]:
+        """Audio URLs already transcribed (or in progress) anywhere in the series."""
+        job_ids = await self.jobs_collection.distinct(
+            "job_id",
+            {"$or": [{"job_id": parent_job_id}, {"parent_job_id": parent_job_id}]}
+        )
+        urls = await self.db.job_episodes.distinct(
+            "audio_url",
+            {"job_id": {"$in": job_ids}, "status": {"$ne": TranscriptStatus.FAILED.value}}
+        )
+        return {url for url in urls if url}
+
+    async def _series_busy(self, parent_job_id: str) -> bool:
+        """Whether any job in the series is still queued or running."""
+        busy = await self.jobs_collection.find_one({
+            "$or": [{"job_id": parent_job_id}, {"parent_job_id": parent_job_id}],
+            "status": {"$in": [BulkJobStatus.PENDING.value, BulkJobStatus.RUNNING.value]},
+        })
+        return busy is not None
+
+    async def set_schedule(self, job_id: str, schedule: Optional[str]):
+        """Attach a schedule to a job (None removes it)."""
+        await self.jobs_collection.update_one(
+            {"job_id": job_id},
+            {"$set": {
+                "schedule": schedule,
+                "next_run_at": next_run_at(schedule) if schedule else None,
+            }}
+        )
+        await cache.invalidate(f"job:{job_id}")
+
+    async def run_due(self):
+        """Start a child job for every scheduled series that is due."""
+        now = datetime.utcnow()
+        due = await self.jobs_collection.find({
+            "schedule": {"$ne": None},
+            "next_run_at": {"$lte": now},
+        }).to_list(length=None)
+
+        for parent in due:
+            parent_id = parent["job_id"]
+            await self.jobs_collection.update_one(
+                {"job_id": parent_id},
+                {"$set": {"next_run_at": next_run_at(parent["schedule"], now)}}
+            )
+
+            if await self._series_busy(parent_id):
+                logger.info(f"Scheduled job {parent_id} still has a run in progress; skipping")
+                continue
+
+            filters = parent.get("filters") or {}
+            podcast = None
+            if parent.get("podcast_id"):
+                podcast = await self.db.podcasts.find_one({"podcast_id": parent["podcast_id"], "deleted_at": None})
+                if not podcast:
+                    logger.info(f"Scheduled job {parent_id}: podcast {parent['podcast_id']} no longer exists")
+                    continue
+
+            try:
+                child = await self.bulk_service.create_job(
+                    rss_url=parent["rss_url"],
+                    max_episodes=parent.get("max_episodes"),
+                    exclude_audio_urls=await self._transcribed_audio_urls(parent_id),
+                    parent_job_id=parent_id,
+                    priority=JobPriority(parent.get("priority", JobPriority.LOW.value)),
+                    published_after=filters.get("published_after"),
+                    published_before=filters.get("published_before"),
+                    title_contains=filters.get("title_contains"),
+                    order=EpisodeOrder(filters.get("order", EpisodeOrder.OLDEST.value)),
+                    podcast=podcast,
+                    model=parent.get("model"),
+                )
+            except ValueError as e:
+                logger.info(f"Scheduled job {parent_id}: {e}")
+                continue
+            except QuotaExceededError as e:
+                logger.warning(f"Scheduled job {parent_id} skipped: {e.message}")
+                continue
+
+            await self.jobs_collection.update_one(
+                {"job_id": parent_id},
+                {"$set": {"last_run_at": now, "last_run_job_id": child["job_id"]}, "$inc": {"run_count": 1}}
+            )
+            logger.info(f"Scheduled job {parent_id} started run {child['job_id']} with {child['total_episodes']} episodes")
+            asyncio.create_task(self.bulk_service.process_job(child["job_id"]))
+
+
+async def run_bulk_schedule_scheduler(get_db):
+    """
+    Start due scheduled bulk jobs, and resume jobs interrupted on other
+    instances, until cancelled. The interval is read each time so
+    BULK_SCHEDULE_POLL_SECONDS can be changed at runtime.
+
+    Args:
+        get_db: Callable returning the database instance
+    """
+    logger.info("Starting bulk transcription schedule runner")
+    while True:
+        await asyncio.sleep(settings.bulk_schedule_poll_seconds)
+        try:
+            await BulkScheduleService(get_db()).run_due()
+        except Exception as e:
+            logger.error(f"Scheduled bulk transcription run failed: {e}")
+        try:
+            await BulkTranscribeService(get_db()).resume_interrupted_jobs()
+        except Exception as e:
+            logger.error(f"Failed to resume interrupted bulk jobs: {e}")
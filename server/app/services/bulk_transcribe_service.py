@@ -1,105 +1,650 @@
 """Service for bulk transcription of podcast episodes."""
 import logging
 import asyncio
-from datetime import datetime
-from typing import Optional, List, Dict, Any
+import hashlib
+import re
+import time
+from datetime import datetime, timedelta
+from typing import Optional, List, Dict, Any, Set, Tuple
 from motor.motor_asyncio import AsyncIOMotorDatabase
+from pymongo.errors import DuplicateKeyError
+from app.cache import cache
+from app.config import settings
 from app.services.rss_parser import parse_rss_feed
 from app.services.whisper_service import whisper_service
-from app.models.schemas import BulkJobStatus, TranscriptStatus
+from app.services.chat_notifier import chat_notifier
+from app.services.ad_detection import AdDetectionService
+from app.services.bulk_checkpoint import JobCheckpoints
+from app.services.download_usage import DownloadCapReached, DownloadUsageService, download_host
+from app.services.private_transcripts import episode_encryption
+from app.services.episode_dedup import find_moved_episode, find_rerun_original, link_moved_episode, link_rerun
+from app.services.storage_locations import episode_storage
+from app.services.transcript_store import store_transcript
+from app.services.cost_service import compute_cost
+from app.services.transcript_progress import transcription_speeds
+from app.services.work_queue import transcription_slots
+from app.services.quota_service import QuotaService, episode_minutes
+from app.models.schemas import BulkJobStatus, EpisodeOrder, JobPriority, TranscriptStatus
+from app.pagination import seek_after
+from app.workspaces import scoped, stamp
 import secrets
+import uuid
 
 logger = logging.getLogger(__name__)
 
+# How often estimated progress of a running transcription is stored
+PROGRESS_INTERVAL_SECONDS = 15
+
+# How often a job paused on an audio host's daily download cap checks it again
+DOWNLOAD_CAP_POLL_SECONDS = 60
+
+# Skip reasons of episodes outside the duration gates (as in the poll Lambda)
+SKIP_TOO_SHORT = "too_short"
+SKIP_TOO_LONG = "too_long"
+
+
+def duration_skip_reason(duration_minutes: Optional[int]) -> Optional[str]:
+    """
+    Why an episode is outside TRANSCRIBE_MIN/MAX_DURATION_MINUTES, if it is.
+
+    Episodes without a feed duration are never skipped.
+    """
+    if duration_minutes is None:
+        return None
+    if settings.transcribe_min_duration_minutes and duration_minutes < settings.transcribe_min_duration_minutes:
+        return SKIP_TOO_SHORT
+    if settings.transcribe_max_duration_minutes and duration_minutes > settings.transcribe_max_duration_minutes:
+        return SKIP_TOO_LONG
+    return None
+
+
+class JobCancelled(Exception):
+    """Raised inside process_job when the job is cancelled mid-episode."""
+
+    def __init__(self):
+        super().__init__("Cancelled")
+
+
+def sort_by_published_date(episodes: List[Dict[str, Any]], order: EpisodeOrder) -> List[Dict[str, Any]]:
+    """
+    Order feed episodes by publication date.
+
+    Undated episodes count as the oldest, matching how Mongo sorts null dates
+    in episode listings. The sort is stable, so ties keep feed order.
+    """
+    return sorted(
+        episodes,
+        key=lambda e: (e.get("published_date") is not None, e.get("published_date") or datetime.min),
+        reverse=order == EpisodeOrder.NEWEST
+    )
+
+
+def episode_id_for(audio_url: str) -> str:
+    """Episode ID for an audio URL; matches the poll Lambda's generateEpisodeID so both paths dedupe."""
+    return hashlib.sha256(audio_url.encode("utf-8")).hexdigest()
+
 
 class BulkTranscribeService:
-    """Service for managing bulk transcription jobs."""
+    """
+    Service for managing bulk transcription jobs.
+
+    Job documents hold the summary counters; per-episode progress lives in
+    the job_episodes collection, one document per feed item keyed by
+    (job_id, index), so large backfills stay well under Mongo's document
+    size limit and progress can be paged.
+    """
+
+    # Shared across instances: the cancel endpoint uses a different instance
+    # than the background task processing the job
+    running_jobs: Dict[str, bool] = {}  # job_id -> still wanted
+    active_transcriptions: Dict[str, asyncio.Task] = {}  # job_id -> in-flight Whisper call
+    leases: Dict[str, str] = {}  # job_id -> checkpoint token held by this process
+    # Jobs this process stopped without finishing (shutdown or lost lease);
+    # they are left for another instance to resume
+    detached: Set[str] = set()
 
     def __init__(self, db: AsyncIOMotorDatabase):
         self.db = db
         self.jobs_collection = db.bulk_transcribe_jobs
+        self.job_episodes = db.job_episodes
         self.episodes_collection = db.episodes
-        self.running_jobs: Dict[str, bool] = {}  # Track running jobs
+        self.checkpoints = JobCheckpoints(db)
 
-    async def create_job(self, rss_url: str, max_episodes: Optional[int] = None, dry_run: bool = False) -> Dict[str, Any]:
+    async def _select_episodes(
+        self,
+        rss_url: str,
+        max_episodes: Optional[int],
+        exclude_audio_urls: Optional[Set[str]],
+        published_after: Optional[datetime],
+        published_before: Optional[datetime],
+        title_contains: Optional[str],
+        order: EpisodeOrder,
+    ) -> Tuple[Dict[str, Any], List[Dict[str, Any]], Dict[str, str], Dict[str, str]]:
         """
-        Create a new bulk transcription job.
+        Parse the feed and pick the episodes a job would cover.
+
+        Returns:
+            Podcast metadata, the selected episodes in processing order, a
+            map of audio URL to episode ID for those already transcribed, and
+            a map of audio URL to skip reason for those outside the duration
+            gates
+        """
+        # Parse RSS feed to get episodes
+        podcast_data, episodes = await parse_rss_feed(rss_url)
+
+        if not episodes:
+            raise ValueError("No episodes found in RSS feed")
+
+        episodes = self._filter_episodes(episodes, published_after, published_before, title_contains)
+        if not episodes:
+            raise ValueError("No episodes match the requested filters")
+
+        if exclude_audio_urls:
+            episodes = [ep for ep in episodes if ep.get("audio_url") not in exclude_audio_urls]
+            if not episodes:
+                raise ValueError("No new episodes to transcribe")
+
+        episodes = sort_by_published_date(episodes, order)
+
+        # Limit episodes if specified
+        if max_episodes and max_episodes > 0:
+            episodes = episodes[:max_episodes]
+
+        transcribed = await self._transcribed_episode_ids(episodes)
+        gated = {}
+        for ep in episodes:
+            reason = duration_skip_reason(ep.get("duration_minutes"))
+            if reason and ep.get("audio_url") not in transcribed:
+                gated[ep.get("audio_url")] = reason
+        return podcast_data, episodes, transcribed, gated
+
+    async def preview_job(
+        self,
+        rss_url: str,
+        max_episodes: Optional[int] = None,
+        published_after: Optional[datetime] = None,
+        published_before: Optional[datetime] = None,
+        title_contains: Optional[str] = None,
+        order: EpisodeOrder = EpisodeOrder.OLDEST,
+        podcast: Optional[Dict[str, Any]] = None,
+    ) -> Dict[str, Any]:
+        """
+        Describe the job a request would create without creating it.
+
+        Args are the same as create_job.
+
+        Returns:
+            Selected episodes with estimated audio hours and cost
+        """
+        logger.info(f"Previewing bulk transcribe job for: {rss_url}")
+        podcast_data, episodes, transcribed, gated = await self._select_episodes(
+            rss_url, max_episodes, None, published_after, published_before, title_contains, order
+        )
+
+        linked: Dict[str, str] = {}
+        if podcast:
+            linked = await self._podcast_episode_ids(podcast["podcast_id"], episodes)
+            await self._match_moved_episodes(podcast["podcast_id"], episodes, linked, transcribed, dry_run=True)
+            await self._match_reruns(podcast["podcast_id"], episodes, linked, transcribed, gated, dry_run=True)
+
+        to_transcribe = [
+            ep for ep in episodes
+            if ep.get("audio_url") not in transcribed and ep.get("audio_url") not in gated
+        ]
+        estimated_minutes = sum(ep.get("duration_minutes") or 0 for ep in to_transcribe)
+
+        return {
+            "rss_url": rss_url,
+            "podcast_id": podcast["podcast_id"] if podcast else None,
+            "podcast_title": (podcast or podcast_data).get("title", "Unknown"),
+            "total_episodes": len(episodes),
+            "skipped_episodes": len(transcribed) + len(gated),
+            "episodes_without_duration": sum(1 for ep in to_transcribe if not ep.get("duration_minutes")),
+            "estimated_audio_hours": round(estimated_minutes / 60, 2),
+            "estimated_cost": compute_cost(estimated_minutes),
+            "episodes": [
+                {
+                    "episode_id": transcribed.get(ep.get("audio_url")) or linked.get(ep.get("audio_url")),
+                    "title": ep.get("title", "Unknown"),
+                    "audio_url": ep.get("audio_url"),
+                    "published_date": ep.get("published_date"),
+                    "duration_minutes": ep.get("duration_minutes"),
+                    "status": (
+                        TranscriptStatus.SKIPPED.value
+                        if ep.get("audio_url") in transcribed or ep.get("audio_url") in gated
+                        else TranscriptStatus.PENDING.value
+                    ),
+                    "skip_reason": gated.get(ep.get("audio_url")),
+                }
+                for ep in episodes
+            ],
+        }
+
+    async def create_job(
+        self,
+        rss_url: str,
+        max_episodes: Optional[int] = None,
+        exclude_audio_urls: Optional[Set[str]] = None,
+        parent_job_id: Optional[str] = None,
+        priority: JobPriority = JobPriority.LOW,
+        published_after: Optional[datetime] = None,
+        published_before: Optional[datetime] = None,
+        title_contains: Optional[str] = None,
+        order: EpisodeOrder = EpisodeOrder.OLDEST,
+        podcast: Optional[Dict[str, Any]] = None,
+        workspace_id: Optional[str] = None,
+        model: Optional[str] = None,
+        api_key: Optional[str] = None,
+    ) -> Dict[str, Any]:
+        """
+        Create a new bulk transcription job, reserving its minutes against
+        the transcription quota before anything is written.
 
         Args:
             rss_url: RSS feed URL to process
             max_episodes: Maximum number of episodes to process (None = all)
-            dry_run: If True, only process 1 episode for testing
+            exclude_audio_urls: Episodes to skip (already transcribed by a scheduled series)
+            parent_job_id: Scheduled job this run belongs to
+            priority: Queue priority for this job's episodes
+            published_after: Only include episodes published on or after this date
+            published_before: Only include episodes published before this date
+            title_contains: Case-insensitive regex the episode title must match
+            order: Process oldest or newest episodes first
+            podcast: Subscribed podcast the feed belongs to; its episode
+                documents receive the transcripts
+            workspace_id: Workspace of the podcast record created for an
+                unsubscribed feed; the job belongs to its podcast's workspace
+            model: Whisper model to request (None = WHISPER_MODEL)
+            api_key: Caller's API key, charged along with the global quota
 
         Returns:
             Job document
+
+        Raises:
+            QuotaExceededError: If the job's minutes exceed the remaining quota
         """
+        quota = QuotaService(self.db)
+        reserved = 0
         try:
-            logger.info(f"Creating bulk transcribe job for: {rss_url} (dry_run={dry_run})")
-
-            # Parse RSS feed to get episodes
-            podcast_data, episodes = await parse_rss_feed(rss_url)
+            logger.info(f"Creating bulk transcribe job for: {rss_url}")
 
-            if not episodes:
-                raise ValueError("No episodes found in RSS feed")
+            podcast_data, episodes, transcribed, gated = await self._select_episodes(
+                rss_url, max_episodes, exclude_audio_urls,
+                published_after, published_before, title_contains, order
+            )
+            # Linking moved episodes and reruns below only skips more episodes;
+            # the minutes that frees are released once the job is built
+            minutes = self._quota_minutes(episodes, set(transcribed) | set(gated))
+            if minutes:
+                await quota.reserve(minutes, api_key)
+                reserved = minutes
 
-            # Sort episodes by published date (oldest first for chronological processing)
-            episodes.sort(key=lambda e: e.get('published_date', datetime.min))
+            if not podcast:
+                podcast = await self.resolve_podcast(rss_url, podcast_data, workspace_id)
+            linked = await self._podcast_episode_ids(podcast["podcast_id"], episodes)
+            await self._match_moved_episodes(podcast["podcast_id"], episodes, linked, transcribed)
+            await self._match_reruns(podcast["podcast_id"], episodes, linked, transcribed, gated)
+            if len(transcribed) == len(episodes):
+                raise ValueError("All episodes already have completed transcripts")
+            if len(transcribed) + len(gated) == len(episodes):
+                raise ValueError("All episodes are transcribed already or outside the duration limits")
+            skipped = set(transcribed) | set(gated)
 
-            # Dry run mode: only process 1 episode
-            if dry_run:
-                logger.info("Dry run mode enabled - limiting to 1 episode")
-                episodes = episodes[:1]
-            # Limit episodes if specified
-            elif max_episodes and max_episodes > 0:
-                episodes = episodes[:max_episodes]
+            # Estimate from feed durations; episodes without one aren't priced
+            estimated_minutes = sum(
+                ep.get("duration_minutes") or 0 for ep in episodes
+                if ep.get("audio_url") not in skipped
+            )
 
             # Create job document
             job_id = f"job_{secrets.token_urlsafe(16)}"
             job = {
                 "job_id": job_id,
                 "rss_url": rss_url,
-                "podcast_title": podcast_data.get("title", "Unknown"),
+                "podcast_id": podcast["podcast_id"],
+                "podcast_title": podcast.get("title", "Unknown"),
                 "status": BulkJobStatus.PENDING.value,
                 "total_episodes": len(episodes),
                 "processed_episodes": 0,
                 "successful_episodes": 0,
                 "failed_episodes": 0,
+                "skipped_episodes": len(skipped),
                 "created_at": datetime.utcnow(),
                 "updated_at": datetime.utcnow(),
                 "completed_at": None,
                 "current_episode": None,
-                "episodes": [
-                    {
-                        "episode_id": None,  # Will be set when created
-                        "title": ep.get("title", "Unknown"),
-                        "audio_url": ep.get("audio_url"),
-                        "status": TranscriptStatus.PENDING.value,
-                        "error_message": None,
-                        "started_at": None,
-                        "completed_at": None,
-                    }
-                    for ep in episodes
-                ]
+                "max_episodes": max_episodes,
+                "priority": priority.value,
+                "model": model,
+                "filters": {
+                    "published_after": published_after,
+                    "published_before": published_before,
+                    "title_contains": title_contains,
+                    "order": order.value,
+                },
+                "parent_job_id": parent_job_id,
+                "schedule": None,
+                "next_run_at": None,
+                "estimated_cost": compute_cost(estimated_minutes),
+                "quota_minutes": self._quota_minutes(episodes, skipped),
             }
+            stamp(job, podcast.get("workspace_id"))
+            job_episodes = [
+                {
+                    "job_id": job_id,
+                    "index": idx,
+                    # Set up front for episodes the podcast already has
+                    "episode_id": transcribed.get(ep.get("audio_url")) or linked.get(ep.get("audio_url")),
+                    "title": ep.get("title", "Unknown"),
+                    "audio_url": ep.get("audio_url"),
+                    "published_date": ep.get("published_date"),
+                    "duration_minutes": ep.get("duration_minutes"),
+                    "persons": ep.get("persons") or [],
+                    "status": (
+                        TranscriptStatus.SKIPPED.value if ep.get("audio_url") in skipped
+                        else TranscriptStatus.PENDING.value
+                    ),
+                    "skip_reason": gated.get(ep.get("audio_url")),
+                    "error_message": None,
+                    "started_at": None,
+                    "completed_at": None,
+                }
+                for idx, ep in enumerate(episodes)
+            ]
+
+            if reserved > job["quota_minutes"]:
+                await quota.release(reserved - job["quota_minutes"], api_key)
+                reserved = job["quota_minutes"]
 
             # Insert job
             await self.jobs_collection.insert_one(job)
-            logger.info(f"Created job {job_id} with {len(episodes)} episodes")
+            await self.job_episodes.insert_many(job_episodes)
+            logger.info(
+                f"Created job {job_id} with {len(episodes)} episodes "
+                f"({len(transcribed)} already transcribed, {len(gated)} outside the duration limits)"
+            )
 
             return job
 
         except Exception as e:
             logger.error(f"Error creating bulk transcribe job: {e}")
+            if reserved:
+                await quota.release(reserved, api_key)
             raise
 
+    @staticmethod
+    def _quota_minutes(episodes: List[Dict[str, Any]], skipped: Set[str]) -> int:
+        """Minutes charged against the quota for a job's episodes (feed duration or the default)."""
+        return sum(episode_minutes(ep) for ep in episodes if ep.get("audio_url") not in skipped)
+
+    @staticmethod
+    def _filter_episodes(
+        episodes: List[Dict[str, Any]],
+        published_after: Optional[datetime],
+        published_before: Optional[datetime],
+        title_contains: Optional[str],
+    ) -> List[Dict[str, Any]]:
+        """Apply the request's date range and title filters to feed episodes."""
+        pattern = re.compile(title_contains, re.IGNORECASE) if title_contains else None
+        filtered = []
+        for ep in episodes:
+            published = ep.get("published_date")
+            if (published_after or published_before) and not published:
+                continue
+            if published_after and published < published_after:
+                continue
+            if published_before and published >= published_before:
+                continue
+            if pattern and not pattern.search(ep.get("title") or ""):
+                continue
+            filtered.append(ep)
+        return filtered
+
+    async def _transcribed_episode_ids(self, episodes: List[Dict[str, Any]]) -> Dict[str, str]:
+        """Map audio URL to episode ID for feed items that already have a completed transcript."""
+        audio_urls = [ep["audio_url"] for ep in episodes if ep.get("audio_url")]
+        if not audio_urls:
+            return {}
+
+        cursor = self.episodes_collection.find(
+            {
+                "audio_url": {"$in": audio_urls},
+                "transcript_status": TranscriptStatus.COMPLETED.value,
+                "deleted_at": None,
+            },
+            {"audio_url": 1, "episode_id": 1}
+        )
+        return {doc["audio_url"]: doc["episode_id"] async for doc in cursor}
+
+    async def resolve_podcast(
+        self,
+        rss_url: str,
+        podcast_data: Dict[str, Any],
+        workspace_id: Optional[str] = None
+    ) -> Dict[str, Any]:
+        """
+        Podcast document for a feed, so the job's episodes have a parent.
+
+        Feeds nobody subscribed to get an inactive podcast record; subscribing
+        later reactivates it and keeps the transcribed episodes.
+        """
+        podcast = await self.db.podcasts.find_one({"rss_url": rss_url})
+        if podcast:
+            return podcast
+
+        podcast = {
+            "podcast_id": f"pod_{uuid.uuid4().hex[:12]}",
+            "rss_url": rss_url,
+            "title": podcast_data.get("title", "Unknown"),
+            "description": podcast_data.get("description"),
+            "image_url": podcast_data.get("image_url"),
+            "author": podcast_data.get("author"),
+            "categories": podcast_data.get("categories") or [],
+            "explicit": podcast_data.get("explicit"),
+            "subscribed_at": datetime.utcnow(),
+            "active": False,
+            "episode_count": 0,
+        }
+        stamp(podcast, workspace_id)
+        try:
+            await self.db.podcasts.insert_one(podcast)
+            logger.info(f"Created inactive podcast {podcast['podcast_id']} for bulk feed {rss_url}")
+        except DuplicateKeyError:
+            # Subscribed concurrently
+            podcast = await self.db.podcasts.find_one({"rss_url": rss_url})
+        return podcast
+
+    async def _upsert_episode(self, podcast_id: str, episode_data: Dict[str, Any]) -> str:
+        """Create (or claim) the episode document for a job entry and mark it processing."""
+        # Entries linked at creation keep their episode, even if its audio moved hosts
+        episode_id = episode_data.get("episode_id") or episode_id_for(episode_data["audio_url"])
+        now = datetime.utcnow()
+        await self.episodes_collection.update_one(
+            {"episode_id": episode_id},
+            {
+                "$setOnInsert": {
+                    "_id": episode_id,
+                    "podcast_id": podcast_id,
+                    "title": episode_data.get("title"),
+                    "audio_url": episode_data["audio_url"],
+                    "published_date": episode_data.get("published_date"),
+                    "duration_minutes": episode_data.get("duration_minutes"),
+                    "episode_type": episode_data.get("episode_type"),
+                    "explicit": episode_data.get("explicit"),
+                    "persons": episode_data.get("persons") or [],
+                    "created_at": now,
+                },
+                "$set": {
+                    "transcript_status": TranscriptStatus.PROCESSING.value,
+                    "processing_step": "transcribing",
+                    "updated_at": now,
+                },
+            },
+            upsert=True
+        )
+        await cache.invalidate("episodes")
+        return episode_id
+
+    async def _podcast_episode_ids(self, podcast_id: str, episodes: List[Dict[str, Any]]) -> Dict[str, str]:
+        """Map audio URL to episode ID for feed items the podcast already has episode documents for."""
+        audio_urls = [ep["audio_url"] for ep in episodes if ep.get("audio_url")]
+        cursor = self.episodes_collection.find(
+            {"podcast_id": podcast_id, "audio_url": {"$in": audio_urls}, "deleted_at": None},
+            {"audio_url": 1, "episode_id": 1}
+        )
+        return {doc["audio_url"]: doc["episode_id"] async for doc in cursor}
+
+    async def _match_moved_episodes(
+        self,
+        podcast_id: str,
+        episodes: List[Dict[str, Any]],
+        linked: Dict[str, str],
+        transcribed: Dict[str, str],
+        dry_run: bool = False,
+    ):
+        """
+        Link feed items that re-list an existing episode under a new audio URL.
+
+        Adds them to linked (and to transcribed if the episode is complete) and,
+        unless dry_run, points the episode at the new audio URL.
+        """
+        for ep in episodes:
+            audio_url = ep.get("audio_url")
+            if not audio_url or audio_url in linked:
+                continue
+            existing = await find_moved_episode(self.episodes_collection, podcast_id, ep)
+            if not existing:
+                continue
+            if not dry_run:
+                await link_moved_episode(self.episodes_collection, existing, audio_url)
+            linked[audio_url] = existing["episode_id"]
+            if existing.get("transcript_status") == TranscriptStatus.COMPLETED.value:
+                transcribed[audio_url] = existing["episode_id"]
+
+    async def _match_reruns(
+        self,
+        podcast_id: str,
+        episodes: List[Dict[str, Any]],
+        linked: Dict[str, str],
+        transcribed: Dict[str, str],
+        gated: Dict[str, str],
+        dry_run: bool = False,
+    ):
+        """
+        Link feed items that republish a transcribed episode (replays).
+
+        Adds them to linked and transcribed and, unless dry_run, creates their
+        episode documents sharing the original's transcript.
+        """
+        for ep in episodes:
+            audio_url = ep.get("audio_url")
+            if not audio_url or audio_url in linked:
+                continue
+            original = await find_rerun_original(self.episodes_collection, podcast_id, ep)
+            if not original:
+                continue
+            episode_id = episode_id_for(audio_url)
+            if not dry_run:
+                await link_rerun(self.episodes_collection, podcast_id, episode_id, ep, original)
+            linked[audio_url] = episode_id
+            transcribed[audio_url] = episode_id
+            gated.pop(audio_url, None)
+        if not dry_run:
+            await cache.invalidate("episodes")
+
+    async def _store_episode_transcript(self, episode_id: str, transcript: str, cost: Dict[str, float]):
+        """Attach a bulk-job transcript to its episode document so the episode API serves it."""
+        storage = await episode_storage(self.db, episode_id)
+        encryption = await episode_encryption(self.db, episode_id)
+        stored = await store_transcript(transcript, storage, encryption)
+        if not stored:
+            raise Exception("Failed to store transcript in S3")
+        transcript_s3_key, transcript_sha256 = stored
+
+        now = datetime.utcnow()
+        await self.episodes_collection.update_one(
+            {"episode_id": episode_id},
+            {
+                "$set": {
+                    "transcript_status": TranscriptStatus.COMPLETED.value,
+                    "processing_step": "completed",
+                    "transcript_s3_key": transcript_s3_key,
+                    "transcript_sha256": transcript_sha256,
+                    "storage": storage,
+                    "total_words": len(transcript.split()),
+                    "error_message": None,
+                    "cost.actual": cost,
+                    "cost.recorded_at": now,
+                    "processed_at": now,
+                    "updated_at": now,
+                },
+                "$unset": {"transcript_progress": ""}
+            }
+        )
+        await cache.invalidate("episodes")
+
     async def get_job(self, job_id: str) -> Optional[Dict[str, Any]]:
         """Get job by ID."""
         return await self.jobs_collection.find_one({"job_id": job_id})
 
-    async def list_jobs(self, limit: int = 50) -> List[Dict[str, Any]]:
-        """List all jobs, most recent first."""
-        cursor = self.jobs_collection.find().sort("created_at", -1).limit(limit)
-        return await cursor.to_list(length=limit)
+    async def list_job_episodes(
+        self,
+        job_id: str,
+        limit: int = 100,
+        after: Optional[int] = None,
+        status: Optional[TranscriptStatus] = None,
+    ) -> List[Dict[str, Any]]:
+        """
+        Page through a job's episode progress in processing order.
+
+        Args:
+            job_id: Job to read
+            limit: Maximum number of entries to return
+            after: Only return entries after this index
+            status: Only return entries with this status
+        """
+        query: Dict[str, Any] = {"job_id": job_id}
+        if after is not None:
+            query["index"] = {"$gt": after}
+        if status:
+            query["status"] = status.value
+        results = self.job_episodes.find(query).sort("index", 1).limit(limit)
+        return await results.to_list(length=limit)
+
+    async def migrate_embedded_episodes(self) -> int:
+        """Move episode progress still embedded in job documents into job_episodes."""
+        migrated = 0
+        async for job in self.jobs_collection.find({"episodes": {"$exists": True}}, {"job_id": 1, "episodes": 1}):
+            entries = [
+                {**ep, "job_id": job["job_id"], "index": idx}
+                for idx, ep in enumerate(job["episodes"])
+            ]
+            await self.job_episodes.delete_many({"job_id": job["job_id"]})
+            if entries:
+                await self.job_episodes.insert_many(entries)
+            await self.jobs_collection.update_one({"_id": job["_id"]}, {"$unset": {"episodes": ""}})
+            migrated += 1
+        if migrated:
+            logger.info(f"Moved episode progress of {migrated} bulk jobs to job_episodes")
+        return migrated
+
+    async def list_jobs(
+        self,
+        limit: int = 50,
+        cursor: Optional[str] = None,
+        workspace_id: Optional[str] = None
+    ) -> List[Dict[str, Any]]:
+        """
+        List jobs, most recent first.
+
+        Args:
+            limit: Maximum number of jobs to return
+            cursor: Opaque cursor; only jobs after this position are returned
+            workspace_id: Only jobs of this workspace (None = all)
+        """
+        query = scoped(seek_after("created_at", cursor) if cursor else {}, workspace_id)
+        results = self.jobs_collection.find(query).sort([("created_at", -1), ("_id", -1)]).limit(limit)
+        return await results.to_list(length=limit)
 
     async def update_job(self, job_id: str, updates: Dict[str, Any]) -> bool:
         """Update job fields."""
@@ -108,6 +653,7 @@ class BulkTranscribeService:
             {"job_id": job_id},
             {"$set": updates}
         )
+        await cache.invalidate(f"job:{job_id}")
         return result.modified_count > 0
 
     async def update_episode_in_job(
@@ -117,78 +663,177 @@ class BulkTranscribeService:
         updates: Dict[str, Any]
     ) -> bool:
         """Update specific episode in job."""
-        update_fields = {
-            f"episodes.{episode_index}.{key}": value
-            for key, value in updates.items()
-        }
-        update_fields["updated_at"] = datetime.utcnow()
-
-        result = await self.jobs_collection.update_one(
+        result = await self.job_episodes.update_one(
+            {"job_id": job_id, "index": episode_index},
+            {"$set": updates}
+        )
+        # Touch the job so its ETag changes with episode progress
+        await self.jobs_collection.update_one(
             {"job_id": job_id},
-            {"$set": update_fields}
+            {"$set": {"updated_at": datetime.utcnow()}}
         )
+        await cache.invalidate(f"job:{job_id}")
         return result.modified_count > 0
 
-    async def process_job(self, job_id: str):
+    async def process_job(self, job_id: str, token: Optional[str] = None):
         """
         Process a bulk transcription job.
         This runs as a background task and processes episodes one at a time.
+
+        Args:
+            job_id: Job to process
+            token: Checkpoint token of a lease already claimed (when resuming)
         """
+        resuming = token is not None
+        if not resuming:
+            token = await self.checkpoints.claim(job_id)
+            if not token:
+                logger.info(f"Job {job_id} is already being processed")
+                return
+        self.leases[job_id] = token
+        keep_lease = asyncio.create_task(self._keep_lease(job_id, token))
         try:
-            logger.info(f"Starting to process job {job_id}")
+            logger.info(f"{'Resuming' if resuming else 'Starting'} to process job {job_id}")
 
-            # Mark job as running
+            # Mark job as running (a resumed job keeps its original start)
             self.running_jobs[job_id] = True
-            await self.update_job(job_id, {"status": BulkJobStatus.RUNNING.value})
+            # The ETA is measured from this run's start and entries
+            started_at = datetime.utcnow()
+            updates: Dict[str, Any] = {"status": BulkJobStatus.RUNNING.value}
+            if not resuming:
+                updates["started_at"] = started_at
+            await self.update_job(job_id, updates)
 
             # Get job
             job = await self.get_job(job_id)
             if not job:
                 logger.error(f"Job {job_id} not found")
                 return
+            if job.get("cancel_requested_at"):
+                # Cancelled while no instance was processing it
+                raise JobCancelled()
+
+            if not await self._wait_for_whisper(job_id, job.get("model")):
+                if job_id in self.detached:
+                    return
+                logger.info(f"Job {job_id} was cancelled")
+                await self.update_job(job_id, {
+                    "status": BulkJobStatus.CANCELLED.value,
+                    "estimated_completion_at": None
+                })
+                return
+
+            total = job["total_episodes"]
+            priority = JobPriority(job.get("priority", JobPriority.LOW.value))
+            to_process = await self.job_episodes.count_documents(
+                {"job_id": job_id, "status": TranscriptStatus.PENDING.value}
+            )
+            attempted = 0
 
-            episodes = job.get("episodes", [])
+            while True:
+                # Fetch one entry at a time; a cursor held open across hours of
+                # transcription would time out on the server
+                episode_data = await self.job_episodes.find_one(
+                    {"job_id": job_id, "status": TranscriptStatus.PENDING.value},
+                    sort=[("index", 1)]
+                )
+                if not episode_data:
+                    break
+                idx = episode_data["index"]
 
-            for idx, episode_data in enumerate(episodes):
                 # Check if job was cancelled
+                if job_id in self.detached:
+                    return
                 if not self.running_jobs.get(job_id, False):
                     logger.info(f"Job {job_id} was cancelled")
-                    await self.update_job(job_id, {"status": BulkJobStatus.CANCELLED.value})
+                    await self.update_job(job_id, {
+                        "status": BulkJobStatus.CANCELLED.value,
+                        "estimated_completion_at": None
+                    })
                     return
 
+                episode_id = None
                 try:
                     # Update current episode
                     await self.update_job(job_id, {
                         "current_episode": episode_data.get("title")
                     })
 
+                    # Transcribe using Whisper
+                    audio_url = episode_data.get("audio_url")
+                    if not audio_url:
+                        raise ValueError("No audio URL found for episode")
+
+                    # Wait out the audio host's daily download cap rather than fail the rest of the backfill
+                    if not await self._wait_for_download_budget(job_id, audio_url):
+                        raise JobCancelled()
+
+                    # Link the entry to a real episode so the transcript is served by the episode API
+                    # (jobs created before podcast linkage have no podcast_id)
+                    if job.get("podcast_id"):
+                        episode_id = await self._upsert_episode(job["podcast_id"], episode_data)
+                        episode_data["episode_id"] = episode_id
+
                     # Update episode status to processing
                     await self.update_episode_in_job(job_id, idx, {
+                        "episode_id": episode_id,
                         "status": TranscriptStatus.PROCESSING.value,
                         "started_at": datetime.utcnow()
                     })
+                    await self._save_checkpoint(job_id, token, idx, [{"index": idx, "episode_id": episode_id}])
 
-                    logger.info(f"Processing episode {idx + 1}/{len(episodes)}: {episode_data.get('title')}")
+                    logger.info(f"Processing episode {idx + 1}/{total}: {episode_data.get('title')}")
 
-                    # Transcribe using Whisper
-                    audio_url = episode_data.get("audio_url")
-                    if not audio_url:
-                        raise ValueError("No audio URL found for episode")
-
-                    transcript = await whisper_service.transcribe_audio_url(audio_url)
+                    # Run as a task so cancel_job can abort it, whether it is
+                    # still queued for a slot or already transcribing
+                    transcription = asyncio.create_task(self._transcribe_entry(
+                        job, idx, episode_id, audio_url, episode_data.get("duration_minutes"), priority
+                    ))
+                    self.active_transcriptions[job_id] = transcription
+                    timeout = settings.bulk_episode_timeout_seconds
+                    try:
+                        transcript, elapsed = await asyncio.wait_for(transcription, timeout or None)
+                    except asyncio.CancelledError:
+                        if asyncio.current_task().cancelling():
+                            raise  # This task itself is being cancelled
+                        raise JobCancelled()
+                    except TimeoutError:
+                        raise TimeoutError(f"Episode wasn't transcribed within {timeout} seconds")
+                    finally:
+                        self.active_transcriptions.pop(job_id, None)
 
                     if transcript:
+                        transcription_speeds.record(
+                            job.get("model"), (episode_data.get("duration_minutes") or 0) * 60, elapsed
+                        )
+                        cost = compute_cost(
+                            episode_data.get("duration_minutes") or 0,
+                            elapsed,
+                            len(transcript.encode("utf-8"))
+                        )
+
+                        if episode_id:
+                            await self._store_episode_transcript(episode_id, transcript, cost)
+                            await AdDetectionService(self.db).analyze_completed(episode_id, transcript)
+
                         # Success - update episode and job with transcript
                         await self.update_episode_in_job(job_id, idx, {
                             "status": TranscriptStatus.COMPLETED.value,
+                            "transcript_progress": None,
                             "transcript": transcript,
+                            "cost": cost,
                             "completed_at": datetime.utcnow()
                         })
+                        # Counted in place: the job document read at the start is stale by now
+                        await self.jobs_collection.update_one(
+                            {"job_id": job_id},
+                            {"$inc": {
+                                "successful_episodes": 1,
+                                **{f"actual_cost.{key}": value for key, value in cost.items()}
+                            }}
+                        )
 
-                        await self.update_job(job_id, {
-                            "processed_episodes": idx + 1,
-                            "successful_episodes": job.get("successful_episodes", 0) + 1
-                        })
+                        await self.update_job(job_id, {"processed_episodes": idx + 1})
 
                         logger.info(f"Successfully transcribed episode {idx + 1} - {len(transcript)} characters")
 
@@ -196,22 +841,51 @@ class BulkTranscribeService:
                         # Failed - update episode and job
                         raise Exception("Transcription returned empty result")
 
+                except JobCancelled:
+                    if job_id not in self.detached:
+                        await self._requeue_entry(job_id, idx, episode_id)
+                    raise
+
                 except Exception as e:
+                    if job_id in self.detached:
+                        # Requeued for the instance that resumes the job
+                        return
                     logger.error(f"Error processing episode {idx + 1}: {e}")
 
+                    if episode_data.get("episode_id"):
+                        await self.episodes_collection.update_one(
+                            {"episode_id": episode_data["episode_id"]},
+                            {
+                                "$set": {
+                                    "transcript_status": TranscriptStatus.FAILED.value,
+                                    "processing_step": None,
+                                    "error_message": str(e),
+                                    "updated_at": datetime.utcnow(),
+                                },
+                                "$unset": {"transcript_progress": ""}
+                            }
+                        )
+
                     await self.update_episode_in_job(job_id, idx, {
                         "status": TranscriptStatus.FAILED.value,
+                        "transcript_progress": None,
                         "error_message": str(e),
                         "completed_at": datetime.utcnow()
                     })
 
-                    await self.update_job(job_id, {
-                        "processed_episodes": idx + 1,
-                        "failed_episodes": job.get("failed_episodes", 0) + 1
-                    })
+                    await self.jobs_collection.update_one(
+                        {"job_id": job_id},
+                        {"$inc": {"failed_episodes": 1}}
+                    )
+                    await self.update_job(job_id, {"processed_episodes": idx + 1})
+
+                await self._save_checkpoint(job_id, token, idx + 1, [])
 
-                # Small delay between episodes to avoid overwhelming the system
-                await asyncio.sleep(2)
+                # Pause between episodes to avoid overwhelming the publisher's CDN
+                await asyncio.sleep(settings.bulk_episode_delay_seconds)
+
+                attempted += 1
+                await self.update_job(job_id, self._progress_fields(started_at, attempted, to_process))
 
             # Mark job as completed
             job = await self.get_job(job_id)  # Refresh job data
@@ -219,8 +893,10 @@ class BulkTranscribeService:
 
             await self.update_job(job_id, {
                 "status": final_status,
+                "processed_episodes": total,
                 "current_episode": None,
-                "completed_at": datetime.utcnow()
+                "completed_at": datetime.utcnow(),
+                "estimated_completion_at": None
             })
 
             logger.info(
@@ -229,21 +905,321 @@ class BulkTranscribeService:
                 f"Failed: {job.get('failed_episodes', 0)}"
             )
 
+            await chat_notifier.notify_bulk_job(await self.get_job(job_id))
+
+        except JobCancelled:
+            if job_id in self.detached:
+                return
+            logger.info(f"Job {job_id} was cancelled")
+            await self.update_job(job_id, {
+                "status": BulkJobStatus.CANCELLED.value,
+                "current_episode": None,
+                "estimated_completion_at": None
+            })
         except Exception as e:
+            if job_id in self.detached:
+                return
             logger.error(f"Error processing job {job_id}: {e}")
             await self.update_job(job_id, {
                 "status": BulkJobStatus.FAILED.value,
-                "current_episode": None
+                "current_episode": None,
+                "estimated_completion_at": None
             })
+
+            job = await self.get_job(job_id)
+            if job:
+                await chat_notifier.notify_bulk_job(job, error_message=str(e))
         finally:
+            keep_lease.cancel()
             # Clean up running jobs tracker
             if job_id in self.running_jobs:
                 del self.running_jobs[job_id]
+            self.leases.pop(job_id, None)
+            if job_id in self.detached:
+                self.detached.discard(job_id)
+            else:
+                try:
+                    await self.checkpoints.release(job_id, token)
+                except Exception as e:
+                    logger.warning(f"Failed to release job {job_id}: {e}")
+
+    async def _requeue_entry(self, job_id: str, idx: int, episode_id: Optional[str]):
+        """
+        Return an entry whose transcription was cancelled to pending, with
+        the episode this run marked processing (None if it got that far).
+        """
+        await self.update_episode_in_job(job_id, idx, {
+            "status": TranscriptStatus.PENDING.value,
+            "started_at": None,
+            "transcript_progress": None
+        })
+        if episode_id:
+            await self.episodes_collection.update_one(
+                {"episode_id": episode_id},
+                {
+                    "$set": {
+                        "transcript_status": TranscriptStatus.PENDING.value,
+                        "processing_step": None,
+                        "updated_at": datetime.utcnow(),
+                    },
+                    "$unset": {"transcript_progress": ""}
+                }
+            )
+
+    async def _wait_for_download_budget(self, job_id: str, audio_url: str) -> bool:
+        """
+        Wait until the audio host is within its daily download cap (see
+        download_usage); the job reports download_paused_until meanwhile.
+
+        Returns:
+            False if the job was cancelled while waiting
+        """
+        usage = DownloadUsageService(self.db)
+        host = download_host(audio_url)
+        paused = False
+        while self.running_jobs.get(job_id, False):
+            try:
+                await usage.check(host)
+            except DownloadCapReached as e:
+                if not paused:
+                    logger.info(f"Job {job_id} paused until {e.resets_at}: {e}")
+                    await self.update_job(job_id, {"download_paused_until": e.resets_at})
+                    paused = True
+                wait = (e.resets_at - datetime.utcnow()).total_seconds()
+                await asyncio.sleep(min(max(wait, 1), DOWNLOAD_CAP_POLL_SECONDS))
+                continue
+            if paused:
+                await self.update_job(job_id, {"download_paused_until": None})
+            return True
+        return False
+
+    async def _transcribe_entry(
+        self,
+        job: Dict[str, Any],
+        idx: int,
+        episode_id: Optional[str],
+        audio_url: str,
+        duration_minutes: Optional[int],
+        priority: JobPriority
+    ) -> Tuple[Optional[str], float]:
+        """
+        Wait behind higher-priority work for a transcription slot, then
+        download and transcribe a job entry's audio.
+
+        Returns:
+            The transcript (None if transcription failed) and seconds spent transcribing
+
+        Raises:
+            JobCancelled: If the job was cancelled while queued
+        """
+        job_id = job["job_id"]
+
+        async def report_queue_position(position: Optional[int]):
+            await self.update_job(job_id, {"queue_position": position})
+
+        async with transcription_slots.slot(priority):
+            if not self.running_jobs.get(job_id, False):
+                raise JobCancelled()
+            started = time.monotonic()
+            progress = asyncio.create_task(
+                self._report_progress(job_id, idx, episode_id, duration_minutes, job.get("model"))
+            )
+            try:
+                transcript = await whisper_service.transcribe_audio_url(
+                    audio_url, on_queue_position=report_queue_position, model=job.get("model")
+                )
+            finally:
+                progress.cancel()
+            return transcript, time.monotonic() - started
+
+    async def _save_checkpoint(self, job_id: str, token: str, next_index: int, in_flight: List[Dict[str, Any]]):
+        """Checkpoint the job; if another instance has taken it over, stop processing it here."""
+        if not await self.checkpoints.save(job_id, token, next_index, in_flight):
+            self._detach(job_id)
+            raise JobCancelled()
+
+    async def _keep_lease(self, job_id: str, token: str):
+        """
+        Renew the job's lease until cancelled; stop the job here if it was
+        lost, or if it was cancelled through another instance.
+        """
+        while True:
+            await asyncio.sleep(max(settings.bulk_job_lease_seconds / 4, 1))
+            try:
+                if not await self.checkpoints.heartbeat(job_id, token):
+                    logger.warning(f"Job {job_id} was taken over by another instance; stopping it here")
+                    self._detach(job_id)
+                    return
+                if await self._cancel_requested(job_id):
+                    logger.info(f"Job {job_id} was cancelled through another instance; stopping it")
+                    self._abort(job_id)
+                    return
+            except Exception as e:
+                logger.warning(f"Failed to renew the lease of job {job_id}: {e}")
+
+    async def _cancel_requested(self, job_id: str) -> bool:
+        """Whether cancel_job was called for the job, on any instance."""
+        job = await self.jobs_collection.find_one({"job_id": job_id}, {"cancel_requested_at": 1})
+        return bool(job and job.get("cancel_requested_at"))
+
+    @classmethod
+    def _abort(cls, job_id: str) -> bool:
+        """
+        Stop this process's run of a job, aborting its in-flight request.
+
+        Returns:
+            False if the job isn't running here
+        """
+        if job_id not in cls.running_jobs:
+            return False
+        cls.running_jobs[job_id] = False
+        transcription = cls.active_transcriptions.get(job_id)
+        if transcription:
+            transcription.cancel()
+        return True
+
+    @classmethod
+    def _detach(cls, job_id: str):
+        """Stop processing a job without finishing it, aborting its in-flight request."""
+        cls.detached.add(job_id)
+        cls.running_jobs[job_id] = False
+        transcription = cls.active_transcriptions.get(job_id)
+        if transcription:
+            transcription.cancel()
+
+    async def resume_interrupted_jobs(self) -> int:
+        """
+        Resume unfinished jobs no instance holds (see bulk_checkpoint).
+
+        Returns:
+            Number of jobs resumed
+        """
+        resumed = 0
+        for job in await self.checkpoints.resumable():
+            job_id = job["job_id"]
+            if job_id in self.leases:
+                continue
+            token = await self.checkpoints.claim(job_id, (job.get("checkpoint") or {}).get("token"))
+            if not token:
+                continue
+            requeued = await self.checkpoints.requeue_in_flight(job_id)
+            logger.info(
+                f"Resuming job {job_id} from entry {(job.get('checkpoint') or {}).get('next_index', 0) + 1}"
+                f" ({requeued} in-flight entr{'y' if requeued == 1 else 'ies'} requeued)"
+            )
+            asyncio.create_task(self.process_job(job_id, token))
+            resumed += 1
+        return resumed
+
+    async def _report_progress(
+        self,
+        job_id: str,
+        idx: int,
+        episode_id: Optional[str],
+        duration_minutes: Optional[int],
+        model: Optional[str]
+    ):
+        """
+        Store the estimated progress of an episode's transcription until
+        cancelled. Episodes without a feed duration have no estimate.
+        """
+        if not duration_minutes:
+            return
+        started = time.monotonic()
+        while True:
+            await asyncio.sleep(PROGRESS_INTERVAL_SECONDS)
+            progress = transcription_speeds.estimate(model, duration_minutes * 60, time.monotonic() - started)
+            try:
+                await self.update_episode_in_job(job_id, idx, {"transcript_progress": progress})
+                if episode_id:
+                    await self.episodes_collection.update_one(
+                        {"episode_id": episode_id},
+                        {"$set": {"transcript_progress": progress}}
+                    )
+            except Exception as e:
+                logger.warning(f"Failed to record progress of job {job_id} episode {idx + 1}: {e}")
+
+    async def _wait_for_whisper(self, job_id: str, model: Optional[str]) -> bool:
+        """
+        Wait for a Whisper backend to load the job's model, so the first
+        episode doesn't fail on a container that is still starting.
+
+        Returns:
+            False if the job was cancelled while waiting
+
+        Raises:
+            WhisperNotReadyError: No backend was ready within WHISPER_READY_TIMEOUT_SECONDS
+        """
+        # Run as a task so cancel_job can abort the wait
+        readiness = asyncio.create_task(whisper_service.wait_until_ready(model))
+        self.active_transcriptions[job_id] = readiness
+        try:
+            await readiness
+        except asyncio.CancelledError:
+            if asyncio.current_task().cancelling():
+                raise
+            return False
+        finally:
+            self.active_transcriptions.pop(job_id, None)
+        return self.running_jobs.get(job_id, False)
+
+    @staticmethod
+    def _progress_fields(started_at: datetime, processed: int, total: int) -> Dict[str, Any]:
+        """
+        Compute progress and ETA after an episode finishes.
+
+        The average includes the pause between episodes, so the ETA reflects
+        actual throughput rather than transcription time alone.
+        """
+        now = datetime.utcnow()
+        average = (now - started_at).total_seconds() / processed
+        remaining = total - processed
+        return {
+            "progress_percent": round(processed / total * 100, 1) if total else 100.0,
+            "average_episode_seconds": round(average, 1),
+            "estimated_completion_at": now + timedelta(seconds=average * remaining) if remaining else None,
+        }
 
     async def cancel_job(self, job_id: str) -> bool:
-        """Cancel a running job."""
-        if job_id in self.running_jobs:
-            self.running_jobs[job_id] = False
+        """
+        Cancel an unfinished job, aborting its in-flight transcription.
+
+        The request is stored on the job: a job running on this instance
+        stops right away, one leased by another instance at its next lease
+        renewal (see _keep_lease), and one no instance holds when it is
+        resumed.
+
+        Returns:
+            False if the job has already finished
+        """
+        result = await self.jobs_collection.update_one(
+            {
+                "job_id": job_id,
+                "status": {"$in": [BulkJobStatus.PENDING.value, BulkJobStatus.RUNNING.value]},
+            },
+            {"$set": {"cancel_requested_at": datetime.utcnow(), "updated_at": datetime.utcnow()}}
+        )
+        await cache.invalidate(f"job:{job_id}")
+        if self._abort(job_id) or result.matched_count:
             logger.info(f"Cancelled job {job_id}")
             return True
         return False
+
+    @classmethod
+    async def interrupt_all(cls, db: AsyncIOMotorDatabase) -> int:
+        """
+        Stop every job this process is running (used on shutdown), requeueing
+        in-flight entries and releasing the jobs so another instance resumes
+        them at once.
+        """
+        leases = dict(cls.leases)
+        for job_id in leases:
+            cls._detach(job_id)
+        checkpoints = JobCheckpoints(db)
+        for job_id, token in leases.items():
+            try:
+                await checkpoints.requeue_in_flight(job_id)
+                await checkpoints.release(job_id, token)
+            except Exception as e:
+                logger.warning(f"Failed to release job {job_id}: {e}")
+        return len(leases)
@@ -0,0 +1,139 @@
+"""Slack and Discord webhook notifications."""
+import json
+import logging
+from typing import Any, Dict, List, Optional
+
+import httpx
+
+from app.config import settings
+
+logger = logging.getLogger(__name__)
+
+# Webhook request timeout in seconds
+WEBHOOK_TIMEOUT = 10.0
+
+# Events a destination can subscribe to
+BULK_JOB_COMPLETED = "bulk_job_completed"
+BULK_JOB_FAILED = "bulk_job_failed"
+EPISODE_TRANSCRIBED = "episode_transcribed"
+
+# Default message templates, overridable per destination
+DEFAULT_TEMPLATES = {
+    BULK_JOB_COMPLETED: (
+        "Bulk transcription of {podcast_title} completed: "
+        "{successful_episodes}/{total_episodes} episodes transcribed, {failed_episodes} failed."
+    ),
+    BULK_JOB_FAILED: "Bulk transcription of {podcast_title} failed ({job_id}): {error_message}",
+    EPISODE_TRANSCRIBED: "New transcript: {podcast_title} - {episode_title}\n{transcript_url}",
+}
+
+
+class _TemplateValues(dict):
+    """Leaves unknown placeholders intact instead of raising KeyError."""
+
+    def __missing__(self, key):
+        return "{" + key + "}"
+
+
+class ChatNotifier:
+    """Posts event messages to configured Slack and Discord webhooks."""
+
+    def __init__(self, destinations: Optional[List[Dict[str, Any]]] = None):
+        self.destinations = destinations if destinations is not None else self._load_destinations()
+
+    @staticmethod
+    def _load_destinations() -> List[Dict[str, Any]]:
+        """Parse webhook destinations from settings."""
+        if not settings.chat_webhooks:
+            return []
+
+        try:
+            destinations = json.loads(settings.chat_webhooks)
+        except json.JSONDecodeError as e:
+            logger.error(f"Invalid CHAT_WEBHOOKS configuration: {e}")
+            return []
+
+        valid = []
+        for destination in destinations:
+            if destination.get("type") not in ("slack", "discord") or not destination.get("url"):
+                logger.warning(f"Ignoring invalid chat webhook destination: {destination.get('name')}")
+                continue
+            valid.append(destination)
+        return valid
+
+    async def notify(self, event: str, values: Dict[str, Any]) -> int:
+        """
+        Send an event to every destination subscribed to it.
+
+        Args:
+            event: Event name
+            values: Values substituted into the message template
+
+        Returns:
+            Number of destinations successfully notified
+        """
+        delivered = 0
+        for destination in self.destinations:
+            events = destination.get("events")
+            if events is not None and event not in events:
+                continue
+
+            template = destination.get("templates", {}).get(event) or DEFAULT_TEMPLATES.get(event)
+            if not template:
+                continue
+
+            message = template.format_map(_TemplateValues(values))
+            if await self._post(destination, message):
+                delivered += 1
+
+        return delivered
+
+    async def _post(self, destination: Dict[str, Any], message: str) -> bool:
+        """Post a message to a single webhook."""
+        if destination["type"] == "discord":
+            payload = {"content": message}
+        else:
+            payload = {"text": message}
+
+        try:
+            async with httpx.AsyncClient(timeout=WEBHOOK_TIMEOUT) as client:
+                response = await client.post(destination["url"], json=payload)
+                response.raise_for_status()
+            return True
+        except Exception as e:
+            logger.error(f"Failed to post to {destination['type']} webhook '{destination.get('name')}': {e}")
+            return False
+
+    async def notify_bulk_job(self, job: Dict[str, Any], error_message: Optional[str] = None):
+        """Announce a bulk job reaching a terminal state."""
+        event = BULK_JOB_FAILED if error_message else BULK_JOB_COMPLETED
+        await self.notify(event, {
+            "job_id": job.get("job_id"),
+            "rss_url": job.get("rss_url"),
+            "podcast_title": job.get("podcast_title", "Unknown"),
+            "status": job.get("status"),
+            "total_episodes": job.get("total_episodes", 0),
+            "successful_episodes": job.get("successful_episodes", 0),
+            "failed_episodes": job.get("failed_episodes", 0),
+            "error_message": error_message or "",
+        })
+
+    async def notify_episode_transcribed(self, podcast: Dict[str, Any], episode: Dict[str, Any]):
+        """Announce a newly transcribed episode if its podcast is a flagship."""
+        podcast_id = podcast.get("podcast_id")
+        if not (podcast.get("flagship") or podcast_id in settings.flagship_podcast_ids_list):
+            return
+
+        base_url = settings.public_base_url.rstrip("/")
+        await self.notify(EPISODE_TRANSCRIBED, {
+            "podcast_id": podcast_id,
+            "podcast_title": podcast.get("title", "Unknown Podcast"),
+            "episode_id": episode.get("episode_id"),
+            "episode_title": episode.get("title", "Untitled Episode"),
+            "summary": episode.get("summary") or "",
+            "transcript_url": f"{base_url}/api/episodes/{episode.get('episode_id')}/transcript",
+        })
+
+
+# Singleton instance
+chat_notifier = ChatNotifier()
@@ -0,0 +1,169 @@
+"""Asynchronous cascade cleanup when a podcast is removed."""
+import logging
+import secrets
+from datetime import datetime
+from typing import Any, Dict, Optional
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.models.schemas import BulkJobStatus, CleanupJobStatus, CleanupMode
+from app.services.archive_service import ArchiveService
+from app.services.s3_service import s3_service
+from app.services.storage_locations import transcript_location
+from app.services.transcript_store import shared_outside
+from app.workspaces import stamp
+
+logger = logging.getLogger(__name__)
+
+
+class CleanupService:
+    """
+    Removes a podcast's episodes, transcripts and bulk-job references.
+
+    The podcast is soft-deleted synchronously; the per-episode work runs as a
+    background job whose progress is stored in the podcast_cleanup_jobs
+    collection.
+    """
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+        self.jobs_collection = db.podcast_cleanup_jobs
+
+    async def create_job(self, podcast_id: str, mode: CleanupMode, workspace_id: Optional[str] = None) -> Dict[str, Any]:
+        """Create a pending cleanup job for a podcast, in the podcast's workspace."""
+        total = await self.db.episodes.count_documents({"podcast_id": podcast_id})
+        now = datetime.utcnow()
+        job = {
+            "job_id": f"cln_{secrets.token_urlsafe(12)}",
+            "podcast_id": podcast_id,
+            "mode": mode.value,
+            "status": CleanupJobStatus.PENDING.value,
+            "total_episodes": total,
+            "processed_episodes": 0,
+            "transcripts_archived": 0,
+            "transcripts_deleted": 0,
+            "bulk_jobs_updated": 0,
+            "errors": [],
+            "created_at": now,
+            "updated_at": now,
+            "completed_at": None,
+        }
+        stamp(job, workspace_id)
+        await self.jobs_collection.insert_one(job)
+        logger.info(f"Created cleanup job {job['job_id']} for podcast {podcast_id} (mode={mode.value})")
+        return job
+
+    async def get_job(self, job_id: str) -> Optional[Dict[str, Any]]:
+        """Get cleanup job by ID."""
+        return await self.jobs_collection.find_one({"job_id": job_id})
+
+    async def _update_job(self, job_id: str, set_fields: Dict[str, Any], inc_fields: Optional[Dict[str, int]] = None, error: Optional[str] = None):
+        """Update job progress."""
+        update: Dict[str, Any] = {"$set": {**set_fields, "updated_at": datetime.utcnow()}}
+        if inc_fields:
+            update["$inc"] = inc_fields
+        if error:
+            update["$push"] = {"errors": error}
+        await self.jobs_collection.update_one({"job_id": job_id}, update)
+
+    async def run_job(self, job_id: str):
+        """
+        Process a cleanup job.
+
+        archive: moves each episode's transcript to the archive prefix and
+        strips transcripts from the feed's bulk job episode progress.
+        delete: deletes each transcript from S3 and the episode document,
+        deletes the feed's finished bulk jobs, then removes the podcast.
+        """
+        job = await self.get_job(job_id)
+        if not job:
+            logger.error(f"Cleanup job {job_id} not found")
+            return
+
+        podcast_id = job["podcast_id"]
+        mode = CleanupMode(job["mode"])
+        archive_service = ArchiveService(self.db)
+
+        try:
+            await self._update_job(job_id, {"status": CleanupJobStatus.RUNNING.value})
+            podcast = await self.db.podcasts.find_one({"podcast_id": podcast_id})
+
+            async for episode in self.db.episodes.find({"podcast_id": podcast_id}):
+                episode_id = episode["episode_id"]
+                inc = {"processed_episodes": 1}
+                error = None
+
+                if mode == CleanupMode.ARCHIVE:
+                    if episode.get("transcript_s3_key") and not episode.get("transcript_archived_at"):
+                        if await archive_service.archive_episode(episode, podcast_id):
+                            inc["transcripts_archived"] = 1
+                        else:
+                            error = f"{episode_id}: failed to archive transcript"
+                else:
+                    transcript_key = episode.get("transcript_s3_key")
+                    storage = episode.get("storage")
+                    # Identical transcripts are stored once; keep one another podcast uses
+                    if transcript_key and await shared_outside(self.db, transcript_key, podcast_id, storage):
+                        transcript_key = None
+                    if transcript_key and not await s3_service.delete_object(
+                        transcript_key, **transcript_location(storage)
+                    ):
+                        error = f"{episode_id}: failed to delete transcript"
+                    else:
+                        if transcript_key:
+                            inc["transcripts_deleted"] = 1
+                        await self.db.episodes.delete_one({"episode_id": episode_id})
+
+                await self._update_job(job_id, {}, inc, error)
+
+            if podcast:
+                bulk_jobs = await self._cleanup_bulk_jobs(podcast["rss_url"], mode)
+                await self._update_job(job_id, {}, {"bulk_jobs_updated": bulk_jobs})
+
+            job = await self.get_job(job_id)
+            if mode == CleanupMode.DELETE and not job["errors"]:
+                await self.db.podcasts.delete_one({"podcast_id": podcast_id})
+                for collection in (
+                    self.db.user_episode_state,
+                    self.db.favorite_episodes,
+                    self.db.transcript_bookmarks,
+                    self.db.share_links,
+                ):
+                    await collection.delete_many({"podcast_id": podcast_id})
+
+            await self._update_job(job_id, {
+                "status": CleanupJobStatus.COMPLETED.value,
+                "completed_at": datetime.utcnow(),
+            })
+            logger.info(f"Cleanup job {job_id} completed")
+
+        except Exception as e:
+            logger.error(f"Cleanup job {job_id} failed: {e}")
+            await self._update_job(
+                job_id,
+                {"status": CleanupJobStatus.FAILED.value, "completed_at": datetime.utcnow()},
+                error=str(e)
+            )
+
+    async def _cleanup_bulk_jobs(self, rss_url: str, mode: CleanupMode) -> int:
+        """Strip or delete the bulk jobs that ran against a feed. Running jobs are left alone."""
+        finished = {
+            "rss_url": rss_url,
+            "status": {"$nin": [
+                BulkJobStatus.PENDING.value, BulkJobStatus.RUNNING.value, BulkJobStatus.PAUSED.value
+            ]},
+        }
+        job_ids = await self.db.bulk_transcribe_jobs.distinct("job_id", finished)
+        if not job_ids:
+            return 0
+
+        if mode == CleanupMode.DELETE:
+            await self.db.job_episodes.delete_many({"job_id": {"$in": job_ids}})
+            result = await self.db.bulk_transcribe_jobs.delete_many({"job_id": {"$in": job_ids}})
+            return result.deleted_count
+
+        await self.db.job_episodes.update_many(
+            {"job_id": {"$in": job_ids}, "transcript": {"$exists": True}},
+            {"$unset": {"transcript": ""}}
+        )
+        return len(job_ids)
@@ -0,0 +1,150 @@
+"""Cost estimation and tracking for transcriptions."""
+import logging
+from datetime import datetime
+from typing import Any, Dict, List, Optional
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.config import settings
+from app.workspaces import scoped, workspace_podcast_ids
+
+logger = logging.getLogger(__name__)
+
+# Rough transcript size used when estimating storage (~150 words/min)
+TRANSCRIPT_BYTES_PER_MINUTE = 1000
+BYTES_PER_GB = 1024 ** 3
+
+
+def compute_cost(
+    audio_minutes: float,
+    compute_seconds: Optional[float] = None,
+    transcript_bytes: Optional[int] = None,
+) -> Dict[str, float]:
+    """
+    Price a transcription.
+
+    Missing measurements fall back to the configured per-minute estimates, so
+    calling with only audio_minutes yields an estimate.
+
+    Args:
+        audio_minutes: Length of the audio
+        compute_seconds: Measured Lambda/Whisper execution time
+        transcript_bytes: Size of the stored transcript
+
+    Returns:
+        Cost breakdown in USD
+    """
+    if compute_seconds is None:
+        compute_seconds = audio_minutes * settings.cost_compute_seconds_per_audio_minute
+    if transcript_bytes is None:
+        transcript_bytes = int(audio_minutes * TRANSCRIPT_BYTES_PER_MINUTE)
+
+    stored_bytes = transcript_bytes + audio_minutes * settings.cost_audio_bytes_per_minute
+    gb_seconds = compute_seconds * settings.cost_lambda_memory_mb / 1024
+
+    transcription = audio_minutes * settings.cost_transcription_per_minute
+    storage = stored_bytes / BYTES_PER_GB * settings.cost_s3_per_gb_month
+    compute = gb_seconds * settings.cost_lambda_per_gb_second
+
+    return {
+        "audio_minutes": round(audio_minutes, 2),
+        "lambda_gb_seconds": round(gb_seconds, 2),
+        "transcription_usd": round(transcription, 6),
+        "storage_usd": round(storage, 6),
+        "compute_usd": round(compute, 6),
+        "total_usd": round(transcription + storage + compute, 6),
+    }
+
+
+def sum_costs(costs: List[Dict[str, float]]) -> Dict[str, float]:
+    """Add cost breakdowns together."""
+    total: Dict[str, float] = {}
+    for cost in costs:
+        for key, value in cost.items():
+            total[key] = round(total.get(key, 0) + value, 6)
+    return total
+
+
+class CostService:
+    """Stores costs on episodes and bulk jobs and aggregates them by month."""
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+
+    async def record_episode_cost(
+        self,
+        episode_id: str,
+        audio_minutes: float,
+        compute_seconds: float,
+        transcript_bytes: int,
+    ) -> Dict[str, float]:
+        """Store the actual cost of a completed transcription on its episode."""
+        actual = compute_cost(audio_minutes, compute_seconds, transcript_bytes)
+        await self.db.episodes.update_one(
+            {"episode_id": episode_id},
+            {"$set": {
+                "cost.estimated": compute_cost(audio_minutes),
+                "cost.actual": actual,
+                "cost.recorded_at": datetime.utcnow(),
+            }}
+        )
+        logger.info(f"Recorded cost for episode {episode_id}: ${actual['total_usd']:.4f}")
+        return actual
+
+    async def monthly_costs(self, months: int = 12, workspace_id: Optional[str] = None) -> List[Dict[str, Any]]:
+        """
+        Aggregate actual costs per calendar month, newest first.
+
+        Episodes transcribed through the Lambda pipeline and episodes of bulk
+        jobs are counted separately so the two paths can be compared.
+        """
+        episode_match: Dict[str, Any] = {"cost.actual": {"$exists": True}}
+        podcast_ids = await workspace_podcast_ids(self.db, workspace_id)
+        if podcast_ids is not None:
+            episode_match["podcast_id"] = {"$in": podcast_ids}
+
+        episode_rows = await self.db.episodes.aggregate([
+            {"$match": episode_match},
+            {"$group": {
+                "_id": {"$dateToString": {"format": "%Y-%m", "date": "$cost.recorded_at"}},
+                "episodes": {"$sum": 1},
+                "audio_minutes": {"$sum": "$cost.actual.audio_minutes"},
+                "lambda_gb_seconds": {"$sum": "$cost.actual.lambda_gb_seconds"},
+                "transcription_usd": {"$sum": "$cost.actual.transcription_usd"},
+                "storage_usd": {"$sum": "$cost.actual.storage_usd"},
+                "compute_usd": {"$sum": "$cost.actual.compute_usd"},
+                "total_usd": {"$sum": "$cost.actual.total_usd"},
+            }},
+        ]).to_list(length=None)
+
+        job_rows = await self.db.bulk_transcribe_jobs.aggregate([
+            {"$match": scoped({"actual_cost": {"$exists": True}}, workspace_id)},
+            {"$group": {
+                "_id": {"$dateToString": {"format": "%Y-%m", "date": "$created_at"}},
+                "jobs": {"$sum": 1},
+                "audio_minutes": {"$sum": "$actual_cost.audio_minutes"},
+                "lambda_gb_seconds": {"$sum": "$actual_cost.lambda_gb_seconds"},
+                "transcription_usd": {"$sum": "$actual_cost.transcription_usd"},
+                "storage_usd": {"$sum": "$actual_cost.storage_usd"},
+                "compute_usd": {"$sum": "$actual_cost.compute_usd"},
+                "total_usd": {"$sum": "$actual_cost.total_usd"},
+            }},
+        ]).to_list(length=None)
+
+        by_month: Dict[str, Dict[str, Any]] = {}
+        for source, rows in (("pipeline", episode_rows), ("bulk_jobs", job_rows)):
+            for row in rows:
+                month = row.pop("_id")
+                entry = by_month.setdefault(month, {"month": month})
+                entry[source] = row
+
+        result = []
+        for month in sorted(by_month, reverse=True)[:months]:
+            entry = by_month[month]
+            parts = [
+                {k: v for k, v in entry[source].items() if k not in ("episodes", "jobs")}
+                for source in ("pipeline", "bulk_jobs") if source in entry
+            ]
+            entry["total"] = sum_costs(parts)
+            result.append(entry)
+        return result
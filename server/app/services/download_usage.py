@@ -0,0 +1,131 @@
+"""
+Bandwidth accounting for audio downloads.
+
+The bytes of every audio download, partial and failed ones included, are
+added to its host's total for the UTC day in the download_usage collection,
+shared by all API instances. A host that has served its daily cap
+(WHISPER_DOWNLOAD_HOST_DAILY_BYTES, or its entry in
+WHISPER_DOWNLOAD_HOST_DAILY_LIMITS) gets no new downloads until the next UTC
+day, so a large backfill can't run up egress or get the deployment's IP
+blocked by the publisher's host. Bulk jobs wait for the cap to reset; other
+downloads fail. GET /admin/download-usage reports the totals.
+"""
+import logging
+from datetime import datetime, timedelta
+from typing import Any, Dict, Optional, Tuple
+from urllib.parse import urlparse
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.config import settings
+
+logger = logging.getLogger(__name__)
+
+DAY_FORMAT = "%Y-%m-%d"
+
+
+class DownloadCapReached(Exception):
+    """Raised when a host has served its daily download cap."""
+
+    def __init__(self, host: str, limit: int, resets_at: datetime):
+        super().__init__(f"Daily download cap of {limit} bytes reached for {host}")
+        self.host = host
+        self.limit = limit
+        self.resets_at = resets_at
+
+
+def download_host(url: str) -> str:
+    """The host downloads from a URL are accounted to."""
+    return (urlparse(url).hostname or "").lower()
+
+
+def _day_bounds(now: Optional[datetime] = None) -> Tuple[str, datetime]:
+    """Current UTC day key (YYYY-MM-DD) and the start of the next day."""
+    now = now or datetime.utcnow()
+    start = datetime(now.year, now.month, now.day)
+    return start.strftime(DAY_FORMAT), start + timedelta(days=1)
+
+
+def host_limits() -> Dict[str, int]:
+    """Per-host daily caps from WHISPER_DOWNLOAD_HOST_DAILY_LIMITS; invalid entries are skipped."""
+    limits: Dict[str, int] = {}
+    for entry in settings.whisper_download_host_daily_limits.split(","):
+        if not entry.strip():
+            continue
+        host, _, value = entry.partition("=")
+        try:
+            limits[host.strip().lower()] = int(value)
+        except ValueError:
+            logger.error(f"Ignoring invalid WHISPER_DOWNLOAD_HOST_DAILY_LIMITS entry: {entry.strip()}")
+    return limits
+
+
+def daily_limit(host: str) -> int:
+    """A host's daily download cap in bytes (0 = no cap)."""
+    return host_limits().get(host, settings.whisper_download_host_daily_bytes)
+
+
+class DownloadUsageService:
+    """Tracks audio bytes downloaded per host and UTC day."""
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+        self.usage_collection = db.download_usage
+
+    async def used_today(self, host: str) -> int:
+        """Bytes downloaded from a host today."""
+        day, _ = _day_bounds()
+        doc = await self.usage_collection.find_one({"host": host, "day": day})
+        return doc.get("bytes", 0) if doc else 0
+
+    async def check(self, host: str):
+        """
+        Make sure a host is within its daily cap before downloading from it.
+
+        Raises:
+            DownloadCapReached: If the host has served its cap today
+        """
+        limit = daily_limit(host)
+        if limit and await self.used_today(host) >= limit:
+            raise DownloadCapReached(host, limit, _day_bounds()[1])
+
+    async def record(self, host: str, downloaded: int):
+        """Add a download's bytes to the host's total for today."""
+        day, _ = _day_bounds()
+        await self.usage_collection.update_one(
+            {"host": host, "day": day},
+            {"$inc": {"bytes": downloaded, "downloads": 1}, "$set": {"updated_at": datetime.utcnow()}},
+            upsert=True
+        )
+
+    async def report(self, days: int = 7) -> Dict[str, Any]:
+        """
+        Bytes and downloads per host and day, newest day first and the
+        busiest hosts first within a day, with today's caps.
+
+        Args:
+            days: Days to include, today among them
+        """
+        today, resets_at = _day_bounds()
+        since = (resets_at - timedelta(days=days)).strftime(DAY_FORMAT)
+        docs = await self.usage_collection.find(
+            {"day": {"$gte": since}}, {"_id": 0}
+        ).sort([("day", -1), ("bytes", -1)]).to_list(length=None)
+
+        usage = []
+        for doc in docs:
+            limit = daily_limit(doc["host"]) if doc["day"] == today else 0
+            usage.append({
+                "host": doc["host"],
+                "day": doc["day"],
+                "bytes": doc.get("bytes", 0),
+                "downloads": doc.get("downloads", 0),
+                "limit_bytes": limit or None,
+                "capped": bool(limit) and doc.get("bytes", 0) >= limit,
+            })
+        return {
+            "days": days,
+            "resets_at": resets_at,
+            "total_bytes": sum(item["bytes"] for item in usage),
+            "usage": usage,
+        }
@@ -0,0 +1,214 @@
+"""Recognize episodes re-listed under a new audio URL, and reruns.
+
+When a podcast moves hosts, its feed lists every episode again with new
+audio URLs, and since episode IDs are derived from the audio URL they would
+be transcribed a second time. A feed item whose title, publication date and
+duration match an existing episode of the same podcast is linked to that
+episode instead. Mirrors the poll Lambda's dedup.go.
+
+Feeds also republish old episodes as a "Replay" or "Encore" later on. Such
+an item (a rerun-looking title that matches a transcribed episode's once
+the marker is stripped, with a matching duration; or an identical title and
+duration) gets its own episode sharing the original's transcript, with
+rerun_of naming the original. Mirrors rerun.go.
+"""
+import logging
+import re
+from datetime import datetime, timedelta
+from typing import Any, Dict, Optional, Tuple
+
+from motor.motor_asyncio import AsyncIOMotorCollection
+
+from app.models.schemas import TranscriptStatus
+
+logger = logging.getLogger(__name__)
+
+# Hosts sometimes rewrite pubDate on migration (timezone, re-import time)
+PUBLISHED_WINDOW = timedelta(hours=48)
+
+# Re-encoded audio can shift the reported duration slightly
+MIN_DURATION_TOLERANCE_MINUTES = 1
+DURATION_TOLERANCE = 0.02
+
+_TITLE_NOISE = re.compile(r"[^a-z0-9]+")
+
+# Same as the poll Lambda's rerunTitle (episodefilter.go)
+_RERUN_TITLE = re.compile(
+    r"\b(re-?run|rebroadcast|re-?release|encore presentation|from the (vault|archives?)|best of)\b"
+    r"|^\W*(replay|encore)\b|[(\[](r|replay|encore|repeat)[)\]]",
+    re.IGNORECASE,
+)
+# The strippable markers: "Replay:" prefixes, "(Encore)" or "- Rebroadcast" suffixes
+_RERUN_MARKER = re.compile(
+    r"^\W*(?:replay|encore|rebroadcast|re-?run|repost|best of|from the archives?)\b\W*"
+    r"|\W*[(\[]\s*(?:replay|encore|rebroadcast|re-?run|repost|from the archives?)\s*[)\]]\W*$"
+    r"|\s+[-–—|:]\s*(?:replay|encore|rebroadcast|re-?run|repost)\W*$",
+    re.IGNORECASE,
+)
+
+
+def normalize_title(title: Optional[str]) -> str:
+    """Lowercase a title and collapse punctuation and whitespace."""
+    return _TITLE_NOISE.sub(" ", (title or "").lower()).strip()
+
+
+def durations_match(a: Optional[int], b: Optional[int]) -> bool:
+    """Compare durations in minutes; unknown durations don't rule out a match."""
+    if not a or not b:
+        return True
+    return abs(a - b) <= max(MIN_DURATION_TOLERANCE_MINUTES, DURATION_TOLERANCE * max(a, b))
+
+
+def is_same_episode(item: Dict[str, Any], existing: Dict[str, Any]) -> bool:
+    """Whether a feed item is an existing episode under a new audio URL."""
+    title = normalize_title(item.get("title"))
+    if not title or title != normalize_title(existing.get("title")):
+        return False
+    published, existing_published = item.get("published_date"), existing.get("published_date")
+    if not published or not existing_published:
+        return False
+    if abs(published - existing_published) > PUBLISHED_WINDOW:
+        return False
+    return durations_match(item.get("duration_minutes"), existing.get("duration_minutes"))
+
+
+def strip_rerun_marker(title: Optional[str]) -> Tuple[str, bool]:
+    """Remove rerun markers from a title and tell whether it looks like a rerun."""
+    title = title or ""
+    stripped = _RERUN_MARKER.sub("", title)
+    return stripped, stripped != title or bool(_RERUN_TITLE.search(title))
+
+
+def is_rerun_of(item: Dict[str, Any], existing: Dict[str, Any]) -> bool:
+    """Whether a feed item republishes an existing episode."""
+    published, existing_published = item.get("published_date"), existing.get("published_date")
+    if not published or not existing_published:
+        return False
+    # Within the window it would be the same episode re-listed
+    if published - existing_published <= PUBLISHED_WINDOW:
+        return False
+    stripped, marked = strip_rerun_marker(item.get("title"))
+    title = normalize_title(stripped)
+    if not title or title != normalize_title(strip_rerun_marker(existing.get("title"))[0]):
+        return False
+    duration, existing_duration = item.get("duration_minutes"), existing.get("duration_minutes")
+    if marked:
+        return durations_match(duration, existing_duration)
+    return bool(duration) and duration == existing_duration
+
+
+async def find_moved_episode(
+    collection: AsyncIOMotorCollection,
+    podcast_id: str,
+    item: Dict[str, Any],
+) -> Optional[Dict[str, Any]]:
+    """
+    Find the existing episode a feed item duplicates.
+
+    Args:
+        collection: Episodes collection
+        podcast_id: Podcast the feed belongs to
+        item: Parsed feed item (title, audio_url, published_date, duration_minutes)
+
+    Returns:
+        Episode document, or None (items without a title or date never match)
+    """
+    published: Optional[datetime] = item.get("published_date")
+    if not published or not normalize_title(item.get("title")):
+        return None
+
+    cursor = collection.find({
+        "podcast_id": podcast_id,
+        "audio_url": {"$ne": item.get("audio_url")},
+        "deleted_at": None,
+        "published_date": {"$gte": published - PUBLISHED_WINDOW, "$lte": published + PUBLISHED_WINDOW},
+    })
+    async for candidate in cursor:
+        if is_same_episode(item, candidate):
+            return candidate
+    return None
+
+
+async def link_moved_episode(collection: AsyncIOMotorCollection, existing: Dict[str, Any], audio_url: str):
+    """Point an existing episode at its new audio URL, keeping the old one."""
+    await collection.update_one(
+        {"episode_id": existing["episode_id"]},
+        {
+            "$set": {"audio_url": audio_url, "updated_at": datetime.utcnow()},
+            "$addToSet": {"previous_audio_urls": existing.get("audio_url")},
+        }
+    )
+    logger.info(
+        f"Linked {audio_url} to existing episode {existing['episode_id']} "
+        f"(audio moved from {existing.get('audio_url')})"
+    )
+
+
+async def find_rerun_original(
+    collection: AsyncIOMotorCollection,
+    podcast_id: str,
+    item: Dict[str, Any],
+) -> Optional[Dict[str, Any]]:
+    """
+    Find the earliest transcribed episode a feed item republishes.
+
+    Args:
+        collection: Episodes collection
+        podcast_id: Podcast the feed belongs to
+        item: Parsed feed item (title, published_date, duration_minutes)
+
+    Returns:
+        Episode document, or None (items without a title or date never match)
+    """
+    published: Optional[datetime] = item.get("published_date")
+    if not published or not normalize_title(strip_rerun_marker(item.get("title"))[0]):
+        return None
+
+    cursor = collection.find(
+        {
+            "podcast_id": podcast_id,
+            "deleted_at": None,
+            "transcript_status": TranscriptStatus.COMPLETED.value,
+            "transcript_s3_key": {"$nin": [None, ""]},
+            "published_date": {"$lt": published - PUBLISHED_WINDOW},
+        },
+        {"transcript_text": 0, "show_notes": 0, "description_html": 0},
+    ).sort("published_date", 1)
+    async for candidate in cursor:
+        if is_rerun_of(item, candidate):
+            return candidate
+    return None
+
+
+async def link_rerun(
+    collection: AsyncIOMotorCollection,
+    podcast_id: str,
+    episode_id: str,
+    item: Dict[str, Any],
+    original: Dict[str, Any],
+):
+    """Create a rerun's episode document sharing its original's transcript."""
+    now = datetime.utcnow()
+    await collection.update_one(
+        {"episode_id": episode_id},
+        {"$setOnInsert": {
+            "_id": episode_id,
+            "podcast_id": podcast_id,
+            "title": item.get("title"),
+            "audio_url": item["audio_url"],
+            "published_date": item.get("published_date"),
+            "duration_minutes": item.get("duration_minutes"),
+            "episode_type": item.get("episode_type"),
+            "explicit": item.get("explicit"),
+            "persons": item.get("persons") or [],
+            "transcript_status": TranscriptStatus.COMPLETED.value,
+            "transcript_s3_key": original["transcript_s3_key"],
+            "transcript_sha256": original.get("transcript_sha256"),
+            "storage": original.get("storage"),
+            "rerun_of": original["episode_id"],
+            "created_at": now,
+            "updated_at": now,
+        }},
+        upsert=True
+    )
+    logger.info(f"Episode {episode_id} is a rerun of {original['episode_id']}, reusing its transcript")
@@ -0,0 +1,226 @@
+"""
+Transcript embeddings and similar-episode lookup.
+
+A background job embeds each completed transcript with EMBEDDING_MODEL
+(OpenAI, so it needs OPENAI_API_KEY) every EMBEDDING_INTERVAL_HOURS and
+keeps the vector in the episode_embeddings collection, keyed like the
+episode. Transcripts are longer than the model's input, so up to
+MAX_PASSAGES passages spread over the transcript are embedded and their
+normalized mean is the episode's vector. An episode is embedded again
+when it is re-transcribed or the model changes.
+
+Similar episodes are found by cosine similarity against every other
+embedding in the caller's podcasts, computed in-process; that suits
+libraries of a few thousand episodes.
+"""
+import asyncio
+import logging
+import math
+from datetime import datetime
+from typing import Any, Dict, List, Optional
+
+import httpx
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.config import settings
+from app.models.schemas import TranscriptStatus
+from app.services.s3_service import s3_service
+from app.services.storage_locations import transcript_location
+
+logger = logging.getLogger(__name__)
+
+OPENAI_EMBEDDINGS_URL = "https://api.openai.com/v1/embeddings"
+REQUEST_TIMEOUT = 60.0
+
+# About 1,500 tokens of English per passage, well inside the model's limit
+PASSAGE_CHARS = 6000
+MAX_PASSAGES = 16
+
+
+def passages(text: str) -> List[str]:
+    """Up to MAX_PASSAGES passages spread evenly over a transcript."""
+    text = " ".join(text.split())
+    starts = range(0, len(text), PASSAGE_CHARS)
+    if len(starts) > MAX_PASSAGES:
+        step = len(starts) / MAX_PASSAGES
+        starts = [starts[int(i * step)] for i in range(MAX_PASSAGES)]
+    return [text[start:start + PASSAGE_CHARS] for start in starts]
+
+
+def normalize(vector: List[float]) -> List[float]:
+    norm = math.sqrt(sum(x * x for x in vector))
+    return [x / norm for x in vector] if norm else vector
+
+
+def mean_vector(vectors: List[List[float]]) -> List[float]:
+    """Normalized mean of normalized vectors."""
+    normalized = [normalize(v) for v in vectors]
+    return normalize([sum(column) / len(normalized) for column in zip(*normalized)])
+
+
+def cosine(a: List[float], b: List[float]) -> float:
+    """Cosine similarity of two normalized vectors."""
+    return sum(x * y for x, y in zip(a, b))
+
+
+async def embed_texts(texts: List[str]) -> List[List[float]]:
+    """Embed texts with EMBEDDING_MODEL, in input order."""
+    async with httpx.AsyncClient(timeout=REQUEST_TIMEOUT) as client:
+        response = await client.post(
+            OPENAI_EMBEDDINGS_URL,
+            json={"model": settings.embedding_model, "input": texts},
+            headers={"Authorization": f"Bearer {settings.openai_api_key}"},
+        )
+        response.raise_for_status()
+        data = response.json()["data"]
+    return [item["embedding"] for item in sorted(data, key=lambda item: item["index"])]
+
+
+class EpisodeEmbeddingService:
+    """Embeds transcripts and finds similar episodes."""
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+        self.embeddings_collection = db.episode_embeddings
+
+    async def embed_pending(self) -> int:
+        """
+        Embed completed episodes not embedded since they were last
+        transcribed (or with another model), and drop the embeddings of
+        episodes deleted or re-queued.
+
+        Returns:
+            Number of episodes embedded
+        """
+        embedded_versions = {
+            doc["_id"]: (doc.get("episode_updated_at"), doc.get("model"))
+            async for doc in self.embeddings_collection.find({}, {"episode_updated_at": 1, "model": 1})
+        }
+        embedded = 0
+        cursor = self.db.episodes.find(
+            {"transcript_status": TranscriptStatus.COMPLETED.value, "deleted_at": None},
+            {"transcript_text": 0, "show_notes": 0, "description_html": 0}
+        )
+        async for episode in cursor:
+            if episode["_id"] in embedded_versions:
+                version = embedded_versions.pop(episode["_id"])
+                if version == (episode.get("updated_at"), settings.embedding_model):
+                    continue
+            try:
+                if await self.embed_episode(episode):
+                    embedded += 1
+            except Exception as e:
+                logger.warning(f"Failed to embed episode {episode.get('episode_id')}: {e}")
+        if embedded_versions:
+            await self.embeddings_collection.delete_many({"_id": {"$in": list(embedded_versions)}})
+        if embedded:
+            logger.info(f"Embedded {embedded} episode transcript(s) with {settings.embedding_model}")
+        return embedded
+
+    async def embed_episode(self, episode: Dict[str, Any]) -> bool:
+        text = None
+        if episode.get("transcript_s3_key"):
+            text = await s3_service.get_transcript(
+                episode["transcript_s3_key"], **transcript_location(episode.get("storage"))
+            )
+        if not text:
+            stored = await self.db.episodes.find_one({"_id": episode["_id"]}, {"transcript_text": 1})
+            text = (stored or {}).get("transcript_text")
+        if not text or not text.strip():
+            return False
+
+        vector = mean_vector(await embed_texts(passages(text)))
+        await self.embeddings_collection.replace_one(
+            {"_id": episode["_id"]},
+            {
+                "episode_id": episode.get("episode_id"),
+                "podcast_id": episode.get("podcast_id"),
+                "model": settings.embedding_model,
+                "vector": vector,
+                "episode_updated_at": episode.get("updated_at"),
+                "embedded_at": datetime.utcnow(),
+            },
+            upsert=True
+        )
+        return True
+
+    async def similar(
+        self,
+        episode_id: str,
+        podcast_ids: Optional[List[str]] = None,
+        limit: int = 10,
+    ) -> Optional[Dict[str, Any]]:
+        """
+        The episodes most similar to one, best first.
+
+        Args:
+            episode_id: Episode to compare against
+            podcast_ids: Podcasts to search (None = all)
+            limit: Maximum episodes to return
+
+        Returns:
+            The model and the similar episodes with their scores, or None if
+            the episode has no embedding yet
+        """
+        target = await self.embeddings_collection.find_one({"episode_id": episode_id})
+        if not target:
+            return None
+
+        query: Dict[str, Any] = {"model": target["model"], "episode_id": {"$ne": episode_id}}
+        if podcast_ids is not None:
+            query["podcast_id"] = {"$in": podcast_ids}
+        scored = []
+        async for doc in self.embeddings_collection.find(query, {"episode_id": 1, "vector": 1}):
+            scored.append((cosine(target["vector"], doc["vector"]), doc["episode_id"]))
+        scored.sort(reverse=True)
+        # A few spare in case some were deleted since the last embedding run
+        best = scored[:limit * 2]
+
+        episodes = {}
+        cursor = self.db.episodes.find(
+            {"episode_id": {"$in": [similar_id for _, similar_id in best]}, "deleted_at": None},
+            {"episode_id": 1, "title": 1, "podcast_id": 1, "published_date": 1}
+        )
+        async for episode in cursor:
+            episodes[episode["episode_id"]] = episode
+        titles = {}
+        podcast_ids_found = list({e.get("podcast_id") for e in episodes.values() if e.get("podcast_id")})
+        if podcast_ids_found:
+            cursor = self.db.podcasts.find({"podcast_id": {"$in": podcast_ids_found}}, {"podcast_id": 1, "title": 1})
+            async for podcast in cursor:
+                titles[podcast["podcast_id"]] = podcast.get("title")
+
+        results = []
+        for score, similar_id in best:
+            episode = episodes.get(similar_id)
+            if not episode:
+                continue
+            results.append({
+                "episode_id": similar_id,
+                "title": episode.get("title"),
+                "podcast_id": episode.get("podcast_id"),
+                "podcast_title": titles.get(episode.get("podcast_id")),
+                "published_date": episode.get("published_date"),
+                "score": round(score, 4),
+            })
+            if len(results) == limit:
+                break
+        return {"episode_id": episode_id, "model": target["model"], "episodes": results}
+
+
+async def run_embedding_scheduler(get_db, interval_hours: int):
+    """
+    Periodically embed newly transcribed episodes until cancelled.
+
+    Args:
+        get_db: Callable returning the database instance
+        interval_hours: Hours between embedding runs
+    """
+    logger.info(f"Starting transcript embedding scheduler (every {interval_hours} hours)")
+    while True:
+        try:
+            await EpisodeEmbeddingService(get_db()).embed_pending()
+        except Exception as e:
+            logger.error(f"Transcript embedding run failed: {e}")
+        # Runs on startup too, so a new deployment doesn't wait a full interval
+        await asyncio.sleep(interval_hours * 3600)
@@ -0,0 +1,226 @@
+"""
+Episode search across shows through a podcast search API.
+
+Subscriptions only cover feeds someone added. With EPISODE_SEARCH_PROVIDER
+set (listennotes or taddy, each with its API key), GET /api/external/episodes
+finds single episodes of any show the provider indexes, and
+POST /api/external/episodes/{external_id}/transcribe imports just that item:
+the show gets an inactive podcast record (as bulk jobs give unsubscribed
+feeds, so subscribing later keeps the episode) and the episode is created
+under the same ID the poll Lambda would give it, then transcribed.
+"""
+import logging
+from datetime import datetime
+from typing import Any, Dict, List, Optional
+
+import httpx
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.cache import cache
+from app.config import settings
+from app.models.schemas import TranscriptStatus
+from app.services.bulk_transcribe_service import BulkTranscribeService, episode_id_for
+from app.url_normalization import normalize_url
+from app.workspaces import in_workspace
+
+logger = logging.getLogger(__name__)
+
+REQUEST_TIMEOUT = 30.0
+
+
+class ExternalEpisodeError(Exception):
+    """Raised when an external episode can't be imported."""
+
+
+class EpisodeSearchProvider:
+    """A podcast search API. Results are normalized to one shape."""
+
+    name = ""
+
+    async def search(self, query: str, page: int, limit: int) -> List[Dict[str, Any]]:
+        """Episodes matching query, most relevant first."""
+        raise NotImplementedError
+
+    async def get_episode(self, external_id: str) -> Optional[Dict[str, Any]]:
+        """One episode by the provider's ID, None if it doesn't exist."""
+        raise NotImplementedError
+
+
+def _minutes(seconds: Any) -> Optional[int]:
+    return round(int(seconds) / 60) if seconds else None
+
+
+class ListenNotesProvider(EpisodeSearchProvider):
+    """Listen Notes Podcast API v2."""
+
+    name = "listennotes"
+    base_url = "https://listen-api.listennotes.com/api/v2"
+
+    def _headers(self) -> Dict[str, str]:
+        return {"X-ListenAPI-Key": settings.listennotes_api_key}
+
+    @staticmethod
+    def _normalize(item: Dict[str, Any]) -> Dict[str, Any]:
+        podcast = item.get("podcast") or {}
+        published_ms = item.get("pub_date_ms")
+        return {
+            "external_id": item["id"],
+            "title": item.get("title_original") or item.get("title"),
+            "description": item.get("description_original") or item.get("description"),
+            "audio_url": item.get("audio"),
+            "published_date": datetime.utcfromtimestamp(published_ms / 1000) if published_ms else None,
+            "duration_minutes": _minutes(item.get("audio_length_sec")),
+            "image_url": item.get("image"),
+            "podcast_title": podcast.get("title_original") or podcast.get("title"),
+            "podcast_rss_url": item.get("rss") or podcast.get("rss"),
+        }
+
+    async def search(self, query: str, page: int, limit: int) -> List[Dict[str, Any]]:
+        params = {"q": query, "type": "episode", "offset": (page - 1) * limit, "page_size": limit}
+        async with httpx.AsyncClient(timeout=REQUEST_TIMEOUT) as client:
+            response = await client.get(f"{self.base_url}/search", params=params, headers=self._headers())
+            response.raise_for_status()
+        return [self._normalize(item) for item in response.json().get("results") or []][:limit]
+
+    async def get_episode(self, external_id: str) -> Optional[Dict[str, Any]]:
+        async with httpx.AsyncClient(timeout=REQUEST_TIMEOUT) as client:
+            response = await client.get(f"{self.base_url}/episodes/{external_id}", headers=self._headers())
+            if response.status_code == 404:
+                return None
+            response.raise_for_status()
+        return self._normalize(response.json())
+
+
+class TaddyProvider(EpisodeSearchProvider):
+    """Taddy's GraphQL podcast API."""
+
+    name = "taddy"
+    base_url = "https://api.taddy.org"
+    episode_fields = (
+        "uuid name description audioUrl datePublished duration imageUrl "
+        "podcastSeries { name rssUrl imageUrl }"
+    )
+
+    async def _query(self, query: str, variables: Dict[str, Any]) -> Dict[str, Any]:
+        headers = {"X-USER-ID": settings.taddy_user_id, "X-API-KEY": settings.taddy_api_key}
+        async with httpx.AsyncClient(timeout=REQUEST_TIMEOUT) as client:
+            response = await client.post(self.base_url, json={"query": query, "variables": variables}, headers=headers)
+            response.raise_for_status()
+            body = response.json()
+        if body.get("errors"):
+            raise ValueError(f"Taddy: {body['errors'][0].get('message')}")
+        return body.get("data") or {}
+
+    @staticmethod
+    def _normalize(item: Dict[str, Any]) -> Dict[str, Any]:
+        series = item.get("podcastSeries") or {}
+        published = item.get("datePublished")
+        return {
+            "external_id": item["uuid"],
+            "title": item.get("name"),
+            "description": item.get("description"),
+            "audio_url": item.get("audioUrl"),
+            "published_date": datetime.utcfromtimestamp(published) if published else None,
+            "duration_minutes": _minutes(item.get("duration")),
+            "image_url": item.get("imageUrl") or series.get("imageUrl"),
+            "podcast_title": series.get("name"),
+            "podcast_rss_url": series.get("rssUrl"),
+        }
+
+    async def search(self, query: str, page: int, limit: int) -> List[Dict[str, Any]]:
+        data = await self._query(
+            "query Search($term: String, $page: Int, $limit: Int) {"
+            " search(term: $term, filterForTypes: [PODCASTEPISODE], page: $page, limitPerPage: $limit) {"
+            f" podcastEpisodes {{ {self.episode_fields} }} }} }}",
+            {"term": query, "page": page, "limit": limit},
+        )
+        return [self._normalize(item) for item in (data.get("search") or {}).get("podcastEpisodes") or []]
+
+    async def get_episode(self, external_id: str) -> Optional[Dict[str, Any]]:
+        data = await self._query(
+            f"query Episode($uuid: ID) {{ getPodcastEpisode(uuid: $uuid) {{ {self.episode_fields} }} }}",
+            {"uuid": external_id},
+        )
+        item = data.get("getPodcastEpisode")
+        return self._normalize(item) if item else None
+
+
+PROVIDERS: Dict[str, EpisodeSearchProvider] = {
+    provider.name: provider for provider in (ListenNotesProvider(), TaddyProvider())
+}
+
+
+def get_search_provider() -> Optional[EpisodeSearchProvider]:
+    """The configured provider, None if external search is off."""
+    return PROVIDERS.get(settings.episode_search_provider)
+
+
+async def mark_imported(
+    db: AsyncIOMotorDatabase,
+    results: List[Dict[str, Any]],
+    podcast_ids: Optional[List[str]],
+):
+    """Add episode_id and transcript_status to results already in the library."""
+    ids = {episode_id_for(r["audio_url"]): r for r in results if r.get("audio_url")}
+    query: Dict[str, Any] = {"episode_id": {"$in": list(ids)}, "deleted_at": None}
+    if podcast_ids is not None:
+        query["podcast_id"] = {"$in": podcast_ids}
+    async for episode in db.episodes.find(query, {"episode_id": 1, "transcript_status": 1}):
+        ids[episode["episode_id"]]["episode_id"] = episode["episode_id"]
+        ids[episode["episode_id"]]["transcript_status"] = episode.get("transcript_status")
+
+
+async def import_external_episode(
+    db: AsyncIOMotorDatabase,
+    item: Dict[str, Any],
+    workspace_id: Optional[str] = None,
+) -> Dict[str, Any]:
+    """
+    Create the episode document (and an inactive podcast) for a search result.
+
+    Args:
+        db: Database instance
+        item: Normalized provider episode
+        workspace_id: Caller's workspace
+
+    Returns:
+        The episode document, new or already in the library
+
+    Raises:
+        ExternalEpisodeError: If the item has no audio or feed, or its feed
+            belongs to another workspace
+    """
+    if not item.get("audio_url"):
+        raise ExternalEpisodeError("The search provider has no audio URL for this episode")
+    if not item.get("podcast_rss_url"):
+        raise ExternalEpisodeError("The search provider has no feed URL for this episode's podcast")
+
+    podcast = await BulkTranscribeService(db).resolve_podcast(
+        normalize_url(item["podcast_rss_url"]),
+        {"title": item.get("podcast_title") or "Unknown", "image_url": item.get("image_url")},
+        workspace_id,
+    )
+    if not in_workspace(podcast, workspace_id):
+        raise ExternalEpisodeError("This episode's feed is subscribed in another workspace")
+
+    episode_id = episode_id_for(item["audio_url"])
+    now = datetime.utcnow()
+    await db.episodes.update_one(
+        {"episode_id": episode_id},
+        {"$setOnInsert": {
+            "_id": episode_id,
+            "podcast_id": podcast["podcast_id"],
+            "title": item.get("title"),
+            "description": item.get("description"),
+            "audio_url": item["audio_url"],
+            "published_date": item.get("published_date"),
+            "duration_minutes": item.get("duration_minutes"),
+            "transcript_status": TranscriptStatus.PENDING.value,
+            "external_source": {"provider": settings.episode_search_provider, "external_id": item["external_id"]},
+            "created_at": now,
+            "updated_at": now,
+        }},
+        upsert=True
+    )
+    await cache.invalidate("episodes")
+    return await db.episodes.find_one({"episode_id": episode_id})
@@ -0,0 +1,170 @@
+"""Service for exporting transcripts as ZIP archives."""
+import json
+import logging
+import tempfile
+import zipfile
+from datetime import datetime
+from typing import IO, Any, Dict, List, Optional, Tuple
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.services.ad_detection import strip_ad_segments
+from app.services.s3_service import s3_service
+from app.services.storage_locations import transcript_location
+
+logger = logging.getLogger(__name__)
+
+# Archives larger than this are spooled to a temporary file on disk
+EXPORT_SPOOL_MAX_BYTES = 16 * 1024 * 1024
+
+
+class ExportService:
+    """Builds transcript corpora for researchers pulling data out of the system."""
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+
+    async def find_episodes(
+        self,
+        podcast_ids: Optional[List[str]] = None,
+        published_after: Optional[datetime] = None,
+        published_before: Optional[datetime] = None,
+    ) -> List[Dict[str, Any]]:
+        """
+        Find completed episodes matching the export filters.
+
+        Args:
+            podcast_ids: Podcasts to include (None = all)
+            published_after: Inclusive lower bound on published_date
+            published_before: Exclusive upper bound on published_date
+
+        Returns:
+            Episode documents joined with their podcast
+        """
+        query: Dict[str, Any] = {"transcript_status": "completed", "deleted_at": None}
+        if podcast_ids:
+            query["podcast_id"] = {"$in": podcast_ids}
+
+        date_range: Dict[str, datetime] = {}
+        if published_after:
+            date_range["$gte"] = published_after
+        if published_before:
+            date_range["$lt"] = published_before
+        if date_range:
+            query["published_date"] = date_range
+
+        pipeline = [
+            {"$match": query},
+            {"$sort": {"podcast_id": 1, "published_date": 1}},
+            {
+                "$lookup": {
+                    "from": "podcasts",
+                    "localField": "podcast_id",
+                    "foreignField": "podcast_id",
+                    "as": "podcast"
+                }
+            },
+            {"$unwind": {"path": "$podcast", "preserveNullAndEmptyArrays": True}}
+        ]
+
+        return await self.db.episodes.aggregate(pipeline).to_list(length=None)
+
+    async def build_archive(
+        self,
+        episodes: List[Dict[str, Any]],
+        export_format: str,
+        filters: Dict[str, Any],
+        exclude_ads: bool = False,
+    ) -> Tuple[IO[bytes], int]:
+        """
+        Build a ZIP archive with one transcript file per episode and a manifest.
+
+        The archive is written to a temporary file, kept in memory only while
+        small; the caller reads it from the start and closes it.
+
+        Args:
+            episodes: Episode documents from find_episodes
+            export_format: "txt" or "json"
+            filters: Request filters, recorded in the manifest
+            exclude_ads: Remove detected ad/sponsor segments from transcripts
+
+        Returns:
+            Tuple of (archive file, number of transcripts included)
+        """
+        spool = tempfile.SpooledTemporaryFile(max_size=EXPORT_SPOOL_MAX_BYTES)
+        manifest_entries = []
+        missing = []
+
+        try:
+            with zipfile.ZipFile(spool, "w", compression=zipfile.ZIP_DEFLATED) as archive:
+                for episode in episodes:
+                    episode_id = episode["episode_id"]
+                    transcript_text = await self._load_transcript(episode)
+                    if not transcript_text:
+                        missing.append(episode_id)
+                        continue
+                    if exclude_ads:
+                        transcript_text = strip_ad_segments(transcript_text, episode.get("ad_segments") or [])
+
+                    podcast = episode.get("podcast") or {}
+                    published_date = episode.get("published_date")
+                    file_name = f"{episode['podcast_id']}/{episode_id}.{export_format}"
+
+                    if export_format == "json":
+                        content = json.dumps({
+                            "episode_id": episode_id,
+                            "podcast_id": episode["podcast_id"],
+                            "podcast_title": podcast.get("title"),
+                            "title": episode.get("title"),
+                            "published_date": published_date.isoformat() if published_date else None,
+                            "transcript": transcript_text,
+                        }, indent=2)
+                    else:
+                        content = transcript_text
+
+                    archive.writestr(file_name, content)
+                    manifest_entries.append({
+                        "episode_id": episode_id,
+                        "podcast_id": episode["podcast_id"],
+                        "podcast_title": podcast.get("title"),
+                        "title": episode.get("title"),
+                        "published_date": published_date.isoformat() if published_date else None,
+                        "audio_url": episode.get("audio_url"),
+                        "file": file_name,
+                        "word_count": len(transcript_text.split()),
+                    })
+
+                manifest = {
+                    "generated_at": datetime.utcnow().isoformat(),
+                    "format": export_format,
+                    "filters": filters,
+                    "ads_excluded": exclude_ads,
+                    "episode_count": len(manifest_entries),
+                    "episodes": manifest_entries,
+                    "missing_transcripts": missing,
+                }
+                archive.writestr("manifest.json", json.dumps(manifest, indent=2, default=str))
+        except BaseException:
+            spool.close()
+            raise
+
+        if missing:
+            logger.warning(f"Export skipped {len(missing)} episodes with missing transcripts")
+
+        spool.seek(0)
+        return spool, len(manifest_entries)
+
+    async def _load_transcript(self, episode: Dict[str, Any]) -> Optional[str]:
+        """Load transcript text from S3, falling back to MongoDB."""
+        transcript_s3_key = episode.get("transcript_s3_key")
+        if transcript_s3_key:
+            try:
+                transcript_text = await s3_service.get_transcript(
+                    transcript_s3_key, **transcript_location(episode.get("storage"))
+                )
+                if transcript_text:
+                    return transcript_text
+            except Exception as e:
+                logger.error(f"Failed to fetch transcript {transcript_s3_key} for export: {e}")
+
+        return episode.get("transcript_text")
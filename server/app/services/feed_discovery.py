@@ -0,0 +1,127 @@
+"""Find a podcast's RSS feed from its website.
+
+Users rarely have the raw feed URL handy. Podcast websites advertise their
+feeds with <link rel="alternate" type="application/rss+xml"> tags; when a
+page has none, a few conventional feed paths are tried. Every candidate is
+fetched and only feeds with audio episodes are returned.
+"""
+import asyncio
+import logging
+from html.parser import HTMLParser
+from typing import Any, Dict, List, Optional
+from urllib.parse import urljoin
+
+import aiohttp
+
+from app.config import settings
+from app.services.rss_parser import parse_rss_feed
+from app.url_normalization import normalize_url
+
+logger = logging.getLogger(__name__)
+
+FEED_TYPES = {"application/rss+xml", "application/atom+xml", "application/xml", "text/xml"}
+
+# Tried when the page links no feeds
+CONVENTIONAL_FEED_PATHS = ["/feed", "/rss", "/feed.xml", "/podcast.xml"]
+
+# Pages larger than this are truncated before looking for <link> tags
+MAX_PAGE_BYTES = 2 * 1024 * 1024
+
+# Candidates fetched to check they are podcast feeds
+MAX_CANDIDATES = 5
+
+
+class _FeedLinkParser(HTMLParser):
+    """Collects <link rel="alternate"> feed URLs and titles from a page."""
+
+    def __init__(self, base_url: str):
+        super().__init__()
+        self.base_url = base_url
+        self.links: List[Dict[str, Optional[str]]] = []
+
+    def handle_starttag(self, tag: str, attrs):
+        if tag == "base":
+            href = dict(attrs).get("href")
+            if href:
+                self.base_url = urljoin(self.base_url, href)
+            return
+        if tag != "link":
+            return
+        attributes = {k: v or "" for k, v in attrs}
+        rel = attributes.get("rel", "").lower().split()
+        link_type = attributes.get("type", "").lower().split(";")[0].strip()
+        href = attributes.get("href")
+        if "alternate" in rel and link_type in FEED_TYPES and href:
+            self.links.append({
+                "url": urljoin(self.base_url, href),
+                "title": attributes.get("title") or None,
+            })
+
+
+async def _fetch_page(url: str) -> str:
+    """Fetch the start of a web page."""
+    async with aiohttp.ClientSession() as session:
+        timeout = aiohttp.ClientTimeout(total=settings.rss_fetch_timeout_seconds)
+        async with session.get(url, timeout=timeout) as response:
+            if response.status != 200:
+                raise ValueError(f"HTTP {response.status}: Failed to fetch page")
+            body = await response.content.read(MAX_PAGE_BYTES)
+            return body.decode(response.charset or "utf-8", errors="replace")
+
+
+async def _check_candidate(url: str, link_title: Optional[str]) -> Optional[Dict[str, Any]]:
+    """Fetch a candidate feed; None unless it is a podcast feed (has audio episodes)."""
+    try:
+        podcast_data, episodes = await parse_rss_feed(url)
+    except Exception as e:
+        logger.debug(f"Feed candidate {url} rejected: {e}")
+        return None
+
+    audio_episodes = sum(1 for ep in episodes if ep.get("audio_url"))
+    if not audio_episodes:
+        return None
+    return {
+        "url": url,
+        "title": podcast_data.get("title") or link_title,
+        "episode_count": audio_episodes,
+    }
+
+
+async def discover_podcast_feeds(page_url: str) -> List[Dict[str, Any]]:
+    """
+    Find podcast feeds linked from a web page.
+
+    Args:
+        page_url: Podcast website URL
+
+    Returns:
+        Podcast feeds (url, title, episode_count), most episodes first;
+        empty if the page can't be fetched or links no podcast feed
+    """
+    try:
+        page = await _fetch_page(page_url)
+    except Exception as e:
+        logger.info(f"Feed discovery could not fetch {page_url}: {e}")
+        return []
+
+    parser = _FeedLinkParser(page_url)
+    try:
+        parser.feed(page)
+    except Exception as e:
+        logger.info(f"Feed discovery could not parse {page_url}: {e}")
+
+    links = parser.links or [
+        {"url": urljoin(page_url, path), "title": None} for path in CONVENTIONAL_FEED_PATHS
+    ]
+
+    # Same feed linked twice (e.g. with and without a tracking prefix)
+    unique: Dict[str, Optional[str]] = {}
+    for link in links:
+        unique.setdefault(normalize_url(link["url"]), link["title"])
+    candidates = list(unique.items())[:MAX_CANDIDATES]
+
+    results = await asyncio.gather(*(_check_candidate(url, title) for url, title in candidates))
+    feeds = [feed for feed in results if feed]
+    feeds.sort(key=lambda feed: feed["episode_count"], reverse=True)
+    logger.info(f"Discovered {len(feeds)} podcast feed(s) on {page_url}")
+    return feeds
@@ -0,0 +1,270 @@
+"""
+Index of the people appearing in episodes, across podcasts.
+
+People come from two places: the <podcast:person> tags of a feed item
+(stored on the episode as "persons" by the poll Lambda and rss_parser.py),
+and the transcript, where named speaker labels ("Jane Doe: ...") and
+introductions ("my guest today is Jane Doe", "joined by Jane Doe") name
+the people in the conversation. The transcript pass is a rule-based name
+extractor, not a statistical NER model: it favours precision, so guests
+only mentioned in passing are not picked up.
+
+Each person/episode pair is a guest_appearances document; the guests
+collection holds one document per person (names are matched case- and
+accent-insensitively) with their appearance count. A background run every
+GUEST_INDEX_INTERVAL_HOURS indexes episodes added or re-transcribed since
+the last run and drops deleted ones.
+"""
+import asyncio
+import logging
+import re
+import unicodedata
+from collections import Counter
+from datetime import datetime
+from typing import Any, Dict, Iterable, List, Optional
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.models.schemas import TranscriptStatus
+from app.services.s3_service import s3_service
+from app.services.storage_locations import transcript_location
+
+logger = logging.getLogger(__name__)
+
+# Two or three capitalised words ("Jane Doe", "Mary-Kate O'Brien", "Ian McKay")
+NAME_WORD = r"(?:[A-Z]'[A-Z][a-z]+|Ma?c[A-Z][a-z]+|[A-Z][a-z]+)(?:-[A-Z][a-z]+)?"
+NAME = rf"{NAME_WORD}(?: {NAME_WORD}){{1,2}}"
+
+# "Jane Doe:" at the start of a paragraph
+SPEAKER_LABEL = re.compile(rf"^({NAME}):\s", re.MULTILINE)
+
+# Phrases that introduce the person named right after them
+INTRODUCTION = re.compile(
+    r"\b(?:[Mm]y guests? (?:today |this week )?(?:is|are)|[Oo]ur guests? (?:today |this week )?(?:is|are)|"
+    r"joined (?:today )?by|[Pp]lease welcome|[Ww]elcome(?: back)?(?: to the show)?,?|"
+    r"[Ii]'m here with|[Ii]'m talking (?:with|to)|[Ii]nterview with|[Ss]peaking with|"
+    r"[Cc]onversation with)\s+"
+    rf"({NAME})"
+)
+
+# Capitalised phrases the introduction patterns catch that aren't people
+NOT_NAMES = frozenset({"the show", "the podcast", "back everyone", "everybody welcome"})
+
+# Where a person's name came from
+SOURCE_FEED = "feed"
+SOURCE_TRANSCRIPT = "transcript"
+
+
+def name_key(name: str) -> str:
+    """Matching key of a name: lowercase, without accents and punctuation."""
+    decomposed = unicodedata.normalize("NFKD", name)
+    ascii_name = "".join(c for c in decomposed if not unicodedata.combining(c))
+    return " ".join(re.sub(r"[^\w\s]", " ", ascii_name.lower()).split())
+
+
+def extract_transcript_people(text: str, hosts: Iterable[str] = ()) -> Dict[str, Dict[str, Any]]:
+    """
+    People a transcript names as speakers or introduces.
+
+    Args:
+        text: Transcript text
+        hosts: Names to leave out (the episode's hosts)
+
+    Returns:
+        Name key to {"name", "mentions"}
+    """
+    host_keys = {name_key(host) for host in hosts}
+    found: Dict[str, Dict[str, Any]] = {}
+    for match in list(SPEAKER_LABEL.finditer(text)) + list(INTRODUCTION.finditer(text)):
+        name = match.group(1)
+        key = name_key(name)
+        if key in host_keys or key in NOT_NAMES or key in found:
+            continue
+        found[key] = {"name": name, "mentions": 0}
+    if found:
+        counts = Counter(name_key(m) for m in re.findall(NAME, text))
+        for key, person in found.items():
+            person["mentions"] = counts.get(key, 1)
+    return found
+
+
+class GuestIndexService:
+    """Builds and queries the guest appearance index."""
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+        self.guests_collection = db.guests
+        self.appearances_collection = db.guest_appearances
+        self.state_collection = db.guest_index_state
+
+    async def index_pending(self) -> int:
+        """
+        Index episodes added or changed since they were last indexed, and
+        drop the appearances of deleted episodes.
+
+        Returns:
+            Number of episodes indexed
+        """
+        indexed_versions = {
+            doc["_id"]: doc.get("episode_updated_at")
+            async for doc in self.state_collection.find({}, {"episode_updated_at": 1})
+        }
+        indexed = 0
+        cursor = self.db.episodes.find(
+            {"deleted_at": None},
+            {"episode_id": 1, "podcast_id": 1, "title": 1, "persons": 1, "published_date": 1, "created_at": 1,
+             "transcript_status": 1, "transcript_s3_key": 1, "updated_at": 1}
+        )
+        async for episode in cursor:
+            if episode["_id"] in indexed_versions:
+                if indexed_versions.pop(episode["_id"]) == episode.get("updated_at"):
+                    continue
+            try:
+                await self.index_episode(episode)
+                indexed += 1
+            except Exception as e:
+                logger.warning(f"Failed to index guests of episode {episode.get('episode_id')}: {e}")
+
+        for episode_key in indexed_versions:
+            state = await self.state_collection.find_one_and_delete({"_id": episode_key})
+            if state and state.get("episode_id"):
+                await self._replace_appearances(state["episode_id"], [])
+        if indexed or indexed_versions:
+            logger.info(f"Guest index: indexed {indexed} episode(s), dropped {len(indexed_versions)}")
+        return indexed
+
+    async def index_episode(self, episode: Dict[str, Any]):
+        """Record the people of one episode, replacing what was recorded before."""
+        people: Dict[str, Dict[str, Any]] = {}
+        for person in episode.get("persons") or []:
+            key = name_key(person.get("name") or "")
+            if key and key not in people:
+                people[key] = {
+                    "name": person["name"],
+                    "role": person.get("role") or "host",
+                    "sources": [SOURCE_FEED],
+                    "mentions": 0,
+                }
+
+        if episode.get("transcript_status") == TranscriptStatus.COMPLETED.value:
+            text = await self._transcript_text(episode)
+            hosts = [p["name"] for p in (episode.get("persons") or []) if p.get("role") == "host"]
+            for key, person in extract_transcript_people(text or "", hosts).items():
+                if key in people:
+                    people[key]["sources"].append(SOURCE_TRANSCRIPT)
+                    people[key]["mentions"] = person["mentions"]
+                else:
+                    people[key] = {**person, "role": "guest", "sources": [SOURCE_TRANSCRIPT]}
+
+        now = datetime.utcnow()
+        appearances = [
+            {
+                "name_key": key,
+                "name": person["name"],
+                "role": person["role"],
+                "sources": person["sources"],
+                "mentions": person["mentions"],
+                "episode_id": episode["episode_id"],
+                "podcast_id": episode.get("podcast_id"),
+                "episode_title": episode.get("title"),
+                "published_date": episode.get("published_date") or episode.get("created_at"),
+                "indexed_at": now,
+            }
+            for key, person in people.items()
+        ]
+        await self._replace_appearances(episode["episode_id"], appearances)
+        await self.state_collection.replace_one(
+            {"_id": episode["_id"]},
+            {"episode_id": episode["episode_id"], "episode_updated_at": episode.get("updated_at"), "indexed_at": now},
+            upsert=True
+        )
+
+    async def appearances(
+        self,
+        name: str,
+        podcast_ids: Optional[List[str]] = None,
+        limit: int = 50,
+        offset: int = 0,
+    ) -> Dict[str, Any]:
+        """
+        A person's appearances, newest first.
+
+        Args:
+            name: Person's name (matched case- and accent-insensitively)
+            podcast_ids: Podcasts to include (None = all)
+            limit: Maximum appearances to return
+            offset: Appearances to skip
+
+        Returns:
+            The person's name as indexed, appearances and their total
+        """
+        query: Dict[str, Any] = {"name_key": name_key(name)}
+        if podcast_ids is not None:
+            query["podcast_id"] = {"$in": podcast_ids}
+        guest = await self.guests_collection.find_one({"name_key": query["name_key"]})
+        total = await self.appearances_collection.count_documents(query)
+        cursor = self.appearances_collection.find(query, {"_id": 0}).sort(
+            [("published_date", -1), ("episode_id", 1)]
+        ).skip(offset).limit(limit)
+        appearances = await cursor.to_list(length=limit)
+
+        titles = {}
+        podcast_ids_found = list({a["podcast_id"] for a in appearances if a.get("podcast_id")})
+        if podcast_ids_found:
+            cursor = self.db.podcasts.find({"podcast_id": {"$in": podcast_ids_found}}, {"podcast_id": 1, "title": 1})
+            async for podcast in cursor:
+                titles[podcast["podcast_id"]] = podcast.get("title")
+        for appearance in appearances:
+            appearance["podcast_title"] = titles.get(appearance.get("podcast_id"))
+        return {"name": guest["name"] if guest else name, "appearances": appearances, "total": total}
+
+    async def _transcript_text(self, episode: Dict[str, Any]) -> Optional[str]:
+        text = None
+        if episode.get("transcript_s3_key"):
+            text = await s3_service.get_transcript(
+                episode["transcript_s3_key"], **transcript_location(episode.get("storage"))
+            )
+        if not text:
+            stored = await self.db.episodes.find_one({"_id": episode["_id"]}, {"transcript_text": 1})
+            text = (stored or {}).get("transcript_text")
+        return text
+
+    async def _replace_appearances(self, episode_id: str, appearances: List[Dict[str, Any]]):
+        """Swap an episode's appearances and refresh the guests they touch."""
+        previous = await self.appearances_collection.distinct("name_key", {"episode_id": episode_id})
+        await self.appearances_collection.delete_many({"episode_id": episode_id})
+        if appearances:
+            await self.appearances_collection.insert_many(appearances)
+
+        names = {a["name_key"]: a["name"] for a in appearances}
+        for key in set(previous) | set(names):
+            count = await self.appearances_collection.count_documents({"name_key": key})
+            if not count:
+                await self.guests_collection.delete_one({"name_key": key})
+                continue
+            update: Dict[str, Any] = {
+                "$set": {"appearance_count": count, "updated_at": datetime.utcnow()},
+                "$setOnInsert": {"name_key": key},
+            }
+            if key in names:
+                # The most recently indexed spelling names the person
+                update["$set"]["name"] = names[key]
+            await self.guests_collection.update_one({"name_key": key}, update, upsert=True)
+
+
+async def run_guest_index_scheduler(get_db, interval_hours: int):
+    """
+    Periodically index the people of new and re-transcribed episodes until cancelled.
+
+    Args:
+        get_db: Callable returning the database instance
+        interval_hours: Hours between index runs
+    """
+    logger.info(f"Starting guest index scheduler (every {interval_hours} hours)")
+    while True:
+        try:
+            await GuestIndexService(get_db()).index_pending()
+        except Exception as e:
+            logger.error(f"Guest index run failed: {e}")
+        # Runs on startup too, so a new deployment doesn't wait a full interval
+        await asyncio.sleep(interval_hours * 3600)
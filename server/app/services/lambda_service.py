@@ -1,11 +1,23 @@
 """Lambda service for triggering Lambda functions via HTTP."""
 import logging
+from datetime import datetime, timezone
 from typing import Optional, Dict, Any
 import httpx
 from app.config import settings
 
 logger = logging.getLogger(__name__)
 
+# Replays read many archived snapshots, so they get longer than a poll
+POLL_TIMEOUT = 60.0
+REPLAY_TIMEOUT = 300.0
+
+
+def _rfc3339(value: datetime) -> str:
+    """Format a datetime for the poll Lambda; naive values are UTC."""
+    if value.tzinfo is None:
+        value = value.replace(tzinfo=timezone.utc)
+    return value.isoformat()
+
 
 class LambdaService:
     """Service for interacting with Lambda HTTP services."""
@@ -14,13 +26,21 @@ class LambdaService:
         """Initialize Lambda service."""
         self.poll_lambda_url = settings.poll_lambda_url
 
-    async def invoke_poll_lambda(self, podcast_id: Optional[str] = None) -> Dict[str, Any]:
+    async def invoke_poll_lambda(
+        self,
+        podcast_id: Optional[str] = None,
+        replay_from: Optional[datetime] = None,
+        replay_to: Optional[datetime] = None
+    ) -> Dict[str, Any]:
         """
         Invoke the RSS polling Lambda function via HTTP.
 
         Args:
             podcast_id: Optional podcast ID to poll specific podcast.
                        If None, polls all active podcasts.
+            replay_from: Re-process the podcast's archived feed snapshots
+                taken from this time instead of its live feed
+            replay_to: End of the replay window (default now)
 
         Returns:
             Lambda response payload
@@ -36,9 +56,15 @@ class LambdaService:
                 logger.info(f"Invoking poll Lambda for podcast: {podcast_id}")
             else:
                 logger.info("Invoking poll Lambda for all podcasts")
+            if replay_from:
+                payload["replay_from"] = _rfc3339(replay_from)
+                if replay_to:
+                    payload["replay_to"] = _rfc3339(replay_to)
+                logger.info(f"Replaying feed snapshots from {payload['replay_from']}")
 
             # Invoke the Lambda function via HTTP
-            async with httpx.AsyncClient(timeout=60.0) as client:
+            timeout = REPLAY_TIMEOUT if replay_from else POLL_TIMEOUT
+            async with httpx.AsyncClient(timeout=timeout) as client:
                 response = await client.post(
                     f"{self.poll_lambda_url}/invoke",
                     json=payload
@@ -0,0 +1,395 @@
+"""Email notifications for newly transcribed episodes.
+
+Every digest is also kept: its Markdown and HTML renderings are stored in
+S3 under digests/ and recorded in the digests collection, so past digests
+can be fetched through the API whether or not the email arrived. With
+workspaces enabled, preferences and digests belong to a workspace and a
+digest only covers that workspace's podcasts.
+"""
+import asyncio
+import logging
+import smtplib
+import uuid
+from datetime import datetime, timedelta
+from email.message import EmailMessage
+from html import escape
+from typing import Any, Dict, List, Optional
+
+import boto3
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.config import settings
+from app.services.s3_service import s3_service
+from app.services.workspace_service import DEFAULT_WORKSPACE_ID
+from app.workspaces import scoped, stamp, workspace_podcast_ids
+
+logger = logging.getLogger(__name__)
+
+# Minimum time between digests for each frequency
+DIGEST_INTERVALS = {
+    "immediate": timedelta(0),
+    "daily": timedelta(days=1),
+    "weekly": timedelta(weeks=1),
+}
+
+# Summaries longer than this are truncated in digest emails
+DIGEST_SUMMARY_MAX_CHARS = 600
+
+# Stored digests live under this prefix of the transcripts bucket
+DIGEST_PREFIX = "digests/"
+
+DIGEST_STYLE = (
+    "body{font-family:Georgia,serif;max-width:42em;margin:2em auto;padding:0 1em;line-height:1.6}"
+    ".podcast{color:#666;font-size:0.9em}"
+)
+
+
+class EmailNotifier:
+    """Sends plain-text emails through SMTP or Amazon SES."""
+
+    def __init__(self):
+        self.backend = settings.email_backend.lower()
+        self._ses_client = None
+
+    @property
+    def enabled(self) -> bool:
+        """Whether an email backend is configured."""
+        return self.backend in ("smtp", "ses")
+
+    async def send(self, recipient: str, subject: str, body: str, html: Optional[str] = None) -> bool:
+        """
+        Send an email.
+
+        Args:
+            recipient: Destination address
+            subject: Message subject
+            body: Plain-text body
+            html: HTML alternative to the body
+
+        Returns:
+            True if the message was handed to the backend, False otherwise
+        """
+        if not self.enabled:
+            logger.warning("Email backend not configured; skipping notification")
+            return False
+
+        try:
+            if self.backend == "ses":
+                await asyncio.to_thread(self._send_ses, recipient, subject, body, html)
+            else:
+                await asyncio.to_thread(self._send_smtp, recipient, subject, body, html)
+            logger.info(f"Sent email '{subject}' to {recipient} via {self.backend}")
+            return True
+        except Exception as e:
+            logger.error(f"Failed to send email to {recipient}: {e}")
+            return False
+
+    def _send_smtp(self, recipient: str, subject: str, body: str, html: Optional[str] = None):
+        """Send a message through the configured SMTP server."""
+        message = EmailMessage()
+        message["From"] = settings.email_from
+        message["To"] = recipient
+        message["Subject"] = subject
+        message.set_content(body)
+        if html:
+            message.add_alternative(html, subtype="html")
+
+        with smtplib.SMTP(settings.smtp_host, settings.smtp_port, timeout=30) as smtp:
+            if settings.smtp_use_tls:
+                smtp.starttls()
+            if settings.smtp_username:
+                smtp.login(settings.smtp_username, settings.smtp_password)
+            smtp.send_message(message)
+
+    def _send_ses(self, recipient: str, subject: str, body: str, html: Optional[str] = None):
+        """Send a message through Amazon SES."""
+        if self._ses_client is None:
+            self._ses_client = boto3.client("ses", region_name=settings.aws_region)
+
+        message_body = {"Text": {"Data": body}}
+        if html:
+            message_body["Html"] = {"Data": html}
+        self._ses_client.send_email(
+            Source=settings.email_from,
+            Destination={"ToAddresses": [recipient]},
+            Message={
+                "Subject": {"Data": subject},
+                "Body": message_body,
+            },
+        )
+
+
+class NotificationService:
+    """Builds and delivers transcription digests according to user preferences."""
+
+    def __init__(self, db: AsyncIOMotorDatabase, notifier: Optional[EmailNotifier] = None):
+        self.db = db
+        self.preferences_collection = db.notification_preferences
+        self.digests_collection = db.digests
+        self.notifier = notifier or email_notifier
+
+    async def send_due_digests(self, workspace_id: Optional[str] = None) -> Dict[str, int]:
+        """
+        Send digests to every user whose frequency interval has elapsed.
+
+        Args:
+            workspace_id: Only send the workspace's digests (default: all)
+
+        Returns:
+            Counts of digests sent and users skipped
+        """
+        now = datetime.utcnow()
+        sent = 0
+        skipped = 0
+
+        cursor = self.preferences_collection.find(scoped({"email_enabled": True}, workspace_id))
+        async for preferences in cursor:
+            interval = DIGEST_INTERVALS.get(preferences.get("frequency", "daily"), DIGEST_INTERVALS["daily"])
+            last_notified_at = preferences.get("last_notified_at")
+            if last_notified_at and now - last_notified_at < interval:
+                skipped += 1
+                continue
+
+            if await self.send_digest(preferences, now):
+                sent += 1
+            else:
+                skipped += 1
+
+        logger.info(f"Digest run complete: {sent} sent, {skipped} skipped")
+        return {"sent": sent, "skipped": skipped}
+
+    async def send_digest(self, preferences: Dict[str, Any], now: Optional[datetime] = None) -> bool:
+        """
+        Send a digest of episodes transcribed since the user's last digest.
+
+        Args:
+            preferences: Notification preferences document
+            now: Cut-off time for the digest (default: current time)
+
+        Returns:
+            True if a digest was sent
+        """
+        now = now or datetime.utcnow()
+        since = preferences.get("last_notified_at") or preferences.get("updated_at") or now - timedelta(days=1)
+        workspace_id = self._preferences_workspace(preferences)
+        episodes = await self._find_new_transcripts(preferences.get("podcast_ids"), since, now, workspace_id)
+        if not episodes:
+            return False
+
+        subject, body = self._render_digest(episodes)
+        html = self._render_digest_html(subject, episodes)
+        digest = await self._store_digest(preferences, episodes, since, now, subject, html, workspace_id)
+        delivered = await self.notifier.send(preferences["email"], subject, body, html)
+        await self.digests_collection.update_one(
+            {"digest_id": digest["digest_id"]},
+            {"$set": {"delivered": delivered}}
+        )
+        if not delivered:
+            return False
+
+        await self.preferences_collection.update_one(
+            {"email": preferences["email"]},
+            {"$set": {"last_notified_at": now}}
+        )
+        return True
+
+    async def list_digests(
+        self,
+        email: str,
+        limit: int = 20,
+        workspace_id: Optional[str] = None
+    ) -> List[Dict[str, Any]]:
+        """A user's stored digests, newest first."""
+        cursor = self.digests_collection.find(
+            scoped({"email": email}, workspace_id), {"_id": 0}
+        ).sort("created_at", -1).limit(limit)
+        return await cursor.to_list(length=limit)
+
+    async def get_digest(
+        self,
+        email: str,
+        digest_id: str,
+        workspace_id: Optional[str] = None
+    ) -> Optional[Dict[str, Any]]:
+        return await self.digests_collection.find_one(
+            scoped({"email": email, "digest_id": digest_id}, workspace_id), {"_id": 0}
+        )
+
+    @staticmethod
+    def _preferences_workspace(preferences: Dict[str, Any]) -> Optional[str]:
+        """The workspace preferences belong to (None when workspaces are disabled)."""
+        if not settings.workspaces_enabled:
+            return None
+        return preferences.get("workspace_id") or DEFAULT_WORKSPACE_ID
+
+    async def _store_digest(
+        self,
+        preferences: Dict[str, Any],
+        episodes: List[Dict[str, Any]],
+        since: datetime,
+        until: datetime,
+        subject: str,
+        html: str,
+        workspace_id: Optional[str] = None,
+    ) -> Dict[str, Any]:
+        """Store a digest's renderings in S3 and record it; S3 failures only lose the copies."""
+        digest_id = f"dig_{uuid.uuid4().hex[:12]}"
+        keys = {}
+        for fmt, content, content_type in (
+            ("markdown", self._render_digest_markdown(subject, episodes), "text/markdown; charset=utf-8"),
+            ("html", html, "text/html; charset=utf-8"),
+        ):
+            key = f"{DIGEST_PREFIX}{digest_id}.{'md' if fmt == 'markdown' else 'html'}"
+            if await s3_service.upload_bytes(key, content.encode("utf-8"), content_type):
+                keys[fmt] = key
+            else:
+                logger.warning(f"Failed to store {fmt} copy of digest {digest_id}")
+
+        digest = {
+            "digest_id": digest_id,
+            "email": preferences["email"],
+            "frequency": preferences.get("frequency", "daily"),
+            "subject": subject,
+            "period_start": since,
+            "period_end": until,
+            "episode_count": len(episodes),
+            "episode_ids": [episode["episode_id"] for episode in episodes],
+            "markdown_s3_key": keys.get("markdown"),
+            "html_s3_key": keys.get("html"),
+            "delivered": False,
+            "created_at": datetime.utcnow(),
+        }
+        await self.digests_collection.insert_one(stamp(digest, workspace_id))
+        digest.pop("_id", None)
+        return digest
+
+    async def _find_new_transcripts(
+        self,
+        podcast_ids: Optional[List[str]],
+        since: datetime,
+        until: datetime,
+        workspace_id: Optional[str] = None,
+    ) -> List[Dict[str, Any]]:
+        """Find episodes of the workspace's subscribed podcasts transcribed in the given window."""
+        if podcast_ids is None:
+            subscribed = await self.db.podcasts.find(
+                scoped({"active": True}, workspace_id), {"podcast_id": 1}
+            ).to_list(length=None)
+            podcast_ids = [p["podcast_id"] for p in subscribed]
+        else:
+            # Preferences may name podcasts of other workspaces
+            allowed = await workspace_podcast_ids(self.db, workspace_id)
+            if allowed is not None:
+                podcast_ids = [podcast_id for podcast_id in podcast_ids if podcast_id in allowed]
+
+        if not podcast_ids:
+            return []
+
+        pipeline = [
+            {
+                "$match": {
+                    "podcast_id": {"$in": podcast_ids},
+                    "transcript_status": "completed",
+                    "processed_at": {"$gt": since, "$lte": until},
+                }
+            },
+            {"$sort": {"processed_at": 1}},
+            {
+                "$lookup": {
+                    "from": "podcasts",
+                    "localField": "podcast_id",
+                    "foreignField": "podcast_id",
+                    "as": "podcast"
+                }
+            },
+            {"$unwind": {"path": "$podcast", "preserveNullAndEmptyArrays": True}}
+        ]
+        return await self.db.episodes.aggregate(pipeline).to_list(length=None)
+
+    @staticmethod
+    def _render_digest(episodes: List[Dict[str, Any]]) -> tuple:
+        """Render the digest subject and plain-text body."""
+        base_url = settings.public_base_url.rstrip("/")
+        count = len(episodes)
+        subject = f"{count} new transcript{'s' if count != 1 else ''} available"
+
+        lines = [f"{subject}:", ""]
+        for episode in episodes:
+            podcast_title = (episode.get("podcast") or {}).get("title", "Unknown Podcast")
+            lines.append(f"{podcast_title} - {episode.get('title', 'Untitled Episode')}")
+
+            summary = episode.get("summary") or episode.get("description")
+            if summary:
+                if len(summary) > DIGEST_SUMMARY_MAX_CHARS:
+                    summary = summary[:DIGEST_SUMMARY_MAX_CHARS].rsplit(" ", 1)[0] + "..."
+                lines.append(summary)
+
+            lines.append(f"Transcript: {base_url}/api/episodes/{episode['episode_id']}/transcript")
+            lines.append("")
+
+        return subject, "\n".join(lines)
+
+    @staticmethod
+    def _digest_entries(episodes: List[Dict[str, Any]]) -> List[Dict[str, str]]:
+        """Podcast, title, truncated summary and transcript link of each episode."""
+        base_url = settings.public_base_url.rstrip("/")
+        entries = []
+        for episode in episodes:
+            summary = episode.get("summary") or episode.get("description") or ""
+            if len(summary) > DIGEST_SUMMARY_MAX_CHARS:
+                summary = summary[:DIGEST_SUMMARY_MAX_CHARS].rsplit(" ", 1)[0] + "..."
+            entries.append({
+                "podcast": (episode.get("podcast") or {}).get("title", "Unknown Podcast"),
+                "title": episode.get("title", "Untitled Episode"),
+                "summary": summary,
+                "url": f"{base_url}/api/episodes/{episode['episode_id']}/transcript.html",
+            })
+        return entries
+
+    @classmethod
+    def _render_digest_markdown(cls, subject: str, episodes: List[Dict[str, Any]]) -> str:
+        lines = [f"# {subject}", ""]
+        for entry in cls._digest_entries(episodes):
+            lines += [f"## {entry['title']}", "", f"*{entry['podcast']}*", ""]
+            if entry["summary"]:
+                lines += [entry["summary"], ""]
+            lines += [f"[Read the transcript]({entry['url']})", ""]
+        return "\n".join(lines)
+
+    @classmethod
+    def _render_digest_html(cls, subject: str, episodes: List[Dict[str, Any]]) -> str:
+        parts = [
+            "<!DOCTYPE html>",
+            f'<html><head><meta charset="utf-8"><title>{escape(subject)}</title>'
+            f"<style>{DIGEST_STYLE}</style></head><body>",
+            f"<h1>{escape(subject)}</h1>",
+        ]
+        for entry in cls._digest_entries(episodes):
+            parts.append(f"<h2>{escape(entry['title'])}</h2>")
+            parts.append(f'<p class="podcast">{escape(entry["podcast"])}</p>')
+            if entry["summary"]:
+                parts.append(f"<p>{escape(entry['summary'])}</p>")
+            parts.append(f'<p><a href="{escape(entry["url"])}">Read the transcript</a></p>')
+        parts.append("</body></html>")
+        return "\n".join(parts)
+
+
+async def run_digest_scheduler(get_db, interval_minutes: int):
+    """
+    Periodically send due digests until cancelled.
+
+    Args:
+        get_db: Callable returning the database instance
+        interval_minutes: Minutes between digest runs
+    """
+    logger.info(f"Starting notification digest scheduler (every {interval_minutes} minutes)")
+    while True:
+        await asyncio.sleep(interval_minutes * 60)
+        try:
+            await NotificationService(get_db()).send_due_digests()
+        except Exception as e:
+            logger.error(f"Notification digest run failed: {e}")
+
+
+# Singleton instance
+email_notifier = EmailNotifier()
@@ -4,14 +4,25 @@ Replaces AWS Step Functions with direct HTTP calls to Lambda services.
 """
 import asyncio
 import logging
+import time
 from typing import Dict, List, Any, Optional
 from datetime import datetime
 
 import httpx
 from pymongo import MongoClient
 
+from app.cache import cache
 from app.config import settings
 from app.database.mongodb import MongoDB
+from app.services.ad_detection import AdDetectionService
+from app.services.asr_jobs import ASRJobService
+from app.services.chat_notifier import chat_notifier
+from app.models.schemas import JobPriority
+from app.services.cost_service import CostService
+from app.services.private_transcripts import episode_encryption
+from app.services.storage_locations import audio_location, episode_storage, pipeline_storage
+from app.services.transcript_progress import chunk_progress
+from app.services.work_queue import transcription_slots
 
 logger = logging.getLogger(__name__)
 
@@ -28,44 +39,73 @@ class OrchestrationService:
         self.chunking_url = settings.chunking_lambda_url
         self.whisper_url = settings.whisper_lambda_url
         self.merge_url = settings.merge_lambda_url
-        self.s3_audio_bucket = settings.s3_audio_bucket
 
     async def transcribe_episode(
         self,
         episode_id: str,
         audio_url: str,
-        max_concurrent_transcriptions: int = 5
+        max_concurrent_transcriptions: int = 5,
+        priority: JobPriority = JobPriority.NORMAL
     ) -> Dict[str, Any]:
         """
         Orchestrate the full transcription workflow for an episode.
 
+        The workflow waits for a shared transcription slot first, so
+        higher-priority episodes overtake queued backfill work. With
+        ASR_PROVIDER set the episode is submitted to the provider instead,
+        and the result arrives later (see app/services/asr_jobs.py).
+
         Args:
             episode_id: Unique identifier for the episode
             audio_url: URL to the audio file
             max_concurrent_transcriptions: Max parallel transcription tasks
+            priority: Queue priority for this episode
 
         Returns:
             Dict with status, transcript_s3_key, and any error messages
         """
+        if settings.asr_provider:
+            return await ASRJobService(MongoDB.get_db()).submit(episode_id, audio_url)
+        async with transcription_slots.slot(priority):
+            return await self._run_workflow(episode_id, audio_url, max_concurrent_transcriptions)
+
+    async def _run_workflow(
+        self,
+        episode_id: str,
+        audio_url: str,
+        max_concurrent_transcriptions: int
+    ) -> Dict[str, Any]:
+        """Run chunking, transcription and merge for an episode."""
         logger.info(f"Starting transcription workflow for episode {episode_id}")
 
         db = MongoDB.get_db()
         episodes_collection = db.episodes
 
         try:
+            # Chunks and transcripts stay in the podcast's storage location
+            storage = await episode_storage(db, episode_id)
+            # Private podcasts' transcripts are encrypted with their workspace's key
+            encryption = await episode_encryption(db, episode_id)
+
             # Update status to processing
-            episodes_collection.update_one(
+            await episodes_collection.update_one(
                 {"episode_id": episode_id},
                 {"$set": {"transcript_status": "processing", "updated_at": datetime.utcnow()}}
             )
+            await cache.invalidate("episodes")
 
             # Step 1: Download and chunk audio
             logger.info(f"Step 1: Chunking audio for episode {episode_id}")
-            episodes_collection.update_one(
+            await episodes_collection.update_one(
                 {"episode_id": episode_id},
-                {"$set": {"processing_step": "chunking", "updated_at": datetime.utcnow()}}
+                {
+                    "$set": {"processing_step": "chunking", "updated_at": datetime.utcnow()},
+                    "$unset": {"chunks_total": "", "chunks_completed": "", "transcript_progress": ""}
+                }
             )
-            chunk_result = await self._call_chunking_lambda(episode_id, audio_url)
+            started = time.monotonic()
+            chunk_result = await self._call_chunking_lambda(episode_id, audio_url, storage)
+            compute_seconds = time.monotonic() - started
 
             if "error" in chunk_result:
                 raise Exception(f"Chunking failed: {chunk_result['error']}")
@@ -80,13 +120,22 @@ class OrchestrationService:
 
             # Step 2: Transcribe each chunk in parallel (with concurrency limit)
             logger.info(f"Step 2: Transcribing {total_chunks} chunks for episode {episode_id}")
-            episodes_collection.update_one(
+            await episodes_collection.update_one(
                 {"episode_id": episode_id},
-                {"$set": {"processing_step": "transcribing", "updated_at": datetime.utcnow()}}
+                {
+                    "$set": {
+                        "processing_step": "transcribing",
+                        "chunks_total": total_chunks,
+                        "chunks_completed": 0,
+                        "transcript_progress": chunk_progress(chunks, set()),
+                        "updated_at": datetime.utcnow()
+                    }
+                }
             )
             transcription_results = await self._transcribe_chunks_parallel(
                 episode_id,
                 chunks,
+                storage,
                 max_concurrent=max_concurrent_transcriptions
             )
 
@@ -97,40 +146,60 @@ class OrchestrationService:
                 raise Exception(f"Transcription failed for chunks: {failed_indices}")
 
             logger.info(f"Successfully transcribed all {total_chunks} chunks")
+            compute_seconds += sum(r.get("elapsed_seconds", 0) for r in transcription_results)
 
             # Step 3: Merge transcripts
             logger.info(f"Step 3: Merging transcripts for episode {episode_id}")
-            episodes_collection.update_one(
+            await episodes_collection.update_one(
                 {"episode_id": episode_id},
                 {"$set": {"processing_step": "merging", "updated_at": datetime.utcnow()}}
             )
+            started = time.monotonic()
             merge_result = await self._call_merge_lambda(
                 episode_id,
                 total_chunks,
-                transcription_results
+                transcription_results,
+                chunks,
+                storage,
+                encryption
             )
+            compute_seconds += time.monotonic() - started
 
             if merge_result.get("status") == "error":
                 raise Exception(f"Merge failed: {merge_result.get('error_message')}")
+            if merge_result.get("intermediates_cleaned"):
+                logger.info(
+                    f"Cleaned up {merge_result['intermediates_cleaned']} intermediate object(s) for episode "
+                    f"{episode_id}, {merge_result.get('reclaimed_bytes', 0)} bytes"
+                )
 
             transcript_s3_key = merge_result.get("transcript_s3_key")
             total_words = merge_result.get("total_words", 0)
 
             # Update episode with success status
-            episodes_collection.update_one(
+            await episodes_collection.update_one(
                 {"episode_id": episode_id},
                 {
                     "$set": {
                         "transcript_status": "completed",
+                        "processing_step": "completed",
                         "transcript_s3_key": transcript_s3_key,
+                        "transcript_sha256": merge_result.get("transcript_sha256"),
+                        "storage": pipeline_storage(storage),
                         "total_words": total_words,
                         "updated_at": datetime.utcnow()
-                    }
+                    },
+                    "$unset": {"transcript_progress": ""}
                 }
             )
 
+            await cache.invalidate("episodes")
             logger.info(f"Transcription completed for episode {episode_id}: {total_words} words")
 
+            await self._record_cost(db, episode_id, chunks, compute_seconds, total_words)
+            await AdDetectionService(db).analyze_completed(episode_id)
+            await self._announce_transcription(db, episode_id)
+
             return {
                 "status": "completed",
                 "episode_id": episode_id,
@@ -144,7 +213,7 @@ class OrchestrationService:
             logger.error(f"Transcription failed for episode {episode_id}: {error_message}")
 
             # Update episode with error status
-            episodes_collection.update_one(
+            await episodes_collection.update_one(
                 {"episode_id": episode_id},
                 {
                     "$set": {
@@ -152,9 +221,11 @@ class OrchestrationService:
                         "processing_step": None,
                         "error_message": error_message,
                         "updated_at": datetime.utcnow()
-                    }
+                    },
+                    "$unset": {"transcript_progress": ""}
                 }
             )
+            await cache.invalidate("episodes")
 
             return {
                 "status": "failed",
@@ -162,16 +233,47 @@ class OrchestrationService:
                 "error_message": error_message
             }
 
+    async def _record_cost(
+        self,
+        db,
+        episode_id: str,
+        chunks: List[Dict[str, Any]],
+        compute_seconds: float,
+        total_words: int
+    ):
+        """Store the actual cost of a completed transcription."""
+        try:
+            audio_seconds = max((c.get("end_time_seconds", 0) for c in chunks), default=0)
+            # Merged transcripts average about six bytes per word
+            await CostService(db).record_episode_cost(
+                episode_id, audio_seconds / 60, compute_seconds, total_words * 6
+            )
+        except Exception as e:
+            logger.warning(f"Failed to record cost for {episode_id}: {e}")
+
+    async def _announce_transcription(self, db, episode_id: str):
+        """Post chat notifications for a completed episode of a flagship podcast."""
+        try:
+            episode = await db.episodes.find_one({"episode_id": episode_id})
+            if not episode:
+                return
+            podcast = await db.podcasts.find_one({"podcast_id": episode.get("podcast_id")})
+            if podcast:
+                await chat_notifier.notify_episode_transcribed(podcast, episode)
+        except Exception as e:
+            logger.warning(f"Failed to announce transcription of {episode_id}: {e}")
+
     async def _call_chunking_lambda(
         self,
         episode_id: str,
-        audio_url: str
+        audio_url: str,
+        storage: Optional[Dict[str, Any]]
     ) -> Dict[str, Any]:
         """Call the chunking Lambda service."""
         payload = {
             "episode_id": episode_id,
             "audio_url": audio_url,
-            "s3_bucket": self.s3_audio_bucket
+            **self._bucket_payload(storage)
         }
 
         async with httpx.AsyncClient(timeout=CHUNKING_TIMEOUT) as client:
@@ -186,15 +288,32 @@ class OrchestrationService:
         self,
         episode_id: str,
         chunks: List[Dict[str, Any]],
+        storage: Optional[Dict[str, Any]],
         max_concurrent: int = 5
     ) -> List[Dict[str, Any]]:
-        """Transcribe chunks in parallel with concurrency limit."""
+        """Transcribe chunks in parallel with concurrency limit.
+
+        Each successful chunk bumps the episode's chunks_completed and
+        transcript_progress so the pipeline endpoint can report progress.
+        """
         semaphore = asyncio.Semaphore(max_concurrent)
+        episodes_collection = MongoDB.get_db().episodes
         results = []
+        completed = set()
 
         async def transcribe_with_semaphore(chunk: Dict[str, Any]) -> Dict[str, Any]:
             async with semaphore:
-                return await self._call_whisper_lambda(episode_id, chunk)
+                result = await self._call_whisper_lambda(episode_id, chunk, storage)
+            if result.get("status") != "error":
+                completed.add(chunk.get("chunk_index"))
+                await episodes_collection.update_one(
+                    {"episode_id": episode_id},
+                    {
+                        "$inc": {"chunks_completed": 1},
+                        "$set": {"transcript_progress": chunk_progress(chunks, completed)}
+                    }
+                )
+            return result
 
         tasks = [transcribe_with_semaphore(chunk) for chunk in chunks]
         results = await asyncio.gather(*tasks, return_exceptions=True)
@@ -217,7 +336,8 @@ class OrchestrationService:
     async def _call_whisper_lambda(
         self,
         episode_id: str,
-        chunk: Dict[str, Any]
+        chunk: Dict[str, Any],
+        storage: Optional[Dict[str, Any]]
     ) -> Dict[str, Any]:
         """Call the Whisper Lambda service for a single chunk."""
         payload = {
@@ -225,22 +345,28 @@ class OrchestrationService:
             "chunk_index": chunk.get("chunk_index"),
             "s3_key": chunk.get("s3_key"),
             "start_time_seconds": chunk.get("start_time_seconds", 0),
-            "s3_bucket": self.s3_audio_bucket
+            **self._bucket_payload(storage)
         }
 
+        started = time.monotonic()
         async with httpx.AsyncClient(timeout=WHISPER_TIMEOUT) as client:
             response = await client.post(
                 f"{self.whisper_url}/invoke",
                 json=payload
             )
             response.raise_for_status()
-            return response.json()
+            result = response.json()
+        result["elapsed_seconds"] = time.monotonic() - started
+        return result
 
     async def _call_merge_lambda(
         self,
         episode_id: str,
         total_chunks: int,
-        transcription_results: List[Dict[str, Any]]
+        transcription_results: List[Dict[str, Any]],
+        chunks: List[Dict[str, Any]],
+        storage: Optional[Dict[str, Any]],
+        encryption: Optional[Dict[str, str]] = None
     ) -> Dict[str, Any]:
         """Call the merge Lambda service."""
         # Format transcripts for merge service
@@ -258,7 +384,15 @@ class OrchestrationService:
             "episode_id": episode_id,
             "total_chunks": total_chunks,
             "transcripts": transcripts,
-            "s3_bucket": self.s3_audio_bucket  # Transcripts are also stored in audio bucket
+            **self._bucket_payload(storage),  # Transcripts are also stored in audio bucket
+            "storage": storage,
+            "encryption": encryption,
+            # Audio chunks, deleted with the chunk transcripts once merged
+            "chunks": [
+                {"chunk_index": chunk.get("chunk_index"), "s3_key": chunk.get("s3_key")}
+                for chunk in chunks
+            ],
+            "cleanup_intermediates": settings.cleanup_intermediates
         }
 
         async with httpx.AsyncClient(timeout=MERGE_TIMEOUT) as client:
@@ -269,6 +403,12 @@ class OrchestrationService:
             response.raise_for_status()
             return response.json()
 
+    @staticmethod
+    def _bucket_payload(storage: Optional[Dict[str, Any]]) -> Dict[str, Any]:
+        """The audio bucket (and region) the Lambdas work in for a storage location."""
+        location = audio_location(storage)
+        return {"s3_bucket": location["bucket"], "s3_region": location.get("region")}
+
 
 # Singleton instance
 _orchestration_service: Optional[OrchestrationService] = None
@@ -0,0 +1,124 @@
+"""
+Private podcasts.
+
+A podcast marked private (PUT /api/podcasts/{id}/privacy) has its
+transcripts encrypted at rest with its workspace's KMS key (set with
+PUT /api/admin/workspaces/{id}/kms-key) and served only through the
+authenticated API: no share links, presigned export links or quote cards,
+and nothing over the unauthenticated gRPC API. Transcripts are stored under
+transcripts/private/<workspace_id>/sha256/<digest>.txt with SSE-KMS, so
+they are shared only with identical transcripts of the same workspace; the
+API, and the merge Lambda for pipeline runs, need kms:GenerateDataKey and
+kms:Decrypt on the key.
+
+Private podcasts need WORKSPACES_ENABLED, since without workspaces the API
+has no authentication. When a podcast is made private its existing
+transcripts are stored again this way in the background; making it public
+again leaves them encrypted.
+"""
+import logging
+from datetime import datetime
+from typing import Dict, Optional
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.models.schemas import TranscriptStatus
+from app.services.s3_service import s3_service
+from app.services.storage_locations import same_bucket, transcript_location
+from app.services.transcript_store import PRIVATE_PREFIX, store_transcript
+from app.services.workspace_service import DEFAULT_WORKSPACE_ID
+
+logger = logging.getLogger(__name__)
+
+
+class PrivateTranscriptError(Exception):
+    """Raised when a private podcast's transcripts can't be encrypted."""
+
+
+async def podcast_encryption(db: AsyncIOMotorDatabase, podcast_id: Optional[str]) -> Optional[Dict[str, str]]:
+    """
+    The workspace ID and KMS key a podcast's transcripts are encrypted
+    with, None if the podcast isn't private.
+
+    Raises:
+        PrivateTranscriptError: If the podcast is private but its workspace has no key
+    """
+    podcast = await db.podcasts.find_one({"podcast_id": podcast_id}, {"private": 1, "workspace_id": 1})
+    if not podcast or not podcast.get("private"):
+        return None
+    workspace_id = podcast.get("workspace_id") or DEFAULT_WORKSPACE_ID
+    workspace = await db.workspaces.find_one({"workspace_id": workspace_id}, {"kms_key_id": 1})
+    kms_key_id = (workspace or {}).get("kms_key_id")
+    if not kms_key_id:
+        raise PrivateTranscriptError(f"Podcast {podcast_id} is private but workspace {workspace_id} has no KMS key")
+    return {"workspace_id": workspace_id, "kms_key_id": kms_key_id}
+
+
+async def episode_encryption(db: AsyncIOMotorDatabase, episode_id: str) -> Optional[Dict[str, str]]:
+    """How a new transcript of an episode is encrypted: its podcast's (see podcast_encryption)."""
+    episode = await db.episodes.find_one({"episode_id": episode_id}, {"podcast_id": 1})
+    return await podcast_encryption(db, episode and episode.get("podcast_id"))
+
+
+async def is_private(db: AsyncIOMotorDatabase, podcast_id: Optional[str]) -> bool:
+    """Whether a podcast is private."""
+    return bool(await db.podcasts.find_one({"podcast_id": podcast_id, "private": True}, {"_id": 1}))
+
+
+async def encrypt_existing(db: AsyncIOMotorDatabase, podcast_id: str) -> Dict[str, int]:
+    """
+    Store a newly private podcast's transcripts again, encrypted. The
+    plaintext copy is deleted unless another episode still uses it.
+    Archived transcripts are left as they are.
+
+    Returns:
+        Counts of encrypted and failed transcripts
+    """
+    counts = {"encrypted": 0, "failed": 0}
+    try:
+        encryption = await podcast_encryption(db, podcast_id)
+    except PrivateTranscriptError as e:
+        logger.error(f"Not encrypting transcripts: {e}")
+        return counts
+    if not encryption:
+        return counts
+
+    cursor = db.episodes.find(
+        {
+            "podcast_id": podcast_id,
+            "transcript_status": TranscriptStatus.COMPLETED.value,
+            "transcript_s3_key": {"$ne": None, "$not": {"$regex": f"^{PRIVATE_PREFIX}"}},
+            "transcript_archived_at": None,
+        },
+        {"episode_id": 1, "transcript_s3_key": 1, "storage": 1}
+    )
+    async for episode in cursor:
+        source_key = episode["transcript_s3_key"]
+        storage = episode.get("storage")
+        stored = None
+        try:
+            text = await s3_service.get_transcript(source_key, **transcript_location(storage))
+            if text:
+                stored = await store_transcript(text, storage, encryption)
+        except Exception as e:
+            logger.error(f"Failed to encrypt transcript of episode {episode['episode_id']}: {e}")
+        if not stored:
+            counts["failed"] += 1
+            continue
+
+        await db.episodes.update_one(
+            {"episode_id": episode["episode_id"]},
+            {"$set": {
+                "transcript_s3_key": stored[0],
+                "transcript_sha256": stored[1],
+                "updated_at": datetime.utcnow(),
+            }}
+        )
+        if not await db.episodes.find_one({"transcript_s3_key": source_key, **same_bucket(storage)}, {"_id": 1}):
+            await s3_service.delete_object(source_key, **transcript_location(storage))
+        counts["encrypted"] += 1
+
+    logger.info(
+        f"Encrypted {counts['encrypted']} transcript(s) of private podcast {podcast_id}, {counts['failed']} failed"
+    )
+    return counts
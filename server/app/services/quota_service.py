@@ -0,0 +1,146 @@
+"""Monthly transcription quota enforcement."""
+import hashlib
+import logging
+from datetime import datetime
+from typing import Any, Dict, Optional
+
+from fastapi import status
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.config import settings
+
+logger = logging.getLogger(__name__)
+
+GLOBAL_SUBJECT = "global"
+
+
+class QuotaExceededError(Exception):
+    """
+    Raised when a transcription would exceed a monthly quota.
+
+    The global budget running out is a spend cap (402); a single API key
+    exhausting its share is throttling (429).
+    """
+
+    def __init__(self, message: str, status_code: int, quota: Dict[str, Any]):
+        super().__init__(message)
+        self.message = message
+        self.status_code = status_code
+        self.quota = quota
+
+
+def _month_bounds(now: Optional[datetime] = None):
+    """Current month key (YYYY-MM) and the start of the next month."""
+    now = now or datetime.utcnow()
+    if now.month == 12:
+        resets_at = datetime(now.year + 1, 1, 1)
+    else:
+        resets_at = datetime(now.year, now.month + 1, 1)
+    return now.strftime("%Y-%m"), resets_at
+
+
+def key_subject(api_key: Optional[str]) -> Optional[str]:
+    """Quota subject for an API key; keys are stored hashed."""
+    if not api_key:
+        return None
+    return "key:" + hashlib.sha256(api_key.encode("utf-8")).hexdigest()[:16]
+
+
+def episode_minutes(episode: Dict[str, Any]) -> int:
+    """Minutes to reserve for an episode, falling back to a default when the feed has no duration."""
+    return episode.get("duration_minutes") or settings.quota_default_episode_minutes
+
+
+class QuotaService:
+    """Tracks monthly transcription minutes globally and per API key."""
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+        self.usage_collection = db.quota_usage
+
+    async def _used(self, subject: str, month: str) -> int:
+        doc = await self.usage_collection.find_one({"subject": subject, "month": month})
+        return doc.get("minutes_used", 0) if doc else 0
+
+    async def _try_consume(self, subject: str, month: str, minutes: int, limit: int) -> bool:
+        """Atomically add minutes if the subject stays within its limit (0 = unlimited)."""
+        await self.usage_collection.update_one(
+            {"subject": subject, "month": month},
+            {"$setOnInsert": {"minutes_used": 0}},
+            upsert=True
+        )
+        query: Dict[str, Any] = {"subject": subject, "month": month}
+        if limit > 0:
+            query["minutes_used"] = {"$lte": limit - minutes}
+        result = await self.usage_collection.update_one(
+            query,
+            {"$inc": {"minutes_used": minutes}, "$set": {"updated_at": datetime.utcnow()}}
+        )
+        return result.modified_count > 0
+
+    async def _release(self, subject: str, month: str, minutes: int):
+        await self.usage_collection.update_one(
+            {"subject": subject, "month": month},
+            {"$inc": {"minutes_used": -minutes}}
+        )
+
+    async def reserve(self, minutes: int, api_key: Optional[str] = None):
+        """
+        Reserve transcription minutes for the current month.
+
+        Args:
+            minutes: Audio minutes about to be transcribed
+            api_key: Caller's API key, if any
+
+        Raises:
+            QuotaExceededError: If the global or per-key quota would be exceeded
+        """
+        month, _ = _month_bounds()
+
+        if not await self._try_consume(GLOBAL_SUBJECT, month, minutes, settings.quota_monthly_minutes):
+            raise QuotaExceededError(
+                f"Monthly transcription quota exhausted ({minutes} minutes requested)",
+                status.HTTP_402_PAYMENT_REQUIRED,
+                await self.get_status(api_key)
+            )
+
+        subject = key_subject(api_key)
+        if subject and not await self._try_consume(subject, month, minutes, settings.quota_key_monthly_minutes):
+            await self._release(GLOBAL_SUBJECT, month, minutes)
+            raise QuotaExceededError(
+                f"Monthly transcription quota for this API key exhausted ({minutes} minutes requested)",
+                status.HTTP_429_TOO_MANY_REQUESTS,
+                await self.get_status(api_key)
+            )
+
+        logger.info(f"Reserved {minutes} transcription minutes ({subject or GLOBAL_SUBJECT})")
+
+    async def release(self, minutes: int, api_key: Optional[str] = None):
+        """Return minutes reserved for work that won't run."""
+        month, _ = _month_bounds()
+        await self._release(GLOBAL_SUBJECT, month, minutes)
+        subject = key_subject(api_key)
+        if subject:
+            await self._release(subject, month, minutes)
+
+    async def get_status(self, api_key: Optional[str] = None) -> Dict[str, Any]:
+        """Remaining budget for the current month, globally and for the caller's key."""
+        month, resets_at = _month_bounds()
+
+        def budget(limit: int, used: int) -> Dict[str, Any]:
+            return {
+                "limit_minutes": limit or None,
+                "used_minutes": used,
+                "remaining_minutes": max(limit - used, 0) if limit else None,
+            }
+
+        result: Dict[str, Any] = {
+            "month": month,
+            "resets_at": resets_at,
+            "global": budget(settings.quota_monthly_minutes, await self._used(GLOBAL_SUBJECT, month)),
+            "api_key": None,
+        }
+        subject = key_subject(api_key)
+        if subject:
+            result["api_key"] = budget(settings.quota_key_monthly_minutes, await self._used(subject, month))
+        return result
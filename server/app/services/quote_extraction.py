@@ -0,0 +1,335 @@
+"""
+Notable quotes from transcripts, and shareable quote cards.
+
+Quotes are whole transcript sentences, so their text stays verbatim and
+their times come from the [HH:MM:SS] markers like ad segments' do. A
+heuristic scores each sentence (a self-contained length, quotable phrasing,
+little filler, no questions or ad reads); with OPENAI_API_KEY and
+QUOTE_LLM_MODEL set, the best-scoring candidates are sent to the model,
+which picks the most notable. The model only chooses among candidates, so
+it can't misquote; if it fails, the heuristic's picks are used.
+
+A quote card is an SVG image (1200x630, the size link previews use) with
+the podcast's artwork embedded next to the quote, stored in S3 under
+quote-cards/<episode_id>/.
+"""
+import base64
+import json
+import logging
+import re
+import textwrap
+from datetime import datetime
+from typing import Any, Dict, List, Optional, Tuple
+from xml.sax.saxutils import escape
+
+import httpx
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.config import settings
+from app.services.ad_detection import TIMESTAMP, split_sentences, time_anchors, time_at
+from app.services.s3_service import s3_service
+from app.services.storage_locations import transcript_location
+
+logger = logging.getLogger(__name__)
+
+OPENAI_CHAT_URL = "https://api.openai.com/v1/chat/completions"
+REQUEST_TIMEOUT = 60.0
+
+# Candidates sent to the model
+LLM_CANDIDATES = 40
+
+# Sentences scoring lower are never quoted
+MIN_SCORE = 0.4
+
+MIN_WORDS = 8
+MAX_WORDS = 45
+
+# Sentences between two picked quotes, so they don't repeat one passage
+MIN_SENTENCES_APART = 3
+
+# Phrasing typical of a line worth quoting
+QUOTABLE = re.compile(
+    r"\b(never|always|everyone|nobody|the (real|whole|only|biggest|best|worst|hardest) |"
+    r"the (secret|truth|key|point|problem|lesson) (is|was)|most important|"
+    r"if you|you have to|you can't|the moment|changed my|i learned|i realized|"
+    r"the difference between|isn't about|is not about|it's not|it's about)",
+    re.IGNORECASE,
+)
+
+# Conversational starts and fillers that make a sentence read badly out of context
+WEAK_START = re.compile(r"^(and|so|but|or|because|um|uh|yeah|like|i mean|you know|right|okay|well)\b", re.IGNORECASE)
+FILLER = re.compile(r"\b(um|uh|you know|kind of|sort of|i guess)\b", re.IGNORECASE)
+
+# "Jane Doe:" or "SPEAKER_01:" at the start of a paragraph
+SPEAKER_LABEL = re.compile(r"^\s*[\w .'-]{1,40}:\s+")
+
+CARD_WIDTH = 1200
+CARD_HEIGHT = 630
+CARD_ART_BYTES = 5 * 1024 ** 2
+CARD_URL_EXPIRES_SECONDS = 7 * 24 * 3600
+
+
+def _clean(text: str) -> str:
+    text = TIMESTAMP.sub("", text)
+    text = SPEAKER_LABEL.sub("", text)
+    return " ".join(text.split())
+
+
+def _score(text: str) -> float:
+    """How quotable a cleaned sentence is (0-1)."""
+    words = len(text.split())
+    if words < MIN_WORDS or words > MAX_WORDS or text.endswith("?"):
+        return 0.0
+    score = 0.5
+    score += 0.15 * min(len(QUOTABLE.findall(text)), 2)
+    if WEAK_START.match(text):
+        score -= 0.2
+    score -= 0.1 * len(FILLER.findall(text))
+    if 12 <= words <= 30:
+        score += 0.1
+    return round(max(0.0, min(score, 1.0)), 2)
+
+
+def _in_ads(start: int, end: int, ad_segments: List[Dict[str, Any]]) -> bool:
+    return any(start < s["end_char"] and end > s["start_char"] for s in ad_segments)
+
+
+def quote_candidates(
+    transcript: str,
+    duration_seconds: Optional[float] = None,
+    ad_segments: Optional[List[Dict[str, Any]]] = None,
+) -> List[Dict[str, Any]]:
+    """
+    Sentences that could stand alone as quotes, in transcript order.
+
+    Returns:
+        Candidates with text, character range, time range and score
+    """
+    anchors = time_anchors(transcript, duration_seconds)
+    candidates = []
+    for position, (start, end, sentence) in enumerate(split_sentences(transcript)):
+        if _in_ads(start, end, ad_segments or []):
+            continue
+        text = _clean(sentence)
+        score = _score(text)
+        if score < MIN_SCORE:
+            continue
+        candidates.append({
+            "text": text,
+            "start_char": start,
+            "end_char": end,
+            "start_seconds": time_at(anchors, start),
+            "end_seconds": time_at(anchors, end),
+            "score": score,
+            "position": position,
+        })
+    return candidates
+
+
+def pick_quotes(candidates: List[Dict[str, Any]], count: int) -> List[Dict[str, Any]]:
+    """The best-scoring candidates, kept apart from each other, in transcript order."""
+    picked: List[Dict[str, Any]] = []
+    for candidate in sorted(candidates, key=lambda c: (-c["score"], c["position"])):
+        if all(abs(candidate["position"] - p["position"]) >= MIN_SENTENCES_APART for p in picked):
+            picked.append(candidate)
+            if len(picked) == count:
+                break
+    return sorted(picked, key=lambda c: c["position"])
+
+
+async def _llm_pick(candidates: List[Dict[str, Any]], count: int, title: str) -> List[Dict[str, Any]]:
+    """Ask the model which of the candidates are most notable."""
+    shortlist = sorted(
+        sorted(candidates, key=lambda c: -c["score"])[:LLM_CANDIDATES], key=lambda c: c["position"]
+    )
+    numbered = "\n".join(f"{i}. {c['text']}" for i, c in enumerate(shortlist))
+    payload = {
+        "model": settings.quote_llm_model,
+        "response_format": {"type": "json_object"},
+        "temperature": 0,
+        "messages": [
+            {
+                "role": "system",
+                "content": (
+                    "You pick the most notable, shareable quotes from a podcast transcript. "
+                    "Prefer lines that are insightful, surprising or memorable and make sense "
+                    "without context. Answer with JSON: {\"quotes\": [<line numbers>]}, best first."
+                ),
+            },
+            {"role": "user", "content": f"Episode: {title}\nPick up to {count} lines.\n\n{numbered}"},
+        ],
+    }
+    async with httpx.AsyncClient(timeout=REQUEST_TIMEOUT) as client:
+        response = await client.post(
+            OPENAI_CHAT_URL, json=payload, headers={"Authorization": f"Bearer {settings.openai_api_key}"}
+        )
+        response.raise_for_status()
+        content = response.json()["choices"][0]["message"]["content"]
+
+    picked: List[Dict[str, Any]] = []
+    for number in json.loads(content).get("quotes") or []:
+        if isinstance(number, int) and 0 <= number < len(shortlist) and shortlist[number] not in picked:
+            picked.append(shortlist[number])
+    if not picked:
+        raise ValueError("The model picked no quotes")
+    return sorted(picked[:count], key=lambda c: c["position"])
+
+
+def _format_time(seconds: float) -> str:
+    seconds = int(seconds)
+    hours, rest = divmod(seconds, 3600)
+    return f"{hours}:{rest // 60:02d}:{rest % 60:02d}" if hours else f"{rest // 60}:{rest % 60:02d}"
+
+
+def render_quote_card(
+    quote: Dict[str, Any],
+    episode_title: str,
+    podcast_title: str,
+    art: Optional[Tuple[bytes, str]] = None,
+) -> str:
+    """
+    An SVG card showing a quote next to the podcast's artwork.
+
+    Args:
+        quote: Quote with text and start_seconds
+        episode_title: Title of the quote's episode
+        podcast_title: Title of the podcast
+        art: Artwork bytes and content type; without it the card is text only
+
+    Returns:
+        SVG document
+    """
+    text_x = CARD_HEIGHT + 40 if art else 60
+    columns = (CARD_WIDTH - text_x - 60) // 20
+    size = 44 if len(quote["text"]) <= 120 else 34 if len(quote["text"]) <= 200 else 28
+    lines = textwrap.wrap(f"“{quote['text']}”", width=int(columns * 44 / size), max_lines=8, placeholder=" …”")
+    top = (CARD_HEIGHT - 110 - len(lines) * size * 1.25) / 2 + size
+
+    parts = [
+        f'<svg xmlns="http://www.w3.org/2000/svg" width="{CARD_WIDTH}" height="{CARD_HEIGHT}" '
+        f'viewBox="0 0 {CARD_WIDTH} {CARD_HEIGHT}">',
+        f'<rect width="{CARD_WIDTH}" height="{CARD_HEIGHT}" fill="#111827"/>',
+    ]
+    if art:
+        data, content_type = art
+        parts.append(
+            f'<image x="0" y="0" width="{CARD_HEIGHT}" height="{CARD_HEIGHT}" preserveAspectRatio="xMidYMid slice" '
+            f'href="data:{content_type};base64,{base64.b64encode(data).decode()}"/>'
+        )
+    parts.append(
+        f'<text x="{text_x}" y="{top:.0f}" fill="#f9fafb" font-family="Georgia, serif" font-size="{size}">'
+    )
+    for i, line in enumerate(lines):
+        parts.append(f'<tspan x="{text_x}" dy="{0 if i == 0 else size * 1.25:.0f}">{escape(line)}</tspan>')
+    parts.append("</text>")
+    footer = f"{podcast_title} · {episode_title}" if podcast_title else episode_title
+    parts.append(
+        f'<text x="{text_x}" y="{CARD_HEIGHT - 80}" fill="#9ca3af" font-family="Helvetica, Arial, sans-serif" '
+        f'font-size="24">{escape(textwrap.shorten(footer, width=columns * 2, placeholder=" …"))}</text>'
+    )
+    parts.append(
+        f'<text x="{text_x}" y="{CARD_HEIGHT - 45}" fill="#9ca3af" font-family="Helvetica, Arial, sans-serif" '
+        f'font-size="24">at {_format_time(quote["start_seconds"])}</text>'
+    )
+    parts.append("</svg>")
+    return "\n".join(parts)
+
+
+async def _fetch_art(url: Optional[str]) -> Optional[Tuple[bytes, str]]:
+    if not url:
+        return None
+    try:
+        async with httpx.AsyncClient(timeout=REQUEST_TIMEOUT, follow_redirects=True) as client:
+            response = await client.get(url)
+            response.raise_for_status()
+        content_type = response.headers.get("content-type", "").split(";")[0].strip()
+        if not content_type.startswith("image/") or len(response.content) > CARD_ART_BYTES:
+            logger.warning(f"Skipping artwork {url} ({content_type}, {len(response.content)} bytes)")
+            return None
+        return response.content, content_type
+    except httpx.HTTPError as e:
+        logger.warning(f"Failed to fetch artwork {url}: {e}")
+        return None
+
+
+class QuoteExtractionService:
+    """Extracts an episode's quotes and renders their cards."""
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+
+    async def extract(self, episode_id: str, count: int = 5, cards: bool = False) -> Optional[Dict[str, Any]]:
+        """
+        Extract an episode's notable quotes and store them on the episode.
+
+        Args:
+            episode_id: Episode to analyze
+            count: Maximum quotes to return
+            cards: Also render a quote card for each quote
+
+        Returns:
+            The method used and the quotes (with card_url when rendered), or
+            None if the episode or its transcript is missing
+        """
+        episode = await self.db.episodes.find_one({"episode_id": episode_id})
+        if not episode:
+            return None
+        transcript = None
+        if episode.get("transcript_s3_key"):
+            transcript = await s3_service.get_transcript(
+                episode["transcript_s3_key"], **transcript_location(episode.get("storage"))
+            )
+        transcript = transcript or episode.get("transcript_text")
+        if not transcript:
+            return None
+
+        duration_minutes = episode.get("duration_minutes")
+        candidates = quote_candidates(
+            transcript, duration_minutes * 60 if duration_minutes else None, episode.get("ad_segments") or []
+        )
+        method, model = "heuristic", None
+        quotes = pick_quotes(candidates, count)
+        if candidates and settings.openai_api_key and settings.quote_llm_model:
+            try:
+                quotes = await _llm_pick(candidates, count, episode.get("title") or "")
+                method, model = "llm", settings.quote_llm_model
+            except Exception as e:
+                logger.warning(f"LLM quote selection failed for {episode_id}, using heuristic picks: {e}")
+
+        for quote in quotes:
+            quote.pop("position", None)
+        if cards and quotes:
+            await self._render_cards(episode, quotes)
+
+        previous_keys = {q.get("card_s3_key") for q in episode.get("quotes") or []} - {None}
+        current_keys = {q.get("card_s3_key") for q in quotes} - {None}
+        for key in previous_keys - current_keys:
+            await s3_service.delete_object(key)
+
+        now = datetime.utcnow()
+        await self.db.episodes.update_one(
+            {"episode_id": episode_id},
+            {"$set": {
+                "quotes": quotes,
+                "quote_extraction": {"method": method, "model": model, "extracted_at": now},
+                "updated_at": now,
+            }}
+        )
+        for quote in quotes:
+            if quote.get("card_s3_key"):
+                quote["card_url"] = s3_service.generate_presigned_url(
+                    quote["card_s3_key"], expires_in=CARD_URL_EXPIRES_SECONDS
+                )
+        logger.info(f"Extracted {len(quotes)} quote(s) from episode {episode_id} ({method})")
+        return {"episode_id": episode_id, "method": method, "model": model, "quotes": quotes}
+
+    async def _render_cards(self, episode: Dict[str, Any], quotes: List[Dict[str, Any]]):
+        podcast = await self.db.podcasts.find_one(
+            {"podcast_id": episode.get("podcast_id")}, {"title": 1, "image_url": 1}
+        ) or {}
+        art = await _fetch_art(episode.get("image_url") or podcast.get("image_url"))
+        for i, quote in enumerate(quotes):
+            svg = render_quote_card(quote, episode.get("title") or "", podcast.get("title") or "", art)
+            key = f"quote-cards/{episode['episode_id']}/{i + 1}.svg"
+            if await s3_service.upload_bytes(key, svg.encode("utf-8"), "image/svg+xml"):
+                quote["card_s3_key"] = key
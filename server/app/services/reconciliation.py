@@ -0,0 +1,280 @@
+"""Consistency checks between MongoDB and the transcripts bucket.
+
+A reconciliation run lists the bucket once and checks both directions:
+every completed episode's transcript_s3_key must exist (under transcripts/
+or, once archived, the archive prefix), and every object under
+transcripts/ must belong to an episode or a one-off transcription task.
+With verify, the checksum of each transcript with a transcript_sha256 is
+also compared with it (see transcript_store.py).
+
+With repair, missing or mismatched transcripts are stored again from the
+copy kept in MongoDB where there is one, otherwise the episode is marked
+failed so it can be transcribed again; orphaned objects are moved under
+RECONCILE_ORPHAN_PREFIX rather than deleted. Each run's report is stored in
+the reconciliation_runs collection.
+
+Only the default bucket is reconciled; episodes whose transcripts went to
+another storage location (see storage_locations.py) are skipped.
+"""
+import asyncio
+import logging
+import re
+import uuid
+from datetime import datetime
+from typing import Any, Dict, List
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.config import settings
+from app.models.schemas import TranscriptStatus
+from app.services.s3_service import s3_service
+from app.services.private_transcripts import PrivateTranscriptError, episode_encryption
+from app.services.transcript_store import CONTENT_PREFIX, PRIVATE_PREFIX, store_transcript
+
+logger = logging.getLogger(__name__)
+
+TRANSCRIPTS_PREFIX = "transcripts/"
+
+# transcripts/uploads/<task_id>/... belongs to a one-off task,
+# transcripts/<episode_id>/... to an episode and transcripts/sha256/<digest>.txt
+# to the episodes whose transcript_s3_key it is
+UPLOAD_OBJECT_KEY = re.compile(r"^transcripts/uploads/([^/]+)/")
+EPISODE_OBJECT_KEY = re.compile(r"^transcripts/([^/]+)/")
+
+# IDs and keys listed per problem in a stored report; counts are always complete
+REPORT_SAMPLE_LIMIT = 1000
+
+MISSING_TRANSCRIPT_ERROR = "Transcript missing from S3; transcribe the episode again"
+MISMATCHED_TRANSCRIPT_ERROR = "Transcript in S3 doesn't match its checksum; transcribe the episode again"
+
+
+class ReconciliationService:
+    """Checks and repairs transcript references between MongoDB and S3."""
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+        self.runs_collection = db.reconciliation_runs
+
+    async def run(self, repair: bool = False, trigger: str = "manual", verify: bool = False) -> Dict[str, Any]:
+        """
+        Run a reconciliation and store its report.
+
+        Args:
+            repair: Fix the mismatches found, not just report them
+            trigger: "manual" or "scheduled"
+            verify: Also compare transcript checksums with transcript_sha256
+
+        Returns:
+            The report
+        """
+        report: Dict[str, Any] = {
+            "run_id": f"rec_{uuid.uuid4().hex[:12]}",
+            "trigger": trigger,
+            "repair": repair,
+            "verify": verify,
+            "started_at": datetime.utcnow(),
+        }
+        logger.info(f"Starting reconciliation {report['run_id']} (repair={repair})")
+
+        objects = await s3_service.list_objects(TRANSCRIPTS_PREFIX)
+        archived = await s3_service.list_objects(settings.archive_prefix.rstrip("/") + "/")
+        existing_keys = {item["key"] for item in objects} | {item["key"] for item in archived}
+
+        missing = await self._find_missing_transcripts(existing_keys, report["started_at"])
+        orphans = await self._find_orphaned_objects([item["key"] for item in objects])
+        mismatched: List[str] = []
+        if verify:
+            verified = await self._find_checksum_mismatches(existing_keys, report["started_at"])
+            mismatched = verified["episode_ids"]
+            report.update({
+                "checksums_verified": verified["checked"],
+                "checksum_mismatches": len(mismatched),
+                "mismatched_episode_ids": mismatched[:REPORT_SAMPLE_LIMIT],
+            })
+        report.update({
+            "episodes_checked": missing["checked"],
+            "objects_checked": len(objects),
+            "missing_transcripts": len(missing["episode_ids"]),
+            "missing_episode_ids": missing["episode_ids"][:REPORT_SAMPLE_LIMIT],
+            "orphaned_objects": len(orphans),
+            "orphaned_keys": orphans[:REPORT_SAMPLE_LIMIT],
+        })
+
+        if repair:
+            missing_repairs = await self._repair_missing(missing["episode_ids"], MISSING_TRANSCRIPT_ERROR)
+            mismatch_repairs = await self._repair_missing(mismatched, MISMATCHED_TRANSCRIPT_ERROR)
+            report["repaired"] = {
+                **{key: count + mismatch_repairs[key] for key, count in missing_repairs.items()},
+                "orphans_moved": await self._move_orphans(orphans),
+            }
+
+        report["finished_at"] = datetime.utcnow()
+        await self.runs_collection.insert_one(report)
+        report.pop("_id", None)
+        logger.info(
+            f"Reconciliation {report['run_id']}: {report['missing_transcripts']} missing transcript(s), "
+            f"{report['orphaned_objects']} orphaned object(s)"
+        )
+        return report
+
+    async def list_runs(self, limit: int = 10) -> List[Dict[str, Any]]:
+        """Most recent reports, newest first."""
+        cursor = self.runs_collection.find({}, {"_id": 0}).sort("started_at", -1).limit(limit)
+        return await cursor.to_list(length=limit)
+
+    async def _find_missing_transcripts(self, existing_keys: set, listed_at: datetime) -> Dict[str, Any]:
+        """
+        Completed episodes whose transcript_s3_key isn't in the bucket.
+
+        Episodes updated since the bucket was listed are skipped; their
+        transcript may have been written after the listing.
+        """
+        checked = 0
+        episode_ids = []
+        cursor = self.db.episodes.find(
+            {
+                "transcript_status": TranscriptStatus.COMPLETED.value,
+                "transcript_s3_key": {"$ne": None},
+                "storage": None,
+                "$or": [{"updated_at": {"$lt": listed_at}}, {"updated_at": None}],
+            },
+            {"episode_id": 1, "transcript_s3_key": 1}
+        )
+        async for episode in cursor:
+            checked += 1
+            if episode["transcript_s3_key"] not in existing_keys:
+                episode_ids.append(episode["episode_id"])
+        return {"checked": checked, "episode_ids": episode_ids}
+
+    async def _find_checksum_mismatches(self, existing_keys: set, listed_at: datetime) -> Dict[str, Any]:
+        """
+        Completed episodes whose transcript's checksum isn't their
+        transcript_sha256. Missing transcripts are left to
+        _find_missing_transcripts; shared objects are read once.
+        """
+        checked = 0
+        episode_ids = []
+        digests: Dict[str, Any] = {}
+        cursor = self.db.episodes.find(
+            {
+                "transcript_status": TranscriptStatus.COMPLETED.value,
+                "transcript_s3_key": {"$ne": None},
+                "transcript_sha256": {"$nin": [None, ""]},
+                "storage": None,
+                "$or": [{"updated_at": {"$lt": listed_at}}, {"updated_at": None}],
+            },
+            {"episode_id": 1, "transcript_s3_key": 1, "transcript_sha256": 1}
+        )
+        async for episode in cursor:
+            key = episode["transcript_s3_key"]
+            if key not in existing_keys:
+                continue
+            if key not in digests:
+                digests[key] = await s3_service.object_sha256(key)
+            checked += 1
+            if digests[key] != episode["transcript_sha256"]:
+                episode_ids.append(episode["episode_id"])
+        return {"checked": checked, "episode_ids": episode_ids}
+
+    async def _find_orphaned_objects(self, keys: List[str]) -> List[str]:
+        """Objects under transcripts/ without an episode or task to own them."""
+        episode_keys: Dict[str, List[str]] = {}
+        task_keys: Dict[str, List[str]] = {}
+        content_keys = []
+        orphans = []
+        for key in keys:
+            if key.startswith((CONTENT_PREFIX, PRIVATE_PREFIX)):
+                content_keys.append(key)
+                continue
+            upload = UPLOAD_OBJECT_KEY.match(key)
+            episode = EPISODE_OBJECT_KEY.match(key)
+            if upload:
+                task_keys.setdefault(upload.group(1), []).append(key)
+            elif episode and episode.group(1) != "uploads":
+                episode_keys.setdefault(episode.group(1), []).append(key)
+            else:
+                orphans.append(key)
+
+        known_episodes = set(await self.db.episodes.distinct(
+            "episode_id", {"episode_id": {"$in": list(episode_keys)}}
+        ))
+        known_tasks = set(await self.db.transcription_tasks.distinct(
+            "task_id", {"task_id": {"$in": list(task_keys)}}
+        ))
+        used_content_keys = set(await self.db.episodes.distinct(
+            "transcript_s3_key", {"transcript_s3_key": {"$in": content_keys}}
+        ))
+        orphans.extend(key for key in content_keys if key not in used_content_keys)
+        for episode_id, owned in episode_keys.items():
+            if episode_id not in known_episodes:
+                orphans.extend(owned)
+        for task_id, owned in task_keys.items():
+            if task_id not in known_tasks:
+                orphans.extend(owned)
+        return sorted(orphans)
+
+    async def _repair_missing(self, episode_ids: List[str], error_message: str) -> Dict[str, int]:
+        """Store transcripts again from MongoDB, or mark the episodes failed."""
+        counts = {"reuploaded": 0, "marked_failed": 0}
+        for episode_id in episode_ids:
+            episode = await self.db.episodes.find_one(
+                {"episode_id": episode_id}, {"transcript_s3_key": 1, "transcript_text": 1}
+            )
+            if not episode:
+                continue
+            stored = None
+            if episode.get("transcript_text"):
+                try:
+                    encryption = await episode_encryption(self.db, episode_id)
+                    stored = await store_transcript(episode["transcript_text"], encryption=encryption)
+                except PrivateTranscriptError as e:
+                    logger.error(f"Not storing transcript of episode {episode_id} again: {e}")
+            if stored:
+                await self.db.episodes.update_one(
+                    {"episode_id": episode_id},
+                    {"$set": {"transcript_s3_key": stored[0], "transcript_sha256": stored[1]}}
+                )
+                counts["reuploaded"] += 1
+                continue
+
+            await self.db.episodes.update_one(
+                {"episode_id": episode_id},
+                {"$set": {
+                    "transcript_status": TranscriptStatus.FAILED.value,
+                    "transcript_s3_key": None,
+                    "processing_step": None,
+                    "error_message": error_message,
+                    "updated_at": datetime.utcnow(),
+                }}
+            )
+            counts["marked_failed"] += 1
+        return counts
+
+    async def _move_orphans(self, keys: List[str]) -> int:
+        """Move orphaned objects under the orphan prefix."""
+        moved = 0
+        prefix = settings.reconcile_orphan_prefix.rstrip("/")
+        for key in keys:
+            if await s3_service.move_object(key, f"{prefix}/{key}"):
+                moved += 1
+        return moved
+
+
+async def run_reconciliation_scheduler(get_db, interval_hours: int):
+    """
+    Periodically reconcile MongoDB with S3 until cancelled.
+
+    Args:
+        get_db: Callable returning the database instance
+        interval_hours: Hours between runs
+    """
+    logger.info(f"Starting reconciliation scheduler (every {interval_hours} hours)")
+    while True:
+        await asyncio.sleep(interval_hours * 3600)
+        try:
+            await ReconciliationService(get_db()).run(
+                settings.reconcile_repair, trigger="scheduled", verify=settings.reconcile_verify
+            )
+        except Exception as e:
+            logger.error(f"Reconciliation run failed: {e}")
+
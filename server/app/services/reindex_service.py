@@ -0,0 +1,165 @@
+"""
+Rebuilding the transcript search index from S3.
+
+The change-stream indexer (app/services/transcript_indexer.py) only applies
+changes as they happen. After the index definition changes, or when the
+index was lost, POST /api/admin/reindex rebuilds it: every completed
+episode (of one podcast, or all of them) is re-read from S3 and indexed
+again, and entries the run didn't refresh are dropped. A full rebuild also
+recreates the text index, so a changed definition takes effect.
+
+Each run is a reindex_runs document updated as it goes, which is how its
+progress is reported. One run goes at a time; a run whose instance stopped
+updating it is marked interrupted when the next one starts.
+"""
+import logging
+import uuid
+from datetime import datetime, timedelta
+from typing import Any, Dict, List, Optional
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+from pymongo.errors import DuplicateKeyError
+
+from app.models.schemas import TranscriptStatus
+from app.services.transcript_indexer import TranscriptIndexer, create_search_indexes
+
+logger = logging.getLogger(__name__)
+
+RUNNING = "running"
+COMPLETED = "completed"
+FAILED = "failed"
+
+# Progress is written after this many episodes
+PROGRESS_EVERY = 25
+
+# A running run not updated for this long belongs to a stopped instance
+STALE_AFTER = timedelta(minutes=10)
+
+# Failed episode IDs kept on a run; the count is always complete
+FAILED_SAMPLE_LIMIT = 1000
+
+
+class ReindexRunningError(Exception):
+    """Raised when a reindex is requested while another is running."""
+
+
+class ReindexService:
+    """Starts, runs and reports transcript index rebuilds."""
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+        self.runs_collection = db.reindex_runs
+        self.indexer = TranscriptIndexer(db)
+
+    async def start(self, podcast_id: Optional[str] = None) -> Dict[str, Any]:
+        """
+        Record a new run; run() then does the work.
+
+        Raises:
+            ReindexRunningError: If another run is in progress
+        """
+        now = datetime.utcnow()
+        await self.runs_collection.update_many(
+            {"status": RUNNING, "updated_at": {"$lt": now - STALE_AFTER}},
+            {"$set": {"status": FAILED, "error": "Interrupted", "finished_at": now}}
+        )
+
+        query = self._episode_query(podcast_id)
+        run = {
+            "run_id": f"reidx_{uuid.uuid4().hex[:12]}",
+            "podcast_id": podcast_id,
+            "status": RUNNING,
+            "total": await self.db.episodes.count_documents(query),
+            "processed": 0,
+            "indexed": 0,
+            "skipped": 0,
+            "failed": 0,
+            "failed_episode_ids": [],
+            "started_at": now,
+            "updated_at": now,
+            "finished_at": None,
+            "error": None,
+        }
+        try:
+            # The partial unique index on status admits one running run
+            await self.runs_collection.insert_one(run)
+        except DuplicateKeyError:
+            raise ReindexRunningError("A reindex is already running")
+        run.pop("_id", None)
+        return run
+
+    async def run(self, run_id: str):
+        """Rebuild the index for a started run, recording progress on it."""
+        run = await self.runs_collection.find_one({"run_id": run_id})
+        if not run:
+            return
+        podcast_id = run.get("podcast_id")
+        logger.info(f"Starting reindex {run_id} ({'podcast ' + podcast_id if podcast_id else 'all podcasts'})")
+        counts = {"processed": 0, "indexed": 0, "skipped": 0, "failed": 0}
+        failed_ids: List[Any] = []
+        failed_episode_ids: List[str] = []
+        try:
+            if not podcast_id:
+                await self._recreate_indexes()
+
+            async for episode in self.db.episodes.find(self._episode_query(podcast_id)):
+                try:
+                    counts["indexed" if await self.indexer.index_episode(episode) else "skipped"] += 1
+                except Exception as e:
+                    logger.warning(f"Reindex {run_id}: failed to index episode {episode.get('episode_id')}: {e}")
+                    counts["failed"] += 1
+                    failed_ids.append(episode["_id"])
+                    failed_episode_ids.append(episode.get("episode_id"))
+                counts["processed"] += 1
+                if counts["processed"] % PROGRESS_EVERY == 0:
+                    await self._update(run_id, counts, failed_episode_ids)
+
+            # Entries this run didn't refresh belong to episodes that are gone,
+            # no longer completed or without text; failures keep their old entry
+            stale: Dict[str, Any] = {"indexed_at": {"$lt": run["started_at"]}, "_id": {"$nin": failed_ids}}
+            if podcast_id:
+                stale["podcast_id"] = podcast_id
+            dropped = await self.indexer.index_collection.delete_many(stale)
+
+            await self._update(run_id, counts, failed_episode_ids, status=COMPLETED)
+            logger.info(
+                f"Reindex {run_id} completed: {counts['indexed']} indexed, {counts['skipped']} without text, "
+                f"{counts['failed']} failed, {dropped.deleted_count} stale entries dropped"
+            )
+        except Exception as e:
+            logger.error(f"Reindex {run_id} failed: {e}")
+            await self._update(run_id, counts, failed_episode_ids, status=FAILED, error=str(e))
+
+    async def get_run(self, run_id: str) -> Optional[Dict[str, Any]]:
+        return await self.runs_collection.find_one({"run_id": run_id}, {"_id": 0})
+
+    @staticmethod
+    def _episode_query(podcast_id: Optional[str]) -> Dict[str, Any]:
+        query: Dict[str, Any] = {"transcript_status": TranscriptStatus.COMPLETED.value, "deleted_at": None}
+        if podcast_id:
+            query["podcast_id"] = podcast_id
+        return query
+
+    async def _recreate_indexes(self):
+        try:
+            await self.indexer.index_collection.drop_indexes()
+        except Exception as e:
+            logger.warning(f"Failed to drop transcript index definitions: {e}")
+        await create_search_indexes(self.db)
+
+    async def _update(
+        self,
+        run_id: str,
+        counts: Dict[str, int],
+        failed_episode_ids: List[str],
+        status: Optional[str] = None,
+        error: Optional[str] = None
+    ):
+        fields: Dict[str, Any] = {
+            **counts,
+            "failed_episode_ids": failed_episode_ids[:FAILED_SAMPLE_LIMIT],
+            "updated_at": datetime.utcnow(),
+        }
+        if status:
+            fields.update({"status": status, "error": error, "finished_at": datetime.utcnow()})
+        await self.runs_collection.update_one({"run_id": run_id}, {"$set": fields})
@@ -1,20 +1,76 @@
 """RSS feed parser service."""
+import copy
 import logging
 import asyncio
+import time
 import feedparser
 import aiohttp
-from typing import Dict, Optional
+from collections import OrderedDict
+from dataclasses import dataclass
+from typing import Any, Dict, List, Optional, Tuple
 from datetime import datetime
+from xml.etree import ElementTree
+from app.config import settings
+from app.url_normalization import normalize_url
 
 logger = logging.getLogger(__name__)
 
-# RSS feed fetch timeout in seconds
-RSS_FETCH_TIMEOUT = 10
+ITUNES_NS = "http://www.itunes.com/dtds/podcast-1.0.dtd"
+PODCAST_NS = "https://podcastindex.org/namespace/1.0"
+
+# Values of <itunes:episodeType>
+EPISODE_TYPES = {"full", "trailer", "bonus"}
+
+# Feed bodies are read this many bytes at a time, up to RSS_MAX_FEED_BYTES
+FEED_CHUNK_BYTES = 64 * 1024
+
+
+@dataclass
+class CachedFeed:
+    """A parsed feed and the validators to revalidate it with."""
+    podcast_data: Dict[str, Any]
+    episodes: List[Dict[str, Any]]
+    etag: Optional[str]
+    last_modified: Optional[str]
+    fetched_at: float
+
+
+class FeedCache:
+    """
+    Parsed feeds by URL, least recently used evicted first.
+
+    Subscribing, creating a bulk job and refreshing metadata often read the
+    same feed within minutes. An entry younger than RSS_CACHE_TTL_SECONDS is
+    served without a request; an older one is revalidated with its ETag /
+    Last-Modified, and a 304 serves it again without re-parsing.
+    """
+
+    def __init__(self):
+        self._entries: "OrderedDict[str, CachedFeed]" = OrderedDict()
+
+    def get(self, rss_url: str) -> Optional[CachedFeed]:
+        entry = self._entries.get(rss_url)
+        if entry:
+            self._entries.move_to_end(rss_url)
+        return entry
+
+    def put(self, rss_url: str, entry: CachedFeed):
+        if settings.rss_cache_max_entries <= 0:
+            return
+        self._entries[rss_url] = entry
+        self._entries.move_to_end(rss_url)
+        while len(self._entries) > settings.rss_cache_max_entries:
+            self._entries.popitem(last=False)
+
+    def clear(self):
+        self._entries.clear()
 
 
 class RSSParser:
     """RSS feed parser for extracting podcast information."""
 
+    feed_cache = FeedCache()
+
     @staticmethod
     async def _fetch_rss_content(rss_url: str) -> str:
         """
@@ -29,23 +85,74 @@ class RSSParser:
         Raises:
             ValueError: If feed cannot be fetched or times out
         """
+        _, content, _, _ = await RSSParser._fetch_rss(rss_url)
+        return content
+
+    @staticmethod
+    async def _fetch_rss(
+        rss_url: str,
+        etag: Optional[str] = None,
+        last_modified: Optional[str] = None
+    ) -> Tuple[int, str, Optional[str], Optional[str]]:
+        """
+        Fetch RSS feed content, conditionally when validators are given.
+
+        The whole request is bounded by RSS_FETCH_TIMEOUT_SECONDS and the
+        body by RSS_MAX_FEED_BYTES, so a slow or huge feed can't hold up
+        subscribing or a bulk job.
+
+        Args:
+            rss_url: URL of the RSS feed
+            etag: ETag of the copy held, sent as If-None-Match
+            last_modified: Last-Modified of the copy held, sent as If-Modified-Since
+
+        Returns:
+            Tuple of (HTTP status, content, ETag, Last-Modified); content is
+            empty when the status is 304
+
+        Raises:
+            ValueError: If feed cannot be fetched, times out or is too large
+        """
+        headers = {}
+        if etag:
+            headers["If-None-Match"] = etag
+        if last_modified:
+            headers["If-Modified-Since"] = last_modified
         try:
             logger.info(f"Fetching RSS feed from: {rss_url}")
             async with aiohttp.ClientSession() as session:
                 async with session.get(
                     rss_url,
-                    timeout=aiohttp.ClientTimeout(total=RSS_FETCH_TIMEOUT)
+                    headers=headers,
+                    timeout=aiohttp.ClientTimeout(total=settings.rss_fetch_timeout_seconds)
                 ) as response:
+                    validators = (response.headers.get("ETag"), response.headers.get("Last-Modified"))
+                    if response.status == 304 and headers:
+                        logger.info(f"RSS feed not modified: {rss_url}")
+                        return 304, "", *validators
                     if response.status != 200:
                         raise ValueError(f"HTTP {response.status}: Failed to fetch RSS feed")
 
-                    content = await response.text()
+                    max_bytes = settings.rss_max_feed_bytes
+                    if (response.content_length or 0) > max_bytes:
+                        raise ValueError(f"RSS feed is larger than {max_bytes} bytes")
+                    # Feeds without a Content-Length (chunked responses) are cut off once they exceed it
+                    chunks = []
+                    received = 0
+                    async for chunk in response.content.iter_chunked(FEED_CHUNK_BYTES):
+                        received += len(chunk)
+                        if received > max_bytes:
+                            raise ValueError(f"RSS feed is larger than {max_bytes} bytes")
+                        chunks.append(chunk)
+                    content = b"".join(chunks).decode(response.get_encoding(), errors="replace")
                     logger.info(f"Successfully fetched RSS feed ({len(content)} bytes)")
-                    return content
+                    return 200, content, *validators
 
         except asyncio.TimeoutError:
             logger.error(f"Timeout fetching RSS feed from {rss_url}")
-            raise ValueError(f"Request timeout: RSS feed took longer than {RSS_FETCH_TIMEOUT} seconds to respond")
+            raise ValueError(
+                f"Request timeout: RSS feed took longer than {settings.rss_fetch_timeout_seconds} seconds to respond"
+            )
         except aiohttp.ClientError as e:
             logger.error(f"Network error fetching RSS feed: {e}")
             raise ValueError(f"Network error: {str(e)}")
@@ -92,6 +199,8 @@ class RSSParser:
                 "description": feed.feed.get("description") or feed.feed.get("subtitle"),
                 "image_url": RSSParser._extract_image_url(feed.feed),
                 "author": feed.feed.get("author") or feed.feed.get("itunes_author"),
+                "categories": RSSParser._extract_categories(content, feed.feed),
+                "explicit": RSSParser._extract_explicit(feed.feed),
             }
 
             logger.info(f"Successfully parsed podcast: {podcast_data['title']}")
@@ -129,6 +238,86 @@ class RSSParser:
 
         return None
 
+    @staticmethod
+    def _extract_categories(content, feed_data: dict) -> List[Dict[str, Any]]:
+        """
+        Extract iTunes categories with their subcategories.
+
+        feedparser flattens nested <itunes:category> elements, so the channel
+        is read again with ElementTree; if the XML doesn't parse, the flat
+        categories feedparser found are returned without subcategories.
+
+        Args:
+            content: Raw feed content
+            feed_data: Feed data dictionary
+
+        Returns:
+            Categories ({"name", "subcategories"}) in feed order
+        """
+        categories: Dict[str, List[str]] = {}
+        try:
+            channel = ElementTree.fromstring(content).find("channel")
+            elements = channel.findall(f"{{{ITUNES_NS}}}category") if channel is not None else []
+            for element in elements:
+                name = (element.get("text") or "").strip()
+                if not name:
+                    continue
+                subcategories = categories.setdefault(name, [])
+                for sub in element.findall(f"{{{ITUNES_NS}}}category"):
+                    sub_name = (sub.get("text") or "").strip()
+                    if sub_name and sub_name not in subcategories:
+                        subcategories.append(sub_name)
+        except (ElementTree.ParseError, ValueError):
+            for tag in feed_data.get("tags") or []:
+                if "itunes" in (tag.get("scheme") or "") and tag.get("term"):
+                    categories.setdefault(tag["term"], [])
+
+        return [{"name": name, "subcategories": subs} for name, subs in categories.items()]
+
+    @staticmethod
+    def _extract_explicit(data: dict) -> Optional[bool]:
+        """<itunes:explicit> of a feed or entry, None if it isn't set."""
+        explicit = data.get("itunes_explicit")
+        return None if explicit is None else bool(explicit)
+
+    @staticmethod
+    def _extract_item_persons(content) -> Optional[List[List[Dict[str, Any]]]]:
+        """
+        People named by each item's <podcast:person> tags, in item order.
+
+        feedparser keeps only one tag per element, so items are read again
+        with ElementTree. The role defaults to host, as in the namespace spec.
+
+        Returns:
+            Persons ({"name", "role", "group", "href", "img"}) per item, or
+            None if the XML doesn't parse
+        """
+        try:
+            channel = ElementTree.fromstring(content).find("channel")
+        except (ElementTree.ParseError, ValueError):
+            return None
+        items = []
+        for item in channel.findall("item") if channel is not None else []:
+            persons = []
+            for element in item.findall(f"{{{PODCAST_NS}}}person"):
+                name = (element.text or "").strip()
+                if not name:
+                    continue
+                person = {"name": name, "role": (element.get("role") or "host").strip().lower()}
+                for attribute in ("group", "href", "img"):
+                    value = (element.get(attribute) or "").strip()
+                    if value:
+                        person[attribute] = value.lower() if attribute == "group" else value
+                persons.append(person)
+            items.append(persons)
+        return items
+
+    @staticmethod
+    def _extract_episode_type(entry: dict) -> Optional[str]:
+        """<itunes:episodeType> (full, trailer or bonus) of an entry."""
+        episode_type = (entry.get("itunes_episodetype") or "").strip().lower()
+        return episode_type if episode_type in EPISODE_TYPES else None
+
     @staticmethod
     async def parse_episodes(rss_url: str, limit: Optional[int] = None) -> list:
         """
@@ -167,6 +356,8 @@ class RSSParser:
                     "audio_url": RSSParser._extract_audio_url(entry),
                     "published_date": RSSParser._parse_published_date(entry),
                     "duration_minutes": RSSParser._extract_duration(entry),
+                    "episode_type": RSSParser._extract_episode_type(entry),
+                    "explicit": RSSParser._extract_explicit(entry),
                 }
                 episodes.append(episode_data)
 
@@ -182,18 +373,18 @@ class RSSParser:
 
     @staticmethod
     def _extract_audio_url(entry: dict) -> Optional[str]:
-        """Extract audio URL from episode entry."""
+        """Extract the (normalized) audio URL from episode entry."""
         # Check enclosures for audio files
         if 'enclosures' in entry:
             for enclosure in entry.enclosures:
                 if enclosure.get('type', '').startswith('audio/'):
-                    return enclosure.get('href') or enclosure.get('url')
+                    return normalize_url(enclosure.get('href') or enclosure.get('url'))
 
         # Check links
         if 'links' in entry:
             for link in entry.links:
                 if link.get('type', '').startswith('audio/'):
-                    return link.get('href')
+                    return normalize_url(link.get('href'))
 
         return None
 
@@ -245,7 +436,8 @@ rss_parser = RSSParser()
 async def parse_rss_feed(rss_url: str):
     """
     Parse RSS feed and return podcast data and episodes.
-    Optimized to fetch the feed only once.
+    Optimized to fetch the feed only once, and served from the feed cache
+    when it was parsed recently (see FeedCache).
 
     Args:
         rss_url: URL of the RSS feed
@@ -253,9 +445,29 @@ async def parse_rss_feed(rss_url: str):
     Returns:
         Tuple of (podcast_data, episodes)
     """
-    # Fetch RSS content once with timeout
-    content = await rss_parser._fetch_rss_content(rss_url)
-
+    cached = RSSParser.feed_cache.get(rss_url)
+    now = time.monotonic()
+    if cached and now - cached.fetched_at < settings.rss_cache_ttl_seconds:
+        logger.info(f"Using cached RSS feed: {rss_url}")
+        return copy.deepcopy((cached.podcast_data, cached.episodes))
+
+    status, content, etag, last_modified = await RSSParser._fetch_rss(
+        rss_url,
+        etag=cached.etag if cached else None,
+        last_modified=cached.last_modified if cached else None
+    )
+    if status == 304:
+        cached.fetched_at = now
+        RSSParser.feed_cache.put(rss_url, cached)
+        return copy.deepcopy((cached.podcast_data, cached.episodes))
+
+    podcast_data, episodes = _parse_feed_content(content)
+    RSSParser.feed_cache.put(rss_url, CachedFeed(podcast_data, episodes, etag, last_modified, now))
+    return copy.deepcopy((podcast_data, episodes))
+
+
+def _parse_feed_content(content: str) -> Tuple[Dict[str, Any], List[Dict[str, Any]]]:
+    """Parse feed content into podcast data and episodes."""
     # Parse the content for both podcast data and episodes
     feed = feedparser.parse(content)
 
@@ -274,17 +486,25 @@ async def parse_rss_feed(rss_url: str):
         "description": feed.feed.get("description") or feed.feed.get("subtitle"),
         "image_url": rss_parser._extract_image_url(feed.feed),
         "author": feed.feed.get("author") or feed.feed.get("itunes_author"),
+        "categories": rss_parser._extract_categories(content, feed.feed),
+        "explicit": rss_parser._extract_explicit(feed.feed),
     }
 
     # Extract episodes
     episodes = []
-    for entry in feed.entries:
+    item_persons = rss_parser._extract_item_persons(content)
+    if item_persons is not None and len(item_persons) != len(feed.entries):
+        item_persons = None
+    for index, entry in enumerate(feed.entries):
         episode_data = {
             "title": entry.get("title", "Untitled Episode"),
             "description": entry.get("description") or entry.get("summary"),
             "audio_url": rss_parser._extract_audio_url(entry),
             "published_date": rss_parser._parse_published_date(entry),
             "duration_minutes": rss_parser._extract_duration(entry),
+            "episode_type": rss_parser._extract_episode_type(entry),
+            "explicit": rss_parser._extract_explicit(entry),
+            "persons": item_persons[index] if item_persons else [],
         }
         episodes.append(episode_data)
 
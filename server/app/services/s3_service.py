@@ -1,28 +1,41 @@
 """AWS S3 service for handling transcript storage and retrieval."""
+import asyncio
+import base64
+import hashlib
 import logging
 import boto3
 from botocore.exceptions import ClientError, NoCredentialsError
-from typing import Optional
+from typing import Any, BinaryIO, Dict, List, Optional
 from app.config import settings
 
 logger = logging.getLogger(__name__)
 
 
 class S3Service:
-    """Service for interacting with AWS S3."""
+    """
+    Service for interacting with AWS S3.
+
+    Methods work on the transcripts bucket in AWS_REGION unless given
+    another bucket and its region (see storage_locations.py).
+    """
 
     def __init__(self):
         """Initialize S3 client."""
-        self._client = None
+        self._clients: Dict[str, Any] = {}
 
     @property
     def client(self):
-        """Lazy initialization of S3 client."""
-        if self._client is None:
+        """S3 client for AWS_REGION."""
+        return self.client_for(None)
+
+    def client_for(self, region: Optional[str]):
+        """Lazy initialization of the S3 client for a region (None = AWS_REGION)."""
+        region = region or settings.aws_region
+        if region not in self._clients:
             try:
                 # Initialize boto3 S3 client
                 client_kwargs = {
-                    'region_name': settings.aws_region
+                    'region_name': region
                 }
 
                 # Add endpoint URL if configured (for Minio/LocalStack)
@@ -34,21 +47,32 @@ class S3Service:
                     client_kwargs['aws_access_key_id'] = settings.aws_access_key_id
                     client_kwargs['aws_secret_access_key'] = settings.aws_secret_access_key
 
-                self._client = boto3.client('s3', **client_kwargs)
+                self._clients[region] = boto3.client('s3', **client_kwargs)
 
-                logger.info(f"S3 client initialized successfully (endpoint: {settings.aws_endpoint_url or 'default AWS'})")
+                logger.info(
+                    f"S3 client initialized successfully for {region} "
+                    f"(endpoint: {settings.aws_endpoint_url or 'default AWS'})"
+                )
             except Exception as e:
                 logger.error(f"Failed to initialize S3 client: {e}")
                 raise
 
-        return self._client
+        return self._clients[region]
+
+    def reset_client(self):
+        """Drop the clients so the next call uses the current credentials."""
+        self._clients = {}
 
-    async def get_transcript(self, s3_key: str) -> Optional[str]:
+    async def get_transcript(
+        self, s3_key: str, bucket: Optional[str] = None, region: Optional[str] = None
+    ) -> Optional[str]:
         """
         Retrieve transcript from S3.
 
         Args:
             s3_key: S3 object key for the transcript
+            bucket: Bucket holding it (default: the transcripts bucket)
+            region: The bucket's region (default: AWS_REGION)
 
         Returns:
             Transcript text if found, None otherwise
@@ -59,8 +83,9 @@ class S3Service:
         try:
             logger.info(f"Retrieving transcript from S3: {s3_key}")
 
-            response = self.client.get_object(
-                Bucket=settings.s3_bucket_name,
+            bucket = bucket or settings.s3_bucket_name
+            response = self.client_for(region).get_object(
+                Bucket=bucket,
                 Key=s3_key
             )
 
@@ -77,8 +102,8 @@ class S3Service:
                 logger.warning(f"Transcript not found in S3: {s3_key}")
                 return None
             elif error_code == 'NoSuchBucket':
-                logger.error(f"S3 bucket not found: {settings.s3_bucket_name}")
-                raise ValueError(f"S3 bucket '{settings.s3_bucket_name}' does not exist")
+                logger.error(f"S3 bucket not found: {bucket}")
+                raise ValueError(f"S3 bucket '{bucket}' does not exist")
             else:
                 logger.error(f"S3 client error retrieving transcript: {e}")
                 raise Exception(f"Failed to retrieve transcript from S3: {str(e)}")
@@ -116,13 +141,45 @@ class S3Service:
             logger.error(f"Unexpected error checking transcript: {e}")
             return False
 
-    async def upload_transcript(self, s3_key: str, transcript_text: str) -> bool:
+    async def object_exists(self, s3_key: str, bucket: Optional[str] = None, region: Optional[str] = None) -> bool:
+        """
+        Check if an object exists in the transcripts bucket (or another bucket).
+
+        Returns:
+            True if the object exists, False otherwise (including on errors)
+        """
+        try:
+            self.client_for(region).head_object(Bucket=bucket or settings.s3_bucket_name, Key=s3_key)
+            return True
+        except ClientError as e:
+            if e.response['Error']['Code'] not in ('404', 'NoSuchKey'):
+                logger.error(f"Error checking object existence: {e}")
+            return False
+        except Exception as e:
+            logger.error(f"Unexpected error checking object: {e}")
+            return False
+
+    async def upload_transcript(
+        self,
+        s3_key: str,
+        transcript_text: str,
+        sha256: Optional[str] = None,
+        bucket: Optional[str] = None,
+        region: Optional[str] = None,
+        kms_key_id: Optional[str] = None,
+    ) -> bool:
         """
         Upload transcript to S3.
 
         Args:
             s3_key: S3 object key for the transcript
             transcript_text: Transcript content to upload
+            sha256: Hex SHA-256 of the content; S3 rejects the upload if it
+                doesn't match and keeps it as the object's checksum
+            bucket: Bucket to upload to (default: the transcripts bucket)
+            region: The bucket's region (default: AWS_REGION)
+            kms_key_id: Encrypt the object with this KMS key (SSE-KMS)
+                rather than the bucket's default encryption
 
         Returns:
             True if upload successful, False otherwise
@@ -130,11 +187,21 @@ class S3Service:
         try:
             logger.info(f"Uploading transcript to S3: {s3_key}")
 
-            self.client.put_object(
-                Bucket=settings.s3_bucket_name,
+            extra: Dict[str, Any] = {}
+            if sha256:
+                extra = {
+                    "ChecksumSHA256": base64.b64encode(bytes.fromhex(sha256)).decode(),
+                    "Metadata": {"sha256": sha256},
+                }
+            if kms_key_id:
+                extra.update({"ServerSideEncryption": "aws:kms", "SSEKMSKeyId": kms_key_id})
+            self.client_for(region).put_object(
+                Bucket=bucket or settings.s3_bucket_name,
                 Key=s3_key,
                 Body=transcript_text.encode('utf-8'),
-                ContentType='text/plain'
+                ContentType='text/plain',
+                StorageClass=settings.transcript_storage_class,
+                **extra
             )
 
             logger.info(f"Successfully uploaded transcript: {s3_key}")
@@ -144,6 +211,230 @@ class S3Service:
             logger.error(f"Failed to upload transcript to S3: {e}")
             return False
 
+    async def object_sha256(
+        self, s3_key: str, bucket: Optional[str] = None, region: Optional[str] = None
+    ) -> Optional[str]:
+        """
+        Hex SHA-256 of an object in the transcripts bucket.
+
+        Taken from the checksum S3 stored with the object (or the sha256
+        metadata of stores that don't keep checksums); objects uploaded
+        without either are downloaded and hashed.
+
+        Returns:
+            The digest, or None if the object doesn't exist or can't be read
+        """
+        try:
+            client = self.client_for(region)
+            bucket = bucket or settings.s3_bucket_name
+            head = client.head_object(Bucket=bucket, Key=s3_key, ChecksumMode="ENABLED")
+            checksum = head.get("ChecksumSHA256")
+            # Multipart checksums ("...-<parts>") are of the parts, not the content
+            if checksum and "-" not in checksum:
+                return base64.b64decode(checksum).hex()
+            if head.get("Metadata", {}).get("sha256"):
+                return head["Metadata"]["sha256"]
+            response = client.get_object(Bucket=bucket, Key=s3_key)
+            return hashlib.sha256(response["Body"].read()).hexdigest()
+        except ClientError as e:
+            if e.response['Error']['Code'] not in ('404', 'NoSuchKey'):
+                logger.error(f"Error reading checksum of {s3_key}: {e}")
+            return None
+        except Exception as e:
+            logger.error(f"Unexpected error reading checksum of {s3_key}: {e}")
+            return None
+
+    async def upload_bytes(
+        self,
+        s3_key: str,
+        data: bytes,
+        content_type: str,
+        bucket: Optional[str] = None,
+        region: Optional[str] = None,
+    ) -> bool:
+        """
+        Upload arbitrary binary content to the transcripts bucket.
+
+        Args:
+            s3_key: S3 object key
+            data: Content to upload
+            content_type: MIME type of the content
+            bucket: Another bucket to upload to (e.g. the audio bucket)
+            region: The bucket's region (default: AWS_REGION)
+
+        Returns:
+            True if upload successful, False otherwise
+        """
+        try:
+            logger.info(f"Uploading {len(data)} bytes to S3: {s3_key}")
+
+            self.client_for(region).put_object(
+                Bucket=bucket or settings.s3_bucket_name,
+                Key=s3_key,
+                Body=data,
+                ContentType=content_type
+            )
+
+            return True
+
+        except Exception as e:
+            logger.error(f"Failed to upload object to S3: {e}")
+            return False
+
+    async def upload_fileobj(
+        self,
+        s3_key: str,
+        fileobj: BinaryIO,
+        content_type: str,
+        bucket: Optional[str] = None,
+        region: Optional[str] = None,
+    ) -> bool:
+        """
+        Upload a file to the transcripts bucket without reading it into memory.
+
+        boto3's managed transfer switches to a multipart upload for large
+        files; it runs in a thread so the event loop isn't blocked meanwhile.
+
+        Args:
+            s3_key: S3 object key
+            fileobj: Binary file positioned at the start of the content
+            content_type: MIME type of the content
+            bucket: Another bucket to upload to (e.g. the audio bucket)
+            region: The bucket's region (default: AWS_REGION)
+
+        Returns:
+            True if upload successful, False otherwise
+        """
+        try:
+            logger.info(f"Uploading file to S3: {s3_key}")
+            await asyncio.to_thread(
+                self.client_for(region).upload_fileobj,
+                fileobj,
+                bucket or settings.s3_bucket_name,
+                s3_key,
+                ExtraArgs={"ContentType": content_type},
+            )
+            return True
+
+        except Exception as e:
+            logger.error(f"Failed to upload file to S3: {e}")
+            return False
+
+    async def list_objects(self, prefix: str) -> List[Dict[str, Any]]:
+        """
+        List the objects under a prefix in the transcripts bucket.
+
+        Args:
+            prefix: Key prefix, e.g. "transcripts/"
+
+        Returns:
+            Objects as {"key", "size", "last_modified"} (naive UTC)
+        """
+        objects = []
+        paginator = self.client.get_paginator("list_objects_v2")
+        for page in paginator.paginate(Bucket=settings.s3_bucket_name, Prefix=prefix):
+            for item in page.get("Contents", []):
+                objects.append({
+                    "key": item["Key"],
+                    "size": item["Size"],
+                    "last_modified": item["LastModified"].replace(tzinfo=None),
+                })
+        return objects
+
+    async def delete_object(self, s3_key: str, bucket: Optional[str] = None, region: Optional[str] = None) -> bool:
+        """
+        Delete an object from the transcripts bucket (or another bucket).
+
+        Args:
+            s3_key: S3 object key
+            bucket: Bucket holding it (default: the transcripts bucket)
+            region: The bucket's region (default: AWS_REGION)
+
+        Returns:
+            True if deleted (or already absent), False otherwise
+        """
+        try:
+            logger.info(f"Deleting S3 object: {s3_key}")
+            self.client_for(region).delete_object(Bucket=bucket or settings.s3_bucket_name, Key=s3_key)
+            return True
+
+        except Exception as e:
+            logger.error(f"Failed to delete S3 object {s3_key}: {e}")
+            return False
+
+    async def move_object(
+        self,
+        source_key: str,
+        dest_key: str,
+        storage_class: Optional[str] = None,
+        bucket: Optional[str] = None,
+        region: Optional[str] = None,
+    ) -> bool:
+        """
+        Move an object within the transcripts bucket (or another bucket).
+
+        Args:
+            source_key: Current S3 object key
+            dest_key: New S3 object key
+            storage_class: Storage class for the new object (e.g. GLACIER_IR);
+                defaults to STANDARD. An object encrypted with a KMS key
+                keeps its key.
+            bucket: Bucket holding it (default: the transcripts bucket)
+            region: The bucket's region (default: AWS_REGION)
+
+        Returns:
+            True if the object was moved, False otherwise
+        """
+        try:
+            logger.info(f"Moving S3 object {source_key} -> {dest_key}")
+
+            client = self.client_for(region)
+            bucket = bucket or settings.s3_bucket_name
+            extra: Dict[str, Any] = {}
+            source = client.head_object(Bucket=bucket, Key=source_key)
+            if source.get("ServerSideEncryption") == "aws:kms":
+                # Copies otherwise get the bucket's default encryption
+                extra = {"ServerSideEncryption": "aws:kms", "SSEKMSKeyId": source["SSEKMSKeyId"]}
+            client.copy_object(
+                Bucket=bucket,
+                Key=dest_key,
+                CopySource={'Bucket': bucket, 'Key': source_key},
+                StorageClass=storage_class or 'STANDARD',
+                **extra
+            )
+            client.delete_object(Bucket=bucket, Key=source_key)
+
+            return True
+
+        except Exception as e:
+            logger.error(f"Failed to move S3 object {source_key}: {e}")
+            return False
+
+    def generate_presigned_url(
+        self,
+        s3_key: str,
+        expires_in: int = 3600,
+        bucket: Optional[str] = None,
+        region: Optional[str] = None,
+    ) -> str:
+        """
+        Generate a presigned GET URL for an object in the transcripts bucket.
+
+        Args:
+            s3_key: S3 object key
+            expires_in: URL lifetime in seconds
+            bucket: Another bucket to sign for (e.g. the audio bucket)
+            region: The bucket's region (default: AWS_REGION)
+
+        Returns:
+            Presigned URL
+        """
+        return self.client_for(region).generate_presigned_url(
+            'get_object',
+            Params={'Bucket': bucket or settings.s3_bucket_name, 'Key': s3_key},
+            ExpiresIn=expires_in
+        )
+
 
 # Create singleton instance
 s3_service = S3Service()
@@ -0,0 +1,103 @@
+"""Revocable public links to episode transcripts.
+
+A share link is an unguessable token in the share_links collection. Anyone
+holding it can read the episode's transcript at /share/<token> until the
+link expires or is revoked; nothing else is exposed.
+"""
+import logging
+import secrets
+from datetime import datetime, timedelta
+from typing import Any, Dict, List, Optional, Tuple
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.config import settings
+from app.services.private_transcripts import is_private
+from app.services.s3_service import s3_service
+from app.services.storage_locations import transcript_location
+
+logger = logging.getLogger(__name__)
+
+# Bytes of randomness in each token (URL-safe base64, ~22 characters)
+TOKEN_BYTES = 16
+
+
+class ShareLinkService:
+    """Creates, revokes and resolves transcript share links."""
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+        self.links_collection = db.share_links
+
+    @staticmethod
+    def share_url(token: str) -> str:
+        """Public URL of a share link."""
+        return f"{settings.public_base_url.rstrip('/')}/share/{token}"
+
+    async def create(self, episode: Dict[str, Any], expires_in_hours: Optional[int] = None) -> Dict[str, Any]:
+        """
+        Mint a share link for an episode.
+
+        Args:
+            episode: Episode document
+            expires_in_hours: Lifetime of the link (None = until revoked)
+
+        Returns:
+            The share link document
+        """
+        now = datetime.utcnow()
+        link = {
+            "token": secrets.token_urlsafe(TOKEN_BYTES),
+            "episode_id": episode["episode_id"],
+            "podcast_id": episode["podcast_id"],
+            "created_at": now,
+            "expires_at": now + timedelta(hours=expires_in_hours) if expires_in_hours else None,
+            "revoked_at": None,
+        }
+        await self.links_collection.insert_one(link)
+        link.pop("_id", None)
+        return link
+
+    async def list_for_episode(self, episode_id: str) -> List[Dict[str, Any]]:
+        """All share links of an episode, newest first, including revoked ones."""
+        cursor = self.links_collection.find({"episode_id": episode_id}, {"_id": 0}).sort("created_at", -1)
+        return await cursor.to_list(length=None)
+
+    async def revoke(self, episode_id: str, token: str) -> bool:
+        """Revoke a link; False if the episode has no such active link."""
+        result = await self.links_collection.update_one(
+            {"episode_id": episode_id, "token": token, "revoked_at": None},
+            {"$set": {"revoked_at": datetime.utcnow()}}
+        )
+        return result.modified_count > 0
+
+    async def resolve(self, token: str) -> Optional[Tuple[Dict[str, Any], Dict[str, Any]]]:
+        """
+        Look up an active link and its episode.
+
+        Returns:
+            (link, episode), or None if the link is unknown, revoked or
+            expired, or its episode is gone or was made private since
+        """
+        link = await self.links_collection.find_one({"token": token, "revoked_at": None}, {"_id": 0})
+        if not link or (link["expires_at"] and link["expires_at"] <= datetime.utcnow()):
+            return None
+        episode = await self.db.episodes.find_one({"episode_id": link["episode_id"], "deleted_at": None})
+        if not episode or await is_private(self.db, episode.get("podcast_id")):
+            return None
+        return link, episode
+
+    @staticmethod
+    async def load_transcript(episode: Dict[str, Any]) -> Optional[str]:
+        """An episode's transcript from S3, falling back to MongoDB."""
+        transcript_s3_key = episode.get("transcript_s3_key")
+        if transcript_s3_key:
+            try:
+                transcript = await s3_service.get_transcript(
+                    transcript_s3_key, **transcript_location(episode.get("storage"))
+                )
+                if transcript:
+                    return transcript
+            except Exception as e:
+                logger.error(f"Failed to fetch shared transcript from S3: {e}")
+        return episode.get("transcript_text")
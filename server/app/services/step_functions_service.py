@@ -1,15 +1,46 @@
 """Service for interacting with AWS Step Functions."""
 import logging
 import json
-import time
-from typing import Dict, Any
+import secrets
+from datetime import datetime
+from typing import Dict, Any, Optional
 import boto3
 from botocore.exceptions import ClientError
 
 from app.config import settings
+from app.services.storage_locations import audio_location
 
 logger = logging.getLogger(__name__)
 
+# Execution names are limited to 80 characters; see execution_name
+EXECUTION_EPISODE_ID_LENGTH = 40
+
+# Pipeline stage of each top-level state in the transcription state machine
+STATE_STAGES = {
+    "DownloadAndChunk": "chunking",
+    "PrepareForMapping": "chunking",
+    "TranscribeChunks": "transcribing",
+    "MergeTranscripts": "merging",
+    "ProcessingComplete": "completed",
+    "HandleFailure": "failed",
+    "WorkflowFailed": "failed",
+}
+
+
+def execution_name(episode_id: str) -> str:
+    """
+    A fresh execution name for an episode, as the poll Lambda generates
+    them: a shortened episode ID, the start time and a random suffix, so
+    two starts in the same second don't collide.
+    """
+    started = datetime.utcnow().strftime("%Y%m%dT%H%M%S")
+    return f"ep-{episode_id[:EXECUTION_EPISODE_ID_LENGTH]}-{started}-{secrets.token_hex(4)}"
+
+
+def execution_arn(state_machine_arn: str, name: str) -> str:
+    """The ARN of a named execution of a standard workflow."""
+    return state_machine_arn.replace(":stateMachine:", ":execution:", 1) + f":{name}"
+
 
 class StepFunctionsService:
     """Service for triggering Step Functions state machine executions."""
@@ -38,7 +69,9 @@ class StepFunctionsService:
         self,
         episode_id: str,
         audio_url: str,
-        s3_bucket: str = None
+        s3_bucket: str = None,
+        storage: Optional[Dict[str, Any]] = None,
+        encryption: Optional[Dict[str, str]] = None
     ) -> Dict[str, Any]:
         """
         Trigger Step Functions state machine for episode transcription.
@@ -47,6 +80,10 @@ class StepFunctionsService:
             episode_id: ID of the episode to transcribe
             audio_url: URL of the audio file
             s3_bucket: S3 bucket name (optional, uses default if not provided)
+            storage: The podcast's storage location, whose audio bucket and
+                region the execution works in (see storage_locations.py)
+            encryption: The workspace ID and KMS key the merged transcript
+                is encrypted with, for private podcasts (see private_transcripts.py)
 
         Returns:
             Dict containing execution ARN and start date
@@ -61,19 +98,23 @@ class StepFunctionsService:
                 "Cannot trigger transcription."
             )
 
-        # Use default S3 bucket if not provided
+        # Use the storage location's bucket, or the default S3 bucket if not provided
+        location = audio_location(storage) if storage else {}
         if not s3_bucket:
-            s3_bucket = settings.s3_bucket_name
+            s3_bucket = location.get("bucket") or settings.s3_bucket_name
 
-        # Prepare input for Step Functions
+        # Prepare input for Step Functions; the state machine reads
+        # s3_region, storage and encryption even when they are null
         step_input = {
             "episode_id": episode_id,
             "audio_url": audio_url,
-            "s3_bucket": s3_bucket
+            "s3_bucket": s3_bucket,
+            "s3_region": location.get("region"),
+            "storage": storage,
+            "encryption": encryption
         }
 
-        # Generate unique execution name
-        execution_name = f"episode-{episode_id}-{int(time.time())}"
+        name = execution_name(episode_id)
 
         try:
             logger.info(
@@ -82,11 +123,21 @@ class StepFunctionsService:
             logger.debug(f"Step Functions input: {step_input}")
 
             # Start Step Functions execution
-            response = self.sfn_client.start_execution(
-                stateMachineArn=settings.step_function_arn,
-                name=execution_name,
-                input=json.dumps(step_input)
-            )
+            try:
+                response = self.sfn_client.start_execution(
+                    stateMachineArn=settings.step_function_arn,
+                    name=name,
+                    input=json.dumps(step_input)
+                )
+            except ClientError as e:
+                # A retried start whose first attempt went through
+                if e.response.get("Error", {}).get("Code") != "ExecutionAlreadyExists":
+                    raise
+                logger.info(f"Execution {name} already exists, treating the start as done")
+                return {
+                    "execution_arn": execution_arn(settings.step_function_arn, name),
+                    "start_date": datetime.utcnow().isoformat()
+                }
 
             logger.info(
                 f"Successfully started Step Functions execution: {response['executionArn']}"
@@ -104,6 +155,61 @@ class StepFunctionsService:
             logger.error(f"Unexpected error triggering Step Functions: {e}")
             raise
 
+    async def describe_pipeline(self, execution_arn: str) -> Dict[str, Any]:
+        """
+        Describe where an execution is in the transcription pipeline.
+
+        The stage comes from the last top-level state entered, and chunk
+        progress from the TranscribeChunks Map state's iterations.
+
+        Args:
+            execution_arn: ARN of the execution
+
+        Returns:
+            Dict with execution_status, stage, current_state, chunks_completed,
+            chunks_total, started_at, stopped_at and error
+
+        Raises:
+            ClientError: If the execution can't be read
+        """
+        execution = self.sfn_client.describe_execution(executionArn=execution_arn)
+
+        current_state: Optional[str] = None
+        chunks_total: Optional[int] = None
+        chunks_completed = 0
+        paginator = self.sfn_client.get_paginator("get_execution_history")
+        for page in paginator.paginate(executionArn=execution_arn, includeExecutionData=False):
+            for event in page["events"]:
+                event_type = event["type"]
+                if event_type.endswith("StateEntered"):
+                    name = event.get("stateEnteredEventDetails", {}).get("name")
+                    # Iterator states inside the Map are reported as chunks instead
+                    if name in STATE_STAGES:
+                        current_state = name
+                elif event_type == "MapStateStarted":
+                    chunks_total = event.get("mapStateStartedEventDetails", {}).get("length")
+                elif event_type == "MapIterationSucceeded":
+                    chunks_completed += 1
+
+        execution_status = execution["status"]
+        if execution_status == "SUCCEEDED":
+            stage = "completed"
+        elif execution_status != "RUNNING":
+            stage = "failed"
+        else:
+            stage = STATE_STAGES.get(current_state)
+
+        return {
+            "execution_status": execution_status,
+            "stage": stage,
+            "current_state": current_state,
+            "chunks_completed": chunks_completed if chunks_total is not None else None,
+            "chunks_total": chunks_total,
+            "started_at": execution.get("startDate"),
+            "stopped_at": execution.get("stopDate"),
+            "error": execution.get("cause") or execution.get("error"),
+        }
+
 
 # Create singleton instance
 step_functions_service = StepFunctionsService()
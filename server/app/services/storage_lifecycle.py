@@ -0,0 +1,120 @@
+"""
+S3 lifecycle rules for pipeline intermediates.
+
+The chunking Lambda stores each episode's audio chunks under chunks/ and the
+Whisper Lambda stores a JSON transcript per chunk (tagged
+artifact=chunk-transcript, since it shares transcripts/<episode_id>/ with
+older final transcripts). Neither is read again once the merge is done, so
+with S3_LIFECYCLE_BOOTSTRAP=true the API installs, at startup, rules that
+delete chunk audio after CHUNK_AUDIO_EXPIRE_DAYS and move chunk transcripts
+to STANDARD_IA after CHUNK_TRANSCRIPT_IA_DAYS and to GLACIER after
+CHUNK_TRANSCRIPT_GLACIER_DAYS, deleting them after
+CHUNK_TRANSCRIPT_EXPIRE_DAYS (0 turns each step off). Chunks and chunk
+transcripts the merge Lambda re-tagged artifact=merged-intermediate once
+merged are deleted after MERGED_INTERMEDIATE_EXPIRE_DAYS.
+
+The rules go on the audio and transcripts buckets, replacing the rules this
+module installed before and keeping any others. Deployments whose buckets
+Terraform manages get the same rules from the s3 module and leave the
+bootstrap off, so the two don't overwrite each other.
+"""
+import logging
+from typing import Any, Dict, List, Optional
+
+from botocore.exceptions import ClientError
+
+from app.config import settings
+from app.services.s3_service import s3_service
+from app.services.storage_locations import configured_locations
+
+logger = logging.getLogger(__name__)
+
+# Rules with this ID prefix are owned (and replaced) by the bootstrap
+RULE_PREFIX = "podcasts-"
+
+CHUNK_AUDIO_PREFIX = "chunks/"
+CHUNK_TRANSCRIPT_TAG = {"Key": "artifact", "Value": "chunk-transcript"}
+MERGED_INTERMEDIATE_TAG = {"Key": "artifact", "Value": "merged-intermediate"}
+
+
+def lifecycle_rules() -> List[Dict[str, Any]]:
+    """The rules the current settings call for."""
+    rules = []
+    if settings.chunk_audio_expire_days:
+        rules.append({
+            "ID": f"{RULE_PREFIX}expire-chunk-audio",
+            "Status": "Enabled",
+            "Filter": {"Prefix": CHUNK_AUDIO_PREFIX},
+            "Expiration": {"Days": settings.chunk_audio_expire_days},
+        })
+
+    transitions = []
+    if settings.chunk_transcript_ia_days:
+        transitions.append({"Days": settings.chunk_transcript_ia_days, "StorageClass": "STANDARD_IA"})
+    if settings.chunk_transcript_glacier_days:
+        transitions.append({"Days": settings.chunk_transcript_glacier_days, "StorageClass": "GLACIER"})
+    if transitions or settings.chunk_transcript_expire_days:
+        rule: Dict[str, Any] = {
+            "ID": f"{RULE_PREFIX}chunk-transcripts",
+            "Status": "Enabled",
+            "Filter": {"Tag": CHUNK_TRANSCRIPT_TAG},
+        }
+        if transitions:
+            rule["Transitions"] = transitions
+        if settings.chunk_transcript_expire_days:
+            rule["Expiration"] = {"Days": settings.chunk_transcript_expire_days}
+        rules.append(rule)
+
+    if settings.merged_intermediate_expire_days:
+        rules.append({
+            "ID": f"{RULE_PREFIX}expire-merged-intermediates",
+            "Status": "Enabled",
+            "Filter": {"Tag": MERGED_INTERMEDIATE_TAG},
+            "Expiration": {"Days": settings.merged_intermediate_expire_days},
+        })
+    return rules
+
+
+def _current_rules(client, bucket: str) -> List[Dict[str, Any]]:
+    try:
+        return client.get_bucket_lifecycle_configuration(Bucket=bucket).get("Rules", [])
+    except ClientError as e:
+        if e.response["Error"]["Code"] == "NoSuchLifecycleConfiguration":
+            return []
+        raise
+
+
+async def apply_lifecycle_rules(bucket: str, region: Optional[str] = None) -> bool:
+    """
+    Install the intermediate rules on a bucket, keeping rules not ours.
+
+    Returns:
+        True if the bucket's configuration was written (or already current)
+    """
+    client = s3_service.client_for(region)
+    try:
+        current = _current_rules(client, bucket)
+        kept = [rule for rule in current if not rule.get("ID", "").startswith(RULE_PREFIX)]
+        rules = kept + lifecycle_rules()
+        if rules == current:
+            return True
+        if rules:
+            client.put_bucket_lifecycle_configuration(
+                Bucket=bucket, LifecycleConfiguration={"Rules": rules}
+            )
+        else:
+            client.delete_bucket_lifecycle(Bucket=bucket)
+        logger.info(f"Applied {len(rules) - len(kept)} lifecycle rule(s) to s3://{bucket}")
+        return True
+    except Exception as e:
+        logger.warning(f"Could not apply lifecycle rules to s3://{bucket}: {e}")
+        return False
+
+
+async def bootstrap_lifecycle():
+    """Apply the rules to the audio and transcripts buckets, and those of each storage location."""
+    for bucket in dict.fromkeys((settings.s3_audio_bucket, settings.s3_bucket_name)):
+        await apply_lifecycle_rules(bucket)
+    for location in configured_locations().values():
+        for bucket in dict.fromkeys((location.get("audio_bucket") or location["bucket"], location["bucket"])):
+            await apply_lifecycle_rules(bucket, location["region"])
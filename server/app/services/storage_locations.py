@@ -0,0 +1,92 @@
+"""
+Per-podcast and per-workspace storage locations.
+
+By default audio goes to S3_AUDIO_BUCKET and transcripts to S3_BUCKET_NAME,
+in AWS_REGION. STORAGE_LOCATIONS names other buckets, in any region, and a
+podcast (PUT /api/podcasts/{id}/storage) or a workspace
+(PUT /api/admin/workspaces/{id}/storage) can be pinned to one, so European
+podcasts' audio and transcripts stay in an EU bucket. The location is
+copied onto the podcast or workspace as its "storage" field, where the poll
+Lambda reads it too; a podcast's own location wins over its workspace's.
+
+New transcriptions run in the location of the episode's podcast: the
+Lambdas get its audio bucket and region (the pipeline keeps chunks and
+transcripts in the audio bucket) and transcripts the API stores go to its
+transcript bucket. Each episode records where its transcript went in its
+own "storage" field, so re-pinning a podcast only affects transcripts made
+afterwards. Episodes without one are in the default buckets.
+"""
+import json
+from typing import Any, Dict, Optional
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.config import settings
+
+
+def configured_locations() -> Dict[str, Dict[str, Any]]:
+    """STORAGE_LOCATIONS by name (validated at startup)."""
+    return json.loads(settings.storage_locations) if settings.storage_locations else {}
+
+
+def location_document(name: str) -> Optional[Dict[str, Any]]:
+    """The storage document for a configured location, None if there's no such location."""
+    location = configured_locations().get(name)
+    if not location:
+        return None
+    return {
+        "location": name,
+        "region": location["region"],
+        "bucket": location["bucket"],
+        "audio_bucket": location.get("audio_bucket") or location["bucket"],
+    }
+
+
+async def podcast_storage(db: AsyncIOMotorDatabase, podcast_id: Optional[str]) -> Optional[Dict[str, Any]]:
+    """Where a podcast's new transcripts go, None for the default buckets."""
+    podcast = await db.podcasts.find_one({"podcast_id": podcast_id}, {"storage": 1, "workspace_id": 1})
+    if not podcast:
+        return None
+    if podcast.get("storage"):
+        return podcast["storage"]
+    if podcast.get("workspace_id"):
+        workspace = await db.workspaces.find_one({"workspace_id": podcast["workspace_id"]}, {"storage": 1})
+        return (workspace or {}).get("storage")
+    return None
+
+
+async def episode_storage(db: AsyncIOMotorDatabase, episode_id: str) -> Optional[Dict[str, Any]]:
+    """Where a new transcript of an episode goes: its podcast's location."""
+    episode = await db.episodes.find_one({"episode_id": episode_id}, {"podcast_id": 1})
+    return await podcast_storage(db, episode and episode.get("podcast_id"))
+
+
+def pipeline_storage(storage: Optional[Dict[str, Any]]) -> Optional[Dict[str, Any]]:
+    """
+    What an episode records for a transcript made by the Lambdas, which keep
+    it in the location's audio bucket (as the merge Lambda records it).
+    """
+    if not storage:
+        return None
+    return {**storage, "bucket": storage["audio_bucket"]}
+
+
+def transcript_location(storage: Optional[Dict[str, Any]]) -> Dict[str, str]:
+    """s3_service arguments for a storage document's transcript bucket."""
+    if not storage:
+        return {}
+    return {"bucket": storage["bucket"], "region": storage["region"]}
+
+
+def same_bucket(storage: Optional[Dict[str, Any]]) -> Dict[str, Any]:
+    """Episode filter for transcripts in the same bucket as storage's."""
+    if not storage:
+        return {"storage": None}
+    return {"storage.bucket": storage["bucket"]}
+
+
+def audio_location(storage: Optional[Dict[str, Any]]) -> Dict[str, str]:
+    """s3_service arguments for a storage document's audio bucket."""
+    if not storage:
+        return {"bucket": settings.s3_audio_bucket}
+    return {"bucket": storage.get("audio_bucket") or storage["bucket"], "region": storage["region"]}
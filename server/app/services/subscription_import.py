@@ -0,0 +1,241 @@
+"""Import subscriptions from Apple Podcasts and Spotify exports.
+
+Apple Podcasts exports subscriptions as OPML (with feed URLs) or JSON;
+Spotify's account data export (a zip, or its JSON files on their own) lists
+saved shows in YourLibrary.json and listened shows in the streaming
+history. Shows without a feed URL are matched against the podcast directory
+(the iTunes Search API) by title and publisher; ambiguous or unknown shows
+are reported with the closest directory results for manual resolution.
+"""
+import asyncio
+import io
+import json
+import logging
+import zipfile
+from typing import Any, Dict, List, Optional, Tuple
+from xml.etree import ElementTree
+
+import aiohttp
+
+from app.cache import cache
+from app.config import settings
+from app.models.schemas import ImportSource
+from app.services.episode_dedup import normalize_title
+from app.url_normalization import normalize_url
+
+logger = logging.getLogger(__name__)
+
+# Directory lookups in flight at once (the iTunes Search API is rate limited)
+DIRECTORY_CONCURRENCY = 4
+DIRECTORY_TIMEOUT = 10
+DIRECTORY_RESULTS = 5
+
+TITLE_KEYS = ("title", "name", "podcastName", "episode_show_name", "collectionName", "Podcast Title", "Show Name")
+AUTHOR_KEYS = ("author", "publisher", "artistName", "Podcast Author")
+FEED_KEYS = ("feedUrl", "feed_url", "xmlUrl", "rss_url", "Feed URL")
+
+
+class ImportFormatError(ValueError):
+    """The uploaded file isn't a recognized export."""
+
+
+def _first(record: Dict[str, Any], keys: Tuple[str, ...]) -> Optional[str]:
+    for key in keys:
+        value = record.get(key)
+        if isinstance(value, str) and value.strip():
+            return value.strip()
+    return None
+
+
+def _show(title: Optional[str], author: Optional[str] = None, rss_url: Optional[str] = None) -> Dict[str, Any]:
+    return {"title": title, "author": author, "rss_url": normalize_url(rss_url)}
+
+
+def parse_opml(content: bytes) -> List[Dict[str, Any]]:
+    """Shows listed as <outline xmlUrl="..."> in an OPML file."""
+    try:
+        root = ElementTree.fromstring(content)
+    except ElementTree.ParseError as e:
+        raise ImportFormatError(f"Invalid OPML: {e}")
+    return [
+        _show(outline.get("title") or outline.get("text"), None, outline.get("xmlUrl"))
+        for outline in root.iter("outline")
+        if outline.get("xmlUrl")
+    ]
+
+
+def _records(data: Any) -> List[Dict[str, Any]]:
+    """The show records in a JSON export, wherever the format nests them."""
+    if isinstance(data, list):
+        return [item for item in data if isinstance(item, dict)]
+    if isinstance(data, dict):
+        for key in ("shows", "podcasts", "subscriptions", "Podcasts"):
+            if isinstance(data.get(key), list):
+                return _records(data[key])
+    return []
+
+
+def parse_json_export(data: Any) -> List[Dict[str, Any]]:
+    """
+    Shows in an Apple Podcasts or Spotify JSON export.
+
+    Handles Spotify's YourLibrary.json ("shows": name/publisher), its
+    podcast streaming history (podcastName or, in the extended history,
+    episode_show_name) and Apple-style lists of title/author/feedUrl.
+    """
+    shows = []
+    for record in _records(data):
+        title = _first(record, TITLE_KEYS)
+        rss_url = _first(record, FEED_KEYS)
+        if title or rss_url:
+            shows.append(_show(title, _first(record, AUTHOR_KEYS), rss_url))
+    return shows
+
+
+def _parse_zip(content: bytes) -> List[Dict[str, Any]]:
+    """Shows in a Spotify account data export archive."""
+    shows = []
+    try:
+        with zipfile.ZipFile(io.BytesIO(content)) as archive:
+            for name in archive.namelist():
+                base = name.rsplit("/", 1)[-1]
+                if base.endswith(".opml"):
+                    shows.extend(parse_opml(archive.read(name)))
+                elif base.endswith(".json") and (
+                    base.startswith("YourLibrary") or "Streaming_History" in base or "StreamingHistory" in base
+                ):
+                    try:
+                        shows.extend(parse_json_export(json.loads(archive.read(name))))
+                    except ValueError:
+                        logger.warning(f"Skipping unreadable export file {name}")
+    except zipfile.BadZipFile as e:
+        raise ImportFormatError(f"Invalid zip archive: {e}")
+    return shows
+
+
+def parse_export(source: ImportSource, filename: str, content: bytes) -> List[Dict[str, Any]]:
+    """
+    Extract the shows from an export file, without duplicates.
+
+    Args:
+        source: Service the export came from
+        filename: Uploaded file name (used to tell formats apart)
+        content: File contents
+
+    Returns:
+        Shows (title, author, rss_url); rss_url is None unless the export has it
+
+    Raises:
+        ImportFormatError: If the file isn't a recognized export
+    """
+    name = (filename or "").lower()
+    if name.endswith(".zip") or content[:4] == b"PK\x03\x04":
+        shows = _parse_zip(content)
+    elif name.endswith((".opml", ".xml")) or content.lstrip()[:1] == b"<":
+        shows = parse_opml(content)
+    else:
+        try:
+            shows = parse_json_export(json.loads(content))
+        except ValueError as e:
+            raise ImportFormatError(f"Invalid JSON: {e}")
+
+    unique: Dict[str, Dict[str, Any]] = {}
+    for show in shows:
+        key = show["rss_url"] or normalize_title(show["title"])
+        if key and key not in unique:
+            unique[key] = show
+    if not unique:
+        raise ImportFormatError(f"No shows found in {source.value} export")
+    return list(unique.values())
+
+
+def _same_author(a: Optional[str], b: Optional[str]) -> bool:
+    a, b = normalize_title(a), normalize_title(b)
+    return bool(a and b and (a in b or b in a))
+
+
+class SubscriptionImportService:
+    """Matches exported shows to feeds through the podcast directory."""
+
+    def __init__(self):
+        self._semaphore = asyncio.Semaphore(DIRECTORY_CONCURRENCY)
+
+    async def _search(self, session: aiohttp.ClientSession, term: str) -> List[Dict[str, Any]]:
+        async def fetch() -> List[Dict[str, Any]]:
+            params = {"term": term, "media": "podcast", "entity": "podcast", "limit": str(DIRECTORY_RESULTS)}
+            async with self._semaphore:
+                async with session.get(
+                    settings.podcast_directory_url,
+                    params=params,
+                    timeout=aiohttp.ClientTimeout(total=DIRECTORY_TIMEOUT)
+                ) as response:
+                    if response.status != 200:
+                        raise ValueError(f"HTTP {response.status} from podcast directory")
+                    data = await response.json(content_type=None)
+            return [r for r in data.get("results", []) if r.get("feedUrl")]
+
+        # Re-imports and repeated titles hit the directory once a day
+        return await cache.get_or_load(
+            f"directory:{settings.podcast_directory_url}:{term.strip().lower()}",
+            settings.cache_directory_ttl_seconds,
+            fetch
+        )
+
+    async def match_show(self, session: aiohttp.ClientSession, show: Dict[str, Any]) -> Dict[str, Any]:
+        """
+        Find the feed of a show by title (and publisher, when known).
+
+        Args:
+            session: HTTP session for directory requests
+            show: Show without a feed URL
+
+        Returns:
+            The show with rss_url set when exactly one directory result
+            matches, and the directory results as candidates otherwise
+        """
+        title = normalize_title(show["title"])
+        if not title:
+            return {**show, "candidates": [], "error": "Show has no title"}
+        try:
+            results = await self._search(session, show["title"])
+        except Exception as e:
+            logger.warning(f"Directory lookup failed for {show['title']!r}: {e}")
+            return {**show, "candidates": [], "error": f"Directory lookup failed: {e}"}
+
+        candidates = [
+            {
+                "url": normalize_url(r["feedUrl"]),
+                "title": r.get("collectionName"),
+                "author": r.get("artistName"),
+                "episode_count": r.get("trackCount") or 0,
+            }
+            for r in results
+        ]
+        matches = [c for c in candidates if normalize_title(c["title"]) == title]
+        if show.get("author") and len(matches) > 1:
+            matches = [c for c in matches if _same_author(c["author"], show["author"])]
+        if len(matches) == 1:
+            return {**show, "rss_url": matches[0]["url"], "candidates": []}
+        return {**show, "candidates": candidates}
+
+    async def resolve(self, shows: List[Dict[str, Any]]) -> List[Dict[str, Any]]:
+        """
+        Look up feed URLs for shows that don't have one.
+
+        Args:
+            shows: Shows parsed from an export
+
+        Returns:
+            The shows in the same order; those still without rss_url carry
+            directory candidates for manual resolution
+        """
+        async with aiohttp.ClientSession() as session:
+            async def resolve_one(show: Dict[str, Any]) -> Dict[str, Any]:
+                if show["rss_url"]:
+                    return {**show, "candidates": []}
+                return await self.match_show(session, show)
+
+            return list(await asyncio.gather(*(resolve_one(show) for show in shows)))
+
+
+subscription_import_service = SubscriptionImportService()
@@ -0,0 +1,169 @@
+"""Managed temp directory for downloaded audio.
+
+Audio is written under a single directory (TEMP_DIR) so files orphaned by a
+crashed run can be found and removed at startup. The bytes this process keeps
+on disk are capped by TEMP_MAX_BYTES: a download reserves its expected size
+up front and waits until enough of the budget is free.
+"""
+import asyncio
+import logging
+import tempfile
+from contextlib import asynccontextmanager
+from pathlib import Path
+from typing import Any, Dict, Optional, Set
+
+from app.config import settings
+
+logger = logging.getLogger(__name__)
+
+FILE_PREFIX = "podcast-"
+
+
+class TempBudgetExceeded(Exception):
+    """Raised when a temp file can't fit in the disk budget."""
+
+
+class TempFile:
+    """A file in the managed temp directory and its share of the budget."""
+
+    def __init__(self, storage: "TempStorage", path: Path):
+        self.storage = storage
+        self.path = path
+        self.reserved = 0
+
+    async def reserve(self, nbytes: int):
+        """Wait until the file can grow to nbytes within the budget."""
+        await self.storage._reserve(self, nbytes)
+
+    def grow(self, nbytes: int):
+        """Extend the reservation to nbytes without waiting (for files larger than expected)."""
+        self.storage._grow(self, nbytes)
+
+
+class TempStorage:
+    """Creates, tracks and cleans up temp files under a byte budget."""
+
+    def __init__(self, path: Optional[str] = None, max_bytes: Optional[int] = None):
+        self.path = Path(path or settings.temp_dir or Path(tempfile.gettempdir()) / "podcasts")
+        self.max_bytes = settings.temp_max_bytes if max_bytes is None else max_bytes
+        self._files: Set[TempFile] = set()
+        self._reserved = 0
+        self._budget_changed = asyncio.Condition()
+
+        # Metrics
+        self.peak_reserved_bytes = 0
+        self.files_created = 0
+        self.budget_waits = 0
+        self.orphans_removed = 0
+        self.orphan_bytes_removed = 0
+
+    def cleanup_orphans(self) -> int:
+        """
+        Delete temp files left behind by a previous run.
+
+        Only call before any temp files are created (at startup); files
+        tracked by this process are skipped regardless.
+
+        Returns:
+            Number of files removed
+        """
+        self.path.mkdir(parents=True, exist_ok=True)
+        tracked = {f.path for f in self._files}
+        removed = 0
+        for path in self.path.glob(f"{FILE_PREFIX}*"):
+            if path in tracked or not path.is_file():
+                continue
+            try:
+                size = path.stat().st_size
+                path.unlink()
+            except OSError as e:
+                logger.warning(f"Failed to remove orphaned temp file {path}: {e}")
+                continue
+            removed += 1
+            self.orphan_bytes_removed += size
+        self.orphans_removed += removed
+        if removed:
+            logger.info(f"Removed {removed} orphaned temp file(s) from {self.path}")
+        return removed
+
+    @asynccontextmanager
+    async def create(self, suffix: str = ""):
+        """Create an empty temp file, deleting it and releasing its reservation on exit."""
+        self.path.mkdir(parents=True, exist_ok=True)
+        handle = tempfile.NamedTemporaryFile(delete=False, dir=self.path, prefix=FILE_PREFIX, suffix=suffix)
+        handle.close()
+        temp = TempFile(self, Path(handle.name))
+        self._files.add(temp)
+        self.files_created += 1
+        try:
+            yield temp
+        finally:
+            self._files.discard(temp)
+            try:
+                temp.path.unlink(missing_ok=True)
+            except OSError as e:
+                logger.warning(f"Failed to delete temporary file {temp.path}: {e}")
+            await self._release(temp)
+
+    def _fits(self, extra: int) -> bool:
+        return not self.max_bytes or self._reserved + extra <= self.max_bytes
+
+    def _add(self, temp: TempFile, nbytes: int):
+        self._reserved += nbytes - temp.reserved
+        temp.reserved = nbytes
+        self.peak_reserved_bytes = max(self.peak_reserved_bytes, self._reserved)
+
+    async def _reserve(self, temp: TempFile, nbytes: int):
+        extra = nbytes - temp.reserved
+        if extra <= 0:
+            return
+        if self.max_bytes and nbytes > self.max_bytes:
+            raise TempBudgetExceeded(f"{nbytes} bytes exceeds the {self.max_bytes}-byte temp budget")
+
+        async with self._budget_changed:
+            if not self._fits(extra):
+                self.budget_waits += 1
+                logger.info(f"Waiting for temp disk budget ({extra} bytes needed, {self._reserved} in use)")
+                await self._budget_changed.wait_for(lambda: self._fits(nbytes - temp.reserved))
+            self._add(temp, nbytes)
+
+    def _grow(self, temp: TempFile, nbytes: int):
+        extra = nbytes - temp.reserved
+        if extra <= 0:
+            return
+        if not self._fits(extra):
+            raise TempBudgetExceeded(f"Temp disk budget of {self.max_bytes} bytes exhausted")
+        self._add(temp, nbytes)
+
+    async def _release(self, temp: TempFile):
+        if not temp.reserved:
+            return
+        async with self._budget_changed:
+            self._reserved -= temp.reserved
+            temp.reserved = 0
+            self._budget_changed.notify_all()
+
+    def status(self) -> Dict[str, Any]:
+        """Temp usage for health reporting."""
+        on_disk = 0
+        for temp in self._files:
+            try:
+                on_disk += temp.path.stat().st_size
+            except OSError:
+                pass
+        return {
+            "path": str(self.path),
+            "max_bytes": self.max_bytes or None,
+            "reserved_bytes": self._reserved,
+            "bytes_on_disk": on_disk,
+            "peak_reserved_bytes": self.peak_reserved_bytes,
+            "active_files": len(self._files),
+            "files_created": self.files_created,
+            "budget_waits": self.budget_waits,
+            "orphans_removed": self.orphans_removed,
+            "orphan_bytes_removed": self.orphan_bytes_removed,
+        }
+
+
+# Singleton instance
+temp_storage = TempStorage()
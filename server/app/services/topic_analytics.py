@@ -0,0 +1,243 @@
+"""
+Topic trends across a podcast's transcripts.
+
+An analysis job reads each completed transcript once and keeps its most
+frequent keywords (words outside a stopword and filler list) in the
+episode_keywords collection, keyed like the episode and re-read when the
+episode is transcribed again. GET /api/analytics/topics groups those by
+publication week, month, quarter or year, so a podcast's subject matter
+can be followed across seasons without re-reading transcripts per request.
+The job runs every TOPIC_ANALYSIS_INTERVAL_HOURS.
+"""
+import asyncio
+import logging
+import re
+from collections import Counter
+from datetime import datetime, timedelta
+from typing import Any, Dict, List, Optional, Tuple
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.models.schemas import TranscriptStatus
+from app.services.s3_service import s3_service
+from app.services.storage_locations import transcript_location
+
+logger = logging.getLogger(__name__)
+
+# Keywords kept per episode
+KEYWORDS_PER_EPISODE = 100
+
+WINDOWS = ("week", "month", "quarter", "year")
+
+WORD = re.compile(r"[a-z][a-z'-]*[a-z]")
+
+STOPWORDS = frozenset("""
+a about above after again against all also am an and any are aren't as at be because been before being
+below between both but by can can't cannot could couldn't did didn't do does doesn't doing don't down
+during each even ever every few for from further get gets getting got had hadn't has hasn't have haven't
+having he he'd he'll he's her here here's hers herself him himself his how how's however i i'd i'll i'm
+i've if in into is isn't it it's its itself just let's lot lots made make makes making many may me might
+more most much must mustn't my myself never no nor not now of off on once one only or other ought our ours
+ourselves out over own really said same say says see seen shan't she she'd she'll she's should shouldn't
+so some something still such than that that's the their theirs them themselves then there there's these
+they they'd they'll they're they've thing things think this those though through to too two under until
+up upon us very was wasn't way we we'd we'll we're we've well were weren't what what's when when's where
+where's whether which while who who's whom why why's will with without won't would wouldn't yes yet you
+you'd you'll you're you've your yours yourself yourselves
+""".split())
+
+# Conversational filler that dominates spoken transcripts
+FILLER = frozenset("""
+actually ah anyway basically right yeah yep okay ok oh um uh uhm hmm mm like know kind sort gonna wanna
+gotta going go goes went come comes came want wanted mean means guess sure maybe pretty stuff look looking
+talk talking time times today good great little big back first new people
+""".split())
+
+
+def extract_keywords(text: str, limit: int = KEYWORDS_PER_EPISODE) -> Tuple[Dict[str, int], int]:
+    """
+    The most frequent keywords of a transcript.
+
+    Returns:
+        Keyword counts (at most limit) and the transcript's word count
+    """
+    words = WORD.findall(text.lower())
+    counts = Counter(
+        word[:-2] if word.endswith("'s") else word
+        for word in words
+        if len(word) > 2 and word not in STOPWORDS and word not in FILLER
+    )
+    return dict(counts.most_common(limit)), len(words)
+
+
+def period_of(moment: datetime, window: str) -> Tuple[str, datetime]:
+    """The label and start of the window period containing moment."""
+    if window == "week":
+        start = datetime(moment.year, moment.month, moment.day) - timedelta(days=moment.weekday())
+        year, week, _ = start.isocalendar()
+        return f"{year}-W{week:02d}", start
+    if window == "quarter":
+        quarter = (moment.month - 1) // 3
+        return f"{moment.year}-Q{quarter + 1}", datetime(moment.year, quarter * 3 + 1, 1)
+    if window == "year":
+        return str(moment.year), datetime(moment.year, 1, 1)
+    return f"{moment.year}-{moment.month:02d}", datetime(moment.year, moment.month, 1)
+
+
+class TopicAnalyticsService:
+    """Extracts episode keywords and aggregates them into trends."""
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+        self.keywords_collection = db.episode_keywords
+
+    async def analyze_pending(self) -> int:
+        """
+        Extract keywords of completed episodes not analyzed since they were
+        last transcribed, and drop those of episodes deleted or re-queued.
+
+        Returns:
+            Number of episodes analyzed
+        """
+        analyzed_versions = {
+            doc["_id"]: doc.get("episode_updated_at")
+            async for doc in self.keywords_collection.find({}, {"episode_updated_at": 1})
+        }
+        analyzed = 0
+        cursor = self.db.episodes.find(
+            {"transcript_status": TranscriptStatus.COMPLETED.value, "deleted_at": None},
+            {"transcript_text": 0, "show_notes": 0, "description_html": 0}
+        )
+        async for episode in cursor:
+            if episode["_id"] in analyzed_versions:
+                if analyzed_versions.pop(episode["_id"]) == episode.get("updated_at"):
+                    continue
+            try:
+                if await self._analyze(episode):
+                    analyzed += 1
+            except Exception as e:
+                logger.warning(f"Failed to analyze topics of episode {episode.get('episode_id')}: {e}")
+        if analyzed_versions:
+            await self.keywords_collection.delete_many({"_id": {"$in": list(analyzed_versions)}})
+        if analyzed:
+            logger.info(f"Topic analysis: extracted keywords of {analyzed} episode(s)")
+        return analyzed
+
+    async def _analyze(self, episode: Dict[str, Any]) -> bool:
+        text = None
+        if episode.get("transcript_s3_key"):
+            text = await s3_service.get_transcript(
+                episode["transcript_s3_key"], **transcript_location(episode.get("storage"))
+            )
+        if not text:
+            stored = await self.db.episodes.find_one({"_id": episode["_id"]}, {"transcript_text": 1})
+            text = (stored or {}).get("transcript_text")
+        if not text:
+            return False
+
+        keywords, words = extract_keywords(text)
+        await self.keywords_collection.replace_one(
+            {"_id": episode["_id"]},
+            {
+                "episode_id": episode.get("episode_id"),
+                "podcast_id": episode.get("podcast_id"),
+                "published_date": episode.get("published_date") or episode.get("created_at"),
+                "keywords": keywords,
+                "words": words,
+                "episode_updated_at": episode.get("updated_at"),
+                "analyzed_at": datetime.utcnow(),
+            },
+            upsert=True
+        )
+        return True
+
+    async def topic_trends(
+        self,
+        podcast_ids: Optional[List[str]],
+        window: str = "month",
+        top: int = 10,
+    ) -> Dict[str, Any]:
+        """
+        Keyword frequency per period.
+
+        Args:
+            podcast_ids: Podcasts to include (None = all)
+            window: week, month, quarter or year
+            top: Keywords listed per period and followed as trends
+
+        Returns:
+            The periods with their top keywords, and the overall top
+            keywords' counts in every period
+        """
+        query: Dict[str, Any] = {}
+        if podcast_ids is not None:
+            query["podcast_id"] = {"$in": podcast_ids}
+
+        periods: Dict[str, Dict[str, Any]] = {}
+        async for doc in self.keywords_collection.find(query, {"keywords": 1, "words": 1, "published_date": 1}):
+            if not doc.get("published_date"):
+                continue
+            label, start = period_of(doc["published_date"], window)
+            period = periods.setdefault(label, {"start": start, "episodes": 0, "words": 0, "counts": Counter()})
+            period["episodes"] += 1
+            period["words"] += doc.get("words", 0)
+            period["counts"].update(doc.get("keywords") or {})
+
+        ordered = sorted(periods.items(), key=lambda item: item[1]["start"])
+        overall = Counter()
+        for _, period in ordered:
+            overall.update(period["counts"])
+        trending = [term for term, _ in overall.most_common(top)]
+
+        completed = {"transcript_status": TranscriptStatus.COMPLETED.value, "deleted_at": None}
+        if podcast_ids is not None:
+            completed["podcast_id"] = {"$in": podcast_ids}
+        analyzed = sum(period["episodes"] for _, period in ordered)
+        return {
+            "window": window,
+            "periods": [
+                {
+                    "period": label,
+                    "start": period["start"],
+                    "episodes": period["episodes"],
+                    "words": period["words"],
+                    "top_terms": [
+                        {
+                            "term": term,
+                            "count": count,
+                            "per_thousand_words": round(count * 1000 / period["words"], 2) if period["words"] else 0.0,
+                        }
+                        for term, count in period["counts"].most_common(top)
+                    ],
+                }
+                for label, period in ordered
+            ],
+            "trends": [
+                {
+                    "term": term,
+                    "total": overall[term],
+                    "counts": [period["counts"].get(term, 0) for _, period in ordered],
+                }
+                for term in trending
+            ],
+            "episodes_analyzed": analyzed,
+            "episodes_pending": max(0, await self.db.episodes.count_documents(completed) - analyzed),
+        }
+
+
+async def run_topic_analysis_scheduler(get_db, interval_hours: int):
+    """
+    Periodically extract keywords of newly transcribed episodes until cancelled.
+
+    Args:
+        get_db: Callable returning the database instance
+        interval_hours: Hours between analysis runs
+    """
+    logger.info(f"Starting topic analysis scheduler (every {interval_hours} hours)")
+    while True:
+        try:
+            await TopicAnalyticsService(get_db()).analyze_pending()
+        except Exception as e:
+            logger.error(f"Topic analysis run failed: {e}")
+        # Runs on startup too, so a new deployment doesn't wait a full interval for trends
+        await asyncio.sleep(interval_hours * 3600)
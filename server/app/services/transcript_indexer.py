@@ -0,0 +1,228 @@
+"""
+Keep the transcript search index current as transcripts complete.
+
+The transcript_index collection holds one document per completed episode
+(same _id as the episode) with its title and transcript text under a
+MongoDB text index. With TRANSCRIPT_INDEX_ENABLED, the indexer watches the
+episodes collection through a change stream and indexes an episode when
+its transcript completes, re-indexes it when it is transcribed again and
+drops it when it is deleted, archived or queued for transcription again;
+nothing needs a batch reindex. Transcripts written by the Lambdas show up
+too, since the stream sees every write to the collection.
+
+The stream's resume token is stored in indexer_state after each change, so
+a restart continues where the last instance stopped. Every start (and a
+resume token MongoDB no longer has) first runs a catch-up pass indexing
+completed episodes changed since they were last indexed. Change streams
+need a replica set; on a standalone server the indexer falls back to the
+catch-up pass every TRANSCRIPT_INDEX_POLL_SECONDS.
+"""
+import asyncio
+import logging
+from datetime import datetime
+from typing import Any, Dict
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+from pymongo.errors import OperationFailure, PyMongoError
+
+from app.config import settings
+from app.models.schemas import TranscriptStatus
+from app.services.s3_service import s3_service
+from app.services.storage_locations import transcript_location
+
+logger = logging.getLogger(__name__)
+
+STATE_ID = "transcript_indexer"
+
+# Server error codes: change streams unsupported, resume point gone
+CHANGE_STREAMS_UNSUPPORTED = (40573, 40324)
+CHANGE_STREAM_HISTORY_LOST = (280, 286)
+
+# Updates touching any of these can change whether or what an episode indexes
+INDEXED_FIELDS = ("transcript_status", "transcript_s3_key", "transcript_text", "title", "deleted_at")
+
+# Wait after an unexpected stream error before reopening it
+RETRY_SECONDS = 30
+
+
+async def create_search_indexes(db: AsyncIOMotorDatabase):
+    """Create transcript_index's indexes (a no-op when they exist unchanged)."""
+    await db.transcript_index.create_index(
+        [("title", "text"), ("text", "text")], weights={"title": 5}, name="transcript_text"
+    )
+    await db.transcript_index.create_index("podcast_id")
+
+
+def _indexable(episode: Dict[str, Any]) -> bool:
+    return episode.get("transcript_status") == TranscriptStatus.COMPLETED.value and not episode.get("deleted_at")
+
+
+class TranscriptIndexer:
+    """Maintains transcript_index from changes to episodes."""
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+        self.index_collection = db.transcript_index
+        self.state_collection = db.indexer_state
+
+    async def index_episode(self, episode: Dict[str, Any]) -> bool:
+        """
+        Index an episode's transcript, or drop it if it has none to index.
+
+        Returns:
+            Whether the episode is in the index afterwards
+        """
+        if not _indexable(episode):
+            await self.index_collection.delete_one({"_id": episode["_id"]})
+            return False
+
+        text = None
+        if episode.get("transcript_s3_key"):
+            text = await s3_service.get_transcript(
+                episode["transcript_s3_key"], **transcript_location(episode.get("storage"))
+            )
+        text = text or episode.get("transcript_text")
+        if not text:
+            logger.warning(f"No transcript text to index for episode {episode.get('episode_id')}")
+            return False
+
+        await self.index_collection.replace_one(
+            {"_id": episode["_id"]},
+            {
+                "episode_id": episode.get("episode_id"),
+                "podcast_id": episode.get("podcast_id"),
+                "workspace_id": episode.get("workspace_id"),
+                "title": episode.get("title") or "",
+                "text": text,
+                "episode_updated_at": episode.get("updated_at"),
+                "indexed_at": datetime.utcnow(),
+            },
+            upsert=True
+        )
+        return True
+
+    async def catch_up(self) -> int:
+        """
+        Index completed episodes changed since they were last indexed, and
+        drop index entries of episodes that are gone or no longer completed.
+
+        Returns:
+            Number of episodes indexed
+        """
+        indexed_at = {
+            doc["_id"]: doc.get("episode_updated_at")
+            async for doc in self.index_collection.find({}, {"episode_updated_at": 1})
+        }
+        indexed = 0
+        async for episode in self.db.episodes.find(
+            {"transcript_status": TranscriptStatus.COMPLETED.value, "deleted_at": None}
+        ):
+            previous = indexed_at.pop(episode["_id"], None)
+            if previous is not None and previous == episode.get("updated_at"):
+                continue
+            try:
+                if await self.index_episode(episode):
+                    indexed += 1
+            except Exception as e:
+                logger.warning(f"Failed to index episode {episode.get('episode_id')}: {e}")
+        if indexed_at:
+            await self.index_collection.delete_many({"_id": {"$in": list(indexed_at)}})
+        if indexed or indexed_at:
+            logger.info(f"Transcript index catch-up: indexed {indexed}, dropped {len(indexed_at)}")
+        return indexed
+
+    async def apply_change(self, change: Dict[str, Any]):
+        """Bring the index in line with one change event on episodes."""
+        operation = change["operationType"]
+        episode_key = change["documentKey"]["_id"]
+        if operation == "delete":
+            await self.index_collection.delete_one({"_id": episode_key})
+            return
+        if operation == "update":
+            updated = change.get("updateDescription") or {}
+            touched = list((updated.get("updatedFields") or {}).keys()) + list(updated.get("removedFields") or [])
+            if not any(field.split(".")[0] in INDEXED_FIELDS for field in touched):
+                return
+        episode = change.get("fullDocument")
+        if episode is None:
+            # Deleted again before the lookup
+            await self.index_collection.delete_one({"_id": episode_key})
+            return
+        await self.index_episode(episode)
+
+    async def watch(self):
+        """Apply changes to episodes as they happen; returns only on error."""
+        state = await self.state_collection.find_one({"_id": STATE_ID})
+        resume_token = state.get("resume_token") if state else None
+        pipeline = [{"$match": {"operationType": {"$in": ["insert", "update", "replace", "delete"]}}}]
+        async with self.db.episodes.watch(
+            pipeline, full_document="updateLookup", resume_after=resume_token
+        ) as stream:
+            logger.info("Watching episodes for transcript index updates")
+            # Saved before any change arrives, so a restart misses nothing
+            await self._save_resume_token(stream.resume_token)
+            async for change in stream:
+                try:
+                    await self.apply_change(change)
+                except Exception as e:
+                    logger.warning(f"Failed to index change to episode {change['documentKey']['_id']}: {e}")
+                await self._save_resume_token(stream.resume_token)
+
+    async def _save_resume_token(self, resume_token: Any):
+        if resume_token is None:
+            return
+        await self.state_collection.update_one(
+            {"_id": STATE_ID},
+            {"$set": {"resume_token": resume_token, "updated_at": datetime.utcnow()}},
+            upsert=True
+        )
+
+    async def forget_resume_token(self):
+        await self.state_collection.update_one({"_id": STATE_ID}, {"$unset": {"resume_token": ""}})
+
+
+async def run_transcript_indexer(get_db):
+    """
+    Keep the transcript index current until cancelled.
+
+    Args:
+        get_db: Callable returning the database instance
+    """
+    logger.info("Starting transcript indexer")
+    catch_up_due = True
+    while True:
+        indexer = TranscriptIndexer(get_db())
+        try:
+            if catch_up_due:
+                await indexer.catch_up()
+                catch_up_due = False
+            await indexer.watch()
+        except OperationFailure as e:
+            if e.code in CHANGE_STREAMS_UNSUPPORTED:
+                logger.warning(
+                    f"Change streams unavailable ({e}); re-indexing every "
+                    f"{settings.transcript_index_poll_seconds}s instead"
+                )
+                await _poll(get_db)
+                return
+            if e.code in CHANGE_STREAM_HISTORY_LOST:
+                logger.warning(f"Transcript indexer resume point lost, catching up: {e}")
+                await indexer.forget_resume_token()
+                catch_up_due = True
+                continue
+            logger.error(f"Transcript indexer stream failed: {e}")
+        except PyMongoError as e:
+            logger.error(f"Transcript indexer stream failed: {e}")
+        except Exception as e:
+            logger.error(f"Transcript index catch-up failed: {e}")
+            catch_up_due = True
+        await asyncio.sleep(RETRY_SECONDS)
+
+
+async def _poll(get_db):
+    while True:
+        await asyncio.sleep(settings.transcript_index_poll_seconds)
+        try:
+            await TranscriptIndexer(get_db()).catch_up()
+        except Exception as e:
+            logger.error(f"Transcript index catch-up failed: {e}")
@@ -0,0 +1,100 @@
+"""
+Within-episode transcription progress.
+
+A multi-hour episode would otherwise sit in "processing" with nothing to
+show. While it is transcribed, its document (and its bulk job entry) carries
+transcript_progress: percent complete and transcribed_seconds, how far into
+the audio the transcript reaches. The chunked workflow knows this from the
+chunks completed so far. A local Whisper request doesn't report progress, so
+bulk jobs estimate it from elapsed time and the transcription speed measured
+on earlier episodes (estimated: true, capped below 100%).
+"""
+from datetime import datetime
+from typing import Any, Dict, List, Optional, Set
+
+from app.config import settings
+
+# Estimates stop short of done until the transcript actually arrives
+MAX_ESTIMATED_PERCENT = 99.0
+
+# Weight of the latest measurement in the running speed average
+SPEED_SMOOTHING = 0.3
+
+
+def chunk_progress(chunks: List[Dict[str, Any]], completed: Set[int]) -> Dict[str, Any]:
+    """
+    Progress of a chunked transcription.
+
+    Args:
+        chunks: Chunk metadata from the chunking Lambda (chunk_index,
+            start_time_seconds, end_time_seconds)
+        completed: Indexes of the chunks transcribed so far
+
+    Returns:
+        transcript_progress document
+    """
+    def seconds(chunk: Dict[str, Any]) -> float:
+        end = chunk.get("end_time_seconds")
+        return max(end - chunk.get("start_time_seconds", 0), 0) if end is not None else 0
+
+    total = sum(seconds(c) for c in chunks)
+    if total:
+        done = sum(seconds(c) for c in chunks if c.get("chunk_index") in completed)
+        percent = 100 * done / total
+    else:
+        # Older chunking output has no end times
+        percent = 100 * len(completed) / len(chunks) if chunks else 0
+
+    # Chunks finish out of order; the transcript reaches the end of the
+    # leading run of completed ones
+    transcribed_seconds = None
+    if total:
+        transcribed_seconds = 0.0
+        for chunk in sorted(chunks, key=lambda c: c.get("start_time_seconds", 0)):
+            if chunk.get("chunk_index") not in completed:
+                break
+            transcribed_seconds = chunk["end_time_seconds"]
+
+    return {
+        "percent": round(percent, 1),
+        "transcribed_seconds": transcribed_seconds,
+        "estimated": False,
+        "updated_at": datetime.utcnow(),
+    }
+
+
+class TranscriptionSpeeds:
+    """Audio seconds transcribed per second of processing, per Whisper model."""
+
+    def __init__(self):
+        self._speeds: Dict[str, float] = {}
+
+    def record(self, model: Optional[str], audio_seconds: float, elapsed_seconds: float):
+        """Fold a finished transcription into the model's running average."""
+        if audio_seconds <= 0 or elapsed_seconds <= 0:
+            return
+        key = model or settings.whisper_model
+        speed = audio_seconds / elapsed_seconds
+        previous = self._speeds.get(key)
+        self._speeds[key] = speed if previous is None else (
+            SPEED_SMOOTHING * speed + (1 - SPEED_SMOOTHING) * previous
+        )
+
+    def estimate(self, model: Optional[str], audio_seconds: float, elapsed_seconds: float) -> Dict[str, Any]:
+        """
+        Estimated progress of a transcription that has run for elapsed_seconds.
+
+        Before the model's first measurement, WHISPER_EXPECTED_SPEED is assumed.
+        """
+        speed = self._speeds.get(model or settings.whisper_model, settings.whisper_expected_speed)
+        transcribed = min(elapsed_seconds * speed, audio_seconds * MAX_ESTIMATED_PERCENT / 100)
+        return {
+            "percent": round(100 * transcribed / audio_seconds, 1),
+            "transcribed_seconds": round(transcribed, 1),
+            "estimated": True,
+            "updated_at": datetime.utcnow(),
+        }
+
+
+# Shared by every bulk job in the process
+transcription_speeds = TranscriptionSpeeds()
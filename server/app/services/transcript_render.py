@@ -0,0 +1,115 @@
+"""Render merged transcripts as HTML.
+
+Merged transcripts are plain text: paragraphs separated by blank lines,
+with "[HH:MM:SS]" marker lines every few minutes (see the merge Lambda).
+Markers become anchor links (#t-<seconds>) so pages can deep-link into a
+transcript. Paragraphs starting with a short "Name:" label get the label
+set apart as the speaker. Chapters come from timestamped lines in the
+episode's show notes ("12:30 Interview") and are inserted as headings at
+the first marker at or after their start.
+"""
+import re
+from html import escape
+from typing import Any, Dict, List, Optional
+
+# A merge Lambda timestamp marker line
+TIMESTAMP_LINE = re.compile(r"^\[(\d{2}):(\d{2}):(\d{2})\]$")
+
+# "Host:", "Jane Doe:", "SPEAKER_01:" at the start of a paragraph
+SPEAKER_LABEL = re.compile(r"^((?:[A-Z][\w.'-]*)(?: [A-Z][\w.'-]*){0,2}|SPEAKER_\d+):\s+")
+
+# "12:30 Title", "(1:02:03) - Title", "00:00 – Intro" in show notes
+CHAPTER_LINE = re.compile(r"^\s*\(?((?:\d{1,2}:)?\d{1,2}:\d{2})\)?\s*[-–—:|]?\s*(.+?)\s*$")
+
+PAGE_STYLE = (
+    "body{font-family:Georgia,serif;max-width:42em;margin:2em auto;padding:0 1em;line-height:1.6}"
+    ".timestamp{font-family:monospace;color:#666;text-decoration:none}"
+    ".speaker{font-weight:bold}"
+)
+
+
+def format_seconds(seconds: int) -> str:
+    """HH:MM:SS for a position in seconds."""
+    return f"{seconds // 3600:02d}:{seconds % 3600 // 60:02d}:{seconds % 60:02d}"
+
+
+def parse_chapters(description: Optional[str]) -> List[Dict[str, Any]]:
+    """
+    Chapters listed in show notes, one "<timestamp> <title>" per line.
+
+    Returns:
+        Chapters ({"start_seconds", "title"}) in order, or [] when the notes
+        list fewer than two
+    """
+    chapters = []
+    for line in re.split(r"<br\s*/?>|\n", description or ""):
+        match = CHAPTER_LINE.match(re.sub(r"<[^>]+>", "", line))
+        if not match:
+            continue
+        seconds = 0
+        for part in match.group(1).split(":"):
+            seconds = seconds * 60 + int(part)
+        chapters.append({"start_seconds": seconds, "title": match.group(2)})
+    chapters.sort(key=lambda chapter: chapter["start_seconds"])
+    return chapters if len(chapters) >= 2 else []
+
+
+def _render_paragraph(text: str) -> str:
+    lines = "<br>\n".join(escape(line) for line in text.splitlines())
+    match = SPEAKER_LABEL.match(text)
+    if match:
+        label = escape(match.group(1))
+        lines = f'<span class="speaker">{label}:</span> ' + lines[len(escape(match.group(0))):]
+    return f"<p>{lines}</p>"
+
+
+def render_transcript_html(transcript: str, chapters: Optional[List[Dict[str, Any]]] = None) -> str:
+    """
+    Render a transcript as an HTML fragment (an <article> element).
+
+    Args:
+        transcript: Merged transcript text
+        chapters: Chapter headings ({"start_seconds", "title"}), in order
+
+    Returns:
+        HTML fragment
+    """
+    pending = list(chapters or [])
+    parts = ['<article class="transcript">']
+
+    def add_chapters(until_seconds: Optional[int]):
+        while pending and (until_seconds is None or pending[0]["start_seconds"] <= until_seconds):
+            chapter = pending.pop(0)
+            anchor = f"chapter-{chapter['start_seconds']}"
+            parts.append(
+                f'<h2 id="{anchor}"><a class="timestamp" href="#{anchor}">'
+                f'{format_seconds(chapter["start_seconds"])}</a> {escape(chapter["title"])}</h2>'
+            )
+
+    for block in re.split(r"\n\s*\n", transcript.strip()):
+        lines = block.strip().splitlines()
+        marker = TIMESTAMP_LINE.match(lines[0].strip()) if lines else None
+        if marker:
+            hours, minutes, secs = (int(group) for group in marker.groups())
+            seconds = hours * 3600 + minutes * 60 + secs
+            add_chapters(seconds)
+            parts.append(
+                f'<p><a class="timestamp" id="t-{seconds}" href="#t-{seconds}">{format_seconds(seconds)}</a></p>'
+            )
+            lines = lines[1:]
+        if lines:
+            parts.append(_render_paragraph("\n".join(lines)))
+
+    add_chapters(None)
+    parts.append("</article>")
+    return "\n".join(parts)
+
+
+def render_transcript_page(title: str, subtitle: str, fragment: str) -> str:
+    """Wrap a rendered transcript in a standalone HTML document."""
+    return (
+        "<!DOCTYPE html>\n"
+        f'<html><head><meta charset="utf-8"><title>{escape(title)}</title>'
+        f"<style>{PAGE_STYLE}</style></head>\n"
+        f"<body><h1>{escape(title)}</h1><p>{escape(subtitle)}</p>\n{fragment}\n</body></html>\n"
+    )
@@ -0,0 +1,80 @@
+"""
+Content-addressed transcript storage.
+
+Episode transcripts are stored under transcripts/sha256/<hex digest>.txt and
+the episode records the digest as transcript_sha256, so identical
+transcripts (a replay, or a show published on two feeds) share one object.
+S3 checks each upload against the digest and keeps it as the object's
+SHA-256 checksum, which a reconciliation run with verify compares with the
+episode's transcript_sha256. Mirrors the merge Lambda's contentstore.go.
+
+Transcripts stored before this keep their transcripts/<episode_id>/ keys
+until the episode is transcribed again. Keys are only unique within a
+bucket: episodes pinned to another storage location (see
+storage_locations.py) share transcripts with episodes in the same bucket.
+Private podcasts' transcripts are stored under
+transcripts/private/<workspace_id>/sha256/ encrypted with the workspace's
+KMS key (see private_transcripts.py).
+"""
+import hashlib
+import logging
+from typing import Any, Dict, Optional, Tuple
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.services.s3_service import s3_service
+from app.services.storage_locations import same_bucket, transcript_location
+
+logger = logging.getLogger(__name__)
+
+CONTENT_PREFIX = "transcripts/sha256/"
+PRIVATE_PREFIX = "transcripts/private/"
+
+
+def transcript_digest(text: str) -> str:
+    """Hex SHA-256 of a transcript's UTF-8 text."""
+    return hashlib.sha256(text.encode("utf-8")).hexdigest()
+
+
+def content_key(digest: str) -> str:
+    """S3 key of the transcript with a digest."""
+    return f"{CONTENT_PREFIX}{digest}.txt"
+
+
+def private_content_key(workspace_id: str, digest: str) -> str:
+    """S3 key of a workspace's private transcript with a digest."""
+    return f"{PRIVATE_PREFIX}{workspace_id}/sha256/{digest}.txt"
+
+
+async def store_transcript(
+    text: str, storage: Optional[Dict[str, Any]] = None, encryption: Optional[Dict[str, str]] = None
+) -> Optional[Tuple[str, str]]:
+    """
+    Upload a transcript under its content key, unless an identical one is
+    already stored there, in storage's transcript bucket (the default
+    bucket if None). With encryption (a private podcast's workspace_id and
+    kms_key_id) it goes to the workspace's private prefix, encrypted with
+    the key.
+
+    Returns:
+        The S3 key and digest, or None if the upload failed
+    """
+    digest = transcript_digest(text)
+    location = transcript_location(storage)
+    key = private_content_key(encryption["workspace_id"], digest) if encryption else content_key(digest)
+    if await s3_service.object_exists(key, **location):
+        logger.info(f"Transcript {key} already stored, sharing it")
+        return key, digest
+    kms_key_id = encryption["kms_key_id"] if encryption else None
+    if not await s3_service.upload_transcript(key, text, sha256=digest, kms_key_id=kms_key_id, **location):
+        return None
+    return key, digest
+
+
+async def shared_outside(
+    db: AsyncIOMotorDatabase, s3_key: str, podcast_id: str, storage: Optional[Dict[str, Any]] = None
+) -> bool:
+    """Whether an episode of another podcast uses the transcript (in the same bucket) too."""
+    return bool(await db.episodes.find_one(
+        {"transcript_s3_key": s3_key, "podcast_id": {"$ne": podcast_id}, **same_bucket(storage)}, {"_id": 1}
+    ))
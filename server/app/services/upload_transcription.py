@@ -0,0 +1,187 @@
+"""One-off transcription of audio that isn't part of any feed.
+
+Audio arrives as a multipart upload, an object in the audio bucket or a
+(presigned) URL. Each request becomes a task in the transcription_tasks
+collection; the audio is transcribed in the background on the Whisper pool,
+sharing transcription slots with bulk jobs and episodes, and the transcript
+is stored under transcripts/uploads/<task_id>/final.txt.
+"""
+import asyncio
+import logging
+import time
+import uuid
+from contextlib import AsyncExitStack
+from datetime import datetime
+from pathlib import Path
+from typing import Any, Dict, Optional, Set
+
+from fastapi import UploadFile
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.config import settings
+from app.models.schemas import JobPriority, TranscriptStatus
+from app.services.s3_service import s3_service
+from app.services.temp_storage import TempFile
+from app.services.whisper_service import whisper_service
+from app.services.work_queue import transcription_slots
+from app.workspaces import stamp
+
+logger = logging.getLogger(__name__)
+
+UPLOAD_CHUNK_SIZE = 1024 * 1024
+
+# Presigned URLs for audio-bucket objects only need to outlive the download
+AUDIO_URL_EXPIRES_SECONDS = 3600
+
+
+class UploadTooLarge(Exception):
+    """Raised when an uploaded file exceeds TRANSCRIBE_UPLOAD_MAX_BYTES."""
+
+
+class UploadTranscriptionService:
+    """Creates and runs one-off transcription tasks."""
+
+    # Keeps background tasks referenced until they finish
+    _running: Set[asyncio.Task] = set()
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+        self.tasks_collection = db.transcription_tasks
+
+    async def create_task(
+        self,
+        source: str,
+        description: str,
+        priority: JobPriority,
+        workspace_id: Optional[str] = None
+    ) -> Dict[str, Any]:
+        """
+        Record a new pending task.
+
+        Args:
+            source: upload, s3 or url
+            description: File name, S3 key or URL (query string removed) shown to clients
+            priority: Queue priority for the transcription
+            workspace_id: Workspace the task belongs to
+
+        Returns:
+            The task document
+        """
+        now = datetime.utcnow()
+        task = {
+            "task_id": f"tr_{uuid.uuid4().hex[:12]}",
+            "source": source,
+            "description": description,
+            "priority": priority.value,
+            "status": TranscriptStatus.PENDING.value,
+            "queue_position": None,
+            "transcript_s3_key": None,
+            "total_words": None,
+            "error_message": None,
+            "created_at": now,
+            "updated_at": now,
+        }
+        stamp(task, workspace_id)
+        await self.tasks_collection.insert_one(task)
+        return task
+
+    async def get_task(self, task_id: str) -> Optional[Dict[str, Any]]:
+        """Get a task by ID."""
+        return await self.tasks_collection.find_one({"task_id": task_id}, {"_id": 0})
+
+    async def _update(self, task_id: str, fields: Dict[str, Any]):
+        fields["updated_at"] = datetime.utcnow()
+        await self.tasks_collection.update_one({"task_id": task_id}, {"$set": fields})
+
+    @staticmethod
+    async def save_upload(file: UploadFile, temp: TempFile):
+        """
+        Copy an uploaded file into a managed temp file, reserving its size
+        from the temp disk budget.
+
+        Raises:
+            UploadTooLarge: If the file exceeds TRANSCRIBE_UPLOAD_MAX_BYTES
+        """
+        max_bytes = settings.transcribe_upload_max_bytes
+        await temp.reserve(file.size or 0)
+        written = 0
+        with temp.path.open("wb") as out:
+            while chunk := await file.read(UPLOAD_CHUNK_SIZE):
+                written += len(chunk)
+                if max_bytes and written > max_bytes:
+                    raise UploadTooLarge(f"Audio file exceeds {max_bytes} bytes")
+                if written > temp.reserved:
+                    temp.grow(written)
+                out.write(chunk)
+
+    def start(self, task_id: str, audio_path: Optional[Path] = None, audio_url: Optional[str] = None,
+              cleanup: Optional[AsyncExitStack] = None):
+        """
+        Transcribe a task's audio in the background.
+
+        Args:
+            task_id: Task to run
+            audio_path: Uploaded audio on disk
+            audio_url: URL to download the audio from instead
+            cleanup: Releases the uploaded temp file when the task finishes
+        """
+        task = asyncio.create_task(self._run(task_id, audio_path, audio_url, cleanup))
+        self._running.add(task)
+        task.add_done_callback(self._running.discard)
+
+    async def _run(self, task_id: str, audio_path: Optional[Path], audio_url: Optional[str],
+                   cleanup: Optional[AsyncExitStack]):
+        async with cleanup or AsyncExitStack():
+            try:
+                task = await self.get_task(task_id)
+                priority = JobPriority(task["priority"]) if task else JobPriority.HIGH
+
+                async def report_queue_position(position: Optional[int]):
+                    await self._update(task_id, {"queue_position": position})
+
+                await whisper_service.wait_until_ready()
+                async with transcription_slots.slot(priority):
+                    await self._update(task_id, {"status": TranscriptStatus.PROCESSING.value})
+                    started = time.monotonic()
+                    if audio_path:
+                        transcript = await whisper_service.transcribe_local_audio(audio_path, report_queue_position)
+                    else:
+                        transcript = await whisper_service.transcribe_audio_url(audio_url, report_queue_position)
+                    elapsed = time.monotonic() - started
+                if not transcript:
+                    raise Exception("Whisper returned no transcript")
+
+                transcript_s3_key = f"transcripts/uploads/{task_id}/final.txt"
+                if not await s3_service.upload_transcript(transcript_s3_key, transcript):
+                    raise Exception("Failed to store transcript in S3")
+
+                await self._update(task_id, {
+                    "status": TranscriptStatus.COMPLETED.value,
+                    "queue_position": None,
+                    "transcript_s3_key": transcript_s3_key,
+                    "total_words": len(transcript.split()),
+                    "processing_seconds": round(elapsed, 1),
+                })
+                logger.info(f"Transcription task {task_id} completed in {elapsed:.0f}s")
+
+            except Exception as e:
+                logger.error(f"Transcription task {task_id} failed: {e}")
+                await self._update(task_id, {
+                    "status": TranscriptStatus.FAILED.value,
+                    "queue_position": None,
+                    "error_message": str(e),
+                })
+
+    @classmethod
+    def cancel_all(cls) -> int:
+        """Cancel every running task in this process (used on shutdown)."""
+        for task in cls._running:
+            task.cancel()
+        return len(cls._running)
+
+    @staticmethod
+    def audio_bucket_url(s3_key: str) -> str:
+        """Presigned URL for an object in the audio bucket."""
+        return s3_service.generate_presigned_url(
+            s3_key, expires_in=AUDIO_URL_EXPIRES_SECONDS, bucket=settings.s3_audio_bucket
+        )
@@ -1,136 +1,634 @@
 """Service for local Whisper transcription."""
+import asyncio
+import io
+import itertools
 import logging
+import shutil
+import time
+import wave
 import aiohttp
-import tempfile
+import mimetypes
+from contextlib import asynccontextmanager, nullcontext
+from datetime import datetime, timezone
+from email.utils import parsedate_to_datetime
 from pathlib import Path
-from typing import Optional
+from typing import Any, Awaitable, Callable, Dict, List, Optional, Set, Tuple
 from app.config import settings
+from app.database.mongodb import MongoDB
+from app.services.audio_preprocess import OUTPUT_SUFFIX, preprocess_audio
+from app.services.download_usage import DownloadUsageService, download_host
+from app.services.temp_storage import TempFile, temp_storage
 
 logger = logging.getLogger(__name__)
 
+# Called with a request's position in the admission queue (None once admitted)
+QueuePositionCallback = Callable[[Optional[int]], Awaitable[None]]
+
+ROUND_ROBIN = "round_robin"
+LEAST_BUSY = "least_busy"
+
+DOWNLOAD_CHUNK_SIZE = 64 * 1024
+
+# Pause between warm-up attempts while backends load their model
+WARMUP_RETRY_SECONDS = 10
+
+
+def _silent_wav(seconds: float = 1.0, rate: int = 16000) -> bytes:
+    """A clip of 16-bit mono silence for warm-up requests."""
+    buffer = io.BytesIO()
+    with wave.open(buffer, "wb") as clip:
+        clip.setnchannels(1)
+        clip.setsampwidth(2)
+        clip.setframerate(rate)
+        clip.writeframes(b"\x00\x00" * int(seconds * rate))
+    return buffer.getvalue()
+
+
+WARMUP_AUDIO = _silent_wav()
+
+
+class AudioDownloadError(Exception):
+    """Raised when episode audio can't be downloaded."""
+
+
+class AudioHostBusy(Exception):
+    """Raised when an audio host answers 429 (or 503 with Retry-After)."""
+
+    def __init__(self, status: int, retry_after: Optional[float]):
+        super().__init__(f"HTTP {status}")
+        self.status = status
+        self.retry_after = retry_after
+
+
+def retry_after_seconds(value: Optional[str]) -> Optional[float]:
+    """Seconds to wait from a Retry-After header (delay or HTTP date), None if absent or malformed."""
+    if not value:
+        return None
+    value = value.strip()
+    if value.isdigit():
+        return float(value)
+    try:
+        when = parsedate_to_datetime(value)
+    except (TypeError, ValueError):
+        return None
+    if when.tzinfo is None:
+        when = when.replace(tzinfo=timezone.utc)
+    return max((when - datetime.now(timezone.utc)).total_seconds(), 0.0)
+
+
+class WhisperNotReadyError(Exception):
+    """Raised when no backend loads its model within WHISPER_READY_TIMEOUT_SECONDS."""
+
+
+class WhisperBackend:
+    """One Whisper container and its load/health state."""
+
+    def __init__(self, url: str, max_concurrent: int = 1):
+        self.url = url.rstrip('/')
+        self.transcribe_endpoint = f"{self.url}/asr"
+        self.max_concurrent = max(max_concurrent, 1)
+        self.in_flight = 0
+        self.healthy = True
+        self.last_checked_at: Optional[datetime] = None
+        self.last_error: Optional[str] = None
+        # Models that answered a request ("" for the backend's default)
+        self.warm_models: Set[str] = set()
+        self.warm_lock = asyncio.Lock()
+
+    def mark_unhealthy(self, error: str):
+        """Take the backend out of rotation until a health check passes."""
+        if self.healthy:
+            logger.warning(f"Whisper backend {self.url} marked unhealthy: {error}")
+        self.healthy = False
+        self.last_error = error
+        # A restarted container loads its model again
+        self.warm_models.clear()
+
+    def status(self) -> Dict[str, Any]:
+        return {
+            "url": self.url,
+            "healthy": self.healthy,
+            "in_flight": self.in_flight,
+            "max_concurrent": self.max_concurrent,
+            "last_checked_at": self.last_checked_at,
+            "last_error": self.last_error,
+            "models_loaded": sorted(model or "default" for model in self.warm_models),
+        }
+
 
 class WhisperService:
-    """Service for transcribing audio using local Whisper container."""
+    """
+    Service for transcribing audio using local Whisper containers.
+
+    Requests are spread across WHISPER_SERVICE_URLS (round-robin or
+    least-busy). A backend that fails at the network level is taken out of
+    rotation until the health monitor sees it answer again; when every
+    backend is down, all are tried anyway.
 
-    def __init__(self):
-        self.whisper_url = settings.whisper_service_url.rstrip('/')
-        self.transcribe_endpoint = f"{self.whisper_url}/asr"
+    Each backend accepts at most WHISPER_MAX_CONCURRENT_PER_BACKEND requests.
+    Further requests wait in a FIFO admission queue instead of piling onto a
+    saturated GPU; callers can be told their queue position.
+    """
 
-    async def transcribe_audio_file(self, audio_path: Path) -> Optional[str]:
+    def __init__(
+        self,
+        urls: Optional[List[str]] = None,
+        strategy: Optional[str] = None,
+        max_concurrent_per_backend: Optional[int] = None
+    ):
+        max_concurrent = max_concurrent_per_backend or settings.whisper_max_concurrent_per_backend
+        self.backends = [
+            WhisperBackend(url, max_concurrent)
+            for url in (urls or settings.whisper_service_urls_list)
+        ]
+        self.strategy = strategy or settings.whisper_balance_strategy
+        self._rotation = itertools.count()
+        self._queue: List[object] = []
+        self._capacity_changed = asyncio.Condition()
+        # host -> its download slots (WHISPER_DOWNLOADS_PER_HOST)
+        self._host_slots: Dict[str, asyncio.Semaphore] = {}
+
+    async def reconfigure(self, urls: List[str], max_concurrent_per_backend: int):
+        """
+        Replace the backend pool at runtime. Backends that stay keep their
+        load and health; requests running on removed backends finish there.
         """
-        Transcribe an audio file using the local Whisper service.
+        current = {backend.url: backend for backend in self.backends}
+        backends = []
+        for url in urls:
+            backend = current.get(url.rstrip('/')) or WhisperBackend(url)
+            backend.max_concurrent = max(max_concurrent_per_backend, 1)
+            backends.append(backend)
+        async with self._capacity_changed:
+            self.backends = backends
+            self._capacity_changed.notify_all()
+        logger.info(f"Whisper pool reconfigured: {', '.join(b.url for b in backends)}")
+
+    def _candidates(self, exclude: Set[str]) -> List[WhisperBackend]:
+        """Backends in the order they should be tried for the next request."""
+        pool = [b for b in self.backends if b.healthy and b.url not in exclude]
+        if not pool:
+            pool = [b for b in self.backends if b.url not in exclude]
+        if not pool:
+            return []
+        start = next(self._rotation) % len(pool)
+        rotated = pool[start:] + pool[:start]
+        if self.strategy == LEAST_BUSY:
+            # Stable sort keeps the rotation among equally loaded backends
+            rotated.sort(key=lambda b: b.in_flight)
+        return rotated
+
+    async def _acquire_backend(
+        self,
+        exclude: Set[str],
+        on_queue_position: Optional[QueuePositionCallback] = None
+    ) -> Tuple[Optional[WhisperBackend], bool]:
+        """
+        Wait for a backend with spare capacity, first come first served.
+
+        Returns:
+            The claimed backend (None if every backend has been excluded) and
+            whether the request had to queue
+        """
+        ticket = object()
+        async with self._capacity_changed:
+            self._queue.append(ticket)
+        reported = None
+        try:
+            while True:
+                async with self._capacity_changed:
+                    while True:
+                        candidates = self._candidates(exclude)
+                        if not candidates:
+                            return None, reported is not None
+                        position = self._queue.index(ticket) + 1
+                        free = [b for b in candidates if b.in_flight < b.max_concurrent]
+                        if position == 1 and free:
+                            free[0].in_flight += 1
+                            break
+                        if position != reported:
+                            break
+                        await self._capacity_changed.wait()
+
+                if position == 1 and free:
+                    return free[0], reported is not None
+
+                # Report outside the lock so a slow callback doesn't stall the queue
+                reported = position
+                if on_queue_position:
+                    await on_queue_position(position)
+        finally:
+            async with self._capacity_changed:
+                self._queue.remove(ticket)
+                self._capacity_changed.notify_all()
+
+    async def _release_backend(self, backend: WhisperBackend):
+        async with self._capacity_changed:
+            backend.in_flight -= 1
+            self._capacity_changed.notify_all()
+
+    async def transcribe_audio_file(
+        self,
+        audio_path: Path,
+        on_queue_position: Optional[QueuePositionCallback] = None,
+        model: Optional[str] = None
+    ) -> Optional[str]:
+        """
+        Transcribe an audio file using the Whisper pool.
+
+        A network failure moves on to the next backend; an error response
+        from a reachable backend is returned as a failure.
 
         Args:
             audio_path: Path to the audio file to transcribe
+            on_queue_position: Notified of the queue position while waiting for capacity
+            model: Whisper model to request (None = WHISPER_MODEL)
 
         Returns:
             Transcribed text or None if transcription fails
         """
-        try:
-            logger.info(f"Transcribing audio file: {audio_path}")
+        tried: Set[str] = set()
+        while True:
+            backend, queued = await self._acquire_backend(tried, on_queue_position)
+            if not backend:
+                return None
+            try:
+                if queued and on_queue_position:
+                    await on_queue_position(None)
+                return await self._transcribe_with(backend, audio_path, model or settings.whisper_model)
+            except aiohttp.ClientError as e:
+                logger.error(f"Network error during transcription on {backend.url}: {e}")
+                backend.mark_unhealthy(str(e))
+                tried.add(backend.url)
+            except Exception as e:
+                logger.error(f"Unexpected error during transcription: {e}")
+                return None
+            finally:
+                await self._release_backend(backend)
 
-            # Prepare the file for upload
-            async with aiohttp.ClientSession() as session:
-                with open(audio_path, 'rb') as audio_file:
-                    form_data = aiohttp.FormData()
-                    form_data.add_field(
-                        'audio_file',
-                        audio_file,
-                        filename=audio_path.name,
-                        content_type='audio/mpeg'
-                    )
-                    form_data.add_field('task', 'transcribe')
-                    form_data.add_field('language', 'en')
-                    form_data.add_field('output', 'txt')
-
-                    # Send request to Whisper service
-                    async with session.post(
-                        self.transcribe_endpoint,
-                        data=form_data,
-                        timeout=aiohttp.ClientTimeout(total=3600)  # 1 hour timeout
-                    ) as response:
-                        if response.status == 200:
-                            transcript = await response.text()
-                            logger.info(f"Successfully transcribed {audio_path.name}")
-                            return transcript.strip()
-                        else:
-                            error_text = await response.text()
-                            logger.error(
-                                f"Whisper service returned status {response.status}: {error_text}"
-                            )
-                            return None
-
-        except aiohttp.ClientError as e:
-            logger.error(f"Network error during transcription: {e}")
-            return None
-        except Exception as e:
-            logger.error(f"Unexpected error during transcription: {e}")
-            return None
+    async def _transcribe_with(self, backend: WhisperBackend, audio_path: Path, model: str) -> Optional[str]:
+        """Send an audio file to one backend."""
+        logger.info(f"Transcribing audio file: {audio_path} on {backend.url} (model: {model or 'default'})")
+
+        # Prepare the file for upload
+        async with aiohttp.ClientSession() as session:
+            with open(audio_path, 'rb') as audio_file:
+                form_data = aiohttp.FormData()
+                form_data.add_field(
+                    'audio_file',
+                    audio_file,
+                    filename=audio_path.name,
+                    content_type=mimetypes.guess_type(audio_path.name)[0] or 'audio/mpeg'
+                )
+                form_data.add_field('task', 'transcribe')
+                form_data.add_field('language', 'en')
+                form_data.add_field('output', 'txt')
+                if model:
+                    # Backends that serve a single model ignore it
+                    form_data.add_field('model', model)
 
-    async def transcribe_audio_url(self, audio_url: str) -> Optional[str]:
+                # Send request to Whisper service
+                async with session.post(
+                    backend.transcribe_endpoint,
+                    data=form_data,
+                    timeout=aiohttp.ClientTimeout(total=settings.whisper_transcription_timeout_seconds)
+                ) as response:
+                    if response.status == 200:
+                        transcript = await response.text()
+                        backend.warm_models.add(model)
+                        logger.info(f"Successfully transcribed {audio_path.name}")
+                        return transcript.strip()
+                    else:
+                        error_text = await response.text()
+                        logger.error(
+                            f"Whisper service {backend.url} returned status {response.status}: {error_text}"
+                        )
+                        return None
+
+    async def transcribe_audio_url(
+        self,
+        audio_url: str,
+        on_queue_position: Optional[QueuePositionCallback] = None,
+        model: Optional[str] = None
+    ) -> Optional[str]:
         """
         Download and transcribe audio from a URL.
 
         Args:
             audio_url: URL of the audio file to transcribe
+            on_queue_position: Notified of the queue position while waiting for capacity
+            model: Whisper model to request (None = WHISPER_MODEL)
 
         Returns:
             Transcribed text or None if transcription fails
         """
-        temp_file = None
         try:
-            logger.info(f"Downloading audio from: {audio_url}")
+            # The temp file is deleted (and its disk budget released) on exit
+            async with temp_storage.create(suffix=".mp3") as temp:
+                await self._download_audio(audio_url, temp)
+                return await self.transcribe_local_audio(temp.path, on_queue_position, model)
 
-            # Download the audio file
-            async with aiohttp.ClientSession() as session:
-                async with session.get(audio_url, timeout=aiohttp.ClientTimeout(total=600)) as response:
-                    if response.status != 200:
-                        logger.error(f"Failed to download audio: HTTP {response.status}")
-                        return None
+        except Exception as e:
+            logger.error(f"Error downloading/transcribing audio: {e}")
+            return None
 
-                    # Save to temporary file
-                    with tempfile.NamedTemporaryFile(delete=False, suffix='.mp3') as temp_file:
-                        while True:
-                            chunk = await response.content.read(8192)
-                            if not chunk:
-                                break
-                            temp_file.write(chunk)
-                        temp_path = Path(temp_file.name)
+    async def transcribe_local_audio(
+        self,
+        audio_path: Path,
+        on_queue_position: Optional[QueuePositionCallback] = None,
+        model: Optional[str] = None
+    ) -> Optional[str]:
+        """
+        Transcribe an audio file on disk, preprocessing it first when
+        WHISPER_PREPROCESS_AUDIO is enabled.
 
-            logger.info(f"Audio downloaded to: {temp_path}")
+        Args:
+            audio_path: Downloaded or uploaded audio file
+            on_queue_position: Notified of the queue position while waiting for capacity
+            model: Whisper model to request (None = WHISPER_MODEL)
 
-            # Transcribe the downloaded file
-            transcript = await self.transcribe_audio_file(temp_path)
+        Returns:
+            Transcribed text or None if transcription fails
+        """
+        if settings.whisper_preprocess_audio:
+            async with temp_storage.create(suffix=OUTPUT_SUFFIX) as processed:
+                if await preprocess_audio(audio_path, processed):
+                    return await self.transcribe_audio_file(processed.path, on_queue_position, model)
 
-            return transcript
+        return await self.transcribe_audio_file(audio_path, on_queue_position, model)
 
-        except Exception as e:
-            logger.error(f"Error downloading/transcribing audio: {e}")
-            return None
-        finally:
-            # Clean up temporary file
-            if temp_file and Path(temp_file.name).exists():
+    async def _download_audio(self, audio_url: str, temp: TempFile) -> int:
+        """
+        Download audio into a managed temp file.
+
+        Transient network failures resume with a Range request from the last
+        byte received (or restart if the server ignores Range). At most
+        WHISPER_DOWNLOADS_PER_HOST downloads run against one host, within its
+        daily cap (see download_usage), and a host answering 429 is retried
+        after its Retry-After. The download is rejected if its content type
+        isn't audio, it exceeds the size cap, or the temp directory lacks
+        space for it. The expected size is reserved from the temp disk budget
+        before writing.
+
+        Returns:
+            Bytes downloaded
+
+        Raises:
+            AudioDownloadError: If the audio can't be downloaded
+            DownloadCapReached: If the host has served its daily cap
+            TempBudgetExceeded: If the audio doesn't fit in the temp disk budget
+        """
+        logger.info(f"Downloading audio from: {audio_url}")
+        max_bytes = settings.whisper_max_download_bytes
+        received = 0
+        attempt = 0
+
+        with temp.path.open("wb") as temp_file:
+            async with self._host_download(audio_url) as meter, aiohttp.ClientSession() as session:
+                while True:
+                    headers = {"Range": f"bytes={received}-"} if received else {}
+                    try:
+                        async with session.get(
+                            audio_url,
+                            headers=headers,
+                            timeout=aiohttp.ClientTimeout(total=settings.whisper_download_timeout_seconds)
+                        ) as response:
+                            if received and response.status == 200:
+                                # Server ignored the Range header; start over
+                                logger.info("Server does not support resume; restarting download")
+                                temp_file.seek(0)
+                                temp_file.truncate()
+                                received = 0
+                            elif response.status == 429 or (
+                                response.status == 503 and "Retry-After" in response.headers
+                            ):
+                                raise AudioHostBusy(
+                                    response.status, retry_after_seconds(response.headers.get("Retry-After"))
+                                )
+                            elif response.status not in (200, 206):
+                                raise AudioDownloadError(f"Failed to download audio: HTTP {response.status}")
+
+                            if not received:
+                                self._check_download(response, temp.path, max_bytes)
+                                await temp.reserve(response.content_length or max_bytes)
+
+                            async for chunk in response.content.iter_chunked(DOWNLOAD_CHUNK_SIZE):
+                                received += len(chunk)
+                                meter["bytes"] += len(chunk)
+                                if max_bytes and received > max_bytes:
+                                    raise AudioDownloadError(f"Audio exceeds the {max_bytes}-byte download limit")
+                                if received > temp.reserved:
+                                    temp.grow(received)
+                                temp_file.write(chunk)
+                            break
+
+                    except (aiohttp.ClientPayloadError, aiohttp.ClientConnectionError, asyncio.TimeoutError) as e:
+                        attempt += 1
+                        if attempt > settings.whisper_download_retries:
+                            raise AudioDownloadError(f"Download failed after {attempt} attempts: {e}") from e
+                        logger.warning(
+                            f"Download interrupted at {received} bytes ({e}); "
+                            f"resuming (attempt {attempt}/{settings.whisper_download_retries})"
+                        )
+                        await asyncio.sleep(min(2 ** attempt, 30))
+
+                    except AudioHostBusy as e:
+                        attempt += 1
+                        if attempt > settings.whisper_download_retries:
+                            raise AudioDownloadError(f"Audio host still busy after {attempt} attempts: {e}") from e
+                        delay = min(2 ** attempt, 30) if e.retry_after is None else e.retry_after
+                        if delay > settings.whisper_download_max_retry_after_seconds:
+                            raise AudioDownloadError(
+                                f"Audio host asked to retry after {delay:.0f}s, over the "
+                                f"{settings.whisper_download_max_retry_after_seconds}s limit"
+                            ) from e
+                        logger.warning(
+                            f"Audio host answered {e}; retrying in {delay:.0f}s "
+                            f"(attempt {attempt}/{settings.whisper_download_retries})"
+                        )
+                        await asyncio.sleep(delay)
+
+        logger.info(f"Audio downloaded to: {temp.path} ({received} bytes)")
+        return received
+
+    @asynccontextmanager
+    async def _host_download(self, audio_url: str):
+        """
+        Hold one of the audio host's download slots, once the host is within
+        its daily cap. Yields a meter whose "bytes" are added to the host's
+        usage when the download ends, however it ends, before the slot is
+        released.
+        """
+        host = download_host(audio_url)
+        usage = DownloadUsageService(MongoDB.get_db())
+        await usage.check(host)
+        limit = settings.whisper_downloads_per_host
+        slot = self._host_slots.setdefault(host, asyncio.Semaphore(limit)) if limit else nullcontext()
+        async with slot:
+            # Downloads this one queued behind may have used up the cap
+            await usage.check(host)
+            meter = {"bytes": 0}
+            try:
+                yield meter
+            finally:
                 try:
-                    Path(temp_file.name).unlink()
-                    logger.debug(f"Cleaned up temporary file: {temp_file.name}")
+                    await usage.record(host, meter["bytes"])
                 except Exception as e:
-                    logger.warning(f"Failed to delete temporary file: {e}")
+                    logger.warning(f"Failed to record {meter['bytes']} bytes downloaded from {host}: {e}")
+
+    @staticmethod
+    def _check_download(response: aiohttp.ClientResponse, temp_path: Path, max_bytes: int):
+        """Validate content type, declared size and free disk space before writing."""
+        content_type = response.content_type or ""
+        if not any(content_type.startswith(allowed) for allowed in settings.whisper_allowed_content_types_list):
+            raise AudioDownloadError(f"Unexpected content type for audio: {content_type or 'none'}")
+
+        length = response.content_length
+        if length is not None and max_bytes and length > max_bytes:
+            raise AudioDownloadError(f"Audio is {length} bytes, over the {max_bytes}-byte download limit")
+
+        needed = (length or max_bytes or 0) + settings.whisper_min_free_disk_bytes
+        free = shutil.disk_usage(temp_path.parent).free
+        if free < needed:
+            raise AudioDownloadError(f"Not enough disk space for download ({free} bytes free, {needed} needed)")
+
+    async def _check_backend(self, session: aiohttp.ClientSession, backend: WhisperBackend) -> bool:
+        """Probe one backend's /health and update its state."""
+        try:
+            async with session.get(f"{backend.url}/health", timeout=aiohttp.ClientTimeout(total=5)) as response:
+                healthy = response.status == 200
+                error = None if healthy else f"health check returned HTTP {response.status}"
+        except Exception as e:
+            healthy, error = False, str(e)
+
+        backend.last_checked_at = datetime.utcnow()
+        if healthy:
+            if not backend.healthy:
+                logger.info(f"Whisper backend {backend.url} is healthy again")
+            backend.healthy = True
+            backend.last_error = None
+        else:
+            backend.mark_unhealthy(error)
+        return healthy
 
     async def health_check(self) -> bool:
         """
-        Check if the Whisper service is available.
+        Check every Whisper backend.
 
         Returns:
-            True if service is healthy, False otherwise
+            True if at least one backend is healthy, False otherwise
         """
-        try:
-            health_url = f"{self.whisper_url}/health"
+        async with aiohttp.ClientSession() as session:
+            results = await asyncio.gather(*(self._check_backend(session, b) for b in self.backends))
+        # Recovered backends may admit queued requests
+        async with self._capacity_changed:
+            self._capacity_changed.notify_all()
+        return any(results)
+
+    async def _warm_up(
+        self,
+        session: aiohttp.ClientSession,
+        backend: WhisperBackend,
+        model: str,
+        timeout: float
+    ) -> bool:
+        """Transcribe a second of silence on one backend, which makes it load the model."""
+        async with backend.warm_lock:
+            if model in backend.warm_models:
+                return True
+            form_data = aiohttp.FormData()
+            form_data.add_field('audio_file', WARMUP_AUDIO, filename='warmup.wav', content_type='audio/wav')
+            form_data.add_field('task', 'transcribe')
+            form_data.add_field('output', 'txt')
+            if model:
+                form_data.add_field('model', model)
+            try:
+                async with session.post(
+                    backend.transcribe_endpoint,
+                    data=form_data,
+                    timeout=aiohttp.ClientTimeout(total=timeout)
+                ) as response:
+                    await response.read()
+                    if response.status != 200:
+                        logger.info(f"Whisper backend {backend.url} not ready: HTTP {response.status}")
+                        return False
+            except Exception as e:
+                logger.info(f"Whisper backend {backend.url} not ready: {e or type(e).__name__}")
+                return False
+            backend.warm_models.add(model)
+        logger.info(f"Whisper backend {backend.url} has loaded model {model or 'default'}")
+        return True
+
+    async def wait_until_ready(self, model: Optional[str] = None, timeout: Optional[float] = None):
+        """
+        Wait until a backend has loaded the model.
+
+        A container's /health answers as soon as its web server is up, while
+        the model may still be loading onto the GPU; requests sent then fail.
+        Backends are warmed up with a silent clip (every backend, so the pool
+        is ready together) and retried until one has the model loaded.
+
+        Args:
+            model: Whisper model the work will request (None = WHISPER_MODEL)
+            timeout: Seconds to wait (None = WHISPER_READY_TIMEOUT_SECONDS; 0 doesn't wait)
+
+        Raises:
+            WhisperNotReadyError: No backend was ready in time
+        """
+        model = model or settings.whisper_model
+        timeout = settings.whisper_ready_timeout_seconds if timeout is None else timeout
+        if timeout <= 0 or not self.backends:
+            return
+        deadline = time.monotonic() + timeout
+        while True:
+            if any(b.healthy and model in b.warm_models for b in self.backends):
+                return
+            pool = [b for b in self.backends if b.healthy] or self.backends
+            remaining = max(deadline - time.monotonic(), 1)
             async with aiohttp.ClientSession() as session:
-                async with session.get(health_url, timeout=aiohttp.ClientTimeout(total=5)) as response:
-                    return response.status == 200
+                results = await asyncio.gather(*(self._warm_up(session, b, model, remaining) for b in pool))
+            if any(results):
+                return
+            remaining = deadline - time.monotonic()
+            if remaining <= 0:
+                raise WhisperNotReadyError(
+                    f"No Whisper backend loaded model {model or 'default'} within {timeout:.0f}s"
+                )
+            await asyncio.sleep(min(WARMUP_RETRY_SECONDS, remaining))
+
+    async def warm_up(self):
+        """Load the default model on startup so the first job doesn't wait for it."""
+        try:
+            await self.wait_until_ready()
+        except WhisperNotReadyError as e:
+            logger.warning(f"Whisper warm-up: {e}")
+        except Exception as e:
+            logger.error(f"Whisper warm-up failed: {e}")
+
+    def status(self) -> Dict[str, Any]:
+        """Capacity, load and queue depth of the pool."""
+        return {
+            "capacity": sum(b.max_concurrent for b in self.backends if b.healthy),
+            "in_flight": sum(b.in_flight for b in self.backends),
+            "queue_depth": len(self._queue),
+            "backends": [backend.status() for backend in self.backends],
+        }
+
+
+async def run_whisper_health_monitor(interval_seconds: int):
+    """
+    Re-check Whisper backends until cancelled, returning recovered ones to rotation.
+
+    Args:
+        interval_seconds: Seconds between checks
+    """
+    logger.info(f"Starting Whisper health monitor (every {interval_seconds}s)")
+    while True:
+        try:
+            await whisper_service.health_check()
         except Exception as e:
-            logger.error(f"Whisper service health check failed: {e}")
-            return False
+            logger.error(f"Whisper health check run failed: {e}")
+        await asyncio.sleep(interval_seconds)
 
 
 # Singleton instance
@@ -0,0 +1,97 @@
+"""Priority-ordered worker slots for transcription work.
+
+Bulk backfills and single-episode requests share a fixed number of
+transcription slots. When every slot is busy, waiters are admitted by
+priority (then arrival order), so a user-requested episode runs before the
+next episode of a 500-episode backfill.
+"""
+import asyncio
+import heapq
+import itertools
+import logging
+from contextlib import asynccontextmanager
+from typing import Dict, List, Tuple
+
+from app.config import settings
+from app.models.schemas import JobPriority
+
+logger = logging.getLogger(__name__)
+
+_PRIORITY_RANK = {
+    JobPriority.HIGH: 0,
+    JobPriority.NORMAL: 1,
+    JobPriority.LOW: 2,
+}
+
+
+class PrioritySlots:
+    """A semaphore whose waiters are woken in priority order."""
+
+    def __init__(self, size: int):
+        self.size = max(size, 1)
+        self._in_use = 0
+        self._waiters: List[Tuple[int, int, asyncio.Future]] = []
+        self._counter = itertools.count()
+
+    @property
+    def waiting(self) -> int:
+        """Number of tasks waiting for a slot."""
+        return sum(1 for _, _, future in self._waiters if not future.done())
+
+    async def acquire(self, priority: JobPriority = JobPriority.NORMAL):
+        """Wait for a free slot."""
+        if self._in_use < self.size and not self.waiting:
+            self._in_use += 1
+            return
+
+        future = asyncio.get_running_loop().create_future()
+        heapq.heappush(self._waiters, (_PRIORITY_RANK[priority], next(self._counter), future))
+        try:
+            await future
+        except asyncio.CancelledError:
+            if future.done() and not future.cancelled():
+                # Slot was handed over just as we were cancelled; pass it on
+                self.release()
+            raise
+
+    def _wake_next(self) -> bool:
+        """Hand a slot to the highest-priority waiter, if any."""
+        while self._waiters:
+            _, _, future = heapq.heappop(self._waiters)
+            if not future.done():
+                future.set_result(None)
+                return True
+        return False
+
+    def release(self):
+        """Free a slot, handing it to the highest-priority waiter."""
+        # After a shrink, slots are retired until usage fits the new size
+        if self._in_use > self.size or not self._wake_next():
+            self._in_use -= 1
+
+    def resize(self, size: int):
+        """
+        Change the number of slots. Growing admits waiters immediately;
+        shrinking lets running work finish and retires slots as they free up.
+        """
+        self.size = max(size, 1)
+        while self._in_use < self.size and self._wake_next():
+            self._in_use += 1
+        logger.info(f"Transcription slots resized to {self.size} ({self._in_use} in use)")
+
+    def status(self) -> Dict[str, int]:
+        """Slot usage for diagnostics."""
+        return {"size": self.size, "in_use": self._in_use, "waiting": self.waiting}
+
+    @asynccontextmanager
+    async def slot(self, priority: JobPriority = JobPriority.NORMAL):
+        """Hold a slot for the duration of the block."""
+        await self.acquire(priority)
+        try:
+            yield
+        finally:
+            self.release()
+
+
+# Singleton instance shared by bulk jobs and single-episode transcriptions
+transcription_slots = PrioritySlots(settings.transcription_workers)
@@ -0,0 +1,120 @@
+"""Workspaces and their API keys.
+
+Keys are shown once when created and stored as SHA-256 hashes; requests
+send them as X-API-Key (see app/workspaces.py).
+"""
+import hashlib
+import logging
+import secrets
+import uuid
+from datetime import datetime
+from typing import Any, Dict, List, Optional
+
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+logger = logging.getLogger(__name__)
+
+# Owns data created before workspaces were enabled (no workspace_id)
+DEFAULT_WORKSPACE_ID = "default"
+
+KEY_PREFIX = "pk_"
+
+
+def hash_api_key(api_key: str) -> str:
+    """Stored form of an API key."""
+    return hashlib.sha256(api_key.encode("utf-8")).hexdigest()
+
+
+class WorkspaceService:
+    """Creates workspaces and issues, revokes and resolves their API keys."""
+
+    def __init__(self, db: AsyncIOMotorDatabase):
+        self.db = db
+        self.workspaces_collection = db.workspaces
+        self.keys_collection = db.workspace_api_keys
+
+    async def ensure_default(self):
+        """Create the default workspace if it doesn't exist yet."""
+        await self.workspaces_collection.update_one(
+            {"workspace_id": DEFAULT_WORKSPACE_ID},
+            {"$setOnInsert": {
+                "workspace_id": DEFAULT_WORKSPACE_ID,
+                "name": "Default",
+                "created_at": datetime.utcnow(),
+            }},
+            upsert=True
+        )
+
+    async def create_workspace(self, name: str) -> Dict[str, Any]:
+        """Create a workspace."""
+        workspace = {
+            "workspace_id": f"ws_{uuid.uuid4().hex[:12]}",
+            "name": name,
+            "created_at": datetime.utcnow(),
+        }
+        await self.workspaces_collection.insert_one(workspace)
+        workspace.pop("_id", None)
+        logger.info(f"Created workspace {workspace['workspace_id']} ({name})")
+        return workspace
+
+    async def get_workspace(self, workspace_id: str) -> Optional[Dict[str, Any]]:
+        """Get a workspace by ID."""
+        return await self.workspaces_collection.find_one({"workspace_id": workspace_id}, {"_id": 0})
+
+    async def list_workspaces(self) -> List[Dict[str, Any]]:
+        """All workspaces, oldest first."""
+        cursor = self.workspaces_collection.find({}, {"_id": 0}).sort("created_at", 1)
+        return await cursor.to_list(length=None)
+
+    async def delete_workspace(self, workspace_id: str) -> bool:
+        """Delete a workspace and its keys; the caller checks it holds no data."""
+        await self.keys_collection.delete_many({"workspace_id": workspace_id})
+        result = await self.workspaces_collection.delete_one({"workspace_id": workspace_id})
+        return result.deleted_count > 0
+
+    async def create_key(self, workspace_id: str, name: str) -> Dict[str, Any]:
+        """
+        Issue an API key for a workspace.
+
+        Returns:
+            The key document, plus the plaintext "api_key" (not stored)
+        """
+        api_key = KEY_PREFIX + secrets.token_urlsafe(24)
+        key = {
+            "key_id": f"key_{uuid.uuid4().hex[:12]}",
+            "workspace_id": workspace_id,
+            "name": name,
+            "key_hash": hash_api_key(api_key),
+            "key_prefix": api_key[:len(KEY_PREFIX) + 4],
+            "created_at": datetime.utcnow(),
+            "last_used_at": None,
+            "revoked_at": None,
+        }
+        await self.keys_collection.insert_one(key)
+        key.pop("_id", None)
+        logger.info(f"Issued API key {key['key_id']} for workspace {workspace_id}")
+        return {**key, "api_key": api_key}
+
+    async def list_keys(self, workspace_id: str) -> List[Dict[str, Any]]:
+        """A workspace's keys (without hashes), newest first."""
+        cursor = self.keys_collection.find(
+            {"workspace_id": workspace_id}, {"_id": 0, "key_hash": 0}
+        ).sort("created_at", -1)
+        return await cursor.to_list(length=None)
+
+    async def revoke_key(self, workspace_id: str, key_id: str) -> bool:
+        """Revoke a key; False if the workspace has no such active key."""
+        result = await self.keys_collection.update_one(
+            {"workspace_id": workspace_id, "key_id": key_id, "revoked_at": None},
+            {"$set": {"revoked_at": datetime.utcnow()}}
+        )
+        return result.modified_count > 0
+
+    async def resolve_key(self, api_key: str) -> Optional[str]:
+        """Workspace ID of an active API key, or None."""
+        key = await self.keys_collection.find_one_and_update(
+            {"key_hash": hash_api_key(api_key), "revoked_at": None},
+            {"$set": {"last_used_at": datetime.utcnow()}},
+            projection={"workspace_id": 1}
+        )
+        return key["workspace_id"] if key else None
@@ -0,0 +1,95 @@
+"""Canonical forms of feed and enclosure URLs.
+
+The same show or episode reaches us under many URLs: analytics services
+(Podtrac, Chartable, Podsights, ...) wrap enclosures in redirect prefixes,
+hosts are written in mixed case, and query strings carry campaign tags in
+arbitrary order. Normalizing before lookups keeps one podcast per feed and
+one episode per audio file. Mirrors the poll Lambda's urlnorm.go.
+"""
+import re
+from typing import Optional
+from urllib.parse import parse_qsl, quote, urlencode, urlsplit, urlunsplit
+
+# Measurement redirect prefixes, matched against "host/path" (without scheme).
+# Each wraps the real URL, which follows the prefix without its scheme (or,
+# for a few services, with it).
+TRACKING_PREFIXES = [
+    re.compile(r"^(dts\.|www\.)?podtrac\.com/(pts/)?redirect\.[a-z0-9]+/", re.IGNORECASE),
+    re.compile(r"^(www\.)?chtbl\.com/track/[^/]+/", re.IGNORECASE),
+    re.compile(r"^chrt\.fm/track/[^/]+/", re.IGNORECASE),
+    re.compile(r"^pdst\.fm/e/", re.IGNORECASE),
+    re.compile(r"^(pfx\.)?vpixl\.com/[^/]+/", re.IGNORECASE),
+    re.compile(r"^op3\.dev/e(,[^/]*)?/", re.IGNORECASE),
+    re.compile(r"^(verifi\.)?podscribe\.com/rss/p/", re.IGNORECASE),
+    re.compile(r"^pscrb\.fm/rss/p/", re.IGNORECASE),
+    re.compile(r"^arttrk\.com/p/[^/]+/", re.IGNORECASE),
+    re.compile(r"^mgln\.ai/e/[^/]+/", re.IGNORECASE),
+    re.compile(r"^prfx\.byspotify\.com/e/", re.IGNORECASE),
+    re.compile(r"^claritaspod\.com/measure/", re.IGNORECASE),
+    re.compile(r"^tracking\.swap\.fm/track/[^/]+/", re.IGNORECASE),
+]
+
+# Query parameters that only identify a campaign or listener
+TRACKING_PARAMS = re.compile(r"^(utm_\w+|fbclid|gclid|mc_(cid|eid)|aw(collection|episode)id)$", re.IGNORECASE)
+
+DEFAULT_PORTS = {"http": "80", "https": "443"}
+
+
+def _strip_tracking_prefixes(url: str) -> str:
+    """Unwrap (possibly chained) analytics redirect prefixes."""
+    scheme, sep, rest = url.partition("://")
+    if not sep:
+        return url
+    while True:
+        for prefix in TRACKING_PREFIXES:
+            match = prefix.match(rest)
+            if match:
+                rest = rest[match.end():]
+                # A few services embed the full target URL, scheme included
+                inner_scheme, inner_sep, inner_rest = rest.partition("://")
+                if inner_sep and inner_scheme.lower() in DEFAULT_PORTS:
+                    scheme, rest = inner_scheme, inner_rest
+                break
+        else:
+            return f"{scheme}://{rest}"
+
+
+def normalize_url(url: Optional[str]) -> Optional[str]:
+    """
+    Canonicalize a feed or enclosure URL.
+
+    Strips analytics redirect prefixes, lowercases the scheme and host, drops
+    default ports, fragments and tracking query parameters, and sorts the
+    remaining parameters. Paths are kept as-is (they're case-sensitive).
+
+    Args:
+        url: URL to normalize
+
+    Returns:
+        Canonical URL (None/empty input is returned unchanged)
+    """
+    if not url:
+        return url
+
+    parts = urlsplit(_strip_tracking_prefixes(url.strip()))
+    if not parts.scheme or not parts.netloc:
+        return url.strip()
+
+    try:
+        port = parts.port
+    except ValueError:
+        return url.strip()
+
+    scheme = parts.scheme.lower()
+    host = (parts.hostname or "").lower()
+    if port and str(port) != DEFAULT_PORTS.get(scheme):
+        host = f"{host}:{port}"
+    if parts.username:
+        credentials = parts.username + (f":{parts.password}" if parts.password else "")
+        host = f"{credentials}@{host}"
+
+    params = parse_qsl(parts.query, keep_blank_values=True)
+    kept = sorted((key, value) for key, value in params if not TRACKING_PARAMS.match(key))
+    # Leave an already-canonical query string byte-for-byte (signed URLs)
+    query = parts.query if kept == params else urlencode(kept, quote_via=quote)
+    return urlunsplit((scheme, host, parts.path or "/", query, ""))
@@ -0,0 +1,105 @@
+"""Structured request validation errors.
+
+Every validation failure, whether raised by FastAPI while binding a request
+DTO or by a handler checking cross-field rules, is returned in one shape:
+
+    {
+        "error": "Validation error",
+        "detail": "rss_url: Input should be a valid URL",
+        "errors": [{"code": "invalid_url", "field": "rss_url", "message": "..."}]
+    }
+
+"detail" stays a human-readable string so existing clients that display it
+keep working.
+"""
+from typing import Any, Dict, Iterable, List, Optional
+
+from fastapi import status
+
+# Request locations stripped from field paths ("body.rss_url" -> "rss_url")
+_LOCATION_PREFIXES = {"body", "query", "path", "header", "cookie"}
+
+# Pydantic error types mapped to stable error codes
+_CODE_MAP = {
+    "missing": "required",
+    "url_parsing": "invalid_url",
+    "url_scheme": "invalid_url",
+    "url_type": "invalid_url",
+    "int_parsing": "invalid_integer",
+    "int_type": "invalid_integer",
+    "bool_parsing": "invalid_boolean",
+    "datetime_parsing": "invalid_datetime",
+    "datetime_from_date_parsing": "invalid_datetime",
+    "enum": "invalid_choice",
+    "literal_error": "invalid_choice",
+    "greater_than": "too_small",
+    "greater_than_equal": "too_small",
+    "less_than": "too_large",
+    "less_than_equal": "too_large",
+    "string_too_short": "too_short",
+    "string_too_long": "too_long",
+    "too_short": "too_short",
+    "too_long": "too_long",
+    "json_invalid": "invalid_json",
+    "value_error": "invalid_value",
+    "model_attributes_type": "invalid_type",
+    "dict_type": "invalid_type",
+    "list_type": "invalid_type",
+    "string_type": "invalid_type",
+}
+
+
+def field_error(field: str, code: str, message: str) -> Dict[str, str]:
+    """Build a single structured field error."""
+    return {"code": code, "field": field, "message": message}
+
+
+def errors_from_pydantic(errors: Iterable[Dict[str, Any]]) -> List[Dict[str, str]]:
+    """
+    Convert pydantic/FastAPI error dicts into structured field errors.
+
+    Args:
+        errors: Output of RequestValidationError.errors()
+
+    Returns:
+        List of {code, field, message} dicts
+    """
+    result = []
+    for error in errors:
+        loc = [str(part) for part in error.get("loc", ())]
+        if loc and loc[0] in _LOCATION_PREFIXES:
+            loc = loc[1:]
+        field = ".".join(loc) or "body"
+        error_type = error.get("type", "invalid")
+        result.append(field_error(field, _CODE_MAP.get(error_type, error_type), error.get("msg", "Invalid value")))
+    return result
+
+
+def validation_response_body(errors: List[Dict[str, str]]) -> Dict[str, Any]:
+    """Build the JSON body returned for validation failures."""
+    detail = "; ".join(f"{e['field']}: {e['message']}" for e in errors) or "Invalid request"
+    return {"error": "Validation error", "detail": detail, "errors": errors}
+
+
+class RequestValidationFailure(Exception):
+    """Raised by handlers for validation rules that DTO binding can't express."""
+
+    def __init__(
+        self,
+        errors: List[Dict[str, str]],
+        status_code: int = status.HTTP_422_UNPROCESSABLE_ENTITY,
+    ):
+        super().__init__("; ".join(e["message"] for e in errors))
+        self.errors = errors
+        self.status_code = status_code
+
+    @classmethod
+    def single(
+        cls,
+        field: str,
+        code: str,
+        message: str,
+        status_code: Optional[int] = None,
+    ) -> "RequestValidationFailure":
+        """Build a failure for one field."""
+        return cls([field_error(field, code, message)], status_code or status.HTTP_422_UNPROCESSABLE_ENTITY)
@@ -0,0 +1,80 @@
+"""Workspace scoping for API requests.
+
+With WORKSPACES_ENABLED, each /api request must send a workspace API key
+as X-API-Key, and only sees that workspace's data. Podcasts, bulk jobs,
+one-off transcription tasks, per-user state, notification preferences and
+digests carry a workspace_id;
+episodes belong to the workspace of their podcast. Documents without a
+workspace_id (created before workspaces were enabled) belong to the
+default workspace. With workspaces disabled every helper here is a no-op.
+"""
+from typing import Any, Dict, List, Optional
+
+from fastapi import Depends, Header, HTTPException, status
+from motor.motor_asyncio import AsyncIOMotorDatabase
+
+from app.config import settings
+from app.database import get_database
+from app.services.workspace_service import DEFAULT_WORKSPACE_ID, WorkspaceService
+
+
+async def current_workspace(
+    x_api_key: Optional[str] = Header(None),
+    db: AsyncIOMotorDatabase = Depends(get_database)
+) -> Optional[str]:
+    """The caller's workspace ID (None when workspaces are disabled)."""
+    if not settings.workspaces_enabled:
+        return None
+    if not x_api_key:
+        raise HTTPException(status_code=status.HTTP_401_UNAUTHORIZED, detail="X-API-Key header required")
+    workspace_id = await WorkspaceService(db).resolve_key(x_api_key)
+    if not workspace_id:
+        raise HTTPException(status_code=status.HTTP_401_UNAUTHORIZED, detail="Invalid API key")
+    return workspace_id
+
+
+def scoped(query: Dict[str, Any], workspace_id: Optional[str]) -> Dict[str, Any]:
+    """Restrict a query on a workspace-stamped collection to one workspace."""
+    if workspace_id is None:
+        return query
+    if workspace_id == DEFAULT_WORKSPACE_ID:
+        return {**query, "workspace_id": {"$in": [DEFAULT_WORKSPACE_ID, None]}}
+    return {**query, "workspace_id": workspace_id}
+
+
+def stamp(document: Dict[str, Any], workspace_id: Optional[str]) -> Dict[str, Any]:
+    """Mark a new document as belonging to a workspace."""
+    if workspace_id is not None:
+        document["workspace_id"] = workspace_id
+    return document
+
+
+def in_workspace(document: Optional[Dict[str, Any]], workspace_id: Optional[str]) -> bool:
+    """Whether a workspace-stamped document belongs to the workspace."""
+    if document is None:
+        return False
+    if workspace_id is None:
+        return True
+    return (document.get("workspace_id") or DEFAULT_WORKSPACE_ID) == workspace_id
+
+
+async def workspace_podcast_ids(db: AsyncIOMotorDatabase, workspace_id: Optional[str]) -> Optional[List[str]]:
+    """IDs of the workspace's podcasts (None = no restriction)."""
+    if workspace_id is None:
+        return None
+    return await db.podcasts.distinct("podcast_id", scoped({}, workspace_id))
+
+
+async def find_episode(
+    db: AsyncIOMotorDatabase,
+    episode_id: str,
+    workspace_id: Optional[str],
+    **filters: Any
+) -> Optional[Dict[str, Any]]:
+    """An episode, if it exists and its podcast belongs to the workspace."""
+    episode = await db.episodes.find_one({"episode_id": episode_id, **filters})
+    if episode and workspace_id is not None:
+        podcast = await db.podcasts.find_one({"podcast_id": episode.get("podcast_id")}, {"workspace_id": 1})
+        if not in_workspace(podcast, workspace_id):
+            return None
+    return episode
@@ -0,0 +1,223 @@
+"""
+In-memory stand-ins for the Motor collections the services use, so service
+logic can be tested without MongoDB. Only the query and update operators the
+services under test use are supported.
+"""
+import copy
+import operator
+from types import SimpleNamespace
+from typing import Any, Dict, List, Optional
+
+_MISSING = object()
+
+
+def _get(doc: Dict[str, Any], path: str) -> Any:
+    value: Any = doc
+    for part in path.split("."):
+        if not isinstance(value, dict) or part not in value:
+            return _MISSING
+        value = value[part]
+    return value
+
+
+def _set(doc: Dict[str, Any], path: str, value: Any):
+    *parents, last = path.split(".")
+    for part in parents:
+        doc = doc.setdefault(part, {})
+    doc[last] = value
+
+
+def _unset(doc: Dict[str, Any], path: str):
+    *parents, last = path.split(".")
+    for part in parents:
+        doc = doc.get(part)
+        if not isinstance(doc, dict):
+            return
+    doc.pop(last, None)
+
+
+_COMPARISONS = {
+    "$gt": operator.gt,
+    "$gte": operator.ge,
+    "$lt": operator.lt,
+    "$lte": operator.le,
+}
+
+
+def _sort_key(value: Any):
+    # Mongo sorts null and missing values below every other value
+    if value is _MISSING or value is None:
+        return (0, 0)
+    return (1, value)
+
+
+def _matches_condition(value: Any, condition: Any) -> bool:
+    if isinstance(condition, dict) and any(key.startswith("$") for key in condition):
+        present = None if value is _MISSING else value
+        for op, operand in condition.items():
+            if op == "$exists":
+                if (value is not _MISSING) != bool(operand):
+                    return False
+            elif op == "$in":
+                if present not in operand:
+                    return False
+            elif op == "$ne":
+                if present == operand:
+                    return False
+            elif op in _COMPARISONS:
+                if present is None or operand is None or not _COMPARISONS[op](present, operand):
+                    return False
+            else:
+                raise NotImplementedError(op)
+        return True
+    return (None if value is _MISSING else value) == condition
+
+
+def matches(doc: Dict[str, Any], query: Dict[str, Any]) -> bool:
+    """Whether a document matches a Mongo query."""
+    for key, condition in query.items():
+        if key == "$and":
+            if not all(matches(doc, part) for part in condition):
+                return False
+            continue
+        if key == "$or":
+            if not any(matches(doc, part) for part in condition):
+                return False
+            continue
+        if not _matches_condition(_get(doc, key), condition):
+            return False
+    return True
+
+
+def _apply(doc: Dict[str, Any], update: Dict[str, Any], inserting: bool):
+    for op, fields in update.items():
+        for path, value in fields.items():
+            if op == "$set" or (op == "$setOnInsert" and inserting):
+                _set(doc, path, copy.deepcopy(value))
+            elif op == "$inc":
+                current = _get(doc, path)
+                _set(doc, path, (0 if current is _MISSING else current) + value)
+            elif op == "$unset":
+                _unset(doc, path)
+            elif op != "$setOnInsert":
+                raise NotImplementedError(op)
+
+
+class FakeCursor:
+    def __init__(self, docs: List[Dict[str, Any]]):
+        self.docs = docs
+
+    def sort(self, keys, direction: Optional[int] = None) -> "FakeCursor":
+        if isinstance(keys, str):
+            keys = [(keys, direction or 1)]
+        for key, order in reversed(keys):
+            self.docs.sort(key=lambda doc: _sort_key(_get(doc, key)), reverse=order == -1)
+        return self
+
+    def limit(self, length: int) -> "FakeCursor":
+        self.docs = self.docs[:length]
+        return self
+
+    def __aiter__(self):
+        return self._iterate()
+
+    async def _iterate(self):
+        for doc in copy.deepcopy(self.docs):
+            yield doc
+
+    async def to_list(self, length: Optional[int] = None) -> List[Dict[str, Any]]:
+        docs = copy.deepcopy(self.docs)
+        return docs if length is None else docs[:length]
+
+
+class FakeCollection:
+    def __init__(self, database: Optional["FakeDatabase"] = None):
+        self.docs: List[Dict[str, Any]] = []
+        self.database = database
+
+    def _matching(self, query: Dict[str, Any]) -> List[Dict[str, Any]]:
+        return [doc for doc in self.docs if matches(doc, query)]
+
+    async def insert_one(self, doc: Dict[str, Any]):
+        self.docs.append(copy.deepcopy(doc))
+        return SimpleNamespace(inserted_id=doc.get("_id"))
+
+    async def find_one(self, query: Optional[Dict[str, Any]] = None, projection=None, sort=None):
+        docs = FakeCursor(self._matching(query or {}))
+        if sort:
+            docs.sort(sort)
+        return copy.deepcopy(docs.docs[0]) if docs.docs else None
+
+    def find(self, query: Optional[Dict[str, Any]] = None, projection=None) -> FakeCursor:
+        return FakeCursor(self._matching(query or {}))
+
+    async def distinct(self, key: str, query: Optional[Dict[str, Any]] = None) -> List[Any]:
+        values = []
+        for doc in self._matching(query or {}):
+            value = _get(doc, key)
+            if value is not _MISSING and value not in values:
+                values.append(value)
+        return values
+
+    def aggregate(self, pipeline: List[Dict[str, Any]]) -> FakeCursor:
+        docs = copy.deepcopy(self.docs)
+        for stage in pipeline:
+            (op, spec), = stage.items()
+            if op == "$match":
+                docs = [doc for doc in docs if matches(doc, spec)]
+            elif op == "$sort":
+                docs = FakeCursor(docs).sort(list(spec.items())).docs
+            elif op == "$skip":
+                docs = docs[spec:]
+            elif op == "$limit":
+                docs = docs[:spec]
+            elif op == "$lookup":
+                foreign = getattr(self.database, spec["from"]).docs
+                for doc in docs:
+                    doc[spec["as"]] = [
+                        copy.deepcopy(other) for other in foreign
+                        if _get(other, spec["foreignField"]) == _get(doc, spec["localField"])
+                    ]
+            elif op == "$unwind":
+                field = spec["path"].lstrip("$")
+                unwound = []
+                for doc in docs:
+                    values = doc.get(field) or []
+                    if not values and spec.get("preserveNullAndEmptyArrays"):
+                        doc.pop(field, None)
+                        unwound.append(doc)
+                    unwound.extend({**doc, field: value} for value in values)
+                docs = unwound
+            else:
+                raise NotImplementedError(op)
+        return FakeCursor(docs)
+
+    async def count_documents(self, query: Dict[str, Any]) -> int:
+        return len(self._matching(query))
+
+    async def update_one(self, query: Dict[str, Any], update: Dict[str, Any], upsert: bool = False):
+        docs = self._matching(query)
+        if docs:
+            before = copy.deepcopy(docs[0])
+            _apply(docs[0], update, inserting=False)
+            return SimpleNamespace(matched_count=1, modified_count=int(docs[0] != before), upserted_id=None)
+        if upsert:
+            doc = {key: value for key, value in query.items() if not isinstance(value, dict)}
+            _apply(doc, update, inserting=True)
+            self.docs.append(doc)
+            return SimpleNamespace(matched_count=0, modified_count=0, upserted_id=doc.get("_id"))
+        return SimpleNamespace(matched_count=0, modified_count=0, upserted_id=None)
+
+
+class FakeDatabase:
+    """A database whose collections are created on first use."""
+
+    def __init__(self):
+        self._collections: Dict[str, FakeCollection] = {}
+
+    def __getattr__(self, name: str) -> FakeCollection:
+        if name.startswith("_"):
+            raise AttributeError(name)
+        if name not in self._collections:
+            self._collections[name] = FakeCollection(self)
+        return self._collections[name]
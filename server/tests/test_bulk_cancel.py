@@ -0,0 +1,144 @@
+import asyncio
+import unittest
+from datetime import datetime
+from unittest import mock
+
+from app.config import settings
+from app.models.schemas import BulkJobStatus, JobPriority, TranscriptStatus
+from app.services.bulk_transcribe_service import BulkTranscribeService
+from tests.fakes import FakeDatabase
+
+JOB_ID = "job-1"
+
+
+class BulkJobCancellationTest(unittest.IsolatedAsyncioTestCase):
+    """Stopping a bulk job mid-episode: cancellation and the per-episode deadline."""
+
+    async def asyncSetUp(self):
+        self.db = FakeDatabase()
+        now = datetime.utcnow()
+        await self.db.bulk_transcribe_jobs.insert_one({
+            "job_id": JOB_ID,
+            "rss_url": "https://example.com/feed.xml",
+            "podcast_id": "podcast-1",
+            "status": BulkJobStatus.PENDING.value,
+            "priority": JobPriority.LOW.value,
+            "total_episodes": 2,
+            "processed_episodes": 0,
+            "successful_episodes": 0,
+            "failed_episodes": 0,
+            "created_at": now,
+            "updated_at": now,
+        })
+        for index in range(2):
+            await self.db.job_episodes.insert_one({
+                "job_id": JOB_ID,
+                "index": index,
+                "title": f"Episode {index + 1}",
+                "audio_url": f"https://cdn.example.com/{index + 1}.mp3",
+                "status": TranscriptStatus.PENDING.value,
+            })
+
+        self.transcribing = asyncio.Event()
+
+        async def transcribe_audio_url(audio_url, on_queue_position=None, model=None):
+            self.transcribing.set()
+            await asyncio.Event().wait()
+
+        patches = [
+            mock.patch("app.services.bulk_transcribe_service.cache.invalidate", mock.AsyncMock()),
+            mock.patch("app.services.bulk_transcribe_service.whisper_service.wait_until_ready", mock.AsyncMock()),
+            mock.patch(
+                "app.services.bulk_transcribe_service.whisper_service.transcribe_audio_url",
+                transcribe_audio_url
+            ),
+        ]
+        for patch in patches:
+            patch.start()
+            self.addCleanup(patch.stop)
+        self.service = BulkTranscribeService(self.db)
+
+    def tearDown(self):
+        BulkTranscribeService.running_jobs.clear()
+        BulkTranscribeService.active_transcriptions.clear()
+        BulkTranscribeService.leases.clear()
+        BulkTranscribeService.detached.clear()
+
+    async def _start_job(self) -> asyncio.Task:
+        processing = asyncio.create_task(self.service.process_job(JOB_ID))
+        await asyncio.wait_for(self.transcribing.wait(), 5)
+        return processing
+
+    async def test_cancel_mid_episode_requeues_the_episode(self):
+        processing = await self._start_job()
+
+        self.assertTrue(await self.service.cancel_job(JOB_ID))
+        await asyncio.wait_for(processing, 5)
+
+        job = await self.db.bulk_transcribe_jobs.find_one({"job_id": JOB_ID})
+        self.assertEqual(job["status"], BulkJobStatus.CANCELLED.value)
+        self.assertEqual(job["failed_episodes"], 0)
+        self.assertEqual(job["processed_episodes"], 0)
+
+        entry = await self.db.job_episodes.find_one({"job_id": JOB_ID, "index": 0})
+        self.assertEqual(entry["status"], TranscriptStatus.PENDING.value)
+        self.assertNotIn("error_message", entry)
+
+        episode = await self.db.episodes.find_one({"episode_id": entry["episode_id"]})
+        self.assertEqual(episode["transcript_status"], TranscriptStatus.PENDING.value)
+        self.assertNotIn("error_message", episode)
+
+    async def test_cancel_through_another_instance_stops_the_job(self):
+        with mock.patch.object(settings, "bulk_job_lease_seconds", 4):
+            processing = await self._start_job()
+
+            # The instance handling the request doesn't run the job
+            with mock.patch.object(BulkTranscribeService, "running_jobs", {}):
+                self.assertTrue(await BulkTranscribeService(self.db).cancel_job(JOB_ID))
+            self.assertFalse(processing.done())
+
+            # Stopped at the next lease renewal
+            await asyncio.wait_for(processing, 5)
+
+        job = await self.db.bulk_transcribe_jobs.find_one({"job_id": JOB_ID})
+        self.assertEqual(job["status"], BulkJobStatus.CANCELLED.value)
+        entry = await self.db.job_episodes.find_one({"job_id": JOB_ID, "index": 0})
+        self.assertEqual(entry["status"], TranscriptStatus.PENDING.value)
+
+    async def test_job_cancelled_while_unclaimed_is_not_resumed(self):
+        self.assertTrue(await self.service.cancel_job(JOB_ID))
+
+        await asyncio.wait_for(self.service.process_job(JOB_ID), 5)
+
+        self.assertFalse(self.transcribing.is_set())
+        job = await self.db.bulk_transcribe_jobs.find_one({"job_id": JOB_ID})
+        self.assertEqual(job["status"], BulkJobStatus.CANCELLED.value)
+
+    async def test_cancel_finished_job(self):
+        await self.db.bulk_transcribe_jobs.update_one(
+            {"job_id": JOB_ID}, {"$set": {"status": BulkJobStatus.COMPLETED.value}}
+        )
+        self.assertFalse(await self.service.cancel_job(JOB_ID))
+
+    async def test_episode_deadline_fails_the_episode_and_moves_on(self):
+        notify = mock.AsyncMock()
+        with mock.patch.object(settings, "bulk_episode_timeout_seconds", 0.05), \
+                mock.patch.object(settings, "bulk_episode_delay_seconds", 0), \
+                mock.patch("app.services.bulk_transcribe_service.chat_notifier.notify_bulk_job", notify):
+            await asyncio.wait_for(self.service.process_job(JOB_ID), 5)
+
+        job = await self.db.bulk_transcribe_jobs.find_one({"job_id": JOB_ID})
+        self.assertEqual(job["status"], BulkJobStatus.COMPLETED.value)
+        self.assertEqual(job["failed_episodes"], 2)
+        self.assertEqual(job["successful_episodes"], 0)
+        self.assertEqual(notify.await_args.args[0]["failed_episodes"], 2)
+        for index in range(2):
+            entry = await self.db.job_episodes.find_one({"job_id": JOB_ID, "index": index})
+            self.assertEqual(entry["status"], TranscriptStatus.FAILED.value)
+            self.assertIn("wasn't transcribed within", entry["error_message"])
+            episode = await self.db.episodes.find_one({"episode_id": entry["episode_id"]})
+            self.assertEqual(episode["transcript_status"], TranscriptStatus.FAILED.value)
+
+
+if __name__ == "__main__":
+    unittest.main()
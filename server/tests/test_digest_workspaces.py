@@ -0,0 +1,79 @@
+import unittest
+from datetime import datetime, timedelta
+from unittest import mock
+
+from app.config import settings
+from app.services.notification_service import NotificationService
+from tests.fakes import FakeDatabase
+
+
+class DigestWorkspaceTest(unittest.IsolatedAsyncioTestCase):
+    """With workspaces enabled, digests only cover the preferences' workspace."""
+
+    async def asyncSetUp(self):
+        self.db = FakeDatabase()
+        now = datetime.utcnow()
+        # podcast-legacy predates workspaces, so belongs to the default one
+        for podcast_id, workspace_id in (("podcast-a", "ws-a"), ("podcast-b", "ws-b"), ("podcast-legacy", None)):
+            podcast = {"podcast_id": podcast_id, "title": podcast_id, "active": True}
+            if workspace_id:
+                podcast["workspace_id"] = workspace_id
+            await self.db.podcasts.insert_one(podcast)
+            await self.db.episodes.insert_one({
+                "episode_id": f"{podcast_id}-1",
+                "podcast_id": podcast_id,
+                "title": "Episode 1",
+                "transcript_status": "completed",
+                "processed_at": now - timedelta(hours=1),
+            })
+        for email, workspace_id, podcast_ids in (
+            ("all@example.com", "ws-a", None),
+            ("picked@example.com", "ws-a", ["podcast-a", "podcast-b"]),
+            ("legacy@example.com", None, None),
+        ):
+            preferences = {
+                "email": email,
+                "email_enabled": True,
+                "podcast_ids": podcast_ids,
+                "frequency": "daily",
+                "last_notified_at": now - timedelta(days=2),
+                "updated_at": now - timedelta(days=2),
+            }
+            if workspace_id:
+                preferences["workspace_id"] = workspace_id
+            await self.db.notification_preferences.insert_one(preferences)
+
+        patches = [
+            mock.patch.object(settings, "workspaces_enabled", True),
+            mock.patch("app.services.notification_service.s3_service.upload_bytes", mock.AsyncMock(return_value=True)),
+        ]
+        for patch in patches:
+            patch.start()
+            self.addCleanup(patch.stop)
+        self.notifier = mock.Mock(send=mock.AsyncMock(return_value=True))
+        self.service = NotificationService(self.db, self.notifier)
+
+    async def _digest_episodes(self, email):
+        digest = await self.db.digests.find_one({"email": email})
+        return digest and digest["episode_ids"]
+
+    async def test_digests_only_include_the_workspace_podcasts(self):
+        counts = await self.service.send_due_digests()
+
+        self.assertEqual(counts["sent"], 3)
+        self.assertEqual(await self._digest_episodes("all@example.com"), ["podcast-a-1"])
+        self.assertEqual(await self._digest_episodes("picked@example.com"), ["podcast-a-1"])
+        self.assertEqual(await self._digest_episodes("legacy@example.com"), ["podcast-legacy-1"])
+
+    async def test_digests_are_read_and_sent_per_workspace(self):
+        counts = await self.service.send_due_digests("ws-b")
+        self.assertEqual(counts["sent"], 0)
+
+        await self.service.send_due_digests("ws-a")
+        self.assertIsNone(await self.db.digests.find_one({"email": "legacy@example.com"}))
+        self.assertEqual(len(await self.service.list_digests("all@example.com", workspace_id="ws-a")), 1)
+        self.assertEqual(await self.service.list_digests("all@example.com", workspace_id="ws-b"), [])
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,55 @@
+import asyncio
+import unittest
+from unittest import mock
+
+from app.config import settings
+from app.services.download_usage import DownloadCapReached, _day_bounds
+from app.services.whisper_service import WhisperService
+from tests.fakes import FakeDatabase
+
+HOST = "cdn.example.com"
+AUDIO_URL = f"https://{HOST}/episode.mp3"
+
+
+class HostDownloadCapTest(unittest.IsolatedAsyncioTestCase):
+    async def asyncSetUp(self):
+        self.db = FakeDatabase()
+        day, _ = _day_bounds()
+        # 10 bytes left of the host's 100-byte daily cap
+        await self.db.download_usage.insert_one({"host": HOST, "day": day, "bytes": 90, "downloads": 3})
+
+        patches = [
+            mock.patch("app.services.whisper_service.MongoDB.get_db", return_value=self.db),
+            mock.patch.object(settings, "whisper_download_host_daily_bytes", 100),
+            mock.patch.object(settings, "whisper_download_host_daily_limits", ""),
+            mock.patch.object(settings, "whisper_downloads_per_host", 1),
+        ]
+        for patch in patches:
+            patch.start()
+            self.addCleanup(patch.stop)
+        self.service = WhisperService(urls=["http://whisper:9000"])
+
+    async def _download(self, size: int):
+        async with self.service._host_download(AUDIO_URL) as meter:
+            # Let the other download queue for the slot
+            await asyncio.sleep(0.01)
+            meter["bytes"] += size
+
+    async def test_download_queued_for_the_slot_rechecks_the_cap(self):
+        results = await asyncio.gather(self._download(50), self._download(50), return_exceptions=True)
+
+        self.assertIsNone(results[0])
+        self.assertIsInstance(results[1], DownloadCapReached)
+        usage = await self.db.download_usage.find_one({"host": HOST})
+        self.assertEqual(usage["bytes"], 140)
+        self.assertEqual(usage["downloads"], 4)
+
+    async def test_exhausted_cap_fails_before_queueing(self):
+        await self._download(10)
+
+        with self.assertRaises(DownloadCapReached):
+            await self._download(10)
+
+
+if __name__ == "__main__":
+    unittest.main()
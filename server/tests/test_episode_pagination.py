@@ -0,0 +1,70 @@
+import hashlib
+import unittest
+from datetime import datetime, timedelta
+from types import SimpleNamespace
+from unittest import mock
+
+from app.models.schemas import EpisodeOrder
+from app.routes.episodes import _list_episodes
+from tests.fakes import FakeDatabase
+
+
+def _episode_id(guid: str) -> str:
+    return hashlib.sha256(guid.encode("utf-8")).hexdigest()
+
+
+class EpisodeCursorPaginationTest(unittest.IsolatedAsyncioTestCase):
+    """Following next_cursor through episodes, whose _ids are sha256 strings."""
+
+    async def asyncSetUp(self):
+        self.db = FakeDatabase()
+        await self.db.podcasts.insert_one({"podcast_id": "podcast-1", "title": "Show", "active": True})
+        published = datetime(2024, 1, 1)
+        for index in range(7):
+            episode_id = _episode_id(f"guid-{index}")
+            await self.db.episodes.insert_one({
+                "_id": episode_id,
+                "episode_id": episode_id,
+                "podcast_id": "podcast-1",
+                "title": f"Episode {index}",
+                # Two episodes share a date and one has none, so paging also
+                # relies on the _id tiebreak and the null ordering
+                "published_date": None if index == 0 else published + timedelta(days=min(index, 5)),
+                "deleted_at": None,
+            })
+
+        patch = mock.patch(
+            "app.routes.episodes._format_episode_response",
+            lambda episode: SimpleNamespace(model_dump=lambda mode: {"episode_id": episode["episode_id"]})
+        )
+        patch.start()
+        self.addCleanup(patch.stop)
+
+    async def _follow(self, order: EpisodeOrder):
+        seen, cursor = [], None
+        for _ in range(10):
+            page = await _list_episodes(self.db, None, None, 1, 3, cursor, order)
+            seen.extend(episode["episode_id"] for episode in page["episodes"])
+            cursor = page["next_cursor"]
+            if not page["has_more"]:
+                self.assertIsNone(cursor)
+                return seen
+        self.fail("next_cursor never reached the last page")
+
+    async def test_newest_first_pages_through_every_episode_once(self):
+        seen = await self._follow(EpisodeOrder.NEWEST)
+
+        self.assertEqual(len(seen), 7)
+        self.assertEqual(len(set(seen)), 7)
+        self.assertEqual(seen[-1], _episode_id("guid-0"))
+
+    async def test_oldest_first_pages_through_every_episode_once(self):
+        seen = await self._follow(EpisodeOrder.OLDEST)
+
+        self.assertEqual(len(seen), 7)
+        self.assertEqual(len(set(seen)), 7)
+        self.assertEqual(seen[0], _episode_id("guid-0"))
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,81 @@
+import unittest
+from unittest import mock
+
+from app.config import settings
+from app.services.rss_parser import RSSParser
+
+FEED_URL = "https://example.com/feed.xml"
+
+
+class FakeStream:
+    """A response body delivered in chunks, like aiohttp's StreamReader."""
+
+    def __init__(self, chunks):
+        self.chunks = chunks
+
+    async def read(self, n: int = -1) -> bytes:
+        # StreamReader.read(n) returns what is buffered, at most n bytes: often one chunk
+        return self.chunks[0][:n] if self.chunks else b""
+
+    async def iter_chunked(self, n: int):
+        for chunk in self.chunks:
+            for start in range(0, len(chunk), n):
+                yield chunk[start:start + n]
+
+
+class FakeResponse:
+    def __init__(self, chunks):
+        self.status = 200
+        self.headers = {}
+        self.content_length = None  # Sent chunked
+        self.content = FakeStream(chunks)
+
+    def get_encoding(self) -> str:
+        return "utf-8"
+
+    async def __aenter__(self):
+        return self
+
+    async def __aexit__(self, *exc_info):
+        return False
+
+
+class FakeSession:
+    def __init__(self, chunks):
+        self.chunks = chunks
+
+    def get(self, url, **kwargs):
+        return FakeResponse(self.chunks)
+
+    async def __aenter__(self):
+        return self
+
+    async def __aexit__(self, *exc_info):
+        return False
+
+
+class FetchRSSTest(unittest.IsolatedAsyncioTestCase):
+    async def _fetch(self, chunks, max_bytes: int):
+        with mock.patch("app.services.rss_parser.aiohttp.ClientSession", lambda: FakeSession(chunks)), \
+                mock.patch.object(settings, "rss_max_feed_bytes", max_bytes):
+            return await RSSParser._fetch_rss(FEED_URL)
+
+    async def test_reads_a_multi_chunk_feed_to_the_end(self):
+        chunks = [b"<rss><channel>", b"<title>Show</title>", b"</channel></rss>"]
+        status, content, _, _ = await self._fetch(chunks, 1024)
+        self.assertEqual(status, 200)
+        self.assertEqual(content, "<rss><channel><title>Show</title></channel></rss>")
+
+    async def test_rejects_a_multi_chunk_feed_over_the_limit(self):
+        chunks = [b"x" * 40, b"x" * 40, b"x" * 40]
+        with self.assertRaisesRegex(ValueError, "larger than 100 bytes"):
+            await self._fetch(chunks, 100)
+
+    async def test_accepts_a_feed_of_exactly_the_limit(self):
+        chunks = [b"x" * 50, b"x" * 50]
+        _, content, _, _ = await self._fetch(chunks, 100)
+        self.assertEqual(len(content), 100)
+
+
+if __name__ == "__main__":
+    unittest.main()
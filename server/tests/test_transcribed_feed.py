@@ -0,0 +1,40 @@
+import unittest
+from datetime import datetime
+from types import SimpleNamespace
+from unittest import mock
+
+from app.routes.feeds import transcribed_episodes_feed
+from tests.fakes import FakeDatabase
+
+
+class TranscribedFeedTest(unittest.IsolatedAsyncioTestCase):
+    """The transcribed episodes RSS feed."""
+
+    async def asyncSetUp(self):
+        self.db = FakeDatabase()
+        patch = mock.patch(
+            "app.routes.feeds.Response",
+            lambda content, media_type: SimpleNamespace(body=content, media_type=media_type)
+        )
+        patch.start()
+        self.addCleanup(patch.stop)
+
+    async def test_null_titles_fall_back_to_placeholders(self):
+        await self.db.podcasts.insert_one({"podcast_id": "podcast-1", "title": None})
+        await self.db.episodes.insert_one({
+            "episode_id": "episode-1",
+            "podcast_id": "podcast-1",
+            "title": None,
+            "transcript_status": "completed",
+            "processed_at": datetime(2024, 1, 2),
+        })
+
+        response = await transcribed_episodes_feed(podcast_id=None, limit=50, db=self.db, workspace_id=None)
+
+        self.assertEqual(response.media_type, "application/rss+xml")
+        self.assertIn("<title>Unknown Podcast: Untitled Episode</title>", response.body)
+        self.assertIn('<guid isPermaLink="false">episode-1</guid>', response.body)
+
+
+if __name__ == "__main__":
+    unittest.main()
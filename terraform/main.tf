@@ -48,6 +48,11 @@ module "s3_buckets" {
   chunk_expiration_days = 7
 }
 
+# Buckets of the API's STORAGE_LOCATIONS, for podcasts pinned to one
+locals {
+  storage_location_resources = flatten([for arn in var.storage_bucket_arns : [arn, "${arn}/*"]])
+}
+
 # Step Functions State Machine
 module "step_functions" {
   source = "./modules/step-functions"
@@ -57,6 +62,9 @@ module "step_functions" {
   audio_chunker_arn     = module.lambda_audio_chunker.lambda_arn
   transcribe_chunk_arn  = module.lambda_transcribe_chunk.lambda_arn
   merge_transcripts_arn = module.lambda_merge_transcripts.lambda_arn
+
+  create_batch_state_machine = var.step_function_trigger_mode == "batch"
+  episode_state_machine_arns = concat(values(var.step_function_routes), var.podcast_state_machine_arns)
 }
 
 # Lambda: RSS Poller (Go)
@@ -71,12 +79,24 @@ module "lambda_rss_poller" {
   environment     = var.environment
 
   environment_variables = {
-    MONGODB_URI        = var.mongodb_uri
-    STEP_FUNCTION_ARN  = module.step_functions.state_machine_arn
-    AWS_REGION         = var.aws_region
-    S3_BUCKET          = module.s3_buckets.audio_bucket_name
+    MONGODB_URI            = var.mongodb_uri
+    STEP_FUNCTION_ARN      = module.step_functions.state_machine_arn
+    AWS_REGION             = var.aws_region
+    S3_BUCKET              = module.s3_buckets.audio_bucket_name
+    FEED_SNAPSHOTS_ENABLED = tostring(var.feed_snapshots_enabled)
+    STEP_FUNCTION_ARNS     = jsonencode(var.step_function_routes)
+    XRAY_TRACING_ENABLED   = tostring(var.xray_tracing_enabled)
+
+    STEP_FUNCTION_TRIGGER_MODE = var.step_function_trigger_mode
+    STEP_FUNCTION_BATCH_ARN    = module.step_functions.batch_state_machine_arn
+    STEP_FUNCTION_START_RATE   = tostring(var.step_function_start_rate)
+
+    TRANSCRIBE_MIN_DURATION_MINUTES = tostring(var.transcribe_min_duration_minutes)
+    TRANSCRIBE_MAX_DURATION_MINUTES = tostring(var.transcribe_max_duration_minutes)
   }
 
+  tracing_mode = var.xray_tracing_enabled ? "Active" : "PassThrough"
+
   policy_statements = [
     {
       effect = "Allow"
@@ -84,7 +104,25 @@ module "lambda_rss_poller" {
         "states:StartExecution",
         "states:DescribeExecution"
       ]
-      resources = [module.step_functions.state_machine_arn]
+      resources = distinct(concat(
+        [module.step_functions.state_machine_arn],
+        compact([module.step_functions.batch_state_machine_arn]),
+        values(var.step_function_routes),
+        var.podcast_state_machine_arns
+      ))
+    },
+    {
+      effect = "Allow"
+      actions = [
+        "s3:PutObject",
+        "s3:GetObject",
+        "s3:DeleteObject",
+        "s3:ListBucket"
+      ]
+      resources = [
+        module.s3_buckets.audio_bucket_arn,
+        "${module.s3_buckets.audio_bucket_arn}/feed-snapshots/*"
+      ]
     },
     {
       effect = "Allow"
@@ -130,10 +168,10 @@ module "lambda_audio_chunker" {
         "s3:DeleteObject",
         "s3:ListBucket"
       ]
-      resources = [
+      resources = concat([
         module.s3_buckets.audio_bucket_arn,
         "${module.s3_buckets.audio_bucket_arn}/*"
-      ]
+      ], local.storage_location_resources)
     },
     {
       effect = "Allow"
@@ -175,12 +213,13 @@ module "lambda_transcribe_chunk" {
       actions = [
         "s3:GetObject",
         "s3:PutObject",
+        "s3:PutObjectTagging",
         "s3:DeleteObject"
       ]
-      resources = [
+      resources = concat([
         module.s3_buckets.audio_bucket_arn,
         "${module.s3_buckets.audio_bucket_arn}/*"
-      ]
+      ], local.storage_location_resources)
     },
     {
       effect = "Allow"
@@ -209,26 +248,32 @@ module "lambda_merge_transcripts" {
   reserved_concurrent_executions = 5
 
   environment_variables = {
-    MONGODB_URI = var.mongodb_uri
-    S3_BUCKET   = module.s3_buckets.audio_bucket_name
-    AWS_REGION  = var.aws_region
+    MONGODB_URI               = var.mongodb_uri
+    S3_BUCKET                 = module.s3_buckets.audio_bucket_name
+    AWS_REGION                = var.aws_region
+    XRAY_TRACING_ENABLED      = tostring(var.xray_tracing_enabled)
+    CLEANUP_INTERMEDIATES     = tostring(var.cleanup_intermediates)
+    INTERMEDIATE_CLEANUP_MODE = var.intermediate_cleanup_mode
   }
 
-  policy_statements = [
+  tracing_mode = var.xray_tracing_enabled ? "Active" : "PassThrough"
+
+  policy_statements = concat([
     {
       effect = "Allow"
       actions = [
         "s3:GetObject",
         "s3:PutObject",
+        "s3:PutObjectTagging",
         "s3:DeleteObject",
         "s3:ListBucket"
       ]
-      resources = [
+      resources = concat([
         module.s3_buckets.audio_bucket_arn,
         "${module.s3_buckets.audio_bucket_arn}/*",
         module.s3_buckets.transcript_bucket_arn,
         "${module.s3_buckets.transcript_bucket_arn}/*"
-      ]
+      ], local.storage_location_resources)
     },
     {
       effect = "Allow"
@@ -240,5 +285,15 @@ module "lambda_merge_transcripts" {
         module.ssm_parameters.mongodb_uri_param_arn
       ]
     }
-  ]
+    ], length(var.workspace_kms_key_arns) > 0 ? [
+    {
+      # Private podcasts' transcripts are stored with their workspace's key
+      effect = "Allow"
+      actions = [
+        "kms:GenerateDataKey",
+        "kms:Decrypt"
+      ]
+      resources = var.workspace_kms_key_arns
+    }
+  ] : [])
 }
@@ -81,6 +81,13 @@ resource "aws_iam_role_policy" "custom" {
   })
 }
 
+# Attach X-Ray write policy when tracing is active
+resource "aws_iam_role_policy_attachment" "xray" {
+  count      = var.tracing_mode == "Active" ? 1 : 0
+  role       = aws_iam_role.lambda.name
+  policy_arn = "arn:aws:iam::aws:policy/AWSXRayDaemonWriteAccess"
+}
+
 # Attach VPC execution policy if needed
 resource "aws_iam_role_policy_attachment" "vpc_execution" {
   count      = var.enable_vpc ? 1 : 0
@@ -123,6 +130,10 @@ resource "aws_lambda_function" "zip" {
     }
   }
 
+  tracing_config {
+    mode = var.tracing_mode
+  }
+
   # Attach layers if specified
   layers = var.layer_arns
 
@@ -171,6 +182,10 @@ resource "aws_lambda_function" "docker" {
     }
   }
 
+  tracing_config {
+    mode = var.tracing_mode
+  }
+
   depends_on = [
     aws_cloudwatch_log_group.lambda,
     aws_iam_role_policy.cloudwatch_logs
@@ -99,6 +99,12 @@ variable "ephemeral_storage_size" {
   default     = 512
 }
 
+variable "tracing_mode" {
+  description = "X-Ray tracing mode (PassThrough or Active)"
+  type        = string
+  default     = "PassThrough"
+}
+
 variable "enable_vpc" {
   description = "Whether to deploy Lambda in VPC"
   type        = bool
@@ -101,6 +101,61 @@ resource "aws_s3_bucket_lifecycle_configuration" "audio" {
     }
   }
 
+  # Per-chunk Whisper transcripts, tagged by the transcription Lambda since
+  # they share transcripts/<episode_id>/ with final transcripts
+  rule {
+    id     = "archive-chunk-transcripts"
+    status = "Enabled"
+
+    filter {
+      tag {
+        key   = "artifact"
+        value = "chunk-transcript"
+      }
+    }
+
+    dynamic "transition" {
+      for_each = var.chunk_transcript_ia_days > 0 ? [var.chunk_transcript_ia_days] : []
+      content {
+        days          = transition.value
+        storage_class = "STANDARD_IA"
+      }
+    }
+
+    dynamic "transition" {
+      for_each = var.chunk_transcript_glacier_days > 0 ? [var.chunk_transcript_glacier_days] : []
+      content {
+        days          = transition.value
+        storage_class = "GLACIER"
+      }
+    }
+
+    dynamic "expiration" {
+      for_each = var.chunk_transcript_expiration_days > 0 ? [var.chunk_transcript_expiration_days] : []
+      content {
+        days = expiration.value
+      }
+    }
+  }
+
+  # Chunks and chunk transcripts the merge Lambda re-tagged once merged
+  # (INTERMEDIATE_CLEANUP_MODE=tag)
+  rule {
+    id     = "expire-merged-intermediates"
+    status = "Enabled"
+
+    filter {
+      tag {
+        key   = "artifact"
+        value = "merged-intermediate"
+      }
+    }
+
+    expiration {
+      days = var.merged_intermediate_expiration_days
+    }
+  }
+
   rule {
     id     = "delete-incomplete-multipart-uploads"
     status = "Enabled"
@@ -18,3 +18,27 @@ variable "chunk_expiration_days" {
   type        = number
   default     = 7
 }
+
+variable "chunk_transcript_ia_days" {
+  description = "Days before chunk transcripts move to STANDARD_IA (0 = never, otherwise at least 30)"
+  type        = number
+  default     = 30
+}
+
+variable "chunk_transcript_glacier_days" {
+  description = "Days before chunk transcripts move to GLACIER (0 = never)"
+  type        = number
+  default     = 90
+}
+
+variable "chunk_transcript_expiration_days" {
+  description = "Days before chunk transcripts are deleted (0 = never)"
+  type        = number
+  default     = 0
+}
+
+variable "merged_intermediate_expiration_days" {
+  description = "Days before chunks and chunk transcripts tagged by the merge Lambda are deleted"
+  type        = number
+  default     = 1
+}
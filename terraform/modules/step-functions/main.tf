@@ -92,6 +92,9 @@ resource "aws_sfn_state_machine" "podcast_processing" {
           "episode_id.$" = "$.episode_id"
           "chunks.$" = "$.chunkingResult.chunks"
           "s3_bucket.$" = "$.s3_bucket"
+          "s3_region.$" = "$.s3_region"
+          "storage.$" = "$.storage"
+          "encryption.$" = "$.encryption"
         }
         Next = "TranscribeChunks"
       }
@@ -244,3 +247,149 @@ resource "aws_cloudwatch_log_group" "step_functions" {
     Environment = var.environment
   }
 }
+
+# Batch workflow: one execution per podcast poll, starting the episode
+# workflow named in the input for each of its episodes
+locals {
+  batch_target_arns = distinct(concat(
+    [aws_sfn_state_machine.podcast_processing.arn],
+    var.episode_state_machine_arns
+  ))
+}
+
+resource "aws_iam_role" "batch" {
+  count = var.create_batch_state_machine ? 1 : 0
+  name  = "${var.state_machine_name}-batch-role"
+
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Action = "sts:AssumeRole"
+        Effect = "Allow"
+        Principal = {
+          Service = "states.amazonaws.com"
+        }
+      }
+    ]
+  })
+
+  tags = {
+    Name        = "${var.state_machine_name}-batch-role"
+    Environment = var.environment
+  }
+}
+
+# Starting a nested execution and waiting for it (startExecution.sync)
+resource "aws_iam_role_policy" "batch" {
+  count = var.create_batch_state_machine ? 1 : 0
+  name  = "${var.state_machine_name}-batch-policy"
+  role  = aws_iam_role.batch[0].id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = ["states:StartExecution"]
+        Resource = local.batch_target_arns
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "states:DescribeExecution",
+          "states:StopExecution"
+        ]
+        Resource = [for arn in local.batch_target_arns : "${replace(arn, ":stateMachine:", ":execution:")}:*"]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "events:PutTargets",
+          "events:PutRule",
+          "events:DescribeRule"
+        ]
+        Resource = "arn:aws:events:*:*:rule/StepFunctionsGetEventsForStepFunctionsExecutionRule"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "logs:CreateLogDelivery",
+          "logs:GetLogDelivery",
+          "logs:UpdateLogDelivery",
+          "logs:DeleteLogDelivery",
+          "logs:ListLogDeliveries",
+          "logs:PutResourcePolicy",
+          "logs:DescribeResourcePolicies",
+          "logs:DescribeLogGroups"
+        ]
+        Resource = "*"
+      }
+    ]
+  })
+}
+
+resource "aws_sfn_state_machine" "batch" {
+  count    = var.create_batch_state_machine ? 1 : 0
+  name     = "${var.state_machine_name}-batch"
+  role_arn = aws_iam_role.batch[0].arn
+
+  definition = jsonencode({
+    Comment = "Start the transcription workflow for each episode of a podcast's batch"
+    StartAt = "StartEpisodes"
+    States = {
+      StartEpisodes = {
+        Type           = "Map"
+        ItemsPath      = "$.episodes"
+        MaxConcurrency = var.batch_max_concurrency
+        Parameters = {
+          "state_machine_arn.$" = "$.state_machine_arn"
+          "episode.$"           = "$$.Map.Item.Value"
+        }
+        ResultPath = "$.results"
+        Iterator = {
+          StartAt = "StartEpisode"
+          States = {
+            StartEpisode = {
+              Type     = "Task"
+              Resource = "arn:aws:states:::states:startExecution.sync:2"
+              Parameters = {
+                "StateMachineArn.$" = "$.state_machine_arn"
+                "Input.$"           = "$.episode"
+              }
+              # One failed episode doesn't stop the rest of the batch
+              Catch = [
+                {
+                  ErrorEquals = ["States.ALL"]
+                  ResultPath  = "$.error"
+                  Next        = "EpisodeFailed"
+                }
+              ]
+              End = true
+            }
+            EpisodeFailed = {
+              Type = "Pass"
+              End  = true
+            }
+          }
+        }
+        End = true
+      }
+    }
+  })
+
+  logging_configuration {
+    log_destination        = "${aws_cloudwatch_log_group.step_functions.arn}:*"
+    include_execution_data = true
+    level                  = "ERROR"
+  }
+
+  tags = {
+    Name        = "${var.state_machine_name}-batch"
+    Environment = var.environment
+  }
+
+  depends_on = [
+    aws_iam_role_policy.batch
+  ]
+}
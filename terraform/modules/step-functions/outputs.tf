@@ -8,6 +8,11 @@ output "state_machine_name" {
   value       = aws_sfn_state_machine.podcast_processing.name
 }
 
+output "batch_state_machine_arn" {
+  description = "ARN of the batch state machine (empty unless created)"
+  value       = length(aws_sfn_state_machine.batch) > 0 ? aws_sfn_state_machine.batch[0].arn : ""
+}
+
 output "state_machine_role_arn" {
   description = "ARN of the Step Functions IAM role"
   value       = aws_iam_role.step_functions.arn
@@ -22,3 +22,21 @@ variable "merge_transcripts_arn" {
   description = "ARN of the merge transcripts Lambda function"
   type        = string
 }
+
+variable "create_batch_state_machine" {
+  description = "Create a state machine that starts the episode workflow for each episode of a podcast's batch"
+  type        = bool
+  default     = false
+}
+
+variable "batch_max_concurrency" {
+  description = "Episode workflows a batch execution runs at once"
+  type        = number
+  default     = 5
+}
+
+variable "episode_state_machine_arns" {
+  description = "Other episode workflows a batch execution may start (routes and per-podcast state machines)"
+  type        = list(string)
+  default     = []
+}
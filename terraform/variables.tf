@@ -44,6 +44,88 @@ variable "log_level" {
   }
 }
 
+variable "feed_snapshots_enabled" {
+  description = "Store the raw XML of every polled feed in S3"
+  type        = bool
+  default     = false
+}
+
+variable "xray_tracing_enabled" {
+  description = "Trace the poll and merge Lambdas with AWS X-Ray"
+  type        = bool
+  default     = false
+}
+
+variable "step_function_routes" {
+  description = "State machines by podcast provider or language, e.g. { \"language:es\" = \"arn:...\" }"
+  type        = map(string)
+  default     = {}
+}
+
+variable "podcast_state_machine_arns" {
+  description = "State machines set on individual podcasts, which the poller may start"
+  type        = list(string)
+  default     = []
+}
+
+variable "step_function_trigger_mode" {
+  description = "episode starts one execution per new episode; batch starts one per podcast poll on a batch state machine"
+  type        = string
+  default     = "episode"
+
+  validation {
+    condition     = contains(["episode", "batch"], var.step_function_trigger_mode)
+    error_message = "step_function_trigger_mode must be episode or batch."
+  }
+}
+
+variable "step_function_start_rate" {
+  description = "StartExecution calls per second the poller makes at most (0 for no limit)"
+  type        = number
+  default     = 5
+}
+
+variable "transcribe_min_duration_minutes" {
+  description = "New episodes shorter than this are recorded but not transcribed (0 for no limit)"
+  type        = number
+  default     = 0
+}
+
+variable "transcribe_max_duration_minutes" {
+  description = "New episodes longer than this are recorded but not transcribed (0 for no limit)"
+  type        = number
+  default     = 0
+}
+
+variable "cleanup_intermediates" {
+  description = "Have the merge Lambda clean up an episode's chunks and chunk transcripts once merged"
+  type        = bool
+  default     = false
+}
+
+variable "intermediate_cleanup_mode" {
+  description = "delete removes merged intermediates; tag marks them for the bucket's lifecycle rule to expire"
+  type        = string
+  default     = "delete"
+
+  validation {
+    condition     = contains(["delete", "tag"], var.intermediate_cleanup_mode)
+    error_message = "intermediate_cleanup_mode must be delete or tag."
+  }
+}
+
+variable "storage_bucket_arns" {
+  description = "ARNs of the buckets in STORAGE_LOCATIONS, which the pipeline Lambdas read and write for pinned podcasts"
+  type        = list(string)
+  default     = []
+}
+
+variable "workspace_kms_key_arns" {
+  description = "KMS keys of workspaces with private podcasts, which the merge Lambda encrypts their transcripts with"
+  type        = list(string)
+  default     = []
+}
+
 variable "tags" {
   description = "Additional tags to apply to all resources"
   type        = map(string)
@@ -14,13 +14,18 @@ logger.setLevel(logging.INFO)
 MAX_RETRIES = 3
 INITIAL_RETRY_DELAY = 1  # seconds
 
+# Chunk transcripts share transcripts/<episode_id>/ with final transcripts, so
+# the bucket's lifecycle rule for them matches this tag instead of a prefix
+CHUNK_TRANSCRIPT_TAGGING = "artifact=chunk-transcript"
+CHUNK_TRANSCRIPT_STORAGE_CLASS = os.environ.get('CHUNK_TRANSCRIPT_STORAGE_CLASS', 'STANDARD')
 
-def get_s3_client():
+
+def get_s3_client(region=None):
     """Create S3 client with proper configuration for Minio/LocalStack."""
     endpoint_url = os.environ.get('AWS_ENDPOINT_URL')
 
     client_kwargs = {
-        'region_name': os.environ.get('AWS_REGION', 'us-east-1')
+        'region_name': region or os.environ.get('AWS_REGION', 'us-east-1')
     }
 
     if endpoint_url:
@@ -34,6 +39,17 @@ def get_s3_client():
 
 # Initialize clients
 s3_client = get_s3_client()
+# Clients for podcasts pinned to buckets in other regions, by region
+regional_s3_clients = {}
+
+
+def s3_client_for(region):
+    """S3 client for an event's s3_region; the default client for our own."""
+    if not region or region == s3_client.meta.region_name:
+        return s3_client
+    if region not in regional_s3_clients:
+        regional_s3_clients[region] = get_s3_client(region)
+    return regional_s3_clients[region]
 
 # Check which Whisper service to use
 WHISPER_SERVICE_URL = os.environ.get('WHISPER_SERVICE_URL')
@@ -47,11 +63,11 @@ else:
     logger.info("Using OpenAI Whisper API")
 
 
-def download_from_s3(bucket, key, local_path):
+def download_from_s3(bucket, key, local_path, region=None):
     """Download a file from S3 to local path."""
     try:
         logger.info(f"Downloading s3://{bucket}/{key} to {local_path}")
-        s3_client.download_file(bucket, key, local_path)
+        s3_client_for(region).download_file(bucket, key, local_path)
         logger.info(f"Successfully downloaded {key}")
         return True
     except ClientError as e:
@@ -59,11 +75,11 @@ def download_from_s3(bucket, key, local_path):
         raise
 
 
-def upload_to_s3(bucket, key, local_path):
+def upload_to_s3(bucket, key, local_path, extra_args=None, region=None):
     """Upload a file from local path to S3."""
     try:
         logger.info(f"Uploading {local_path} to s3://{bucket}/{key}")
-        s3_client.upload_file(local_path, bucket, key)
+        s3_client_for(region).upload_file(local_path, bucket, key, ExtraArgs=extra_args)
         logger.info(f"Successfully uploaded to {key}")
         return True
     except ClientError as e:
@@ -119,11 +135,14 @@ def transcribe_with_local_whisper(audio_path):
             return {'text': self.text, 'segments': [s.__dict__ for s in self.segments]}
 
     class Segment:
-        def __init__(self, id, start, end, text):
+        def __init__(self, id, start, end, text, avg_logprob=None, no_speech_prob=None, compression_ratio=None):
             self.id = id
             self.start = start
             self.end = end
             self.text = text
+            self.avg_logprob = avg_logprob
+            self.no_speech_prob = no_speech_prob
+            self.compression_ratio = compression_ratio
 
     # Parse segments from local Whisper response
     segments = []
@@ -133,7 +152,10 @@ def transcribe_with_local_whisper(audio_path):
                 id=i,
                 start=seg.get('start', 0),
                 end=seg.get('end', 0),
-                text=seg.get('text', '')
+                text=seg.get('text', ''),
+                avg_logprob=seg.get('avg_logprob'),
+                no_speech_prob=seg.get('no_speech_prob'),
+                compression_ratio=seg.get('compression_ratio')
             ))
 
     return TranscriptObject(text=result.get('text', ''), segments=segments)
@@ -214,7 +236,8 @@ def lambda_handler(event, context):
         "chunk_index": 0,
         "s3_key": "chunks/ep123/chunk_0.mp3",
         "start_time_seconds": 0,
-        "s3_bucket": "podcast-audio-bucket"  # Optional, uses env var if not provided
+        "s3_bucket": "podcast-audio-bucket",  # Optional, uses env var if not provided
+        "s3_region": "eu-west-1"  # Optional, the bucket's region if not the Lambda's
     }
 
     Returns:
@@ -235,6 +258,7 @@ def lambda_handler(event, context):
     s3_key = event.get('s3_key')
     start_time_seconds = event.get('start_time_seconds', 0)
     s3_bucket = event.get('s3_bucket', os.environ.get('S3_BUCKET'))
+    s3_region = event.get('s3_region')
 
     # Validate required parameters
     if not all([episode_id, chunk_index is not None, s3_key, s3_bucket]):
@@ -258,7 +282,7 @@ def lambda_handler(event, context):
 
     try:
         # Step 1: Download audio chunk from S3
-        download_from_s3(s3_bucket, s3_key, local_audio_path)
+        download_from_s3(s3_bucket, s3_key, local_audio_path, s3_region)
 
         # Step 2: Transcribe using OpenAI Whisper API
         transcript = transcribe_audio_with_retry(local_audio_path)
@@ -275,7 +299,11 @@ def lambda_handler(event, context):
                     "id": seg.id,
                     "start": seg.start,
                     "end": seg.end,
-                    "text": seg.text
+                    "text": seg.text,
+                    # Confidence signals the merge step scores the transcript with
+                    "avg_logprob": getattr(seg, 'avg_logprob', None),
+                    "no_speech_prob": getattr(seg, 'no_speech_prob', None),
+                    "compression_ratio": getattr(seg, 'compression_ratio', None)
                 } for seg in (transcript.segments if hasattr(transcript, 'segments') else [])
             ]
         }
@@ -285,7 +313,11 @@ def lambda_handler(event, context):
             json.dump(transcript_data, f, indent=2)
 
         # Step 4: Upload transcript to S3
-        upload_to_s3(s3_bucket, transcript_s3_key, local_transcript_path)
+        upload_to_s3(s3_bucket, transcript_s3_key, local_transcript_path, {
+            'ContentType': 'application/json',
+            'StorageClass': CHUNK_TRANSCRIPT_STORAGE_CLASS,
+            'Tagging': CHUNK_TRANSCRIPT_TAGGING,
+        }, s3_region)
 
         # Step 5: Prepare response
         text_preview = transcript.text[:100] if transcript.text else ""
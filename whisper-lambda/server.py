@@ -42,7 +42,8 @@ def invoke():
         "chunk_index": int,
         "s3_key": "string",
         "start_time_seconds": int,
-        "s3_bucket": "string"
+        "s3_bucket": "string",
+        "s3_region": "string"  (optional)
     }
 
     Returns the Lambda handler response.